@@ -4,6 +4,9 @@
 package wire
 
 import (
+	"log/slog"
+	"time"
+
 	"github.com/google/wire"
 	"github.com/spf13/viper"
 	"gorm.io/gorm"
@@ -12,47 +15,95 @@ import (
 	api "github.com/personal/task-management/internal/delivery/rest/handler"
 	"github.com/personal/task-management/internal/delivery/rest/middleware"
 	"github.com/personal/task-management/internal/delivery/websocket"
+	"github.com/personal/task-management/internal/domain"
+	"github.com/personal/task-management/internal/repositories"
 	"github.com/personal/task-management/internal/repositories/postgres"
 	internalServer "github.com/personal/task-management/internal/server"
 	"github.com/personal/task-management/internal/usecase"
 	"github.com/personal/task-management/pkg/app"
+	"github.com/personal/task-management/pkg/cache"
+	localmemory "github.com/personal/task-management/pkg/cache/local-memory"
 	"github.com/personal/task-management/pkg/db"
+	"github.com/personal/task-management/pkg/features"
+	"github.com/personal/task-management/pkg/logger"
 	"github.com/personal/task-management/pkg/server/http-server"
+	"github.com/personal/task-management/pkg/storage"
+	localdisk "github.com/personal/task-management/pkg/storage/local-disk"
 	"github.com/personal/task-management/pkg/utils/hasher"
 	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/personal/task-management/pkg/utils/mailer"
+	"github.com/personal/task-management/pkg/utils/pagination"
+	"github.com/personal/task-management/pkg/webhook"
 )
 
 func NewWire() (*app.App, func(), error) {
 	panic(wire.Build(
 		config.LoadConfig,
+		loadLogger,
+		features.NewFlags,
 		db.ConnectDB,
 		loadGormDB,
 		postgres.NewPostgresUserRepository,
-		postgres.NewPostgresTaskRepository,
+		postgres.NewPostgresSessionRepository,
+		postgres.NewPostgresTaskRepositoryWithCache,
+		postgres.NewPostgresTaskCommentRepository,
+		postgres.NewPostgresTaskTemplateRepository,
 		postgres.NewChatRepository,
 		loadHasher,
 		jwt.NewJWTTokenService,
+		pagination.NewPaginator,
 		usecase.NewUserService,
+		loadWebhookDispatcher,
+		usecase.NewTaskWorkflow,
 		usecase.NewTaskService,
-		usecase.NewWebSocketService,
+		usecase.NewTaskTemplateService,
+		usecase.NewRecurrenceJob,
+		loadOfflineNotifier,
+		loadCache,
+		loadWebSocketService,
 		api.NewUserHandler,
 		api.NewTaskHandler,
+		api.NewTaskTemplateHandler,
 		api.NewAuthHandler,
 		api.NewChatHandler,
+		api.NewUploadHandler,
+		loadStorage,
+		api.NewNotificationHandler,
+		api.NewAdminHandler,
+		api.NewPermissionHandler,
 		websocket.NewHandler,
 		middleware.NewCasbinRBACService,
+		middleware.NewPolicyReloadJob,
+		usecase.NewWebSocketShutdown,
 		internalServer.NewHTTPServer,
 		newApp,
 	))
 }
 
-func newApp(httpServer *http.Server) (*app.App, func(), error) {
-	app := app.NewApp(app.WithServer(httpServer), app.WithName("task-management"))
+// newApp stops the HTTP server first so no new WebSocket upgrades arrive, then
+// drains and closes the existing sockets, and only then the background jobs.
+func newApp(httpServer *http.Server, recurrenceJob *usecase.RecurrenceJob, policyReloadJob *middleware.PolicyReloadJob, webSocketShutdown *usecase.WebSocketShutdown) (*app.App, func(), error) {
+	app := app.NewApp(
+		app.WithServer(recurrenceJob),
+		app.WithServer(policyReloadJob),
+		app.WithServer(httpServer),
+		app.WithServer(webSocketShutdown),
+		app.WithStopOrder(httpServer, webSocketShutdown),
+		app.WithName("task-management"),
+	)
 	return app, func() {
 		app.Stop()
 	}, nil
 }
 
+// loadLogger also makes the logger the default, so log.Printf output goes
+// through it too
+func loadLogger(cfg *viper.Viper) *slog.Logger {
+	log := logger.New(cfg)
+	slog.SetDefault(log)
+	return log
+}
+
 func loadGormDB(instance *db.PostgresDB) *gorm.DB {
 	instance.MigrateDB()
 	return instance.GetDB()
@@ -61,3 +112,55 @@ func loadGormDB(instance *db.PostgresDB) *gorm.DB {
 func loadHasher(cfg *viper.Viper) usecase.Hasher {
 	return hasher.NewBcryptHasher(cfg)
 }
+
+func loadOfflineNotifier(cfg *viper.Viper, flags *features.Flags, userRepo repositories.UserRepository) usecase.Notifier {
+	if !flags.Enabled(features.OfflineEmail) {
+		return usecase.NewNoopNotifier()
+	}
+	return usecase.NewEmailNotifier(userRepo, mailer.NewSMTPMailer(cfg))
+}
+
+func loadWebhookDispatcher(cfg *viper.Viper, flags *features.Flags) usecase.WebhookDispatcher {
+	if !flags.Enabled(features.Webhooks) {
+		return usecase.NewNoopWebhookDispatcher()
+	}
+	return webhook.NewDispatcher(cfg)
+}
+
+func loadCache(cfg *viper.Viper) (cache.Cache, func(), error) {
+	interval := cfg.GetDuration("cache.cleanup_interval")
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	c, err := localmemory.NewCache(interval)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, func() {
+		c.Close()
+	}, nil
+}
+
+// loadStorage keeps chat attachments on the local disk
+func loadStorage(cfg *viper.Viper) (storage.Storage, error) {
+	store, err := localdisk.NewStorage(cfg.GetString("storage.local.dir"), cfg.GetString("storage.local.base_url"))
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// loadWebSocketService stops the hub goroutine on cleanup
+// loadWebSocketService stops the hub goroutine on cleanup. With webhooks
+// enabled, notifications are also forwarded to the webhook endpoints; email
+// stays the offline fallback rather than a channel every notification fans
+// out to.
+func loadWebSocketService(cfg *viper.Viper, flags *features.Flags, roomRepo repositories.ChatRepository, offlineNotifier usecase.Notifier, webhooks usecase.WebhookDispatcher, idempotencyKeys cache.Cache) (usecase.WebSocketService, func()) {
+	service := usecase.NewWebSocketService(cfg, roomRepo, offlineNotifier, idempotencyKeys)
+	if flags.Enabled(features.Webhooks) {
+		service.RegisterNotifier(domain.NotificationChannelWebhook, usecase.NewWebhookNotifier(webhooks))
+	}
+	return service, func() {
+		service.Close()
+	}
+}
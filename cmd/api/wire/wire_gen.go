@@ -7,18 +7,32 @@
 package wire
 
 import (
+	"log/slog"
+	"time"
+
 	"github.com/personal/task-management/config"
 	"github.com/personal/task-management/internal/delivery/rest/handler"
 	"github.com/personal/task-management/internal/delivery/rest/middleware"
 	"github.com/personal/task-management/internal/delivery/websocket"
+	"github.com/personal/task-management/internal/domain"
+	"github.com/personal/task-management/internal/repositories"
 	"github.com/personal/task-management/internal/repositories/postgres"
 	"github.com/personal/task-management/internal/server"
 	"github.com/personal/task-management/internal/usecase"
 	"github.com/personal/task-management/pkg/app"
+	"github.com/personal/task-management/pkg/cache"
+	localmemory "github.com/personal/task-management/pkg/cache/local-memory"
 	"github.com/personal/task-management/pkg/db"
+	"github.com/personal/task-management/pkg/features"
+	"github.com/personal/task-management/pkg/logger"
 	"github.com/personal/task-management/pkg/server/http-server"
+	"github.com/personal/task-management/pkg/storage"
+	localdisk "github.com/personal/task-management/pkg/storage/local-disk"
 	"github.com/personal/task-management/pkg/utils/hasher"
 	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/personal/task-management/pkg/utils/mailer"
+	"github.com/personal/task-management/pkg/utils/pagination"
+	"github.com/personal/task-management/pkg/webhook"
 	"github.com/spf13/viper"
 	"gorm.io/gorm"
 )
@@ -30,45 +44,99 @@ func NewWire() (*app.App, func(), error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	flags := features.NewFlags(viper)
 	postgresDB := db.ConnectDB(viper)
 	gormDB := loadGormDB(postgresDB)
 	userRepository := postgres.NewPostgresUserRepository(gormDB)
+	sessionRepository := postgres.NewPostgresSessionRepository(gormDB)
 	hasher := loadHasher(viper)
-	jwtTokenServicer := jwt.NewJWTTokenService(viper)
-	userService := usecase.NewUserService(userRepository, hasher, jwtTokenServicer)
-	userHandler := handler.NewUserHandler(userService)
-	taskRepository := postgres.NewPostgresTaskRepository(gormDB)
+	cacheCache, cleanup, err := loadCache(viper)
+	if err != nil {
+		return nil, nil, err
+	}
+	jwtTokenServicer := jwt.NewJWTTokenService(viper, cacheCache)
+	userService := usecase.NewUserService(userRepository, sessionRepository, hasher, jwtTokenServicer)
+	paginator := pagination.NewPaginator(viper)
+	userHandler := handler.NewUserHandler(userService, paginator)
+	taskRepository := postgres.NewPostgresTaskRepositoryWithCache(gormDB, cacheCache)
 	chatRepository := postgres.NewChatRepository(gormDB)
-	webSocketService := usecase.NewWebSocketService(chatRepository)
-	taskService := usecase.NewTaskService(taskRepository, userRepository, webSocketService)
-	taskHandler := handler.NewTaskHandler(taskService)
+	notifier := loadOfflineNotifier(viper, flags, userRepository)
+	webhookDispatcher := loadWebhookDispatcher(viper, flags)
+	webSocketService, cleanup2 := loadWebSocketService(viper, flags, chatRepository, notifier, webhookDispatcher, cacheCache)
+	taskCommentRepository := postgres.NewPostgresTaskCommentRepository(gormDB)
+	workflow, err := usecase.NewTaskWorkflow(viper)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	taskService := usecase.NewTaskService(taskRepository, taskCommentRepository, userRepository, webSocketService, webhookDispatcher, workflow)
+	taskHandler := handler.NewTaskHandler(taskService, paginator)
+	taskTemplateRepository := postgres.NewPostgresTaskTemplateRepository(gormDB)
+	taskTemplateService := usecase.NewTaskTemplateService(taskTemplateRepository, userRepository, taskService)
+	taskTemplateHandler := handler.NewTaskTemplateHandler(taskTemplateService, paginator)
 	authHandler := handler.NewAuthHandler(userService)
 	casbinRBACService, err := middleware.NewCasbinRBACService(viper, gormDB)
 	if err != nil {
+		cleanup2()
+		cleanup()
 		return nil, nil, err
 	}
-	websocketHandler := websocket.NewHandler(webSocketService, jwtTokenServicer)
+	websocketHandler := websocket.NewHandler(viper, webSocketService, jwtTokenServicer, userService)
 	chatHandler := handler.NewChatHandler(webSocketService, jwtTokenServicer)
-	httpServer := server.NewHTTPServer(viper, userHandler, taskHandler, authHandler, casbinRBACService, websocketHandler, chatHandler)
-	appApp, cleanup, err := newApp(httpServer)
+	storageStorage, err := loadStorage(viper)
 	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	uploadHandler := handler.NewUploadHandler(viper, storageStorage)
+	notificationHandler := handler.NewNotificationHandler(webSocketService)
+	adminHandler := handler.NewAdminHandler(casbinRBACService)
+	permissionHandler := handler.NewPermissionHandler(casbinRBACService)
+	slogLogger := loadLogger(viper)
+	httpServer := server.NewHTTPServer(viper, slogLogger, userService, taskRepository, userHandler, taskHandler, taskTemplateHandler, authHandler, jwtTokenServicer, casbinRBACService, websocketHandler, chatHandler, uploadHandler, notificationHandler, adminHandler, permissionHandler)
+	recurrenceJob := usecase.NewRecurrenceJob(viper, flags, taskService)
+	policyReloadJob := middleware.NewPolicyReloadJob(viper, casbinRBACService)
+	webSocketShutdown := usecase.NewWebSocketShutdown(viper, webSocketService)
+	appApp, cleanup3, err := newApp(httpServer, recurrenceJob, policyReloadJob, webSocketShutdown)
+	if err != nil {
+		cleanup2()
+		cleanup()
 		return nil, nil, err
 	}
 	return appApp, func() {
+		cleanup3()
+		cleanup2()
 		cleanup()
 	}, nil
 }
 
 // wire.go:
 
-func newApp(httpServer *http.Server) (*app.App, func(), error) {
-	app2 := app.NewApp(app.WithServer(httpServer), app.WithName("task-management"))
+func newApp(httpServer *http.Server, recurrenceJob *usecase.RecurrenceJob, policyReloadJob *middleware.PolicyReloadJob, webSocketShutdown *usecase.WebSocketShutdown) (*app.App, func(), error) {
+	app2 := app.NewApp(
+		app.WithServer(recurrenceJob),
+		app.WithServer(policyReloadJob),
+		app.WithServer(httpServer),
+		app.WithServer(webSocketShutdown),
+		app.WithStopOrder(httpServer, webSocketShutdown),
+		app.WithName("task-management"),
+	)
 	return app2, func() {
 		app2.
 			Stop()
 	}, nil
 }
 
+// loadLogger also makes the logger the default, so log.Printf output goes
+// through it too
+func loadLogger(cfg *viper.Viper) *slog.Logger {
+	log := logger.New(cfg)
+	slog.SetDefault(log)
+	return log
+}
+
 func loadGormDB(instance *db.PostgresDB) *gorm.DB {
 	instance.MigrateDB()
 	return instance.GetDB()
@@ -77,3 +145,54 @@ func loadGormDB(instance *db.PostgresDB) *gorm.DB {
 func loadHasher(cfg *viper.Viper) usecase.Hasher {
 	return hasher.NewBcryptHasher(cfg)
 }
+
+func loadOfflineNotifier(cfg *viper.Viper, flags *features.Flags, userRepo repositories.UserRepository) usecase.Notifier {
+	if !flags.Enabled(features.OfflineEmail) {
+		return usecase.NewNoopNotifier()
+	}
+	return usecase.NewEmailNotifier(userRepo, mailer.NewSMTPMailer(cfg))
+}
+
+func loadWebhookDispatcher(cfg *viper.Viper, flags *features.Flags) usecase.WebhookDispatcher {
+	if !flags.Enabled(features.Webhooks) {
+		return usecase.NewNoopWebhookDispatcher()
+	}
+	return webhook.NewDispatcher(cfg)
+}
+
+func loadCache(cfg *viper.Viper) (cache.Cache, func(), error) {
+	interval := cfg.GetDuration("cache.cleanup_interval")
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	c, err := localmemory.NewCache(interval)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, func() {
+		c.Close()
+	}, nil
+}
+
+// loadStorage keeps chat attachments on the local disk
+func loadStorage(cfg *viper.Viper) (storage.Storage, error) {
+	store, err := localdisk.NewStorage(cfg.GetString("storage.local.dir"), cfg.GetString("storage.local.base_url"))
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// loadWebSocketService stops the hub goroutine on cleanup. With webhooks
+// enabled, notifications are also forwarded to the webhook endpoints; email
+// stays the offline fallback rather than a channel every notification fans
+// out to.
+func loadWebSocketService(cfg *viper.Viper, flags *features.Flags, roomRepo repositories.ChatRepository, offlineNotifier usecase.Notifier, webhooks usecase.WebhookDispatcher, idempotencyKeys cache.Cache) (usecase.WebSocketService, func()) {
+	service := usecase.NewWebSocketService(cfg, roomRepo, offlineNotifier, idempotencyKeys)
+	if flags.Enabled(features.Webhooks) {
+		service.RegisterNotifier(domain.NotificationChannelWebhook, usecase.NewWebhookNotifier(webhooks))
+	}
+	return service, func() {
+		service.Close()
+	}
+}
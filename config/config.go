@@ -25,6 +25,7 @@ func LoadConfig() (*viper.Viper, error) {
 	viper.SetDefault("server.write_timeout", "10s")
 	viper.SetDefault("server.shutdown_timeout", "30s")
 	viper.SetDefault("auth.jwt_expiration", "24h")
+	viper.SetDefault("auth.refresh_expiration", "168h")
 	viper.SetDefault("database.conn_max_lifetime", "5m")
 
 	// Process environment variable substitutions with defaults
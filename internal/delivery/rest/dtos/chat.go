@@ -1,26 +1,88 @@
 package dtos
 
+import "github.com/personal/task-management/internal/domain"
+
 // CreateDirectRoomRequest represents the request body for creating a direct chat room
 type CreateDirectRoomRequest struct {
-	UserID2 string `json:"user_id_2" example:"user-123"`
+	UserID2 string `json:"user_id_2" validate:"required,uuid" example:"3f0b9c1e-7a2d-4c55-9e61-0d8f2b7a4c10"`
 }
 
 // CreateGroupRoomRequest represents the request body for creating a group chat room
 type CreateGroupRoomRequest struct {
-	Name    string   `json:"name" example:"Team Chat"`
+	Name    string   `json:"name" validate:"required,max=100" example:"Team Chat"`
+	UserIDs []string `json:"user_ids" validate:"required,min=1,dive,uuid" example:"[\"3f0b9c1e-7a2d-4c55-9e61-0d8f2b7a4c10\"]"`
+}
+
+// AddRoomMemberRequest represents the request body for adding a member to a group room
+type AddRoomMemberRequest struct {
+	UserID string `json:"user_id" validate:"required" example:"user-123"`
+}
+
+// RoomMembersResponse represents the members of a chat room
+type RoomMembersResponse struct {
 	UserIDs []string `json:"user_ids" example:"[\"user-123\", \"user-456\"]"`
 }
 
 // UpdateRoomRequest represents the request body for updating a chat room
 type UpdateRoomRequest struct {
-	Name        string `json:"name,omitempty" example:"New Room Name"`
-	Description string `json:"description,omitempty" example:"Updated room description"`
-	AvatarURL   string `json:"avatar_url,omitempty" example:"https://example.com/avatar.jpg"`
+	Name        string `json:"name,omitempty" validate:"omitempty,max=100" example:"New Room Name"`
+	Description string `json:"description,omitempty" validate:"omitempty,max=500" example:"Updated room description"`
+	AvatarURL   string `json:"avatar_url,omitempty" validate:"omitempty,url" example:"https://example.com/avatar.jpg"`
+}
+
+// RoomHistoryResponse represents a cursor-paginated page of room history
+type RoomHistoryResponse struct {
+	Messages []domain.WebSocketMessage `json:"messages"`
+	HasMore  bool                      `json:"has_more"`
+}
+
+// DirectRoomIDResponse represents the canonical ID of a direct room
+type DirectRoomIDResponse struct {
+	RoomID string `json:"room_id" example:"user-a_user-b"`
+	Exists bool   `json:"exists"`
+}
+
+// SendDirectMessageRequest represents the request body for sending a direct message
+type SendDirectMessageRequest struct {
+	Content string `json:"content" example:"Hello, world!"`
 }
 
 // SendMessageRequest represents the request body for sending a message
 type SendMessageRequest struct {
-	Content string `json:"content" example:"Hello, world!"`
-	Type    string `json:"type,omitempty" example:"text" enums:"text,file,image,video,audio"`
-	FileURL string `json:"file_url,omitempty" example:"https://example.com/file.pdf"`
+	Content      string `json:"content" validate:"required_if=Type text" example:"Hello, world!"`
+	Type         string `json:"type,omitempty" validate:"required,oneof=text file image video audio" example:"text" enums:"text,file,image,video,audio"`
+	FileURL      string `json:"file_url,omitempty" validate:"required_unless=Type text,omitempty,url" example:"https://example.com/file.pdf"`
+	FileName     string `json:"file_name,omitempty" example:"report.pdf"`
+	FileSize     int64  `json:"file_size,omitempty" validate:"min=0" example:"1048576"`
+	FileType     string `json:"file_type,omitempty" example:"application/pdf"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty" validate:"omitempty,url" example:"https://example.com/thumb.jpg"`
+	Duration     int    `json:"duration,omitempty" validate:"min=0" example:"120"`
+}
+
+// SetSlowModeRequest represents the request body for setting a room's slow mode
+type SetSlowModeRequest struct {
+	Seconds int `json:"seconds" validate:"min=0,max=3600" example:"30"`
+}
+
+// UnreadCountResponse represents the number of unread notifications
+type UnreadCountResponse struct {
+	Count int `json:"count" example:"3"`
+}
+
+// UploadResponse describes a stored attachment; its fields map onto the file
+// fields of SendMessageRequest
+type UploadResponse struct {
+	FileURL      string `json:"file_url" example:"http://localhost:8080/uploads/0b7c8f0e.pdf"`
+	FileName     string `json:"file_name" example:"report.pdf"`
+	FileSize     int64  `json:"file_size" example:"1048576"`
+	FileType     string `json:"file_type" example:"application/pdf"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty" example:"http://localhost:8080/uploads/0b7c8f0e_thumb.jpg"`
+}
+
+// NotificationPreferenceRequest represents the request body for opting in to
+// or out of a notification channel. An empty type applies to every type.
+type NotificationPreferenceRequest struct {
+	Channel string `json:"channel" validate:"required,oneof=websocket email webhook" example:"email" enums:"websocket,email,webhook"`
+	Type    string `json:"type,omitempty" validate:"omitempty,oneof=task_update mention system message" example:"mention" enums:"task_update,mention,system,message"`
+	Enabled *bool  `json:"enabled" validate:"required" example:"false"`
 }
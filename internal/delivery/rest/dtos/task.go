@@ -8,17 +8,69 @@ import (
 )
 
 type CreateTaskInput struct {
-	Title       string    `json:"title" validate:"required"`
-	Description string    `json:"description"`
-	DueDate     time.Time `json:"due_date" validate:"required,gt=now"`
-	AssigneeID  uuid.UUID `json:"assignee_id" validate:"required"`
-	CreatorID   uuid.UUID `json:"creator_id" validate:"required"`
+	Title       string               `json:"title" validate:"required"`
+	Description string               `json:"description"`
+	DueDate     time.Time            `json:"due_date" validate:"required,gt=now"`
+	AssigneeID  uuid.UUID            `json:"assignee_id" validate:"required"`
+	CreatorID   uuid.UUID            `json:"creator_id" validate:"required"`
+	Recurrence  *task.RecurrenceRule `json:"recurrence,omitempty"`
+}
+
+// CreateTasksBulkRequest is the body of a bulk create; the creator comes from
+// the caller's token
+type CreateTasksBulkRequest struct {
+	Tasks []CreateTaskInput `json:"tasks" validate:"required,min=1,max=100"`
+}
+
+type CreateTasksBulkInput struct {
+	CreatorID uuid.UUID         `json:"creator_id" validate:"required"`
+	Tasks     []CreateTaskInput `json:"tasks" validate:"required,min=1,max=100"`
+}
+
+// BulkTaskError reports why the task at Index in the request was not created
+type BulkTaskError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+type CreateTasksBulkOutput struct {
+	Created []*task.Task    `json:"created"`
+	Errors  []BulkTaskError `json:"errors"`
 }
 
 type UpdateTaskStatusInput struct {
 	TaskID    uuid.UUID   `json:"task_id" validate:"required"`
 	UserID    uuid.UUID   `json:"user_id" validate:"required"`
-	NewStatus task.Status `json:"new_status" validate:"required,oneof=pending in_progress completed"`
+	NewStatus task.Status `json:"new_status" validate:"required,oneof=pending in_progress blocked completed"`
+	Reason    string      `json:"reason,omitempty" validate:"required_if=NewStatus blocked"`
+}
+
+// UpdateTaskDetailsRequest is the body of a task edit; omitted fields keep
+// their current values
+type UpdateTaskDetailsRequest struct {
+	Title       *string    `json:"title,omitempty" example:"Write the Q3 report"`
+	Description *string    `json:"description,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+}
+
+type UpdateTaskDetailsInput struct {
+	TaskID      uuid.UUID  `json:"task_id" validate:"required"`
+	RequesterID uuid.UUID  `json:"requester_id" validate:"required"`
+	Title       *string    `json:"title,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+}
+
+// ReassignTaskRequest is the body of a reassignment; the task comes from the
+// path and the requester from the caller's token
+type ReassignTaskRequest struct {
+	AssigneeID uuid.UUID `json:"assignee_id" validate:"required"`
+}
+
+type ReassignTaskInput struct {
+	TaskID      uuid.UUID `json:"task_id" validate:"required"`
+	RequesterID uuid.UUID `json:"requester_id" validate:"required"`
+	AssigneeID  uuid.UUID `json:"assignee_id" validate:"required"`
 }
 
 type GetEmployeeTasksInput struct {
@@ -31,6 +83,54 @@ type GetTaskInput struct {
 	RequesterID uuid.UUID `json:"requester_id" validate:"required"`
 }
 
+type AddCommentRequest struct {
+	Content string `json:"content" validate:"required,max=5000" example:"Blocked on the API review"`
+	// Status, when set, moves the task along with the comment. A blocking
+	// comment doubles as the block reason.
+	Status *task.Status `json:"status,omitempty" validate:"omitempty,oneof=pending in_progress blocked completed" example:"blocked"`
+}
+
+type AddCommentInput struct {
+	TaskID  uuid.UUID    `json:"task_id" validate:"required"`
+	UserID  uuid.UUID    `json:"user_id" validate:"required"`
+	Content string       `json:"content" validate:"required,max=5000"`
+	Status  *task.Status `json:"status,omitempty" validate:"omitempty,oneof=pending in_progress blocked completed"`
+}
+
+type ListCommentsInput struct {
+	TaskID      uuid.UUID `json:"task_id" validate:"required"`
+	RequesterID uuid.UUID `json:"requester_id" validate:"required"`
+	Limit       int       `json:"limit"`
+	Offset      int       `json:"offset"`
+}
+
+type DeleteCommentInput struct {
+	TaskID      uuid.UUID `json:"task_id" validate:"required"`
+	CommentID   uuid.UUID `json:"comment_id" validate:"required"`
+	RequesterID uuid.UUID `json:"requester_id" validate:"required"`
+}
+
+type AddTagRequest struct {
+	Name string `json:"name" validate:"required,max=50" example:"backend"`
+}
+
+type AddTagInput struct {
+	TaskID uuid.UUID `json:"task_id" validate:"required"`
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+	Name   string    `json:"name" validate:"required"`
+}
+
+type RemoveTagInput struct {
+	TaskID uuid.UUID `json:"task_id" validate:"required"`
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+	Name   string    `json:"name" validate:"required"`
+}
+
+type ListTagsInput struct {
+	TaskID      uuid.UUID `json:"task_id" validate:"required"`
+	RequesterID uuid.UUID `json:"requester_id" validate:"required"`
+}
+
 type DeleteTaskInput struct {
 	TaskID      uuid.UUID `json:"task_id" validate:"required"`
 	RequesterID uuid.UUID `json:"requester_id" validate:"required"`
@@ -42,13 +142,16 @@ type GetTasksWithFilterInput struct {
 }
 
 type TaskFilter struct {
-	SortBy     string      `json:"sort_by"`
-	Status     task.Status `json:"status"`
-	DueDate    time.Time   `json:"due_date"`
-	Limit      int         `json:"limit"`
-	Offset     int         `json:"offset"`
-	SortOrder  string      `json:"sort_order"`
-	AssigneeID uuid.UUID   `json:"assignee_id"`
+	SortBy     string        `json:"sort_by"`
+	Status     task.Status   `json:"status"`
+	Statuses   []task.Status `json:"statuses"`
+	Tags       []string      `json:"tags"`
+	AnyTag     bool          `json:"any_tag"`
+	DueDate    time.Time     `json:"due_date"`
+	Limit      int           `json:"limit"`
+	Offset     int           `json:"offset"`
+	SortOrder  string        `json:"sort_order"`
+	AssigneeID uuid.UUID     `json:"assignee_id"`
 }
 
 type GetTaskSummaryByEmployeeInput struct {
@@ -62,4 +165,72 @@ type EmployeeTaskSummary struct {
 	CompletedTasks  int       `json:"completed_tasks"`
 	PendingTasks    int       `json:"pending_tasks"`
 	InProgressTasks int       `json:"in_progress_tasks"`
+	BlockedTasks    int       `json:"blocked_tasks"`
+}
+
+type TaskStatusChangedPayload struct {
+	TaskID         uuid.UUID   `json:"task_id"`
+	Title          string      `json:"title"`
+	PreviousStatus task.Status `json:"previous_status"`
+	Status         task.Status `json:"status"`
+	BlockReason    string      `json:"block_reason,omitempty"`
+	AssigneeID     uuid.UUID   `json:"assignee_id"`
+	ChangedBy      uuid.UUID   `json:"changed_by"`
+	ChangedAt      time.Time   `json:"changed_at"`
+}
+
+type TaskTemplateRequest struct {
+	Title             string     `json:"title" validate:"required,max=255" example:"Weekly report"`
+	Description       string     `json:"description"`
+	DueOffsetHours    int        `json:"due_offset_hours" validate:"required,min=1" example:"48"`
+	DefaultAssigneeID *uuid.UUID `json:"default_assignee_id,omitempty"`
+}
+
+type CreateTaskTemplateInput struct {
+	CreatorID         uuid.UUID  `json:"creator_id" validate:"required"`
+	Title             string     `json:"title" validate:"required,max=255"`
+	Description       string     `json:"description"`
+	DueOffsetHours    int        `json:"due_offset_hours" validate:"required,min=1"`
+	DefaultAssigneeID *uuid.UUID `json:"default_assignee_id,omitempty"`
+}
+
+type UpdateTaskTemplateInput struct {
+	TemplateID        uuid.UUID  `json:"template_id" validate:"required"`
+	RequesterID       uuid.UUID  `json:"requester_id" validate:"required"`
+	Title             string     `json:"title" validate:"required,max=255"`
+	Description       string     `json:"description"`
+	DueOffsetHours    int        `json:"due_offset_hours" validate:"required,min=1"`
+	DefaultAssigneeID *uuid.UUID `json:"default_assignee_id,omitempty"`
+}
+
+type GetTaskTemplateInput struct {
+	TemplateID  uuid.UUID `json:"template_id" validate:"required"`
+	RequesterID uuid.UUID `json:"requester_id" validate:"required"`
+}
+
+type ListTaskTemplatesInput struct {
+	RequesterID uuid.UUID `json:"requester_id" validate:"required"`
+	Limit       int       `json:"limit"`
+	Offset      int       `json:"offset"`
+}
+
+type DeleteTaskTemplateInput struct {
+	TemplateID  uuid.UUID `json:"template_id" validate:"required"`
+	RequesterID uuid.UUID `json:"requester_id" validate:"required"`
+}
+
+// CreateTaskFromTemplateRequest overrides template defaults; omitted fields
+// keep the template's values
+type CreateTaskFromTemplateRequest struct {
+	Title       *string              `json:"title,omitempty" validate:"omitempty,max=255"`
+	Description *string              `json:"description,omitempty"`
+	DueDate     *time.Time           `json:"due_date,omitempty"`
+	AssigneeID  *uuid.UUID           `json:"assignee_id,omitempty"`
+	Recurrence  *task.RecurrenceRule `json:"recurrence,omitempty"`
+}
+
+type CreateTaskFromTemplateInput struct {
+	TemplateID uuid.UUID `json:"template_id" validate:"required"`
+	CreatorID  uuid.UUID `json:"creator_id" validate:"required"`
+	Overrides  CreateTaskFromTemplateRequest
 }
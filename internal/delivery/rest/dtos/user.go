@@ -1,27 +1,54 @@
 package dtos
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+)
 
 type RegisterUserInput struct {
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8"`
+	Password string `json:"password" validate:"required,min=8,password"`
 	Name     string `json:"name" validate:"required"`
-	Role     string `json:"role" validate:"required,oneof=employee employer"`
+	Role     string `json:"role" validate:"required,oneof=employee manager employer"`
 }
 
 type LoginInput struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
+	// Device labels the session in the sessions list; the handler falls back
+	// to the User-Agent header
+	Device string `json:"device,omitempty"`
 }
 
 type LoginOutput struct {
-	User      *GetUserOutput `json:"user"`
-	AuthToken string         `json:"auth_token"`
+	User         *GetUserOutput `json:"user"`
+	AuthToken    string         `json:"auth_token"`
+	RefreshToken string         `json:"refresh_token"`
+	TokenType    string         `json:"token_type"`
+	IssuedAt     time.Time      `json:"issued_at"`
+	ExpiresAt    time.Time      `json:"expires_at"`
+}
+
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type RefreshTokenOutput struct {
+	AuthToken string `json:"auth_token"`
+}
+
+// PermissionsOutput lists what the caller's role may do, for clients deciding
+// which actions to offer
+type PermissionsOutput struct {
+	Role        string                  `json:"role"`
+	Permissions []middleware.Permission `json:"permissions"`
 }
 
 type GetUserInput struct {
 	ID    *uuid.UUID `json:"id"`
-	Email *string    `json:"email""`
+	Email *string    `json:"email"`
 }
 
 type UpdateUserInput struct {
@@ -30,16 +57,39 @@ type UpdateUserInput struct {
 	Password *string   `json:"password"`
 }
 
+// ChangePasswordInput changes the caller's password, proving they know the
+// current one
+type ChangePasswordInput struct {
+	UserID          uuid.UUID `json:"-"`
+	CurrentPassword string    `json:"current_password" validate:"required"`
+	NewPassword     string    `json:"new_password" validate:"required,min=8,password"`
+}
+
 type ListUsersInput struct {
 	Offset int    `json:"offset" validate:"min=0"`
 	Limit  int    `json:"limit" validate:"required,min=1,max=100"`
-	Sort   string `json:"sort" validate:"oneof=asc desc"`
-	SortBy string `json:"sort_by" validate:"oneof=name email role"`
-	Role   string `json:"role" validate:"oneof=employee employer"`
-	Status string `json:"status" validate:"oneof=active inactive"`
+	Sort   string `json:"sort" validate:"omitempty,oneof=asc desc"`
+	SortBy string `json:"sort_by" validate:"omitempty,oneof=name email role created_at"`
+	Role   string `json:"role" validate:"omitempty,oneof=employee manager employer"`
+	Status string `json:"status" validate:"omitempty,oneof=active inactive"`
 	Search string `json:"search"`
 }
 
+// SearchUsersInput looks up users by name prefix, e.g. for @-mention autocomplete
+type SearchUsersInput struct {
+	Query string `json:"q" validate:"required"`
+	Limit int    `json:"limit" validate:"required,min=1"`
+	// SharedWith restricts results to users sharing a chat room with this user
+	SharedWith *uuid.UUID `json:"shared_with,omitempty"`
+}
+
+// ListActiveUsersInput lists users seen within the given window
+type ListActiveUsersInput struct {
+	// Within defaults to 15 minutes when zero
+	Within time.Duration `json:"within"`
+	Limit  int           `json:"limit" validate:"required,min=1"`
+}
+
 type GetUserOutput struct {
 	ID     uuid.UUID `json:"id"`
 	Name   string    `json:"name"`
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+	"github.com/personal/task-management/pkg/apperrors"
+)
+
+// AdminHandler handles administrative HTTP requests
+type AdminHandler struct {
+	rbacService middleware.CasbinRBACService
+}
+
+// NewAdminHandler creates a new AdminHandler instance
+func NewAdminHandler(rbacService middleware.CasbinRBACService) *AdminHandler {
+	return &AdminHandler{
+		rbacService: rbacService,
+	}
+}
+
+// ReloadPolicies godoc
+// @Summary Reload access control policies
+// @Description Reloads the Casbin policy from the database so changes made elsewhere take effect on this instance
+// @Tags admin
+// @Security ApiKeyAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} apperrors.AppError "Unauthorized"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /admin/policies/reload [post]
+func (h *AdminHandler) ReloadPolicies(w http.ResponseWriter, r *http.Request) {
+	if err := h.rbacService.ReloadPolicy(); err != nil {
+		apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to reload policies"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListPolicies godoc
+// @Summary List access control policies
+// @Description Lists every stored role/resource/action policy
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} middleware.Policy
+// @Failure 401 {object} apperrors.AppError "Unauthorized"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /admin/policies [get]
+func (h *AdminHandler) ListPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.rbacService.Policies()
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to list policies"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policies)
+}
+
+// AddPolicy godoc
+// @Summary Add an access control policy
+// @Description Grants a role an action on a resource. Takes effect on this instance immediately and on others when they reload.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param policy body middleware.Policy true "Policy to add"
+// @Success 200 {object} middleware.Policy "Policy already existed"
+// @Success 201 {object} middleware.Policy "Policy added"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 401 {object} apperrors.AppError "Unauthorized"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /admin/policies [post]
+func (h *AdminHandler) AddPolicy(w http.ResponseWriter, r *http.Request) {
+	var policy middleware.Policy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	added, err := h.rbacService.AddPolicy(policy)
+	if err != nil {
+		writePolicyError(w, err, "Failed to add policy")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if added {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(policy)
+}
+
+// RemovePolicy godoc
+// @Summary Remove an access control policy
+// @Description Revokes a role's action on a resource. Employers' admin policies can't be removed.
+// @Tags admin
+// @Security ApiKeyAuth
+// @Param role query string true "Role"
+// @Param resource query string true "Resource"
+// @Param action query string true "Action" Enums(create, read, update, delete)
+// @Success 204 "No Content"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 401 {object} apperrors.AppError "Unauthorized"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 404 {object} apperrors.AppError "Not Found"
+// @Failure 409 {object} apperrors.AppError "Conflict"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /admin/policies [delete]
+func (h *AdminHandler) RemovePolicy(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	policy := middleware.Policy{
+		Role:     query.Get("role"),
+		Resource: query.Get("resource"),
+		Action:   query.Get("action"),
+	}
+
+	removed, err := h.rbacService.RemovePolicy(policy)
+	if err != nil {
+		writePolicyError(w, err, "Failed to remove policy")
+		return
+	}
+	if !removed {
+		apperrors.WriteError(w, apperrors.NewNotFoundError("Policy not found"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writePolicyError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, middleware.ErrInvalidPolicy):
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+	case errors.Is(err, middleware.ErrProtectedPolicy):
+		apperrors.WriteError(w, apperrors.NewConflictError(err.Error()))
+	default:
+		apperrors.WriteError(w, apperrors.NewInternalServerError(message))
+	}
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+	"github.com/personal/task-management/internal/mocks"
+	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type AdminHandlerTestSuite struct {
+	suite.Suite
+	ctrl        *gomock.Controller
+	rbacService *mocks.MockCasbinRBACService
+	handler     *AdminHandler
+}
+
+func (suite *AdminHandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.rbacService = mocks.NewMockCasbinRBACService(suite.ctrl)
+	suite.handler = NewAdminHandler(suite.rbacService)
+}
+
+func (suite *AdminHandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func (suite *AdminHandlerTestSuite) reload() *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	suite.handler.ReloadPolicies(rec, httptest.NewRequest(http.MethodPost, "/admin/policies/reload", nil))
+	return rec
+}
+
+func (suite *AdminHandlerTestSuite) TestReloadPolicies() {
+	suite.rbacService.EXPECT().ReloadPolicy().Return(nil)
+
+	rec := suite.reload()
+	suite.Equal(http.StatusNoContent, rec.Code)
+}
+
+func (suite *AdminHandlerTestSuite) TestReloadPoliciesFailure() {
+	suite.rbacService.EXPECT().ReloadPolicy().Return(errors.New("connection refused"))
+
+	rec := suite.reload()
+	suite.Equal(http.StatusInternalServerError, rec.Code)
+	suite.NotContains(rec.Body.String(), "connection refused")
+}
+
+func (suite *AdminHandlerTestSuite) TestAddPolicyRejectsInvalidPolicy() {
+	suite.rbacService.EXPECT().AddPolicy(gomock.Any()).
+		Return(false, errors.Join(middleware.ErrInvalidPolicy, errors.New("unknown resource")))
+
+	rec := httptest.NewRecorder()
+	body := `{"role":"employee","resource":"taks","action":"read"}`
+	suite.handler.AddPolicy(rec, httptest.NewRequest(http.MethodPost, "/admin/policies", strings.NewReader(body)))
+	suite.Equal(http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	suite.handler.AddPolicy(rec, httptest.NewRequest(http.MethodPost, "/admin/policies", strings.NewReader("{")))
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *AdminHandlerTestSuite) TestRemovePolicyErrors() {
+	remove := func() int {
+		rec := httptest.NewRecorder()
+		suite.handler.RemovePolicy(rec, httptest.NewRequest(http.MethodDelete, "/admin/policies?role=employer&resource=admin&action=read", nil))
+		return rec.Code
+	}
+	policy := middleware.Policy{Role: "employer", Resource: "admin", Action: "read"}
+
+	suite.rbacService.EXPECT().RemovePolicy(policy).Return(false, middleware.ErrProtectedPolicy)
+	suite.Equal(http.StatusConflict, remove())
+	suite.rbacService.EXPECT().RemovePolicy(policy).Return(false, nil)
+	suite.Equal(http.StatusNotFound, remove())
+	suite.rbacService.EXPECT().RemovePolicy(policy).Return(false, errors.New("connection refused"))
+	suite.Equal(http.StatusInternalServerError, remove())
+}
+
+// TestPoliciesTakeEffect drives the handler against a real enforcer, so a
+// policy added through it is what AuthorizationMiddleware enforces
+func (suite *AdminHandlerTestSuite) TestPoliciesTakeEffect() {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	suite.Require().NoError(err)
+	sqlDB, err := db.DB()
+	suite.Require().NoError(err)
+	sqlDB.SetMaxOpenConns(1)
+	defer sqlDB.Close()
+	cfg := viper.New()
+	cfg.Set("casbin.model_path", "../../../../config/rbac_model.conf")
+	rbacService, err := middleware.NewCasbinRBACService(cfg, db)
+	suite.Require().NoError(err)
+	handler := NewAdminHandler(rbacService)
+
+	readTemplates := func() int {
+		guarded := middleware.AuthorizationMiddleware(nil, rbacService)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/api/tasks/templates", nil)
+		req = req.WithContext(middleware.WithClaims(req.Context(), &jwt.UserClaims{UserID: uuid.New(), Role: "employee"}))
+		rec := httptest.NewRecorder()
+		guarded(rec, req)
+		return rec.Code
+	}
+	suite.Equal(http.StatusForbidden, readTemplates())
+
+	body := `{"role":"employee","resource":"task_templates","action":"read"}`
+	rec := httptest.NewRecorder()
+	handler.AddPolicy(rec, httptest.NewRequest(http.MethodPost, "/admin/policies", strings.NewReader(body)))
+	suite.Equal(http.StatusCreated, rec.Code)
+	suite.Equal(http.StatusOK, readTemplates())
+
+	// Adding it again changes nothing
+	rec = httptest.NewRecorder()
+	handler.AddPolicy(rec, httptest.NewRequest(http.MethodPost, "/admin/policies", strings.NewReader(body)))
+	suite.Equal(http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ListPolicies(rec, httptest.NewRequest(http.MethodGet, "/admin/policies", nil))
+	suite.Equal(http.StatusOK, rec.Code)
+	var policies []middleware.Policy
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &policies))
+	suite.Contains(policies, middleware.Policy{Role: "employee", Resource: "task_templates", Action: "read"})
+
+	rec = httptest.NewRecorder()
+	handler.RemovePolicy(rec, httptest.NewRequest(http.MethodDelete, "/admin/policies?role=employee&resource=task_templates&action=read", nil))
+	suite.Equal(http.StatusNoContent, rec.Code)
+	suite.Equal(http.StatusForbidden, readTemplates())
+}
+
+func TestAdminHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(AdminHandlerTestSuite))
+}
@@ -5,10 +5,15 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
 	"github.com/personal/task-management/internal/domain/user"
 	"github.com/personal/task-management/internal/usecase"
 	"github.com/personal/task-management/pkg/apperrors"
+	"github.com/personal/task-management/pkg/utils/jwt"
 )
 
 type AuthHandler struct {
@@ -38,6 +43,9 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid request body"))
 		return
 	}
+	if input.Device == "" {
+		input.Device = r.UserAgent()
+	}
 
 	// Authenticate the user
 	authUser, err := h.userService.Login(r.Context(), input)
@@ -61,7 +69,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param registerUserInput body dtos.RegisterUserInput true "Register user input"
 // @Success 201 {object} dtos.GetUserOutput "Register response"
 // @Failure 400 {object} apperrors.AppError "Bad Request"
@@ -79,9 +87,12 @@ func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	// Register the user
 	newUser, err := h.userService.RegisterUser(r.Context(), input)
 	if err != nil {
+		var validationErrors validator.ValidationErrors
 		switch {
 		case errors.Is(err, user.ErrEmailExists):
 			apperrors.WriteError(w, apperrors.NewConflictError("Email already exists"))
+		case errors.As(err, &validationErrors):
+			apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
 		default:
 			apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to register user"))
 		}
@@ -92,3 +103,131 @@ func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(newUser)
 }
+
+// godoc RefreshToken
+// @Summary Refresh Token
+// @Description Exchange a refresh token for a new access token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param refreshTokenInput body dtos.RefreshTokenInput true "Refresh token input"
+// @Success 200 {object} dtos.RefreshTokenOutput "Refresh response"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 401 {object} apperrors.AppError "Unauthorized"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /auth/refresh [post]
+func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var input dtos.RefreshTokenInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.RefreshToken == "" {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+
+	output, err := h.userService.RefreshToken(r.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, jwt.ErrExpiredToken):
+			apperrors.WriteError(w, apperrors.NewUnauthorizedError("Refresh token has expired"))
+		case errors.Is(err, jwt.ErrInvalidToken), errors.Is(err, jwt.ErrWrongTokenType), errors.Is(err, jwt.ErrRevokedToken):
+			apperrors.WriteError(w, apperrors.NewUnauthorizedError("Invalid refresh token"))
+		default:
+			apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to refresh token"))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(output)
+}
+
+// godoc Logout
+// @Summary Logout
+// @Description Revoke the current access token and end the session it was issued for
+// @Tags auth
+// @Security ApiKeyAuth
+// @Success 204 "No Content"
+// @Failure 401 {object} apperrors.AppError "Unauthorized"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("Invalid claims"))
+		return
+	}
+
+	if err := h.userService.Logout(r.Context(), claims); err != nil {
+		if errors.Is(err, jwt.ErrInvalidToken) {
+			apperrors.WriteError(w, apperrors.NewUnauthorizedError("Token cannot be revoked"))
+			return
+		}
+		apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to logout"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// godoc ListSessions
+// @Summary List Sessions
+// @Description List the caller's active sessions, most recently used first
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} user.Session "Active sessions"
+// @Failure 401 {object} apperrors.AppError "Unauthorized"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /auth/sessions [get]
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	sessions, err := h.userService.ListSessions(r.Context(), userID)
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to list sessions"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// godoc RevokeSession
+// @Summary Revoke Session
+// @Description Revoke one of the caller's sessions so its refresh token stops working
+// @Tags auth
+// @Security ApiKeyAuth
+// @Param id path string true "Session ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 401 {object} apperrors.AppError "Unauthorized"
+// @Failure 404 {object} apperrors.AppError "Not Found"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid session ID"))
+		return
+	}
+
+	if err := h.userService.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, user.ErrSessionNotFound) {
+			apperrors.WriteError(w, apperrors.NewNotFoundError("Session not found"))
+			return
+		}
+		apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to revoke session"))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+	"github.com/personal/task-management/internal/domain/user"
+	"github.com/personal/task-management/internal/mocks"
+	"github.com/personal/task-management/internal/usecase"
+	localmemory "github.com/personal/task-management/pkg/cache/local-memory"
+	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/personal/task-management/pkg/utils/validate"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuthHandlerTestSuite struct {
+	suite.Suite
+	ctrl        *gomock.Controller
+	userService *mocks.MockUserService
+	handler     *AuthHandler
+}
+
+func (suite *AuthHandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.userService = mocks.NewMockUserService(suite.ctrl)
+	suite.handler = NewAuthHandler(suite.userService)
+}
+
+func (suite *AuthHandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func (suite *AuthHandlerTestSuite) refresh(body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	suite.handler.RefreshToken(rec, httptest.NewRequest(http.MethodPost, "/auth/refresh", strings.NewReader(body)))
+	return rec
+}
+
+func (suite *AuthHandlerTestSuite) TestRefreshTokenReturnsAccessToken() {
+	suite.userService.EXPECT().RefreshToken(gomock.Any(), dtos.RefreshTokenInput{RefreshToken: "refresh"}).
+		Return(&dtos.RefreshTokenOutput{AuthToken: "access"}, nil)
+
+	rec := suite.refresh(`{"refresh_token":"refresh"}`)
+	suite.Equal(http.StatusOK, rec.Code)
+	var body dtos.RefreshTokenOutput
+	suite.NoError(json.NewDecoder(rec.Body).Decode(&body))
+	suite.Equal("access", body.AuthToken)
+}
+
+func (suite *AuthHandlerTestSuite) TestRefreshTokenRejectsUnusableTokens() {
+	for _, err := range []error{jwt.ErrExpiredToken, jwt.ErrInvalidToken, jwt.ErrWrongTokenType, jwt.ErrRevokedToken} {
+		suite.userService.EXPECT().RefreshToken(gomock.Any(), gomock.Any()).Return(nil, err)
+
+		rec := suite.refresh(`{"refresh_token":"token"}`)
+		suite.Equal(http.StatusUnauthorized, rec.Code, err.Error())
+	}
+}
+
+func (suite *AuthHandlerTestSuite) TestRefreshTokenRequiresToken() {
+	rec := suite.refresh(`{}`)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *AuthHandlerTestSuite) TestLogoutRevokesCurrentToken() {
+	claims := &jwt.UserClaims{UserID: uuid.New()}
+	suite.userService.EXPECT().Logout(gomock.Any(), claims).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+	req = req.WithContext(middleware.WithClaims(req.Context(), claims))
+	rec := httptest.NewRecorder()
+	suite.handler.Logout(rec, req)
+	suite.Equal(http.StatusNoContent, rec.Code)
+}
+
+func (suite *AuthHandlerTestSuite) TestLogoutRequiresClaims() {
+	rec := httptest.NewRecorder()
+	suite.handler.Logout(rec, httptest.NewRequest(http.MethodPost, "/auth/logout", nil))
+	suite.Equal(http.StatusUnauthorized, rec.Code)
+}
+
+func (suite *AuthHandlerTestSuite) TestLoggedOutTokenIsRejectedByAuthMiddleware() {
+	cfg := viper.New()
+	cfg.Set("auth.jwt_secret", "test_secret_key")
+	cfg.Set("auth.jwt_expiration", time.Hour)
+	denylist, err := localmemory.NewCache(time.Minute)
+	suite.Require().NoError(err)
+	defer denylist.Close()
+
+	tokens := jwt.NewJWTTokenService(cfg, denylist)
+	handler := NewAuthHandler(usecase.NewUserService(nil, nil, nil, tokens))
+	logout := middleware.Use(handler.Logout, middleware.AuthMiddleware(tokens))
+	protected := middleware.Use(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, middleware.AuthMiddleware(tokens))
+
+	call := func(h http.HandlerFunc, token string) int {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		h(rec, req)
+		return rec.Code
+	}
+
+	userID := uuid.New()
+	token, err := tokens.GenerateToken(userID, "test@example.com", "employee")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, call(protected, token))
+
+	suite.Equal(http.StatusNoContent, call(logout, token))
+	suite.Equal(http.StatusUnauthorized, call(protected, token))
+	suite.Equal(http.StatusUnauthorized, call(logout, token))
+
+	fresh, err := tokens.GenerateToken(userID, "test@example.com", "employee")
+	suite.Require().NoError(err)
+	suite.Equal(http.StatusOK, call(protected, fresh))
+}
+
+func (suite *AuthHandlerTestSuite) TestRegisterRejectsInvalidInput() {
+	// The service validates, so its errors are real validator errors
+	input := dtos.RegisterUserInput{Email: "alice@example.com", Name: "Alice", Password: "password", Role: "employee"}
+	err := validate.Struct(input)
+	suite.Require().Error(err)
+	suite.userService.EXPECT().RegisterUser(gomock.Any(), input).Return(nil, err)
+
+	rec := httptest.NewRecorder()
+	body := `{"email":"alice@example.com","name":"Alice","password":"password","role":"employee"}`
+	suite.handler.RegisterUser(rec, httptest.NewRequest(http.MethodPost, "/auth/register", strings.NewReader(body)))
+	suite.Equal(http.StatusBadRequest, rec.Code)
+	suite.Contains(rec.Body.String(), "'password' tag")
+}
+
+func (suite *AuthHandlerTestSuite) TestLoginDefaultsDeviceToUserAgent() {
+	suite.userService.EXPECT().Login(gomock.Any(), dtos.LoginInput{Email: "alice@example.com", Password: "secret", Device: "curl/8.0"}).
+		Return(&dtos.LoginOutput{AuthToken: "access"}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"email":"alice@example.com","password":"secret"}`))
+	req.Header.Set("User-Agent", "curl/8.0")
+	rec := httptest.NewRecorder()
+	suite.handler.Login(rec, req)
+	suite.Equal(http.StatusOK, rec.Code)
+}
+
+// sessionRequest builds a request for the sessions routes made by userID
+func sessionRequest(method, sessionID string, userID uuid.UUID) *http.Request {
+	req := httptest.NewRequest(method, "/auth/sessions/"+sessionID, nil)
+	ctx := middleware.WithClaims(req.Context(), &jwt.UserClaims{UserID: userID})
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("id", sessionID)
+	return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, routeCtx))
+}
+
+func (suite *AuthHandlerTestSuite) TestListSessions() {
+	userID := uuid.New()
+	sessions := []*user.Session{
+		{ID: uuid.New(), UserID: userID, Device: "phone"},
+		{ID: uuid.New(), UserID: userID, Device: "laptop"},
+	}
+	suite.userService.EXPECT().ListSessions(gomock.Any(), userID).Return(sessions, nil)
+
+	rec := httptest.NewRecorder()
+	suite.handler.ListSessions(rec, sessionRequest(http.MethodGet, "", userID))
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var body []user.Session
+	suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&body))
+	suite.Require().Len(body, 2)
+	suite.Equal(sessions[0].ID, body[0].ID)
+	suite.Equal("laptop", body[1].Device)
+}
+
+func (suite *AuthHandlerTestSuite) TestRevokeSession() {
+	userID, sessionID := uuid.New(), uuid.New()
+	suite.userService.EXPECT().RevokeSession(gomock.Any(), userID, sessionID).Return(nil)
+
+	rec := httptest.NewRecorder()
+	suite.handler.RevokeSession(rec, sessionRequest(http.MethodDelete, sessionID.String(), userID))
+	suite.Equal(http.StatusNoContent, rec.Code)
+}
+
+func (suite *AuthHandlerTestSuite) TestRevokeUnknownSession() {
+	userID, sessionID := uuid.New(), uuid.New()
+	suite.userService.EXPECT().RevokeSession(gomock.Any(), userID, sessionID).Return(user.ErrSessionNotFound)
+
+	rec := httptest.NewRecorder()
+	suite.handler.RevokeSession(rec, sessionRequest(http.MethodDelete, sessionID.String(), userID))
+	suite.Equal(http.StatusNotFound, rec.Code)
+}
+
+func (suite *AuthHandlerTestSuite) TestRevokeSessionRejectsInvalidID() {
+	rec := httptest.NewRecorder()
+	suite.handler.RevokeSession(rec, sessionRequest(http.MethodDelete, "not-a-uuid", uuid.New()))
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *AuthHandlerTestSuite) TestSessionsRequireUser() {
+	rec := httptest.NewRecorder()
+	suite.handler.ListSessions(rec, httptest.NewRequest(http.MethodGet, "/auth/sessions", nil))
+	suite.Equal(http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	suite.handler.RevokeSession(rec, httptest.NewRequest(http.MethodDelete, "/auth/sessions/x", nil))
+	suite.Equal(http.StatusUnauthorized, rec.Code)
+}
+
+func TestAuthHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthHandlerTestSuite))
+}
@@ -2,13 +2,21 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+	"github.com/personal/task-management/internal/domain"
+	"github.com/personal/task-management/internal/domain/user"
+	"github.com/personal/task-management/internal/repositories"
 	"github.com/personal/task-management/internal/usecase"
 	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/personal/task-management/pkg/utils/validate"
 )
 
 // ChatHandler handles chat-related HTTP requests
@@ -28,27 +36,40 @@ func NewChatHandler(wsService usecase.WebSocketService, jwtService jwt.JWTTokenS
 
 // CreateDirectRoom godoc
 // @Summary Create a direct chat room between two users
-// @Description Creates a new direct chat room between the authenticated user and another user
+// @Description Returns the direct chat room between the authenticated user and another user, creating it on first contact
 // @Tags chat
 // @Accept json
 // @Produce json
 // @Param request body dtos.CreateDirectRoomRequest true "Create Direct Room Request"
 // @Success 200 {object} interface{} "Room created successfully"
-// @Failure 400 {string} string "Invalid request body"
+// @Failure 400 {string} string "Invalid request body or fields, naming each field that failed, or the other user is the caller"
 // @Failure 500 {string} string "Internal server error"
 // @Security ApiKeyAuth
 // @Router /chat/direct [post]
 func (h *ChatHandler) CreateDirectRoom(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("user_id").(string)
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+	userID := callerID.String()
 
 	var req dtos.CreateDirectRoomRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
 		return
 	}
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	room, err := h.wsService.CreateDirectRoom(userID, req.UserID2)
 	if err != nil {
+		if errors.Is(err, domain.ErrSelfDirectRoom) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -63,8 +84,9 @@ func (h *ChatHandler) CreateDirectRoom(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param request body dtos.CreateGroupRoomRequest true "Create Group Room Request"
+// @Param Idempotency-Key header string false "Repeat a creation safely; retries with the same key return the same room"
 // @Success 200 {object} interface{} "Room created successfully"
-// @Failure 400 {string} string "Invalid request body"
+// @Failure 400 {string} string "Invalid request body or fields, naming each field that failed"
 // @Failure 500 {string} string "Internal server error"
 // @Security ApiKeyAuth
 // @Router /chat/group [post]
@@ -74,8 +96,22 @@ func (h *ChatHandler) CreateGroupRoom(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
 		return
 	}
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	room, err := h.wsService.CreateGroupRoom(req.Name, req.UserIDs)
+	// Scope the key to the caller so clients cannot collide with each other
+	var creatorID string
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if claims, ok := middleware.UserFromContext(r.Context()); ok {
+		creatorID = claims.UserID.String()
+		if idempotencyKey != "" {
+			idempotencyKey = creatorID + ":" + idempotencyKey
+		}
+	}
+
+	room, err := h.wsService.CreateGroupRoom(req.Name, creatorID, req.UserIDs, idempotencyKey)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -86,48 +122,350 @@ func (h *ChatHandler) CreateGroupRoom(w http.ResponseWriter, r *http.Request) {
 
 // ListRooms godoc
 // @Summary List all chat rooms for the authenticated user
-// @Description Returns a list of all chat rooms the authenticated user is a member of
+// @Description Returns the chat rooms the authenticated user is a member of, most recently active first, optionally narrowed by type, archived or muted state and name
 // @Tags chat
 // @Produce json
+// @Param type query string false "Only rooms of this type" Enums(direct, group)
+// @Param archived query boolean false "Only archived (true) or unarchived (false) rooms"
+// @Param muted query boolean false "Only muted (true) or unmuted (false) rooms"
+// @Param search query string false "Match a substring of the room name, ignoring case"
+// @Param limit query integer false "Number of rooms to return" default(20)
+// @Param offset query integer false "Number of rooms to skip" default(0)
 // @Success 200 {array} interface{} "List of chat rooms"
+// @Failure 400 {string} string "Invalid filter"
 // @Failure 500 {string} string "Internal server error"
 // @Security ApiKeyAuth
 // @Router /chat/rooms [get]
 func (h *ChatHandler) ListRooms(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("user_id").(string)
-	rooms, err := h.wsService.ListRooms(userID)
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+	userID := callerID.String()
+
+	query := r.URL.Query()
+	filter := repositories.RoomFilter{
+		Type:   query.Get("type"),
+		Search: query.Get("search"),
+	}
+	filter.Limit, _ = strconv.Atoi(query.Get("limit"))
+	filter.Offset, _ = strconv.Atoi(query.Get("offset"))
+	var err error
+	if filter.IsArchived, err = queryBool(r, "archived"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.IsMuted, err = queryBool(r, "muted"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rooms, err := h.wsService.ListRooms(userID, filter)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRoomType) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	json.NewEncoder(w).Encode(rooms)
 }
 
+// queryBool reads an optional boolean query parameter, nil when it is absent
+func queryBool(r *http.Request, name string) (*bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: must be true or false", name)
+	}
+	return &parsed, nil
+}
+
+// SearchMessages godoc
+// @Summary Search messages across rooms
+// @Description Finds messages containing the query, ignoring case, in every room the authenticated user belongs to, newest first
+// @Tags chat
+// @Produce json
+// @Param q query string true "Text to search for"
+// @Param limit query integer false "Number of results to return" default(20)
+// @Param offset query integer false "Number of results to skip" default(0)
+// @Success 200 {array} domain.MessageSearchResult "Matching messages with their room"
+// @Failure 400 {string} string "Empty search query"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /chat/search [get]
+func (h *ChatHandler) SearchMessages(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	results, err := h.wsService.SearchAllMessages(callerID.String(), r.URL.Query().Get("q"), limit, offset)
+	if err != nil {
+		if errors.Is(err, domain.ErrEmptySearchQuery) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if results == nil {
+		results = []*domain.MessageSearchResult{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// GetUnreadCounts godoc
+// @Summary Get unread message counts for all rooms
+// @Description Returns the number of unread messages in each of the authenticated user's rooms, keyed by room ID
+// @Tags chat
+// @Produce json
+// @Success 200 {object} map[string]int "Unread message count per room"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /chat/unread-counts [get]
+func (h *ChatHandler) GetUnreadCounts(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	counts, err := h.wsService.GetUnreadCounts(claims.UserID.String())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(counts)
+}
+
+// statsDateLayout is the format of the from and to chat statistics parameters
+const statsDateLayout = "2006-01-02"
+
+// defaultStatsDays is how many days chat statistics cover when no range is given
+const defaultStatsDays = 7
+
+// GetChatStats godoc
+// @Summary Get aggregate chat statistics
+// @Description Returns total rooms and messages, active connections, messages per day and the busiest rooms within a date range. Employers only.
+// @Tags admin
+// @Produce json
+// @Param from query string false "First day of the range (YYYY-MM-DD), defaults to 7 days before to"
+// @Param to query string false "Last day of the range (YYYY-MM-DD), defaults to today"
+// @Param top query integer false "Number of busiest rooms to return" default(5)
+// @Success 200 {object} domain.ChatStats "Chat statistics"
+// @Failure 400 {string} string "Invalid range"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /admin/chat-stats [get]
+func (h *ChatHandler) GetChatStats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	// Both ends are whole UTC days; to is inclusive
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if value := query.Get("to"); value != "" {
+		parsed, err := time.Parse(statsDateLayout, value)
+		if err != nil {
+			http.Error(w, "invalid to date", http.StatusBadRequest)
+			return
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, 1-defaultStatsDays)
+	if value := query.Get("from"); value != "" {
+		parsed, err := time.Parse(statsDateLayout, value)
+		if err != nil {
+			http.Error(w, "invalid from date", http.StatusBadRequest)
+			return
+		}
+		from = parsed
+	}
+
+	top := 0
+	if value := query.Get("top"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			http.Error(w, "invalid top", http.StatusBadRequest)
+			return
+		}
+		top = parsed
+	}
+
+	stats, err := h.wsService.GetChatStats(from, to.AddDate(0, 0, 1), top)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidStatsRange) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// SetRoomSlowMode godoc
+// @Summary Set a chat room's slow mode
+// @Description Sets the minimum number of seconds between a user's messages in the room. Zero turns slow mode off. Employers only.
+// @Tags admin
+// @Accept json
+// @Param roomId path string true "Room ID"
+// @Param request body dtos.SetSlowModeRequest true "Slow mode interval"
+// @Success 204 "Slow mode updated"
+// @Failure 400 {string} string "Invalid interval"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Room not found"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /admin/chat/rooms/{roomId}/slow-mode [put]
+func (h *ChatHandler) SetRoomSlowMode(w http.ResponseWriter, r *http.Request) {
+	var req dtos.SetSlowModeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := h.wsService.SetRoomSlowMode(chi.URLParam(r, "roomId"), time.Duration(req.Seconds)*time.Second)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidSlowMode):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, domain.ErrRoomNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetRoom godoc
+// @Summary Get a chat room
+// @Description Returns a chat room's details: its name, description, members, admins, latest message, pinned messages and the authenticated user's unread count. Only members may see a room.
+// @Tags chat
+// @Produce json
+// @Param roomId path string true "Room ID"
+// @Success 200 {object} domain.Room "Room details"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not a member of the room"
+// @Failure 404 {string} string "Room not found"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /chat/rooms/{roomId} [get]
+func (h *ChatHandler) GetRoom(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	room, err := h.wsService.GetRoomDetails(chi.URLParam(r, "roomId"), callerID.String())
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUserNotInRoom):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, domain.ErrRoomNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(room)
+}
+
 // GetRoomHistory godoc
 // @Summary Get chat room history
 // @Description Retrieves the message history for a specific chat room
 // @Tags chat
 // @Produce json
 // @Param roomId path string true "Room ID"
-// @Param limit query integer false "Number of messages to return" default(50)
+// @Param limit query integer false "Number of messages to return" default(20)
 // @Param offset query integer false "Number of messages to skip" default(0)
+// @Param order query string false "Order offset pages are returned in; before pages are always newest first" Enums(asc, desc) default(desc)
+// @Param before query string false "Return messages older than this message ID"
 // @Success 200 {object} interface{} "Room history"
+// @Failure 400 {string} string "Requested more messages than allowed, or an invalid order"
+// @Failure 403 {string} string "Not a member of the room"
+// @Failure 404 {string} string "Room or message not found"
 // @Failure 500 {string} string "Internal server error"
 // @Security ApiKeyAuth
 // @Router /chat/rooms/{roomId}/history [get]
 func (h *ChatHandler) GetRoomHistory(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
 	roomID := chi.URLParam(r, "roomId")
 	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
 	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
-	room, err := h.wsService.GetRoomHistory(roomID, limit, offset)
+
+	// A cursor switches to keyset pagination, which stays stable while new messages arrive
+	if before, ok := r.URL.Query()["before"]; ok {
+		messages, hasMore, err := h.wsService.GetRoomHistoryBefore(roomID, callerID.String(), before[0], limit)
+		if err != nil {
+			writeHistoryError(w, err)
+			return
+		}
+
+		json.NewEncoder(w).Encode(dtos.RoomHistoryResponse{Messages: messages, HasMore: hasMore})
+		return
+	}
+
+	order, err := domain.ParseHistoryOrder(r.URL.Query().Get("order"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	room, err := h.wsService.GetRoomHistory(roomID, callerID.String(), limit, offset, order)
+	if err != nil {
+		writeHistoryError(w, err)
 		return
 	}
 
 	json.NewEncoder(w).Encode(room)
 }
 
+// writeHistoryError maps an error from reading a room's history to a status
+func writeHistoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotInRoom):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, domain.ErrMessageNotFound), errors.Is(err, domain.ErrRoomNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrHistoryLimitExceeded):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 // JoinRoom godoc
 // @Summary Join a chat room
 // @Description Adds the authenticated user to a chat room
@@ -138,7 +476,12 @@ func (h *ChatHandler) GetRoomHistory(w http.ResponseWriter, r *http.Request) {
 // @Security ApiKeyAuth
 // @Router /chat/rooms/{roomId}/join [post]
 func (h *ChatHandler) JoinRoom(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("user_id").(string)
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+	userID := callerID.String()
 	roomID := chi.URLParam(r, "roomId")
 
 	if err := h.wsService.JoinRoom(roomID, userID); err != nil {
@@ -159,7 +502,12 @@ func (h *ChatHandler) JoinRoom(w http.ResponseWriter, r *http.Request) {
 // @Security ApiKeyAuth
 // @Router /chat/rooms/{roomId}/leave [post]
 func (h *ChatHandler) LeaveRoom(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("user_id").(string)
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+	userID := callerID.String()
 	roomID := chi.URLParam(r, "roomId")
 
 	if err := h.wsService.LeaveRoom(roomID, userID); err != nil {
@@ -170,19 +518,63 @@ func (h *ChatHandler) LeaveRoom(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// DeleteRoom godoc
+// @Summary Delete a chat room
+// @Description Deletes a chat room along with its members, messages and read statuses. Only the room's creator or an employer may delete it. Online members receive a room_deleted event.
+// @Tags chat
+// @Param roomId path string true "Room ID"
+// @Success 204 "Room deleted"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not the room's creator or an employer"
+// @Failure 404 {string} string "Room not found"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /chat/rooms/{roomId} [delete]
+func (h *ChatHandler) DeleteRoom(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	isEmployer := user.ParseRole(claims.Role) == user.Employer
+	err := h.wsService.DeleteRoom(chi.URLParam(r, "roomId"), claims.UserID.String(), isEmployer)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotRoomCreator):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, domain.ErrRoomNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // UpdateRoom godoc
 // @Summary Update chat room information
-// @Description Updates the name, description, or avatar of a chat room
+// @Description Updates the name, description, or avatar of a chat room. Group rooms can only be updated by their admins or an employer.
 // @Tags chat
 // @Accept json
 // @Param roomId path string true "Room ID"
 // @Param request body dtos.UpdateRoomRequest true "Update Room Request"
 // @Success 200 "Room updated successfully"
-// @Failure 400 {string} string "Invalid request body"
+// @Failure 400 {string} string "Invalid request body or fields, naming each field that failed"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not a room admin or an employer"
+// @Failure 404 {string} string "Room not found"
 // @Failure 500 {string} string "Internal server error"
 // @Security ApiKeyAuth
 // @Router /chat/rooms/{roomId} [put]
 func (h *ChatHandler) UpdateRoom(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
 	roomID := chi.URLParam(r, "roomId")
 
 	var req dtos.UpdateRoomRequest
@@ -190,9 +582,15 @@ func (h *ChatHandler) UpdateRoom(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
 		return
 	}
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	if err := h.wsService.UpdateRoomInfo(roomID, req.Name, req.Description, req.AvatarURL); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	isEmployer := user.ParseRole(claims.Role) == user.Employer
+	err := h.wsService.UpdateRoomInfo(roomID, claims.UserID.String(), req.Name, req.Description, req.AvatarURL, isEmployer)
+	if err != nil {
+		writeMemberChangeError(w, err)
 		return
 	}
 
@@ -205,20 +603,35 @@ func (h *ChatHandler) UpdateRoom(w http.ResponseWriter, r *http.Request) {
 // @Tags chat
 // @Produce json
 // @Param roomId path string true "Room ID"
-// @Param limit query integer false "Number of messages to return" default(50)
+// @Param limit query integer false "Number of messages to return" default(20)
 // @Param offset query integer false "Number of messages to skip" default(0)
+// @Param order query string false "Order messages are paginated in" Enums(asc, desc) default(desc)
 // @Success 200 {array} interface{} "List of messages"
+// @Failure 400 {string} string "Requested more messages than allowed, or an invalid order"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not a member of the room"
+// @Failure 404 {string} string "Room not found"
 // @Failure 500 {string} string "Internal server error"
 // @Security ApiKeyAuth
 // @Router /chat/rooms/{roomId}/messages [get]
 func (h *ChatHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
 	roomID := chi.URLParam(r, "roomId")
 	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
 	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	order, err := domain.ParseHistoryOrder(r.URL.Query().Get("order"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	messages, err := h.wsService.GetRoomHistory(roomID, limit, offset)
+	messages, err := h.wsService.GetRoomHistory(roomID, callerID.String(), limit, offset, order)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeHistoryError(w, err)
 		return
 	}
 
@@ -227,18 +640,28 @@ func (h *ChatHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
 
 // SendMessage godoc
 // @Summary Send a message to a chat room
-// @Description Sends a message to a specific chat room
+// @Description Sends a message to a specific chat room. Attachments need a URL, a size within chat.max_file_size and an allowed type; image, video and audio messages only take their own kind of file.
 // @Tags chat
 // @Accept json
+// @Produce json
 // @Param roomId path string true "Room ID"
 // @Param request body dtos.SendMessageRequest true "Send Message Request"
-// @Success 200 "Message sent successfully"
-// @Failure 400 {string} string "Invalid request body"
+// @Success 201 {object} domain.Message "Message sent successfully"
+// @Failure 400 {string} string "Invalid request body or attachment"
+// @Failure 403 {string} string "Not a member of the room"
+// @Failure 404 {string} string "Room not found"
+// @Failure 413 {string} string "Content is longer than chat.max_message_length"
+// @Failure 429 {string} string "Slow mode is on or the sender is rate limited; retry after the Retry-After header's seconds"
 // @Failure 500 {string} string "Internal server error"
 // @Security ApiKeyAuth
 // @Router /chat/rooms/{roomId}/messages [post]
 func (h *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("user_id").(string)
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+	userID := callerID.String()
 	roomID := chi.URLParam(r, "roomId")
 
 	var req dtos.SendMessageRequest
@@ -246,29 +669,161 @@ func (h *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
 		return
 	}
+	if req.Type == "" {
+		req.Type = domain.MessageTypeText
+	}
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	var err error
+	var (
+		message *domain.Message
+		err     error
+	)
 	switch req.Type {
-	case "text":
-		err = h.wsService.SendGroupMessage(roomID, userID, req.Content)
-	case "file":
-		err = h.wsService.SendFileMessage(roomID, userID, req.FileURL, "", 0, "")
-	case "image":
-		err = h.wsService.SendImageMessage(roomID, userID, req.FileURL, "")
-	case "video":
-		err = h.wsService.SendVideoMessage(roomID, userID, req.FileURL, "", 0)
-	case "audio":
-		err = h.wsService.SendAudioMessage(roomID, userID, req.FileURL, 0)
+	case domain.MessageTypeFile:
+		message, err = h.wsService.SendFileMessage(roomID, userID, req.FileURL, req.FileName, req.FileSize, req.FileType)
+	case domain.MessageTypeImage:
+		message, err = h.wsService.SendImageMessage(roomID, userID, req.FileURL, req.ThumbnailURL, req.FileSize, req.FileType)
+	case domain.MessageTypeVideo:
+		message, err = h.wsService.SendVideoMessage(roomID, userID, req.FileURL, req.ThumbnailURL, req.FileSize, req.FileType, req.Duration)
+	case domain.MessageTypeAudio:
+		message, err = h.wsService.SendAudioMessage(roomID, userID, req.FileURL, req.FileSize, req.FileType, req.Duration)
 	default:
-		err = h.wsService.SendGroupMessage(roomID, userID, req.Content)
+		message, err = h.wsService.SendGroupMessage(roomID, userID, req.Content)
 	}
 
 	if err != nil {
+		if writeThrottled(w, err) {
+			return
+		}
+		if errors.Is(err, domain.ErrMessageTooLong) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidMessage) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, domain.ErrUserNotInRoom) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, domain.ErrRoomNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(message)
+}
+
+// writeThrottled answers a message rejected by slow mode or the sender's rate
+// limit with 429 and a Retry-After header, reporting whether it did
+func writeThrottled(w http.ResponseWriter, err error) bool {
+	var retryAfter time.Duration
+	var slowMode *domain.SlowModeError
+	var rateLimit *domain.RateLimitError
+	switch {
+	case errors.As(err, &slowMode):
+		retryAfter = slowMode.RetryAfter()
+	case errors.As(err, &rateLimit):
+		retryAfter = rateLimit.RetryAfter()
+	default:
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter/time.Second)))
+	http.Error(w, err.Error(), http.StatusTooManyRequests)
+	return true
+}
+
+// SendDirectMessage godoc
+// @Summary Send a direct message to a user
+// @Description Sends a message to another user, creating the direct room on first contact
+// @Tags chat
+// @Accept json
+// @Produce json
+// @Param userId path string true "Receiver user ID"
+// @Param request body dtos.SendDirectMessageRequest true "Send Direct Message Request"
+// @Success 201 {object} domain.Message "Message sent successfully"
+// @Failure 400 {string} string "Invalid request body, or the receiver is the sender"
+// @Failure 413 {string} string "Content is longer than chat.max_message_length"
+// @Failure 429 {string} string "The sender is rate limited; retry after the Retry-After header's seconds"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /chat/direct/{userId}/messages [post]
+func (h *ChatHandler) SendDirectMessage(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+	receiverID := chi.URLParam(r, "userId")
+
+	var req dtos.SendDirectMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Content == "" {
+		http.Error(w, "content is required", http.StatusBadRequest)
+		return
+	}
+
+	message, err := h.wsService.SendDirectMessage(claims.UserID.String(), receiverID, req.Content)
+	if err != nil {
+		if writeThrottled(w, err) {
+			return
+		}
+		if errors.Is(err, domain.ErrMessageTooLong) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if errors.Is(err, domain.ErrSelfDirectRoom) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(message)
+}
+
+// GetDirectRoomID godoc
+// @Summary Get the direct room ID for a user
+// @Description Returns the ID of the direct room between the authenticated user and another user without creating it, and whether it exists yet
+// @Tags chat
+// @Produce json
+// @Param userId path string true "Other user's ID"
+// @Success 200 {object} dtos.DirectRoomIDResponse "Direct room ID"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /chat/direct/{userId}/room-id [get]
+func (h *ChatHandler) GetDirectRoomID(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	roomID, exists, err := h.wsService.GetDirectRoomID(callerID.String(), chi.URLParam(r, "userId"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dtos.DirectRoomIDResponse{RoomID: roomID, Exists: exists})
 }
 
 // MarkMessageAsRead godoc
@@ -282,7 +837,12 @@ func (h *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
 // @Security ApiKeyAuth
 // @Router /chat/rooms/{roomId}/messages/{messageId}/read [post]
 func (h *ChatHandler) MarkMessageAsRead(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("user_id").(string)
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+	userID := callerID.String()
 	roomID := chi.URLParam(r, "roomId")
 	messageID := chi.URLParam(r, "messageId")
 
@@ -294,6 +854,44 @@ func (h *ChatHandler) MarkMessageAsRead(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 }
 
+// GetReadReceipts godoc
+// @Summary List who has read a message
+// @Description Lists the users who have read a message, earliest first
+// @Tags chat
+// @Produce json
+// @Param roomId path string true "Room ID"
+// @Param messageId path string true "Message ID"
+// @Success 200 {array} domain.ReadReceipt "Read receipts"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not a member of the room"
+// @Failure 404 {string} string "Room or message not found"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /chat/rooms/{roomId}/messages/{messageId}/read-receipts [get]
+func (h *ChatHandler) GetReadReceipts(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	receipts, err := h.wsService.GetReadReceipts(chi.URLParam(r, "roomId"), callerID.String(), chi.URLParam(r, "messageId"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUserNotInRoom):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, domain.ErrMessageNotFound), errors.Is(err, domain.ErrRoomNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(receipts)
+}
+
 // PinMessage godoc
 // @Summary Pin a message in a chat room
 // @Description Pins a specific message in a chat room
@@ -301,21 +899,67 @@ func (h *ChatHandler) MarkMessageAsRead(w http.ResponseWriter, r *http.Request)
 // @Param roomId path string true "Room ID"
 // @Param messageId path string true "Message ID"
 // @Success 200 "Message pinned successfully"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not a member of the room"
+// @Failure 404 {string} string "Room or message not found"
+// @Failure 409 {string} string "The room has as many pinned messages as allowed"
 // @Failure 500 {string} string "Internal server error"
 // @Security ApiKeyAuth
 // @Router /chat/rooms/{roomId}/messages/{messageId}/pin [post]
 func (h *ChatHandler) PinMessage(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
 	roomID := chi.URLParam(r, "roomId")
 	messageID := chi.URLParam(r, "messageId")
 
-	if err := h.wsService.PinMessage(roomID, messageID); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.wsService.PinMessage(roomID, callerID.String(), messageID); err != nil {
+		writePinError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
+// GetPinnedMessages godoc
+// @Summary List a chat room's pinned messages
+// @Description Returns the full pinned messages of a room in the order they were pinned. Only members may see them.
+// @Tags chat
+// @Produce json
+// @Param roomId path string true "Room ID"
+// @Success 200 {array} domain.Message "Pinned messages"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not a member of the room"
+// @Failure 404 {string} string "Room not found"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /chat/rooms/{roomId}/pinned [get]
+func (h *ChatHandler) GetPinnedMessages(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	messages, err := h.wsService.GetPinnedMessages(chi.URLParam(r, "roomId"), callerID.String())
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUserNotInRoom):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, domain.ErrRoomNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(messages)
+}
+
 // UnpinMessage godoc
 // @Summary Unpin a message in a chat room
 // @Description Unpins a specific message in a chat room
@@ -323,21 +967,43 @@ func (h *ChatHandler) PinMessage(w http.ResponseWriter, r *http.Request) {
 // @Param roomId path string true "Room ID"
 // @Param messageId path string true "Message ID"
 // @Success 200 "Message unpinned successfully"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not a member of the room"
+// @Failure 404 {string} string "Room not found"
 // @Failure 500 {string} string "Internal server error"
 // @Security ApiKeyAuth
 // @Router /chat/rooms/{roomId}/messages/{messageId}/unpin [post]
 func (h *ChatHandler) UnpinMessage(w http.ResponseWriter, r *http.Request) {
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
 	roomID := chi.URLParam(r, "roomId")
 	messageID := chi.URLParam(r, "messageId")
 
-	if err := h.wsService.UnpinMessage(roomID, messageID); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.wsService.UnpinMessage(roomID, callerID.String(), messageID); err != nil {
+		writePinError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
+// writePinError maps an error from pinning or unpinning a message to a status
+func writePinError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrPinLimitReached):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, domain.ErrUserNotInRoom):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, domain.ErrRoomNotFound), errors.Is(err, domain.ErrMessageNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 // ArchiveRoom godoc
 // @Summary Archive a chat room
 // @Description Archives a specific chat room for the authenticated user
@@ -348,7 +1014,12 @@ func (h *ChatHandler) UnpinMessage(w http.ResponseWriter, r *http.Request) {
 // @Security ApiKeyAuth
 // @Router /chat/rooms/{roomId}/archive [post]
 func (h *ChatHandler) ArchiveRoom(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("user_id").(string)
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+	userID := callerID.String()
 	roomID := chi.URLParam(r, "roomId")
 
 	if err := h.wsService.ArchiveRoom(roomID, userID); err != nil {
@@ -369,7 +1040,12 @@ func (h *ChatHandler) ArchiveRoom(w http.ResponseWriter, r *http.Request) {
 // @Security ApiKeyAuth
 // @Router /chat/rooms/{roomId}/unarchive [post]
 func (h *ChatHandler) UnarchiveRoom(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("user_id").(string)
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+	userID := callerID.String()
 	roomID := chi.URLParam(r, "roomId")
 
 	if err := h.wsService.UnarchiveRoom(roomID, userID); err != nil {
@@ -390,7 +1066,12 @@ func (h *ChatHandler) UnarchiveRoom(w http.ResponseWriter, r *http.Request) {
 // @Security ApiKeyAuth
 // @Router /chat/rooms/{roomId}/mute [post]
 func (h *ChatHandler) MuteRoom(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("user_id").(string)
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+	userID := callerID.String()
 	roomID := chi.URLParam(r, "roomId")
 
 	if err := h.wsService.MuteRoom(roomID, userID); err != nil {
@@ -411,7 +1092,12 @@ func (h *ChatHandler) MuteRoom(w http.ResponseWriter, r *http.Request) {
 // @Security ApiKeyAuth
 // @Router /chat/rooms/{roomId}/unmute [post]
 func (h *ChatHandler) UnmuteRoom(w http.ResponseWriter, r *http.Request) {
-	userID := r.Context().Value("user_id").(string)
+	callerID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+	userID := callerID.String()
 	roomID := chi.URLParam(r, "roomId")
 
 	if err := h.wsService.UnmuteRoom(roomID, userID); err != nil {
@@ -421,3 +1107,261 @@ func (h *ChatHandler) UnmuteRoom(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// ListMembers godoc
+// @Summary List the members of a chat room
+// @Description Returns the IDs of the users in a chat room
+// @Tags chat
+// @Produce json
+// @Param roomId path string true "Room ID"
+// @Success 200 {object} dtos.RoomMembersResponse "Room members"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not a member of the room"
+// @Failure 404 {string} string "Room not found"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /chat/rooms/{roomId}/members [get]
+func (h *ChatHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	members, err := h.wsService.ListRoomMembers(chi.URLParam(r, "roomId"), userID.String())
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUserNotInRoom):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, domain.ErrRoomNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dtos.RoomMembersResponse{UserIDs: members})
+}
+
+// AddMember godoc
+// @Summary Add a member to a group chat room
+// @Description Adds a user to a group room. Only the room's admins or an employer may add members. The room's members receive a member_joined event.
+// @Tags chat
+// @Accept json
+// @Param roomId path string true "Room ID"
+// @Param request body dtos.AddRoomMemberRequest true "User to add"
+// @Success 204 "Member added"
+// @Failure 400 {string} string "Invalid request body or not a group room"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not a room admin or an employer"
+// @Failure 404 {string} string "Room not found"
+// @Failure 409 {string} string "User already in room"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /chat/rooms/{roomId}/members [post]
+func (h *ChatHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	var req dtos.AddRoomMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	isEmployer := user.ParseRole(claims.Role) == user.Employer
+	err := h.wsService.AddMember(chi.URLParam(r, "roomId"), claims.UserID.String(), req.UserID, isEmployer)
+	if err != nil {
+		writeMemberChangeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveMember godoc
+// @Summary Remove a member from a group chat room
+// @Description Removes a user from a group room. Only the room's admins or an employer may remove members; members leave on their own through the leave endpoint. The room's members and the removed user receive a member_left event.
+// @Tags chat
+// @Param roomId path string true "Room ID"
+// @Param userId path string true "ID of the member to remove"
+// @Success 204 "Member removed"
+// @Failure 400 {string} string "Not a group room"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not a room admin or an employer"
+// @Failure 404 {string} string "Room not found or user not in room"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /chat/rooms/{roomId}/members/{userId} [delete]
+func (h *ChatHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	isEmployer := user.ParseRole(claims.Role) == user.Employer
+	err := h.wsService.RemoveMember(chi.URLParam(r, "roomId"), claims.UserID.String(), chi.URLParam(r, "userId"), isEmployer)
+	if err != nil {
+		writeMemberChangeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PromoteMember godoc
+// @Summary Make a member a group room admin
+// @Description Lets a member of a group room rename it and manage its members. Only the room's admins or an employer may promote members.
+// @Tags chat
+// @Param roomId path string true "Room ID"
+// @Param userId path string true "ID of the member to promote"
+// @Success 204 "Member promoted"
+// @Failure 400 {string} string "Not a group room"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not a room admin or an employer"
+// @Failure 404 {string} string "Room not found or user not in room"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /chat/rooms/{roomId}/members/{userId}/promote [post]
+func (h *ChatHandler) PromoteMember(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	isEmployer := user.ParseRole(claims.Role) == user.Employer
+	err := h.wsService.PromoteToAdmin(chi.URLParam(r, "roomId"), claims.UserID.String(), chi.URLParam(r, "userId"), isEmployer)
+	if err != nil {
+		writeMemberChangeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DemoteMember godoc
+// @Summary Make a group room admin a plain member
+// @Description Takes admin rights away from a member of a group room. Only the room's admins or an employer may demote admins, and the last admin cannot be demoted.
+// @Tags chat
+// @Param roomId path string true "Room ID"
+// @Param userId path string true "ID of the admin to demote"
+// @Success 204 "Member demoted"
+// @Failure 400 {string} string "Not a group room"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not a room admin or an employer"
+// @Failure 404 {string} string "Room not found or user not in room"
+// @Failure 409 {string} string "Last admin of the room"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /chat/rooms/{roomId}/members/{userId}/demote [post]
+func (h *ChatHandler) DemoteMember(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	isEmployer := user.ParseRole(claims.Role) == user.Employer
+	err := h.wsService.DemoteMember(chi.URLParam(r, "roomId"), claims.UserID.String(), chi.URLParam(r, "userId"), isEmployer)
+	if err != nil {
+		writeMemberChangeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeMemberChangeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidRoomType):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrNotRoomAdmin):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, domain.ErrRoomNotFound), errors.Is(err, domain.ErrUserNotInRoom):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrUserAlreadyInRoom), errors.Is(err, domain.ErrLastRoomAdmin):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// MuteMember godoc
+// @Summary Mute a member of a chat room
+// @Description Stops a member's messages in the room from pushing notifications to the authenticated user. Their messages are still stored and delivered live.
+// @Tags chat
+// @Param roomId path string true "Room ID"
+// @Param userId path string true "ID of the member to mute"
+// @Success 204 "Member muted"
+// @Failure 400 {string} string "Cannot mute yourself"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Room not found or user not in room"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /chat/rooms/{roomId}/members/{userId}/mute [post]
+func (h *ChatHandler) MuteMember(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	err := h.wsService.MuteRoomMember(chi.URLParam(r, "roomId"), claims.UserID.String(), chi.URLParam(r, "userId"))
+	if err != nil {
+		writeMemberMuteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnmuteMember godoc
+// @Summary Unmute a member of a chat room
+// @Description Lets a member's messages in the room notify the authenticated user again
+// @Tags chat
+// @Param roomId path string true "Room ID"
+// @Param userId path string true "ID of the member to unmute"
+// @Success 204 "Member unmuted"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Room not found or user not in room"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /chat/rooms/{roomId}/members/{userId}/mute [delete]
+func (h *ChatHandler) UnmuteMember(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	err := h.wsService.UnmuteRoomMember(chi.URLParam(r, "roomId"), claims.UserID.String(), chi.URLParam(r, "userId"))
+	if err != nil {
+		writeMemberMuteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeMemberMuteError maps member mute errors to HTTP responses
+func writeMemberMuteError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrCannotMuteSelf):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrRoomNotFound), errors.Is(err, domain.ErrUserNotInRoom):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
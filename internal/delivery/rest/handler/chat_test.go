@@ -0,0 +1,832 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+	"github.com/personal/task-management/internal/domain"
+	"github.com/personal/task-management/internal/mocks"
+	"github.com/personal/task-management/internal/repositories"
+	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/stretchr/testify/suite"
+)
+
+type ChatHandlerTestSuite struct {
+	suite.Suite
+	ctrl       *gomock.Controller
+	wsService  *mocks.MockWebSocketService
+	jwtService *mocks.MockJWTTokenServicer
+	handler    *ChatHandler
+	userID     uuid.UUID
+}
+
+func (suite *ChatHandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.wsService = mocks.NewMockWebSocketService(suite.ctrl)
+	suite.jwtService = mocks.NewMockJWTTokenServicer(suite.ctrl)
+	suite.handler = NewChatHandler(suite.wsService, suite.jwtService)
+	suite.userID = uuid.New()
+}
+
+func (suite *ChatHandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// newRequest builds a request routed through chi so URL params resolve
+func (suite *ChatHandlerTestSuite) newRequest(method, pattern, target, body string, handlerFunc http.HandlerFunc) *httptest.ResponseRecorder {
+	router := chi.NewRouter()
+	router.Method(method, pattern, handlerFunc)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req = req.WithContext(middleware.WithClaims(req.Context(), &jwt.UserClaims{UserID: suite.userID}))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func (suite *ChatHandlerTestSuite) TestSendMessageReturnsCreatedMessage() {
+	created := &domain.Message{
+		ID:        "message-1",
+		RoomID:    "room-1",
+		UserID:    suite.userID.String(),
+		Content:   "hello",
+		Type:      domain.MessageTypeText,
+		Status:    domain.MessageStatusSent,
+		CreatedAt: time.Now(),
+	}
+	suite.wsService.EXPECT().SendGroupMessage("room-1", suite.userID.String(), "hello").Return(created, nil)
+
+	rec := suite.newRequest(http.MethodPost, "/rooms/{roomId}/messages", "/rooms/room-1/messages",
+		`{"content":"hello","type":"text"}`, suite.handler.SendMessage)
+
+	suite.Equal(http.StatusCreated, rec.Code)
+	var body domain.Message
+	suite.NoError(json.NewDecoder(rec.Body).Decode(&body))
+	suite.Equal("message-1", body.ID)
+	suite.Equal(domain.MessageStatusSent, body.Status)
+}
+
+func (suite *ChatHandlerTestSuite) TestSendMessageByType() {
+	created := &domain.Message{ID: "message-1", RoomID: "room-1", UserID: suite.userID.String()}
+	tests := []struct {
+		name   string
+		body   string
+		expect func()
+	}{
+		{
+			name: "text",
+			body: `{"content":"hello","type":"text"}`,
+			expect: func() {
+				suite.wsService.EXPECT().SendGroupMessage("room-1", suite.userID.String(), "hello").Return(created, nil)
+			},
+		},
+		{
+			name: "default type is text",
+			body: `{"content":"hello"}`,
+			expect: func() {
+				suite.wsService.EXPECT().SendGroupMessage("room-1", suite.userID.String(), "hello").Return(created, nil)
+			},
+		},
+		{
+			name: "file",
+			body: `{"type":"file","file_url":"https://example.com/report.pdf","file_name":"report.pdf","file_size":2048,"file_type":"application/pdf"}`,
+			expect: func() {
+				suite.wsService.EXPECT().SendFileMessage("room-1", suite.userID.String(), "https://example.com/report.pdf", "report.pdf", int64(2048), "application/pdf").Return(created, nil)
+			},
+		},
+		{
+			name: "image",
+			body: `{"type":"image","file_url":"https://example.com/cat.png","thumbnail_url":"https://example.com/cat-thumb.png","file_size":4096,"file_type":"image/png"}`,
+			expect: func() {
+				suite.wsService.EXPECT().SendImageMessage("room-1", suite.userID.String(), "https://example.com/cat.png", "https://example.com/cat-thumb.png", int64(4096), "image/png").Return(created, nil)
+			},
+		},
+		{
+			name: "video",
+			body: `{"type":"video","file_url":"https://example.com/demo.mp4","thumbnail_url":"https://example.com/demo.jpg","file_size":8192,"file_type":"video/mp4","duration":90}`,
+			expect: func() {
+				suite.wsService.EXPECT().SendVideoMessage("room-1", suite.userID.String(), "https://example.com/demo.mp4", "https://example.com/demo.jpg", int64(8192), "video/mp4", 90).Return(created, nil)
+			},
+		},
+		{
+			name: "audio",
+			body: `{"type":"audio","file_url":"https://example.com/memo.ogg","file_size":1024,"file_type":"audio/ogg","duration":15}`,
+			expect: func() {
+				suite.wsService.EXPECT().SendAudioMessage("room-1", suite.userID.String(), "https://example.com/memo.ogg", int64(1024), "audio/ogg", 15).Return(created, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			tt.expect()
+			rec := suite.newRequest(http.MethodPost, "/rooms/{roomId}/messages", "/rooms/room-1/messages",
+				tt.body, suite.handler.SendMessage)
+			suite.Equal(http.StatusCreated, rec.Code)
+		})
+	}
+}
+
+func (suite *ChatHandlerTestSuite) TestSendMessageRejectsInvalidRequests() {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "unknown type", body: `{"content":"hello","type":"sticker"}`},
+		{name: "text without content", body: `{"type":"text"}`},
+		{name: "file without url", body: `{"type":"file","file_name":"report.pdf"}`},
+		{name: "negative duration", body: `{"type":"audio","file_url":"https://example.com/memo.ogg","duration":-1}`},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			rec := suite.newRequest(http.MethodPost, "/rooms/{roomId}/messages", "/rooms/room-1/messages",
+				tt.body, suite.handler.SendMessage)
+			suite.Equal(http.StatusBadRequest, rec.Code)
+		})
+	}
+}
+
+func (suite *ChatHandlerTestSuite) TestSendMessageRejectsInvalidAttachment() {
+	suite.wsService.EXPECT().SendImageMessage("room-1", suite.userID.String(), "https://example.com/report.pdf", "", int64(2048), "application/pdf").
+		Return(nil, fmt.Errorf("%w: application/pdf files are not allowed in image messages", domain.ErrInvalidMessage))
+
+	rec := suite.newRequest(http.MethodPost, "/rooms/{roomId}/messages", "/rooms/room-1/messages",
+		`{"type":"image","file_url":"https://example.com/report.pdf","file_size":2048,"file_type":"application/pdf"}`, suite.handler.SendMessage)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+	suite.Contains(rec.Body.String(), "not allowed in image messages")
+}
+
+func (suite *ChatHandlerTestSuite) TestGetMessagesOverHistoryLimit() {
+	suite.wsService.EXPECT().GetRoomHistory("room-1", suite.userID.String(), 1000, 0, domain.HistoryOrderNewestFirst).
+		Return(nil, fmt.Errorf("%w: requested 1000 messages, maximum is 500", domain.ErrHistoryLimitExceeded))
+
+	rec := suite.newRequest(http.MethodGet, "/rooms/{roomId}/messages", "/rooms/room-1/messages?limit=1000", "",
+		suite.handler.GetMessages)
+
+	suite.Equal(http.StatusBadRequest, rec.Code)
+	suite.Contains(rec.Body.String(), "maximum is 500")
+}
+
+func (suite *ChatHandlerTestSuite) TestGetRoomHistoryOrder() {
+	tests := []struct {
+		query string
+		order domain.HistoryOrder
+	}{
+		{query: "", order: domain.HistoryOrderNewestFirst},
+		{query: "?order=desc", order: domain.HistoryOrderNewestFirst},
+		{query: "?order=asc", order: domain.HistoryOrderOldestFirst},
+		{query: "?order=ASC", order: domain.HistoryOrderOldestFirst},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.query, func() {
+			suite.wsService.EXPECT().GetRoomHistory("room-1", suite.userID.String(), 0, 0, tt.order).
+				Return([]domain.WebSocketMessage{{ID: "message-1"}}, nil)
+
+			rec := suite.newRequest(http.MethodGet, "/rooms/{roomId}/history", "/rooms/room-1/history"+tt.query, "",
+				suite.handler.GetRoomHistory)
+			suite.Equal(http.StatusOK, rec.Code)
+			suite.Contains(rec.Body.String(), "message-1")
+		})
+	}
+}
+
+func (suite *ChatHandlerTestSuite) TestGetRoomHistoryErrors() {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "not a member", err: domain.ErrUserNotInRoom, status: http.StatusForbidden},
+		{name: "unknown room", err: domain.ErrRoomNotFound, status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.wsService.EXPECT().GetRoomHistory("room-1", suite.userID.String(), 0, 0, domain.HistoryOrderNewestFirst).
+				Return(nil, tt.err).Times(2)
+
+			rec := suite.newRequest(http.MethodGet, "/rooms/{roomId}/history", "/rooms/room-1/history", "",
+				suite.handler.GetRoomHistory)
+			suite.Equal(tt.status, rec.Code)
+
+			rec = suite.newRequest(http.MethodGet, "/rooms/{roomId}/messages", "/rooms/room-1/messages", "",
+				suite.handler.GetMessages)
+			suite.Equal(tt.status, rec.Code)
+		})
+	}
+}
+
+func (suite *ChatHandlerTestSuite) TestGetRoomHistoryRejectsInvalidOrder() {
+	rec := suite.newRequest(http.MethodGet, "/rooms/{roomId}/history", "/rooms/room-1/history?order=sideways", "",
+		suite.handler.GetRoomHistory)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+
+	rec = suite.newRequest(http.MethodGet, "/rooms/{roomId}/messages", "/rooms/room-1/messages?order=sideways", "",
+		suite.handler.GetMessages)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *ChatHandlerTestSuite) TestGetDirectRoomID() {
+	suite.wsService.EXPECT().GetDirectRoomID(suite.userID.String(), "user-2").Return("room-1", true, nil)
+
+	rec := suite.newRequest(http.MethodGet, "/direct/{userId}/room-id", "/direct/user-2/room-id", "",
+		suite.handler.GetDirectRoomID)
+
+	suite.Equal(http.StatusOK, rec.Code)
+	var body dtos.DirectRoomIDResponse
+	suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&body))
+	suite.Equal(dtos.DirectRoomIDResponse{RoomID: "room-1", Exists: true}, body)
+}
+
+func (suite *ChatHandlerTestSuite) TestGetReadReceipts() {
+	readAt := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	receipts := []domain.ReadReceipt{{UserID: "user-2", ReadAt: readAt}, {UserID: "user-3", ReadAt: readAt.Add(time.Minute)}}
+	suite.wsService.EXPECT().GetReadReceipts("room-1", suite.userID.String(), "message-1").Return(receipts, nil)
+
+	rec := suite.newRequest(http.MethodGet, "/rooms/{roomId}/messages/{messageId}/read-receipts",
+		"/rooms/room-1/messages/message-1/read-receipts", "", suite.handler.GetReadReceipts)
+
+	suite.Equal(http.StatusOK, rec.Code)
+	var body []domain.ReadReceipt
+	suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&body))
+	suite.Equal(receipts, body)
+}
+
+func (suite *ChatHandlerTestSuite) TestGetReadReceiptsForUnknownMessage() {
+	suite.wsService.EXPECT().GetReadReceipts("room-1", suite.userID.String(), "missing").Return(nil, domain.ErrMessageNotFound)
+
+	rec := suite.newRequest(http.MethodGet, "/rooms/{roomId}/messages/{messageId}/read-receipts",
+		"/rooms/room-1/messages/missing/read-receipts", "", suite.handler.GetReadReceipts)
+	suite.Equal(http.StatusNotFound, rec.Code)
+}
+
+func (suite *ChatHandlerTestSuite) TestGetReadReceiptsForNonMember() {
+	suite.wsService.EXPECT().GetReadReceipts("room-1", suite.userID.String(), "message-1").Return(nil, domain.ErrUserNotInRoom)
+
+	rec := suite.newRequest(http.MethodGet, "/rooms/{roomId}/messages/{messageId}/read-receipts",
+		"/rooms/room-1/messages/message-1/read-receipts", "", suite.handler.GetReadReceipts)
+	suite.Equal(http.StatusForbidden, rec.Code)
+}
+
+func (suite *ChatHandlerTestSuite) TestSearchMessages() {
+	results := []*domain.MessageSearchResult{{Message: domain.Message{ID: "message-1", RoomID: "room-1"}, RoomName: "general"}}
+	suite.wsService.EXPECT().SearchAllMessages(suite.userID.String(), "deploy", 5, 10).Return(results, nil)
+
+	rec := suite.newRequest(http.MethodGet, "/search", "/search?q=deploy&limit=5&offset=10", "", suite.handler.SearchMessages)
+
+	suite.Equal(http.StatusOK, rec.Code)
+	var body []domain.MessageSearchResult
+	suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&body))
+	suite.Require().Len(body, 1)
+	suite.Equal("room-1", body[0].RoomID)
+	suite.Equal("general", body[0].RoomName)
+}
+
+func (suite *ChatHandlerTestSuite) TestSearchMessagesRequiresQuery() {
+	suite.wsService.EXPECT().SearchAllMessages(suite.userID.String(), "", 0, 0).Return(nil, domain.ErrEmptySearchQuery)
+
+	rec := suite.newRequest(http.MethodGet, "/search", "/search", "", suite.handler.SearchMessages)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *ChatHandlerTestSuite) TestListRoomsThroughAuthMiddleware() {
+	suite.jwtService.EXPECT().ValidateToken("access").Return(&jwt.UserClaims{UserID: suite.userID}, nil)
+	suite.wsService.EXPECT().ListRooms(suite.userID.String(), repositories.RoomFilter{}).Return([]*domain.Room{{ID: "room-1"}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/chat/rooms", nil)
+	req.Header.Set("Authorization", "Bearer access")
+	rec := httptest.NewRecorder()
+	middleware.Use(suite.handler.ListRooms, middleware.AuthMiddleware(suite.jwtService))(rec, req)
+
+	suite.Equal(http.StatusOK, rec.Code)
+	var rooms []domain.Room
+	suite.NoError(json.NewDecoder(rec.Body).Decode(&rooms))
+	suite.Require().Len(rooms, 1)
+	suite.Equal("room-1", rooms[0].ID)
+}
+
+func (suite *ChatHandlerTestSuite) TestListRoomsFilters() {
+	archived, muted := true, false
+	suite.wsService.EXPECT().ListRooms(suite.userID.String(), repositories.RoomFilter{
+		Type:       domain.RoomTypeGroup,
+		IsArchived: &archived,
+		IsMuted:    &muted,
+		Search:     "ops",
+		Limit:      5,
+		Offset:     10,
+	}).Return([]*domain.Room{{ID: "room-1"}}, nil)
+
+	rec := suite.newRequest(http.MethodGet, "/rooms", "/rooms?type=group&archived=true&muted=false&search=ops&limit=5&offset=10",
+		"", suite.handler.ListRooms)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	rec = suite.newRequest(http.MethodGet, "/rooms", "/rooms?archived=maybe", "", suite.handler.ListRooms)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+
+	suite.wsService.EXPECT().ListRooms(suite.userID.String(), repositories.RoomFilter{Type: "channel"}).
+		Return(nil, domain.ErrInvalidRoomType)
+	rec = suite.newRequest(http.MethodGet, "/rooms", "/rooms?type=channel", "", suite.handler.ListRooms)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *ChatHandlerTestSuite) TestHandlersWithoutUserAreUnauthorized() {
+	handlers := map[string]http.HandlerFunc{
+		"create direct room": suite.handler.CreateDirectRoom,
+		"list rooms":         suite.handler.ListRooms,
+		"join room":          suite.handler.JoinRoom,
+		"send message":       suite.handler.SendMessage,
+		"unread counts":      suite.handler.GetUnreadCounts,
+		"mute member":        suite.handler.MuteMember,
+		"direct room id":     suite.handler.GetDirectRoomID,
+		"search messages":    suite.handler.SearchMessages,
+		"delete room":        suite.handler.DeleteRoom,
+		"get room":           suite.handler.GetRoom,
+	}
+
+	for name, handlerFunc := range handlers {
+		suite.Run(name, func() {
+			rec := httptest.NewRecorder()
+			suite.NotPanics(func() {
+				handlerFunc(rec, httptest.NewRequest(http.MethodPost, "/chat", strings.NewReader(`{}`)))
+			})
+			suite.Equal(http.StatusUnauthorized, rec.Code)
+		})
+	}
+}
+
+func (suite *ChatHandlerTestSuite) TestSendMessageDuringSlowMode() {
+	suite.wsService.EXPECT().SendGroupMessage("room-1", suite.userID.String(), "hello").
+		Return(nil, &domain.SlowModeError{Remaining: 12500 * time.Millisecond})
+
+	rec := suite.newRequest(http.MethodPost, "/rooms/{roomId}/messages", "/rooms/room-1/messages",
+		`{"content":"hello"}`, suite.handler.SendMessage)
+
+	suite.Equal(http.StatusTooManyRequests, rec.Code)
+	suite.Equal("13", rec.Header().Get("Retry-After"))
+	suite.Contains(rec.Body.String(), "wait 13s")
+}
+
+func (suite *ChatHandlerTestSuite) TestSendDirectMessageWhenRateLimited() {
+	suite.wsService.EXPECT().SendDirectMessage(suite.userID.String(), "user-2", "hello").
+		Return(nil, &domain.RateLimitError{Remaining: 800 * time.Millisecond})
+
+	rec := suite.newRequest(http.MethodPost, "/direct/{userId}/messages", "/direct/user-2/messages",
+		`{"content":"hello"}`, suite.handler.SendDirectMessage)
+
+	suite.Equal(http.StatusTooManyRequests, rec.Code)
+	suite.Equal("1", rec.Header().Get("Retry-After"))
+	suite.Contains(rec.Body.String(), domain.ErrRateLimited.Error())
+}
+
+func (suite *ChatHandlerTestSuite) TestSendMessageTooLong() {
+	suite.wsService.EXPECT().SendGroupMessage("room-1", suite.userID.String(), "hello").
+		Return(nil, fmt.Errorf("%w: 5 characters, at most 4 allowed", domain.ErrMessageTooLong))
+
+	rec := suite.newRequest(http.MethodPost, "/rooms/{roomId}/messages", "/rooms/room-1/messages",
+		`{"content":"hello"}`, suite.handler.SendMessage)
+
+	suite.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+	suite.Contains(rec.Body.String(), "at most 4 allowed")
+}
+
+func (suite *ChatHandlerTestSuite) TestSendMessageOutsideRoom() {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "not a member", err: domain.ErrUserNotInRoom, status: http.StatusForbidden},
+		{name: "unknown room", err: domain.ErrRoomNotFound, status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.wsService.EXPECT().SendFileMessage("room-1", suite.userID.String(), "https://example.com/a.pdf", "a.pdf", int64(2048), "application/pdf").
+				Return(nil, tt.err)
+
+			rec := suite.newRequest(http.MethodPost, "/rooms/{roomId}/messages", "/rooms/room-1/messages",
+				`{"type":"file","file_url":"https://example.com/a.pdf","file_name":"a.pdf","file_size":2048,"file_type":"application/pdf"}`, suite.handler.SendMessage)
+			suite.Equal(tt.status, rec.Code)
+		})
+	}
+}
+
+func (suite *ChatHandlerTestSuite) TestSetRoomSlowMode() {
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		status int
+	}{
+		{name: "set", body: `{"seconds":30}`, status: http.StatusNoContent},
+		{name: "off", body: `{"seconds":0}`, status: http.StatusNoContent},
+		{name: "negative", body: `{"seconds":-1}`, status: http.StatusBadRequest},
+		{name: "too long", body: `{"seconds":7200}`, status: http.StatusBadRequest},
+		{name: "unknown room", body: `{"seconds":30}`, err: domain.ErrRoomNotFound, status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			if tt.status != http.StatusBadRequest {
+				var seconds struct{ Seconds int }
+				suite.Require().NoError(json.Unmarshal([]byte(tt.body), &seconds))
+				suite.wsService.EXPECT().SetRoomSlowMode("room-1", time.Duration(seconds.Seconds)*time.Second).Return(tt.err)
+			}
+
+			rec := suite.newRequest(http.MethodPut, "/rooms/{roomId}/slow-mode", "/rooms/room-1/slow-mode",
+				tt.body, suite.handler.SetRoomSlowMode)
+			suite.Equal(tt.status, rec.Code)
+		})
+	}
+}
+
+func (suite *ChatHandlerTestSuite) TestDeleteRoom() {
+	tests := []struct {
+		name       string
+		role       string
+		isEmployer bool
+		err        error
+		status     int
+	}{
+		{name: "creator", role: "employee", status: http.StatusNoContent},
+		{name: "employer", role: "employer", isEmployer: true, status: http.StatusNoContent},
+		{name: "not the creator", role: "employee", err: domain.ErrNotRoomCreator, status: http.StatusForbidden},
+		{name: "unknown room", role: "employee", err: domain.ErrRoomNotFound, status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.wsService.EXPECT().DeleteRoom("room-1", suite.userID.String(), tt.isEmployer).Return(tt.err)
+
+			router := chi.NewRouter()
+			router.Delete("/rooms/{roomId}", suite.handler.DeleteRoom)
+			req := httptest.NewRequest(http.MethodDelete, "/rooms/room-1", nil)
+			req = req.WithContext(middleware.WithClaims(req.Context(), &jwt.UserClaims{UserID: suite.userID, Role: tt.role}))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			suite.Equal(tt.status, rec.Code)
+		})
+	}
+}
+
+func (suite *ChatHandlerTestSuite) TestListMembers() {
+	suite.wsService.EXPECT().ListRoomMembers("room-1", suite.userID.String()).Return([]string{"user-1", "user-2"}, nil)
+
+	rec := suite.newRequest(http.MethodGet, "/rooms/{roomId}/members", "/rooms/room-1/members", "", suite.handler.ListMembers)
+
+	suite.Equal(http.StatusOK, rec.Code)
+	var response dtos.RoomMembersResponse
+	suite.NoError(json.NewDecoder(rec.Body).Decode(&response))
+	suite.Equal([]string{"user-1", "user-2"}, response.UserIDs)
+}
+
+func (suite *ChatHandlerTestSuite) TestListMembersErrors() {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "not a member", err: domain.ErrUserNotInRoom, status: http.StatusForbidden},
+		{name: "unknown room", err: domain.ErrRoomNotFound, status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.wsService.EXPECT().ListRoomMembers("room-1", suite.userID.String()).Return(nil, tt.err)
+
+			rec := suite.newRequest(http.MethodGet, "/rooms/{roomId}/members", "/rooms/room-1/members", "", suite.handler.ListMembers)
+
+			suite.Equal(tt.status, rec.Code)
+		})
+	}
+}
+
+func (suite *ChatHandlerTestSuite) TestAddMember() {
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		status int
+	}{
+		{name: "added", body: `{"user_id":"user-2"}`, status: http.StatusNoContent},
+		{name: "missing user", body: `{}`, status: http.StatusBadRequest},
+		{name: "direct room", body: `{"user_id":"user-2"}`, err: domain.ErrInvalidRoomType, status: http.StatusBadRequest},
+		{name: "not an admin", body: `{"user_id":"user-2"}`, err: domain.ErrNotRoomAdmin, status: http.StatusForbidden},
+		{name: "already a member", body: `{"user_id":"user-2"}`, err: domain.ErrUserAlreadyInRoom, status: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			if tt.body != `{}` {
+				suite.wsService.EXPECT().AddMember("room-1", suite.userID.String(), "user-2", false).Return(tt.err)
+			}
+
+			rec := suite.newRequest(http.MethodPost, "/rooms/{roomId}/members", "/rooms/room-1/members", tt.body, suite.handler.AddMember)
+			suite.Equal(tt.status, rec.Code)
+		})
+	}
+}
+
+func (suite *ChatHandlerTestSuite) TestRemoveMember() {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "removed", status: http.StatusNoContent},
+		{name: "not a member", err: domain.ErrUserNotInRoom, status: http.StatusNotFound},
+		{name: "not an admin", err: domain.ErrNotRoomAdmin, status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.wsService.EXPECT().RemoveMember("room-1", suite.userID.String(), "user-2", false).Return(tt.err)
+
+			rec := suite.newRequest(http.MethodDelete, "/rooms/{roomId}/members/{userId}", "/rooms/room-1/members/user-2", "", suite.handler.RemoveMember)
+			suite.Equal(tt.status, rec.Code)
+		})
+	}
+}
+
+func (suite *ChatHandlerTestSuite) TestUpdateRoomAsPlainMember() {
+	suite.wsService.EXPECT().UpdateRoomInfo("room-1", suite.userID.String(), "Renamed", "", "", false).Return(domain.ErrNotRoomAdmin)
+
+	rec := suite.newRequest(http.MethodPut, "/rooms/{roomId}", "/rooms/room-1", `{"name":"Renamed"}`, suite.handler.UpdateRoom)
+	suite.Equal(http.StatusForbidden, rec.Code)
+}
+
+func (suite *ChatHandlerTestSuite) TestRoomRequestsAreValidated() {
+	tests := []struct {
+		name        string
+		method      string
+		pattern     string
+		target      string
+		body        string
+		handlerFunc http.HandlerFunc
+		field       string
+	}{
+		{name: "direct room malformed body", method: http.MethodPost, pattern: "/direct", target: "/direct",
+			body: `{"user_id_2":`, handlerFunc: suite.handler.CreateDirectRoom},
+		{name: "direct room empty body", method: http.MethodPost, pattern: "/direct", target: "/direct",
+			body: `{}`, handlerFunc: suite.handler.CreateDirectRoom, field: "UserID2"},
+		{name: "direct room malformed user", method: http.MethodPost, pattern: "/direct", target: "/direct",
+			body: `{"user_id_2":"user-2"}`, handlerFunc: suite.handler.CreateDirectRoom, field: "UserID2"},
+		{name: "group room malformed body", method: http.MethodPost, pattern: "/group", target: "/group",
+			body: `[]`, handlerFunc: suite.handler.CreateGroupRoom},
+		{name: "group room empty body", method: http.MethodPost, pattern: "/group", target: "/group",
+			body: `{}`, handlerFunc: suite.handler.CreateGroupRoom, field: "UserIDs"},
+		{name: "group room without members", method: http.MethodPost, pattern: "/group", target: "/group",
+			body: `{"name":"Team","user_ids":[]}`, handlerFunc: suite.handler.CreateGroupRoom, field: "UserIDs"},
+		{name: "group room malformed member", method: http.MethodPost, pattern: "/group", target: "/group",
+			body:        `{"name":"Team","user_ids":["` + uuid.NewString() + `","user-2"]}`,
+			handlerFunc: suite.handler.CreateGroupRoom, field: "UserIDs[1]"},
+		{name: "group room without name", method: http.MethodPost, pattern: "/group", target: "/group",
+			body: `{"user_ids":["` + uuid.NewString() + `"]}`, handlerFunc: suite.handler.CreateGroupRoom, field: "Name"},
+		{name: "update room malformed body", method: http.MethodPut, pattern: "/rooms/{roomId}", target: "/rooms/room-1",
+			body: `{"name":1}`, handlerFunc: suite.handler.UpdateRoom},
+		{name: "update room malformed avatar", method: http.MethodPut, pattern: "/rooms/{roomId}", target: "/rooms/room-1",
+			body: `{"avatar_url":"not a url"}`, handlerFunc: suite.handler.UpdateRoom, field: "AvatarURL"},
+		{name: "update room name too long", method: http.MethodPut, pattern: "/rooms/{roomId}", target: "/rooms/room-1",
+			body: `{"name":"` + strings.Repeat("a", 101) + `"}`, handlerFunc: suite.handler.UpdateRoom, field: "Name"},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			rec := suite.newRequest(tt.method, tt.pattern, tt.target, tt.body, tt.handlerFunc)
+			suite.Equal(http.StatusBadRequest, rec.Code)
+			if tt.field != "" {
+				suite.Contains(rec.Body.String(), "'"+tt.field+"'")
+			}
+		})
+	}
+}
+
+func (suite *ChatHandlerTestSuite) TestCreateDirectRoomWithSelf() {
+	suite.wsService.EXPECT().CreateDirectRoom(suite.userID.String(), suite.userID.String()).Return(nil, domain.ErrSelfDirectRoom)
+
+	rec := suite.newRequest(http.MethodPost, "/direct", "/direct", `{"user_id_2":"`+suite.userID.String()+`"}`, suite.handler.CreateDirectRoom)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+	suite.Contains(rec.Body.String(), domain.ErrSelfDirectRoom.Error())
+}
+
+func (suite *ChatHandlerTestSuite) TestCreateRoomsWithValidRequests() {
+	otherID := uuid.NewString()
+	suite.wsService.EXPECT().CreateDirectRoom(suite.userID.String(), otherID).Return(&domain.Room{ID: "room-1"}, nil)
+	rec := suite.newRequest(http.MethodPost, "/direct", "/direct", `{"user_id_2":"`+otherID+`"}`, suite.handler.CreateDirectRoom)
+	suite.Equal(http.StatusOK, rec.Code)
+
+	suite.wsService.EXPECT().CreateGroupRoom("Team", suite.userID.String(), []string{otherID}, "").Return(&domain.Room{ID: "room-2"}, nil)
+	rec = suite.newRequest(http.MethodPost, "/group", "/group", `{"name":"Team","user_ids":["`+otherID+`"]}`, suite.handler.CreateGroupRoom)
+	suite.Equal(http.StatusOK, rec.Code)
+}
+
+func (suite *ChatHandlerTestSuite) TestPromoteAndDemoteMember() {
+	suite.wsService.EXPECT().PromoteToAdmin("room-1", suite.userID.String(), "user-2", false).Return(nil)
+	rec := suite.newRequest(http.MethodPost, "/rooms/{roomId}/members/{userId}/promote", "/rooms/room-1/members/user-2/promote", "", suite.handler.PromoteMember)
+	suite.Equal(http.StatusNoContent, rec.Code)
+
+	suite.wsService.EXPECT().DemoteMember("room-1", suite.userID.String(), "user-2", false).Return(domain.ErrLastRoomAdmin)
+	rec = suite.newRequest(http.MethodPost, "/rooms/{roomId}/members/{userId}/demote", "/rooms/room-1/members/user-2/demote", "", suite.handler.DemoteMember)
+	suite.Equal(http.StatusConflict, rec.Code)
+}
+
+func (suite *ChatHandlerTestSuite) TestGetRoom() {
+	createdAt := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	suite.wsService.EXPECT().GetRoomDetails("room-1", suite.userID.String()).Return(&domain.Room{
+		ID:             "room-1",
+		Name:           "Team",
+		Type:           domain.RoomTypeGroup,
+		CreatedBy:      "user-1",
+		Description:    "Release planning",
+		Users:          []string{"user-1", suite.userID.String()},
+		Admins:         []string{"user-1"},
+		LastMessage:    &domain.Message{ID: "message-2", RoomID: "room-1", Content: "ship it", Type: domain.MessageTypeText},
+		UnreadCount:    map[string]int{suite.userID.String(): 2},
+		PinnedMessages: []string{"message-1"},
+		CreatedAt:      createdAt,
+		UpdatedAt:      createdAt,
+	}, nil)
+
+	rec := suite.newRequest(http.MethodGet, "/rooms/{roomId}", "/rooms/room-1", "", suite.handler.GetRoom)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	suite.Equal("application/json", rec.Header().Get("Content-Type"))
+
+	var room map[string]any
+	suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&room))
+	suite.Equal("room-1", room["id"])
+	suite.Equal("Team", room["name"])
+	suite.Equal("group", room["type"])
+	suite.Equal("user-1", room["created_by"])
+	suite.Equal("Release planning", room["description"])
+	suite.Equal([]any{"user-1", suite.userID.String()}, room["users"])
+	suite.Equal([]any{"user-1"}, room["admins"])
+	suite.Equal(map[string]any{suite.userID.String(): float64(2)}, room["unread_count"])
+	suite.Equal([]any{"message-1"}, room["pinned_messages"])
+	suite.Equal("2026-03-10T12:00:00Z", room["created_at"])
+	lastMessage, ok := room["last_message"].(map[string]any)
+	suite.Require().True(ok)
+	suite.Equal("message-2", lastMessage["id"])
+	suite.Equal("ship it", lastMessage["content"])
+}
+
+func (suite *ChatHandlerTestSuite) TestGetRoomErrors() {
+	for err, status := range map[error]int{
+		domain.ErrUserNotInRoom: http.StatusForbidden,
+		domain.ErrRoomNotFound:  http.StatusNotFound,
+	} {
+		suite.wsService.EXPECT().GetRoomDetails("room-1", suite.userID.String()).Return(nil, err)
+		rec := suite.newRequest(http.MethodGet, "/rooms/{roomId}", "/rooms/room-1", "", suite.handler.GetRoom)
+		suite.Equal(status, rec.Code, err.Error())
+	}
+}
+
+func (suite *ChatHandlerTestSuite) TestGetPinnedMessages() {
+	suite.wsService.EXPECT().GetPinnedMessages("room-1", suite.userID.String()).Return([]domain.Message{
+		{ID: "message-2", RoomID: "room-1", Content: "release notes"},
+		{ID: "message-1", RoomID: "room-1", Content: "agenda"},
+	}, nil)
+
+	rec := suite.newRequest(http.MethodGet, "/rooms/{roomId}/pinned", "/rooms/room-1/pinned", "", suite.handler.GetPinnedMessages)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+	var messages []domain.Message
+	suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&messages))
+	suite.Require().Len(messages, 2)
+	suite.Equal("message-2", messages[0].ID)
+	suite.Equal("release notes", messages[0].Content)
+
+	suite.wsService.EXPECT().GetPinnedMessages("room-1", suite.userID.String()).Return(nil, domain.ErrUserNotInRoom)
+	rec = suite.newRequest(http.MethodGet, "/rooms/{roomId}/pinned", "/rooms/room-1/pinned", "", suite.handler.GetPinnedMessages)
+	suite.Equal(http.StatusForbidden, rec.Code)
+}
+
+func (suite *ChatHandlerTestSuite) TestPinMessageOverLimit() {
+	suite.wsService.EXPECT().PinMessage("room-1", suite.userID.String(), "message-1").Return(domain.ErrPinLimitReached)
+
+	rec := suite.newRequest(http.MethodPost, "/rooms/{roomId}/messages/{messageId}/pin", "/rooms/room-1/messages/message-1/pin", "", suite.handler.PinMessage)
+	suite.Equal(http.StatusConflict, rec.Code)
+}
+
+func (suite *ChatHandlerTestSuite) TestPinMessageErrors() {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "not a member", err: domain.ErrUserNotInRoom, status: http.StatusForbidden},
+		{name: "message from another room", err: domain.ErrMessageNotFound, status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.wsService.EXPECT().PinMessage("room-1", suite.userID.String(), "message-1").Return(tt.err)
+			rec := suite.newRequest(http.MethodPost, "/rooms/{roomId}/messages/{messageId}/pin", "/rooms/room-1/messages/message-1/pin", "", suite.handler.PinMessage)
+			suite.Equal(tt.status, rec.Code)
+
+			suite.wsService.EXPECT().UnpinMessage("room-1", suite.userID.String(), "message-1").Return(tt.err)
+			rec = suite.newRequest(http.MethodDelete, "/rooms/{roomId}/messages/{messageId}/pin", "/rooms/room-1/messages/message-1/pin", "", suite.handler.UnpinMessage)
+			suite.Equal(tt.status, rec.Code)
+		})
+	}
+}
+
+func (suite *ChatHandlerTestSuite) TestGetUnreadCounts() {
+	userID := uuid.New()
+	suite.wsService.EXPECT().GetUnreadCounts(userID.String()).Return(map[string]int{"room-1": 3, "room-2": 0}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/chat/unread-counts", nil)
+	req = req.WithContext(middleware.WithClaims(req.Context(), &jwt.UserClaims{UserID: userID}))
+	rec := httptest.NewRecorder()
+	suite.handler.GetUnreadCounts(rec, req)
+
+	suite.Equal(http.StatusOK, rec.Code)
+	var counts map[string]int
+	suite.NoError(json.NewDecoder(rec.Body).Decode(&counts))
+	suite.Equal(map[string]int{"room-1": 3, "room-2": 0}, counts)
+}
+
+func (suite *ChatHandlerTestSuite) TestMuteMember() {
+	userID := uuid.New()
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "muted", status: http.StatusNoContent},
+		{name: "self", err: domain.ErrCannotMuteSelf, status: http.StatusBadRequest},
+		{name: "not a member", err: domain.ErrUserNotInRoom, status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.wsService.EXPECT().MuteRoomMember("room-1", userID.String(), "user-2").Return(tt.err)
+
+			router := chi.NewRouter()
+			router.Post("/rooms/{roomId}/members/{userId}/mute", suite.handler.MuteMember)
+			req := httptest.NewRequest(http.MethodPost, "/rooms/room-1/members/user-2/mute", nil)
+			req = req.WithContext(middleware.WithClaims(req.Context(), &jwt.UserClaims{UserID: userID}))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			suite.Equal(tt.status, rec.Code)
+		})
+	}
+}
+
+func (suite *ChatHandlerTestSuite) TestGetChatStats() {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)
+	suite.wsService.EXPECT().GetChatStats(from, to, 3).Return(&domain.ChatStats{
+		TotalRooms:        2,
+		TotalMessages:     10,
+		ActiveConnections: 1,
+		MessagesPerDay:    []domain.DailyMessageCount{{Date: "2026-03-02", Count: 10}},
+		BusiestRooms:      []domain.RoomActivity{{RoomID: "room-1", Name: "general", MessageCount: 10}},
+	}, nil)
+
+	rec := suite.newRequest(http.MethodGet, "/admin/chat-stats", "/admin/chat-stats?from=2026-03-01&to=2026-03-07&top=3", "", suite.handler.GetChatStats)
+
+	suite.Equal(http.StatusOK, rec.Code)
+	var body domain.ChatStats
+	suite.NoError(json.NewDecoder(rec.Body).Decode(&body))
+	suite.Equal(int64(2), body.TotalRooms)
+	suite.Equal(int64(10), body.TotalMessages)
+	suite.Equal(1, body.ActiveConnections)
+	suite.Len(body.MessagesPerDay, 1)
+	suite.Equal("room-1", body.BusiestRooms[0].RoomID)
+}
+
+func (suite *ChatHandlerTestSuite) TestGetChatStatsDefaultsToLastWeek() {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	suite.wsService.EXPECT().GetChatStats(today.AddDate(0, 0, -6), today.AddDate(0, 0, 1), 0).Return(&domain.ChatStats{}, nil)
+
+	rec := suite.newRequest(http.MethodGet, "/admin/chat-stats", "/admin/chat-stats", "", suite.handler.GetChatStats)
+	suite.Equal(http.StatusOK, rec.Code)
+}
+
+func (suite *ChatHandlerTestSuite) TestGetChatStatsRejectsInvalidParams() {
+	for _, target := range []string{
+		"/admin/chat-stats?from=yesterday",
+		"/admin/chat-stats?to=2026-13-01",
+		"/admin/chat-stats?top=0",
+	} {
+		rec := suite.newRequest(http.MethodGet, "/admin/chat-stats", target, "", suite.handler.GetChatStats)
+		suite.Equal(http.StatusBadRequest, rec.Code, target)
+	}
+
+	suite.wsService.EXPECT().GetChatStats(gomock.Any(), gomock.Any(), 0).Return(nil, domain.ErrInvalidStatsRange)
+	rec := suite.newRequest(http.MethodGet, "/admin/chat-stats", "/admin/chat-stats?from=2026-03-08&to=2026-03-01", "", suite.handler.GetChatStats)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func TestChatHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(ChatHandlerTestSuite))
+}
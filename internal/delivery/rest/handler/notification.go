@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+	"github.com/personal/task-management/internal/domain"
+	"github.com/personal/task-management/internal/usecase"
+	"github.com/personal/task-management/pkg/utils/validate"
+)
+
+// NotificationHandler handles notification-related HTTP requests
+type NotificationHandler struct {
+	wsService usecase.WebSocketService
+}
+
+// NewNotificationHandler creates a new NotificationHandler instance
+func NewNotificationHandler(wsService usecase.WebSocketService) *NotificationHandler {
+	return &NotificationHandler{
+		wsService: wsService,
+	}
+}
+
+// ListNotifications godoc
+// @Summary List notifications for the authenticated user
+// @Description Returns a page of the authenticated user's notifications, newest first
+// @Tags notifications
+// @Produce json
+// @Param limit query integer false "Number of notifications to return" default(20)
+// @Param offset query integer false "Number of notifications to skip" default(0)
+// @Success 200 {array} domain.Notification "List of notifications"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /notifications [get]
+func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	notifications, err := h.wsService.ListNotifications(claims.UserID.String(), limit, offset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(notifications)
+}
+
+// GetUnreadCount godoc
+// @Summary Get the unread notification count
+// @Description Returns the number of unread notifications for the authenticated user
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} dtos.UnreadCountResponse "Unread notification count"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /notifications/unread-count [get]
+func (h *NotificationHandler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	count, err := h.wsService.GetUnreadNotificationCount(claims.UserID.String())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dtos.UnreadCountResponse{Count: count})
+}
+
+// MarkAsRead godoc
+// @Summary Mark a notification as read
+// @Description Marks one of the authenticated user's notifications as read
+// @Tags notifications
+// @Param id path string true "Notification ID"
+// @Success 204 "Notification marked as read"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Notification not found"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /notifications/{id}/read [post]
+func (h *NotificationHandler) MarkAsRead(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+	notificationID := chi.URLParam(r, "id")
+
+	if err := h.wsService.MarkNotificationAsRead(notificationID, claims.UserID.String()); err != nil {
+		if errors.Is(err, domain.ErrNotificationNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListPreferences godoc
+// @Summary List notification preferences
+// @Description Returns the authenticated user's notification channel preferences. Channels without a preference are enabled.
+// @Tags notifications
+// @Produce json
+// @Success 200 {array} domain.NotificationPreference "Notification preferences"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /notifications/preferences [get]
+func (h *NotificationHandler) ListPreferences(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	preferences, err := h.wsService.GetNotificationPreferences(claims.UserID.String())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if preferences == nil {
+		preferences = []*domain.NotificationPreference{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preferences)
+}
+
+// SetPreference godoc
+// @Summary Set a notification preference
+// @Description Opts the authenticated user in to or out of a notification channel, for one notification type or, when type is omitted, for all of them
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body dtos.NotificationPreferenceRequest true "Notification preference"
+// @Success 200 {object} domain.NotificationPreference "Saved preference"
+// @Failure 400 {string} string "Invalid preference"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /notifications/preferences [put]
+func (h *NotificationHandler) SetPreference(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+	var req dtos.NotificationPreferenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	preference, err := h.wsService.SetNotificationPreference(claims.UserID.String(), req.Channel, req.Type, *req.Enabled)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidPreference) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preference)
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+	"github.com/personal/task-management/internal/domain"
+	"github.com/personal/task-management/internal/mocks"
+	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/stretchr/testify/suite"
+)
+
+type NotificationHandlerTestSuite struct {
+	suite.Suite
+	ctrl      *gomock.Controller
+	wsService *mocks.MockWebSocketService
+	handler   *NotificationHandler
+	userID    uuid.UUID
+}
+
+func (suite *NotificationHandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.wsService = mocks.NewMockWebSocketService(suite.ctrl)
+	suite.handler = NewNotificationHandler(suite.wsService)
+	suite.userID = uuid.New()
+}
+
+func (suite *NotificationHandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func (suite *NotificationHandlerTestSuite) newRequest(method, body string) *http.Request {
+	req := httptest.NewRequest(method, "/notifications/preferences", strings.NewReader(body))
+	return req.WithContext(middleware.WithClaims(req.Context(), &jwt.UserClaims{UserID: suite.userID}))
+}
+
+func (suite *NotificationHandlerTestSuite) TestSetPreference() {
+	saved := &domain.NotificationPreference{
+		ID:      "preference-1",
+		UserID:  suite.userID.String(),
+		Channel: domain.NotificationChannelEmail,
+		Type:    domain.NotificationTypeMention,
+		Enabled: false,
+	}
+	suite.wsService.EXPECT().
+		SetNotificationPreference(suite.userID.String(), domain.NotificationChannelEmail, domain.NotificationTypeMention, false).
+		Return(saved, nil)
+
+	rec := httptest.NewRecorder()
+	suite.handler.SetPreference(rec, suite.newRequest(http.MethodPut, `{"channel":"email","type":"mention","enabled":false}`))
+
+	suite.Equal(http.StatusOK, rec.Code)
+	var got domain.NotificationPreference
+	suite.NoError(json.NewDecoder(rec.Body).Decode(&got))
+	suite.Equal("preference-1", got.ID)
+	suite.False(got.Enabled)
+}
+
+func (suite *NotificationHandlerTestSuite) TestSetPreferenceRejectsInvalidBodies() {
+	for _, body := range []string{
+		`{"channel":"sms","enabled":false}`,
+		`{"channel":"email","type":"gossip","enabled":false}`,
+		`{"channel":"email"}`,
+		`not json`,
+	} {
+		rec := httptest.NewRecorder()
+		suite.handler.SetPreference(rec, suite.newRequest(http.MethodPut, body))
+		suite.Equal(http.StatusBadRequest, rec.Code, body)
+	}
+}
+
+func (suite *NotificationHandlerTestSuite) TestListPreferencesReturnsEmptyArray() {
+	suite.wsService.EXPECT().GetNotificationPreferences(suite.userID.String()).Return(nil, nil)
+
+	rec := httptest.NewRecorder()
+	suite.handler.ListPreferences(rec, suite.newRequest(http.MethodGet, ""))
+
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.JSONEq(`[]`, rec.Body.String())
+}
+
+func TestNotificationHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(NotificationHandlerTestSuite))
+}
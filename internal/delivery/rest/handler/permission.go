@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+	"github.com/personal/task-management/internal/domain/user"
+	"github.com/personal/task-management/pkg/apperrors"
+)
+
+// PermissionHandler reports what the authenticated user is allowed to do
+type PermissionHandler struct {
+	rbacService middleware.CasbinRBACService
+}
+
+// NewPermissionHandler creates a new PermissionHandler instance
+func NewPermissionHandler(rbacService middleware.CasbinRBACService) *PermissionHandler {
+	return &PermissionHandler{
+		rbacService: rbacService,
+	}
+}
+
+// GetMyPermissions godoc
+// @Summary List the current user's permissions
+// @Description Returns every resource/action pair the authenticated user's role is allowed
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} dtos.PermissionsOutput
+// @Failure 401 {object} apperrors.AppError "Unauthorized"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /me/permissions [get]
+func (h *PermissionHandler) GetMyPermissions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	permissions, err := h.rbacService.Permissions(user.ParseRole(claims.Role))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to list permissions"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dtos.PermissionsOutput{
+		Role:        claims.Role,
+		Permissions: permissions,
+	})
+}
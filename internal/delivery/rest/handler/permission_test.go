@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+	"github.com/personal/task-management/internal/domain/user"
+	"github.com/personal/task-management/internal/mocks"
+	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/stretchr/testify/suite"
+)
+
+type PermissionHandlerTestSuite struct {
+	suite.Suite
+	ctrl        *gomock.Controller
+	rbacService *mocks.MockCasbinRBACService
+	handler     *PermissionHandler
+}
+
+func (suite *PermissionHandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.rbacService = mocks.NewMockCasbinRBACService(suite.ctrl)
+	suite.handler = NewPermissionHandler(suite.rbacService)
+}
+
+func (suite *PermissionHandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func (suite *PermissionHandlerTestSuite) get(role string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/me/permissions", nil)
+	req = req.WithContext(middleware.WithClaims(req.Context(), &jwt.UserClaims{UserID: uuid.New(), Role: role}))
+	rec := httptest.NewRecorder()
+	suite.handler.GetMyPermissions(rec, req)
+	return rec
+}
+
+func (suite *PermissionHandlerTestSuite) TestGetMyPermissionsByRole() {
+	tests := []struct {
+		role        string
+		parsed      user.Role
+		permissions []middleware.Permission
+	}{
+		{
+			role:        "employee",
+			parsed:      user.Employee,
+			permissions: []middleware.Permission{{Resource: "tasks", Action: "read"}},
+		},
+		{
+			role:   "employer",
+			parsed: user.Employer,
+			permissions: []middleware.Permission{
+				{Resource: "admin", Action: "read"},
+				{Resource: "tasks", Action: "create"},
+				{Resource: "tasks", Action: "read"},
+			},
+		},
+		{role: "admin", parsed: user.Unknown, permissions: []middleware.Permission{}},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.role, func() {
+			suite.rbacService.EXPECT().Permissions(tt.parsed).Return(tt.permissions, nil)
+
+			rec := suite.get(tt.role)
+			suite.Equal(http.StatusOK, rec.Code)
+			var body dtos.PermissionsOutput
+			suite.NoError(json.NewDecoder(rec.Body).Decode(&body))
+			suite.Equal(tt.role, body.Role)
+			suite.Equal(tt.permissions, body.Permissions)
+		})
+	}
+}
+
+func (suite *PermissionHandlerTestSuite) TestGetMyPermissionsFailure() {
+	suite.rbacService.EXPECT().Permissions(user.Employee).Return(nil, errors.New("database is down"))
+
+	rec := suite.get("employee")
+	suite.Equal(http.StatusInternalServerError, rec.Code)
+}
+
+func (suite *PermissionHandlerTestSuite) TestGetMyPermissionsWithoutUser() {
+	rec := httptest.NewRecorder()
+	suite.handler.GetMyPermissions(rec, httptest.NewRequest(http.MethodGet, "/me/permissions", nil))
+	suite.Equal(http.StatusUnauthorized, rec.Code)
+}
+
+func TestPermissionHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(PermissionHandlerTestSuite))
+}
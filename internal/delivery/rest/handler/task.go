@@ -2,25 +2,29 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/personal/task-management/internal/delivery/rest/dtos"
-	_ "github.com/personal/task-management/internal/domain/task"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+	"github.com/personal/task-management/internal/domain/task"
 	"github.com/personal/task-management/internal/usecase"
 	"github.com/personal/task-management/pkg/apperrors"
-	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/personal/task-management/pkg/utils/pagination"
+	"github.com/personal/task-management/pkg/utils/validate"
 )
 
 type TaskHandler struct {
 	taskService usecase.TaskService
+	paginator   *pagination.Paginator
 }
 
-func NewTaskHandler(taskService usecase.TaskService) *TaskHandler {
+func NewTaskHandler(taskService usecase.TaskService, paginator *pagination.Paginator) *TaskHandler {
 	return &TaskHandler{
 		taskService: taskService,
+		paginator:   paginator,
 	}
 }
 
@@ -37,22 +41,25 @@ func NewTaskHandler(taskService usecase.TaskService) *TaskHandler {
 // @Failure 500 {object} apperrors.AppError "Internal Server Error"
 // @Router /tasks [post]
 func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
-	var task dtos.CreateTaskInput
-	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
-		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
 		return
 	}
 
-	// get user id from context
-	if userID, ok := r.Context().Value("user").(*jwt.UserClaims); ok {
-		task.CreatorID = userID.UserID
-	} else {
-		apperrors.WriteError(w, apperrors.NewBadRequestError("User not found in context"))
+	var input dtos.CreateTaskInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
 		return
 	}
+	input.CreatorID = claims.UserID
 
-	createdTask, err := h.taskService.CreateTask(r.Context(), task)
+	createdTask, err := h.taskService.CreateTask(r.Context(), input)
 	if err != nil {
+		if errors.Is(err, task.ErrInvalidRecurrence) || errors.Is(err, task.ErrInvalidAssignee) {
+			apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+			return
+		}
 		apperrors.WriteError(w, apperrors.NewInternalServerError(err.Error()))
 		return
 	}
@@ -61,53 +68,164 @@ func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(createdTask)
 }
 
+// godoc CreateTasksBulk
+// @Summary Create Tasks In Bulk
+// @Description Create up to 100 tasks at once. Valid tasks are stored together; invalid ones are reported by their index in the request.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param createTasksBulkRequest body dtos.CreateTasksBulkRequest true "Tasks to create"
+// @Success 201 {object} dtos.CreateTasksBulkOutput "Every task was created"
+// @Success 207 {object} dtos.CreateTasksBulkOutput "Some tasks were rejected"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/bulk [post]
+func (h *TaskHandler) CreateBulk(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	var req dtos.CreateTasksBulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	output, err := h.taskService.CreateTasksBulk(r.Context(), dtos.CreateTasksBulkInput{
+		CreatorID: claims.UserID,
+		Tasks:     req.Tasks,
+	})
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if len(output.Errors) > 0 {
+		status = http.StatusMultiStatus
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(output)
+}
+
 // godoc ListTasks
 // @Summary List Tasks
-// @Description List all tasks
+// @Description List tasks. Employees only ever see tasks assigned to them.
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param limit query integer false "Number of tasks to return" default(20)
+// @Param offset query integer false "Number of tasks to skip" default(0)
+// @Param status query []string false "Filter by status; repeat to match any of several" collectionFormat(multi)
+// @Param tag query []string false "Filter by tag; repeat to require several" collectionFormat(multi)
+// @Param tag_match query string false "Whether tasks must have all or any of the tags" Enums(all, any) default(all)
+// @Param sort_by query string false "Field to sort by" Enums(created_at, updated_at, due_date, title, status) default(created_at)
+// @Param sort_order query string false "Sort direction" Enums(asc, desc)
+// @Param assignee_id query string false "Filter by assignee; ignored for employees"
 // @Success 200 {object} []task.Task "List tasks response"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
 // @Failure 500 {object} apperrors.AppError "Internal Server Error"
 // @Router /tasks [get]
 func (h *TaskHandler) List(w http.ResponseWriter, r *http.Request) {
 	// user id from context
 	var userID uuid.UUID
-	if user, ok := r.Context().Value("user").(*jwt.UserClaims); ok {
+	if user, ok := middleware.UserFromContext(r.Context()); ok {
 		userID = user.UserID
 	} else {
-		apperrors.WriteError(w, apperrors.NewBadRequestError("User not found in context"))
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
 		return
 	}
-	limit := r.URL.Query().Get("limit")
-	if limit == "" {
-		limit = "10"
-	}
-	offset := r.URL.Query().Get("offset")
-	if offset == "" {
-		offset = "0"
-	}
-	limitInt, err := strconv.Atoi(limit)
+	limit, offset, err := h.paginator.Parse(r)
 	if err != nil {
-		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid limit"))
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
 		return
 	}
-	offsetInt, err := strconv.Atoi(offset)
-	if err != nil {
-		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid offset"))
+
+	// Repeated status params match tasks in any of the given statuses
+	var statuses []task.Status
+	for _, value := range r.URL.Query()["status"] {
+		status := task.Status(value)
+		if !status.IsValid() {
+			apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid status: "+value))
+			return
+		}
+		statuses = append(statuses, status)
+	}
+
+	// Repeated tag params match tasks with all of the tags unless tag_match=any
+	var anyTag bool
+	switch r.URL.Query().Get("tag_match") {
+	case "", "all":
+	case "any":
+		anyTag = true
+	default:
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid tag_match: must be all or any"))
 		return
 	}
 
+	// The scope AuthorizationMiddleware enforces wins over any assignee asked for
+	var assigneeID uuid.UUID
+	if scope, ok := middleware.AssigneeScopeFromContext(r.Context()); ok {
+		assigneeID = scope
+	} else if value := r.URL.Query().Get("assignee_id"); value != "" {
+		if assigneeID, err = uuid.Parse(value); err != nil {
+			apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid assignee_id"))
+			return
+		}
+	}
+
 	input := dtos.GetTasksWithFilterInput{
 		UserID: userID,
 		Filter: dtos.TaskFilter{
-			Limit:  limitInt,
-			Offset: offsetInt,
+			Limit:      limit,
+			Offset:     offset,
+			Statuses:   statuses,
+			AssigneeID: assigneeID,
+			Tags:       r.URL.Query()["tag"],
+			AnyTag:     anyTag,
+			SortBy:     r.URL.Query().Get("sort_by"),
+			SortOrder:  r.URL.Query().Get("sort_order"),
 		},
 	}
 
 	tasks, err := h.taskService.GetTasksWithFilter(r.Context(), input)
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tasks)
+}
+
+// godoc ListOverdueTasks
+// @Summary List Overdue Tasks
+// @Description List tasks past their due date that are not completed, most overdue first. Employees only see their own tasks.
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} []task.Task "List overdue tasks response"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/overdue [get]
+func (h *TaskHandler) ListOverdue(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	tasks, err := h.taskService.GetOverdueTasks(r.Context(), claims.UserID)
 	if err != nil {
 		apperrors.WriteError(w, apperrors.NewInternalServerError(err.Error()))
 		return
@@ -133,10 +251,10 @@ func (h *TaskHandler) List(w http.ResponseWriter, r *http.Request) {
 func (h *TaskHandler) GetEmployeeTasks(w http.ResponseWriter, r *http.Request) {
 	// get user id from context
 	var requesterID uuid.UUID
-	if userID, ok := r.Context().Value("user").(*jwt.UserClaims); ok {
+	if userID, ok := middleware.UserFromContext(r.Context()); ok {
 		requesterID = userID.UserID
 	} else {
-		apperrors.WriteError(w, apperrors.NewBadRequestError("User not found in context"))
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
 		return
 	}
 
@@ -175,10 +293,10 @@ func (h *TaskHandler) GetEmployeeTasks(w http.ResponseWriter, r *http.Request) {
 func (h *TaskHandler) GetSummaryByEmployee(w http.ResponseWriter, r *http.Request) {
 	// get user id from context
 	var requesterID uuid.UUID
-	if userID, ok := r.Context().Value("user").(*jwt.UserClaims); ok {
+	if userID, ok := middleware.UserFromContext(r.Context()); ok {
 		requesterID = userID.UserID
 	} else {
-		apperrors.WriteError(w, apperrors.NewBadRequestError("User not found in context"))
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
 		return
 	}
 
@@ -212,10 +330,10 @@ func (h *TaskHandler) GetSummaryByEmployee(w http.ResponseWriter, r *http.Reques
 func (h *TaskHandler) Get(w http.ResponseWriter, r *http.Request) {
 	// get user id from context
 	var requesterID uuid.UUID
-	if userID, ok := r.Context().Value("user").(*jwt.UserClaims); ok {
+	if userID, ok := middleware.UserFromContext(r.Context()); ok {
 		requesterID = userID.UserID
 	} else {
-		apperrors.WriteError(w, apperrors.NewBadRequestError("User not found in context"))
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
 		return
 	}
 
@@ -256,27 +374,133 @@ func (h *TaskHandler) Get(w http.ResponseWriter, r *http.Request) {
 // @Failure 500 {object} apperrors.AppError "Internal Server Error"
 // @Router /tasks/{id} [put]
 func (h *TaskHandler) Update(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
 	var input dtos.UpdateTaskStatusInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
 		return
 	}
+	input.UserID = claims.UserID
 
-	// get user id from context
-	if userID, ok := r.Context().Value("user").(*jwt.UserClaims); ok {
-		input.UserID = userID.UserID
-	} else {
-		apperrors.WriteError(w, apperrors.NewBadRequestError("User not found in context"))
+	updatedTask, err := h.taskService.UpdateTaskStatus(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, task.ErrBlockReasonRequired) || errors.Is(err, task.ErrInvalidStatusTransition) {
+			apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+			return
+		}
+		apperrors.WriteError(w, apperrors.NewInternalServerError(err.Error()))
 		return
 	}
-	task, err := h.taskService.UpdateTaskStatus(r.Context(), input)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updatedTask)
+}
+
+// godoc EditTask
+// @Summary Edit Task
+// @Description Change a task's title, description or due date; omitted fields are left as they are. Only employers and the task's creator may edit it. Use PUT /tasks/{id} to change its status.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param updateTaskDetailsRequest body dtos.UpdateTaskDetailsRequest true "Fields to change"
+// @Success 200 {object} task.Task "Edited task"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 404 {object} apperrors.AppError "Not Found"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/{id} [patch]
+func (h *TaskHandler) Edit(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
-		apperrors.WriteError(w, apperrors.NewInternalServerError(err.Error()))
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid task ID"))
+		return
+	}
+
+	var req dtos.UpdateTaskDetailsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	edited, err := h.taskService.UpdateTaskDetails(r.Context(), dtos.UpdateTaskDetailsInput{
+		TaskID:      taskID,
+		RequesterID: claims.UserID,
+		Title:       req.Title,
+		Description: req.Description,
+		DueDate:     req.DueDate,
+	})
+	if err != nil {
+		writeTaskError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(task)
+	json.NewEncoder(w).Encode(edited)
+}
+
+// godoc ReassignTask
+// @Summary Reassign Task
+// @Description Hand a task to another active employee. Only employers and the task's creator may reassign it; both the previous and new assignee are notified.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param reassignTaskRequest body dtos.ReassignTaskRequest true "New assignee"
+// @Success 200 {object} task.Task "Reassigned task"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 404 {object} apperrors.AppError "Not Found"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/{id}/assignee [put]
+func (h *TaskHandler) Reassign(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid task ID"))
+		return
+	}
+
+	var req dtos.ReassignTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	reassigned, err := h.taskService.ReassignTask(r.Context(), dtos.ReassignTaskInput{
+		TaskID:      taskID,
+		RequesterID: claims.UserID,
+		AssigneeID:  req.AssigneeID,
+	})
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reassigned)
 }
 
 // godoc DeleteTask
@@ -295,13 +519,13 @@ func (h *TaskHandler) Update(w http.ResponseWriter, r *http.Request) {
 func (h *TaskHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	taskID := chi.URLParam(r, "id")
 	var input dtos.DeleteTaskInput
-	if userID, ok := r.Context().Value("user").(*jwt.UserClaims); ok {
+	if userID, ok := middleware.UserFromContext(r.Context()); ok {
 		input = dtos.DeleteTaskInput{
 			RequesterID: userID.UserID,
 			TaskID:      uuid.MustParse(taskID),
 		}
 	} else {
-		apperrors.WriteError(w, apperrors.NewBadRequestError("User not found in context"))
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
 		return
 	}
 
@@ -314,3 +538,330 @@ func (h *TaskHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"message": "Task deleted successfully"})
 }
+
+// godoc AddComment
+// @Summary Add Task Comment
+// @Description Add a comment to a task as its assignee, its creator or an employer, optionally moving the task to a new status in the same update
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param addCommentRequest body dtos.AddCommentRequest true "Add comment request"
+// @Success 201 {object} task.TaskComment "Created comment"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/{id}/comments [post]
+func (h *TaskHandler) AddComment(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid task ID"))
+		return
+	}
+
+	var req dtos.AddCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	comment, err := h.taskService.AddComment(r.Context(), dtos.AddCommentInput{
+		TaskID:  taskID,
+		UserID:  claims.UserID,
+		Content: req.Content,
+		Status:  req.Status,
+	})
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
+
+// godoc ListComments
+// @Summary List Task Comments
+// @Description List a task's comments, oldest first
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param limit query integer false "Number of comments to return" default(20)
+// @Param offset query integer false "Number of comments to skip" default(0)
+// @Success 200 {object} []task.TaskComment "List comments response"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/{id}/comments [get]
+func (h *TaskHandler) ListComments(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid task ID"))
+		return
+	}
+
+	limit, offset, err := h.paginator.Parse(r)
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	comments, err := h.taskService.ListComments(r.Context(), dtos.ListCommentsInput{
+		TaskID:      taskID,
+		RequesterID: claims.UserID,
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comments)
+}
+
+// godoc DeleteComment
+// @Summary Delete Task Comment
+// @Description Delete a comment as its author or an employer
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param commentId path string true "Comment ID"
+// @Success 204 "Comment deleted"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 404 {object} apperrors.AppError "Not Found"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/{id}/comments/{commentId} [delete]
+func (h *TaskHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid task ID"))
+		return
+	}
+	commentID, err := uuid.Parse(chi.URLParam(r, "commentId"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid comment ID"))
+		return
+	}
+
+	err = h.taskService.DeleteComment(r.Context(), dtos.DeleteCommentInput{
+		TaskID:      taskID,
+		CommentID:   commentID,
+		RequesterID: claims.UserID,
+	})
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// godoc AddTag
+// @Summary Add Task Tag
+// @Description Label a task, creating the tag if it does not exist yet
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param addTagRequest body dtos.AddTagRequest true "Add tag request"
+// @Success 201 {object} task.Tag "Added tag"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/{id}/tags [post]
+func (h *TaskHandler) AddTag(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid task ID"))
+		return
+	}
+
+	var req dtos.AddTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	tag, err := h.taskService.AddTag(r.Context(), dtos.AddTagInput{
+		TaskID: taskID,
+		UserID: claims.UserID,
+		Name:   req.Name,
+	})
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tag)
+}
+
+// godoc ListTags
+// @Summary List Task Tags
+// @Description List a task's tags ordered by name
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Success 200 {object} []task.Tag "List tags response"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/{id}/tags [get]
+func (h *TaskHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid task ID"))
+		return
+	}
+
+	tags, err := h.taskService.ListTags(r.Context(), dtos.ListTagsInput{
+		TaskID:      taskID,
+		RequesterID: claims.UserID,
+	})
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+// godoc GetTaskHistory
+// @Summary Get Task History
+// @Description List who changed a task's status, assignee, title, description or due date, oldest first
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Success 200 {object} []task.TaskEvent "Task history"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 404 {object} apperrors.AppError "Not Found"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/{id}/history [get]
+func (h *TaskHandler) History(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid task ID"))
+		return
+	}
+
+	events, err := h.taskService.GetTaskHistory(r.Context(), taskID, claims.UserID)
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+// godoc RemoveTag
+// @Summary Remove Task Tag
+// @Description Remove a tag from a task
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param tag path string true "Tag name"
+// @Success 204 "Tag removed"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/{id}/tags/{tag} [delete]
+func (h *TaskHandler) RemoveTag(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid task ID"))
+		return
+	}
+
+	err = h.taskService.RemoveTag(r.Context(), dtos.RemoveTagInput{
+		TaskID: taskID,
+		UserID: claims.UserID,
+		Name:   chi.URLParam(r, "tag"),
+	})
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeTaskError maps task comment and tag errors to HTTP responses
+func writeTaskError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, task.ErrUnauthorized):
+		apperrors.WriteError(w, apperrors.NewForbiddenError(err.Error()))
+	case errors.Is(err, task.ErrCommentNotFound), errors.Is(err, task.ErrTaskNotFound):
+		apperrors.WriteError(w, apperrors.NewNotFoundError(err.Error()))
+	case errors.Is(err, task.ErrEmptyComment), errors.Is(err, task.ErrEmptyTag), errors.Is(err, task.ErrTagTooLong),
+		errors.Is(err, task.ErrInvalidSort), errors.Is(err, task.ErrInvalidAssignee),
+		errors.Is(err, task.ErrEmptyTitle), errors.Is(err, task.ErrInvalidDueDate):
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+	default:
+		apperrors.WriteError(w, apperrors.NewInternalServerError(err.Error()))
+	}
+}
@@ -0,0 +1,325 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+	"github.com/personal/task-management/internal/domain/task"
+	"github.com/personal/task-management/internal/usecase"
+	"github.com/personal/task-management/pkg/apperrors"
+	"github.com/personal/task-management/pkg/utils/pagination"
+	"github.com/personal/task-management/pkg/utils/validate"
+)
+
+type TaskTemplateHandler struct {
+	templateService usecase.TaskTemplateService
+	paginator       *pagination.Paginator
+}
+
+func NewTaskTemplateHandler(templateService usecase.TaskTemplateService, paginator *pagination.Paginator) *TaskTemplateHandler {
+	return &TaskTemplateHandler{
+		templateService: templateService,
+		paginator:       paginator,
+	}
+}
+
+// godoc CreateTaskTemplate
+// @Summary Create Task Template
+// @Description Save defaults for a kind of task employers create repeatedly
+// @Tags task-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param taskTemplateRequest body dtos.TaskTemplateRequest true "Task template"
+// @Success 201 {object} task.TaskTemplate "Created template"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/templates [post]
+func (h *TaskTemplateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	req, ok := decodeTemplateRequest(w, r)
+	if !ok {
+		return
+	}
+
+	template, err := h.templateService.CreateTemplate(r.Context(), dtos.CreateTaskTemplateInput{
+		CreatorID:         claims.UserID,
+		Title:             req.Title,
+		Description:       req.Description,
+		DueOffsetHours:    req.DueOffsetHours,
+		DefaultAssigneeID: req.DefaultAssigneeID,
+	})
+	if err != nil {
+		writeTemplateError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(template)
+}
+
+// godoc ListTaskTemplates
+// @Summary List Task Templates
+// @Description List task templates ordered by title
+// @Tags task-templates
+// @Produce json
+// @Security BearerAuth
+// @Param limit query integer false "Number of templates to return" default(20)
+// @Param offset query integer false "Number of templates to skip" default(0)
+// @Success 200 {object} []task.TaskTemplate "List templates response"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/templates [get]
+func (h *TaskTemplateHandler) List(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	limit, offset, err := h.paginator.Parse(r)
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	templates, err := h.templateService.ListTemplates(r.Context(), dtos.ListTaskTemplatesInput{
+		RequesterID: claims.UserID,
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		writeTemplateError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+// godoc GetTaskTemplate
+// @Summary Get Task Template
+// @Description Get a task template by ID
+// @Tags task-templates
+// @Produce json
+// @Security BearerAuth
+// @Param templateId path string true "Template ID"
+// @Success 200 {object} task.TaskTemplate "Template"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 404 {object} apperrors.AppError "Not Found"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/templates/{templateId} [get]
+func (h *TaskTemplateHandler) Get(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	templateID, err := uuid.Parse(chi.URLParam(r, "templateId"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid template ID"))
+		return
+	}
+
+	template, err := h.templateService.GetTemplate(r.Context(), dtos.GetTaskTemplateInput{
+		TemplateID:  templateID,
+		RequesterID: claims.UserID,
+	})
+	if err != nil {
+		writeTemplateError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(template)
+}
+
+// godoc UpdateTaskTemplate
+// @Summary Update Task Template
+// @Description Replace a task template's defaults
+// @Tags task-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param templateId path string true "Template ID"
+// @Param taskTemplateRequest body dtos.TaskTemplateRequest true "Task template"
+// @Success 200 {object} task.TaskTemplate "Updated template"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 404 {object} apperrors.AppError "Not Found"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/templates/{templateId} [put]
+func (h *TaskTemplateHandler) Update(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	templateID, err := uuid.Parse(chi.URLParam(r, "templateId"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid template ID"))
+		return
+	}
+
+	req, ok := decodeTemplateRequest(w, r)
+	if !ok {
+		return
+	}
+
+	template, err := h.templateService.UpdateTemplate(r.Context(), dtos.UpdateTaskTemplateInput{
+		TemplateID:        templateID,
+		RequesterID:       claims.UserID,
+		Title:             req.Title,
+		Description:       req.Description,
+		DueOffsetHours:    req.DueOffsetHours,
+		DefaultAssigneeID: req.DefaultAssigneeID,
+	})
+	if err != nil {
+		writeTemplateError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(template)
+}
+
+// godoc DeleteTaskTemplate
+// @Summary Delete Task Template
+// @Description Delete a task template; tasks created from it are kept
+// @Tags task-templates
+// @Produce json
+// @Security BearerAuth
+// @Param templateId path string true "Template ID"
+// @Success 204 "Template deleted"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 404 {object} apperrors.AppError "Not Found"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/templates/{templateId} [delete]
+func (h *TaskTemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	templateID, err := uuid.Parse(chi.URLParam(r, "templateId"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid template ID"))
+		return
+	}
+
+	err = h.templateService.DeleteTemplate(r.Context(), dtos.DeleteTaskTemplateInput{
+		TemplateID:  templateID,
+		RequesterID: claims.UserID,
+	})
+	if err != nil {
+		writeTemplateError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// godoc CreateTaskFromTemplate
+// @Summary Create Task From Template
+// @Description Create a task from a template, overriding any of its defaults
+// @Tags task-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param templateId path string true "Template ID"
+// @Param createTaskFromTemplateRequest body dtos.CreateTaskFromTemplateRequest false "Overrides"
+// @Success 201 {object} task.Task "Created task"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 404 {object} apperrors.AppError "Not Found"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /tasks/from-template/{templateId} [post]
+func (h *TaskTemplateHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	templateID, err := uuid.Parse(chi.URLParam(r, "templateId"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid template ID"))
+		return
+	}
+
+	// An empty body instantiates the template as is
+	var req dtos.CreateTaskFromTemplateRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+			return
+		}
+	}
+	if err := validate.Struct(req); err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	createdTask, err := h.templateService.CreateTaskFromTemplate(r.Context(), dtos.CreateTaskFromTemplateInput{
+		TemplateID: templateID,
+		CreatorID:  claims.UserID,
+		Overrides:  req,
+	})
+	if err != nil {
+		writeTemplateError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createdTask)
+}
+
+func decodeTemplateRequest(w http.ResponseWriter, r *http.Request) (dtos.TaskTemplateRequest, bool) {
+	var req dtos.TaskTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return req, false
+	}
+	if err := validate.Struct(req); err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return req, false
+	}
+	return req, true
+}
+
+// writeTemplateError maps task template errors to HTTP responses
+func writeTemplateError(w http.ResponseWriter, err error) {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.Is(err, task.ErrUnauthorized):
+		apperrors.WriteError(w, apperrors.NewForbiddenError(err.Error()))
+	case errors.Is(err, task.ErrTemplateNotFound):
+		apperrors.WriteError(w, apperrors.NewNotFoundError(err.Error()))
+	case errors.Is(err, task.ErrEmptyTitle), errors.Is(err, task.ErrInvalidDueOffset),
+		errors.Is(err, task.ErrAssigneeRequired), errors.Is(err, task.ErrInvalidDueDate),
+		errors.Is(err, task.ErrInvalidRecurrence), errors.As(err, &validationErrors):
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+	default:
+		apperrors.WriteError(w, apperrors.NewInternalServerError(err.Error()))
+	}
+}
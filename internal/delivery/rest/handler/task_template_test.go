@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+	"github.com/personal/task-management/internal/domain/task"
+	"github.com/personal/task-management/internal/mocks"
+	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/stretchr/testify/suite"
+)
+
+type TaskTemplateHandlerTestSuite struct {
+	suite.Suite
+	ctrl            *gomock.Controller
+	templateService *mocks.MockTaskTemplateService
+	handler         *TaskTemplateHandler
+	userID          uuid.UUID
+}
+
+func (suite *TaskTemplateHandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.templateService = mocks.NewMockTaskTemplateService(suite.ctrl)
+	suite.handler = NewTaskTemplateHandler(suite.templateService, newTestPaginator())
+	suite.userID = uuid.New()
+}
+
+func (suite *TaskTemplateHandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// serve routes the request through chi so URL params resolve
+func (suite *TaskTemplateHandlerTestSuite) serve(method, pattern, target string, body io.Reader, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	router := chi.NewRouter()
+	router.MethodFunc(method, pattern, handler)
+
+	req := httptest.NewRequest(method, target, body)
+	req = req.WithContext(middleware.WithClaims(req.Context(), &jwt.UserClaims{UserID: suite.userID}))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func (suite *TaskTemplateHandlerTestSuite) TestCreateTemplate() {
+	suite.templateService.EXPECT().CreateTemplate(gomock.Any(), dtos.CreateTaskTemplateInput{
+		CreatorID:      suite.userID,
+		Title:          "Weekly report",
+		DueOffsetHours: 48,
+	}).Return(&task.TaskTemplate{ID: uuid.New()}, nil)
+
+	rec := suite.serve(http.MethodPost, "/tasks/templates", "/tasks/templates",
+		strings.NewReader(`{"title":"Weekly report","due_offset_hours":48}`), suite.handler.Create)
+	suite.Equal(http.StatusCreated, rec.Code)
+}
+
+func (suite *TaskTemplateHandlerTestSuite) TestCreateTemplateRequiresDueOffset() {
+	rec := suite.serve(http.MethodPost, "/tasks/templates", "/tasks/templates",
+		strings.NewReader(`{"title":"Weekly report"}`), suite.handler.Create)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *TaskTemplateHandlerTestSuite) TestCreateTaskFromTemplateWithoutBody() {
+	templateID := uuid.New()
+	suite.templateService.EXPECT().CreateTaskFromTemplate(gomock.Any(), dtos.CreateTaskFromTemplateInput{
+		TemplateID: templateID,
+		CreatorID:  suite.userID,
+	}).Return(&task.Task{ID: uuid.New()}, nil)
+
+	rec := suite.serve(http.MethodPost, "/tasks/from-template/{templateId}", "/tasks/from-template/"+templateID.String(),
+		nil, suite.handler.CreateTask)
+	suite.Equal(http.StatusCreated, rec.Code)
+}
+
+func (suite *TaskTemplateHandlerTestSuite) TestCreateTaskFromTemplatePassesOverrides() {
+	templateID := uuid.New()
+	assignee := uuid.New()
+	suite.templateService.EXPECT().CreateTaskFromTemplate(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input dtos.CreateTaskFromTemplateInput) (*task.Task, error) {
+			suite.Require().NotNil(input.Overrides.Title)
+			suite.Equal("Week 12 report", *input.Overrides.Title)
+			suite.Nil(input.Overrides.Description)
+			suite.Equal(&assignee, input.Overrides.AssigneeID)
+			return &task.Task{}, nil
+		})
+
+	body := `{"title":"Week 12 report","assignee_id":"` + assignee.String() + `"}`
+	rec := suite.serve(http.MethodPost, "/tasks/from-template/{templateId}", "/tasks/from-template/"+templateID.String(),
+		strings.NewReader(body), suite.handler.CreateTask)
+	suite.Equal(http.StatusCreated, rec.Code)
+}
+
+func (suite *TaskTemplateHandlerTestSuite) TestCreateTaskFromTemplateMapsErrors() {
+	for err, status := range map[error]int{
+		task.ErrTemplateNotFound: http.StatusNotFound,
+		task.ErrUnauthorized:     http.StatusForbidden,
+		task.ErrAssigneeRequired: http.StatusBadRequest,
+	} {
+		suite.templateService.EXPECT().CreateTaskFromTemplate(gomock.Any(), gomock.Any()).Return(nil, err)
+
+		templateID := uuid.NewString()
+		rec := suite.serve(http.MethodPost, "/tasks/from-template/{templateId}", "/tasks/from-template/"+templateID,
+			nil, suite.handler.CreateTask)
+		suite.Equal(status, rec.Code, err.Error())
+	}
+}
+
+func TestTaskTemplateHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(TaskTemplateHandlerTestSuite))
+}
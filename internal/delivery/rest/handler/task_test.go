@@ -0,0 +1,336 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+	"github.com/personal/task-management/internal/domain/task"
+	"github.com/personal/task-management/internal/mocks"
+	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/personal/task-management/pkg/utils/pagination"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	testDefaultLimit = 15
+	testMaxLimit     = 40
+)
+
+// newTestPaginator returns the shared paginator configured with test page sizes
+func newTestPaginator() *pagination.Paginator {
+	cfg := viper.New()
+	cfg.Set("pagination.default_limit", testDefaultLimit)
+	cfg.Set("pagination.max_limit", testMaxLimit)
+	return pagination.NewPaginator(cfg)
+}
+
+type TaskHandlerTestSuite struct {
+	suite.Suite
+	ctrl        *gomock.Controller
+	taskService *mocks.MockTaskService
+	handler     *TaskHandler
+	userID      uuid.UUID
+}
+
+func (suite *TaskHandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.taskService = mocks.NewMockTaskService(suite.ctrl)
+	suite.handler = NewTaskHandler(suite.taskService, newTestPaginator())
+	suite.userID = uuid.New()
+}
+
+func (suite *TaskHandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func (suite *TaskHandlerTestSuite) list(target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	claims := &jwt.UserClaims{UserID: suite.userID}
+	req = req.WithContext(middleware.WithClaims(req.Context(), claims))
+	rec := httptest.NewRecorder()
+	suite.handler.List(rec, req)
+	return rec
+}
+
+func (suite *TaskHandlerTestSuite) TestHandlersWithoutUserAreUnauthorized() {
+	handlers := map[string]http.HandlerFunc{
+		"create":      suite.handler.Create,
+		"create bulk": suite.handler.CreateBulk,
+		"list":        suite.handler.List,
+		"overdue":     suite.handler.ListOverdue,
+		"get":         suite.handler.Get,
+		"update":      suite.handler.Update,
+		"delete":      suite.handler.Delete,
+	}
+
+	for name, handlerFunc := range handlers {
+		suite.Run(name, func() {
+			rec := httptest.NewRecorder()
+			suite.NotPanics(func() {
+				handlerFunc(rec, httptest.NewRequest(http.MethodPost, "/tasks", nil))
+			})
+			suite.Equal(http.StatusUnauthorized, rec.Code)
+		})
+	}
+}
+
+func (suite *TaskHandlerTestSuite) TestListWithMultipleStatuses() {
+	suite.taskService.EXPECT().GetTasksWithFilter(gomock.Any(), dtos.GetTasksWithFilterInput{
+		UserID: suite.userID,
+		Filter: dtos.TaskFilter{
+			Limit:    testDefaultLimit,
+			Statuses: []task.Status{task.StatusPending, task.StatusInProgress},
+		},
+	}).Return([]*task.Task{}, nil)
+
+	rec := suite.list("/tasks?status=pending&status=in_progress")
+	suite.Equal(http.StatusOK, rec.Code)
+}
+
+func (suite *TaskHandlerTestSuite) TestListRejectsUnknownStatus() {
+	rec := suite.list("/tasks?status=pending&status=archived")
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *TaskHandlerTestSuite) TestListAppliesSharedPageSize() {
+	suite.taskService.EXPECT().GetTasksWithFilter(gomock.Any(), dtos.GetTasksWithFilterInput{
+		UserID: suite.userID,
+		Filter: dtos.TaskFilter{Limit: testMaxLimit, Offset: 5},
+	}).Return([]*task.Task{}, nil)
+
+	rec := suite.list("/tasks?limit=1000&offset=5")
+	suite.Equal(http.StatusOK, rec.Code)
+}
+
+func (suite *TaskHandlerTestSuite) TestListWithTags() {
+	suite.taskService.EXPECT().GetTasksWithFilter(gomock.Any(), dtos.GetTasksWithFilterInput{
+		UserID: suite.userID,
+		Filter: dtos.TaskFilter{
+			Limit:  testDefaultLimit,
+			Tags:   []string{"backend", "bug"},
+			AnyTag: true,
+		},
+	}).Return([]*task.Task{}, nil)
+
+	rec := suite.list("/tasks?tag=backend&tag=bug&tag_match=any")
+	suite.Equal(http.StatusOK, rec.Code)
+}
+
+func (suite *TaskHandlerTestSuite) TestListRejectsUnknownTagMatch() {
+	rec := suite.list("/tasks?tag=backend&tag_match=some")
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *TaskHandlerTestSuite) TestListPassesSort() {
+	suite.taskService.EXPECT().GetTasksWithFilter(gomock.Any(), dtos.GetTasksWithFilterInput{
+		UserID: suite.userID,
+		Filter: dtos.TaskFilter{Limit: testDefaultLimit, SortBy: "due_date", SortOrder: "asc"},
+	}).Return([]*task.Task{}, nil)
+
+	rec := suite.list("/tasks?sort_by=due_date&sort_order=asc")
+	suite.Equal(http.StatusOK, rec.Code)
+}
+
+func (suite *TaskHandlerTestSuite) TestListRejectsInvalidSort() {
+	suite.taskService.EXPECT().GetTasksWithFilter(gomock.Any(), gomock.Any()).Return(nil, task.ErrInvalidSort)
+
+	rec := suite.list("/tasks?sort_by=title%3B+DROP+TABLE+tasks")
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *TaskHandlerTestSuite) TestListKeepsEmployeesToTheirOwnTasks() {
+	// Asking for someone else's tasks still lists only the caller's
+	suite.taskService.EXPECT().GetTasksWithFilter(gomock.Any(), dtos.GetTasksWithFilterInput{
+		UserID: suite.userID,
+		Filter: dtos.TaskFilter{Limit: testDefaultLimit, AssigneeID: suite.userID},
+	}).Return([]*task.Task{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks?assignee_id="+uuid.NewString(), nil)
+	ctx := middleware.WithClaims(req.Context(), &jwt.UserClaims{UserID: suite.userID, Role: "employee"})
+	req = req.WithContext(middleware.WithAssigneeScope(ctx, suite.userID))
+	rec := httptest.NewRecorder()
+	suite.handler.List(rec, req)
+	suite.Equal(http.StatusOK, rec.Code)
+}
+
+func (suite *TaskHandlerTestSuite) TestListFiltersByAssignee() {
+	assigneeID := uuid.New()
+	suite.taskService.EXPECT().GetTasksWithFilter(gomock.Any(), dtos.GetTasksWithFilterInput{
+		UserID: suite.userID,
+		Filter: dtos.TaskFilter{Limit: testDefaultLimit, AssigneeID: assigneeID},
+	}).Return([]*task.Task{}, nil)
+
+	rec := suite.list("/tasks?assignee_id=" + assigneeID.String())
+	suite.Equal(http.StatusOK, rec.Code)
+
+	rec = suite.list("/tasks?assignee_id=nobody")
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *TaskHandlerTestSuite) edit(taskID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, "/tasks/"+taskID, strings.NewReader(body))
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("id", taskID)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+	req = req.WithContext(middleware.WithClaims(ctx, &jwt.UserClaims{UserID: suite.userID}))
+	rec := httptest.NewRecorder()
+	suite.handler.Edit(rec, req)
+	return rec
+}
+
+func (suite *TaskHandlerTestSuite) TestEditPassesOnlyGivenFields() {
+	taskID := uuid.New()
+	suite.taskService.EXPECT().UpdateTaskDetails(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input dtos.UpdateTaskDetailsInput) (*task.Task, error) {
+			suite.Equal(taskID, input.TaskID)
+			suite.Equal(suite.userID, input.RequesterID)
+			suite.Require().NotNil(input.Title)
+			suite.Equal("Write the report", *input.Title)
+			suite.Nil(input.Description)
+			suite.Nil(input.DueDate)
+			return &task.Task{ID: taskID, Title: *input.Title}, nil
+		})
+
+	rec := suite.edit(taskID.String(), `{"title":"Write the report"}`)
+	suite.Equal(http.StatusOK, rec.Code)
+}
+
+func (suite *TaskHandlerTestSuite) TestEditErrors() {
+	suite.Equal(http.StatusBadRequest, suite.edit("not-a-uuid", `{}`).Code)
+	suite.Equal(http.StatusBadRequest, suite.edit(uuid.NewString(), `{"due_date":"tomorrow"}`).Code)
+
+	errs := map[error]int{
+		task.ErrInvalidDueDate: http.StatusBadRequest,
+		task.ErrEmptyTitle:     http.StatusBadRequest,
+		task.ErrUnauthorized:   http.StatusForbidden,
+		task.ErrTaskNotFound:   http.StatusNotFound,
+	}
+	for err, status := range errs {
+		suite.taskService.EXPECT().UpdateTaskDetails(gomock.Any(), gomock.Any()).Return(nil, err)
+		suite.Equal(status, suite.edit(uuid.NewString(), `{"title":"x"}`).Code, err.Error())
+	}
+}
+
+func (suite *TaskHandlerTestSuite) history(taskID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/tasks/"+taskID+"/history", nil)
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("id", taskID)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+	req = req.WithContext(middleware.WithClaims(ctx, &jwt.UserClaims{UserID: suite.userID}))
+	rec := httptest.NewRecorder()
+	suite.handler.History(rec, req)
+	return rec
+}
+
+func (suite *TaskHandlerTestSuite) TestHistory() {
+	taskID := uuid.New()
+	event := task.NewTaskEvent(taskID, suite.userID, task.FieldStatus, "pending", "completed")
+	suite.taskService.EXPECT().GetTaskHistory(gomock.Any(), taskID, suite.userID).Return([]*task.TaskEvent{event}, nil)
+
+	rec := suite.history(taskID.String())
+	suite.Equal(http.StatusOK, rec.Code)
+	var events []task.TaskEvent
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &events))
+	suite.Require().Len(events, 1)
+	suite.Equal("completed", events[0].NewValue)
+
+	suite.Equal(http.StatusBadRequest, suite.history("not-a-uuid").Code)
+	suite.taskService.EXPECT().GetTaskHistory(gomock.Any(), taskID, suite.userID).Return(nil, task.ErrUnauthorized)
+	suite.Equal(http.StatusForbidden, suite.history(taskID.String()).Code)
+}
+
+func (suite *TaskHandlerTestSuite) reassign(taskID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPut, "/tasks/"+taskID+"/assignee", strings.NewReader(body))
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("id", taskID)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+	req = req.WithContext(middleware.WithClaims(ctx, &jwt.UserClaims{UserID: suite.userID}))
+	rec := httptest.NewRecorder()
+	suite.handler.Reassign(rec, req)
+	return rec
+}
+
+func (suite *TaskHandlerTestSuite) TestReassign() {
+	taskID, assigneeID := uuid.New(), uuid.New()
+	suite.taskService.EXPECT().ReassignTask(gomock.Any(), dtos.ReassignTaskInput{
+		TaskID:      taskID,
+		RequesterID: suite.userID,
+		AssigneeID:  assigneeID,
+	}).Return(&task.Task{ID: taskID, AssigneeID: assigneeID}, nil)
+
+	rec := suite.reassign(taskID.String(), `{"assignee_id":"`+assigneeID.String()+`"}`)
+	suite.Equal(http.StatusOK, rec.Code)
+	var reassigned task.Task
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &reassigned))
+	suite.Equal(assigneeID, reassigned.AssigneeID)
+}
+
+func (suite *TaskHandlerTestSuite) TestReassignErrors() {
+	body := `{"assignee_id":"` + uuid.NewString() + `"}`
+	suite.Equal(http.StatusBadRequest, suite.reassign("not-a-uuid", body).Code)
+	suite.Equal(http.StatusBadRequest, suite.reassign(uuid.NewString(), `{}`).Code)
+
+	errs := map[error]int{
+		task.ErrInvalidAssignee: http.StatusBadRequest,
+		task.ErrUnauthorized:    http.StatusForbidden,
+		task.ErrTaskNotFound:    http.StatusNotFound,
+	}
+	for err, status := range errs {
+		suite.taskService.EXPECT().ReassignTask(gomock.Any(), gomock.Any()).Return(nil, err)
+		suite.Equal(status, suite.reassign(uuid.NewString(), body).Code, err.Error())
+	}
+}
+
+func (suite *TaskHandlerTestSuite) createBulk(body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/tasks/bulk", strings.NewReader(body))
+	req = req.WithContext(middleware.WithClaims(req.Context(), &jwt.UserClaims{UserID: suite.userID}))
+	rec := httptest.NewRecorder()
+	suite.handler.CreateBulk(rec, req)
+	return rec
+}
+
+func (suite *TaskHandlerTestSuite) TestCreateBulkAllCreated() {
+	suite.taskService.EXPECT().CreateTasksBulk(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input dtos.CreateTasksBulkInput) (*dtos.CreateTasksBulkOutput, error) {
+			suite.Equal(suite.userID, input.CreatorID)
+			suite.Len(input.Tasks, 2)
+			return &dtos.CreateTasksBulkOutput{Created: []*task.Task{{}, {}}, Errors: []dtos.BulkTaskError{}}, nil
+		})
+
+	rec := suite.createBulk(`{"tasks":[{"title":"a"},{"title":"b"}]}`)
+	suite.Equal(http.StatusCreated, rec.Code)
+}
+
+func (suite *TaskHandlerTestSuite) TestCreateBulkPartialFailure() {
+	suite.taskService.EXPECT().CreateTasksBulk(gomock.Any(), gomock.Any()).Return(&dtos.CreateTasksBulkOutput{
+		Created: []*task.Task{{Title: "a"}},
+		Errors:  []dtos.BulkTaskError{{Index: 1, Error: task.ErrEmptyTitle.Error()}},
+	}, nil)
+
+	rec := suite.createBulk(`{"tasks":[{"title":"a"},{"title":""}]}`)
+	suite.Equal(http.StatusMultiStatus, rec.Code)
+
+	var body dtos.CreateTasksBulkOutput
+	suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&body))
+	suite.Len(body.Created, 1)
+	suite.Equal([]dtos.BulkTaskError{{Index: 1, Error: task.ErrEmptyTitle.Error()}}, body.Errors)
+}
+
+func (suite *TaskHandlerTestSuite) TestCreateBulkRejectsEmptyBatch() {
+	rec := suite.createBulk(`{"tasks":[]}`)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func TestTaskHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(TaskHandlerTestSuite))
+}
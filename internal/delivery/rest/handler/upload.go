@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+	"github.com/personal/task-management/pkg/logger"
+	"github.com/personal/task-management/pkg/storage"
+	"github.com/personal/task-management/pkg/utils/thumbnail"
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultMaxUploadSize = 10 << 20
+	defaultThumbnailSize = 256
+	// multipartOverhead leaves room for the boundary and part headers around
+	// the file when capping the request body
+	multipartOverhead = 1 << 20
+)
+
+// defaultUploadTypes are the content types http.DetectContentType reports for
+// common attachments
+var defaultUploadTypes = []string{
+	"image/jpeg", "image/png", "image/gif", "image/webp",
+	"video/mp4", "video/webm",
+	"audio/mpeg", "audio/wave", "audio/ogg",
+	"application/pdf", "text/plain",
+}
+
+// UploadHandler stores chat attachments so their URLs can be sent as file,
+// image, video or audio messages
+type UploadHandler struct {
+	storage       storage.Storage
+	maxSize       int64
+	allowedTypes  map[string]bool
+	thumbnailSize int
+}
+
+// NewUploadHandler reads uploads.max_size, uploads.allowed_types and
+// uploads.thumbnail_size
+func NewUploadHandler(cfg *viper.Viper, store storage.Storage) *UploadHandler {
+	maxSize := int64(cfg.GetSizeInBytes("uploads.max_size"))
+	if maxSize <= 0 {
+		maxSize = defaultMaxUploadSize
+	}
+	thumbnailSize := cfg.GetInt("uploads.thumbnail_size")
+	if thumbnailSize <= 0 {
+		thumbnailSize = defaultThumbnailSize
+	}
+	types := cfg.GetStringSlice("uploads.allowed_types")
+	if len(types) == 0 {
+		types = defaultUploadTypes
+	}
+
+	allowed := make(map[string]bool, len(types))
+	for _, t := range types {
+		allowed[strings.ToLower(strings.TrimSpace(t))] = true
+	}
+	return &UploadHandler{
+		storage:       store,
+		maxSize:       maxSize,
+		allowedTypes:  allowed,
+		thumbnailSize: thumbnailSize,
+	}
+}
+
+// Files returns the handler serving uploaded files when the storage serves
+// them itself, or nil when they are fetched from elsewhere
+func (h *UploadHandler) Files() http.Handler {
+	files, _ := h.storage.(http.Handler)
+	return files
+}
+
+// Upload godoc
+// @Summary Upload a chat attachment
+// @Description Stores a file sent as the multipart field "file" and returns the fields to send it as a file, image, video or audio message. The type is detected from the content rather than trusted from the client, and images also get a JPEG thumbnail.
+// @Tags chat
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "File to upload"
+// @Success 201 {object} dtos.UploadResponse "Stored file"
+// @Failure 400 {string} string "Missing or empty file"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 413 {string} string "File too large"
+// @Failure 415 {string} string "File type not allowed"
+// @Failure 500 {string} string "Internal server error"
+// @Security ApiKeyAuth
+// @Router /chat/upload [post]
+func (h *UploadHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	if _, ok := middleware.UserIDFromContext(r.Context()); !ok {
+		http.Error(w, "user not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxSize+multipartOverhead)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, h.tooLargeMessage(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	if header.Size == 0 {
+		http.Error(w, "file is empty", http.StatusBadRequest)
+		return
+	}
+	if header.Size > h.maxSize {
+		http.Error(w, h.tooLargeMessage(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	fileType, err := detectType(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !h.allowedTypes[fileType] {
+		http.Error(w, fmt.Sprintf("file type %s is not allowed", fileType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	// Stored names are generated so uploads never collide or pick their path
+	name := uuid.NewString()
+	fileURL, err := h.storage.Save(r.Context(), name+extensionFor(header.Filename, fileType), file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := dtos.UploadResponse{
+		FileURL:  fileURL,
+		FileName: filepath.Base(header.Filename),
+		FileSize: header.Size,
+		FileType: fileType,
+	}
+	if strings.HasPrefix(fileType, "image/") {
+		response.ThumbnailURL = h.saveThumbnail(r, file, name)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// saveThumbnail stores a preview of the uploaded image and returns its URL.
+// The upload itself already succeeded, so a failure only leaves the
+// thumbnail out.
+func (h *UploadHandler) saveThumbnail(r *http.Request, file multipart.File, name string) string {
+	log := logger.FromContext(r.Context()).With("upload", name)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		log.Error("rewinding upload for its thumbnail", "error", err)
+		return ""
+	}
+	preview, err := thumbnail.JPEG(file, h.thumbnailSize)
+	if err != nil {
+		log.Error("generating thumbnail", "error", err)
+		return ""
+	}
+	thumbnailURL, err := h.storage.Save(r.Context(), name+"_thumb.jpg", bytes.NewReader(preview))
+	if err != nil {
+		log.Error("saving thumbnail", "error", err)
+		return ""
+	}
+	return thumbnailURL
+}
+
+func (h *UploadHandler) tooLargeMessage() string {
+	return fmt.Sprintf("file exceeds the %d byte limit", h.maxSize)
+}
+
+// detectType sniffs the content type from the start of the file and rewinds
+// it for storing
+func detectType(file multipart.File) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	fileType, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	return fileType, err
+}
+
+// extensionFor keeps the client's extension when it matches the detected
+// type, so a PNG can't be stored, and later served, as .html
+func extensionFor(filename, fileType string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if t, _, err := mime.ParseMediaType(mime.TypeByExtension(ext)); err == nil && t == fileType {
+		return ext
+	}
+	if exts, _ := mime.ExtensionsByType(fileType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
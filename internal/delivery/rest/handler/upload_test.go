@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+	localdisk "github.com/personal/task-management/pkg/storage/local-disk"
+	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+type UploadHandlerTestSuite struct {
+	suite.Suite
+	dir     string
+	handler *UploadHandler
+}
+
+func (suite *UploadHandlerTestSuite) SetupTest() {
+	suite.dir = suite.T().TempDir()
+	store, err := localdisk.NewStorage(suite.dir, "http://files.example.com/uploads/")
+	suite.Require().NoError(err)
+
+	cfg := viper.New()
+	cfg.Set("uploads.max_size", "1KB")
+	cfg.Set("uploads.allowed_types", []string{"image/png", "application/pdf"})
+	cfg.Set("uploads.thumbnail_size", 4)
+	suite.handler = NewUploadHandler(cfg, store)
+}
+
+// upload posts content as the multipart field "file" on behalf of a user
+func (suite *UploadHandlerTestSuite) upload(filename string, content []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	suite.Require().NoError(err)
+	_, err = part.Write(content)
+	suite.Require().NoError(err)
+	suite.Require().NoError(form.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/chat/upload", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req = req.WithContext(middleware.WithClaims(req.Context(), &jwt.UserClaims{UserID: uuid.New()}))
+	rec := httptest.NewRecorder()
+	suite.handler.Upload(rec, req)
+	return rec
+}
+
+// stored reads back the file a returned URL points at
+func (suite *UploadHandlerTestSuite) stored(fileURL string) []byte {
+	suite.Require().True(strings.HasPrefix(fileURL, "http://files.example.com/uploads/"), fileURL)
+	parsed, err := url.Parse(fileURL)
+	suite.Require().NoError(err)
+	content, err := os.ReadFile(filepath.Join(suite.dir, filepath.Base(parsed.Path)))
+	suite.Require().NoError(err)
+	return content
+}
+
+func pngImage(width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x * 20), G: uint8(y * 20), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func (suite *UploadHandlerTestSuite) TestUploadImageWithThumbnail() {
+	content := pngImage(10, 5)
+	rec := suite.upload("holiday.png", content)
+	suite.Require().Equal(http.StatusCreated, rec.Code, rec.Body.String())
+
+	var response dtos.UploadResponse
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &response))
+	suite.Equal("holiday.png", response.FileName)
+	suite.Equal(int64(len(content)), response.FileSize)
+	suite.Equal("image/png", response.FileType)
+	suite.True(strings.HasSuffix(response.FileURL, ".png"))
+	suite.Equal(content, suite.stored(response.FileURL))
+
+	// The thumbnail fits inside 4x4 and keeps the aspect ratio
+	suite.Require().NotEmpty(response.ThumbnailURL)
+	preview, err := jpeg.DecodeConfig(bytes.NewReader(suite.stored(response.ThumbnailURL)))
+	suite.Require().NoError(err)
+	suite.Equal(4, preview.Width)
+	suite.Equal(2, preview.Height)
+}
+
+func (suite *UploadHandlerTestSuite) TestUploadFileWithoutThumbnail() {
+	content := []byte("%PDF-1.4\n%minimal")
+	rec := suite.upload("report.pdf", content)
+	suite.Require().Equal(http.StatusCreated, rec.Code, rec.Body.String())
+
+	var response dtos.UploadResponse
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &response))
+	suite.Equal("application/pdf", response.FileType)
+	suite.Empty(response.ThumbnailURL)
+	suite.Equal(content, suite.stored(response.FileURL))
+}
+
+func (suite *UploadHandlerTestSuite) TestUploadDetectsTypeFromContent() {
+	// A PNG named .html is stored as .png, so it is never served as a page
+	rec := suite.upload("page.html", pngImage(2, 2))
+	suite.Require().Equal(http.StatusCreated, rec.Code, rec.Body.String())
+	var response dtos.UploadResponse
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &response))
+	suite.True(strings.HasSuffix(response.FileURL, ".png"), response.FileURL)
+
+	// HTML named .png is still HTML, which is not allowed
+	rec = suite.upload("cat.png", []byte("<html><script>alert(1)</script></html>"))
+	suite.Equal(http.StatusUnsupportedMediaType, rec.Code)
+}
+
+func (suite *UploadHandlerTestSuite) TestUploadRejectsOversizedFile() {
+	rec := suite.upload("big.pdf", append([]byte("%PDF-1.4\n"), bytes.Repeat([]byte("a"), 2048)...))
+	suite.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+
+	entries, err := os.ReadDir(suite.dir)
+	suite.Require().NoError(err)
+	suite.Empty(entries)
+}
+
+func (suite *UploadHandlerTestSuite) TestUploadRequiresFile() {
+	req := httptest.NewRequest(http.MethodPost, "/chat/upload", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(middleware.WithClaims(req.Context(), &jwt.UserClaims{UserID: uuid.New()}))
+	rec := httptest.NewRecorder()
+	suite.handler.Upload(rec, req)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+
+	rec = suite.upload("empty.pdf", nil)
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func TestUploadHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(UploadHandlerTestSuite))
+}
@@ -4,24 +4,31 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
 	"github.com/personal/task-management/internal/domain/user"
 	"github.com/personal/task-management/internal/usecase"
 	"github.com/personal/task-management/pkg/apperrors"
+	"github.com/personal/task-management/pkg/utils/pagination"
+	"github.com/personal/task-management/pkg/utils/validate"
 )
 
 // UserHandler handles HTTP requests for user operations
 type UserHandler struct {
 	userService usecase.UserService
+	paginator   *pagination.Paginator
 }
 
 // NewUserHandler creates a new instance of UserHandler
-func NewUserHandler(userService usecase.UserService) *UserHandler {
+func NewUserHandler(userService usecase.UserService, paginator *pagination.Paginator) *UserHandler {
 	return &UserHandler{
 		userService: userService,
+		paginator:   paginator,
 	}
 }
 
@@ -70,6 +77,91 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// godoc GetCurrentUser
+// @Summary Get Current User
+// @Description Get the profile of the authenticated user
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dtos.GetUserOutput "Current user"
+// @Failure 401 {object} apperrors.AppError "Unauthorized"
+// @Failure 404 {object} apperrors.AppError "Not Found"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /users/me [get]
+func (h *UserHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	u, err := h.userService.GetUser(r.Context(), dtos.GetUserInput{ID: &claims.UserID})
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrUserNotFound):
+			apperrors.WriteError(w, apperrors.NewNotFoundError("User not found"))
+		default:
+			apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to get user"))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dtos.GetUserOutput{
+		ID:     u.ID,
+		Name:   u.Name,
+		Email:  u.Email,
+		Role:   u.Role.String(),
+		Status: u.Status.String(),
+	})
+}
+
+// godoc ChangePassword
+// @Summary Change Password
+// @Description Change the authenticated user's password. The current password must be given.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param changePasswordInput body dtos.ChangePasswordInput true "Current and new password"
+// @Success 204 "Password changed"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 401 {object} apperrors.AppError "Unauthorized"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /users/me/password [post]
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+
+	var input dtos.ChangePasswordInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid request body"))
+		return
+	}
+	if err := validate.Struct(input); err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return
+	}
+	input.UserID = claims.UserID
+
+	if err := h.userService.ChangePassword(r.Context(), input); err != nil {
+		switch {
+		case errors.Is(err, user.ErrWrongPassword):
+			apperrors.WriteError(w, apperrors.NewUnauthorizedError(err.Error()))
+		case errors.Is(err, user.ErrUserNotFound):
+			apperrors.WriteError(w, apperrors.NewNotFoundError("User not found"))
+		default:
+			apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to change password"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // godoc UpdateUser
 // @Summary Update User
 // @Description Update a user by ID
@@ -133,6 +225,47 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// godoc DeleteUser
+// @Summary Delete User
+// @Description Soft-delete a user. They can no longer sign in or be assigned tasks, but their tasks and messages are kept. Employers only.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 204 "User deleted"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 401 {object} apperrors.AppError "Unauthorized"
+// @Failure 403 {object} apperrors.AppError "Forbidden"
+// @Failure 404 {object} apperrors.AppError "Not Found"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /users/{id} [delete]
+func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+		return
+	}
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid user ID"))
+		return
+	}
+
+	if err := h.userService.DeleteUser(r.Context(), claims.UserID, userID); err != nil {
+		switch {
+		case errors.Is(err, user.ErrForbidden):
+			apperrors.WriteError(w, apperrors.NewForbiddenError(err.Error()))
+		case errors.Is(err, user.ErrUserNotFound):
+			apperrors.WriteError(w, apperrors.NewNotFoundError("User not found"))
+		default:
+			apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to delete user"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // godoc ListUsers
 // @Summary List Users
 // @Description List all users
@@ -140,21 +273,44 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param limit query integer false "Number of users to return" default(20)
+// @Param offset query integer false "Number of users to skip" default(0)
+// @Param search query string false "Match a substring of the name or email"
+// @Param role query string false "Only users with this role" Enums(employee, manager, employer)
+// @Param status query string false "Only users with this status" Enums(active, inactive)
+// @Param sort_by query string false "Field to sort by" Enums(name, email, role, created_at)
+// @Param sort_order query string false "Sort direction" Enums(asc, desc) default(asc)
 // @Success 200 {object} []user.User "List users response"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
 // @Failure 500 {object} apperrors.AppError "Internal Server Error"
 // @Router /users [get]
 func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
-	offset := 0
-	limit := 10
+	limit, offset, err := h.paginator.Parse(r)
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return
+	}
 
 	// Get users
-	users, err := h.userService.ListUsers(r.Context(), dtos.ListUsersInput{
+	users, total, err := h.userService.ListUsers(r.Context(), dtos.ListUsersInput{
 		Offset: offset,
 		Limit:  limit,
+		Search: r.URL.Query().Get("search"),
+		SortBy: r.URL.Query().Get("sort_by"),
+		Sort:   r.URL.Query().Get("sort_order"),
+		Role:   r.URL.Query().Get("role"),
+		Status: r.URL.Query().Get("status"),
 	})
 	if err != nil {
-		apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to list users"))
+		switch {
+		case errors.Is(err, user.ErrInvalidSort),
+			errors.Is(err, user.ErrInvalidRole),
+			errors.Is(err, user.ErrInvalidStatus):
+			apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		default:
+			apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to list users"))
+		}
 		return
 	}
 
@@ -176,10 +332,128 @@ func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 		"meta": map[string]interface{}{
 			"offset": offset,
 			"limit":  limit,
-			"total":  len(usersResponse),
+			"total":  total,
 		},
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// godoc SearchUsers
+// @Summary Search Users
+// @Description Find users whose name starts with a prefix, for @-mention autocomplete
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Name prefix"
+// @Param limit query integer false "Maximum number of users to return"
+// @Param shared query boolean false "Only return users sharing a chat room with the caller"
+// @Success 200 {object} []map[string]interface{} "Matching users"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /users/search [get]
+func (h *UserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	limit, _, err := h.paginator.Parse(r)
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	input := dtos.SearchUsersInput{
+		Query: r.URL.Query().Get("q"),
+		Limit: limit,
+	}
+	if shared := r.URL.Query().Get("shared"); shared != "" {
+		scoped, err := strconv.ParseBool(shared)
+		if err != nil {
+			apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid shared flag"))
+			return
+		}
+		if scoped {
+			claims, ok := middleware.UserFromContext(r.Context())
+			if !ok {
+				apperrors.WriteError(w, apperrors.NewUnauthorizedError("User not found in context"))
+				return
+			}
+			input.SharedWith = &claims.UserID
+		}
+	}
+
+	users, err := h.userService.SearchUsers(r.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrEmptySearch):
+			apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		default:
+			apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to search users"))
+		}
+		return
+	}
+
+	// Keep the payload small; autocomplete only needs enough to render a mention
+	response := make([]map[string]interface{}, 0, len(users))
+	for _, u := range users {
+		response = append(response, map[string]interface{}{
+			"id":   u.ID,
+			"name": u.Name,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// godoc ListActiveUsers
+// @Summary List Active Users
+// @Description List users seen recently, most recent first
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param within query string false "How far back to look as a duration, e.g. 30m (default 15m)"
+// @Param limit query integer false "Maximum number of users to return"
+// @Success 200 {object} []map[string]interface{} "Recently active users"
+// @Failure 400 {object} apperrors.AppError "Bad Request"
+// @Failure 500 {object} apperrors.AppError "Internal Server Error"
+// @Router /users/active [get]
+func (h *UserHandler) ListActiveUsers(w http.ResponseWriter, r *http.Request) {
+	limit, _, err := h.paginator.Parse(r)
+	if err != nil {
+		apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		return
+	}
+
+	input := dtos.ListActiveUsersInput{Limit: limit}
+	if within := r.URL.Query().Get("within"); within != "" {
+		input.Within, err = time.ParseDuration(within)
+		if err != nil {
+			apperrors.WriteError(w, apperrors.NewBadRequestError("Invalid within duration"))
+			return
+		}
+	}
+
+	users, err := h.userService.ListActiveUsers(r.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrInvalidWindow):
+			apperrors.WriteError(w, apperrors.NewBadRequestError(err.Error()))
+		default:
+			apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to list active users"))
+		}
+		return
+	}
+
+	response := make([]map[string]interface{}, 0, len(users))
+	for _, u := range users {
+		response = append(response, map[string]interface{}{
+			"id":           u.ID,
+			"name":         u.Name,
+			"last_seen_at": u.LastSeenAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
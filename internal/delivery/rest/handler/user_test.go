@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
+	"github.com/personal/task-management/internal/domain/user"
+	"github.com/personal/task-management/internal/mocks"
+	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/stretchr/testify/suite"
+)
+
+type UserHandlerTestSuite struct {
+	suite.Suite
+	ctrl        *gomock.Controller
+	userService *mocks.MockUserService
+	handler     *UserHandler
+	callerID    uuid.UUID
+}
+
+func (suite *UserHandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.userService = mocks.NewMockUserService(suite.ctrl)
+	suite.handler = NewUserHandler(suite.userService, newTestPaginator())
+	suite.callerID = uuid.New()
+}
+
+func (suite *UserHandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func (suite *UserHandlerTestSuite) list(target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	suite.handler.ListUsers(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func (suite *UserHandlerTestSuite) TestListUsersAppliesSharedDefaultPageSize() {
+	suite.userService.EXPECT().ListUsers(gomock.Any(), dtos.ListUsersInput{Limit: testDefaultLimit}).Return([]*user.User{}, int64(0), nil)
+
+	rec := suite.list("/users")
+	suite.Equal(http.StatusOK, rec.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestListUsersCapsPageSize() {
+	suite.userService.EXPECT().ListUsers(gomock.Any(), dtos.ListUsersInput{Limit: testMaxLimit, Offset: 20}).Return([]*user.User{}, int64(0), nil)
+
+	rec := suite.list("/users?limit=500&offset=20")
+	suite.Equal(http.StatusOK, rec.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestListUsersRejectsInvalidLimit() {
+	rec := suite.list("/users?limit=many")
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestListUsersPassesSearchAndSort() {
+	suite.userService.EXPECT().ListUsers(gomock.Any(), dtos.ListUsersInput{
+		Limit:  testDefaultLimit,
+		Search: "carol",
+		SortBy: "email",
+		Sort:   "desc",
+	}).Return([]*user.User{}, int64(0), nil)
+
+	rec := suite.list("/users?search=carol&sort_by=email&sort_order=desc")
+	suite.Equal(http.StatusOK, rec.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestListUsersRejectsInvalidSort() {
+	suite.userService.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Return(nil, int64(0), user.ErrInvalidSort)
+
+	rec := suite.list("/users?sort_by=password")
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+// listBody decodes a ListUsers response
+func (suite *UserHandlerTestSuite) listBody(rec *httptest.ResponseRecorder) (names []string, meta map[string]interface{}) {
+	var body struct {
+		Users []map[string]interface{} `json:"users"`
+		Meta  map[string]interface{}   `json:"meta"`
+	}
+	suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&body))
+	for _, u := range body.Users {
+		names = append(names, u["name"].(string))
+	}
+	return names, body.Meta
+}
+
+func (suite *UserHandlerTestSuite) TestListUsersReportsTotalAcrossPages() {
+	page := []*user.User{{ID: uuid.New(), Name: "Carol"}, {ID: uuid.New(), Name: "Dave"}}
+	suite.userService.EXPECT().ListUsers(gomock.Any(), dtos.ListUsersInput{Limit: 2, Offset: 2}).Return(page, int64(5), nil)
+
+	rec := suite.list("/users?limit=2&offset=2")
+	suite.Equal(http.StatusOK, rec.Code)
+	names, meta := suite.listBody(rec)
+	suite.Equal([]string{"Carol", "Dave"}, names)
+	suite.Equal(map[string]interface{}{"offset": 2.0, "limit": 2.0, "total": 5.0}, meta)
+}
+
+func (suite *UserHandlerTestSuite) TestListUsersFiltersByRoleAndStatus() {
+	boss := &user.User{ID: uuid.New(), Name: "Boss", Role: user.Employer}
+	suite.userService.EXPECT().ListUsers(gomock.Any(), dtos.ListUsersInput{Limit: testDefaultLimit, Role: "employer", Status: "active"}).
+		Return([]*user.User{boss}, int64(1), nil)
+
+	rec := suite.list("/users?role=employer&status=active")
+	suite.Equal(http.StatusOK, rec.Code)
+	names, meta := suite.listBody(rec)
+	suite.Equal([]string{"Boss"}, names)
+	suite.Equal(1.0, meta["total"])
+}
+
+func (suite *UserHandlerTestSuite) TestListUsersRejectsUnknownRoleOrStatus() {
+	suite.userService.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Return(nil, int64(0), user.ErrInvalidRole)
+	rec := suite.list("/users?role=admin")
+	suite.Equal(http.StatusBadRequest, rec.Code)
+
+	suite.userService.EXPECT().ListUsers(gomock.Any(), gomock.Any()).Return(nil, int64(0), user.ErrInvalidStatus)
+	rec = suite.list("/users?status=banned")
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestListUsersSearchesByName() {
+	carol := &user.User{ID: uuid.New(), Name: "Carol", Email: "carol@example.com"}
+	suite.userService.EXPECT().ListUsers(gomock.Any(), dtos.ListUsersInput{Limit: testDefaultLimit, Search: "car"}).
+		Return([]*user.User{carol}, int64(1), nil)
+
+	rec := suite.list("/users?search=car")
+	suite.Equal(http.StatusOK, rec.Code)
+	names, _ := suite.listBody(rec)
+	suite.Equal([]string{"Carol"}, names)
+}
+
+func (suite *UserHandlerTestSuite) search(target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req = req.WithContext(middleware.WithClaims(req.Context(), &jwt.UserClaims{UserID: suite.callerID}))
+	suite.handler.SearchUsers(rec, req)
+	return rec
+}
+
+func (suite *UserHandlerTestSuite) TestSearchUsersReturnsIDAndName() {
+	alice := &user.User{ID: uuid.New(), Name: "Alice", Email: "alice@example.com"}
+	suite.userService.EXPECT().SearchUsers(gomock.Any(), dtos.SearchUsersInput{Query: "al", Limit: 5}).Return([]*user.User{alice}, nil)
+
+	rec := suite.search("/users/search?q=al&limit=5")
+	suite.Equal(http.StatusOK, rec.Code)
+
+	var body []map[string]interface{}
+	suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&body))
+	suite.Equal([]map[string]interface{}{{"id": alice.ID.String(), "name": "Alice"}}, body)
+}
+
+func (suite *UserHandlerTestSuite) TestSearchUsersCapsLimit() {
+	suite.userService.EXPECT().SearchUsers(gomock.Any(), dtos.SearchUsersInput{Query: "al", Limit: testMaxLimit}).Return([]*user.User{}, nil)
+
+	rec := suite.search("/users/search?q=al&limit=500")
+	suite.Equal(http.StatusOK, rec.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestSearchUsersScopesToSharedRooms() {
+	suite.userService.EXPECT().SearchUsers(gomock.Any(), dtos.SearchUsersInput{Query: "al", Limit: testDefaultLimit, SharedWith: &suite.callerID}).Return([]*user.User{}, nil)
+
+	rec := suite.search("/users/search?q=al&shared=true")
+	suite.Equal(http.StatusOK, rec.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestSearchUsersRejectsEmptyQuery() {
+	suite.userService.EXPECT().SearchUsers(gomock.Any(), gomock.Any()).Return(nil, user.ErrEmptySearch)
+
+	rec := suite.search("/users/search?q=")
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *UserHandlerTestSuite) active(target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	suite.handler.ListActiveUsers(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func (suite *UserHandlerTestSuite) TestListActiveUsersParsesWindow() {
+	seen := time.Now()
+	bob := &user.User{ID: uuid.New(), Name: "Bob", LastSeenAt: &seen}
+	suite.userService.EXPECT().ListActiveUsers(gomock.Any(), dtos.ListActiveUsersInput{Within: 30 * time.Minute, Limit: 5}).
+		Return([]*user.User{bob}, nil)
+
+	rec := suite.active("/users/active?within=30m&limit=5")
+	suite.Equal(http.StatusOK, rec.Code)
+	var body []map[string]interface{}
+	suite.NoError(json.NewDecoder(rec.Body).Decode(&body))
+	suite.Require().Len(body, 1)
+	suite.Equal("Bob", body[0]["name"])
+	suite.NotEmpty(body[0]["last_seen_at"])
+}
+
+func (suite *UserHandlerTestSuite) TestListActiveUsersDefaultsWindow() {
+	suite.userService.EXPECT().ListActiveUsers(gomock.Any(), dtos.ListActiveUsersInput{Limit: testDefaultLimit}).Return([]*user.User{}, nil)
+
+	rec := suite.active("/users/active")
+	suite.Equal(http.StatusOK, rec.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestListActiveUsersRejectsBadWindow() {
+	rec := suite.active("/users/active?within=soon")
+	suite.Equal(http.StatusBadRequest, rec.Code)
+
+	suite.userService.EXPECT().ListActiveUsers(gomock.Any(), gomock.Any()).Return(nil, user.ErrInvalidWindow)
+	rec = suite.active("/users/active?within=-5m")
+	suite.Equal(http.StatusBadRequest, rec.Code)
+}
+
+func (suite *UserHandlerTestSuite) delete(id string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/users/"+id, nil)
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("id", id)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+	req = req.WithContext(middleware.WithClaims(ctx, &jwt.UserClaims{UserID: suite.callerID}))
+	suite.handler.DeleteUser(rec, req)
+	return rec
+}
+
+func (suite *UserHandlerTestSuite) TestDeleteUser() {
+	target := uuid.New()
+	suite.userService.EXPECT().DeleteUser(gomock.Any(), suite.callerID, target).Return(nil)
+
+	rec := suite.delete(target.String())
+	suite.Equal(http.StatusNoContent, rec.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestDeleteUserErrors() {
+	suite.Equal(http.StatusBadRequest, suite.delete("not-a-uuid").Code)
+
+	target := uuid.New()
+	suite.userService.EXPECT().DeleteUser(gomock.Any(), suite.callerID, target).Return(user.ErrForbidden)
+	suite.Equal(http.StatusForbidden, suite.delete(target.String()).Code)
+
+	suite.userService.EXPECT().DeleteUser(gomock.Any(), suite.callerID, target).Return(user.ErrUserNotFound)
+	suite.Equal(http.StatusNotFound, suite.delete(target.String()).Code)
+}
+
+func (suite *UserHandlerTestSuite) TestGetCurrentUserThroughRoute() {
+	tokens := mocks.NewMockJWTTokenServicer(suite.ctrl)
+	tokens.EXPECT().ValidateToken("access").Return(&jwt.UserClaims{UserID: suite.callerID, Role: "employee"}, nil)
+	me := &user.User{ID: suite.callerID, Email: "erin@example.com", Name: "Erin", Role: user.Employee, Status: user.StatusActive}
+	suite.userService.EXPECT().GetUser(gomock.Any(), dtos.GetUserInput{ID: &suite.callerID}).Return(me, nil)
+
+	router := chi.NewRouter()
+	router.Get("/users/me", middleware.Use(suite.handler.GetCurrentUser, middleware.AuthMiddleware(tokens)))
+	router.Get("/users/{id}", suite.handler.GetUser)
+	req := httptest.NewRequest(http.MethodGet, "/users/me", nil)
+	req.Header.Set("Authorization", "Bearer access")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	suite.Equal(http.StatusOK, rec.Code)
+	var body map[string]interface{}
+	suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&body))
+	suite.Equal(map[string]interface{}{
+		"id":     suite.callerID.String(),
+		"email":  "erin@example.com",
+		"name":   "Erin",
+		"role":   "employee",
+		"status": "active",
+	}, body)
+}
+
+func (suite *UserHandlerTestSuite) TestGetCurrentUserNeedsToken() {
+	tokens := mocks.NewMockJWTTokenServicer(suite.ctrl)
+	rec := httptest.NewRecorder()
+	middleware.Use(suite.handler.GetCurrentUser, middleware.AuthMiddleware(tokens))(rec, httptest.NewRequest(http.MethodGet, "/users/me", nil))
+	suite.Equal(http.StatusUnauthorized, rec.Code)
+}
+
+func (suite *UserHandlerTestSuite) changePassword(body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users/me/password", strings.NewReader(body))
+	req = req.WithContext(middleware.WithClaims(req.Context(), &jwt.UserClaims{UserID: suite.callerID}))
+	suite.handler.ChangePassword(rec, req)
+	return rec
+}
+
+func (suite *UserHandlerTestSuite) TestChangePassword() {
+	suite.userService.EXPECT().ChangePassword(gomock.Any(), dtos.ChangePasswordInput{
+		UserID:          suite.callerID,
+		CurrentPassword: "old-password",
+		NewPassword:     "new-passw0rd",
+	}).Return(nil)
+
+	rec := suite.changePassword(`{"current_password":"old-password","new_password":"new-passw0rd"}`)
+	suite.Equal(http.StatusNoContent, rec.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestChangePasswordWithWrongCurrentPassword() {
+	suite.userService.EXPECT().ChangePassword(gomock.Any(), gomock.Any()).Return(user.ErrWrongPassword)
+
+	rec := suite.changePassword(`{"current_password":"guess","new_password":"new-passw0rd"}`)
+	suite.Equal(http.StatusUnauthorized, rec.Code)
+}
+
+func (suite *UserHandlerTestSuite) TestChangePasswordValidatesInput() {
+	for _, body := range []string{
+		`{"new_password":"new-passw0rd"}`,
+		`{"current_password":"old-password","new_password":"short1"}`,
+		`{"current_password":"old-password","new_password":"no-digits-here"}`,
+		`not json`,
+	} {
+		suite.Equal(http.StatusBadRequest, suite.changePassword(body).Code, body)
+	}
+}
+
+func TestUserHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(UserHandlerTestSuite))
+}
@@ -1,8 +1,13 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/google/uuid"
@@ -13,32 +18,191 @@ import (
 
 type CasbinRBACService interface {
 	HasPermission(role user.Role, resource string, action string) bool
-	ApplyResourceFilter(r *http.Request, role user.Role, userID uuid.UUID)
+	ApplyResourceFilter(r *http.Request, role user.Role, userID uuid.UUID) *http.Request
+	ReloadPolicy() error
+	Permissions(role user.Role) ([]Permission, error)
+	Policies() ([]Policy, error)
+	AddPolicy(policy Policy) (bool, error)
+	RemovePolicy(policy Policy) (bool, error)
+}
+
+// Permission is a resource/action pair a role is allowed
+type Permission struct {
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+}
+
+// Policy grants a role an action on a resource
+type Policy struct {
+	Role     string `json:"role"`
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+}
+
+var (
+	ErrInvalidPolicy   = errors.New("invalid policy")
+	ErrProtectedPolicy = errors.New("employers' admin policies can't be removed")
+)
+
+// policyResources are the resources GetResourceFromPath resolves requests to
+var policyResources = map[string]bool{
+	"tasks": true, "task_templates": true, "task_comments": true, "task_tags": true,
+	"users": true, "admin": true, "chat": true, "notifications": true,
+}
+
+// Validate checks the policy names a user.Role, a known resource and action,
+// so a typo can't silently grant nothing
+func (p Policy) Validate() error {
+	if user.ParseRole(p.Role) == user.Unknown {
+		return fmt.Errorf("%w: unknown role %q", ErrInvalidPolicy, p.Role)
+	}
+	if !policyResources[p.Resource] {
+		return fmt.Errorf("%w: unknown resource %q", ErrInvalidPolicy, p.Resource)
+	}
+	switch p.Action {
+	case "create", "read", "update", "delete":
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidPolicy, p.Action)
+	}
+	return nil
 }
 
 // CasbinRBACService handles role-based access control using Casbin
 type casbinRBACService struct {
+	// mu keeps permission checks from reading a half-loaded policy
+	mu       sync.RWMutex
 	enforcer *casbin.Enforcer
 }
 
+// defaultPolicies are what a fresh database starts with. Comment authorship,
+// task participation and room membership are checked by the services, and
+// everyone reads and marks their own notifications and sets their own
+// notification preferences.
+var defaultPolicies = func() []Policy {
+	policies := []Policy{
+		{Role: "employer", Resource: "tasks", Action: "create"},
+		{Role: "employer", Resource: "tasks", Action: "read"},
+		{Role: "employer", Resource: "tasks", Action: "update"},
+		{Role: "employer", Resource: "tasks", Action: "delete"},
+		{Role: "employer", Resource: "users", Action: "create"},
+		{Role: "employer", Resource: "users", Action: "read"},
+		{Role: "employer", Resource: "users", Action: "update"},
+		{Role: "employer", Resource: "users", Action: "delete"},
+		{Role: "employer", Resource: "admin", Action: "create"},
+		{Role: "employer", Resource: "admin", Action: "read"},
+		{Role: "employer", Resource: "admin", Action: "update"},
+		{Role: "employer", Resource: "admin", Action: "delete"},
+		{Role: "employer", Resource: "task_templates", Action: "create"},
+		{Role: "employer", Resource: "task_templates", Action: "read"},
+		{Role: "employer", Resource: "task_templates", Action: "update"},
+		{Role: "employer", Resource: "task_templates", Action: "delete"},
+		{Role: "employee", Resource: "tasks", Action: "read"},
+		{Role: "employee", Resource: "tasks", Action: "update"},
+		{Role: "employee", Resource: "users", Action: "read"},
+	}
+	for _, role := range []string{"employer", "employee"} {
+		for _, resource := range []string{"task_comments", "task_tags"} {
+			for _, action := range []string{"create", "read", "delete"} {
+				policies = append(policies, Policy{Role: role, Resource: resource, Action: action})
+			}
+		}
+		for _, action := range []string{"create", "read", "update", "delete"} {
+			policies = append(policies, Policy{Role: role, Resource: "chat", Action: action})
+		}
+		for _, action := range []string{"create", "read", "update"} {
+			policies = append(policies, Policy{Role: role, Resource: "notifications", Action: action})
+		}
+	}
+	return policies
+}()
+
+// defaultGroupings let managers do whatever employees may, across every task
+// rather than only their own, and employers whatever managers may
+var defaultGroupings = [][]string{
+	{"manager", "employee"},
+	{"employer", "manager"},
+}
+
+// defaultPoliciesVersion must be bumped whenever defaultPolicies or
+// defaultGroupings change, so existing databases pick up the new defaults
+const defaultPoliciesVersion = 1
+
+// policySeed records which version of the default policies a database has
+// been given
+type policySeed struct {
+	Version  int `gorm:"primaryKey"`
+	SeededAt time.Time
+}
+
+func (policySeed) TableName() string {
+	return "casbin_policy_seeds"
+}
+
+// seedDefaultPolicies adds whichever default policies and role groupings are
+// missing, once per defaultPoliciesVersion. Policies removed through the admin
+// endpoints after that stay removed across restarts until the version changes.
+func seedDefaultPolicies(enforcer *casbin.Enforcer, db *gorm.DB) error {
+	if err := db.AutoMigrate(&policySeed{}); err != nil {
+		return fmt.Errorf("failed to migrate policy seeds: %w", err)
+	}
+	var seeded int64
+	if err := db.Model(&policySeed{}).Where("version >= ?", defaultPoliciesVersion).Count(&seeded).Error; err != nil {
+		return fmt.Errorf("failed to read policy seeds: %w", err)
+	}
+	if seeded > 0 {
+		return nil
+	}
+
+	var missing [][]string
+	for _, policy := range defaultPolicies {
+		if err := policy.Validate(); err != nil {
+			return err
+		}
+		has, err := enforcer.HasPolicy(policy.Role, policy.Resource, policy.Action)
+		if err != nil {
+			return fmt.Errorf("failed to check policy: %w", err)
+		}
+		if !has {
+			missing = append(missing, []string{policy.Role, policy.Resource, policy.Action})
+		}
+	}
+	if len(missing) > 0 {
+		if _, err := enforcer.AddPolicies(missing); err != nil {
+			return fmt.Errorf("failed to seed policies: %w", err)
+		}
+	}
+
+	missing = nil
+	for _, grouping := range defaultGroupings {
+		has, err := enforcer.HasGroupingPolicy(grouping[0], grouping[1])
+		if err != nil {
+			return fmt.Errorf("failed to check role grouping: %w", err)
+		}
+		if !has {
+			missing = append(missing, grouping)
+		}
+	}
+	if len(missing) > 0 {
+		if _, err := enforcer.AddGroupingPolicies(missing); err != nil {
+			return fmt.Errorf("failed to seed role grouping: %w", err)
+		}
+	}
+
+	if err := db.Create(&policySeed{Version: defaultPoliciesVersion, SeededAt: time.Now()}).Error; err != nil {
+		return fmt.Errorf("failed to record policy seed: %w", err)
+	}
+	return nil
+}
+
 // NewCasbinRBACService creates a new Casbin RBAC service
 func NewCasbinRBACService(cfg *viper.Viper, db *gorm.DB) (CasbinRBACService, error) {
 	enforcer, err := newCasbinEnforcer(cfg, db)
 	if err != nil {
 		return nil, err
 	}
-	// add policy to the enforcer
-	enforcer.AddPolicy("employer", "tasks", "create")
-	enforcer.AddPolicy("employer", "tasks", "read")
-	enforcer.AddPolicy("employer", "tasks", "update")
-	enforcer.AddPolicy("employer", "tasks", "delete")
-	enforcer.AddPolicy("employer", "users", "create")
-	enforcer.AddPolicy("employer", "users", "read")
-	enforcer.AddPolicy("employer", "users", "update")
-	enforcer.AddPolicy("employer", "users", "delete")
-	enforcer.AddPolicy("employee", "tasks", "read")
-	enforcer.AddPolicy("employee", "tasks", "update")
-	enforcer.AddPolicy("employee", "users", "read")
+	if err := seedDefaultPolicies(enforcer, db); err != nil {
+		return nil, err
+	}
 	service := &casbinRBACService{
 		enforcer: enforcer,
 	}
@@ -52,6 +216,8 @@ func (s *casbinRBACService) HasPermission(role user.Role, resource string, actio
 	roleStr := role.String()
 
 	// Check permission using Casbin
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	ok, err := s.enforcer.Enforce(roleStr, resource, action)
 	if err != nil {
 		return false
@@ -59,30 +225,149 @@ func (s *casbinRBACService) HasPermission(role user.Role, resource string, actio
 	return ok
 }
 
-// ApplyResourceFilter applies resource filtering based on user role and permissions
-func (s *casbinRBACService) ApplyResourceFilter(r *http.Request, role user.Role, userID uuid.UUID) {
-	// Get the resource from path
-	resource := GetResourceFromPath(r.URL.Path)
-	if resource == "" {
-		return
+// ReloadPolicy replaces the in-memory policy with the one stored in the
+// database, picking up changes made by other instances
+func (s *casbinRBACService) ReloadPolicy() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.enforcer.LoadPolicy(); err != nil {
+		return fmt.Errorf("failed to reload policy: %w", err)
 	}
+	return nil
+}
 
-	// For employees, add their user ID as a filter for their own resources
-	if role == user.Employee {
-		q := r.URL.Query()
-		q.Set("assignee_id", userID.String())
-		r.URL.RawQuery = q.Encode()
+// Permissions lists everything the role is allowed, including permissions
+// inherited through role grouping, sorted by resource then action
+func (s *casbinRBACService) Permissions(role user.Role) ([]Permission, error) {
+	s.mu.RLock()
+	rules, err := s.enforcer.GetImplicitPermissionsForUser(role.String())
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list permissions: %w", err)
 	}
+
+	permissions := make([]Permission, 0, len(rules))
+	seen := make(map[Permission]bool, len(rules))
+	for _, rule := range rules {
+		// Rules follow the model's policy_definition: sub, obj, act
+		if len(rule) < 3 {
+			continue
+		}
+		permission := Permission{Resource: rule[1], Action: rule[2]}
+		if seen[permission] {
+			continue
+		}
+		seen[permission] = true
+		permissions = append(permissions, permission)
+	}
+	sort.Slice(permissions, func(i, j int) bool {
+		if permissions[i].Resource != permissions[j].Resource {
+			return permissions[i].Resource < permissions[j].Resource
+		}
+		return permissions[i].Action < permissions[j].Action
+	})
+	return permissions, nil
+}
+
+// Policies lists every stored policy, sorted by role, resource then action
+func (s *casbinRBACService) Policies() ([]Policy, error) {
+	s.mu.RLock()
+	rules, err := s.enforcer.GetPolicy()
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	policies := make([]Policy, 0, len(rules))
+	for _, rule := range rules {
+		if len(rule) < 3 {
+			continue
+		}
+		policies = append(policies, Policy{Role: rule[0], Resource: rule[1], Action: rule[2]})
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		a, b := policies[i], policies[j]
+		if a.Role != b.Role {
+			return a.Role < b.Role
+		}
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		return a.Action < b.Action
+	})
+	return policies, nil
+}
+
+// AddPolicy grants the policy, saving it so other instances pick it up on
+// their next reload. It reports false when the policy already existed.
+func (s *casbinRBACService) AddPolicy(policy Policy) (bool, error) {
+	if err := policy.Validate(); err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	added, err := s.enforcer.AddPolicy(policy.Role, policy.Resource, policy.Action)
+	if err != nil {
+		return false, fmt.Errorf("failed to add policy: %w", err)
+	}
+	return added, nil
+}
+
+// RemovePolicy revokes the policy, reporting false when it didn't exist.
+// Employers keep their admin policies so policies can always be managed.
+func (s *casbinRBACService) RemovePolicy(policy Policy) (bool, error) {
+	if err := policy.Validate(); err != nil {
+		return false, err
+	}
+	if user.ParseRole(policy.Role) == user.Employer && policy.Resource == "admin" {
+		return false, ErrProtectedPolicy
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed, err := s.enforcer.RemovePolicy(policy.Role, policy.Resource, policy.Action)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove policy: %w", err)
+	}
+	return removed, nil
+}
+
+// ApplyResourceFilter returns r scoped to what the role may see of the
+// resource. Employees only ever list tasks assigned to them; handlers read the
+// scope with AssigneeScopeFromContext.
+func (s *casbinRBACService) ApplyResourceFilter(r *http.Request, role user.Role, userID uuid.UUID) *http.Request {
+	if role != user.Employee || GetResourceFromPath(r.URL.Path) != "tasks" {
+		return r
+	}
+	return r.WithContext(WithAssigneeScope(r.Context(), userID))
 }
 
 // GetResourceFromPath extracts the resource from the request path
 func GetResourceFromPath(path string) string {
 	if strings.HasPrefix(path, "/api/tasks") {
+		if strings.HasPrefix(path, "/api/tasks/templates") || strings.HasPrefix(path, "/api/tasks/from-template") {
+			return "task_templates"
+		}
+		if strings.Contains(path, "/comments") {
+			return "task_comments"
+		}
+		if strings.Contains(path, "/tags") {
+			return "task_tags"
+		}
 		return "tasks"
 	}
 	if strings.HasPrefix(path, "/api/users") {
 		return "users"
 	}
+	if strings.HasPrefix(path, "/api/admin") {
+		return "admin"
+	}
+	// /ws authenticates its own connections and doesn't pass through here
+	if strings.HasPrefix(path, "/api/chat") {
+		return "chat"
+	}
+	if strings.HasPrefix(path, "/api/notifications") {
+		return "notifications"
+	}
 	return ""
 }
 
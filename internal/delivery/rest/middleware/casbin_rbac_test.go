@@ -0,0 +1,321 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain/user"
+	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type CasbinRBACServiceTestSuite struct {
+	suite.Suite
+	cfg     *viper.Viper
+	db      *gorm.DB
+	service CasbinRBACService
+}
+
+func (suite *CasbinRBACServiceTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	suite.Require().NoError(err)
+	sqlDB, err := db.DB()
+	suite.Require().NoError(err)
+	sqlDB.SetMaxOpenConns(1)
+	suite.db = db
+
+	suite.cfg = viper.New()
+	suite.cfg.Set("casbin.model_path", "../../../../config/rbac_model.conf")
+
+	suite.service, err = NewCasbinRBACService(suite.cfg, db)
+	suite.Require().NoError(err)
+}
+
+func (suite *CasbinRBACServiceTestSuite) TearDownTest() {
+	sqlDB, _ := suite.db.DB()
+	sqlDB.Close()
+}
+
+func (suite *CasbinRBACServiceTestSuite) TestReloadPicksUpExternallyAddedPolicy() {
+	suite.False(suite.service.HasPermission(user.Employee, "admin", "read"))
+
+	// Another instance sharing the database grants the permission
+	other, err := newCasbinEnforcer(suite.cfg, suite.db)
+	suite.Require().NoError(err)
+	added, err := other.AddPolicy("employee", "admin", "read")
+	suite.Require().NoError(err)
+	suite.Require().True(added)
+
+	suite.False(suite.service.HasPermission(user.Employee, "admin", "read"))
+	suite.Require().NoError(suite.service.ReloadPolicy())
+	suite.True(suite.service.HasPermission(user.Employee, "admin", "read"))
+}
+
+func (suite *CasbinRBACServiceTestSuite) TestReloadPicksUpExternallyRemovedPolicy() {
+	suite.True(suite.service.HasPermission(user.Employee, "tasks", "read"))
+
+	other, err := newCasbinEnforcer(suite.cfg, suite.db)
+	suite.Require().NoError(err)
+	_, err = other.RemovePolicy("employee", "tasks", "read")
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(suite.service.ReloadPolicy())
+	suite.False(suite.service.HasPermission(user.Employee, "tasks", "read"))
+	suite.True(suite.service.HasPermission(user.Employer, "tasks", "read"))
+}
+
+func (suite *CasbinRBACServiceTestSuite) TestDefaultsAreSeededOnlyOnce() {
+	removed, err := suite.service.RemovePolicy(Policy{Role: "employee", Resource: "tasks", Action: "update"})
+	suite.Require().NoError(err)
+	suite.Require().True(removed)
+
+	// Restarting against the same database keeps the removal
+	restarted, err := NewCasbinRBACService(suite.cfg, suite.db)
+	suite.Require().NoError(err)
+	suite.False(restarted.HasPermission(user.Employee, "tasks", "update"))
+	suite.True(restarted.HasPermission(user.Employee, "tasks", "read"))
+	suite.True(restarted.HasPermission(user.Manager, "tasks", "read"))
+
+	policies, err := restarted.Policies()
+	suite.Require().NoError(err)
+	suite.Len(policies, len(defaultPolicies)-1)
+}
+
+func (suite *CasbinRBACServiceTestSuite) TestMissingDefaultsAreAddedToExistingPolicies() {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	suite.Require().NoError(err)
+	sqlDB, err := db.DB()
+	suite.Require().NoError(err)
+	sqlDB.SetMaxOpenConns(1)
+	defer sqlDB.Close()
+
+	// A database from before the policies were seeded by version holds only
+	// the original task and user rules
+	enforcer, err := newCasbinEnforcer(suite.cfg, db)
+	suite.Require().NoError(err)
+	for _, resource := range []string{"tasks", "users"} {
+		for _, action := range []string{"create", "read", "update", "delete"} {
+			_, err := enforcer.AddPolicy("employer", resource, action)
+			suite.Require().NoError(err)
+		}
+	}
+	for _, rule := range [][]string{{"tasks", "read"}, {"tasks", "update"}, {"users", "read"}} {
+		_, err := enforcer.AddPolicy("employee", rule[0], rule[1])
+		suite.Require().NoError(err)
+	}
+
+	service, err := NewCasbinRBACService(suite.cfg, db)
+	suite.Require().NoError(err)
+	suite.True(service.HasPermission(user.Employee, "chat", "read"))
+	grouped, err := service.(*casbinRBACService).enforcer.HasGroupingPolicy("manager", "employee")
+	suite.Require().NoError(err)
+	suite.True(grouped)
+	suite.True(service.HasPermission(user.Manager, "tasks", "read"))
+	suite.True(service.HasPermission(user.Employer, "admin", "create"))
+	policies, err := service.Policies()
+	suite.Require().NoError(err)
+	suite.Len(policies, len(defaultPolicies))
+}
+
+func (suite *CasbinRBACServiceTestSuite) TestPermissionsDifferByRole() {
+	employee, err := suite.service.Permissions(user.Employee)
+	suite.Require().NoError(err)
+	employer, err := suite.service.Permissions(user.Employer)
+	suite.Require().NoError(err)
+	unknown, err := suite.service.Permissions(user.Unknown)
+	suite.Require().NoError(err)
+
+	suite.Contains(employee, Permission{Resource: "tasks", Action: "read"})
+	suite.NotContains(employee, Permission{Resource: "tasks", Action: "create"})
+	suite.NotContains(employee, Permission{Resource: "admin", Action: "read"})
+
+	suite.Contains(employer, Permission{Resource: "tasks", Action: "create"})
+	suite.Contains(employer, Permission{Resource: "admin", Action: "read"})
+	suite.Contains(employer, Permission{Resource: "admin", Action: "create"})
+	suite.Greater(len(employer), len(employee))
+
+	suite.Empty(unknown)
+}
+
+func (suite *CasbinRBACServiceTestSuite) TestManagersSitBetweenEmployeesAndEmployers() {
+	suite.True(suite.service.HasPermission(user.Manager, "tasks", "read"))
+	suite.True(suite.service.HasPermission(user.Manager, "tasks", "update"))
+	suite.True(suite.service.HasPermission(user.Manager, "users", "read"))
+	suite.False(suite.service.HasPermission(user.Manager, "tasks", "delete"))
+	suite.False(suite.service.HasPermission(user.Manager, "users", "delete"))
+	suite.False(suite.service.HasPermission(user.Manager, "admin", "read"))
+
+	// Whatever employees gain, managers and employers gain too
+	other, err := newCasbinEnforcer(suite.cfg, suite.db)
+	suite.Require().NoError(err)
+	_, err = other.AddPolicy("employee", "task_templates", "read")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.service.ReloadPolicy())
+	suite.True(suite.service.HasPermission(user.Manager, "task_templates", "read"))
+	suite.True(suite.service.HasPermission(user.Employer, "task_templates", "read"))
+
+	employee, err := suite.service.Permissions(user.Employee)
+	suite.Require().NoError(err)
+	manager, err := suite.service.Permissions(user.Manager)
+	suite.Require().NoError(err)
+	suite.ElementsMatch(employee, manager)
+}
+
+func (suite *CasbinRBACServiceTestSuite) TestManagerRoutes() {
+	suite.Equal(http.StatusOK, suite.authorize("manager", http.MethodGet, "/api/tasks"))
+	suite.Equal(http.StatusOK, suite.authorize("manager", http.MethodPut, "/api/tasks/1"))
+	suite.Equal(http.StatusForbidden, suite.authorize("manager", http.MethodDelete, "/api/users/1"))
+	suite.Equal(http.StatusUnauthorized, suite.authorize("contractor", http.MethodGet, "/api/tasks"))
+}
+
+func (suite *CasbinRBACServiceTestSuite) TestPermissionsAreSortedAndMatchEnforcement() {
+	permissions, err := suite.service.Permissions(user.Employer)
+	suite.Require().NoError(err)
+
+	for i, permission := range permissions {
+		suite.True(suite.service.HasPermission(user.Employer, permission.Resource, permission.Action))
+		if i > 0 {
+			prev := permissions[i-1]
+			suite.True(prev.Resource < permission.Resource ||
+				(prev.Resource == permission.Resource && prev.Action < permission.Action))
+		}
+	}
+}
+
+func (suite *CasbinRBACServiceTestSuite) TestPolicyChangesAreValidated() {
+	for _, policy := range []Policy{
+		{Role: "contractor", Resource: "tasks", Action: "read"},
+		{Role: "Employer", Resource: "tasks", Action: "read"},
+		{Role: "unknown", Resource: "tasks", Action: "read"},
+		{Role: "employee", Resource: "taks", Action: "read"},
+		{Role: "employee", Resource: "tasks", Action: "write"},
+	} {
+		_, err := suite.service.AddPolicy(policy)
+		suite.ErrorIs(err, ErrInvalidPolicy, "%+v", policy)
+	}
+
+	_, err := suite.service.RemovePolicy(Policy{Role: "employer", Resource: "admin", Action: "read"})
+	suite.ErrorIs(err, ErrProtectedPolicy)
+	suite.True(suite.service.HasPermission(user.Employer, "admin", "read"))
+
+	removed, err := suite.service.RemovePolicy(Policy{Role: "employee", Resource: "admin", Action: "read"})
+	suite.NoError(err)
+	suite.False(removed)
+}
+
+func (suite *CasbinRBACServiceTestSuite) TestGetResourceFromPath() {
+	paths := map[string]string{
+		"/api/tasks/123":                    "tasks",
+		"/api/tasks/templates":              "task_templates",
+		"/api/users/me":                     "users",
+		"/api/chat/rooms":                   "chat",
+		"/api/chat/rooms/room-1/messages":   "chat",
+		"/api/admin/chat/rooms/1/slow-mode": "admin",
+		"/api/notifications/unread-count":   "notifications",
+		"/ws":                               "",
+	}
+	for path, resource := range paths {
+		suite.Equal(resource, GetResourceFromPath(path), path)
+	}
+}
+
+// authorize runs a request from a caller with the role through
+// AuthorizationMiddleware, returning the status it ended with
+func (suite *CasbinRBACServiceTestSuite) authorize(role, method, path string) int {
+	handler := AuthorizationMiddleware(nil, suite.service)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest(method, path, nil)
+	req = req.WithContext(WithClaims(req.Context(), &jwt.UserClaims{UserID: uuid.New(), Role: role}))
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec.Code
+}
+
+func (suite *CasbinRBACServiceTestSuite) TestChatRoutesPassAuthorization() {
+	// Every chat and notification route, so one added without its policy fails here
+	requests := []struct{ method, path string }{
+		{http.MethodPost, "/api/chat/rooms/direct"},
+		{http.MethodPost, "/api/chat/rooms/group"},
+		{http.MethodGet, "/api/chat/rooms"},
+		{http.MethodGet, "/api/chat/rooms/room-1"},
+		{http.MethodGet, "/api/chat/rooms/room-1/history"},
+		{http.MethodPost, "/api/chat/rooms/room-1/join"},
+		{http.MethodPost, "/api/chat/rooms/room-1/leave"},
+		{http.MethodPut, "/api/chat/rooms/room-1"},
+		{http.MethodDelete, "/api/chat/rooms/room-1"},
+		{http.MethodGet, "/api/chat/unread-counts"},
+		{http.MethodGet, "/api/chat/search"},
+		{http.MethodPost, "/api/chat/upload"},
+		{http.MethodGet, "/api/chat/rooms/room-1/messages"},
+		{http.MethodPost, "/api/chat/rooms/room-1/messages"},
+		{http.MethodPost, "/api/chat/direct/user-1/messages"},
+		{http.MethodGet, "/api/chat/direct/user-1/room-id"},
+		{http.MethodPost, "/api/chat/rooms/room-1/messages/msg-1/read"},
+		{http.MethodGet, "/api/chat/rooms/room-1/messages/msg-1/read-receipts"},
+		{http.MethodPost, "/api/chat/rooms/room-1/messages/msg-1/pin"},
+		{http.MethodDelete, "/api/chat/rooms/room-1/messages/msg-1/pin"},
+		{http.MethodGet, "/api/chat/rooms/room-1/pinned"},
+		{http.MethodPost, "/api/chat/rooms/room-1/archive"},
+		{http.MethodPost, "/api/chat/rooms/room-1/unarchive"},
+		{http.MethodPost, "/api/chat/rooms/room-1/mute"},
+		{http.MethodPost, "/api/chat/rooms/room-1/unmute"},
+		{http.MethodGet, "/api/chat/rooms/room-1/members"},
+		{http.MethodPost, "/api/chat/rooms/room-1/members"},
+		{http.MethodDelete, "/api/chat/rooms/room-1/members/user-1"},
+		{http.MethodPost, "/api/chat/rooms/room-1/members/user-1/promote"},
+		{http.MethodPost, "/api/chat/rooms/room-1/members/user-1/demote"},
+		{http.MethodPost, "/api/chat/rooms/room-1/members/user-1/mute"},
+		{http.MethodDelete, "/api/chat/rooms/room-1/members/user-1/mute"},
+		{http.MethodGet, "/api/notifications"},
+		{http.MethodGet, "/api/notifications/unread-count"},
+		{http.MethodPost, "/api/notifications/n-1/read"},
+		{http.MethodGet, "/api/notifications/preferences"},
+		{http.MethodPut, "/api/notifications/preferences"},
+	}
+	for _, role := range []string{"employee", "manager", "employer"} {
+		for _, req := range requests {
+			suite.Equal(http.StatusOK, suite.authorize(role, req.method, req.path), "%s %s %s", role, req.method, req.path)
+		}
+	}
+
+	// Chat admin routes stay with employers
+	suite.Equal(http.StatusOK, suite.authorize("employer", http.MethodGet, "/api/admin/chat-stats"))
+	suite.Equal(http.StatusForbidden, suite.authorize("employee", http.MethodGet, "/api/admin/chat-stats"))
+}
+
+func (suite *CasbinRBACServiceTestSuite) TestEmployeeTaskListingsAreScoped() {
+	employeeID := uuid.New()
+	scopeFor := func(role, path string, userID uuid.UUID) (uuid.UUID, bool) {
+		var scope uuid.UUID
+		var scoped bool
+		handler := AuthorizationMiddleware(nil, suite.service)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope, scoped = AssigneeScopeFromContext(r.Context())
+		}))
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req = req.WithContext(WithClaims(req.Context(), &jwt.UserClaims{UserID: userID, Role: role}))
+		handler(httptest.NewRecorder(), req)
+		return scope, scoped
+	}
+
+	scope, scoped := scopeFor("employee", "/api/tasks?assignee_id="+uuid.NewString(), employeeID)
+	suite.True(scoped)
+	suite.Equal(employeeID, scope)
+
+	_, scoped = scopeFor("employer", "/api/tasks", uuid.New())
+	suite.False(scoped)
+	_, scoped = scopeFor("manager", "/api/tasks", uuid.New())
+	suite.False(scoped)
+	_, scoped = scopeFor("employee", "/api/chat/rooms", employeeID)
+	suite.False(scoped)
+}
+
+func TestCasbinRBACServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(CasbinRBACServiceTestSuite))
+}
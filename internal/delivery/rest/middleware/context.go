@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/personal/task-management/pkg/utils/jwt"
+)
+
+// contextKey is unexported so no other package can collide with these keys
+type contextKey int
+
+const (
+	claimsKey contextKey = iota
+	userIDKey
+	requestIDKey
+	assigneeScopeKey
+)
+
+// WithClaims stores the authenticated caller's claims, and their user ID for
+// handlers that only need that, on the context
+func WithClaims(ctx context.Context, claims *jwt.UserClaims) context.Context {
+	ctx = context.WithValue(ctx, claimsKey, claims)
+	return context.WithValue(ctx, userIDKey, claims.UserID)
+}
+
+// UserFromContext returns the claims stored by WithClaims. It never panics;
+// handlers should answer 401 when ok is false.
+func UserFromContext(ctx context.Context) (*jwt.UserClaims, bool) {
+	claims, ok := ctx.Value(claimsKey).(*jwt.UserClaims)
+	return claims, ok && claims != nil
+}
+
+// UserIDFromContext returns the caller's user ID stored by WithClaims
+func UserIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	userID, ok := ctx.Value(userIDKey).(uuid.UUID)
+	return userID, ok && userID != uuid.Nil
+}
+
+// WithRequestID stores the ID RequestID assigned to the request on the context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext returns the ID stored by WithRequestID, or "" outside
+// a request
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithAssigneeScope restricts the task listings of the request to tasks
+// assigned to assigneeID, whatever filter the caller asked for
+func WithAssigneeScope(ctx context.Context, assigneeID uuid.UUID) context.Context {
+	return context.WithValue(ctx, assigneeScopeKey, assigneeID)
+}
+
+// AssigneeScopeFromContext returns the assignee stored by WithAssigneeScope;
+// ok is false when the caller may list every task
+func AssigneeScopeFromContext(ctx context.Context) (uuid.UUID, bool) {
+	assigneeID, ok := ctx.Value(assigneeScopeKey).(uuid.UUID)
+	return assigneeID, ok && assigneeID != uuid.Nil
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// anyOrigin in cors.allowed_origins lets every origin through
+const anyOrigin = "*"
+
+const defaultCORSMaxAge = 10 * time.Minute
+
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	defaultCORSHeaders = []string{"Authorization", "Content-Type", "Idempotency-Key", RequestIDHeader}
+	// Headers scripts may read from responses besides the CORS-safelisted ones
+	corsExposedHeaders = []string{RequestIDHeader, "Retry-After"}
+)
+
+type corsPolicy struct {
+	origins     map[string]bool
+	allowAll    bool
+	credentials bool
+	methods     string
+	headers     string
+	maxAge      string
+}
+
+// CORS answers preflight requests and adds CORS headers to responses for the
+// origins in cors.allowed_origins, with cors.allowed_methods and
+// cors.allowed_headers allowed on them. Credentialed requests are only allowed
+// when a single explicit origin is configured. With no origins configured no
+// cross-origin request is allowed.
+func CORS(cfg *viper.Viper) func(http.Handler) http.Handler {
+	policy := newCORSPolicy(cfg)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+			allowed := policy.allows(origin)
+			if allowed {
+				policy.writeOrigin(w, origin)
+			}
+
+			if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// A preflight is answered here; without the headers below the
+			// browser won't send the request
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			if !allowed {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Methods", policy.methods)
+			w.Header().Set("Access-Control-Allow-Headers", policy.headers)
+			w.Header().Set("Access-Control-Max-Age", policy.maxAge)
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+func newCORSPolicy(cfg *viper.Viper) *corsPolicy {
+	policy := &corsPolicy{origins: make(map[string]bool)}
+	for _, origin := range cfg.GetStringSlice("cors.allowed_origins") {
+		origin = normalizeOrigin(origin)
+		if origin == anyOrigin {
+			policy.allowAll = true
+			continue
+		}
+		if origin != "" {
+			policy.origins[origin] = true
+		}
+	}
+	// Browsers refuse credentials with a wildcard, and echoing any of several
+	// origins back with credentials would hand each of them the user's session
+	policy.credentials = !policy.allowAll && len(policy.origins) == 1
+
+	methods := cfg.GetStringSlice("cors.allowed_methods")
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	policy.methods = strings.ToUpper(strings.Join(methods, ", "))
+
+	headers := cfg.GetStringSlice("cors.allowed_headers")
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	policy.headers = strings.Join(headers, ", ")
+
+	maxAge := cfg.GetDuration("cors.max_age")
+	if maxAge <= 0 {
+		maxAge = defaultCORSMaxAge
+	}
+	policy.maxAge = strconv.Itoa(int(maxAge.Seconds()))
+	return policy
+}
+
+func (p *corsPolicy) allows(origin string) bool {
+	return p.allowAll || p.origins[normalizeOrigin(origin)]
+}
+
+func (p *corsPolicy) writeOrigin(w http.ResponseWriter, origin string) {
+	if p.credentials {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	} else if p.allowAll {
+		w.Header().Set("Access-Control-Allow-Origin", anyOrigin)
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+	w.Header().Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+}
+
+// normalizeOrigin makes configured and received origins comparable
+func normalizeOrigin(origin string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+}
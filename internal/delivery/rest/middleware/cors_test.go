@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+type CORSMiddlewareTestSuite struct {
+	suite.Suite
+	// called records whether the request reached the handler
+	called bool
+}
+
+func (suite *CORSMiddlewareTestSuite) SetupTest() {
+	suite.called = false
+}
+
+func (suite *CORSMiddlewareTestSuite) serve(origins []string, req *http.Request) *httptest.ResponseRecorder {
+	cfg := viper.New()
+	cfg.Set("cors.allowed_origins", origins)
+	handler := CORS(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func preflight(origin, method string) *http.Request {
+	req := httptest.NewRequest(http.MethodOptions, "/api/chat/rooms", nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", method)
+	req.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+	return req
+}
+
+func (suite *CORSMiddlewareTestSuite) TestPreflight() {
+	rec := suite.serve([]string{"https://app.example.com/"}, preflight("https://app.example.com", http.MethodPost))
+
+	suite.Equal(http.StatusNoContent, rec.Code)
+	suite.False(suite.called)
+	suite.Equal("https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	suite.Equal("GET, POST, PUT, PATCH, DELETE", rec.Header().Get("Access-Control-Allow-Methods"))
+	suite.Equal("Authorization, Content-Type, Idempotency-Key, X-Request-ID", rec.Header().Get("Access-Control-Allow-Headers"))
+	suite.Equal("600", rec.Header().Get("Access-Control-Max-Age"))
+	suite.Contains(rec.Header().Values("Vary"), "Origin")
+
+	// Other origins get no CORS headers, so the browser stops there
+	rec = suite.serve([]string{"https://app.example.com"}, preflight("https://evil.example.com", http.MethodPost))
+	suite.Equal(http.StatusForbidden, rec.Code)
+	suite.False(suite.called)
+	suite.Empty(rec.Header().Get("Access-Control-Allow-Origin"))
+	suite.Empty(rec.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func (suite *CORSMiddlewareTestSuite) TestSimpleGet() {
+	req := httptest.NewRequest(http.MethodGet, "/api/chat/rooms", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec := suite.serve([]string{"https://app.example.com"}, req)
+
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.True(suite.called)
+	suite.Equal("https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	suite.Equal("true", rec.Header().Get("Access-Control-Allow-Credentials"))
+	suite.Equal("X-Request-ID, Retry-After", rec.Header().Get("Access-Control-Expose-Headers"))
+	suite.Empty(rec.Header().Get("Access-Control-Allow-Methods"))
+
+	// A disallowed origin still reaches the handler; the browser hides the response
+	req = httptest.NewRequest(http.MethodGet, "/api/chat/rooms", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec = suite.serve([]string{"https://app.example.com"}, req)
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Empty(rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func (suite *CORSMiddlewareTestSuite) TestCredentialsNeedASingleOrigin() {
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+	req.Header.Set("Origin", "https://b.example.com")
+	rec := suite.serve([]string{"https://a.example.com", "https://b.example.com"}, req)
+	suite.Equal("https://b.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	suite.Empty(rec.Header().Get("Access-Control-Allow-Credentials"))
+
+	rec = suite.serve([]string{"*"}, req)
+	suite.Equal("*", rec.Header().Get("Access-Control-Allow-Origin"))
+	suite.Empty(rec.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func (suite *CORSMiddlewareTestSuite) TestSameOriginRequestsPassThrough() {
+	rec := suite.serve(nil, httptest.NewRequest(http.MethodOptions, "/api/tasks", nil))
+	suite.True(suite.called)
+	suite.Empty(rec.Header().Get("Vary"))
+}
+
+func TestCORSMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(CORSMiddlewareTestSuite))
+}
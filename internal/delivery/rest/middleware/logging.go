@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/personal/task-management/pkg/logger"
+)
+
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID gives every request an ID, stored on the context and echoed in
+// the X-Request-ID response header. A UUID the client or a proxy already put
+// in that header is kept so the request can be followed across services;
+// anything else is replaced.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if _, err := uuid.Parse(requestID); err != nil {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// Logging logs the method, path, status, duration and request ID of every
+// request once it completes. Handlers log through logger.FromContext, which
+// gives them log tagged with the request ID. It has to run after RequestID.
+func Logging(log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			requestLog := log.With("request_id", RequestIDFromContext(r.Context()))
+			// The wrapper keeps http.Hijacker working for WebSocket upgrades
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r.WithContext(logger.WithContext(r.Context(), requestLog)))
+
+			// A handler that never wrote answered 200, unless it took the
+			// connection over for a WebSocket; failed upgrades write an error
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+				if websocket.IsWebSocketUpgrade(r) {
+					status = http.StatusSwitchingProtocols
+				}
+			}
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			requestLog.LogAttrs(r.Context(), level, "request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Duration("duration", time.Since(start)),
+				slog.Int("bytes", ww.BytesWritten()),
+			)
+		})
+	}
+}
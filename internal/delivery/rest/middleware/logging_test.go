@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/personal/task-management/pkg/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+type LoggingMiddlewareTestSuite struct {
+	suite.Suite
+	out bytes.Buffer
+	// seen is the request ID the handler found on its context
+	seen    string
+	handler http.Handler
+}
+
+func (suite *LoggingMiddlewareTestSuite) SetupTest() {
+	suite.out.Reset()
+	suite.seen = ""
+	log := logger.NewWithWriter(&suite.out, "info", "json")
+
+	var next http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.seen = RequestIDFromContext(r.Context())
+		logger.FromContext(r.Context()).Info("handling")
+		w.WriteHeader(http.StatusTeapot)
+	})
+	suite.handler = RequestID(Logging(log)(next))
+}
+
+// records decodes each JSON line that was logged
+func (suite *LoggingMiddlewareTestSuite) records() []map[string]any {
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(suite.out.String()), "\n") {
+		var record map[string]any
+		suite.Require().NoError(json.Unmarshal([]byte(line), &record), line)
+		records = append(records, record)
+	}
+	return records
+}
+
+func (suite *LoggingMiddlewareTestSuite) TestRequestIDIsSetAndPropagated() {
+	rec := httptest.NewRecorder()
+	suite.handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tasks?limit=5", nil))
+
+	requestID := rec.Header().Get(RequestIDHeader)
+	_, err := uuid.Parse(requestID)
+	suite.Require().NoError(err, requestID)
+	suite.Equal(requestID, suite.seen)
+
+	records := suite.records()
+	suite.Require().Len(records, 2)
+	// The handler's own log line carries the request ID
+	suite.Equal("handling", records[0]["msg"])
+	suite.Equal(requestID, records[0]["request_id"])
+
+	access := records[1]
+	suite.Equal("request", access["msg"])
+	suite.Equal(requestID, access["request_id"])
+	suite.Equal(http.MethodGet, access["method"])
+	suite.Equal("/api/tasks", access["path"])
+	suite.EqualValues(http.StatusTeapot, access["status"])
+	suite.Contains(access, "duration")
+}
+
+func (suite *LoggingMiddlewareTestSuite) TestIncomingRequestID() {
+	incoming := uuid.NewString()
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set(RequestIDHeader, incoming)
+	rec := httptest.NewRecorder()
+	suite.handler.ServeHTTP(rec, req)
+	suite.Equal(incoming, rec.Header().Get(RequestIDHeader))
+	suite.Equal(incoming, suite.seen)
+
+	// Anything but a UUID is replaced rather than copied into the logs
+	req = httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set(RequestIDHeader, "forged\nline")
+	rec = httptest.NewRecorder()
+	suite.handler.ServeHTTP(rec, req)
+	suite.NotEqual("forged\nline", rec.Header().Get(RequestIDHeader))
+	_, err := uuid.Parse(rec.Header().Get(RequestIDHeader))
+	suite.NoError(err)
+}
+
+func TestLoggingMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(LoggingMiddlewareTestSuite))
+}
@@ -2,10 +2,13 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/personal/task-management/internal/domain/user"
 	"github.com/personal/task-management/pkg/apperrors"
 	"github.com/personal/task-management/pkg/utils/jwt"
@@ -19,20 +22,42 @@ func Use(handler http.HandlerFunc, mid ...func(http.Handler) http.HandlerFunc) h
 	return handler
 }
 
-func AuthMiddleware(jwtService jwt.JWTTokenServicer) func(http.Handler) http.HandlerFunc {
+// ActiveUserChecker reports whether a user may still use the API
+type ActiveUserChecker interface {
+	IsUserActive(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+type authOptions struct {
+	checker    ActiveUserChecker
+	checkAfter time.Duration
+}
+
+// AuthOption configures AuthMiddleware
+type AuthOption func(*authOptions)
+
+// WithStatusCheck has AuthMiddleware look up the user behind tokens issued at
+// least after ago, refusing users who were deactivated since. Younger tokens
+// are trusted so most requests don't hit the database. It does nothing when
+// after isn't positive.
+func WithStatusCheck(checker ActiveUserChecker, after time.Duration) AuthOption {
+	return func(o *authOptions) {
+		if after > 0 {
+			o.checker = checker
+			o.checkAfter = after
+		}
+	}
+}
+
+func AuthMiddleware(jwtService jwt.JWTTokenServicer, opts ...AuthOption) func(http.Handler) http.HandlerFunc {
+	var options authOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 	return func(next http.Handler) http.HandlerFunc {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// bearer token
-			token := r.Header.Get("Authorization")
-			if token == "" {
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
-				return
-			}
-
-			// validate token
-			token = strings.TrimPrefix(token, "Bearer ")
-			if token == "" {
-				apperrors.WriteError(w, apperrors.NewUnauthorizedError("Invalid token"))
+			token, err := BearerToken(r.Header.Get("Authorization"))
+			if err != nil {
+				apperrors.WriteError(w, apperrors.NewUnauthorizedError(err.Error()))
 				return
 			}
 
@@ -42,20 +67,57 @@ func AuthMiddleware(jwtService jwt.JWTTokenServicer) func(http.Handler) http.Han
 				apperrors.WriteError(w, apperrors.NewUnauthorizedError("Invalid token"))
 				return
 			}
+			if options.checker != nil && (claims.IssuedAt == nil || time.Since(claims.IssuedAt.Time) >= options.checkAfter) {
+				active, err := options.checker.IsUserActive(r.Context(), claims.UserID)
+				if err != nil {
+					apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to check user status"))
+					return
+				}
+				if !active {
+					apperrors.WriteError(w, apperrors.NewUnauthorizedError("User is inactive"))
+					return
+				}
+			}
 			// set claims to request
-			ctx := context.WithValue(r.Context(), "user", claims)
-			r = r.WithContext(ctx)
+			r = r.WithContext(WithClaims(r.Context(), claims))
 			// call next handler
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// Authorization header errors, reported to the client as 401s
+var (
+	ErrMissingAuthorization   = errors.New("missing authorization header")
+	ErrUnsupportedAuthScheme  = errors.New("authorization scheme must be Bearer")
+	ErrMalformedAuthorization = errors.New("malformed authorization header")
+)
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>"
+// header. The scheme is matched case-insensitively and surrounding whitespace
+// is ignored, but the token itself may not contain spaces.
+func BearerToken(header string) (string, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return "", ErrMissingAuthorization
+	}
+
+	scheme, token, found := strings.Cut(header, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", ErrUnsupportedAuthScheme
+	}
+	token = strings.TrimSpace(token)
+	if !found || token == "" || strings.ContainsAny(token, " \t") {
+		return "", ErrMalformedAuthorization
+	}
+	return token, nil
+}
+
 // AuthorizationMiddleware enforces role-based access control using Casbin
 func AuthorizationMiddleware(jwtService jwt.JWTTokenServicer, rbacService CasbinRBACService) func(http.Handler) http.HandlerFunc {
 	return func(next http.Handler) http.HandlerFunc {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			claims, ok := r.Context().Value("user").(*jwt.UserClaims)
+			claims, ok := UserFromContext(r.Context())
 			if !ok {
 				apperrors.WriteError(w, apperrors.NewUnauthorizedError("Invalid claims"))
 				return
@@ -67,6 +129,8 @@ func AuthorizationMiddleware(jwtService jwt.JWTTokenServicer, rbacService Casbin
 				userRole = user.Employee
 			case "employer":
 				userRole = user.Employer
+			case "manager":
+				userRole = user.Manager
 			default:
 				apperrors.WriteError(w, apperrors.NewUnauthorizedError("Invalid role"))
 				return
@@ -88,9 +152,7 @@ func AuthorizationMiddleware(jwtService jwt.JWTTokenServicer, rbacService Casbin
 			}
 
 			// Apply resource filtering based on role
-			rbacService.ApplyResourceFilter(r, userRole, claims.UserID)
-			// store userID in context
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, rbacService.ApplyResourceFilter(r, userRole, claims.UserID))
 		})
 	}
 }
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/personal/task-management/pkg/apperrors"
+	localmemory "github.com/personal/task-management/pkg/cache/local-memory"
+	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuthMiddlewareTestSuite struct {
+	suite.Suite
+	tokens  jwt.JWTTokenServicer
+	token   string
+	handler http.HandlerFunc
+}
+
+func (suite *AuthMiddlewareTestSuite) SetupTest() {
+	cfg := viper.New()
+	cfg.Set("auth.jwt_secret", "test_secret_key")
+	cfg.Set("auth.jwt_expiration", time.Hour)
+	denylist, err := localmemory.NewCache(time.Minute)
+	suite.Require().NoError(err)
+	suite.T().Cleanup(func() { denylist.Close() })
+
+	suite.tokens = jwt.NewJWTTokenService(cfg, denylist)
+	suite.token, err = suite.tokens.GenerateToken(uuid.New(), "test@example.com", "employee")
+	suite.Require().NoError(err)
+
+	suite.handler = Use(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, AuthMiddleware(suite.tokens))
+}
+
+func (suite *AuthMiddlewareTestSuite) TestBearerToken() {
+	tests := []struct {
+		name   string
+		header string
+		token  string
+		err    error
+	}{
+		{name: "canonical", header: "Bearer abc.def", token: "abc.def"},
+		{name: "lowercase scheme", header: "bearer abc.def", token: "abc.def"},
+		{name: "uppercase scheme", header: "BEARER abc.def", token: "abc.def"},
+		{name: "extra spaces", header: "  Bearer   abc.def  ", token: "abc.def"},
+		{name: "empty", header: "", err: ErrMissingAuthorization},
+		{name: "whitespace only", header: "   ", err: ErrMissingAuthorization},
+		{name: "raw token", header: "abc.def", err: ErrUnsupportedAuthScheme},
+		{name: "basic scheme", header: "Basic dXNlcjpwYXNz", err: ErrUnsupportedAuthScheme},
+		{name: "scheme only", header: "Bearer", err: ErrMalformedAuthorization},
+		{name: "scheme with trailing space", header: "Bearer   ", err: ErrMalformedAuthorization},
+		{name: "two tokens", header: "Bearer abc def", err: ErrMalformedAuthorization},
+		{name: "tab in token", header: "Bearer abc\tdef", err: ErrMalformedAuthorization},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			token, err := BearerToken(tt.header)
+			if tt.err != nil {
+				suite.ErrorIs(err, tt.err)
+				suite.Empty(token)
+				return
+			}
+			suite.NoError(err)
+			suite.Equal(tt.token, token)
+		})
+	}
+}
+
+func (suite *AuthMiddlewareTestSuite) TestAuthMiddleware() {
+	tests := []struct {
+		name    string
+		header  string
+		status  int
+		message string
+	}{
+		{name: "valid", header: "Bearer " + suite.token, status: http.StatusOK},
+		{name: "lowercase scheme", header: "bearer " + suite.token, status: http.StatusOK},
+		{name: "double space", header: "Bearer  " + suite.token, status: http.StatusOK},
+		{name: "missing", header: "", status: http.StatusUnauthorized, message: ErrMissingAuthorization.Error()},
+		{name: "raw token", header: suite.token, status: http.StatusUnauthorized, message: ErrUnsupportedAuthScheme.Error()},
+		{name: "no token", header: "Bearer ", status: http.StatusUnauthorized, message: ErrMalformedAuthorization.Error()},
+		{name: "invalid token", header: "Bearer not-a-jwt", status: http.StatusUnauthorized, message: "Invalid token"},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			req := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			suite.handler(rec, req)
+
+			suite.Equal(tt.status, rec.Code)
+			if tt.message == "" {
+				return
+			}
+			var body struct {
+				Error apperrors.AppError `json:"error"`
+			}
+			suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&body))
+			suite.Equal(apperrors.Unauthorized, body.Error.Type)
+			suite.Equal(tt.message, body.Error.Message)
+		})
+	}
+}
+
+// activeUsers is an ActiveUserChecker recording the users it was asked about
+type activeUsers struct {
+	active  bool
+	err     error
+	checked []uuid.UUID
+}
+
+func (a *activeUsers) IsUserActive(_ context.Context, userID uuid.UUID) (bool, error) {
+	a.checked = append(a.checked, userID)
+	return a.active, a.err
+}
+
+func (suite *AuthMiddlewareTestSuite) serveChecked(checker ActiveUserChecker, after time.Duration) *httptest.ResponseRecorder {
+	handler := Use(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, AuthMiddleware(suite.tokens, WithStatusCheck(checker, after)))
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+suite.token)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+func (suite *AuthMiddlewareTestSuite) TestStatusCheck() {
+	// Tokens younger than the threshold are trusted
+	checker := &activeUsers{}
+	suite.Equal(http.StatusOK, suite.serveChecked(checker, time.Hour).Code)
+	suite.Empty(checker.checked)
+
+	// A threshold of 0 turns the check off
+	suite.Equal(http.StatusOK, suite.serveChecked(checker, 0).Code)
+	suite.Empty(checker.checked)
+
+	// Older tokens are refused once their user is deactivated
+	rec := suite.serveChecked(checker, time.Nanosecond)
+	suite.Equal(http.StatusUnauthorized, rec.Code)
+	suite.Len(checker.checked, 1)
+	suite.Contains(rec.Body.String(), "User is inactive")
+
+	checker.active = true
+	suite.Equal(http.StatusOK, suite.serveChecked(checker, time.Nanosecond).Code)
+
+	checker.err = errors.New("db down")
+	suite.Equal(http.StatusInternalServerError, suite.serveChecked(checker, time.Nanosecond).Code)
+}
+
+func TestAuthMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthMiddlewareTestSuite))
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain/task"
+	"github.com/personal/task-management/internal/domain/user"
+	"github.com/personal/task-management/pkg/apperrors"
+	"gorm.io/gorm"
+)
+
+// TaskFinder loads the task TaskOwnership checks; TaskRepository satisfies it
+type TaskFinder interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*task.Task, error)
+}
+
+// TaskOwnership refuses employees reading or updating a task they neither
+// created nor are assigned, before the handler runs. Employers and managers
+// may see every task. It goes after AuthMiddleware on routes whose {id} is a task ID.
+// Requests it can't judge, a malformed ID or a missing task, are left to the
+// handler to report.
+func TaskOwnership(tasks TaskFinder) func(http.Handler) http.HandlerFunc {
+	return func(next http.Handler) http.HandlerFunc {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := UserFromContext(r.Context())
+			if !ok {
+				apperrors.WriteError(w, apperrors.NewUnauthorizedError("Invalid claims"))
+				return
+			}
+			action := GetActionFromMethod(r.Method)
+			role := user.ParseRole(claims.Role)
+			if role == user.Employer || role == user.Manager || (action != "read" && action != "update") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			t, err := tasks.GetByID(r.Context(), taskID)
+			if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, task.ErrTaskNotFound) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err != nil {
+				apperrors.WriteError(w, apperrors.NewInternalServerError("Failed to check task ownership"))
+				return
+			}
+			if !t.IsAssignedTo(claims.UserID) && t.CreatorID != claims.UserID {
+				apperrors.WriteError(w, apperrors.NewForbiddenError("Permission denied: not your task"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
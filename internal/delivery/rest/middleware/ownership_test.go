@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain/task"
+	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+// taskFinder serves tasks from a map, failing with err when it is set
+type taskFinder struct {
+	tasks map[uuid.UUID]*task.Task
+	err   error
+}
+
+func (f *taskFinder) GetByID(_ context.Context, id uuid.UUID) (*task.Task, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	t, ok := f.tasks[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return t, nil
+}
+
+type TaskOwnershipTestSuite struct {
+	suite.Suite
+	finder   *taskFinder
+	task     *task.Task
+	assignee uuid.UUID
+	creator  uuid.UUID
+	// called records whether the request reached the handler
+	called bool
+}
+
+func (suite *TaskOwnershipTestSuite) SetupTest() {
+	suite.assignee = uuid.New()
+	suite.creator = uuid.New()
+	suite.task = &task.Task{ID: uuid.New(), AssigneeID: suite.assignee, CreatorID: suite.creator}
+	suite.finder = &taskFinder{tasks: map[uuid.UUID]*task.Task{suite.task.ID: suite.task}}
+	suite.called = false
+}
+
+func (suite *TaskOwnershipTestSuite) serve(method, taskID string, userID uuid.UUID, role string) int {
+	suite.called = false
+	router := chi.NewRouter()
+	router.HandleFunc("/api/tasks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		req := r.WithContext(WithClaims(r.Context(), &jwt.UserClaims{UserID: userID, Role: role}))
+		Use(func(w http.ResponseWriter, r *http.Request) {
+			suite.called = true
+			w.WriteHeader(http.StatusOK)
+		}, TaskOwnership(suite.finder))(w, req)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(method, "/api/tasks/"+taskID, nil))
+	return rec.Code
+}
+
+func (suite *TaskOwnershipTestSuite) TestOwnersPass() {
+	for _, owner := range []uuid.UUID{suite.assignee, suite.creator} {
+		for _, method := range []string{http.MethodGet, http.MethodPut} {
+			suite.Equal(http.StatusOK, suite.serve(method, suite.task.ID.String(), owner, "employee"))
+			suite.True(suite.called)
+		}
+	}
+}
+
+func (suite *TaskOwnershipTestSuite) TestOtherEmployeesAreForbidden() {
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodPatch} {
+		suite.Equal(http.StatusForbidden, suite.serve(method, suite.task.ID.String(), uuid.New(), "employee"), method)
+		suite.False(suite.called)
+	}
+}
+
+func (suite *TaskOwnershipTestSuite) TestEmployersAndManagersSeeEveryTask() {
+	for _, role := range []string{"employer", "manager"} {
+		for _, method := range []string{http.MethodGet, http.MethodPut} {
+			suite.Equal(http.StatusOK, suite.serve(method, suite.task.ID.String(), uuid.New(), role), role)
+			suite.True(suite.called)
+		}
+	}
+}
+
+func (suite *TaskOwnershipTestSuite) TestUnjudgedRequestsReachTheHandler() {
+	// The handler reports malformed IDs and missing tasks itself
+	suite.Equal(http.StatusOK, suite.serve(http.MethodGet, "not-a-uuid", uuid.New(), "employee"))
+	suite.True(suite.called)
+	suite.Equal(http.StatusOK, suite.serve(http.MethodGet, uuid.NewString(), uuid.New(), "employee"))
+	suite.True(suite.called)
+	// Only reads and updates are checked here
+	suite.Equal(http.StatusOK, suite.serve(http.MethodDelete, suite.task.ID.String(), uuid.New(), "employee"))
+	suite.True(suite.called)
+}
+
+func (suite *TaskOwnershipTestSuite) TestLookupFailure() {
+	suite.finder.err = errors.New("db down")
+	suite.Equal(http.StatusInternalServerError, suite.serve(http.MethodGet, suite.task.ID.String(), suite.assignee, "employee"))
+	suite.False(suite.called)
+}
+
+func TestTaskOwnershipTestSuite(t *testing.T) {
+	suite.Run(t, new(TaskOwnershipTestSuite))
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// PolicyReloadJob periodically reloads the Casbin policy so changes made by
+// other instances take effect. It satisfies server.Server so the app starts
+// and stops it alongside the HTTP server.
+type PolicyReloadJob struct {
+	rbacService CasbinRBACService
+	interval    time.Duration
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// NewPolicyReloadJob creates a job that runs every casbin.reload_interval.
+// The job does nothing when the interval is unset.
+func NewPolicyReloadJob(cfg *viper.Viper, rbacService CasbinRBACService) *PolicyReloadJob {
+	return &PolicyReloadJob{
+		rbacService: rbacService,
+		interval:    cfg.GetDuration("casbin.reload_interval"),
+	}
+}
+
+// Start runs the job in the background and returns immediately
+func (j *PolicyReloadJob) Start(ctx context.Context) error {
+	if j.interval <= 0 {
+		log.Println("casbin policy auto-reload is disabled")
+		return nil
+	}
+	ctx, j.cancel = context.WithCancel(ctx)
+	j.done = make(chan struct{})
+	go j.run(ctx)
+	return nil
+}
+
+// Stop cancels the job and waits for the current reload to finish
+func (j *PolicyReloadJob) Stop(ctx context.Context) error {
+	if j.cancel == nil {
+		return nil
+	}
+	j.cancel()
+	<-j.done
+	return nil
+}
+
+func (j *PolicyReloadJob) run(ctx context.Context) {
+	defer close(j.done)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := j.rbacService.ReloadPolicy(); err != nil {
+				log.Printf("error reloading casbin policy: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/personal/task-management/pkg/apperrors"
+	"github.com/personal/task-management/pkg/logger"
+)
+
+// Recover turns a panic in a later handler into a JSON 500, logging the panic
+// and its stack through the request's logger. http.ErrAbortHandler is
+// re-panicked so net/http still aborts the response as asked.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			logger.FromContext(r.Context()).Error("handler panicked",
+				"panic", fmt.Sprint(recovered),
+				"stack", string(debug.Stack()),
+			)
+			apperrors.WriteError(w, apperrors.NewInternalServerError("Internal server error"))
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/personal/task-management/pkg/apperrors"
+	"github.com/personal/task-management/pkg/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecoverMiddlewareTestSuite struct {
+	suite.Suite
+	out bytes.Buffer
+}
+
+func (suite *RecoverMiddlewareTestSuite) SetupTest() {
+	suite.out.Reset()
+}
+
+// serve runs the handler behind the same chain SetupRoutes uses
+func (suite *RecoverMiddlewareTestSuite) serve(handler http.HandlerFunc) *httptest.ResponseRecorder {
+	log := logger.NewWithWriter(&suite.out, "info", "json")
+	rec := httptest.NewRecorder()
+	RequestID(Logging(log)(Recover(handler))).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/chat/rooms", nil))
+	return rec
+}
+
+func (suite *RecoverMiddlewareTestSuite) TestPanicBecomesJSON500() {
+	rec := suite.serve(func(w http.ResponseWriter, r *http.Request) {
+		var claims map[string]string
+		claims["user_id"] = "user-1"
+	})
+
+	suite.Equal(http.StatusInternalServerError, rec.Code)
+	suite.Equal("application/json", rec.Header().Get("Content-Type"))
+	var body struct {
+		Error apperrors.AppError `json:"error"`
+	}
+	suite.Require().NoError(json.NewDecoder(rec.Body).Decode(&body))
+	suite.Equal(apperrors.InternalServer, body.Error.Type)
+	suite.Equal("Internal server error", body.Error.Message)
+
+	// The panic is logged with its stack, then the request as a 500
+	lines := strings.Split(strings.TrimSpace(suite.out.String()), "\n")
+	suite.Require().Len(lines, 2)
+	var panicked, access map[string]any
+	suite.Require().NoError(json.Unmarshal([]byte(lines[0]), &panicked))
+	suite.Require().NoError(json.Unmarshal([]byte(lines[1]), &access))
+	suite.Equal("handler panicked", panicked["msg"])
+	suite.Contains(panicked["panic"], "assignment to entry in nil map")
+	suite.Contains(panicked["stack"], "recover_test.go")
+	suite.Equal(rec.Header().Get(RequestIDHeader), panicked["request_id"])
+	suite.EqualValues(http.StatusInternalServerError, access["status"])
+}
+
+func (suite *RecoverMiddlewareTestSuite) TestAbortHandlerStillAborts() {
+	suite.PanicsWithValue(http.ErrAbortHandler, func() {
+		Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestRecoverMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(RecoverMiddlewareTestSuite))
+}
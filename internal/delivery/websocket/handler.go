@@ -1,52 +1,150 @@
 package websocket
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/personal/task-management/internal/delivery/rest/middleware"
 	"github.com/personal/task-management/internal/usecase"
 	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/spf13/viper"
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		return true // In production, implement proper origin checking
-	},
-}
+// defaultHandshakeTimeout bounds the upgrade when websocket.handshake_timeout is unset
+const defaultHandshakeTimeout = 10 * time.Second
+
+// allowAnyOrigin in websocket.allowed_origins disables origin checking
+const allowAnyOrigin = "*"
+
+// bearerSubprotocol marks a token offered as the next subprotocol, for browsers
+// that can't set headers: new WebSocket(url, ["bearer", token])
+const bearerSubprotocol = "bearer"
+
+var errMissingToken = errors.New("missing token")
 
 type Handler struct {
-	wsService  usecase.WebSocketService
-	jwtService jwt.JWTTokenServicer
+	wsService      usecase.WebSocketService
+	jwtService     jwt.JWTTokenServicer
+	userService    usecase.UserService
+	upgrader       websocket.Upgrader
+	allowedOrigins map[string]bool
+	allowAll       bool
 }
 
-func NewHandler(wsService usecase.WebSocketService, jwtService jwt.JWTTokenServicer) *Handler {
-	return &Handler{
-		wsService:  wsService,
-		jwtService: jwtService,
+func NewHandler(cfg *viper.Viper, wsService usecase.WebSocketService, jwtService jwt.JWTTokenServicer, userService usecase.UserService) *Handler {
+	handshakeTimeout := cfg.GetDuration("websocket.handshake_timeout")
+	if handshakeTimeout <= 0 {
+		handshakeTimeout = defaultHandshakeTimeout
+	}
+
+	h := &Handler{
+		wsService:      wsService,
+		jwtService:     jwtService,
+		userService:    userService,
+		allowedOrigins: make(map[string]bool),
+	}
+	for _, origin := range cfg.GetStringSlice("websocket.allowed_origins") {
+		origin = normalizeOrigin(origin)
+		if origin == allowAnyOrigin {
+			h.allowAll = true
+			continue
+		}
+		if origin != "" {
+			h.allowedOrigins[origin] = true
+		}
 	}
+	// Upgrade answers 403 itself when CheckOrigin rejects the request
+	h.upgrader = websocket.Upgrader{
+		ReadBufferSize:   1024,
+		WriteBufferSize:  1024,
+		HandshakeTimeout: handshakeTimeout,
+		CheckOrigin:      h.checkOrigin,
+	}
+	return h
 }
 
-func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+// checkOrigin allows requests without an Origin header, which browsers always
+// send, requests from the same host, and origins in websocket.allowed_origins
+func (h *Handler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || h.allowAll {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return h.allowedOrigins[normalizeOrigin(origin)]
+}
+
+// normalizeOrigin makes configured and received origins comparable
+func normalizeOrigin(origin string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+}
+
+// requestToken finds the access token, taking the first source present:
+//  1. the Authorization: Bearer header
+//  2. the subprotocol following "bearer" in Sec-WebSocket-Protocol
+//  3. the token query parameter, kept for older clients; it ends up in logs
+//
+// When the subprotocol is used, the returned header selects "bearer" so the
+// token is never echoed back.
+func requestToken(r *http.Request) (string, http.Header, error) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		token, err := middleware.BearerToken(header)
+		return token, nil, err
+	}
+
+	protocols := websocket.Subprotocols(r)
+	for i, protocol := range protocols {
+		if strings.EqualFold(protocol, bearerSubprotocol) && i+1 < len(protocols) {
+			return protocols[i+1], http.Header{"Sec-Websocket-Protocol": {bearerSubprotocol}}, nil
+		}
+	}
 
-	token := r.URL.Query().Get("token")
-	if token == "" {
-		http.Error(w, "missing token", http.StatusBadRequest)
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token, nil, nil
+	}
+	return "", nil, errMissingToken
+}
+
+func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	token, responseHeader, err := requestToken(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
 	// decode token
 	claims, err := h.jwtService.ValidateToken(token)
 	if err != nil {
-		http.Error(w, "invalid token", http.StatusBadRequest)
+		http.Error(w, "invalid token", http.StatusUnauthorized)
 		return
 	}
 
-	conn, err := upgrader.Upgrade(w, r, nil)
+	// Upgrade replies to the client itself on failure, including when the
+	// handshake outlives the deadline, and the connection may already be hijacked
+	conn, err := h.upgrader.Upgrade(w, r, responseHeader)
 	if err != nil {
-		http.Error(w, "could not upgrade connection", http.StatusInternalServerError)
 		return
 	}
 
-	h.wsService.HandleConnection(conn, claims.UserID.String())
+	// Connecting counts as activity, so a client that stays connected on one
+	// token still shows up as active
+	h.userService.RecordActivity(r.Context(), claims.UserID)
+	h.wsService.HandleConnection(conn, claims.UserID.String(), claims.Role)
+}
+
+// Heartbeat reports aggregate ping/pong health of connected clients
+func (h *Handler) Heartbeat(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.wsService.HeartbeatStats())
 }
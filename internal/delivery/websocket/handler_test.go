@@ -0,0 +1,217 @@
+package websocket
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/personal/task-management/internal/mocks"
+	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+// stalledClient hands the handler one end of a synchronous pipe whose other
+// end is never read, so writing the handshake response blocks
+type stalledClient struct {
+	*httptest.ResponseRecorder
+	server net.Conn
+	client net.Conn
+}
+
+func newStalledClient() *stalledClient {
+	server, client := net.Pipe()
+	return &stalledClient{ResponseRecorder: httptest.NewRecorder(), server: server, client: client}
+}
+
+func (s *stalledClient) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return s.server, bufio.NewReadWriter(bufio.NewReader(s.server), bufio.NewWriter(s.server)), nil
+}
+
+type HandlerTestSuite struct {
+	suite.Suite
+	ctrl        *gomock.Controller
+	wsService   *mocks.MockWebSocketService
+	jwtService  *mocks.MockJWTTokenServicer
+	userService *mocks.MockUserService
+}
+
+func (suite *HandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.wsService = mocks.NewMockWebSocketService(suite.ctrl)
+	suite.jwtService = mocks.NewMockJWTTokenServicer(suite.ctrl)
+	suite.userService = mocks.NewMockUserService(suite.ctrl)
+}
+
+func (suite *HandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func (suite *HandlerTestSuite) newHandler(handshakeTimeout time.Duration) *Handler {
+	cfg := viper.New()
+	cfg.Set("websocket.handshake_timeout", handshakeTimeout)
+	return NewHandler(cfg, suite.wsService, suite.jwtService, suite.userService)
+}
+
+func upgradeRequest() *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/ws?token=valid", nil)
+	r.Header.Set("Connection", "Upgrade")
+	r.Header.Set("Upgrade", "websocket")
+	r.Header.Set("Sec-WebSocket-Version", "13")
+	r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	return r
+}
+
+func (suite *HandlerTestSuite) TestHandshakeTimeoutDefaults() {
+	handler := NewHandler(viper.New(), suite.wsService, suite.jwtService, suite.userService)
+	suite.Equal(defaultHandshakeTimeout, handler.upgrader.HandshakeTimeout)
+}
+
+func (suite *HandlerTestSuite) TestStalledHandshakeIsAborted() {
+	suite.jwtService.EXPECT().ValidateToken("valid").Return(&jwt.UserClaims{UserID: uuid.New()}, nil)
+	// HandleConnection is never expected: the upgrade must fail
+	handler := suite.newHandler(50 * time.Millisecond)
+
+	client := newStalledClient()
+	defer client.client.Close()
+
+	done := make(chan struct{})
+	go func() {
+		handler.HandleWebSocket(client, upgradeRequest())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		suite.Fail("stalled handshake was not aborted")
+		return
+	}
+
+	// The aborted upgrade closes the server end of the pipe
+	_, err := client.client.Read(make([]byte, 1))
+	suite.Error(err)
+}
+
+// dial opens a WebSocket to a test server running the handler and returns
+// the handshake status and the subprotocol the server selected. An Origin of
+// "self" is replaced with the test server's own URL.
+func (suite *HandlerTestSuite) dial(handler *Handler, query string, header http.Header, subprotocols ...string) (int, string) {
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	defer server.Close()
+	if header.Get("Origin") == "self" {
+		header.Set("Origin", server.URL)
+	}
+
+	dialer := websocket.Dialer{Subprotocols: subprotocols}
+	conn, resp, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+query, header)
+	if err != nil {
+		suite.Require().NotNil(resp, err)
+		return resp.StatusCode, ""
+	}
+	defer conn.Close()
+	return resp.StatusCode, conn.Subprotocol()
+}
+
+// expectConnection expects the upgraded connection to be recorded as activity
+// and handed over once, along with the role from the token
+func (suite *HandlerTestSuite) expectConnection(userID uuid.UUID) {
+	suite.userService.EXPECT().RecordActivity(gomock.Any(), userID)
+	suite.wsService.EXPECT().HandleConnection(gomock.Any(), userID.String(), "employee").
+		Do(func(conn *websocket.Conn, _, _ string) { conn.Close() })
+}
+
+func (suite *HandlerTestSuite) TestCheckOrigin() {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		status  int
+	}{
+		{name: "same host", origin: "self", status: http.StatusSwitchingProtocols},
+		{name: "allowed origin", allowed: []string{"https://app.example.com/"}, origin: "https://APP.example.com", status: http.StatusSwitchingProtocols},
+		{name: "disallowed origin", allowed: []string{"https://app.example.com"}, origin: "https://evil.example.com", status: http.StatusForbidden},
+		{name: "cross-site by default", origin: "https://evil.example.com", status: http.StatusForbidden},
+		{name: "wildcard", allowed: []string{"*"}, origin: "https://evil.example.com", status: http.StatusSwitchingProtocols},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			cfg := viper.New()
+			cfg.Set("websocket.allowed_origins", tt.allowed)
+			handler := NewHandler(cfg, suite.wsService, suite.jwtService, suite.userService)
+
+			userID := uuid.New()
+			suite.jwtService.EXPECT().ValidateToken("valid").Return(&jwt.UserClaims{UserID: userID, Role: "employee"}, nil)
+			if tt.status == http.StatusSwitchingProtocols {
+				suite.expectConnection(userID)
+			}
+
+			status, _ := suite.dial(handler, "?token=valid", http.Header{"Origin": {tt.origin}})
+			suite.Equal(tt.status, status)
+		})
+	}
+}
+
+func (suite *HandlerTestSuite) TestTokenSources() {
+	tests := []struct {
+		name         string
+		query        string
+		header       http.Header
+		subprotocols []string
+		subprotocol  string
+	}{
+		{name: "authorization header", header: http.Header{"Authorization": {"Bearer valid"}}},
+		{name: "subprotocol", header: http.Header{}, subprotocols: []string{"bearer", "valid"}, subprotocol: "bearer"},
+		{name: "query parameter", query: "?token=valid", header: http.Header{}},
+		{
+			name:         "header takes precedence",
+			query:        "?token=stale",
+			header:       http.Header{"Authorization": {"Bearer valid"}},
+			subprotocols: []string{"bearer", "stale"},
+		},
+		{name: "subprotocol over query", query: "?token=stale", header: http.Header{}, subprotocols: []string{"bearer", "valid"}, subprotocol: "bearer"},
+	}
+
+	handler := suite.newHandler(time.Second)
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			userID := uuid.New()
+			suite.jwtService.EXPECT().ValidateToken("valid").Return(&jwt.UserClaims{UserID: userID, Role: "employee"}, nil)
+			suite.expectConnection(userID)
+
+			status, subprotocol := suite.dial(handler, tt.query, tt.header, tt.subprotocols...)
+			suite.Equal(http.StatusSwitchingProtocols, status)
+			suite.Equal(tt.subprotocol, subprotocol)
+		})
+	}
+}
+
+func (suite *HandlerTestSuite) TestUnusableTokensAreUnauthorized() {
+	handler := suite.newHandler(time.Second)
+
+	suite.Run("missing", func() {
+		status, _ := suite.dial(handler, "", http.Header{})
+		suite.Equal(http.StatusUnauthorized, status)
+	})
+	suite.Run("malformed header", func() {
+		status, _ := suite.dial(handler, "?token=valid", http.Header{"Authorization": {"Basic dXNlcg=="}})
+		suite.Equal(http.StatusUnauthorized, status)
+	})
+	suite.Run("invalid", func() {
+		suite.jwtService.EXPECT().ValidateToken("forged").Return(nil, jwt.ErrInvalidToken)
+		status, _ := suite.dial(handler, "?token=forged", http.Header{})
+		suite.Equal(http.StatusUnauthorized, status)
+	})
+}
+
+func TestHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(HandlerTestSuite))
+}
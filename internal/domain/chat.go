@@ -1,7 +1,12 @@
 package domain
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -9,10 +14,12 @@ import (
 type Room struct {
 	ID             string         `json:"id" gorm:"primaryKey"`
 	Name           string         `json:"name"`
-	Type           string         `json:"type"` // "direct" or "group"
+	Type           string         `json:"type"`                 // "direct" or "group"
+	CreatedBy      string         `json:"created_by,omitempty"` // the user who may delete the room
 	Description    string         `json:"description,omitempty"`
 	AvatarURL      string         `json:"avatar_url,omitempty"`
 	Users          []string       `json:"users" gorm:"-"`
+	Admins         []string       `json:"admins,omitempty" gorm:"-"`
 	LastMessage    *Message       `json:"last_message,omitempty" gorm:"-"`
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
@@ -20,12 +27,26 @@ type Room struct {
 	IsMuted        bool           `json:"is_muted"`
 	UnreadCount    map[string]int `json:"unread_count" gorm:"type:jsonb"`
 	PinnedMessages []string       `json:"pinned_messages" gorm:"type:text[]"`
+	// SlowModeSeconds is the minimum gap between a user's messages in the
+	// room; zero turns slow mode off
+	SlowModeSeconds int `json:"slow_mode_seconds"`
+}
+
+// SlowModeInterval returns the minimum gap between a user's messages
+func (r *Room) SlowModeInterval() time.Duration {
+	return time.Duration(r.SlowModeSeconds) * time.Second
+}
+
+// IsAdmin reports whether the user is an admin of the room
+func (r *Room) IsAdmin(userID string) bool {
+	return slices.Contains(r.Admins, userID)
 }
 
 // Message represents a chat message
 type Message struct {
 	ID           string    `json:"id" gorm:"primaryKey"`
-	RoomID       string    `json:"room_id"`
+	RoomID       string    `json:"room_id" gorm:"uniqueIndex:idx_messages_room_sequence"`
+	Sequence     int64     `json:"sequence" gorm:"uniqueIndex:idx_messages_room_sequence"` // position within the room, starting at 1
 	UserID       string    `json:"user_id"`
 	Content      string    `json:"content"`
 	Type         string    `json:"type"`
@@ -40,15 +61,34 @@ type Message struct {
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
+// RoomSequence holds the sequence of the latest message in a room. It is
+// bumped atomically for every new message so concurrent senders never share
+// a sequence.
+type RoomSequence struct {
+	RoomID       string `gorm:"primaryKey"`
+	LastSequence int64
+}
+
 // RoomUser represents the relationship between rooms and users
 type RoomUser struct {
 	ID        string    `json:"id" gorm:"primaryKey"`
 	RoomID    string    `json:"room_id"`
 	UserID    string    `json:"user_id"`
+	Role      string    `json:"role" gorm:"default:member"` // RoomRoleAdmin or RoomRoleMember
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// RoomUserMute records that UserID muted MutedUserID within a room, so
+// MutedUserID's messages there no longer push notifications to UserID
+type RoomUserMute struct {
+	ID          string    `json:"id" gorm:"primaryKey"`
+	RoomID      string    `json:"room_id" gorm:"uniqueIndex:idx_room_user_mute"`
+	UserID      string    `json:"user_id" gorm:"uniqueIndex:idx_room_user_mute"`
+	MutedUserID string    `json:"muted_user_id" gorm:"uniqueIndex:idx_room_user_mute"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
 // MessageStatus represents the status of a message for a specific user
 type MessageStatus struct {
 	ID        string    `json:"id" gorm:"primaryKey"`
@@ -72,6 +112,37 @@ type Notification struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// NotificationPreference records whether a user wants notifications delivered
+// over a channel. An empty Type applies to every notification type.
+type NotificationPreference struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	UserID    string    `json:"user_id"`
+	Channel   string    `json:"channel"`
+	Type      string    `json:"type,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationEnabled reports whether the preferences allow delivering the
+// notification type over the channel. A type-specific preference wins over a
+// channel-wide one, and channels are enabled unless opted out.
+func NotificationEnabled(preferences []*NotificationPreference, channel, notificationType string) bool {
+	enabled := true
+	for _, p := range preferences {
+		if p.Channel != channel {
+			continue
+		}
+		if p.Type == notificationType {
+			return p.Enabled
+		}
+		if p.Type == "" {
+			enabled = p.Enabled
+		}
+	}
+	return enabled
+}
+
 // WebSocketMessage represents a message sent over WebSocket
 type WebSocketMessage struct {
 	Type         string    `json:"type"`
@@ -88,7 +159,25 @@ type WebSocketMessage struct {
 	Duration     int       `json:"duration,omitempty"`
 	MessageID    string    `json:"message_id,omitempty"`
 	Status       string    `json:"status,omitempty"`
+	Sequence     int64     `json:"sequence,omitempty"` // in request_missing frames, the last sequence the client saw
 	Timestamp    time.Time `json:"timestamp"`
+	// ReadBy lists who has read a group room message in history responses
+	ReadBy []ReadReceipt `json:"read_by,omitempty"`
+	// UnreadCount is the recipient's new unread count in unread_update frames
+	UnreadCount *int `json:"unread_count,omitempty"`
+}
+
+// MessageSearchResult is a message matched by a search, along with the name
+// of the room it was posted in
+type MessageSearchResult struct {
+	Message
+	RoomName string `json:"room_name"`
+}
+
+// ReadReceipt records when a user read a message
+type ReadReceipt struct {
+	UserID string    `json:"user_id"`
+	ReadAt time.Time `json:"read_at"`
 }
 
 // Hub maintains active connections and broadcasts messages
@@ -99,15 +188,116 @@ type Hub struct {
 	Unregister    chan *Connection
 	Broadcast     chan WebSocketMessage
 	DirectMessage chan WebSocketMessage
+
+	// Done is closed once the hub stops; sends to the channels above must
+	// also select on it or they block forever after shutdown
+	Done chan struct{}
 }
 
 // Connection represents a WebSocket connection
 type Connection struct {
 	ID     string
 	UserID string
+	// Role is the user's role as of when they connected
+	Role   string
 	RoomID string
 	Send   chan WebSocketMessage
 	Hub    *Hub
+
+	closeOnce sync.Once
+
+	// ctx is shared by the read and write pumps so that either one exiting,
+	// or the hub closing the connection, stops the other
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	pongMu   sync.RWMutex
+	lastPong time.Time
+}
+
+// NewConnection creates a connection whose pumps stop when parent is done
+func NewConnection(parent context.Context, userID, role string, hub *Hub, buffer int) *Connection {
+	ctx, cancel := context.WithCancel(parent)
+	return &Connection{
+		ID:     userID,
+		UserID: userID,
+		Role:   role,
+		Send:   make(chan WebSocketMessage, buffer),
+		Hub:    hub,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Done is closed once the connection is cancelled. It is nil, and so never
+// ready, for connections not created with NewConnection.
+func (c *Connection) Done() <-chan struct{} {
+	if c.ctx == nil {
+		return nil
+	}
+	return c.ctx.Done()
+}
+
+// Cancel signals both pumps to stop
+func (c *Connection) Cancel() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// Close cancels the connection and closes the send channel, ignoring
+// repeated calls
+func (c *Connection) Close() {
+	c.closeOnce.Do(func() {
+		c.Cancel()
+		close(c.Send)
+	})
+}
+
+// MarkPong records when the client last answered a ping
+func (c *Connection) MarkPong(at time.Time) {
+	c.pongMu.Lock()
+	c.lastPong = at
+	c.pongMu.Unlock()
+}
+
+// LastPong returns when the client last answered a ping
+func (c *Connection) LastPong() time.Time {
+	c.pongMu.RLock()
+	defer c.pongMu.RUnlock()
+	return c.lastPong
+}
+
+// HeartbeatStats summarises how long it has been since connected clients
+// last answered a ping
+type HeartbeatStats struct {
+	Connections      int           `json:"connections"`
+	MinSinceLastPong time.Duration `json:"min_since_last_pong"`
+	MaxSinceLastPong time.Duration `json:"max_since_last_pong"`
+	AvgSinceLastPong time.Duration `json:"avg_since_last_pong"`
+}
+
+// ChatStats aggregates chat activity for administrators. MessagesPerDay and
+// BusiestRooms cover the requested range; the totals cover all time.
+type ChatStats struct {
+	TotalRooms        int64               `json:"total_rooms"`
+	TotalMessages     int64               `json:"total_messages"`
+	ActiveConnections int                 `json:"active_connections"`
+	MessagesPerDay    []DailyMessageCount `json:"messages_per_day"`
+	BusiestRooms      []RoomActivity      `json:"busiest_rooms"`
+}
+
+// DailyMessageCount is the number of messages sent on a calendar day (YYYY-MM-DD)
+type DailyMessageCount struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+// RoomActivity is the number of messages sent in a room
+type RoomActivity struct {
+	RoomID       string `json:"room_id"`
+	Name         string `json:"name"`
+	MessageCount int64  `json:"message_count"`
 }
 
 // Message types
@@ -118,10 +308,36 @@ const (
 	MessageTypeVideo      = "video"
 	MessageTypeAudio      = "audio"
 	MessageTypeTyping     = "typing"
+	MessageTypeStopTyping = "stop_typing"
 	MessageTypeRead       = "read"
+	MessageTypeDelivered  = "delivered"
 	MessageTypeTaskUpdate = "task_update"
 	MessageTypeMention    = "mention"
 	MessageTypeSystem     = "system"
+	MessageTypeError      = "error"
+
+	// MessageTypeRequestMissing is a control frame asking the server to
+	// replay room messages after the sequence the client last saw
+	MessageTypeRequestMissing = "request_missing"
+
+	// MessageTypeUnreadUpdate tells a user their unread count for a room changed
+	MessageTypeUnreadUpdate = "unread_update"
+
+	// MessageTypeSummaryUpdate tells an employer their task summary is stale
+	MessageTypeSummaryUpdate = "summary_update"
+
+	// MessageTypeRoomDeleted tells members a room was deleted and will get no
+	// more messages
+	MessageTypeRoomDeleted = "room_deleted"
+
+	// MessageTypeMemberJoined and MessageTypeMemberLeft tell a room's members
+	// that UserID was added to or removed from it
+	MessageTypeMemberJoined = "member_joined"
+	MessageTypeMemberLeft   = "member_left"
+
+	// MessageTypeRateLimited tells a client its message was dropped because
+	// it is sending faster than the server allows
+	MessageTypeRateLimited = "rate_limited"
 )
 
 // Message statuses
@@ -131,23 +347,120 @@ const (
 	MessageStatusRead      = "read"
 )
 
+// HistoryOrder is the order room history is paginated in
+type HistoryOrder string
+
+// History orders
+const (
+	HistoryOrderNewestFirst HistoryOrder = "desc"
+	HistoryOrderOldestFirst HistoryOrder = "asc"
+)
+
+// ParseHistoryOrder parses an order query parameter, defaulting to newest
+// first when it is empty
+func ParseHistoryOrder(order string) (HistoryOrder, error) {
+	switch HistoryOrder(strings.ToLower(order)) {
+	case "", HistoryOrderNewestFirst:
+		return HistoryOrderNewestFirst, nil
+	case HistoryOrderOldestFirst:
+		return HistoryOrderOldestFirst, nil
+	default:
+		return "", fmt.Errorf("%w: %q, expected asc or desc", ErrInvalidHistoryOrder, order)
+	}
+}
+
 // Room types
 const (
 	RoomTypeDirect = "direct"
 	RoomTypeGroup  = "group"
 )
 
+// Room roles. Admins of a group room rename it and manage its members.
+const (
+	RoomRoleAdmin  = "admin"
+	RoomRoleMember = "member"
+)
+
 // Notification types
 const (
 	NotificationTypeTaskUpdate = "task_update"
 	NotificationTypeMention    = "mention"
 	NotificationTypeSystem     = "system"
+	NotificationTypeMessage    = "message"
+)
+
+// Notification channels
+const (
+	NotificationChannelWebSocket = "websocket"
+	NotificationChannelEmail     = "email"
+	NotificationChannelWebhook   = "webhook"
 )
 
 // Error constants
 var (
-	ErrRoomNotFound    = errors.New("room not found")
-	ErrUserNotInRoom   = errors.New("user not in room")
-	ErrInvalidMessage  = errors.New("invalid message")
-	ErrInvalidRoomType = errors.New("invalid room type")
+	ErrRoomNotFound      = errors.New("room not found")
+	ErrUserNotInRoom     = errors.New("user not in room")
+	ErrUserAlreadyInRoom = errors.New("user already in room")
+	ErrInvalidMessage    = errors.New("invalid message")
+	ErrMessageTooLong    = errors.New("message is too long")
+	ErrInvalidRoomType   = errors.New("invalid room type")
+	ErrMessageNotFound   = errors.New("message not found")
+	ErrCannotMuteSelf    = errors.New("cannot mute yourself")
+	ErrSelfDirectRoom    = errors.New("cannot start a direct room with yourself")
+	ErrInvalidSequence   = errors.New("invalid message sequence")
+	ErrUnroutableMessage = errors.New("message has no room to route to")
+	ErrUnsupportedFrame  = errors.New("unsupported message type")
+	ErrEmptySearchQuery  = errors.New("search query cannot be empty")
+	ErrSlowMode          = errors.New("slow mode is on")
+	ErrRateLimited       = errors.New("sending messages too fast")
+	ErrHubStopped        = errors.New("chat service is shutting down")
+	ErrInvalidSlowMode   = errors.New("invalid slow mode interval")
+	ErrPinLimitReached   = errors.New("pinned message limit reached")
+	ErrNotRoomCreator    = errors.New("only the room creator or an employer can do this")
+	ErrNotRoomAdmin      = errors.New("only a room admin or an employer can do this")
+	ErrLastRoomAdmin     = errors.New("a group room needs at least one admin")
+
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrInvalidPreference    = errors.New("invalid notification preference")
+	ErrHistoryLimitExceeded = errors.New("history limit exceeded")
+	ErrInvalidHistoryOrder  = errors.New("invalid history order")
+	ErrInvalidStatsRange    = errors.New("invalid statistics range")
 )
+
+// SlowModeError rejects a message sent before the room's slow mode interval
+// has passed since the sender's previous one. It matches ErrSlowMode.
+type SlowModeError struct {
+	Remaining time.Duration
+}
+
+func (e *SlowModeError) Error() string {
+	return fmt.Sprintf("%v: wait %s before sending another message", ErrSlowMode, e.RetryAfter())
+}
+
+func (e *SlowModeError) Unwrap() error {
+	return ErrSlowMode
+}
+
+// RetryAfter is the remaining wait rounded up to whole seconds
+func (e *SlowModeError) RetryAfter() time.Duration {
+	return (e.Remaining + time.Second - 1).Truncate(time.Second)
+}
+
+// RateLimitError rejects a message from a user who has used up their
+// chat.messages_per_minute allowance. It matches ErrRateLimited.
+type RateLimitError struct {
+	Remaining time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%v: wait %s before sending another message", ErrRateLimited, e.RetryAfter())
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// RetryAfter is the remaining wait rounded up to whole seconds
+func (e *RateLimitError) RetryAfter() time.Duration {
+	return (e.Remaining + time.Second - 1).Truncate(time.Second)
+}
@@ -0,0 +1,38 @@
+package task
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskComment is a message left on a task by one of its participants
+type TaskComment struct {
+	ID        uuid.UUID `json:"id"`
+	TaskID    uuid.UUID `json:"task_id" gorm:"index"`
+	UserID    uuid.UUID `json:"user_id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewTaskComment creates a comment by userID on the given task
+func NewTaskComment(taskID, userID uuid.UUID, content string) (*TaskComment, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, ErrEmptyComment
+	}
+
+	return &TaskComment{
+		ID:        uuid.New(),
+		TaskID:    taskID,
+		UserID:    userID,
+		Content:   content,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// IsWrittenBy checks if the comment was written by the given user
+func (c *TaskComment) IsWrittenBy(userID uuid.UUID) bool {
+	return c.UserID == userID
+}
@@ -7,6 +7,19 @@ var (
 	ErrEmptyTitle              = errors.New("title cannot be empty")
 	ErrInvalidDueDate          = errors.New("due date must be in the future")
 	ErrInvalidStatusTransition = errors.New("invalid status transition")
+	ErrBlockReasonRequired     = errors.New("a reason is required to block a task")
+	ErrInvalidWorkflow         = errors.New("invalid task workflow")
+	ErrEmptyComment            = errors.New("comment cannot be empty")
+	ErrCommentNotFound         = errors.New("comment not found")
+	ErrEmptyTag                = errors.New("tag cannot be empty")
+	ErrTagTooLong              = errors.New("tag is too long")
+	ErrInvalidRecurrence       = errors.New("recurrence must be daily, weekly or monthly with an interval between 1 and 365")
+	ErrNotRecurring            = errors.New("task does not recur")
 	ErrTaskNotFound            = errors.New("task not found")
 	ErrUnauthorized            = errors.New("unauthorized to perform this action on the task")
+	ErrTemplateNotFound        = errors.New("task template not found")
+	ErrInvalidDueOffset        = errors.New("due offset must be at least one hour")
+	ErrAssigneeRequired        = errors.New("an assignee is required when the template has no default")
+	ErrInvalidAssignee         = errors.New("tasks can only be assigned to active employees")
+	ErrInvalidSort             = errors.New("sort_by must be created_at, updated_at, due_date, title or status and sort_order asc or desc")
 )
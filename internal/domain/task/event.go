@@ -0,0 +1,58 @@
+package task
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Fields a TaskEvent records changes to
+const (
+	FieldStatus      = "status"
+	FieldAssignee    = "assignee_id"
+	FieldTitle       = "title"
+	FieldDescription = "description"
+	FieldDueDate     = "due_date"
+)
+
+// TaskEvent records one change to a task: who changed which field, and from
+// what to what
+type TaskEvent struct {
+	ID        uuid.UUID `json:"id"`
+	TaskID    uuid.UUID `json:"task_id" gorm:"index"`
+	ActorID   uuid.UUID `json:"actor_id"`
+	Field     string    `json:"field"`
+	OldValue  string    `json:"old_value"`
+	NewValue  string    `json:"new_value"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewTaskEvent records that actorID changed field on the task
+func NewTaskEvent(taskID, actorID uuid.UUID, field, oldValue, newValue string) *TaskEvent {
+	return &TaskEvent{
+		ID:        uuid.New(),
+		TaskID:    taskID,
+		ActorID:   actorID,
+		Field:     field,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Changes returns an event for each recorded field that differs between
+// before and after, a copy of the task taken before actorID changed it
+func Changes(before, after *Task, actorID uuid.UUID) []*TaskEvent {
+	var events []*TaskEvent
+	record := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			events = append(events, NewTaskEvent(after.ID, actorID, field, oldValue, newValue))
+		}
+	}
+	record(FieldStatus, before.Status.String(), after.Status.String())
+	record(FieldAssignee, before.AssigneeID.String(), after.AssigneeID.String())
+	record(FieldTitle, before.Title, after.Title)
+	record(FieldDescription, before.Description, after.Description)
+	record(FieldDueDate, before.DueDate.UTC().Format(time.RFC3339), after.DueDate.UTC().Format(time.RFC3339))
+	return events
+}
@@ -0,0 +1,109 @@
+package task
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Frequency is the unit a recurring task repeats on
+type Frequency string
+
+const (
+	// FrequencyDaily repeats a task every Interval days
+	FrequencyDaily Frequency = "daily"
+	// FrequencyWeekly repeats a task every Interval weeks
+	FrequencyWeekly Frequency = "weekly"
+	// FrequencyMonthly repeats a task every Interval months
+	FrequencyMonthly Frequency = "monthly"
+)
+
+// MaxRecurrenceInterval is the largest interval accepted for any frequency
+const MaxRecurrenceInterval = 365
+
+// RecurrenceRule describes how often a task repeats. The zero value means the
+// task does not repeat.
+type RecurrenceRule struct {
+	Frequency Frequency `json:"frequency,omitempty" example:"weekly"`
+	Interval  int       `json:"interval,omitempty" example:"1"`
+}
+
+// IsZero reports whether the rule leaves the task non-recurring
+func (r RecurrenceRule) IsZero() bool {
+	return r.Frequency == ""
+}
+
+// Validate checks the frequency is known and the interval is in range
+func (r RecurrenceRule) Validate() error {
+	switch r.Frequency {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly:
+	default:
+		return ErrInvalidRecurrence
+	}
+	if r.Interval < 1 || r.Interval > MaxRecurrenceInterval {
+		return ErrInvalidRecurrence
+	}
+	return nil
+}
+
+// Next returns the occurrence that follows from
+func (r RecurrenceRule) Next(from time.Time) time.Time {
+	switch r.Frequency {
+	case FrequencyDaily:
+		return from.AddDate(0, 0, r.Interval)
+	case FrequencyWeekly:
+		return from.AddDate(0, 0, 7*r.Interval)
+	case FrequencyMonthly:
+		return from.AddDate(0, r.Interval, 0)
+	}
+	return from
+}
+
+// SetRecurrence makes the task repeat according to rule. An interval of zero
+// defaults to one.
+func (t *Task) SetRecurrence(rule RecurrenceRule) error {
+	if rule.Interval == 0 {
+		rule.Interval = 1
+	}
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	t.Recurrence = rule
+	return nil
+}
+
+// IsRecurring checks if the task repeats on a schedule
+func (t *Task) IsRecurring() bool {
+	return !t.Recurrence.IsZero()
+}
+
+// NextOccurrence builds the pending task that follows this completed recurring
+// task. The due date is shifted by the rule until it falls after now, so
+// periods missed while the task was late are skipped rather than piling up.
+func (t *Task) NextOccurrence(now time.Time) (*Task, error) {
+	if !t.IsRecurring() {
+		return nil, ErrNotRecurring
+	}
+	if !t.IsCompleted() {
+		return nil, ErrInvalidStatusTransition
+	}
+
+	dueDate := t.Recurrence.Next(t.DueDate)
+	for !dueDate.After(now) {
+		dueDate = t.Recurrence.Next(dueDate)
+	}
+
+	return &Task{
+		ID:          uuid.New(),
+		Title:       t.Title,
+		Description: t.Description,
+		Status:      StatusPending,
+		AssigneeID:  t.AssigneeID,
+		CreatorID:   t.CreatorID,
+		DueDate:     dueDate,
+		Recurrence:  t.Recurrence,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
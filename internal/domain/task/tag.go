@@ -0,0 +1,47 @@
+package task
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxTagLength is the longest tag name accepted
+const MaxTagLength = 50
+
+// Tag is a label such as "backend" or "bug" that can be attached to tasks
+type Tag struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name" gorm:"uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TaskTag joins a task to one of its tags
+type TaskTag struct {
+	TaskID uuid.UUID `gorm:"primaryKey"`
+	TagID  uuid.UUID `gorm:"primaryKey;index"`
+}
+
+// NewTag creates a tag with a normalized name
+func NewTag(name string) (*Tag, error) {
+	name = NormalizeTagName(name)
+	if name == "" {
+		return nil, ErrEmptyTag
+	}
+	if len(name) > MaxTagLength {
+		return nil, ErrTagTooLong
+	}
+
+	return &Tag{
+		ID:        uuid.New(),
+		Name:      name,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// NormalizeTagName trims and lowercases a tag name so "Backend " and
+// "backend" refer to the same tag
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
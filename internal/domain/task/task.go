@@ -1,9 +1,11 @@
 package task
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain/user"
 )
 
 // Status represents the current state of a task
@@ -16,6 +18,8 @@ const (
 	StatusInProgress Status = "in_progress"
 	// StatusCompleted represents a task that has been completed
 	StatusCompleted Status = "completed"
+	// StatusBlocked represents a task that cannot progress until something else is resolved
+	StatusBlocked Status = "blocked"
 	// StatusDeleted represents a task that has been deleted
 	StatusDeleted Status = "deleted"
 )
@@ -24,17 +28,32 @@ func (s Status) String() string {
 	return string(s)
 }
 
+// IsValid reports whether s is one of the known task statuses
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusPending, StatusInProgress, StatusBlocked, StatusCompleted, StatusDeleted:
+		return true
+	}
+	return false
+}
+
 // Task represents a task in the system
 type Task struct {
 	ID          uuid.UUID `json:"id"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	Status      Status    `json:"status"`
+	BlockReason string    `json:"block_reason,omitempty"`
 	AssigneeID  uuid.UUID `json:"assignee_id"`
 	CreatorID   uuid.UUID `json:"creator_id"`
 	DueDate     time.Time `json:"due_date"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Recurrence makes a completed task spawn its next occurrence
+	Recurrence RecurrenceRule `json:"recurrence" gorm:"embedded;embeddedPrefix:recurrence_"`
+	// NextTaskID points at the occurrence spawned after this task was completed
+	NextTaskID *uuid.UUID `json:"next_task_id,omitempty"`
 }
 
 // NewTask creates a new task with the given parameters
@@ -61,30 +80,42 @@ func NewTask(title, description string, dueDate time.Time, creatorID, assigneeID
 	}, nil
 }
 
-// UpdateStatus updates the task status if the transition is valid
-func (t *Task) UpdateStatus(newStatus Status) error {
-	if !isValidStatusTransition(t.Status, newStatus) {
+// UpdateStatus updates the task status if the workflow allows the transition
+// for the role. Blocking a task goes through Block so a reason is always recorded.
+func (t *Task) UpdateStatus(workflow Workflow, newStatus Status, role user.Role) error {
+	if newStatus == StatusBlocked {
+		return ErrBlockReasonRequired
+	}
+	if !t.CanTransitionTo(workflow, newStatus, role) {
 		return ErrInvalidStatusTransition
 	}
 
 	t.Status = newStatus
+	t.BlockReason = ""
 	t.UpdatedAt = time.Now()
 	return nil
 }
 
-// isValidStatusTransition checks if a status transition is valid
-func isValidStatusTransition(current, next Status) bool {
-	// Define valid transitions
-	switch current {
-	case StatusPending:
-		return next == StatusInProgress || next == StatusCompleted
-	case StatusInProgress:
-		return next == StatusCompleted
-	case StatusCompleted:
-		return false // Completed tasks cannot transition to other statuses
-	default:
-		return false
+// Block moves the task to blocked and records why
+func (t *Task) Block(workflow Workflow, reason string, role user.Role) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrBlockReasonRequired
+	}
+	if !t.CanTransitionTo(workflow, StatusBlocked, role) {
+		return ErrInvalidStatusTransition
 	}
+
+	t.Status = StatusBlocked
+	t.BlockReason = reason
+	t.UpdatedAt = time.Now()
+	return nil
+}
+
+// CanTransitionTo checks if the workflow lets a user with the given role move
+// the task to next
+func (t *Task) CanTransitionTo(workflow Workflow, next Status, role user.Role) bool {
+	return workflow.Allows(t.Status, next, role)
 }
 
 // IsAssignedTo checks if the task is assigned to the given user
@@ -92,6 +123,35 @@ func (t *Task) IsAssignedTo(userID uuid.UUID) bool {
 	return t.AssigneeID == userID
 }
 
+// Edit changes whichever of the title, description and due date are given,
+// leaving the task untouched unless they are all valid
+func (t *Task) Edit(title, description *string, dueDate *time.Time) error {
+	if title != nil && strings.TrimSpace(*title) == "" {
+		return ErrEmptyTitle
+	}
+	if dueDate != nil && dueDate.Before(time.Now()) {
+		return ErrInvalidDueDate
+	}
+
+	if title != nil {
+		t.Title = *title
+	}
+	if description != nil {
+		t.Description = *description
+	}
+	if dueDate != nil {
+		t.DueDate = *dueDate
+	}
+	t.UpdatedAt = time.Now()
+	return nil
+}
+
+// Reassign hands the task to another assignee
+func (t *Task) Reassign(assigneeID uuid.UUID) {
+	t.AssigneeID = assigneeID
+	t.UpdatedAt = time.Now()
+}
+
 // IsCreatedBy checks if the task was created by the given user
 func (t *Task) IsCreatedBy(userID uuid.UUID) bool {
 	return t.CreatorID == userID
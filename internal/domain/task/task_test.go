@@ -0,0 +1,221 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain/user"
+	"github.com/stretchr/testify/suite"
+)
+
+type TaskTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TaskTestSuite) TestCanTransitionTo() {
+	tests := []struct {
+		from     Status
+		to       Status
+		employee bool
+		employer bool
+	}{
+		{from: StatusPending, to: StatusPending, employee: false, employer: false},
+		{from: StatusPending, to: StatusInProgress, employee: true, employer: true},
+		{from: StatusPending, to: StatusCompleted, employee: true, employer: true},
+		{from: StatusPending, to: StatusBlocked, employee: true, employer: true},
+		{from: StatusInProgress, to: StatusPending, employee: false, employer: false},
+		{from: StatusInProgress, to: StatusInProgress, employee: false, employer: false},
+		{from: StatusInProgress, to: StatusCompleted, employee: true, employer: true},
+		{from: StatusInProgress, to: StatusBlocked, employee: true, employer: true},
+		{from: StatusBlocked, to: StatusPending, employee: true, employer: true},
+		{from: StatusBlocked, to: StatusInProgress, employee: true, employer: true},
+		{from: StatusBlocked, to: StatusBlocked, employee: false, employer: false},
+		{from: StatusBlocked, to: StatusCompleted, employee: false, employer: false},
+		{from: StatusCompleted, to: StatusPending, employee: false, employer: true},
+		{from: StatusCompleted, to: StatusInProgress, employee: false, employer: true},
+		{from: StatusCompleted, to: StatusCompleted, employee: false, employer: false},
+		{from: StatusCompleted, to: StatusBlocked, employee: false, employer: false},
+	}
+
+	for _, tt := range tests {
+		t := &Task{Status: tt.from}
+		suite.Equal(tt.employee, t.CanTransitionTo(DefaultWorkflow(), tt.to, user.Employee), "employee %s -> %s", tt.from, tt.to)
+		suite.Equal(tt.employer, t.CanTransitionTo(DefaultWorkflow(), tt.to, user.Employer), "employer %s -> %s", tt.from, tt.to)
+	}
+}
+
+func (suite *TaskTestSuite) TestUpdateStatusReopenByEmployer() {
+	t := &Task{Status: StatusCompleted}
+
+	suite.ErrorIs(t.UpdateStatus(DefaultWorkflow(), StatusPending, user.Employee), ErrInvalidStatusTransition)
+	suite.Equal(StatusCompleted, t.Status)
+
+	suite.NoError(t.UpdateStatus(DefaultWorkflow(), StatusPending, user.Employer))
+	suite.Equal(StatusPending, t.Status)
+	suite.False(t.UpdatedAt.IsZero())
+}
+
+func (suite *TaskTestSuite) TestEditValidatesBeforeChangingAnything() {
+	due := time.Now().Add(24 * time.Hour)
+	t := &Task{Title: "Write report", Description: "Q3", DueDate: due}
+
+	blank, past := "  ", time.Now().Add(-time.Minute)
+	suite.ErrorIs(t.Edit(&blank, nil, nil), ErrEmptyTitle)
+	title := "Write the report"
+	suite.ErrorIs(t.Edit(&title, nil, &past), ErrInvalidDueDate)
+	suite.Equal("Write report", t.Title)
+	suite.Equal(due, t.DueDate)
+
+	later := due.Add(48 * time.Hour)
+	suite.NoError(t.Edit(&title, nil, &later))
+	suite.Equal("Write the report", t.Title)
+	suite.Equal("Q3", t.Description)
+	suite.Equal(later, t.DueDate)
+	suite.False(t.UpdatedAt.IsZero())
+}
+
+func (suite *TaskTestSuite) TestChangesListsEachChangedField() {
+	actorID := uuid.New()
+	due := time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC)
+	before := Task{ID: uuid.New(), Title: "Deploy", Status: StatusPending, AssigneeID: uuid.New(), DueDate: due}
+	suite.Empty(Changes(&before, &before, actorID))
+
+	after := before
+	after.Status = StatusInProgress
+	after.AssigneeID = uuid.New()
+	after.DueDate = due.Add(24 * time.Hour)
+	events := Changes(&before, &after, actorID)
+
+	suite.Require().Len(events, 3)
+	byField := make(map[string]*TaskEvent)
+	for _, event := range events {
+		suite.Equal(before.ID, event.TaskID)
+		suite.Equal(actorID, event.ActorID)
+		byField[event.Field] = event
+	}
+	suite.Equal("pending", byField[FieldStatus].OldValue)
+	suite.Equal("in_progress", byField[FieldStatus].NewValue)
+	suite.Equal(before.AssigneeID.String(), byField[FieldAssignee].OldValue)
+	suite.Equal(after.AssigneeID.String(), byField[FieldAssignee].NewValue)
+	suite.Equal("2030-01-02T15:04:05Z", byField[FieldDueDate].OldValue)
+	suite.Equal("2030-01-03T15:04:05Z", byField[FieldDueDate].NewValue)
+}
+
+func (suite *TaskTestSuite) TestBlockRequiresReason() {
+	t := &Task{Status: StatusInProgress}
+
+	suite.ErrorIs(t.Block(DefaultWorkflow(), "", user.Employee), ErrBlockReasonRequired)
+	suite.ErrorIs(t.Block(DefaultWorkflow(), "   ", user.Employee), ErrBlockReasonRequired)
+	suite.ErrorIs(t.UpdateStatus(DefaultWorkflow(), StatusBlocked, user.Employee), ErrBlockReasonRequired)
+	suite.Equal(StatusInProgress, t.Status)
+
+	suite.NoError(t.Block(DefaultWorkflow(), " waiting on design review ", user.Employee))
+	suite.Equal(StatusBlocked, t.Status)
+	suite.Equal("waiting on design review", t.BlockReason)
+}
+
+func (suite *TaskTestSuite) TestUnblockClearsReason() {
+	t := &Task{Status: StatusBlocked, BlockReason: "waiting on design review"}
+
+	suite.NoError(t.UpdateStatus(DefaultWorkflow(), StatusInProgress, user.Employee))
+	suite.Equal(StatusInProgress, t.Status)
+	suite.Empty(t.BlockReason)
+}
+
+func (suite *TaskTestSuite) TestBlockCompletedTask() {
+	t := &Task{Status: StatusCompleted}
+	suite.ErrorIs(t.Block(DefaultWorkflow(), "too late", user.Employer), ErrInvalidStatusTransition)
+}
+
+func (suite *TaskTestSuite) TestRecurrenceRuleNext() {
+	from := time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC)
+
+	suite.Equal(time.Date(2025, 2, 3, 9, 0, 0, 0, time.UTC), RecurrenceRule{Frequency: FrequencyDaily, Interval: 3}.Next(from))
+	suite.Equal(time.Date(2025, 2, 14, 9, 0, 0, 0, time.UTC), RecurrenceRule{Frequency: FrequencyWeekly, Interval: 2}.Next(from))
+	suite.Equal(time.Date(2025, 3, 31, 9, 0, 0, 0, time.UTC), RecurrenceRule{Frequency: FrequencyMonthly, Interval: 2}.Next(from))
+}
+
+func (suite *TaskTestSuite) TestSetRecurrence() {
+	t := &Task{}
+
+	suite.NoError(t.SetRecurrence(RecurrenceRule{Frequency: FrequencyWeekly}))
+	suite.Equal(RecurrenceRule{Frequency: FrequencyWeekly, Interval: 1}, t.Recurrence)
+	suite.True(t.IsRecurring())
+
+	suite.ErrorIs(t.SetRecurrence(RecurrenceRule{Frequency: "yearly", Interval: 1}), ErrInvalidRecurrence)
+	suite.ErrorIs(t.SetRecurrence(RecurrenceRule{Frequency: FrequencyDaily, Interval: -1}), ErrInvalidRecurrence)
+	suite.ErrorIs(t.SetRecurrence(RecurrenceRule{Frequency: FrequencyDaily, Interval: MaxRecurrenceInterval + 1}), ErrInvalidRecurrence)
+}
+
+func (suite *TaskTestSuite) TestNextOccurrenceWeekly() {
+	dueDate := time.Date(2025, 3, 3, 17, 0, 0, 0, time.UTC)
+	completed := &Task{
+		ID:          uuid.New(),
+		Title:       "Weekly report",
+		Description: "Summarise the week",
+		Status:      StatusCompleted,
+		AssigneeID:  uuid.New(),
+		CreatorID:   uuid.New(),
+		DueDate:     dueDate,
+		Recurrence:  RecurrenceRule{Frequency: FrequencyWeekly, Interval: 1},
+	}
+
+	next, err := completed.NextOccurrence(dueDate.Add(-time.Hour))
+	suite.NoError(err)
+	suite.NotEqual(completed.ID, next.ID)
+	suite.Equal(StatusPending, next.Status)
+	suite.Equal(time.Date(2025, 3, 10, 17, 0, 0, 0, time.UTC), next.DueDate)
+	suite.Equal(completed.Title, next.Title)
+	suite.Equal(completed.Description, next.Description)
+	suite.Equal(completed.AssigneeID, next.AssigneeID)
+	suite.Equal(completed.CreatorID, next.CreatorID)
+	suite.Equal(completed.Recurrence, next.Recurrence)
+	suite.Nil(next.NextTaskID)
+}
+
+func (suite *TaskTestSuite) TestNextOccurrenceSkipsMissedPeriods() {
+	dueDate := time.Date(2025, 3, 3, 17, 0, 0, 0, time.UTC)
+	completed := &Task{
+		Status:     StatusCompleted,
+		DueDate:    dueDate,
+		Recurrence: RecurrenceRule{Frequency: FrequencyWeekly, Interval: 1},
+	}
+
+	// Completed two and a half weeks late
+	next, err := completed.NextOccurrence(dueDate.AddDate(0, 0, 17))
+	suite.NoError(err)
+	suite.Equal(time.Date(2025, 3, 24, 17, 0, 0, 0, time.UTC), next.DueDate)
+}
+
+func (suite *TaskTestSuite) TestNextOccurrenceRequiresCompletedRecurringTask() {
+	_, err := (&Task{Status: StatusCompleted}).NextOccurrence(time.Now())
+	suite.ErrorIs(err, ErrNotRecurring)
+
+	recurring := &Task{Status: StatusInProgress, Recurrence: RecurrenceRule{Frequency: FrequencyDaily, Interval: 1}}
+	_, err = recurring.NextOccurrence(time.Now())
+	suite.ErrorIs(err, ErrInvalidStatusTransition)
+}
+
+func (suite *TaskTestSuite) TestNewTaskTemplate() {
+	assignee := uuid.New()
+	template, err := NewTaskTemplate("  Weekly report ", "Summarise the week", 48, &assignee, uuid.New())
+	suite.Require().NoError(err)
+	suite.Equal("Weekly report", template.Title)
+	suite.Equal(&assignee, template.DefaultAssigneeID)
+
+	from := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	suite.Equal(time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC), template.DueDateFrom(from))
+}
+
+func (suite *TaskTestSuite) TestNewTaskTemplateValidates() {
+	_, err := NewTaskTemplate(" ", "", 24, nil, uuid.New())
+	suite.ErrorIs(err, ErrEmptyTitle)
+
+	_, err = NewTaskTemplate("Report", "", 0, nil, uuid.New())
+	suite.ErrorIs(err, ErrInvalidDueOffset)
+}
+
+func TestTaskTestSuite(t *testing.T) {
+	suite.Run(t, new(TaskTestSuite))
+}
@@ -0,0 +1,58 @@
+package task
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskTemplate holds the defaults employers reuse when creating similar tasks
+type TaskTemplate struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	// DueOffsetHours is how long after instantiation the task falls due
+	DueOffsetHours    int        `json:"due_offset_hours"`
+	DefaultAssigneeID *uuid.UUID `json:"default_assignee_id,omitempty"`
+	CreatorID         uuid.UUID  `json:"creator_id"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// NewTaskTemplate creates a template owned by creatorID
+func NewTaskTemplate(title, description string, dueOffsetHours int, defaultAssigneeID *uuid.UUID, creatorID uuid.UUID) (*TaskTemplate, error) {
+	now := time.Now()
+	template := &TaskTemplate{
+		ID:        uuid.New(),
+		CreatorID: creatorID,
+		CreatedAt: now,
+	}
+	if err := template.Update(title, description, dueOffsetHours, defaultAssigneeID); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// Update replaces the template's defaults
+func (t *TaskTemplate) Update(title, description string, dueOffsetHours int, defaultAssigneeID *uuid.UUID) error {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return ErrEmptyTitle
+	}
+	if dueOffsetHours <= 0 {
+		return ErrInvalidDueOffset
+	}
+
+	t.Title = title
+	t.Description = description
+	t.DueOffsetHours = dueOffsetHours
+	t.DefaultAssigneeID = defaultAssigneeID
+	t.UpdatedAt = time.Now()
+	return nil
+}
+
+// DueDateFrom returns when a task instantiated at from falls due by default
+func (t *TaskTemplate) DueDateFrom(from time.Time) time.Time {
+	return from.Add(time.Duration(t.DueOffsetHours) * time.Hour)
+}
@@ -0,0 +1,58 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/personal/task-management/internal/domain/user"
+)
+
+// Workflow is the graph of status changes a task may go through
+type Workflow struct {
+	// Transitions lists the statuses any role may move a task to
+	Transitions map[Status][]Status
+	// Reopen lists the extra transitions only employers may make, so a task
+	// completed by mistake can be reopened
+	Reopen map[Status][]Status
+}
+
+// DefaultWorkflow is the graph used when a deployment doesn't configure one
+func DefaultWorkflow() Workflow {
+	return Workflow{
+		Transitions: map[Status][]Status{
+			StatusPending:    {StatusInProgress, StatusBlocked, StatusCompleted},
+			StatusInProgress: {StatusBlocked, StatusCompleted},
+			StatusBlocked:    {StatusPending, StatusInProgress},
+		},
+		Reopen: map[Status][]Status{
+			StatusCompleted: {StatusPending, StatusInProgress},
+		},
+	}
+}
+
+// Validate checks that every transition joins two known statuses. Deleting a
+// task is not a status change, so deleted may not appear in the graph.
+func (w Workflow) Validate() error {
+	for _, graph := range []map[Status][]Status{w.Transitions, w.Reopen} {
+		for from, targets := range graph {
+			if !from.IsValid() || from == StatusDeleted {
+				return fmt.Errorf("%w: unknown status %q", ErrInvalidWorkflow, from)
+			}
+			for _, to := range targets {
+				if !to.IsValid() || to == StatusDeleted {
+					return fmt.Errorf("%w: unknown status %q", ErrInvalidWorkflow, to)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Allows reports whether a user with the given role may move a task from one
+// status to the next
+func (w Workflow) Allows(from, to Status, role user.Role) bool {
+	if slices.Contains(w.Transitions[from], to) {
+		return true
+	}
+	return role == user.Employer && slices.Contains(w.Reopen[from], to)
+}
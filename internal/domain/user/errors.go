@@ -4,10 +4,17 @@ import "errors"
 
 // User domain errors
 var (
-	ErrEmptyEmail    = errors.New("email cannot be empty")
-	ErrEmptyName     = errors.New("name cannot be empty")
-	ErrEmptyPassword = errors.New("password cannot be empty")
-	ErrInvalidRole   = errors.New("invalid role")
-	ErrUserNotFound  = errors.New("user not found")
-	ErrEmailExists   = errors.New("email already exists")
+	ErrEmptyEmail      = errors.New("email cannot be empty")
+	ErrEmptyName       = errors.New("name cannot be empty")
+	ErrEmptyPassword   = errors.New("password cannot be empty")
+	ErrInvalidRole     = errors.New("invalid role")
+	ErrInvalidStatus   = errors.New("status must be active or inactive")
+	ErrForbidden       = errors.New("only employers can manage users")
+	ErrWrongPassword   = errors.New("current password is incorrect")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrEmailExists     = errors.New("email already exists")
+	ErrEmptySearch     = errors.New("search query cannot be empty")
+	ErrInvalidWindow   = errors.New("activity window must be positive")
+	ErrSessionNotFound = errors.New("session not found")
+	ErrInvalidSort     = errors.New("sort_by must be name, email, role or created_at and sort_order asc or desc")
 )
@@ -0,0 +1,36 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxDeviceLength bounds the device label stored with a session
+const maxDeviceLength = 255
+
+// Session is a signed-in device. Its ID is the ID of the refresh token issued
+// at sign-in, so deleting the session stops that token from being used.
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id" gorm:"index"`
+	Device     string    `json:"device"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// NewSession creates a session for the refresh token with the given ID
+func NewSession(id, userID uuid.UUID, device string, createdAt, expiresAt time.Time) *Session {
+	if len(device) > maxDeviceLength {
+		device = device[:maxDeviceLength]
+	}
+	return &Session{
+		ID:         id,
+		UserID:     userID,
+		Device:     device,
+		CreatedAt:  createdAt,
+		LastUsedAt: createdAt,
+		ExpiresAt:  expiresAt,
+	}
+}
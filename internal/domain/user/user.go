@@ -16,6 +16,10 @@ const (
 	Employee
 	// RoleEmployer represents an employer role
 	Employer
+	// Manager sits between employees and employers: they see and update
+	// every task but don't manage users. It comes last so stored roles keep
+	// their values.
+	Manager
 )
 
 func (r Role) String() string {
@@ -26,6 +30,8 @@ func (r Role) String() string {
 		return "employee"
 	case Employer:
 		return "employer"
+	case Manager:
+		return "manager"
 	default:
 		return "unknown"
 	}
@@ -48,6 +54,19 @@ func (s Status) String() string {
 	return "unknown"
 }
 
+// ParseStatus converts a status name to a Status, failing with
+// ErrInvalidStatus for anything but "active" and "inactive"
+func ParseStatus(status string) (Status, error) {
+	switch status {
+	case "active":
+		return StatusActive, nil
+	case "inactive":
+		return StatusInactive, nil
+	default:
+		return StatusActive, ErrInvalidStatus
+	}
+}
+
 // User represents a user in the system
 type User struct {
 	ID        uuid.UUID `json:"id"`
@@ -58,6 +77,11 @@ type User struct {
 	Status    Status    `json:"status"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// LastSeenAt is when the user last signed in; nil until they first do
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty" gorm:"index"`
+	// DeletedAt is when the user was deleted. Deleted users are kept, inactive,
+	// so their tasks and messages still point at someone.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // NewUser creates a new user with the given parameters
@@ -84,17 +108,25 @@ func NewUser(email, name, password string) (*User, error) {
 	}, nil
 }
 
-func (u *User) SetRole(role string) {
+// ParseRole converts a role name, as carried in tokens, to a Role. Unrecognised
+// names map to Unknown.
+func ParseRole(role string) Role {
 	switch role {
 	case "employee":
-		u.Role = Employee
+		return Employee
 	case "employer":
-		u.Role = Employer
+		return Employer
+	case "manager":
+		return Manager
 	default:
-		u.Role = Unknown
+		return Unknown
 	}
 }
 
+func (u *User) SetRole(role string) {
+	u.Role = ParseRole(role)
+}
+
 // IsEmployer checks if user has employer role
 func (u *User) IsEmployer() bool {
 	return u.Role == Employer
@@ -105,6 +137,28 @@ func (u *User) IsEmployee() bool {
 	return u.Role == Employee
 }
 
+// IsManager checks if user has manager role
+func (u *User) IsManager() bool {
+	return u.Role == Manager
+}
+
+// IsActive checks if the user may sign in and be assigned work
+func (u *User) IsActive() bool {
+	return u.Status == StatusActive
+}
+
+// Deactivate soft-deletes the user
+func (u *User) Deactivate(at time.Time) {
+	u.Status = StatusInactive
+	u.DeletedAt = &at
+	u.UpdatedAt = at
+}
+
+// CanBeAssignedTasks checks if tasks may be assigned to the user
+func (u *User) CanBeAssignedTasks() bool {
+	return u.IsEmployee() && u.IsActive()
+}
+
 // CanCreateTasks checks if user can create tasks
 func (u *User) CanCreateTasks() bool {
 	return u.IsEmployer()
@@ -117,10 +171,16 @@ func (u *User) CanAssignTasks() bool {
 
 // CanViewAllTasks checks if user can view all tasks
 func (u *User) CanViewAllTasks() bool {
-	return u.IsEmployer()
+	return u.IsEmployer() || u.IsManager()
+}
+
+// CanManageAllTasks checks if user can edit, reassign and discuss any task,
+// not only ones they created or are assigned
+func (u *User) CanManageAllTasks() bool {
+	return u.IsEmployer() || u.IsManager()
 }
 
 // CanUpdateTaskStatus checks if user can update task status
 func (u *User) CanUpdateTaskStatus() bool {
-	return true // Both roles can update status, but employee only their assigned tasks
+	return true // Every role can update status, but employee only their assigned tasks
 }
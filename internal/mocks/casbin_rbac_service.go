@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
+	middleware "github.com/personal/task-management/internal/delivery/rest/middleware"
 	user "github.com/personal/task-management/internal/domain/user"
 )
 
@@ -36,10 +37,27 @@ func (m *MockCasbinRBACService) EXPECT() *MockCasbinRBACServiceMockRecorder {
 	return m.recorder
 }
 
+// AddPolicy mocks base method.
+func (m *MockCasbinRBACService) AddPolicy(arg0 middleware.Policy) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPolicy", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPolicy indicates an expected call of AddPolicy.
+func (mr *MockCasbinRBACServiceMockRecorder) AddPolicy(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPolicy", reflect.TypeOf((*MockCasbinRBACService)(nil).AddPolicy), arg0)
+}
+
 // ApplyResourceFilter mocks base method.
-func (m *MockCasbinRBACService) ApplyResourceFilter(arg0 *http.Request, arg1 user.Role, arg2 uuid.UUID) {
+func (m *MockCasbinRBACService) ApplyResourceFilter(arg0 *http.Request, arg1 user.Role, arg2 uuid.UUID) *http.Request {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "ApplyResourceFilter", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "ApplyResourceFilter", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*http.Request)
+	return ret0
 }
 
 // ApplyResourceFilter indicates an expected call of ApplyResourceFilter.
@@ -61,3 +79,62 @@ func (mr *MockCasbinRBACServiceMockRecorder) HasPermission(arg0, arg1, arg2 inte
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPermission", reflect.TypeOf((*MockCasbinRBACService)(nil).HasPermission), arg0, arg1, arg2)
 }
+
+// Permissions mocks base method.
+func (m *MockCasbinRBACService) Permissions(arg0 user.Role) ([]middleware.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Permissions", arg0)
+	ret0, _ := ret[0].([]middleware.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Permissions indicates an expected call of Permissions.
+func (mr *MockCasbinRBACServiceMockRecorder) Permissions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Permissions", reflect.TypeOf((*MockCasbinRBACService)(nil).Permissions), arg0)
+}
+
+// Policies mocks base method.
+func (m *MockCasbinRBACService) Policies() ([]middleware.Policy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Policies")
+	ret0, _ := ret[0].([]middleware.Policy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Policies indicates an expected call of Policies.
+func (mr *MockCasbinRBACServiceMockRecorder) Policies() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Policies", reflect.TypeOf((*MockCasbinRBACService)(nil).Policies))
+}
+
+// ReloadPolicy mocks base method.
+func (m *MockCasbinRBACService) ReloadPolicy() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReloadPolicy")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReloadPolicy indicates an expected call of ReloadPolicy.
+func (mr *MockCasbinRBACServiceMockRecorder) ReloadPolicy() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadPolicy", reflect.TypeOf((*MockCasbinRBACService)(nil).ReloadPolicy))
+}
+
+// RemovePolicy mocks base method.
+func (m *MockCasbinRBACService) RemovePolicy(arg0 middleware.Policy) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePolicy", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemovePolicy indicates an expected call of RemovePolicy.
+func (mr *MockCasbinRBACServiceMockRecorder) RemovePolicy(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePolicy", reflect.TypeOf((*MockCasbinRBACService)(nil).RemovePolicy), arg0)
+}
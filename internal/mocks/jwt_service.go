@@ -35,6 +35,21 @@ func (m *MockJWTTokenServicer) EXPECT() *MockJWTTokenServicerMockRecorder {
 	return m.recorder
 }
 
+// GenerateRefreshToken mocks base method.
+func (m *MockJWTTokenServicer) GenerateRefreshToken(arg0 uuid.UUID, arg1, arg2 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateRefreshToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateRefreshToken indicates an expected call of GenerateRefreshToken.
+func (mr *MockJWTTokenServicerMockRecorder) GenerateRefreshToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateRefreshToken", reflect.TypeOf((*MockJWTTokenServicer)(nil).GenerateRefreshToken), arg0, arg1, arg2)
+}
+
 // GenerateToken mocks base method.
 func (m *MockJWTTokenServicer) GenerateToken(arg0 uuid.UUID, arg1, arg2 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +65,95 @@ func (mr *MockJWTTokenServicerMockRecorder) GenerateToken(arg0, arg1, arg2 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockJWTTokenServicer)(nil).GenerateToken), arg0, arg1, arg2)
 }
 
+// IssueRefreshToken mocks base method.
+func (m *MockJWTTokenServicer) IssueRefreshToken(arg0 uuid.UUID, arg1, arg2 string) (*jwt.IssuedToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueRefreshToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*jwt.IssuedToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueRefreshToken indicates an expected call of IssueRefreshToken.
+func (mr *MockJWTTokenServicerMockRecorder) IssueRefreshToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueRefreshToken", reflect.TypeOf((*MockJWTTokenServicer)(nil).IssueRefreshToken), arg0, arg1, arg2)
+}
+
+// IssueSessionToken mocks base method.
+func (m *MockJWTTokenServicer) IssueSessionToken(arg0 uuid.UUID, arg1, arg2, arg3 string) (*jwt.IssuedToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueSessionToken", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*jwt.IssuedToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueSessionToken indicates an expected call of IssueSessionToken.
+func (mr *MockJWTTokenServicerMockRecorder) IssueSessionToken(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueSessionToken", reflect.TypeOf((*MockJWTTokenServicer)(nil).IssueSessionToken), arg0, arg1, arg2, arg3)
+}
+
+// IssueToken mocks base method.
+func (m *MockJWTTokenServicer) IssueToken(arg0 uuid.UUID, arg1, arg2 string) (*jwt.IssuedToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*jwt.IssuedToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueToken indicates an expected call of IssueToken.
+func (mr *MockJWTTokenServicerMockRecorder) IssueToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueToken", reflect.TypeOf((*MockJWTTokenServicer)(nil).IssueToken), arg0, arg1, arg2)
+}
+
+// RefreshAccessToken mocks base method.
+func (m *MockJWTTokenServicer) RefreshAccessToken(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshAccessToken", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshAccessToken indicates an expected call of RefreshAccessToken.
+func (mr *MockJWTTokenServicerMockRecorder) RefreshAccessToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshAccessToken", reflect.TypeOf((*MockJWTTokenServicer)(nil).RefreshAccessToken), arg0)
+}
+
+// RevokeToken mocks base method.
+func (m *MockJWTTokenServicer) RevokeToken(arg0 *jwt.UserClaims) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockJWTTokenServicerMockRecorder) RevokeToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockJWTTokenServicer)(nil).RevokeToken), arg0)
+}
+
+// ValidateRefreshToken mocks base method.
+func (m *MockJWTTokenServicer) ValidateRefreshToken(arg0 string) (*jwt.UserClaims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateRefreshToken", arg0)
+	ret0, _ := ret[0].(*jwt.UserClaims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateRefreshToken indicates an expected call of ValidateRefreshToken.
+func (mr *MockJWTTokenServicerMockRecorder) ValidateRefreshToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateRefreshToken", reflect.TypeOf((*MockJWTTokenServicer)(nil).ValidateRefreshToken), arg0)
+}
+
 // ValidateToken mocks base method.
 func (m *MockJWTTokenServicer) ValidateToken(arg0 string) (*jwt.UserClaims, error) {
 	m.ctrl.T.Helper()
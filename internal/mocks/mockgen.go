@@ -5,6 +5,10 @@ package mocks
 //go:generate mockgen -destination=./jwt_service.go -package=mocks github.com/personal/task-management/pkg/utils/jwt JWTTokenServicer
 //go:generate mockgen -destination=./user_service.go -package=mocks github.com/personal/task-management/internal/usecase UserService
 //go:generate mockgen -destination=./task_service.go -package=mocks github.com/personal/task-management/internal/usecase TaskService
+//go:generate mockgen -destination=./task_template_service.go -package=mocks github.com/personal/task-management/internal/usecase TaskTemplateService
 //go:generate mockgen -destination=./casbin_rbac_service.go -package=mocks github.com/personal/task-management/internal/delivery/rest/middleware CasbinRBACService
 //go:generate mockgen -destination=./task_repository.go -package=mocks github.com/personal/task-management/internal/repositories TaskRepository
+//go:generate mockgen -destination=./task_comment_repository.go -package=mocks github.com/personal/task-management/internal/repositories TaskCommentRepository
+//go:generate mockgen -destination=./task_template_repository.go -package=mocks github.com/personal/task-management/internal/repositories TaskTemplateRepository
+//go:generate mockgen -destination=./session_repository.go -package=mocks github.com/personal/task-management/internal/repositories SessionRepository
 //go:generate mockgen -destination=./websocket_service.go -package=mocks github.com/personal/task-management/internal/usecase WebSocketService
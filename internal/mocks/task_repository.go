@@ -38,6 +38,21 @@ func (m *MockTaskRepository) EXPECT() *MockTaskRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddTag mocks base method.
+func (m *MockTaskRepository) AddTag(arg0 context.Context, arg1 uuid.UUID, arg2 *task.Tag) (*task.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTag", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*task.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTag indicates an expected call of AddTag.
+func (mr *MockTaskRepositoryMockRecorder) AddTag(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTag", reflect.TypeOf((*MockTaskRepository)(nil).AddTag), arg0, arg1, arg2)
+}
+
 // Create mocks base method.
 func (m *MockTaskRepository) Create(arg0 context.Context, arg1 *task.Task) error {
 	m.ctrl.T.Helper()
@@ -52,6 +67,35 @@ func (mr *MockTaskRepositoryMockRecorder) Create(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTaskRepository)(nil).Create), arg0, arg1)
 }
 
+// CreateBatch mocks base method.
+func (m *MockTaskRepository) CreateBatch(arg0 context.Context, arg1 []*task.Task) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBatch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBatch indicates an expected call of CreateBatch.
+func (mr *MockTaskRepositoryMockRecorder) CreateBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBatch", reflect.TypeOf((*MockTaskRepository)(nil).CreateBatch), arg0, arg1)
+}
+
+// CreateNextOccurrence mocks base method.
+func (m *MockTaskRepository) CreateNextOccurrence(arg0 context.Context, arg1, arg2 *task.Task) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNextOccurrence", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNextOccurrence indicates an expected call of CreateNextOccurrence.
+func (mr *MockTaskRepositoryMockRecorder) CreateNextOccurrence(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNextOccurrence", reflect.TypeOf((*MockTaskRepository)(nil).CreateNextOccurrence), arg0, arg1, arg2)
+}
+
 // Delete mocks base method.
 func (m *MockTaskRepository) Delete(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -126,6 +170,36 @@ func (mr *MockTaskRepositoryMockRecorder) FindByStatus(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByStatus", reflect.TypeOf((*MockTaskRepository)(nil).FindByStatus), arg0, arg1)
 }
 
+// FindOverdue mocks base method.
+func (m *MockTaskRepository) FindOverdue(arg0 context.Context, arg1 time.Time, arg2 *uuid.UUID) ([]*task.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOverdue", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*task.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOverdue indicates an expected call of FindOverdue.
+func (mr *MockTaskRepositoryMockRecorder) FindOverdue(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOverdue", reflect.TypeOf((*MockTaskRepository)(nil).FindOverdue), arg0, arg1, arg2)
+}
+
+// FindRecurrencesDue mocks base method.
+func (m *MockTaskRepository) FindRecurrencesDue(arg0 context.Context) ([]*task.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRecurrencesDue", arg0)
+	ret0, _ := ret[0].([]*task.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRecurrencesDue indicates an expected call of FindRecurrencesDue.
+func (mr *MockTaskRepositoryMockRecorder) FindRecurrencesDue(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRecurrencesDue", reflect.TypeOf((*MockTaskRepository)(nil).FindRecurrencesDue), arg0)
+}
+
 // GetByID mocks base method.
 func (m *MockTaskRepository) GetByID(arg0 context.Context, arg1 uuid.UUID) (*task.Task, error) {
 	m.ctrl.T.Helper()
@@ -156,6 +230,50 @@ func (mr *MockTaskRepositoryMockRecorder) List(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTaskRepository)(nil).List), arg0, arg1)
 }
 
+// ListEvents mocks base method.
+func (m *MockTaskRepository) ListEvents(arg0 context.Context, arg1 uuid.UUID) ([]*task.TaskEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEvents", arg0, arg1)
+	ret0, _ := ret[0].([]*task.TaskEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEvents indicates an expected call of ListEvents.
+func (mr *MockTaskRepositoryMockRecorder) ListEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEvents", reflect.TypeOf((*MockTaskRepository)(nil).ListEvents), arg0, arg1)
+}
+
+// ListTags mocks base method.
+func (m *MockTaskRepository) ListTags(arg0 context.Context, arg1 uuid.UUID) ([]*task.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTags", arg0, arg1)
+	ret0, _ := ret[0].([]*task.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTags indicates an expected call of ListTags.
+func (mr *MockTaskRepositoryMockRecorder) ListTags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockTaskRepository)(nil).ListTags), arg0, arg1)
+}
+
+// RemoveTag mocks base method.
+func (m *MockTaskRepository) RemoveTag(arg0 context.Context, arg1 uuid.UUID, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTag", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTag indicates an expected call of RemoveTag.
+func (mr *MockTaskRepositoryMockRecorder) RemoveTag(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTag", reflect.TypeOf((*MockTaskRepository)(nil).RemoveTag), arg0, arg1, arg2)
+}
+
 // Update mocks base method.
 func (m *MockTaskRepository) Update(arg0 context.Context, arg1 *task.Task) error {
 	m.ctrl.T.Helper()
@@ -169,3 +287,31 @@ func (mr *MockTaskRepositoryMockRecorder) Update(arg0, arg1 interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTaskRepository)(nil).Update), arg0, arg1)
 }
+
+// UpdateWithComment mocks base method.
+func (m *MockTaskRepository) UpdateWithComment(arg0 context.Context, arg1 *task.Task, arg2 *task.TaskComment, arg3 []*task.TaskEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWithComment", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWithComment indicates an expected call of UpdateWithComment.
+func (mr *MockTaskRepositoryMockRecorder) UpdateWithComment(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWithComment", reflect.TypeOf((*MockTaskRepository)(nil).UpdateWithComment), arg0, arg1, arg2, arg3)
+}
+
+// UpdateWithEvents mocks base method.
+func (m *MockTaskRepository) UpdateWithEvents(arg0 context.Context, arg1 *task.Task, arg2 []*task.TaskEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWithEvents", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWithEvents indicates an expected call of UpdateWithEvents.
+func (mr *MockTaskRepositoryMockRecorder) UpdateWithEvents(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWithEvents", reflect.TypeOf((*MockTaskRepository)(nil).UpdateWithEvents), arg0, arg1, arg2)
+}
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 	dtos "github.com/personal/task-management/internal/delivery/rest/dtos"
 	task "github.com/personal/task-management/internal/domain/task"
 )
@@ -36,6 +37,36 @@ func (m *MockTaskService) EXPECT() *MockTaskServiceMockRecorder {
 	return m.recorder
 }
 
+// AddComment mocks base method.
+func (m *MockTaskService) AddComment(arg0 context.Context, arg1 dtos.AddCommentInput) (*task.TaskComment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddComment", arg0, arg1)
+	ret0, _ := ret[0].(*task.TaskComment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddComment indicates an expected call of AddComment.
+func (mr *MockTaskServiceMockRecorder) AddComment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddComment", reflect.TypeOf((*MockTaskService)(nil).AddComment), arg0, arg1)
+}
+
+// AddTag mocks base method.
+func (m *MockTaskService) AddTag(arg0 context.Context, arg1 dtos.AddTagInput) (*task.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTag", arg0, arg1)
+	ret0, _ := ret[0].(*task.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTag indicates an expected call of AddTag.
+func (mr *MockTaskServiceMockRecorder) AddTag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTag", reflect.TypeOf((*MockTaskService)(nil).AddTag), arg0, arg1)
+}
+
 // CreateTask mocks base method.
 func (m *MockTaskService) CreateTask(arg0 context.Context, arg1 dtos.CreateTaskInput) (*task.Task, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +82,35 @@ func (mr *MockTaskServiceMockRecorder) CreateTask(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTask", reflect.TypeOf((*MockTaskService)(nil).CreateTask), arg0, arg1)
 }
 
+// CreateTasksBulk mocks base method.
+func (m *MockTaskService) CreateTasksBulk(arg0 context.Context, arg1 dtos.CreateTasksBulkInput) (*dtos.CreateTasksBulkOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTasksBulk", arg0, arg1)
+	ret0, _ := ret[0].(*dtos.CreateTasksBulkOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTasksBulk indicates an expected call of CreateTasksBulk.
+func (mr *MockTaskServiceMockRecorder) CreateTasksBulk(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTasksBulk", reflect.TypeOf((*MockTaskService)(nil).CreateTasksBulk), arg0, arg1)
+}
+
+// DeleteComment mocks base method.
+func (m *MockTaskService) DeleteComment(arg0 context.Context, arg1 dtos.DeleteCommentInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteComment", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteComment indicates an expected call of DeleteComment.
+func (mr *MockTaskServiceMockRecorder) DeleteComment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockTaskService)(nil).DeleteComment), arg0, arg1)
+}
+
 // DeleteTask mocks base method.
 func (m *MockTaskService) DeleteTask(arg0 context.Context, arg1 dtos.DeleteTaskInput) error {
 	m.ctrl.T.Helper()
@@ -65,6 +125,21 @@ func (mr *MockTaskServiceMockRecorder) DeleteTask(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTask", reflect.TypeOf((*MockTaskService)(nil).DeleteTask), arg0, arg1)
 }
 
+// GenerateDueRecurrences mocks base method.
+func (m *MockTaskService) GenerateDueRecurrences(arg0 context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateDueRecurrences", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateDueRecurrences indicates an expected call of GenerateDueRecurrences.
+func (mr *MockTaskServiceMockRecorder) GenerateDueRecurrences(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateDueRecurrences", reflect.TypeOf((*MockTaskService)(nil).GenerateDueRecurrences), arg0)
+}
+
 // GetEmployeeTasks mocks base method.
 func (m *MockTaskService) GetEmployeeTasks(arg0 context.Context, arg1 dtos.GetEmployeeTasksInput) ([]*task.Task, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +155,21 @@ func (mr *MockTaskServiceMockRecorder) GetEmployeeTasks(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmployeeTasks", reflect.TypeOf((*MockTaskService)(nil).GetEmployeeTasks), arg0, arg1)
 }
 
+// GetOverdueTasks mocks base method.
+func (m *MockTaskService) GetOverdueTasks(arg0 context.Context, arg1 uuid.UUID) ([]*task.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverdueTasks", arg0, arg1)
+	ret0, _ := ret[0].([]*task.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverdueTasks indicates an expected call of GetOverdueTasks.
+func (mr *MockTaskServiceMockRecorder) GetOverdueTasks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdueTasks", reflect.TypeOf((*MockTaskService)(nil).GetOverdueTasks), arg0, arg1)
+}
+
 // GetTask mocks base method.
 func (m *MockTaskService) GetTask(arg0 context.Context, arg1 dtos.GetTaskInput) (*task.Task, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +185,21 @@ func (mr *MockTaskServiceMockRecorder) GetTask(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTask", reflect.TypeOf((*MockTaskService)(nil).GetTask), arg0, arg1)
 }
 
+// GetTaskHistory mocks base method.
+func (m *MockTaskService) GetTaskHistory(arg0 context.Context, arg1, arg2 uuid.UUID) ([]*task.TaskEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskHistory", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*task.TaskEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskHistory indicates an expected call of GetTaskHistory.
+func (mr *MockTaskServiceMockRecorder) GetTaskHistory(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskHistory", reflect.TypeOf((*MockTaskService)(nil).GetTaskHistory), arg0, arg1, arg2)
+}
+
 // GetTaskSummaryByEmployee mocks base method.
 func (m *MockTaskService) GetTaskSummaryByEmployee(arg0 context.Context, arg1 dtos.GetTaskSummaryByEmployeeInput) ([]dtos.EmployeeTaskSummary, error) {
 	m.ctrl.T.Helper()
@@ -125,6 +230,80 @@ func (mr *MockTaskServiceMockRecorder) GetTasksWithFilter(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTasksWithFilter", reflect.TypeOf((*MockTaskService)(nil).GetTasksWithFilter), arg0, arg1)
 }
 
+// ListComments mocks base method.
+func (m *MockTaskService) ListComments(arg0 context.Context, arg1 dtos.ListCommentsInput) ([]*task.TaskComment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListComments", arg0, arg1)
+	ret0, _ := ret[0].([]*task.TaskComment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListComments indicates an expected call of ListComments.
+func (mr *MockTaskServiceMockRecorder) ListComments(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListComments", reflect.TypeOf((*MockTaskService)(nil).ListComments), arg0, arg1)
+}
+
+// ListTags mocks base method.
+func (m *MockTaskService) ListTags(arg0 context.Context, arg1 dtos.ListTagsInput) ([]*task.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTags", arg0, arg1)
+	ret0, _ := ret[0].([]*task.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTags indicates an expected call of ListTags.
+func (mr *MockTaskServiceMockRecorder) ListTags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockTaskService)(nil).ListTags), arg0, arg1)
+}
+
+// ReassignTask mocks base method.
+func (m *MockTaskService) ReassignTask(arg0 context.Context, arg1 dtos.ReassignTaskInput) (*task.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReassignTask", arg0, arg1)
+	ret0, _ := ret[0].(*task.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReassignTask indicates an expected call of ReassignTask.
+func (mr *MockTaskServiceMockRecorder) ReassignTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReassignTask", reflect.TypeOf((*MockTaskService)(nil).ReassignTask), arg0, arg1)
+}
+
+// RemoveTag mocks base method.
+func (m *MockTaskService) RemoveTag(arg0 context.Context, arg1 dtos.RemoveTagInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTag", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTag indicates an expected call of RemoveTag.
+func (mr *MockTaskServiceMockRecorder) RemoveTag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTag", reflect.TypeOf((*MockTaskService)(nil).RemoveTag), arg0, arg1)
+}
+
+// UpdateTaskDetails mocks base method.
+func (m *MockTaskService) UpdateTaskDetails(arg0 context.Context, arg1 dtos.UpdateTaskDetailsInput) (*task.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaskDetails", arg0, arg1)
+	ret0, _ := ret[0].(*task.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTaskDetails indicates an expected call of UpdateTaskDetails.
+func (mr *MockTaskServiceMockRecorder) UpdateTaskDetails(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskDetails", reflect.TypeOf((*MockTaskService)(nil).UpdateTaskDetails), arg0, arg1)
+}
+
 // UpdateTaskStatus mocks base method.
 func (m *MockTaskService) UpdateTaskStatus(arg0 context.Context, arg1 dtos.UpdateTaskStatusInput) (*task.Task, error) {
 	m.ctrl.T.Helper()
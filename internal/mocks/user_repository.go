@@ -7,10 +7,12 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
 	user "github.com/personal/task-management/internal/domain/user"
+	repository "github.com/personal/task-management/internal/repositories"
 )
 
 // MockUserRepository is a mock of UserRepository interface.
@@ -36,6 +38,21 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Count mocks base method.
+func (m *MockUserRepository) Count(arg0 context.Context, arg1 repository.UserFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockUserRepositoryMockRecorder) Count(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockUserRepository)(nil).Count), arg0, arg1)
+}
+
 // Create mocks base method.
 func (m *MockUserRepository) Create(arg0 context.Context, arg1 *user.User) error {
 	m.ctrl.T.Helper()
@@ -95,18 +112,62 @@ func (mr *MockUserRepositoryMockRecorder) GetByID(arg0, arg1 interface{}) *gomoc
 }
 
 // List mocks base method.
-func (m *MockUserRepository) List(arg0 context.Context, arg1, arg2 int) ([]*user.User, error) {
+func (m *MockUserRepository) List(arg0 context.Context, arg1 repository.UserFilter) ([]*user.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
 	ret0, _ := ret[0].([]*user.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockUserRepositoryMockRecorder) List(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserRepository)(nil).List), arg0, arg1)
+}
+
+// ListRecentlyActive mocks base method.
+func (m *MockUserRepository) ListRecentlyActive(arg0 context.Context, arg1 time.Time, arg2 int) ([]*user.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRecentlyActive", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*user.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecentlyActive indicates an expected call of ListRecentlyActive.
+func (mr *MockUserRepositoryMockRecorder) ListRecentlyActive(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecentlyActive", reflect.TypeOf((*MockUserRepository)(nil).ListRecentlyActive), arg0, arg1, arg2)
+}
+
+// SearchByNamePrefix mocks base method.
+func (m *MockUserRepository) SearchByNamePrefix(arg0 context.Context, arg1 string, arg2 int, arg3 *uuid.UUID) ([]*user.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchByNamePrefix", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*user.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchByNamePrefix indicates an expected call of SearchByNamePrefix.
+func (mr *MockUserRepositoryMockRecorder) SearchByNamePrefix(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchByNamePrefix", reflect.TypeOf((*MockUserRepository)(nil).SearchByNamePrefix), arg0, arg1, arg2, arg3)
+}
+
+// TouchLastSeen mocks base method.
+func (m *MockUserRepository) TouchLastSeen(arg0 context.Context, arg1 uuid.UUID, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchLastSeen", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchLastSeen indicates an expected call of TouchLastSeen.
+func (mr *MockUserRepositoryMockRecorder) TouchLastSeen(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserRepository)(nil).List), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchLastSeen", reflect.TypeOf((*MockUserRepository)(nil).TouchLastSeen), arg0, arg1, arg2)
 }
 
 // Update mocks base method.
@@ -9,8 +9,10 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 	dtos "github.com/personal/task-management/internal/delivery/rest/dtos"
 	user "github.com/personal/task-management/internal/domain/user"
+	jwt "github.com/personal/task-management/pkg/utils/jwt"
 )
 
 // MockUserService is a mock of UserService interface.
@@ -36,6 +38,34 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockUserService) ChangePassword(arg0 context.Context, arg1 dtos.ChangePasswordInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockUserServiceMockRecorder) ChangePassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUserService)(nil).ChangePassword), arg0, arg1)
+}
+
+// DeleteUser mocks base method.
+func (m *MockUserService) DeleteUser(arg0 context.Context, arg1, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockUserServiceMockRecorder) DeleteUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockUserService)(nil).DeleteUser), arg0, arg1, arg2)
+}
+
 // GetUser mocks base method.
 func (m *MockUserService) GetUser(arg0 context.Context, arg1 dtos.GetUserInput) (*user.User, error) {
 	m.ctrl.T.Helper()
@@ -51,15 +81,61 @@ func (mr *MockUserServiceMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUserService)(nil).GetUser), arg0, arg1)
 }
 
-// ListUsers mocks base method.
-func (m *MockUserService) ListUsers(arg0 context.Context, arg1 dtos.ListUsersInput) ([]*user.User, error) {
+// IsUserActive mocks base method.
+func (m *MockUserService) IsUserActive(arg0 context.Context, arg1 uuid.UUID) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
+	ret := m.ctrl.Call(m, "IsUserActive", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsUserActive indicates an expected call of IsUserActive.
+func (mr *MockUserServiceMockRecorder) IsUserActive(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsUserActive", reflect.TypeOf((*MockUserService)(nil).IsUserActive), arg0, arg1)
+}
+
+// ListActiveUsers mocks base method.
+func (m *MockUserService) ListActiveUsers(arg0 context.Context, arg1 dtos.ListActiveUsersInput) ([]*user.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveUsers", arg0, arg1)
 	ret0, _ := ret[0].([]*user.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// ListActiveUsers indicates an expected call of ListActiveUsers.
+func (mr *MockUserServiceMockRecorder) ListActiveUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveUsers", reflect.TypeOf((*MockUserService)(nil).ListActiveUsers), arg0, arg1)
+}
+
+// ListSessions mocks base method.
+func (m *MockUserService) ListSessions(arg0 context.Context, arg1 uuid.UUID) ([]*user.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", arg0, arg1)
+	ret0, _ := ret[0].([]*user.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockUserServiceMockRecorder) ListSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockUserService)(nil).ListSessions), arg0, arg1)
+}
+
+// ListUsers mocks base method.
+func (m *MockUserService) ListUsers(arg0 context.Context, arg1 dtos.ListUsersInput) ([]*user.User, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
+	ret0, _ := ret[0].([]*user.User)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
 // ListUsers indicates an expected call of ListUsers.
 func (mr *MockUserServiceMockRecorder) ListUsers(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
@@ -81,6 +157,47 @@ func (mr *MockUserServiceMockRecorder) Login(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserService)(nil).Login), arg0, arg1)
 }
 
+// Logout mocks base method.
+func (m *MockUserService) Logout(arg0 context.Context, arg1 *jwt.UserClaims) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockUserServiceMockRecorder) Logout(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockUserService)(nil).Logout), arg0, arg1)
+}
+
+// RecordActivity mocks base method.
+func (m *MockUserService) RecordActivity(arg0 context.Context, arg1 uuid.UUID) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordActivity", arg0, arg1)
+}
+
+// RecordActivity indicates an expected call of RecordActivity.
+func (mr *MockUserServiceMockRecorder) RecordActivity(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordActivity", reflect.TypeOf((*MockUserService)(nil).RecordActivity), arg0, arg1)
+}
+
+// RefreshToken mocks base method.
+func (m *MockUserService) RefreshToken(arg0 context.Context, arg1 dtos.RefreshTokenInput) (*dtos.RefreshTokenOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(*dtos.RefreshTokenOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockUserServiceMockRecorder) RefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockUserService)(nil).RefreshToken), arg0, arg1)
+}
+
 // RegisterUser mocks base method.
 func (m *MockUserService) RegisterUser(arg0 context.Context, arg1 dtos.RegisterUserInput) (*dtos.GetUserOutput, error) {
 	m.ctrl.T.Helper()
@@ -96,6 +213,35 @@ func (mr *MockUserServiceMockRecorder) RegisterUser(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockUserService)(nil).RegisterUser), arg0, arg1)
 }
 
+// RevokeSession mocks base method.
+func (m *MockUserService) RevokeSession(arg0 context.Context, arg1, arg2 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockUserServiceMockRecorder) RevokeSession(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockUserService)(nil).RevokeSession), arg0, arg1, arg2)
+}
+
+// SearchUsers mocks base method.
+func (m *MockUserService) SearchUsers(arg0 context.Context, arg1 dtos.SearchUsersInput) ([]*user.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", arg0, arg1)
+	ret0, _ := ret[0].([]*user.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockUserServiceMockRecorder) SearchUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockUserService)(nil).SearchUsers), arg0, arg1)
+}
+
 // UpdateUser mocks base method.
 func (m *MockUserService) UpdateUser(arg0 context.Context, arg1 dtos.UpdateUserInput) (*user.User, error) {
 	m.ctrl.T.Helper()
@@ -5,11 +5,15 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	websocket "github.com/gorilla/websocket"
 	domain "github.com/personal/task-management/internal/domain"
+	repository "github.com/personal/task-management/internal/repositories"
+	usecase "github.com/personal/task-management/internal/usecase"
 )
 
 // MockWebSocketService is a mock of WebSocketService interface.
@@ -35,18 +39,46 @@ func (m *MockWebSocketService) EXPECT() *MockWebSocketServiceMockRecorder {
 	return m.recorder
 }
 
-// BroadcastTaskUpdate mocks base method.
-func (m *MockWebSocketService) BroadcastTaskUpdate(arg0, arg1, arg2 string) error {
+// AddMember mocks base method.
+func (m *MockWebSocketService) AddMember(arg0, arg1, arg2 string, arg3 bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BroadcastTaskUpdate", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "AddMember", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// BroadcastTaskUpdate indicates an expected call of BroadcastTaskUpdate.
-func (mr *MockWebSocketServiceMockRecorder) BroadcastTaskUpdate(arg0, arg1, arg2 interface{}) *gomock.Call {
+// AddMember indicates an expected call of AddMember.
+func (mr *MockWebSocketServiceMockRecorder) AddMember(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BroadcastTaskUpdate", reflect.TypeOf((*MockWebSocketService)(nil).BroadcastTaskUpdate), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*MockWebSocketService)(nil).AddMember), arg0, arg1, arg2, arg3)
+}
+
+// ArchiveRoom mocks base method.
+func (m *MockWebSocketService) ArchiveRoom(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveRoom", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ArchiveRoom indicates an expected call of ArchiveRoom.
+func (mr *MockWebSocketServiceMockRecorder) ArchiveRoom(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveRoom", reflect.TypeOf((*MockWebSocketService)(nil).ArchiveRoom), arg0, arg1)
+}
+
+// Close mocks base method.
+func (m *MockWebSocketService) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockWebSocketServiceMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockWebSocketService)(nil).Close))
 }
 
 // CreateDirectRoom mocks base method.
@@ -65,30 +97,254 @@ func (mr *MockWebSocketServiceMockRecorder) CreateDirectRoom(arg0, arg1 interfac
 }
 
 // CreateGroupRoom mocks base method.
-func (m *MockWebSocketService) CreateGroupRoom(arg0 string, arg1 []string) (*domain.Room, error) {
+func (m *MockWebSocketService) CreateGroupRoom(arg0, arg1 string, arg2 []string, arg3 string) (*domain.Room, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateGroupRoom", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreateGroupRoom", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(*domain.Room)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateGroupRoom indicates an expected call of CreateGroupRoom.
-func (mr *MockWebSocketServiceMockRecorder) CreateGroupRoom(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockWebSocketServiceMockRecorder) CreateGroupRoom(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroupRoom", reflect.TypeOf((*MockWebSocketService)(nil).CreateGroupRoom), arg0, arg1, arg2, arg3)
+}
+
+// DeleteRoom mocks base method.
+func (m *MockWebSocketService) DeleteRoom(arg0, arg1 string, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRoom", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRoom indicates an expected call of DeleteRoom.
+func (mr *MockWebSocketServiceMockRecorder) DeleteRoom(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroupRoom", reflect.TypeOf((*MockWebSocketService)(nil).CreateGroupRoom), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoom", reflect.TypeOf((*MockWebSocketService)(nil).DeleteRoom), arg0, arg1, arg2)
+}
+
+// DemoteMember mocks base method.
+func (m *MockWebSocketService) DemoteMember(arg0, arg1, arg2 string, arg3 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DemoteMember", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DemoteMember indicates an expected call of DemoteMember.
+func (mr *MockWebSocketServiceMockRecorder) DemoteMember(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DemoteMember", reflect.TypeOf((*MockWebSocketService)(nil).DemoteMember), arg0, arg1, arg2, arg3)
+}
+
+// GetChatStats mocks base method.
+func (m *MockWebSocketService) GetChatStats(arg0, arg1 time.Time, arg2 int) (*domain.ChatStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChatStats", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.ChatStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChatStats indicates an expected call of GetChatStats.
+func (mr *MockWebSocketServiceMockRecorder) GetChatStats(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatStats", reflect.TypeOf((*MockWebSocketService)(nil).GetChatStats), arg0, arg1, arg2)
+}
+
+// GetDirectRoomID mocks base method.
+func (m *MockWebSocketService) GetDirectRoomID(arg0, arg1 string) (string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDirectRoomID", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetDirectRoomID indicates an expected call of GetDirectRoomID.
+func (mr *MockWebSocketServiceMockRecorder) GetDirectRoomID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDirectRoomID", reflect.TypeOf((*MockWebSocketService)(nil).GetDirectRoomID), arg0, arg1)
+}
+
+// GetMissingMessages mocks base method.
+func (m *MockWebSocketService) GetMissingMessages(arg0, arg1 string, arg2 int64, arg3 int) ([]domain.WebSocketMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMissingMessages", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]domain.WebSocketMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMissingMessages indicates an expected call of GetMissingMessages.
+func (mr *MockWebSocketServiceMockRecorder) GetMissingMessages(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMissingMessages", reflect.TypeOf((*MockWebSocketService)(nil).GetMissingMessages), arg0, arg1, arg2, arg3)
+}
+
+// GetNotificationPreferences mocks base method.
+func (m *MockWebSocketService) GetNotificationPreferences(arg0 string) ([]*domain.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationPreferences", arg0)
+	ret0, _ := ret[0].([]*domain.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationPreferences indicates an expected call of GetNotificationPreferences.
+func (mr *MockWebSocketServiceMockRecorder) GetNotificationPreferences(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationPreferences", reflect.TypeOf((*MockWebSocketService)(nil).GetNotificationPreferences), arg0)
+}
+
+// GetPinnedMessages mocks base method.
+func (m *MockWebSocketService) GetPinnedMessages(arg0, arg1 string) ([]domain.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPinnedMessages", arg0, arg1)
+	ret0, _ := ret[0].([]domain.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPinnedMessages indicates an expected call of GetPinnedMessages.
+func (mr *MockWebSocketServiceMockRecorder) GetPinnedMessages(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPinnedMessages", reflect.TypeOf((*MockWebSocketService)(nil).GetPinnedMessages), arg0, arg1)
+}
+
+// GetReadReceipts mocks base method.
+func (m *MockWebSocketService) GetReadReceipts(arg0, arg1, arg2 string) ([]domain.ReadReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReadReceipts", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]domain.ReadReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReadReceipts indicates an expected call of GetReadReceipts.
+func (mr *MockWebSocketServiceMockRecorder) GetReadReceipts(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReadReceipts", reflect.TypeOf((*MockWebSocketService)(nil).GetReadReceipts), arg0, arg1, arg2)
+}
+
+// GetRoomDetails mocks base method.
+func (m *MockWebSocketService) GetRoomDetails(arg0, arg1 string) (*domain.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomDetails", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoomDetails indicates an expected call of GetRoomDetails.
+func (mr *MockWebSocketServiceMockRecorder) GetRoomDetails(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomDetails", reflect.TypeOf((*MockWebSocketService)(nil).GetRoomDetails), arg0, arg1)
+}
+
+// GetRoomHistory mocks base method.
+func (m *MockWebSocketService) GetRoomHistory(arg0, arg1 string, arg2, arg3 int, arg4 domain.HistoryOrder) ([]domain.WebSocketMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomHistory", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]domain.WebSocketMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoomHistory indicates an expected call of GetRoomHistory.
+func (mr *MockWebSocketServiceMockRecorder) GetRoomHistory(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomHistory", reflect.TypeOf((*MockWebSocketService)(nil).GetRoomHistory), arg0, arg1, arg2, arg3, arg4)
+}
+
+// GetRoomHistoryBefore mocks base method.
+func (m *MockWebSocketService) GetRoomHistoryBefore(arg0, arg1, arg2 string, arg3 int) ([]domain.WebSocketMessage, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomHistoryBefore", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]domain.WebSocketMessage)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetRoomHistoryBefore indicates an expected call of GetRoomHistoryBefore.
+func (mr *MockWebSocketServiceMockRecorder) GetRoomHistoryBefore(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomHistoryBefore", reflect.TypeOf((*MockWebSocketService)(nil).GetRoomHistoryBefore), arg0, arg1, arg2, arg3)
+}
+
+// GetUnreadCount mocks base method.
+func (m *MockWebSocketService) GetUnreadCount(arg0, arg1 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnreadCount", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnreadCount indicates an expected call of GetUnreadCount.
+func (mr *MockWebSocketServiceMockRecorder) GetUnreadCount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnreadCount", reflect.TypeOf((*MockWebSocketService)(nil).GetUnreadCount), arg0, arg1)
+}
+
+// GetUnreadCounts mocks base method.
+func (m *MockWebSocketService) GetUnreadCounts(arg0 string) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnreadCounts", arg0)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnreadCounts indicates an expected call of GetUnreadCounts.
+func (mr *MockWebSocketServiceMockRecorder) GetUnreadCounts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnreadCounts", reflect.TypeOf((*MockWebSocketService)(nil).GetUnreadCounts), arg0)
+}
+
+// GetUnreadNotificationCount mocks base method.
+func (m *MockWebSocketService) GetUnreadNotificationCount(arg0 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnreadNotificationCount", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnreadNotificationCount indicates an expected call of GetUnreadNotificationCount.
+func (mr *MockWebSocketServiceMockRecorder) GetUnreadNotificationCount(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnreadNotificationCount", reflect.TypeOf((*MockWebSocketService)(nil).GetUnreadNotificationCount), arg0)
 }
 
 // HandleConnection mocks base method.
-func (m *MockWebSocketService) HandleConnection(arg0 *websocket.Conn, arg1 string) {
+func (m *MockWebSocketService) HandleConnection(arg0 *websocket.Conn, arg1, arg2 string) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "HandleConnection", arg0, arg1)
+	m.ctrl.Call(m, "HandleConnection", arg0, arg1, arg2)
 }
 
 // HandleConnection indicates an expected call of HandleConnection.
-func (mr *MockWebSocketServiceMockRecorder) HandleConnection(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockWebSocketServiceMockRecorder) HandleConnection(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleConnection", reflect.TypeOf((*MockWebSocketService)(nil).HandleConnection), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleConnection", reflect.TypeOf((*MockWebSocketService)(nil).HandleConnection), arg0, arg1, arg2)
+}
+
+// HeartbeatStats mocks base method.
+func (m *MockWebSocketService) HeartbeatStats() domain.HeartbeatStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HeartbeatStats")
+	ret0, _ := ret[0].(domain.HeartbeatStats)
+	return ret0
+}
+
+// HeartbeatStats indicates an expected call of HeartbeatStats.
+func (mr *MockWebSocketServiceMockRecorder) HeartbeatStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeartbeatStats", reflect.TypeOf((*MockWebSocketService)(nil).HeartbeatStats))
 }
 
 // JoinRoom mocks base method.
@@ -119,26 +375,228 @@ func (mr *MockWebSocketServiceMockRecorder) LeaveRoom(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaveRoom", reflect.TypeOf((*MockWebSocketService)(nil).LeaveRoom), arg0, arg1)
 }
 
-// SendDirectMessage mocks base method.
-func (m *MockWebSocketService) SendDirectMessage(arg0, arg1, arg2 string) error {
+// ListNotifications mocks base method.
+func (m *MockWebSocketService) ListNotifications(arg0 string, arg1, arg2 int) ([]*domain.Notification, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendDirectMessage", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "ListNotifications", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*domain.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifications indicates an expected call of ListNotifications.
+func (mr *MockWebSocketServiceMockRecorder) ListNotifications(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifications", reflect.TypeOf((*MockWebSocketService)(nil).ListNotifications), arg0, arg1, arg2)
+}
+
+// ListRoomMembers mocks base method.
+func (m *MockWebSocketService) ListRoomMembers(arg0, arg1 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRoomMembers", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRoomMembers indicates an expected call of ListRoomMembers.
+func (mr *MockWebSocketServiceMockRecorder) ListRoomMembers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoomMembers", reflect.TypeOf((*MockWebSocketService)(nil).ListRoomMembers), arg0, arg1)
+}
+
+// ListRooms mocks base method.
+func (m *MockWebSocketService) ListRooms(arg0 string, arg1 repository.RoomFilter) ([]*domain.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRooms", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRooms indicates an expected call of ListRooms.
+func (mr *MockWebSocketServiceMockRecorder) ListRooms(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRooms", reflect.TypeOf((*MockWebSocketService)(nil).ListRooms), arg0, arg1)
+}
+
+// MarkMessageAsRead mocks base method.
+func (m *MockWebSocketService) MarkMessageAsRead(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkMessageAsRead", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkMessageAsRead indicates an expected call of MarkMessageAsRead.
+func (mr *MockWebSocketServiceMockRecorder) MarkMessageAsRead(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkMessageAsRead", reflect.TypeOf((*MockWebSocketService)(nil).MarkMessageAsRead), arg0, arg1, arg2)
+}
+
+// MarkNotificationAsRead mocks base method.
+func (m *MockWebSocketService) MarkNotificationAsRead(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationAsRead", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkNotificationAsRead indicates an expected call of MarkNotificationAsRead.
+func (mr *MockWebSocketServiceMockRecorder) MarkNotificationAsRead(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationAsRead", reflect.TypeOf((*MockWebSocketService)(nil).MarkNotificationAsRead), arg0, arg1)
+}
+
+// MuteRoom mocks base method.
+func (m *MockWebSocketService) MuteRoom(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MuteRoom", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MuteRoom indicates an expected call of MuteRoom.
+func (mr *MockWebSocketServiceMockRecorder) MuteRoom(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MuteRoom", reflect.TypeOf((*MockWebSocketService)(nil).MuteRoom), arg0, arg1)
+}
+
+// MuteRoomMember mocks base method.
+func (m *MockWebSocketService) MuteRoomMember(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MuteRoomMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MuteRoomMember indicates an expected call of MuteRoomMember.
+func (mr *MockWebSocketServiceMockRecorder) MuteRoomMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MuteRoomMember", reflect.TypeOf((*MockWebSocketService)(nil).MuteRoomMember), arg0, arg1, arg2)
+}
+
+// PinMessage mocks base method.
+func (m *MockWebSocketService) PinMessage(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PinMessage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PinMessage indicates an expected call of PinMessage.
+func (mr *MockWebSocketServiceMockRecorder) PinMessage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PinMessage", reflect.TypeOf((*MockWebSocketService)(nil).PinMessage), arg0, arg1, arg2)
+}
+
+// PromoteToAdmin mocks base method.
+func (m *MockWebSocketService) PromoteToAdmin(arg0, arg1, arg2 string, arg3 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PromoteToAdmin", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// PromoteToAdmin indicates an expected call of PromoteToAdmin.
+func (mr *MockWebSocketServiceMockRecorder) PromoteToAdmin(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromoteToAdmin", reflect.TypeOf((*MockWebSocketService)(nil).PromoteToAdmin), arg0, arg1, arg2, arg3)
+}
+
+// RegisterNotifier mocks base method.
+func (m *MockWebSocketService) RegisterNotifier(arg0 string, arg1 usecase.Notifier) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RegisterNotifier", arg0, arg1)
+}
+
+// RegisterNotifier indicates an expected call of RegisterNotifier.
+func (mr *MockWebSocketServiceMockRecorder) RegisterNotifier(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterNotifier", reflect.TypeOf((*MockWebSocketService)(nil).RegisterNotifier), arg0, arg1)
+}
+
+// RemoveMember mocks base method.
+func (m *MockWebSocketService) RemoveMember(arg0, arg1, arg2 string, arg3 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockWebSocketServiceMockRecorder) RemoveMember(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockWebSocketService)(nil).RemoveMember), arg0, arg1, arg2, arg3)
+}
+
+// SearchAllMessages mocks base method.
+func (m *MockWebSocketService) SearchAllMessages(arg0, arg1 string, arg2, arg3 int) ([]*domain.MessageSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchAllMessages", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*domain.MessageSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchAllMessages indicates an expected call of SearchAllMessages.
+func (mr *MockWebSocketServiceMockRecorder) SearchAllMessages(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAllMessages", reflect.TypeOf((*MockWebSocketService)(nil).SearchAllMessages), arg0, arg1, arg2, arg3)
+}
+
+// SendAudioMessage mocks base method.
+func (m *MockWebSocketService) SendAudioMessage(arg0, arg1, arg2 string, arg3 int64, arg4 string, arg5 int) (*domain.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendAudioMessage", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(*domain.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendAudioMessage indicates an expected call of SendAudioMessage.
+func (mr *MockWebSocketServiceMockRecorder) SendAudioMessage(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAudioMessage", reflect.TypeOf((*MockWebSocketService)(nil).SendAudioMessage), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// SendDirectMessage mocks base method.
+func (m *MockWebSocketService) SendDirectMessage(arg0, arg1, arg2 string) (*domain.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDirectMessage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // SendDirectMessage indicates an expected call of SendDirectMessage.
 func (mr *MockWebSocketServiceMockRecorder) SendDirectMessage(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDirectMessage", reflect.TypeOf((*MockWebSocketService)(nil).SendDirectMessage), arg0, arg1, arg2)
 }
 
+// SendFileMessage mocks base method.
+func (m *MockWebSocketService) SendFileMessage(arg0, arg1, arg2, arg3 string, arg4 int64, arg5 string) (*domain.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendFileMessage", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(*domain.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendFileMessage indicates an expected call of SendFileMessage.
+func (mr *MockWebSocketServiceMockRecorder) SendFileMessage(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendFileMessage", reflect.TypeOf((*MockWebSocketService)(nil).SendFileMessage), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
 // SendGroupMessage mocks base method.
-func (m *MockWebSocketService) SendGroupMessage(arg0, arg1, arg2 string) error {
+func (m *MockWebSocketService) SendGroupMessage(arg0, arg1, arg2 string) (*domain.Message, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SendGroupMessage", arg0, arg1, arg2)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*domain.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // SendGroupMessage indicates an expected call of SendGroupMessage.
@@ -146,3 +604,216 @@ func (mr *MockWebSocketServiceMockRecorder) SendGroupMessage(arg0, arg1, arg2 in
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendGroupMessage", reflect.TypeOf((*MockWebSocketService)(nil).SendGroupMessage), arg0, arg1, arg2)
 }
+
+// SendImageMessage mocks base method.
+func (m *MockWebSocketService) SendImageMessage(arg0, arg1, arg2, arg3 string, arg4 int64, arg5 string) (*domain.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendImageMessage", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(*domain.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendImageMessage indicates an expected call of SendImageMessage.
+func (mr *MockWebSocketServiceMockRecorder) SendImageMessage(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendImageMessage", reflect.TypeOf((*MockWebSocketService)(nil).SendImageMessage), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// SendMentionNotification mocks base method.
+func (m *MockWebSocketService) SendMentionNotification(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMentionNotification", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMentionNotification indicates an expected call of SendMentionNotification.
+func (mr *MockWebSocketServiceMockRecorder) SendMentionNotification(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMentionNotification", reflect.TypeOf((*MockWebSocketService)(nil).SendMentionNotification), arg0, arg1, arg2)
+}
+
+// SendSystemNotification mocks base method.
+func (m *MockWebSocketService) SendSystemNotification(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSystemNotification", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendSystemNotification indicates an expected call of SendSystemNotification.
+func (mr *MockWebSocketServiceMockRecorder) SendSystemNotification(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSystemNotification", reflect.TypeOf((*MockWebSocketService)(nil).SendSystemNotification), arg0, arg1, arg2)
+}
+
+// SendTaskSummaryUpdate mocks base method.
+func (m *MockWebSocketService) SendTaskSummaryUpdate(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendTaskSummaryUpdate", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendTaskSummaryUpdate indicates an expected call of SendTaskSummaryUpdate.
+func (mr *MockWebSocketServiceMockRecorder) SendTaskSummaryUpdate(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTaskSummaryUpdate", reflect.TypeOf((*MockWebSocketService)(nil).SendTaskSummaryUpdate), arg0)
+}
+
+// SendTaskUpdateNotification mocks base method.
+func (m *MockWebSocketService) SendTaskUpdateNotification(arg0, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendTaskUpdateNotification", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendTaskUpdateNotification indicates an expected call of SendTaskUpdateNotification.
+func (mr *MockWebSocketServiceMockRecorder) SendTaskUpdateNotification(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTaskUpdateNotification", reflect.TypeOf((*MockWebSocketService)(nil).SendTaskUpdateNotification), arg0, arg1, arg2, arg3)
+}
+
+// SendTypingIndicator mocks base method.
+func (m *MockWebSocketService) SendTypingIndicator(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendTypingIndicator", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendTypingIndicator indicates an expected call of SendTypingIndicator.
+func (mr *MockWebSocketServiceMockRecorder) SendTypingIndicator(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTypingIndicator", reflect.TypeOf((*MockWebSocketService)(nil).SendTypingIndicator), arg0, arg1)
+}
+
+// SendVideoMessage mocks base method.
+func (m *MockWebSocketService) SendVideoMessage(arg0, arg1, arg2, arg3 string, arg4 int64, arg5 string, arg6 int) (*domain.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendVideoMessage", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	ret0, _ := ret[0].(*domain.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendVideoMessage indicates an expected call of SendVideoMessage.
+func (mr *MockWebSocketServiceMockRecorder) SendVideoMessage(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendVideoMessage", reflect.TypeOf((*MockWebSocketService)(nil).SendVideoMessage), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+// SetNotificationPreference mocks base method.
+func (m *MockWebSocketService) SetNotificationPreference(arg0, arg1, arg2 string, arg3 bool) (*domain.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNotificationPreference", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*domain.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetNotificationPreference indicates an expected call of SetNotificationPreference.
+func (mr *MockWebSocketServiceMockRecorder) SetNotificationPreference(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNotificationPreference", reflect.TypeOf((*MockWebSocketService)(nil).SetNotificationPreference), arg0, arg1, arg2, arg3)
+}
+
+// SetRoomSlowMode mocks base method.
+func (m *MockWebSocketService) SetRoomSlowMode(arg0 string, arg1 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRoomSlowMode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRoomSlowMode indicates an expected call of SetRoomSlowMode.
+func (mr *MockWebSocketServiceMockRecorder) SetRoomSlowMode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRoomSlowMode", reflect.TypeOf((*MockWebSocketService)(nil).SetRoomSlowMode), arg0, arg1)
+}
+
+// Shutdown mocks base method.
+func (m *MockWebSocketService) Shutdown(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shutdown", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Shutdown indicates an expected call of Shutdown.
+func (mr *MockWebSocketServiceMockRecorder) Shutdown(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockWebSocketService)(nil).Shutdown), arg0)
+}
+
+// UnarchiveRoom mocks base method.
+func (m *MockWebSocketService) UnarchiveRoom(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnarchiveRoom", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnarchiveRoom indicates an expected call of UnarchiveRoom.
+func (mr *MockWebSocketServiceMockRecorder) UnarchiveRoom(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnarchiveRoom", reflect.TypeOf((*MockWebSocketService)(nil).UnarchiveRoom), arg0, arg1)
+}
+
+// UnmuteRoom mocks base method.
+func (m *MockWebSocketService) UnmuteRoom(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmuteRoom", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmuteRoom indicates an expected call of UnmuteRoom.
+func (mr *MockWebSocketServiceMockRecorder) UnmuteRoom(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmuteRoom", reflect.TypeOf((*MockWebSocketService)(nil).UnmuteRoom), arg0, arg1)
+}
+
+// UnmuteRoomMember mocks base method.
+func (m *MockWebSocketService) UnmuteRoomMember(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmuteRoomMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmuteRoomMember indicates an expected call of UnmuteRoomMember.
+func (mr *MockWebSocketServiceMockRecorder) UnmuteRoomMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmuteRoomMember", reflect.TypeOf((*MockWebSocketService)(nil).UnmuteRoomMember), arg0, arg1, arg2)
+}
+
+// UnpinMessage mocks base method.
+func (m *MockWebSocketService) UnpinMessage(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnpinMessage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnpinMessage indicates an expected call of UnpinMessage.
+func (mr *MockWebSocketServiceMockRecorder) UnpinMessage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpinMessage", reflect.TypeOf((*MockWebSocketService)(nil).UnpinMessage), arg0, arg1, arg2)
+}
+
+// UpdateRoomInfo mocks base method.
+func (m *MockWebSocketService) UpdateRoomInfo(arg0, arg1, arg2, arg3, arg4 string, arg5 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRoomInfo", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRoomInfo indicates an expected call of UpdateRoomInfo.
+func (mr *MockWebSocketServiceMockRecorder) UpdateRoomInfo(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRoomInfo", reflect.TypeOf((*MockWebSocketService)(nil).UpdateRoomInfo), arg0, arg1, arg2, arg3, arg4, arg5)
+}
@@ -1,10 +1,12 @@
 package repositories
 
 import (
+	"strings"
 	"time"
 
 	"github.com/personal/task-management/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ChatRepository interface {
@@ -12,24 +14,59 @@ type ChatRepository interface {
 	CreateRoom(room *domain.Room) error
 	GetRoom(roomID string) (*domain.Room, error)
 	UpdateRoom(room *domain.Room) error
+	// DeleteRoom removes the room with its messages, message statuses,
+	// members and member mutes
 	DeleteRoom(roomID string) error
-	ListUserRooms(userID string) ([]*domain.Room, error)
+	// ListUserRooms returns the rooms the user belongs to that match the
+	// filter, most recently active first
+	ListUserRooms(userID string, filter RoomFilter) ([]*domain.Room, error)
 
 	// Message operations
 	CreateMessage(message *domain.Message) error
 	GetMessage(messageID string) (*domain.Message, error)
+	// GetMessagesByIDs returns the messages that exist among messageIDs, in
+	// no particular order
+	GetMessagesByIDs(messageIDs []string) ([]*domain.Message, error)
 	UpdateMessage(message *domain.Message) error
 	DeleteMessage(messageID string) error
-	GetRoomMessages(roomID string, limit, offset int) ([]*domain.Message, error)
+	GetRoomMessages(roomID string, limit, offset int, order domain.HistoryOrder) ([]*domain.Message, error)
+	// GetRoomMessagesBefore returns up to limit messages older than the
+	// (createdAt, messageID) cursor, newest first; messages sharing the
+	// cursor's timestamp are ordered by ID so none is skipped between pages
+	GetRoomMessagesBefore(roomID string, createdAt time.Time, messageID string, limit int) ([]*domain.Message, error)
+	GetRoomMessagesAfterSequence(roomID string, after int64, limit int) ([]*domain.Message, error)
+	GetLatestUserMessage(roomID, userID string) (*domain.Message, error)
+	// SearchMessages finds messages containing query, ignoring case, in the
+	// rooms the user currently belongs to, newest first
+	SearchMessages(userID, query string, limit, offset int) ([]*domain.MessageSearchResult, error)
 
 	// Room user operations
 	AddUserToRoom(roomID, userID string) error
 	RemoveUserFromRoom(roomID, userID string) error
 	GetRoomUsers(roomID string) ([]string, error)
+	// SetRoomUserRole sets a member's role, domain.RoomRoleAdmin or
+	// domain.RoomRoleMember. Users are added as members.
+	SetRoomUserRole(roomID, userID, role string) error
+	GetRoomAdmins(roomID string) ([]string, error)
+
+	// Room member mute operations
+	MuteRoomUser(mute *domain.RoomUserMute) error
+	UnmuteRoomUser(roomID, userID, mutedUserID string) error
+	GetRoomUserMuters(roomID, mutedUserID string) ([]string, error)
 
 	// Message status operations
 	UpdateMessageStatus(status *domain.MessageStatus) error
 	GetMessageStatus(messageID, userID string) (*domain.MessageStatus, error)
+	// GetMessageReadReceipts returns the read statuses of the messages,
+	// earliest first
+	GetMessageReadReceipts(messageIDs []string) ([]*domain.MessageStatus, error)
+	GetUnreadCounts(userID string) (map[string]int, error)
+	// GetRoomUnreadCounts counts, for each of the users, the messages in the
+	// room that they did not send and have not read
+	GetRoomUnreadCounts(roomID string, userIDs []string) (map[string]int, error)
+
+	// Statistics
+	GetChatStats(from, to time.Time, topRooms int) (*domain.ChatStats, error)
 
 	// Notification operations
 	CreateNotification(notification *domain.Notification) error
@@ -39,6 +76,22 @@ type ChatRepository interface {
 	GetUserNotifications(userID string, limit, offset int) ([]*domain.Notification, error)
 	MarkNotificationAsRead(notificationID string) error
 	GetUnreadNotificationCount(userID string) (int, error)
+	TrimReadNotifications(userID string, keep int, before time.Time) error
+
+	// Notification preference operations
+	GetNotificationPreferences(userID string) ([]*domain.NotificationPreference, error)
+	SaveNotificationPreference(preference *domain.NotificationPreference) error
+}
+
+// RoomFilter narrows a user's rooms. Unset fields match every room, and a
+// zero Limit returns all of them.
+type RoomFilter struct {
+	Type       string // domain.RoomTypeDirect or domain.RoomTypeGroup
+	IsArchived *bool
+	IsMuted    *bool
+	Search     string // Matches rooms whose name contains it, ignoring case
+	Offset     int
+	Limit      int
 }
 
 type chatRepository struct {
@@ -69,19 +122,73 @@ func (r *chatRepository) UpdateRoom(room *domain.Room) error {
 }
 
 func (r *chatRepository) DeleteRoom(roomID string) error {
-	return r.db.Delete(&domain.Room{}, "id = ?", roomID).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		messageIDs := tx.Model(&domain.Message{}).Select("id").Where("room_id = ?", roomID)
+		if err := tx.Where("message_id IN (?)", messageIDs).Delete(&domain.MessageStatus{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&domain.Message{}, "room_id = ?", roomID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&domain.RoomUser{}, "room_id = ?", roomID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&domain.RoomUserMute{}, "room_id = ?", roomID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&domain.RoomSequence{}, "room_id = ?", roomID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Room{}, "id = ?", roomID).Error
+	})
 }
 
-func (r *chatRepository) ListUserRooms(userID string) ([]*domain.Room, error) {
+func (r *chatRepository) ListUserRooms(userID string, filter RoomFilter) ([]*domain.Room, error) {
+	query := r.db.Where("id IN (SELECT room_id FROM room_users WHERE user_id = ?)", userID)
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.IsArchived != nil {
+		query = query.Where("is_archived = ?", *filter.IsArchived)
+	}
+	if filter.IsMuted != nil {
+		query = query.Where("is_muted = ?", *filter.IsMuted)
+	}
+	if filter.Search != "" {
+		query = query.Where(`LOWER(name) LIKE ? ESCAPE '\'`, "%"+EscapeLike(strings.ToLower(filter.Search))+"%")
+	}
+
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
 	var rooms []*domain.Room
-	if err := r.db.Where("id IN (SELECT room_id FROM room_users WHERE user_id = ?)", userID).Find(&rooms).Error; err != nil {
+	if err := query.Order("updated_at DESC").Find(&rooms).Error; err != nil {
 		return nil, err
 	}
 	return rooms, nil
 }
 
+// CreateMessage stores the message as the next in its room's sequence
 func (r *chatRepository) CreateMessage(message *domain.Message) error {
-	return r.db.Create(message).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// The upsert locks the room's counter row until commit, so concurrent
+		// senders are numbered one after another. A room without a counter
+		// yet starts after its latest message.
+		var sequence int64
+		if err := tx.Raw(`INSERT INTO room_sequences (room_id, last_sequence)
+			VALUES (?, (SELECT COALESCE(MAX(sequence), 0) + 1 FROM messages WHERE room_id = ?))
+			ON CONFLICT (room_id) DO UPDATE SET last_sequence = room_sequences.last_sequence + 1
+			RETURNING last_sequence`, message.RoomID, message.RoomID).
+			Scan(&sequence).Error; err != nil {
+			return err
+		}
+		message.Sequence = sequence
+		return tx.Create(message).Error
+	})
 }
 
 func (r *chatRepository) GetMessage(messageID string) (*domain.Message, error) {
@@ -95,6 +202,45 @@ func (r *chatRepository) GetMessage(messageID string) (*domain.Message, error) {
 	return &message, nil
 }
 
+func (r *chatRepository) GetMessagesByIDs(messageIDs []string) ([]*domain.Message, error) {
+	var messages []*domain.Message
+	if len(messageIDs) == 0 {
+		return messages, nil
+	}
+	err := r.db.Where("id IN ?", messageIDs).Find(&messages).Error
+	return messages, err
+}
+
+// GetLatestUserMessage returns the user's most recent message in the room, or
+// nil if they have not posted there
+func (r *chatRepository) GetLatestUserMessage(roomID, userID string) (*domain.Message, error) {
+	var message domain.Message
+	if err := r.db.Where("room_id = ? AND user_id = ?", roomID, userID).Order("created_at DESC").First(&message).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (r *chatRepository) SearchMessages(userID, query string, limit, offset int) ([]*domain.MessageSearchResult, error) {
+	// Joining room_users scopes the search to rooms the user still belongs to
+	var results []*domain.MessageSearchResult
+	if err := r.db.Table("messages").
+		Select("messages.*, COALESCE(rooms.name, '') AS room_name").
+		Joins("JOIN room_users ON room_users.room_id = messages.room_id AND room_users.user_id = ?", userID).
+		Joins("LEFT JOIN rooms ON rooms.id = messages.room_id").
+		Where(`LOWER(messages.content) LIKE ? ESCAPE '\'`, "%"+EscapeLike(strings.ToLower(query))+"%").
+		Order("messages.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
 func (r *chatRepository) UpdateMessage(message *domain.Message) error {
 	return r.db.Save(message).Error
 }
@@ -103,9 +249,34 @@ func (r *chatRepository) DeleteMessage(messageID string) error {
 	return r.db.Delete(&domain.Message{}, "id = ?", messageID).Error
 }
 
-func (r *chatRepository) GetRoomMessages(roomID string, limit, offset int) ([]*domain.Message, error) {
+// GetRoomMessages pages through a room's messages in the given order
+func (r *chatRepository) GetRoomMessages(roomID string, limit, offset int, order domain.HistoryOrder) ([]*domain.Message, error) {
+	orderBy := "created_at DESC"
+	if order == domain.HistoryOrderOldestFirst {
+		orderBy = "created_at ASC"
+	}
+
+	var messages []*domain.Message
+	if err := r.db.Where("room_id = ?", roomID).Order(orderBy).Limit(limit).Offset(offset).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *chatRepository) GetRoomMessagesBefore(roomID string, createdAt time.Time, messageID string, limit int) ([]*domain.Message, error) {
 	var messages []*domain.Message
-	if err := r.db.Where("room_id = ?", roomID).Order("created_at DESC").Limit(limit).Offset(offset).Find(&messages).Error; err != nil {
+	if err := r.db.Where("room_id = ? AND (created_at < ? OR (created_at = ? AND id < ?))", roomID, createdAt, createdAt, messageID).
+		Order("created_at DESC, id DESC").Limit(limit).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// GetRoomMessagesAfterSequence returns up to limit messages with a sequence
+// above after, oldest first
+func (r *chatRepository) GetRoomMessagesAfterSequence(roomID string, after int64, limit int) ([]*domain.Message, error) {
+	var messages []*domain.Message
+	if err := r.db.Where("room_id = ? AND sequence > ?", roomID, after).Order("sequence ASC").Limit(limit).Find(&messages).Error; err != nil {
 		return nil, err
 	}
 	return messages, nil
@@ -116,6 +287,7 @@ func (r *chatRepository) AddUserToRoom(roomID, userID string) error {
 		ID:        time.Now().Format("20060102150405") + "_" + time.Now().Format("000000000"),
 		RoomID:    roomID,
 		UserID:    userID,
+		Role:      domain.RoomRoleMember,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -134,10 +306,53 @@ func (r *chatRepository) GetRoomUsers(roomID string) ([]string, error) {
 	return userIDs, nil
 }
 
+func (r *chatRepository) SetRoomUserRole(roomID, userID, role string) error {
+	return r.db.Model(&domain.RoomUser{}).
+		Where("room_id = ? AND user_id = ?", roomID, userID).
+		Update("role", role).Error
+}
+
+func (r *chatRepository) GetRoomAdmins(roomID string) ([]string, error) {
+	var userIDs []string
+	err := r.db.Model(&domain.RoomUser{}).
+		Where("room_id = ? AND role = ?", roomID, domain.RoomRoleAdmin).
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+func (r *chatRepository) MuteRoomUser(mute *domain.RoomUserMute) error {
+	// Muting someone twice keeps the original mute
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(mute).Error
+}
+
+func (r *chatRepository) UnmuteRoomUser(roomID, userID, mutedUserID string) error {
+	return r.db.Delete(&domain.RoomUserMute{}, "room_id = ? AND user_id = ? AND muted_user_id = ?", roomID, userID, mutedUserID).Error
+}
+
+// GetRoomUserMuters returns the users who muted mutedUserID in the room
+func (r *chatRepository) GetRoomUserMuters(roomID, mutedUserID string) ([]string, error) {
+	var userIDs []string
+	if err := r.db.Model(&domain.RoomUserMute{}).Where("room_id = ? AND muted_user_id = ?", roomID, mutedUserID).Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
 func (r *chatRepository) UpdateMessageStatus(status *domain.MessageStatus) error {
 	return r.db.Save(status).Error
 }
 
+func (r *chatRepository) GetMessageReadReceipts(messageIDs []string) ([]*domain.MessageStatus, error) {
+	var statuses []*domain.MessageStatus
+	if len(messageIDs) == 0 {
+		return statuses, nil
+	}
+	if err := r.db.Where("message_id IN ? AND status = ?", messageIDs, domain.MessageStatusRead).Order("updated_at ASC").Find(&statuses).Error; err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
 func (r *chatRepository) GetMessageStatus(messageID, userID string) (*domain.MessageStatus, error) {
 	var status domain.MessageStatus
 	if err := r.db.First(&status, "message_id = ? AND user_id = ?", messageID, userID).Error; err != nil {
@@ -149,6 +364,112 @@ func (r *chatRepository) GetMessageStatus(messageID, userID string) (*domain.Mes
 	return &status, nil
 }
 
+// GetUnreadCounts counts, for every room the user belongs to, the messages
+// from other users that the user has not marked as read
+func (r *chatRepository) GetUnreadCounts(userID string) (map[string]int, error) {
+	var rows []struct {
+		RoomID string
+		Unread int
+	}
+	err := r.db.Table("room_users").
+		Select("room_users.room_id, COUNT(messages.id) AS unread").
+		Joins("LEFT JOIN messages ON messages.room_id = room_users.room_id AND messages.user_id <> ? AND NOT EXISTS ("+
+			"SELECT 1 FROM message_statuses WHERE message_statuses.message_id = messages.id "+
+			"AND message_statuses.user_id = ? AND message_statuses.status = ?)",
+			userID, userID, domain.MessageStatusRead).
+		Where("room_users.user_id = ?", userID).
+		Group("room_users.room_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.RoomID] = row.Unread
+	}
+	return counts, nil
+}
+
+// GetRoomUnreadCounts counts, for each of the users, the messages in the room
+// that they did not send and have not read
+func (r *chatRepository) GetRoomUnreadCounts(roomID string, userIDs []string) (map[string]int, error) {
+	counts := make(map[string]int, len(userIDs))
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		UserID string
+		Unread int
+	}
+	err := r.db.Table("room_users").
+		Select("room_users.user_id, COUNT(messages.id) AS unread").
+		Joins("LEFT JOIN messages ON messages.room_id = room_users.room_id AND messages.user_id <> room_users.user_id AND NOT EXISTS ("+
+			"SELECT 1 FROM message_statuses WHERE message_statuses.message_id = messages.id "+
+			"AND message_statuses.user_id = room_users.user_id AND message_statuses.status = ?)",
+			domain.MessageStatusRead).
+		Where("room_users.room_id = ? AND room_users.user_id IN ?", roomID, userIDs).
+		Group("room_users.user_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.UserID] = row.Unread
+	}
+	return counts, nil
+}
+
+// GetChatStats counts rooms and messages overall, and messages per day and per
+// room within [from, to). ActiveConnections is left for the caller to fill.
+func (r *chatRepository) GetChatStats(from, to time.Time, topRooms int) (*domain.ChatStats, error) {
+	stats := &domain.ChatStats{
+		MessagesPerDay: []domain.DailyMessageCount{},
+		BusiestRooms:   []domain.RoomActivity{},
+	}
+	if err := r.db.Model(&domain.Room{}).Count(&stats.TotalRooms).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(&domain.Message{}).Count(&stats.TotalMessages).Error; err != nil {
+		return nil, err
+	}
+
+	var days []struct {
+		Day   string
+		Count int64
+	}
+	if err := r.db.Model(&domain.Message{}).
+		Select("DATE(created_at) AS day, COUNT(*) AS count").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("DATE(created_at)").
+		Order("day ASC").
+		Scan(&days).Error; err != nil {
+		return nil, err
+	}
+	for _, day := range days {
+		// Drivers render dates either as YYYY-MM-DD or as a full timestamp
+		date := day.Day
+		if len(date) > len("2006-01-02") {
+			date = date[:len("2006-01-02")]
+		}
+		stats.MessagesPerDay = append(stats.MessagesPerDay, domain.DailyMessageCount{Date: date, Count: day.Count})
+	}
+
+	if err := r.db.Table("messages").
+		Select("messages.room_id, COALESCE(MAX(rooms.name), '') AS name, COUNT(*) AS message_count").
+		Joins("LEFT JOIN rooms ON rooms.id = messages.room_id").
+		Where("messages.created_at >= ? AND messages.created_at < ?", from, to).
+		Group("messages.room_id").
+		Order("message_count DESC, messages.room_id ASC").
+		Limit(topRooms).
+		Scan(&stats.BusiestRooms).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
 func (r *chatRepository) CreateNotification(notification *domain.Notification) error {
 	return r.db.Create(notification).Error
 }
@@ -191,3 +512,38 @@ func (r *chatRepository) GetUnreadNotificationCount(userID string) (int, error)
 	}
 	return int(count), nil
 }
+
+// TrimReadNotifications deletes the user's read notifications that are not
+// among their newest keep notifications or were created before the cutoff.
+// A keep of zero or a zero cutoff disables that limit.
+func (r *chatRepository) TrimReadNotifications(userID string, keep int, before time.Time) error {
+	var expired *gorm.DB
+	if keep > 0 {
+		newest := r.db.Model(&domain.Notification{}).Select("id").Where("user_id = ?", userID).Order("created_at DESC").Limit(keep)
+		expired = r.db.Where("id NOT IN (?)", newest)
+	}
+	if !before.IsZero() {
+		if expired == nil {
+			expired = r.db.Where("created_at < ?", before)
+		} else {
+			expired = expired.Or("created_at < ?", before)
+		}
+	}
+	if expired == nil {
+		return nil
+	}
+
+	return r.db.Where("user_id = ? AND is_read = ?", userID, true).Where(expired).Delete(&domain.Notification{}).Error
+}
+
+func (r *chatRepository) GetNotificationPreferences(userID string) ([]*domain.NotificationPreference, error) {
+	var preferences []*domain.NotificationPreference
+	if err := r.db.Where("user_id = ?", userID).Find(&preferences).Error; err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+func (r *chatRepository) SaveNotificationPreference(preference *domain.NotificationPreference) error {
+	return r.db.Save(preference).Error
+}
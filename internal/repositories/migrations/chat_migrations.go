@@ -9,11 +9,22 @@ func MigrateChatTables(db *gorm.DB) error {
 	if err := db.AutoMigrate(
 		&domain.Room{},
 		&domain.Message{},
+		&domain.RoomSequence{},
 		&domain.RoomUser{},
+		&domain.RoomUserMute{},
 		&domain.MessageStatus{},
+		&domain.NotificationPreference{},
 	); err != nil {
 		return err
 	}
 
+	// idx_messages_room_sequence replaces this index, which did not enforce
+	// unique sequences
+	if db.Migrator().HasIndex(&domain.Message{}, "idx_message_room_sequence") {
+		if err := db.Migrator().DropIndex(&domain.Message{}, "idx_message_room_sequence"); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
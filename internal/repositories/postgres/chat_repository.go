@@ -1,11 +1,14 @@
 package postgres
 
 import (
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/personal/task-management/internal/domain"
 	"github.com/personal/task-management/internal/repositories"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type chatRepository struct {
@@ -24,6 +27,9 @@ func (r *chatRepository) GetRoom(roomID string) (*domain.Room, error) {
 	var room domain.Room
 	err := r.db.First(&room, "id = ?", roomID).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &room, nil
@@ -33,27 +39,125 @@ func (r *chatRepository) UpdateRoom(room *domain.Room) error {
 	return r.db.Save(room).Error
 }
 
+// DeleteRoom removes the room together with its messages, their statuses,
+// its members and their mutes in one transaction
 func (r *chatRepository) DeleteRoom(roomID string) error {
-	return r.db.Delete(&domain.Room{}, "id = ?", roomID).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		messageIDs := tx.Model(&domain.Message{}).Select("id").Where("room_id = ?", roomID)
+		if err := tx.Where("message_id IN (?)", messageIDs).Delete(&domain.MessageStatus{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&domain.Message{}, "room_id = ?", roomID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&domain.RoomUser{}, "room_id = ?", roomID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&domain.RoomUserMute{}, "room_id = ?", roomID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&domain.RoomSequence{}, "room_id = ?", roomID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Room{}, "id = ?", roomID).Error
+	})
 }
 
-func (r *chatRepository) ListUserRooms(userID string) ([]*domain.Room, error) {
+func (r *chatRepository) ListUserRooms(userID string, filter repositories.RoomFilter) ([]*domain.Room, error) {
+	query := r.db.Joins("JOIN room_users ON room_users.room_id = rooms.id").
+		Where("room_users.user_id = ?", userID)
+	if filter.Type != "" {
+		query = query.Where("rooms.type = ?", filter.Type)
+	}
+	if filter.IsArchived != nil {
+		query = query.Where("rooms.is_archived = ?", *filter.IsArchived)
+	}
+	if filter.IsMuted != nil {
+		query = query.Where("rooms.is_muted = ?", *filter.IsMuted)
+	}
+	if filter.Search != "" {
+		query = query.Where(`LOWER(rooms.name) LIKE ? ESCAPE '\'`, "%"+repositories.EscapeLike(strings.ToLower(filter.Search))+"%")
+	}
+
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
 	var rooms []*domain.Room
-	err := r.db.Joins("JOIN room_users ON room_users.room_id = rooms.id").
-		Where("room_users.user_id = ?", userID).
-		Order("rooms.updated_at DESC").
-		Find(&rooms).Error
+	err := query.Order("rooms.updated_at DESC").Find(&rooms).Error
 	return rooms, err
 }
 
+// CreateMessage stores the message as the next in its room's sequence
 func (r *chatRepository) CreateMessage(message *domain.Message) error {
-	return r.db.Create(message).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// The upsert locks the room's counter row until commit, so concurrent
+		// senders are numbered one after another. A room without a counter
+		// yet starts after its latest message.
+		var sequence int64
+		if err := tx.Raw(`INSERT INTO room_sequences (room_id, last_sequence)
+			VALUES (?, (SELECT COALESCE(MAX(sequence), 0) + 1 FROM messages WHERE room_id = ?))
+			ON CONFLICT (room_id) DO UPDATE SET last_sequence = room_sequences.last_sequence + 1
+			RETURNING last_sequence`, message.RoomID, message.RoomID).
+			Scan(&sequence).Error; err != nil {
+			return err
+		}
+		message.Sequence = sequence
+		return tx.Create(message).Error
+	})
 }
 
 func (r *chatRepository) GetMessage(messageID string) (*domain.Message, error) {
 	var message domain.Message
 	err := r.db.First(&message, "id = ?", messageID).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (r *chatRepository) GetMessagesByIDs(messageIDs []string) ([]*domain.Message, error) {
+	var messages []*domain.Message
+	if len(messageIDs) == 0 {
+		return messages, nil
+	}
+	err := r.db.Where("id IN ?", messageIDs).Find(&messages).Error
+	return messages, err
+}
+
+// SearchMessages joins room_users so only rooms the user still belongs to are
+// searched; leaving a room deletes its room_users row
+func (r *chatRepository) SearchMessages(userID, query string, limit, offset int) ([]*domain.MessageSearchResult, error) {
+	var results []*domain.MessageSearchResult
+	err := r.db.Table("messages").
+		Select("messages.*, COALESCE(rooms.name, '') AS room_name").
+		Joins("JOIN room_users ON room_users.room_id = messages.room_id AND room_users.user_id = ?", userID).
+		Joins("LEFT JOIN rooms ON rooms.id = messages.room_id").
+		Where(`LOWER(messages.content) LIKE ? ESCAPE '\'`, "%"+repositories.EscapeLike(strings.ToLower(query))+"%").
+		Order("messages.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&results).Error
+	return results, err
+}
+
+// GetLatestUserMessage returns the user's most recent message in the room, or
+// nil if they have not posted there
+func (r *chatRepository) GetLatestUserMessage(roomID, userID string) (*domain.Message, error) {
+	var message domain.Message
+	err := r.db.Where("room_id = ? AND user_id = ?", roomID, userID).
+		Order("created_at DESC").
+		First(&message).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &message, nil
@@ -67,20 +171,48 @@ func (r *chatRepository) DeleteMessage(messageID string) error {
 	return r.db.Delete(&domain.Message{}, "id = ?", messageID).Error
 }
 
-func (r *chatRepository) GetRoomMessages(roomID string, limit, offset int) ([]*domain.Message, error) {
+// GetRoomMessages pages through a room's messages in the given order
+func (r *chatRepository) GetRoomMessages(roomID string, limit, offset int, order domain.HistoryOrder) ([]*domain.Message, error) {
+	orderBy := "created_at DESC"
+	if order == domain.HistoryOrderOldestFirst {
+		orderBy = "created_at ASC"
+	}
+
 	var messages []*domain.Message
 	err := r.db.Where("room_id = ?", roomID).
-		Order("created_at DESC").
+		Order(orderBy).
 		Limit(limit).
 		Offset(offset).
 		Find(&messages).Error
 	return messages, err
 }
 
+func (r *chatRepository) GetRoomMessagesBefore(roomID string, createdAt time.Time, messageID string, limit int) ([]*domain.Message, error) {
+	var messages []*domain.Message
+	err := r.db.Where("room_id = ? AND (created_at < ? OR (created_at = ? AND id < ?))", roomID, createdAt, createdAt, messageID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+// GetRoomMessagesAfterSequence returns up to limit messages with a sequence
+// above after, oldest first
+func (r *chatRepository) GetRoomMessagesAfterSequence(roomID string, after int64, limit int) ([]*domain.Message, error) {
+	var messages []*domain.Message
+	err := r.db.Where("room_id = ? AND sequence > ?", roomID, after).
+		Order("sequence ASC").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
 func (r *chatRepository) AddUserToRoom(roomID, userID string) error {
 	roomUser := &domain.RoomUser{
+		ID:        roomID + ":" + userID,
 		RoomID:    roomID,
 		UserID:    userID,
+		Role:      domain.RoomRoleMember,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -99,19 +231,171 @@ func (r *chatRepository) GetRoomUsers(roomID string) ([]string, error) {
 	return userIDs, err
 }
 
+func (r *chatRepository) SetRoomUserRole(roomID, userID, role string) error {
+	return r.db.Model(&domain.RoomUser{}).
+		Where("room_id = ? AND user_id = ?", roomID, userID).
+		Update("role", role).Error
+}
+
+func (r *chatRepository) GetRoomAdmins(roomID string) ([]string, error) {
+	var userIDs []string
+	err := r.db.Model(&domain.RoomUser{}).
+		Where("room_id = ? AND role = ?", roomID, domain.RoomRoleAdmin).
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+func (r *chatRepository) MuteRoomUser(mute *domain.RoomUserMute) error {
+	// Muting someone twice keeps the original mute
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(mute).Error
+}
+
+func (r *chatRepository) UnmuteRoomUser(roomID, userID, mutedUserID string) error {
+	return r.db.Delete(&domain.RoomUserMute{}, "room_id = ? AND user_id = ? AND muted_user_id = ?", roomID, userID, mutedUserID).Error
+}
+
+// GetRoomUserMuters returns the users who muted mutedUserID in the room
+func (r *chatRepository) GetRoomUserMuters(roomID, mutedUserID string) ([]string, error) {
+	var userIDs []string
+	err := r.db.Model(&domain.RoomUserMute{}).
+		Where("room_id = ? AND muted_user_id = ?", roomID, mutedUserID).
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
 func (r *chatRepository) UpdateMessageStatus(status *domain.MessageStatus) error {
 	return r.db.Save(status).Error
 }
 
+func (r *chatRepository) GetMessageReadReceipts(messageIDs []string) ([]*domain.MessageStatus, error) {
+	var statuses []*domain.MessageStatus
+	if len(messageIDs) == 0 {
+		return statuses, nil
+	}
+	err := r.db.Where("message_id IN ? AND status = ?", messageIDs, domain.MessageStatusRead).
+		Order("updated_at ASC").
+		Find(&statuses).Error
+	return statuses, err
+}
+
 func (r *chatRepository) GetMessageStatus(messageID, userID string) (*domain.MessageStatus, error) {
 	var status domain.MessageStatus
 	err := r.db.First(&status, "message_id = ? AND user_id = ?", messageID, userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 	return &status, nil
 }
 
+// GetUnreadCounts counts, for every room the user belongs to, the messages
+// from other users that the user has not marked as read
+func (r *chatRepository) GetUnreadCounts(userID string) (map[string]int, error) {
+	var rows []struct {
+		RoomID string
+		Unread int
+	}
+	err := r.db.Table("room_users").
+		Select("room_users.room_id, COUNT(messages.id) AS unread").
+		Joins("LEFT JOIN messages ON messages.room_id = room_users.room_id AND messages.user_id <> ? AND NOT EXISTS ("+
+			"SELECT 1 FROM message_statuses WHERE message_statuses.message_id = messages.id "+
+			"AND message_statuses.user_id = ? AND message_statuses.status = ?)",
+			userID, userID, domain.MessageStatusRead).
+		Where("room_users.user_id = ?", userID).
+		Group("room_users.room_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.RoomID] = row.Unread
+	}
+	return counts, nil
+}
+
+// GetRoomUnreadCounts counts, for each of the users, the messages in the room
+// that they did not send and have not read
+func (r *chatRepository) GetRoomUnreadCounts(roomID string, userIDs []string) (map[string]int, error) {
+	counts := make(map[string]int, len(userIDs))
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		UserID string
+		Unread int
+	}
+	err := r.db.Table("room_users").
+		Select("room_users.user_id, COUNT(messages.id) AS unread").
+		Joins("LEFT JOIN messages ON messages.room_id = room_users.room_id AND messages.user_id <> room_users.user_id AND NOT EXISTS ("+
+			"SELECT 1 FROM message_statuses WHERE message_statuses.message_id = messages.id "+
+			"AND message_statuses.user_id = room_users.user_id AND message_statuses.status = ?)",
+			domain.MessageStatusRead).
+		Where("room_users.room_id = ? AND room_users.user_id IN ?", roomID, userIDs).
+		Group("room_users.user_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.UserID] = row.Unread
+	}
+	return counts, nil
+}
+
+// GetChatStats counts rooms and messages overall, and messages per day and per
+// room within [from, to). ActiveConnections is left for the caller to fill.
+func (r *chatRepository) GetChatStats(from, to time.Time, topRooms int) (*domain.ChatStats, error) {
+	stats := &domain.ChatStats{
+		MessagesPerDay: []domain.DailyMessageCount{},
+		BusiestRooms:   []domain.RoomActivity{},
+	}
+	if err := r.db.Model(&domain.Room{}).Count(&stats.TotalRooms).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(&domain.Message{}).Count(&stats.TotalMessages).Error; err != nil {
+		return nil, err
+	}
+
+	var days []struct {
+		Day   string
+		Count int64
+	}
+	if err := r.db.Model(&domain.Message{}).
+		Select("DATE(created_at) AS day, COUNT(*) AS count").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("DATE(created_at)").
+		Order("day ASC").
+		Scan(&days).Error; err != nil {
+		return nil, err
+	}
+	for _, day := range days {
+		// Drivers render dates either as YYYY-MM-DD or as a full timestamp
+		date := day.Day
+		if len(date) > len("2006-01-02") {
+			date = date[:len("2006-01-02")]
+		}
+		stats.MessagesPerDay = append(stats.MessagesPerDay, domain.DailyMessageCount{Date: date, Count: day.Count})
+	}
+
+	if err := r.db.Table("messages").
+		Select("messages.room_id, COALESCE(MAX(rooms.name), '') AS name, COUNT(*) AS message_count").
+		Joins("LEFT JOIN rooms ON rooms.id = messages.room_id").
+		Where("messages.created_at >= ? AND messages.created_at < ?", from, to).
+		Group("messages.room_id").
+		Order("message_count DESC, messages.room_id ASC").
+		Limit(topRooms).
+		Scan(&stats.BusiestRooms).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
 func (r *chatRepository) CreateNotification(notification *domain.Notification) error {
 	return r.db.Create(notification).Error
 }
@@ -120,6 +404,9 @@ func (r *chatRepository) GetNotification(notificationID string) (*domain.Notific
 	var notification domain.Notification
 	err := r.db.First(&notification, "id = ?", notificationID).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &notification, nil
@@ -156,3 +443,36 @@ func (r *chatRepository) GetUnreadNotificationCount(userID string) (int, error)
 		Count(&count).Error
 	return int(count), err
 }
+
+// TrimReadNotifications deletes the user's read notifications that are not
+// among their newest keep notifications or were created before the cutoff.
+// A keep of zero or a zero cutoff disables that limit.
+func (r *chatRepository) TrimReadNotifications(userID string, keep int, before time.Time) error {
+	var expired *gorm.DB
+	if keep > 0 {
+		newest := r.db.Model(&domain.Notification{}).Select("id").Where("user_id = ?", userID).Order("created_at DESC").Limit(keep)
+		expired = r.db.Where("id NOT IN (?)", newest)
+	}
+	if !before.IsZero() {
+		if expired == nil {
+			expired = r.db.Where("created_at < ?", before)
+		} else {
+			expired = expired.Or("created_at < ?", before)
+		}
+	}
+	if expired == nil {
+		return nil
+	}
+
+	return r.db.Where("user_id = ? AND is_read = ?", userID, true).Where(expired).Delete(&domain.Notification{}).Error
+}
+
+func (r *chatRepository) GetNotificationPreferences(userID string) ([]*domain.NotificationPreference, error) {
+	var preferences []*domain.NotificationPreference
+	err := r.db.Where("user_id = ?", userID).Find(&preferences).Error
+	return preferences, err
+}
+
+func (r *chatRepository) SaveNotificationPreference(preference *domain.NotificationPreference) error {
+	return r.db.Save(preference).Error
+}
@@ -0,0 +1,641 @@
+package postgres
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/personal/task-management/internal/domain"
+	"github.com/personal/task-management/internal/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type ChatRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo repositories.ChatRepository
+}
+
+// SetupTest runs each test against a fresh in-memory database
+func (suite *ChatRepositoryTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	suite.Require().NoError(err)
+	// Every connection to :memory: opens a separate database, so keep to one
+	sqlDB, err := db.DB()
+	suite.Require().NoError(err)
+	sqlDB.SetMaxOpenConns(1)
+	suite.Require().NoError(db.AutoMigrate(&domain.Message{}, &domain.RoomSequence{}, &domain.RoomUser{}, &domain.MessageStatus{}, &domain.RoomUserMute{}, &domain.Notification{}))
+	suite.db = db
+	suite.repo = NewChatRepository(db)
+}
+
+func (suite *ChatRepositoryTestSuite) TearDownTest() {
+	sqlDB, err := suite.db.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+func (suite *ChatRepositoryTestSuite) addMembers(roomID string, userIDs ...string) {
+	for _, userID := range userIDs {
+		suite.Require().NoError(suite.db.Create(&domain.RoomUser{
+			ID:     roomID + ":" + userID,
+			RoomID: roomID,
+			UserID: userID,
+		}).Error)
+	}
+}
+
+func (suite *ChatRepositoryTestSuite) addMessage(id, roomID, userID string) {
+	suite.Require().NoError(suite.repo.CreateMessage(&domain.Message{
+		ID:        id,
+		RoomID:    roomID,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}))
+}
+
+func (suite *ChatRepositoryTestSuite) markRead(messageID, userID string) {
+	suite.Require().NoError(suite.repo.UpdateMessageStatus(&domain.MessageStatus{
+		ID:        fmt.Sprintf("%s:%s", messageID, userID),
+		MessageID: messageID,
+		UserID:    userID,
+		Status:    domain.MessageStatusRead,
+	}))
+}
+
+func (suite *ChatRepositoryTestSuite) TestGetUnreadCounts() {
+	suite.addMembers("room-1", "user-1", "user-2", "user-3")
+	suite.addMembers("room-2", "user-1", "user-2")
+	suite.addMembers("room-3", "user-1")
+	suite.addMembers("room-4", "user-2", "user-3")
+
+	suite.addMessage("message-1", "room-1", "user-2")
+	suite.addMessage("message-2", "room-1", "user-3")
+	suite.addMessage("message-3", "room-1", "user-1")
+	suite.addMessage("message-4", "room-2", "user-2")
+	suite.addMessage("message-5", "room-2", "user-2")
+	suite.addMessage("message-6", "room-4", "user-2")
+	suite.markRead("message-1", "user-1")
+	suite.markRead("message-4", "user-3")
+
+	counts, err := suite.repo.GetUnreadCounts("user-1")
+	suite.NoError(err)
+	suite.Equal(map[string]int{"room-1": 1, "room-2": 2, "room-3": 0}, counts)
+
+	counts, err = suite.repo.GetUnreadCounts("user-3")
+	suite.NoError(err)
+	suite.Equal(map[string]int{"room-1": 2, "room-4": 1}, counts)
+}
+
+func (suite *ChatRepositoryTestSuite) TestGetRoomUnreadCounts() {
+	suite.addMembers("room-1", "user-1", "user-2", "user-3")
+	suite.addMembers("room-2", "user-1")
+
+	suite.addMessage("message-1", "room-1", "user-2")
+	suite.addMessage("message-2", "room-1", "user-3")
+	suite.addMessage("message-3", "room-2", "user-2")
+	suite.markRead("message-1", "user-1")
+
+	counts, err := suite.repo.GetRoomUnreadCounts("room-1", []string{"user-1", "user-2", "user-3"})
+	suite.NoError(err)
+	suite.Equal(map[string]int{"user-1": 1, "user-2": 1, "user-3": 1}, counts)
+
+	// Only the requested members are counted
+	counts, err = suite.repo.GetRoomUnreadCounts("room-1", []string{"user-1"})
+	suite.NoError(err)
+	suite.Equal(map[string]int{"user-1": 1}, counts)
+}
+
+func (suite *ChatRepositoryTestSuite) TestGetUnreadCountsWithoutRooms() {
+	counts, err := suite.repo.GetUnreadCounts("user-1")
+	suite.NoError(err)
+	suite.Empty(counts)
+}
+
+func (suite *ChatRepositoryTestSuite) TestGetLatestUserMessage() {
+	now := time.Now()
+	for id, age := range map[string]time.Duration{"m-old": 3 * time.Minute, "m-new": time.Minute, "m-middle": 2 * time.Minute} {
+		suite.Require().NoError(suite.repo.CreateMessage(&domain.Message{
+			ID:        id,
+			RoomID:    "room-1",
+			UserID:    "user-1",
+			CreatedAt: now.Add(-age),
+		}))
+	}
+	suite.addMessage("m-other-user", "room-1", "user-2")
+	suite.addMessage("m-other-room", "room-2", "user-1")
+
+	latest, err := suite.repo.GetLatestUserMessage("room-1", "user-1")
+	suite.NoError(err)
+	suite.Require().NotNil(latest)
+	suite.Equal("m-new", latest.ID)
+
+	none, err := suite.repo.GetLatestUserMessage("room-1", "user-3")
+	suite.NoError(err)
+	suite.Nil(none)
+}
+
+func (suite *ChatRepositoryTestSuite) TestRoomUserMutes() {
+	mute := func(id, roomID, userID, mutedUserID string) {
+		suite.Require().NoError(suite.repo.MuteRoomUser(&domain.RoomUserMute{
+			ID:          id,
+			RoomID:      roomID,
+			UserID:      userID,
+			MutedUserID: mutedUserID,
+		}))
+	}
+	mute("mute-1", "room-1", "user-1", "user-2")
+	mute("mute-2", "room-1", "user-1", "user-2") // already muted
+	mute("mute-3", "room-1", "user-3", "user-2")
+	mute("mute-4", "room-2", "user-4", "user-2")
+
+	muters, err := suite.repo.GetRoomUserMuters("room-1", "user-2")
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"user-1", "user-3"}, muters)
+
+	suite.NoError(suite.repo.UnmuteRoomUser("room-1", "user-1", "user-2"))
+	muters, err = suite.repo.GetRoomUserMuters("room-1", "user-2")
+	suite.NoError(err)
+	suite.Equal([]string{"user-3"}, muters)
+}
+
+func (suite *ChatRepositoryTestSuite) TestGetMessagesByIDs() {
+	suite.addMessage("message-1", "room-1", "user-1")
+	suite.addMessage("message-2", "room-1", "user-1")
+	suite.addMessage("message-3", "room-2", "user-1")
+
+	messages, err := suite.repo.GetMessagesByIDs([]string{"message-3", "missing", "message-1"})
+	suite.NoError(err)
+	ids := make([]string, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+	suite.ElementsMatch([]string{"message-1", "message-3"}, ids)
+
+	messages, err = suite.repo.GetMessagesByIDs(nil)
+	suite.NoError(err)
+	suite.Empty(messages)
+}
+
+func (suite *ChatRepositoryTestSuite) TestAddUserToRoom() {
+	suite.NoError(suite.repo.AddUserToRoom("room-1", "user-1"))
+	suite.NoError(suite.repo.AddUserToRoom("room-1", "user-2"))
+	suite.NoError(suite.repo.AddUserToRoom("room-2", "user-1"))
+	// Each user is in a room at most once
+	suite.Error(suite.repo.AddUserToRoom("room-1", "user-1"))
+
+	users, err := suite.repo.GetRoomUsers("room-1")
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"user-1", "user-2"}, users)
+}
+
+func (suite *ChatRepositoryTestSuite) TestRoomUserRoles() {
+	suite.Require().NoError(suite.repo.AddUserToRoom("room-1", "user-1"))
+	suite.Require().NoError(suite.repo.AddUserToRoom("room-1", "user-2"))
+	suite.Require().NoError(suite.repo.AddUserToRoom("room-2", "user-2"))
+
+	admins, err := suite.repo.GetRoomAdmins("room-1")
+	suite.NoError(err)
+	suite.Empty(admins)
+
+	suite.NoError(suite.repo.SetRoomUserRole("room-1", "user-2", domain.RoomRoleAdmin))
+	admins, err = suite.repo.GetRoomAdmins("room-1")
+	suite.NoError(err)
+	suite.Equal([]string{"user-2"}, admins)
+	admins, err = suite.repo.GetRoomAdmins("room-2")
+	suite.NoError(err)
+	suite.Empty(admins)
+
+	suite.NoError(suite.repo.SetRoomUserRole("room-1", "user-2", domain.RoomRoleMember))
+	admins, err = suite.repo.GetRoomAdmins("room-1")
+	suite.NoError(err)
+	suite.Empty(admins)
+}
+
+func (suite *ChatRepositoryTestSuite) TestDeleteRoomCascades() {
+	suite.Require().NoError(suite.db.AutoMigrate(&domain.Room{}))
+	for _, id := range []string{"room-1", "room-2"} {
+		suite.Require().NoError(suite.db.Table("rooms").Create(map[string]any{"id": id}).Error)
+		suite.addMembers(id, "user-1", "user-2")
+		suite.addMessage("message-"+id, id, "user-1")
+		suite.markRead("message-"+id, "user-2")
+		suite.Require().NoError(suite.repo.MuteRoomUser(&domain.RoomUserMute{
+			ID:          "mute-" + id,
+			RoomID:      id,
+			UserID:      "user-2",
+			MutedUserID: "user-1",
+		}))
+	}
+
+	suite.Require().NoError(suite.repo.DeleteRoom("room-1"))
+
+	count := func(model any, query string, args ...any) int64 {
+		var n int64
+		suite.Require().NoError(suite.db.Model(model).Where(query, args...).Count(&n).Error)
+		return n
+	}
+	for _, tt := range []struct {
+		model any
+		query string
+		arg   string
+	}{
+		{&domain.Room{}, "id = ?", "room"},
+		{&domain.RoomUser{}, "room_id = ?", "room"},
+		{&domain.Message{}, "room_id = ?", "room"},
+		{&domain.MessageStatus{}, "message_id = ?", "message-room"},
+		{&domain.RoomUserMute{}, "room_id = ?", "room"},
+		{&domain.RoomSequence{}, "room_id = ?", "room"},
+	} {
+		suite.Zero(count(tt.model, tt.query, tt.arg+"-1"), "%T", tt.model)
+		suite.NotZero(count(tt.model, tt.query, tt.arg+"-2"), "%T", tt.model)
+	}
+}
+
+func (suite *ChatRepositoryTestSuite) seedNotifications(now time.Time, read ...bool) {
+	for i, isRead := range read {
+		suite.Require().NoError(suite.repo.CreateNotification(&domain.Notification{
+			ID:        fmt.Sprintf("notification-%d", i+1),
+			UserID:    "user-1",
+			IsRead:    isRead,
+			CreatedAt: now.Add(time.Duration(i-len(read)) * time.Hour),
+		}))
+	}
+}
+
+func (suite *ChatRepositoryTestSuite) notificationIDs() []string {
+	var ids []string
+	suite.Require().NoError(suite.db.Model(&domain.Notification{}).Order("created_at ASC").Pluck("id", &ids).Error)
+	return ids
+}
+
+func (suite *ChatRepositoryTestSuite) TestTrimReadNotificationsKeepsNewest() {
+	// Oldest first: read, unread, read, read, unread
+	suite.seedNotifications(time.Now(), true, false, true, true, false)
+	suite.Require().NoError(suite.repo.CreateNotification(&domain.Notification{
+		ID: "other-user", UserID: "user-2", IsRead: true, CreatedAt: time.Now().Add(-24 * time.Hour),
+	}))
+
+	suite.NoError(suite.repo.TrimReadNotifications("user-1", 2, time.Time{}))
+	suite.Equal([]string{"other-user", "notification-2", "notification-4", "notification-5"}, suite.notificationIDs())
+}
+
+func (suite *ChatRepositoryTestSuite) TestTrimReadNotificationsByAge() {
+	now := time.Now()
+	suite.seedNotifications(now, true, false, true, true)
+
+	suite.NoError(suite.repo.TrimReadNotifications("user-1", 0, now.Add(-150*time.Minute)))
+	suite.Equal([]string{"notification-2", "notification-3", "notification-4"}, suite.notificationIDs())
+}
+
+func (suite *ChatRepositoryTestSuite) TestTrimReadNotificationsByCountOrAge() {
+	now := time.Now()
+	suite.seedNotifications(now, true, true, true, true)
+
+	suite.NoError(suite.repo.TrimReadNotifications("user-1", 3, now.Add(-90*time.Minute)))
+	suite.Equal([]string{"notification-4"}, suite.notificationIDs())
+}
+
+func (suite *ChatRepositoryTestSuite) TestCreateMessageNumbersEachRoomFromOne() {
+	suite.addMessage("message-1", "room-1", "user-1")
+	suite.addMessage("message-2", "room-2", "user-1")
+	suite.addMessage("message-3", "room-1", "user-2")
+
+	for id, want := range map[string]int64{"message-1": 1, "message-2": 1, "message-3": 2} {
+		message, err := suite.repo.GetMessage(id)
+		suite.NoError(err)
+		suite.Require().NotNil(message)
+		suite.Equal(want, message.Sequence, id)
+	}
+}
+
+func (suite *ChatRepositoryTestSuite) TestCreateMessageNumbersConcurrentSendsUniquely() {
+	const senders = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, senders)
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- suite.repo.CreateMessage(&domain.Message{
+				ID:        fmt.Sprintf("message-%d", i),
+				RoomID:    "room-1",
+				UserID:    "user-1",
+				CreatedAt: time.Now(),
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		suite.NoError(err)
+	}
+
+	messages, err := suite.repo.GetRoomMessagesAfterSequence("room-1", 0, senders)
+	suite.NoError(err)
+	suite.Require().Len(messages, senders)
+	for i, message := range messages {
+		suite.Equal(int64(i+1), message.Sequence)
+	}
+}
+
+func (suite *ChatRepositoryTestSuite) TestRoomSequencesAreUnique() {
+	suite.addMessage("message-1", "room-1", "user-1")
+
+	err := suite.db.Create(&domain.Message{ID: "message-2", RoomID: "room-1", Sequence: 1}).Error
+	suite.Error(err)
+}
+
+func (suite *ChatRepositoryTestSuite) TestCreateMessageContinuesAfterExistingMessages() {
+	// Messages stored before the room had a counter
+	suite.Require().NoError(suite.db.Create(&domain.Message{ID: "message-1", RoomID: "room-1", Sequence: 1}).Error)
+	suite.Require().NoError(suite.db.Create(&domain.Message{ID: "message-2", RoomID: "room-1", Sequence: 2}).Error)
+
+	suite.addMessage("message-3", "room-1", "user-1")
+	message, err := suite.repo.GetMessage("message-3")
+	suite.NoError(err)
+	suite.Require().NotNil(message)
+	suite.Equal(int64(3), message.Sequence)
+}
+
+func (suite *ChatRepositoryTestSuite) TestGetRoomMessagesAfterSequence() {
+	for i := 1; i <= 5; i++ {
+		suite.addMessage(fmt.Sprintf("message-%d", i), "room-1", "user-1")
+	}
+	suite.addMessage("other", "room-2", "user-1")
+
+	messages, err := suite.repo.GetRoomMessagesAfterSequence("room-1", 2, 10)
+	suite.NoError(err)
+	suite.Equal([]string{"message-3", "message-4", "message-5"}, messageIDs(messages))
+
+	messages, err = suite.repo.GetRoomMessagesAfterSequence("room-1", 2, 2)
+	suite.NoError(err)
+	suite.Equal([]string{"message-3", "message-4"}, messageIDs(messages))
+}
+
+func (suite *ChatRepositoryTestSuite) TestGetRoomMessagesOrder() {
+	start := time.Now().Add(-time.Hour)
+	for i := 1; i <= 4; i++ {
+		suite.Require().NoError(suite.repo.CreateMessage(&domain.Message{
+			ID:        fmt.Sprintf("message-%d", i),
+			RoomID:    "room-1",
+			UserID:    "user-1",
+			CreatedAt: start.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	messages, err := suite.repo.GetRoomMessages("room-1", 2, 1, domain.HistoryOrderNewestFirst)
+	suite.NoError(err)
+	suite.Equal([]string{"message-3", "message-2"}, messageIDs(messages))
+
+	messages, err = suite.repo.GetRoomMessages("room-1", 2, 1, domain.HistoryOrderOldestFirst)
+	suite.NoError(err)
+	suite.Equal([]string{"message-2", "message-3"}, messageIDs(messages))
+
+	// An order that skipped ParseHistoryOrder never reaches the query
+	messages, err = suite.repo.GetRoomMessages("room-1", 2, 1, domain.HistoryOrder("asc; DROP TABLE messages; --"))
+	suite.NoError(err)
+	suite.Equal([]string{"message-3", "message-2"}, messageIDs(messages))
+}
+
+func (suite *ChatRepositoryTestSuite) TestGetRoomMessagesBeforeBreaksTimestampTies() {
+	sent := time.Now().Add(-time.Hour)
+	for _, id := range []string{"message-a", "message-b", "message-c"} {
+		suite.Require().NoError(suite.repo.CreateMessage(&domain.Message{ID: id, RoomID: "room-1", UserID: "user-1", CreatedAt: sent}))
+	}
+	suite.Require().NoError(suite.repo.CreateMessage(&domain.Message{ID: "message-0", RoomID: "room-1", UserID: "user-1", CreatedAt: sent.Add(-time.Minute)}))
+
+	messages, err := suite.repo.GetRoomMessagesBefore("room-1", sent, "message-b", 10)
+	suite.NoError(err)
+	suite.Equal([]string{"message-a", "message-0"}, messageIDs(messages))
+}
+
+func (suite *ChatRepositoryTestSuite) TestGetMessageStatus() {
+	status, err := suite.repo.GetMessageStatus("message-1", "user-2")
+	suite.NoError(err)
+	suite.Nil(status)
+
+	suite.Require().NoError(suite.repo.UpdateMessageStatus(&domain.MessageStatus{
+		ID:        "status-1",
+		MessageID: "message-1",
+		UserID:    "user-2",
+		Status:    domain.MessageStatusDelivered,
+	}))
+	status, err = suite.repo.GetMessageStatus("message-1", "user-2")
+	suite.NoError(err)
+	suite.Require().NotNil(status)
+	suite.Equal(domain.MessageStatusDelivered, status.Status)
+}
+
+func (suite *ChatRepositoryTestSuite) TestGetMessageReadReceipts() {
+	readAt := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for _, status := range []*domain.MessageStatus{
+		{ID: "status-1", MessageID: "message-1", UserID: "user-3", Status: domain.MessageStatusRead, UpdatedAt: readAt.Add(time.Minute)},
+		{ID: "status-2", MessageID: "message-1", UserID: "user-2", Status: domain.MessageStatusRead, UpdatedAt: readAt},
+		{ID: "status-3", MessageID: "message-1", UserID: "user-4", Status: domain.MessageStatusDelivered, UpdatedAt: readAt},
+		{ID: "status-4", MessageID: "message-2", UserID: "user-2", Status: domain.MessageStatusRead, UpdatedAt: readAt},
+	} {
+		// Create keeps the given UpdatedAt, where Save would overwrite it
+		suite.Require().NoError(suite.db.Create(status).Error)
+	}
+
+	statuses, err := suite.repo.GetMessageReadReceipts([]string{"message-1"})
+	suite.NoError(err)
+	suite.Require().Len(statuses, 2)
+	suite.Equal("user-2", statuses[0].UserID)
+	suite.Equal("user-3", statuses[1].UserID)
+
+	statuses, err = suite.repo.GetMessageReadReceipts([]string{"message-1", "message-2"})
+	suite.NoError(err)
+	suite.Len(statuses, 3)
+}
+
+func (suite *ChatRepositoryTestSuite) TestSearchMessagesAcrossMemberRooms() {
+	suite.Require().NoError(suite.db.AutoMigrate(&domain.Room{}))
+	for id, name := range map[string]string{"room-1": "general", "room-2": "", "room-3": "secret"} {
+		suite.Require().NoError(suite.db.Table("rooms").Create(map[string]any{"id": id, "name": name}).Error)
+	}
+	suite.addMembers("room-1", "user-1", "user-2")
+	suite.addMembers("room-2", "user-1", "user-2")
+	suite.addMembers("room-3", "user-2", "user-1")
+	// user-1 left room-3, so its messages are out of reach
+	suite.Require().NoError(suite.repo.RemoveUserFromRoom("room-3", "user-1"))
+
+	start := time.Now().Add(-time.Hour)
+	for i, m := range []struct{ id, roomID, content string }{
+		{"message-1", "room-1", "Deploy at noon"},
+		{"message-2", "room-2", "the deploy went fine"},
+		{"message-3", "room-3", "deploy credentials"},
+		{"message-4", "room-1", "lunch?"},
+		{"message-5", "room-1", "100% deployed_ok"},
+	} {
+		suite.Require().NoError(suite.repo.CreateMessage(&domain.Message{
+			ID:        m.id,
+			RoomID:    m.roomID,
+			UserID:    "user-2",
+			Content:   m.content,
+			CreatedAt: start.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	results, err := suite.repo.SearchMessages("user-1", "DEPLOY", 10, 0)
+	suite.NoError(err)
+	suite.Require().Len(results, 3)
+	suite.Equal("message-5", results[0].ID)
+	suite.Equal("message-2", results[1].ID)
+	suite.Equal("room-2", results[1].RoomID)
+	suite.Equal("", results[1].RoomName)
+	suite.Equal("message-1", results[2].ID)
+	suite.Equal("general", results[2].RoomName)
+	suite.Equal("Deploy at noon", results[2].Content)
+
+	results, err = suite.repo.SearchMessages("user-1", "deploy", 1, 1)
+	suite.NoError(err)
+	suite.Require().Len(results, 1)
+	suite.Equal("message-2", results[0].ID)
+
+	// Wildcards are matched literally
+	results, err = suite.repo.SearchMessages("user-1", "0% deployed_", 10, 0)
+	suite.NoError(err)
+	suite.Require().Len(results, 1)
+	suite.Equal("message-5", results[0].ID)
+	results, err = suite.repo.SearchMessages("user-1", "%", 10, 0)
+	suite.NoError(err)
+	suite.Len(results, 1)
+}
+
+func messageIDs(messages []*domain.Message) []string {
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+	return ids
+}
+
+func (suite *ChatRepositoryTestSuite) TestGetChatStats() {
+	suite.Require().NoError(suite.db.AutoMigrate(&domain.Room{}))
+	for id, name := range map[string]string{"room-1": "general", "room-2": "random", "room-3": ""} {
+		suite.Require().NoError(suite.db.Table("rooms").Create(map[string]any{"id": id, "name": name}).Error)
+	}
+
+	day := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for i, m := range []struct {
+		room string
+		at   time.Time
+	}{
+		{"room-1", day},
+		{"room-1", day.Add(time.Hour)},
+		{"room-2", day.Add(time.Hour)},
+		{"room-1", day.AddDate(0, 0, 1)},
+		// Outside the range below
+		{"room-2", day.AddDate(0, 0, -5)},
+		{"room-2", day.AddDate(0, 0, -5)},
+		{"room-2", day.AddDate(0, 0, -5)},
+	} {
+		suite.Require().NoError(suite.repo.CreateMessage(&domain.Message{
+			ID:        fmt.Sprintf("message-%d", i+1),
+			RoomID:    m.room,
+			UserID:    "user-1",
+			CreatedAt: m.at,
+		}))
+	}
+
+	from := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	stats, err := suite.repo.GetChatStats(from, from.AddDate(0, 0, 7), 5)
+	suite.NoError(err)
+	suite.Equal(int64(3), stats.TotalRooms)
+	suite.Equal(int64(7), stats.TotalMessages)
+	suite.Equal([]domain.DailyMessageCount{
+		{Date: "2026-03-10", Count: 3},
+		{Date: "2026-03-11", Count: 1},
+	}, stats.MessagesPerDay)
+	suite.Equal([]domain.RoomActivity{
+		{RoomID: "room-1", Name: "general", MessageCount: 3},
+		{RoomID: "room-2", Name: "random", MessageCount: 1},
+	}, stats.BusiestRooms)
+
+	stats, err = suite.repo.GetChatStats(from, from.AddDate(0, 0, 7), 1)
+	suite.NoError(err)
+	suite.Len(stats.BusiestRooms, 1)
+}
+
+// seedFilterRooms adds user-1 to rooms of each type, archived and muted
+// state, with names to search, most recently updated first by ID
+func (suite *ChatRepositoryTestSuite) seedFilterRooms() {
+	suite.Require().NoError(suite.db.AutoMigrate(&domain.Room{}))
+	now := time.Now()
+	for i, room := range []map[string]any{
+		{"id": "room-1", "name": "Design Team", "type": domain.RoomTypeGroup},
+		{"id": "room-2", "name": "Ops 100%", "type": domain.RoomTypeGroup, "is_archived": true},
+		{"id": "room-3", "name": "design review", "type": domain.RoomTypeGroup, "is_muted": true},
+		{"id": "room-4", "name": "", "type": domain.RoomTypeDirect, "is_archived": true, "is_muted": true},
+		{"id": "room-5", "name": "Design Team", "type": domain.RoomTypeGroup},
+	} {
+		// Rows written from a domain.Room always have both flags
+		for _, flag := range []string{"is_archived", "is_muted"} {
+			if _, set := room[flag]; !set {
+				room[flag] = false
+			}
+		}
+		room["updated_at"] = now.Add(-time.Duration(i) * time.Minute)
+		suite.Require().NoError(suite.db.Table("rooms").Create(room).Error)
+	}
+	suite.addMembers("room-1", "user-1")
+	suite.addMembers("room-2", "user-1")
+	suite.addMembers("room-3", "user-1")
+	suite.addMembers("room-4", "user-1", "user-2")
+	// Only user-2 is in room-5
+	suite.addMembers("room-5", "user-2")
+}
+
+func (suite *ChatRepositoryTestSuite) listRoomIDs(filter repositories.RoomFilter) []string {
+	rooms, err := suite.repo.ListUserRooms("user-1", filter)
+	suite.Require().NoError(err)
+	ids := make([]string, 0, len(rooms))
+	for _, room := range rooms {
+		ids = append(ids, room.ID)
+	}
+	return ids
+}
+
+func (suite *ChatRepositoryTestSuite) TestListUserRoomsFilters() {
+	suite.seedFilterRooms()
+	yes, no := true, false
+
+	tests := map[string]struct {
+		filter repositories.RoomFilter
+		want   []string
+	}{
+		"no filter":    {repositories.RoomFilter{}, []string{"room-1", "room-2", "room-3", "room-4"}},
+		"group type":   {repositories.RoomFilter{Type: domain.RoomTypeGroup}, []string{"room-1", "room-2", "room-3"}},
+		"direct type":  {repositories.RoomFilter{Type: domain.RoomTypeDirect}, []string{"room-4"}},
+		"archived":     {repositories.RoomFilter{IsArchived: &yes}, []string{"room-2", "room-4"}},
+		"not archived": {repositories.RoomFilter{IsArchived: &no}, []string{"room-1", "room-3"}},
+		"muted":        {repositories.RoomFilter{IsMuted: &yes}, []string{"room-3", "room-4"}},
+		"not muted":    {repositories.RoomFilter{IsMuted: &no}, []string{"room-1", "room-2"}},
+		"search ignores case": {
+			repositories.RoomFilter{Search: "DESIGN"}, []string{"room-1", "room-3"},
+		},
+		"search wildcards are literal": {
+			repositories.RoomFilter{Search: "%"}, []string{"room-2"},
+		},
+		"combined": {
+			repositories.RoomFilter{Type: domain.RoomTypeGroup, IsArchived: &no, IsMuted: &no, Search: "team"},
+			[]string{"room-1"},
+		},
+		"limit and offset": {repositories.RoomFilter{Limit: 2, Offset: 1}, []string{"room-2", "room-3"}},
+	}
+
+	for name, tt := range tests {
+		suite.Run(name, func() {
+			suite.Equal(tt.want, suite.listRoomIDs(tt.filter))
+		})
+	}
+}
+
+func TestChatRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ChatRepositoryTestSuite))
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain/user"
+	repository "github.com/personal/task-management/internal/repositories"
+	"gorm.io/gorm"
+)
+
+type PostgresSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresSessionRepository(db *gorm.DB) repository.SessionRepository {
+	return &PostgresSessionRepository{db: db}
+}
+
+func (r *PostgresSessionRepository) Create(ctx context.Context, session *user.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *PostgresSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*user.Session, error) {
+	var session user.Session
+	if err := r.db.WithContext(ctx).First(&session, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *PostgresSessionRepository) ListActive(ctx context.Context, userID uuid.UUID, now time.Time) ([]*user.Session, error) {
+	sessions := []*user.Session{}
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND expires_at > ?", userID, now).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *PostgresSessionRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&user.Session{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", at).Error
+}
+
+func (r *PostgresSessionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&user.Session{}, "id = ?", id).Error
+}
+
+func (r *PostgresSessionRepository) DeleteByUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&user.Session{}, "user_id = ?", userID).Error
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain/user"
+	repository "github.com/personal/task-management/internal/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type SessionRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo repository.SessionRepository
+}
+
+func (suite *SessionRepositoryTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	suite.Require().NoError(err)
+	sqlDB, err := db.DB()
+	suite.Require().NoError(err)
+	sqlDB.SetMaxOpenConns(1)
+	suite.Require().NoError(db.AutoMigrate(&user.Session{}))
+	suite.db = db
+	suite.repo = NewPostgresSessionRepository(db)
+}
+
+func (suite *SessionRepositoryTestSuite) TearDownTest() {
+	sqlDB, err := suite.db.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+func (suite *SessionRepositoryTestSuite) createSession(userID uuid.UUID, device string, createdAt, expiresAt time.Time) *user.Session {
+	session := user.NewSession(uuid.New(), userID, device, createdAt, expiresAt)
+	suite.Require().NoError(suite.repo.Create(context.Background(), session))
+	return session
+}
+
+func (suite *SessionRepositoryTestSuite) TestListActiveSkipsExpiredAndOtherUsers() {
+	ctx := context.Background()
+	now := time.Now()
+	userID := uuid.New()
+
+	older := suite.createSession(userID, "laptop", now.Add(-2*time.Hour), now.Add(time.Hour))
+	newer := suite.createSession(userID, "phone", now.Add(-time.Hour), now.Add(time.Hour))
+	suite.createSession(userID, "expired", now.Add(-48*time.Hour), now.Add(-time.Hour))
+	suite.createSession(uuid.New(), "someone else", now, now.Add(time.Hour))
+
+	sessions, err := suite.repo.ListActive(ctx, userID, now)
+	suite.Require().NoError(err)
+	suite.Require().Len(sessions, 2)
+	suite.Equal(newer.ID, sessions[0].ID)
+	suite.Equal(older.ID, sessions[1].ID)
+
+	// Using the older session moves it to the front
+	suite.Require().NoError(suite.repo.TouchLastUsed(ctx, older.ID, now))
+	sessions, err = suite.repo.ListActive(ctx, userID, now)
+	suite.Require().NoError(err)
+	suite.Equal(older.ID, sessions[0].ID)
+}
+
+func (suite *SessionRepositoryTestSuite) TestDelete() {
+	ctx := context.Background()
+	now := time.Now()
+	session := suite.createSession(uuid.New(), "laptop", now, now.Add(time.Hour))
+
+	found, err := suite.repo.GetByID(ctx, session.ID)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(found)
+	suite.Equal("laptop", found.Device)
+
+	suite.Require().NoError(suite.repo.Delete(ctx, session.ID))
+	found, err = suite.repo.GetByID(ctx, session.ID)
+	suite.NoError(err)
+	suite.Nil(found)
+}
+
+func TestSessionRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(SessionRepositoryTestSuite))
+}
@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain/task"
+	repository "github.com/personal/task-management/internal/repositories"
+	"gorm.io/gorm"
+)
+
+type PostgresTaskCommentRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresTaskCommentRepository(db *gorm.DB) repository.TaskCommentRepository {
+	return &PostgresTaskCommentRepository{db: db}
+}
+
+func (r *PostgresTaskCommentRepository) Create(ctx context.Context, comment *task.TaskComment) error {
+	return r.db.WithContext(ctx).Create(comment).Error
+}
+
+func (r *PostgresTaskCommentRepository) GetByID(ctx context.Context, id uuid.UUID) (*task.TaskComment, error) {
+	var comment task.TaskComment
+	if err := r.db.WithContext(ctx).First(&comment, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *PostgresTaskCommentRepository) ListByTask(ctx context.Context, taskID uuid.UUID, limit, offset int) ([]*task.TaskComment, error) {
+	comments := []*task.TaskComment{}
+	err := r.db.WithContext(ctx).
+		Where("task_id = ?", taskID).
+		Order("created_at ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (r *PostgresTaskCommentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&task.TaskComment{}, "id = ?", id).Error
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,35 +11,151 @@ import (
 	repository "github.com/personal/task-management/internal/repositories"
 	"github.com/personal/task-management/pkg/cache"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PostgresTaskRepository struct {
 	db    *gorm.DB
-	cache cache.Cache
+	cache *cache.TypedCache[task.Task]
 }
 
+// taskCacheTTL bounds how stale a cached task can be if an invalidation is missed
+const taskCacheTTL = 30 * time.Second
+
+const taskCachePrefix = "task:"
+
 func NewPostgresTaskRepository(db *gorm.DB) repository.TaskRepository {
 	return &PostgresTaskRepository{db: db}
 }
 
+// NewPostgresTaskRepositoryWithCache serves GetByID from the cache when possible
+func NewPostgresTaskRepositoryWithCache(db *gorm.DB, c cache.Cache) repository.TaskRepository {
+	return &PostgresTaskRepository{db: db, cache: cache.NewTypedCache[task.Task](c)}
+}
+
 func (r *PostgresTaskRepository) Create(ctx context.Context, task *task.Task) error {
 	return r.db.Create(task).Error
 }
 
+func (r *PostgresTaskRepository) CreateBatch(ctx context.Context, tasks []*task.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Create(tasks).Error
+	})
+}
+
 func (r *PostgresTaskRepository) GetByID(ctx context.Context, id uuid.UUID) (*task.Task, error) {
+	if cached, ok := r.cachedTask(ctx, id); ok {
+		return cached, nil
+	}
+
 	var t task.Task
 	if err := r.db.First(&t, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Still a gorm.ErrRecordNotFound for callers checking for that
+			return nil, fmt.Errorf("%w: %w", task.ErrTaskNotFound, err)
+		}
 		return nil, err
 	}
+	if r.cache != nil {
+		// Store a copy so callers mutating the result cannot change the cache
+		_ = r.cache.SetWithExpire(ctx, taskCacheKey(id), t, taskCacheTTL)
+	}
 	return &t, nil
 }
 
 func (r *PostgresTaskRepository) Update(ctx context.Context, task *task.Task) error {
-	return r.db.Save(task).Error
+	if err := r.db.Save(task).Error; err != nil {
+		return err
+	}
+	return r.invalidateCache(ctx)
+}
+
+func (r *PostgresTaskRepository) UpdateWithEvents(ctx context.Context, t *task.Task, events []*task.TaskEvent) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(t).Error; err != nil {
+			return err
+		}
+		return createEvents(tx, events)
+	})
+	if err != nil {
+		return err
+	}
+	return r.invalidateCache(ctx)
+}
+
+func (r *PostgresTaskRepository) UpdateWithComment(ctx context.Context, t *task.Task, comment *task.TaskComment, events []*task.TaskEvent) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(t).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		return createEvents(tx, events)
+	})
+	if err != nil {
+		return err
+	}
+	return r.invalidateCache(ctx)
+}
+
+// createEvents stores events; gorm rejects an empty batch
+func createEvents(tx *gorm.DB, events []*task.TaskEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return tx.Create(events).Error
+}
+
+func (r *PostgresTaskRepository) ListEvents(ctx context.Context, taskID uuid.UUID) ([]*task.TaskEvent, error) {
+	events := []*task.TaskEvent{}
+	err := r.db.WithContext(ctx).
+		Where("task_id = ?", taskID).
+		Order("created_at ASC").
+		Order("id ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
 }
 
 func (r *PostgresTaskRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.Delete(&task.Task{}, "id = ?", id).Error
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&task.TaskTag{}, "task_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&task.Task{}, "id = ?", id).Error
+	})
+	if err != nil {
+		return err
+	}
+	return r.invalidateCache(ctx)
+}
+
+func taskCacheKey(id uuid.UUID) string {
+	return taskCachePrefix + id.String()
+}
+
+func (r *PostgresTaskRepository) cachedTask(ctx context.Context, id uuid.UUID) (*task.Task, bool) {
+	if r.cache == nil {
+		return nil, false
+	}
+	t, err := r.cache.Get(ctx, taskCacheKey(id))
+	if err != nil {
+		return nil, false
+	}
+	return &t, true
+}
+
+func (r *PostgresTaskRepository) invalidateCache(ctx context.Context) error {
+	if r.cache == nil {
+		return nil
+	}
+	return r.cache.DeleteByPrefix(ctx, taskCachePrefix)
 }
 
 func (r *PostgresTaskRepository) FindByAssignee(ctx context.Context, assigneeID uuid.UUID) ([]*task.Task, error) {
@@ -72,6 +189,63 @@ func (r *PostgresTaskRepository) FindByDueDateRange(ctx context.Context, start,
 	}
 	return tasks, nil
 }
+func (r *PostgresTaskRepository) FindOverdue(ctx context.Context, asOf time.Time, assigneeID *uuid.UUID) ([]*task.Task, error) {
+	query := r.db.WithContext(ctx).Where("due_date < ? AND status != ?", asOf, task.StatusCompleted)
+	if assigneeID != nil {
+		query = query.Where("assignee_id = ?", *assigneeID)
+	}
+
+	tasks := []*task.Task{}
+	if err := query.Order("due_date ASC").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *PostgresTaskRepository) FindRecurrencesDue(ctx context.Context) ([]*task.Task, error) {
+	tasks := []*task.Task{}
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND recurrence_frequency <> '' AND next_task_id IS NULL", task.StatusCompleted).
+		Order("due_date ASC").
+		Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *PostgresTaskRepository) CreateNextOccurrence(ctx context.Context, previous, next *task.Task) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Claim the previous task first so concurrent runs cannot both spawn a successor
+		result := tx.Model(&task.Task{}).
+			Where("id = ? AND next_task_id IS NULL", previous.ID).
+			Update("next_task_id", next.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if created {
+		previous.NextTaskID = &next.ID
+		if err := r.invalidateCache(ctx); err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
 func (r *PostgresTaskRepository) List(ctx context.Context, filter repository.TaskFilter) ([]*task.Task, error) {
 	query := r.db.Model(&task.Task{})
 
@@ -83,6 +257,18 @@ func (r *PostgresTaskRepository) List(ctx context.Context, filter repository.Tas
 		query = query.Where("status = ?", filter.Status)
 	}
 
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+
+	if len(filter.Tags) > 0 {
+		query = query.Where("id IN (?)", r.taggedTaskIDs(filter.Tags, filter.AnyTag))
+	}
+
+	if err := filter.ValidateSort(); err != nil {
+		return nil, err
+	}
+
 	// Default sorting if not specified
 	if filter.SortBy == "" {
 		filter.SortBy = "created_at" // Default sort by creation date
@@ -91,26 +277,25 @@ func (r *PostgresTaskRepository) List(ctx context.Context, filter repository.Tas
 
 	// Handle specific sort fields
 	switch filter.SortBy {
-	case "created_at", "due_date":
-		// These are valid date fields for sorting
-		query = query.Order(fmt.Sprintf("%s %s", filter.SortBy, filter.SortOrder))
 	case "status":
 		// Special handling for status sorting
 		if filter.SortOrder == "asc" {
-			// Order by status: pending, in_progress, completed
+			// Order by status: pending, in_progress, blocked, completed
 			query = query.Order("CASE status " +
 				"WHEN 'pending' THEN 1 " +
 				"WHEN 'in_progress' THEN 2 " +
-				"WHEN 'completed' THEN 3 END")
+				"WHEN 'blocked' THEN 3 " +
+				"WHEN 'completed' THEN 4 END")
 		} else {
-			// Order by status: completed, in_progress, pending
+			// Order by status: completed, blocked, in_progress, pending
 			query = query.Order("CASE status " +
 				"WHEN 'completed' THEN 1 " +
-				"WHEN 'in_progress' THEN 2 " +
-				"WHEN 'pending' THEN 3 END")
+				"WHEN 'blocked' THEN 2 " +
+				"WHEN 'in_progress' THEN 3 " +
+				"WHEN 'pending' THEN 4 END")
 		}
 	default:
-		// For any other fields, use standard ordering
+		// ValidateSort limits these to known columns and directions
 		query = query.Order(fmt.Sprintf("%s %s", filter.SortBy, filter.SortOrder))
 	}
 
@@ -130,3 +315,67 @@ func (r *PostgresTaskRepository) List(ctx context.Context, filter repository.Tas
 
 	return tasks, nil
 }
+
+// taggedTaskIDs builds a subquery selecting the IDs of tasks carrying all of
+// the given tags, or any of them when matchAny is set
+func (r *PostgresTaskRepository) taggedTaskIDs(tags []string, matchAny bool) *gorm.DB {
+	names := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, name := range tags {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	subquery := r.db.Model(&task.TaskTag{}).
+		Select("task_tags.task_id").
+		Joins("JOIN tags ON tags.id = task_tags.tag_id").
+		Where("tags.name IN ?", names).
+		Group("task_tags.task_id")
+	if !matchAny {
+		subquery = subquery.Having("COUNT(DISTINCT tags.name) = ?", len(names))
+	}
+	return subquery
+}
+
+func (r *PostgresTaskRepository) AddTag(ctx context.Context, taskID uuid.UUID, tag *task.Tag) (*task.Tag, error) {
+	var stored task.Tag
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Reuse the existing tag when another task already has it
+		err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).
+			Create(tag).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.First(&stored, "name = ?", tag.Name).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&task.TaskTag{TaskID: taskID, TagID: stored.ID}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+func (r *PostgresTaskRepository) RemoveTag(ctx context.Context, taskID uuid.UUID, name string) error {
+	tagIDs := r.db.Model(&task.Tag{}).Select("id").Where("name = ?", name)
+	return r.db.WithContext(ctx).
+		Where("task_id = ? AND tag_id IN (?)", taskID, tagIDs).
+		Delete(&task.TaskTag{}).Error
+}
+
+func (r *PostgresTaskRepository) ListTags(ctx context.Context, taskID uuid.UUID) ([]*task.Tag, error) {
+	tags := []*task.Tag{}
+	err := r.db.WithContext(ctx).
+		Joins("JOIN task_tags ON task_tags.tag_id = tags.id").
+		Where("task_tags.task_id = ?", taskID).
+		Order("tags.name ASC").
+		Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
@@ -0,0 +1,388 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain/task"
+	"github.com/personal/task-management/internal/domain/user"
+	repository "github.com/personal/task-management/internal/repositories"
+	localmemory "github.com/personal/task-management/pkg/cache/local-memory"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type TaskRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo repository.TaskRepository
+}
+
+// SetupTest runs each test against a fresh in-memory database
+func (suite *TaskRepositoryTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	suite.Require().NoError(err)
+	// Every connection to :memory: opens a separate database, so keep to one
+	sqlDB, err := db.DB()
+	suite.Require().NoError(err)
+	sqlDB.SetMaxOpenConns(1)
+	suite.Require().NoError(db.AutoMigrate(&task.Task{}, &task.Tag{}, &task.TaskTag{}, &task.TaskComment{}, &task.TaskEvent{}))
+	suite.db = db
+	suite.repo = NewPostgresTaskRepository(db)
+}
+
+func (suite *TaskRepositoryTestSuite) TearDownTest() {
+	sqlDB, err := suite.db.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+func (suite *TaskRepositoryTestSuite) createTask(title string, tags ...string) *task.Task {
+	t, err := task.NewTask(title, "", time.Now().Add(time.Hour), uuid.New(), uuid.New())
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.repo.Create(context.Background(), t))
+
+	for _, name := range tags {
+		tag, err := task.NewTag(name)
+		suite.Require().NoError(err)
+		_, err = suite.repo.AddTag(context.Background(), t.ID, tag)
+		suite.Require().NoError(err)
+	}
+	return t
+}
+
+func titles(tasks []*task.Task) []string {
+	result := make([]string, 0, len(tasks))
+	for _, t := range tasks {
+		result = append(result, t.Title)
+	}
+	return result
+}
+
+func (suite *TaskRepositoryTestSuite) TestListFiltersByStatusAlone() {
+	blocked := suite.createTask("blocked")
+	suite.Require().NoError(blocked.Block(task.DefaultWorkflow(), "waiting on review", user.Employee))
+	suite.Require().NoError(suite.repo.Update(context.Background(), blocked))
+	suite.createTask("pending")
+
+	status := task.StatusBlocked
+	tasks, err := suite.repo.List(context.Background(), repository.TaskFilter{Status: &status})
+	suite.NoError(err)
+	suite.Equal([]string{"blocked"}, titles(tasks))
+}
+
+func (suite *TaskRepositoryTestSuite) TestListFiltersByAllTags() {
+	suite.createTask("api and bug", "backend", "bug")
+	suite.createTask("api only", "backend")
+	suite.createTask("ui bug", "frontend", "bug")
+	suite.createTask("untagged")
+
+	tasks, err := suite.repo.List(context.Background(), repository.TaskFilter{
+		Tags: []string{"backend", "bug"},
+	})
+	suite.NoError(err)
+	suite.Equal([]string{"api and bug"}, titles(tasks))
+}
+
+func (suite *TaskRepositoryTestSuite) TestListFiltersByAllTagsIgnoresDuplicates() {
+	suite.createTask("api and bug", "backend", "bug")
+	suite.createTask("api only", "backend")
+
+	tasks, err := suite.repo.List(context.Background(), repository.TaskFilter{
+		Tags: []string{"backend", "backend"},
+	})
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"api and bug", "api only"}, titles(tasks))
+}
+
+func (suite *TaskRepositoryTestSuite) TestListFiltersByAnyTag() {
+	suite.createTask("api and bug", "backend", "bug")
+	suite.createTask("api only", "backend")
+	suite.createTask("ui bug", "frontend", "bug")
+	suite.createTask("untagged")
+
+	tasks, err := suite.repo.List(context.Background(), repository.TaskFilter{
+		Tags:   []string{"backend", "bug"},
+		AnyTag: true,
+	})
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"api and bug", "api only", "ui bug"}, titles(tasks))
+}
+
+func (suite *TaskRepositoryTestSuite) TestListSortsByAllowedField() {
+	suite.createTask("b")
+	suite.createTask("a")
+	suite.createTask("c")
+
+	tasks, err := suite.repo.List(context.Background(), repository.TaskFilter{SortBy: "title", SortOrder: "asc"})
+	suite.NoError(err)
+	suite.Equal([]string{"a", "b", "c"}, titles(tasks))
+}
+
+func (suite *TaskRepositoryTestSuite) TestListRejectsSortInjection() {
+	suite.createTask("survivor")
+
+	filters := map[string]repository.TaskFilter{
+		"sort_by":    {SortBy: "title; DROP TABLE tasks; --"},
+		"sort_order": {SortBy: "title", SortOrder: "asc; DROP TABLE tasks; --"},
+		"subquery":   {SortBy: "(SELECT 1)"},
+		"uppercase":  {SortOrder: "DESC"},
+	}
+	for name, filter := range filters {
+		suite.Run(name, func() {
+			_, err := suite.repo.List(context.Background(), filter)
+			suite.ErrorIs(err, task.ErrInvalidSort)
+		})
+	}
+
+	// Nothing was executed: the table and its rows are still there
+	tasks, err := suite.repo.List(context.Background(), repository.TaskFilter{})
+	suite.NoError(err)
+	suite.Equal([]string{"survivor"}, titles(tasks))
+}
+
+func (suite *TaskRepositoryTestSuite) TestAddTagReusesExistingTag() {
+	first := suite.createTask("first", "backend")
+	second := suite.createTask("second")
+
+	tag, err := task.NewTag("Backend")
+	suite.Require().NoError(err)
+	stored, err := suite.repo.AddTag(context.Background(), second.ID, tag)
+	suite.NoError(err)
+
+	firstTags, err := suite.repo.ListTags(context.Background(), first.ID)
+	suite.NoError(err)
+	suite.Require().Len(firstTags, 1)
+	suite.Equal(firstTags[0].ID, stored.ID)
+
+	// Tagging twice is a no-op
+	_, err = suite.repo.AddTag(context.Background(), second.ID, tag)
+	suite.NoError(err)
+	secondTags, err := suite.repo.ListTags(context.Background(), second.ID)
+	suite.NoError(err)
+	suite.Len(secondTags, 1)
+}
+
+func (suite *TaskRepositoryTestSuite) TestRemoveTag() {
+	t := suite.createTask("api and bug", "bug", "backend")
+
+	suite.NoError(suite.repo.RemoveTag(context.Background(), t.ID, "bug"))
+	suite.NoError(suite.repo.RemoveTag(context.Background(), t.ID, "missing"))
+
+	tags, err := suite.repo.ListTags(context.Background(), t.ID)
+	suite.NoError(err)
+	suite.Require().Len(tags, 1)
+	suite.Equal("backend", tags[0].Name)
+}
+
+func (suite *TaskRepositoryTestSuite) saveWithDueDate(title string, dueDate time.Time, status task.Status, assigneeID uuid.UUID) {
+	t, err := task.NewTask(title, "", time.Now().Add(time.Hour), uuid.New(), assigneeID)
+	suite.Require().NoError(err)
+	t.DueDate = dueDate
+	t.Status = status
+	suite.Require().NoError(suite.repo.Create(context.Background(), t))
+}
+
+func (suite *TaskRepositoryTestSuite) TestFindOverdueStraddlingDueBoundary() {
+	asOf := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	assignee := uuid.New()
+	suite.saveWithDueDate("due a minute ago", asOf.Add(-time.Minute), task.StatusInProgress, assignee)
+	suite.saveWithDueDate("due yesterday", asOf.Add(-24*time.Hour), task.StatusBlocked, uuid.New())
+	suite.saveWithDueDate("due right now", asOf, task.StatusPending, assignee)
+	suite.saveWithDueDate("due in a minute", asOf.Add(time.Minute), task.StatusPending, assignee)
+	suite.saveWithDueDate("finished late", asOf.Add(-time.Hour), task.StatusCompleted, assignee)
+
+	tasks, err := suite.repo.FindOverdue(context.Background(), asOf, nil)
+	suite.NoError(err)
+	suite.Equal([]string{"due yesterday", "due a minute ago"}, titles(tasks))
+
+	tasks, err = suite.repo.FindOverdue(context.Background(), asOf, &assignee)
+	suite.NoError(err)
+	suite.Equal([]string{"due a minute ago"}, titles(tasks))
+}
+
+func (suite *TaskRepositoryTestSuite) TestCreateNextOccurrenceOnlyOnce() {
+	weekly := task.RecurrenceRule{Frequency: task.FrequencyWeekly, Interval: 1}
+	completed := suite.createTask("weekly report")
+	completed.Status = task.StatusCompleted
+	completed.Recurrence = weekly
+	suite.Require().NoError(suite.repo.Update(context.Background(), completed))
+
+	oneOff := suite.createTask("one-off")
+	oneOff.Status = task.StatusCompleted
+	suite.Require().NoError(suite.repo.Update(context.Background(), oneOff))
+
+	open := suite.createTask("open weekly")
+	open.Recurrence = weekly
+	suite.Require().NoError(suite.repo.Update(context.Background(), open))
+
+	due, err := suite.repo.FindRecurrencesDue(context.Background())
+	suite.NoError(err)
+	suite.Equal([]string{"weekly report"}, titles(due))
+
+	next, err := due[0].NextOccurrence(time.Now())
+	suite.Require().NoError(err)
+	created, err := suite.repo.CreateNextOccurrence(context.Background(), due[0], next)
+	suite.NoError(err)
+	suite.True(created)
+
+	// A second run racing on the same task must not spawn another occurrence
+	duplicate, err := due[0].NextOccurrence(time.Now())
+	suite.Require().NoError(err)
+	created, err = suite.repo.CreateNextOccurrence(context.Background(), due[0], duplicate)
+	suite.NoError(err)
+	suite.False(created)
+
+	stored, err := suite.repo.GetByID(context.Background(), completed.ID)
+	suite.NoError(err)
+	suite.Require().NotNil(stored.NextTaskID)
+	suite.Equal(next.ID, *stored.NextTaskID)
+
+	spawned, err := suite.repo.GetByID(context.Background(), next.ID)
+	suite.NoError(err)
+	suite.Equal(weekly, spawned.Recurrence)
+
+	due, err = suite.repo.FindRecurrencesDue(context.Background())
+	suite.NoError(err)
+	suite.Empty(due)
+}
+
+func (suite *TaskRepositoryTestSuite) TestCreateBatchIsAtomic() {
+	first, err := task.NewTask("First", "", time.Now().Add(time.Hour), uuid.New(), uuid.New())
+	suite.Require().NoError(err)
+	second, err := task.NewTask("Second", "", time.Now().Add(time.Hour), uuid.New(), uuid.New())
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.repo.CreateBatch(context.Background(), []*task.Task{first, second}))
+
+	stored, err := suite.repo.GetByID(context.Background(), second.ID)
+	suite.Require().NoError(err)
+	suite.Equal("Second", stored.Title)
+
+	// Reusing an existing ID fails the batch, so the new task is not kept either
+	third, err := task.NewTask("Third", "", time.Now().Add(time.Hour), uuid.New(), uuid.New())
+	suite.Require().NoError(err)
+	suite.Error(suite.repo.CreateBatch(context.Background(), []*task.Task{third, first}))
+
+	var count int64
+	suite.Require().NoError(suite.db.Model(&task.Task{}).Count(&count).Error)
+	suite.Equal(int64(2), count)
+}
+
+func (suite *TaskRepositoryTestSuite) TestUpdateWithEventsRecordsHistory() {
+	ctx := context.Background()
+	t := suite.createTask("Deploy")
+	actorID := uuid.New()
+
+	before := *t
+	suite.Require().NoError(t.UpdateStatus(task.DefaultWorkflow(), task.StatusInProgress, user.Employee))
+	suite.Require().NoError(suite.repo.UpdateWithEvents(ctx, t, task.Changes(&before, t, actorID)))
+	before = *t
+	suite.Require().NoError(t.UpdateStatus(task.DefaultWorkflow(), task.StatusCompleted, user.Employee))
+	events := task.Changes(&before, t, actorID)
+	suite.Require().NoError(suite.repo.UpdateWithEvents(ctx, t, events))
+
+	history, err := suite.repo.ListEvents(ctx, t.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(history, 2)
+	suite.Equal("pending", history[0].OldValue)
+	suite.Equal("in_progress", history[0].NewValue)
+	suite.Equal("in_progress", history[1].OldValue)
+	suite.Equal("completed", history[1].NewValue)
+	suite.Equal(actorID, history[1].ActorID)
+
+	// Replaying an event fails its insert, so the task change is rolled back with it
+	suite.Require().NoError(t.UpdateStatus(task.DefaultWorkflow(), task.StatusPending, user.Employer))
+	suite.Error(suite.repo.UpdateWithEvents(ctx, t, events))
+	stored, err := suite.repo.GetByID(ctx, t.ID)
+	suite.Require().NoError(err)
+	suite.Equal(task.StatusCompleted, stored.Status)
+
+	history, err = suite.repo.ListEvents(ctx, uuid.New())
+	suite.NoError(err)
+	suite.Empty(history)
+}
+
+func (suite *TaskRepositoryTestSuite) TestUpdateWithCommentIsAtomic() {
+	ctx := context.Background()
+	t := suite.createTask("Deploy")
+	suite.Require().NoError(t.UpdateStatus(task.DefaultWorkflow(), task.StatusInProgress, user.Employer))
+	comment, err := task.NewTaskComment(t.ID, t.AssigneeID, "starting now")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.repo.UpdateWithComment(ctx, t, comment, nil))
+
+	stored, err := suite.repo.GetByID(ctx, t.ID)
+	suite.Require().NoError(err)
+	suite.Equal(task.StatusInProgress, stored.Status)
+
+	// Reusing the comment's ID fails the insert, so the status change is
+	// rolled back with it
+	suite.Require().NoError(t.UpdateStatus(task.DefaultWorkflow(), task.StatusCompleted, user.Employer))
+	suite.Error(suite.repo.UpdateWithComment(ctx, t, comment, nil))
+
+	stored, err = suite.repo.GetByID(ctx, t.ID)
+	suite.Require().NoError(err)
+	suite.Equal(task.StatusInProgress, stored.Status)
+
+	var count int64
+	suite.Require().NoError(suite.db.Model(&task.TaskComment{}).Count(&count).Error)
+	suite.Equal(int64(1), count)
+}
+
+func (suite *TaskRepositoryTestSuite) newCachedRepo() repository.TaskRepository {
+	c, err := localmemory.NewCache(time.Minute)
+	suite.Require().NoError(err)
+	suite.T().Cleanup(func() { c.Close() })
+	return NewPostgresTaskRepositoryWithCache(suite.db, c)
+}
+
+func (suite *TaskRepositoryTestSuite) TestGetByIDServedFromCache() {
+	repo := suite.newCachedRepo()
+	t := suite.createTask("cached")
+
+	first, err := repo.GetByID(context.Background(), t.ID)
+	suite.Require().NoError(err)
+	suite.Equal("cached", first.Title)
+
+	// Change the row behind the repository's back; the cached copy must still be returned
+	suite.Require().NoError(suite.db.Model(&task.Task{}).Where("id = ?", t.ID).Update("title", "changed").Error)
+
+	second, err := repo.GetByID(context.Background(), t.ID)
+	suite.NoError(err)
+	suite.Equal("cached", second.Title)
+}
+
+func (suite *TaskRepositoryTestSuite) TestUpdateInvalidatesCache() {
+	repo := suite.newCachedRepo()
+	t := suite.createTask("before")
+
+	cached, err := repo.GetByID(context.Background(), t.ID)
+	suite.Require().NoError(err)
+
+	cached.Title = "after"
+	suite.Require().NoError(repo.Update(context.Background(), cached))
+
+	got, err := repo.GetByID(context.Background(), t.ID)
+	suite.NoError(err)
+	suite.Equal("after", got.Title)
+}
+
+func (suite *TaskRepositoryTestSuite) TestDeleteInvalidatesCache() {
+	repo := suite.newCachedRepo()
+	t := suite.createTask("doomed")
+
+	_, err := repo.GetByID(context.Background(), t.ID)
+	suite.Require().NoError(err)
+	suite.Require().NoError(repo.Delete(context.Background(), t.ID))
+
+	_, err = repo.GetByID(context.Background(), t.ID)
+	suite.ErrorIs(err, gorm.ErrRecordNotFound)
+	suite.ErrorIs(err, task.ErrTaskNotFound)
+}
+
+func TestTaskRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(TaskRepositoryTestSuite))
+}
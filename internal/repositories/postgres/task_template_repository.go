@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain/task"
+	repository "github.com/personal/task-management/internal/repositories"
+	"gorm.io/gorm"
+)
+
+type PostgresTaskTemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresTaskTemplateRepository(db *gorm.DB) repository.TaskTemplateRepository {
+	return &PostgresTaskTemplateRepository{db: db}
+}
+
+func (r *PostgresTaskTemplateRepository) Create(ctx context.Context, template *task.TaskTemplate) error {
+	return r.db.WithContext(ctx).Create(template).Error
+}
+
+func (r *PostgresTaskTemplateRepository) GetByID(ctx context.Context, id uuid.UUID) (*task.TaskTemplate, error) {
+	var template task.TaskTemplate
+	if err := r.db.WithContext(ctx).First(&template, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *PostgresTaskTemplateRepository) List(ctx context.Context, limit, offset int) ([]*task.TaskTemplate, error) {
+	templates := []*task.TaskTemplate{}
+	err := r.db.WithContext(ctx).
+		Order("title ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&templates).Error
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (r *PostgresTaskTemplateRepository) Update(ctx context.Context, template *task.TaskTemplate) error {
+	return r.db.WithContext(ctx).Save(template).Error
+}
+
+func (r *PostgresTaskTemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&task.TaskTemplate{}, "id = ?", id).Error
+}
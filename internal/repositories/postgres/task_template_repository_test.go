@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain/task"
+	repository "github.com/personal/task-management/internal/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type TaskTemplateRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo repository.TaskTemplateRepository
+}
+
+// SetupTest runs each test against a fresh in-memory database
+func (suite *TaskTemplateRepositoryTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	suite.Require().NoError(err)
+	// Every connection to :memory: opens a separate database, so keep to one
+	sqlDB, err := db.DB()
+	suite.Require().NoError(err)
+	sqlDB.SetMaxOpenConns(1)
+	suite.Require().NoError(db.AutoMigrate(&task.TaskTemplate{}))
+	suite.db = db
+	suite.repo = NewPostgresTaskTemplateRepository(db)
+}
+
+func (suite *TaskTemplateRepositoryTestSuite) TearDownTest() {
+	sqlDB, err := suite.db.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+func (suite *TaskTemplateRepositoryTestSuite) createTemplate(title string, assigneeID *uuid.UUID) *task.TaskTemplate {
+	template, err := task.NewTaskTemplate(title, "", 24, assigneeID, uuid.New())
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.repo.Create(context.Background(), template))
+	return template
+}
+
+func (suite *TaskTemplateRepositoryTestSuite) TestCreateAndGet() {
+	assignee := uuid.New()
+	created := suite.createTemplate("Weekly report", &assignee)
+
+	stored, err := suite.repo.GetByID(context.Background(), created.ID)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(stored)
+	suite.Equal("Weekly report", stored.Title)
+	suite.Equal(24, stored.DueOffsetHours)
+	suite.Equal(&assignee, stored.DefaultAssigneeID)
+}
+
+func (suite *TaskTemplateRepositoryTestSuite) TestGetMissingReturnsNil() {
+	stored, err := suite.repo.GetByID(context.Background(), uuid.New())
+	suite.NoError(err)
+	suite.Nil(stored)
+}
+
+func (suite *TaskTemplateRepositoryTestSuite) TestListOrdersByTitle() {
+	suite.createTemplate("Onboarding", nil)
+	suite.createTemplate("Audit", nil)
+	suite.createTemplate("Release", nil)
+
+	templates, err := suite.repo.List(context.Background(), 2, 0)
+	suite.NoError(err)
+	suite.Require().Len(templates, 2)
+	suite.Equal("Audit", templates[0].Title)
+	suite.Equal("Onboarding", templates[1].Title)
+}
+
+func (suite *TaskTemplateRepositoryTestSuite) TestUpdateAndDelete() {
+	template := suite.createTemplate("Draft", nil)
+	suite.Require().NoError(template.Update("Final", "Done", 72, nil))
+	suite.Require().NoError(suite.repo.Update(context.Background(), template))
+
+	stored, err := suite.repo.GetByID(context.Background(), template.ID)
+	suite.Require().NoError(err)
+	suite.Equal("Final", stored.Title)
+	suite.Equal(72, stored.DueOffsetHours)
+
+	suite.Require().NoError(suite.repo.Delete(context.Background(), template.ID))
+	stored, err = suite.repo.GetByID(context.Background(), template.ID)
+	suite.NoError(err)
+	suite.Nil(stored)
+}
+
+func TestTaskTemplateRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(TaskTemplateRepositoryTestSuite))
+}
@@ -2,6 +2,10 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/personal/task-management/internal/domain/user"
@@ -24,6 +28,9 @@ func (r *PostgresUserRepository) Create(ctx context.Context, user *user.User) er
 func (r *PostgresUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*user.User, error) {
 	var u user.User
 	if err := r.db.First(&u, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, user.ErrUserNotFound
+		}
 		return nil, err
 	}
 	return &u, nil
@@ -32,6 +39,9 @@ func (r *PostgresUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*us
 func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (*user.User, error) {
 	var u user.User
 	if err := r.db.First(&u, "email = ?", email).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, user.ErrUserNotFound
+		}
 		return nil, err
 	}
 
@@ -46,9 +56,86 @@ func (r *PostgresUserRepository) Delete(ctx context.Context, id uuid.UUID) error
 	return r.db.Delete(&user.User{}, "id = ?", id).Error
 }
 
-func (r *PostgresUserRepository) List(ctx context.Context, offset, limit int) ([]*user.User, error) {
+func (r *PostgresUserRepository) List(ctx context.Context, filter repository.UserFilter) ([]*user.User, error) {
+	if err := filter.ValidateSort(); err != nil {
+		return nil, err
+	}
+
+	sortBy, order := filter.SortBy, filter.SortOrder
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+	if order == "" {
+		order = "asc"
+	}
+	// ValidateSort limits these to known columns and directions. Ties, such as
+	// everyone sharing a role, are broken by ID so pages neither repeat nor
+	// skip users.
+	query := r.filtered(ctx, filter).Order(fmt.Sprintf("%s %s", sortBy, order)).Order("id ASC")
+
+	var users []*user.User
+	if err := query.Offset(filter.Offset).Limit(filter.Limit).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *PostgresUserRepository) Count(ctx context.Context, filter repository.UserFilter) (int64, error) {
+	var count int64
+	if err := r.filtered(ctx, filter).Model(&user.User{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// filtered applies the filter's search, role and status, shared by List and
+// Count so a page and its total always agree
+func (r *PostgresUserRepository) filtered(ctx context.Context, filter repository.UserFilter) *gorm.DB {
+	query := r.db.WithContext(ctx)
+	if filter.Search != "" {
+		pattern := "%" + repository.EscapeLike(strings.ToLower(filter.Search)) + "%"
+		// Grouped so the OR doesn't swallow the conditions that follow
+		query = query.Where(r.db.Where(`LOWER(name) LIKE ? ESCAPE '\'`, pattern).Or(`LOWER(email) LIKE ? ESCAPE '\'`, pattern))
+	}
+	if filter.Role != nil {
+		query = query.Where("role = ?", *filter.Role)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	return query
+}
+
+func (r *PostgresUserRepository) SearchByNamePrefix(ctx context.Context, prefix string, limit int, sharedWith *uuid.UUID) ([]*user.User, error) {
+	// LOWER(name) matches the idx_users_name_prefix expression index
+	query := r.db.WithContext(ctx).
+		Where(`LOWER(name) LIKE ? ESCAPE '\'`, repository.EscapeLike(strings.ToLower(prefix))+"%")
+	if sharedWith != nil {
+		rooms := r.db.Table("room_users").Select("room_id").Where("user_id = ?", sharedWith.String())
+		members := r.db.Table("room_users").Select("user_id").Where("room_id IN (?)", rooms)
+		query = query.Where("CAST(id AS TEXT) IN (?)", members)
+	}
+
+	var users []*user.User
+	if err := query.Order("name ASC").Limit(limit).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *PostgresUserRepository) TouchLastSeen(ctx context.Context, id uuid.UUID, at time.Time) error {
+	// UpdateColumn leaves updated_at alone; being seen is not an edit
+	return r.db.WithContext(ctx).Model(&user.User{}).Where("id = ?", id).UpdateColumn("last_seen_at", at).Error
+}
+
+func (r *PostgresUserRepository) ListRecentlyActive(ctx context.Context, since time.Time, limit int) ([]*user.User, error) {
 	var users []*user.User
-	if err := r.db.Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+	err := r.db.WithContext(ctx).
+		Where("last_seen_at >= ?", since).
+		Order("last_seen_at DESC").
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
 		return nil, err
 	}
 	return users, nil
@@ -0,0 +1,308 @@
+package postgres
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain"
+	"github.com/personal/task-management/internal/domain/user"
+	repository "github.com/personal/task-management/internal/repositories"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type UserRepositoryTestSuite struct {
+	suite.Suite
+	db   *gorm.DB
+	repo repository.UserRepository
+}
+
+// SetupTest runs each test against a fresh in-memory database
+func (suite *UserRepositoryTestSuite) SetupTest() {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	suite.Require().NoError(err)
+	// Every connection to :memory: opens a separate database, so keep to one
+	sqlDB, err := db.DB()
+	suite.Require().NoError(err)
+	sqlDB.SetMaxOpenConns(1)
+	suite.Require().NoError(db.AutoMigrate(&user.User{}, &domain.RoomUser{}))
+	suite.db = db
+	suite.repo = NewPostgresUserRepository(db)
+}
+
+func (suite *UserRepositoryTestSuite) TearDownTest() {
+	sqlDB, err := suite.db.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+func (suite *UserRepositoryTestSuite) createUser(name string) *user.User {
+	u, err := user.NewUser(uuid.NewString()+"@example.com", name, "hashed")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.repo.Create(context.Background(), u))
+	return u
+}
+
+func (suite *UserRepositoryTestSuite) joinRoom(roomID string, users ...*user.User) {
+	for _, u := range users {
+		suite.Require().NoError(suite.db.Create(&domain.RoomUser{ID: uuid.NewString(), RoomID: roomID, UserID: u.ID.String()}).Error)
+	}
+}
+
+func names(users []*user.User) []string {
+	result := make([]string, 0, len(users))
+	for _, u := range users {
+		result = append(result, u.Name)
+	}
+	return result
+}
+
+func (suite *UserRepositoryTestSuite) TestSearchByNamePrefixMatchesPrefixIgnoringCase() {
+	suite.createUser("Alice")
+	suite.createUser("alan")
+	suite.createUser("Malory")
+
+	users, err := suite.repo.SearchByNamePrefix(context.Background(), "AL", 10, nil)
+	suite.NoError(err)
+	suite.Equal([]string{"Alice", "alan"}, names(users))
+}
+
+func (suite *UserRepositoryTestSuite) TestSearchByNamePrefixTreatsWildcardsLiterally() {
+	suite.createUser("a_b")
+	suite.createUser("axb")
+
+	users, err := suite.repo.SearchByNamePrefix(context.Background(), "a_", 10, nil)
+	suite.NoError(err)
+	suite.Equal([]string{"a_b"}, names(users))
+}
+
+func (suite *UserRepositoryTestSuite) TestSearchByNamePrefixAppliesLimit() {
+	suite.createUser("sam")
+	suite.createUser("sandra")
+	suite.createUser("sara")
+
+	users, err := suite.repo.SearchByNamePrefix(context.Background(), "sa", 2, nil)
+	suite.NoError(err)
+	suite.Equal([]string{"sam", "sandra"}, names(users))
+}
+
+func (suite *UserRepositoryTestSuite) TestSearchByNamePrefixScopesToSharedRooms() {
+	caller := suite.createUser("caller")
+	roommate := suite.createUser("bob")
+	stranger := suite.createUser("bobby")
+	suite.joinRoom("room-1", caller, roommate)
+	suite.joinRoom("room-2", stranger)
+
+	users, err := suite.repo.SearchByNamePrefix(context.Background(), "bob", 10, &caller.ID)
+	suite.NoError(err)
+	suite.Equal([]string{"bob"}, names(users))
+}
+
+func (suite *UserRepositoryTestSuite) TestGetMissingUserIsNotFound() {
+	u := suite.createUser("alice")
+
+	found, err := suite.repo.GetByEmail(context.Background(), u.Email)
+	suite.NoError(err)
+	suite.Equal(u.ID, found.ID)
+
+	_, err = suite.repo.GetByEmail(context.Background(), "nobody@example.com")
+	suite.ErrorIs(err, user.ErrUserNotFound)
+	_, err = suite.repo.GetByID(context.Background(), uuid.New())
+	suite.ErrorIs(err, user.ErrUserNotFound)
+}
+
+func (suite *UserRepositoryTestSuite) TestGetByEmailReportsOtherErrors() {
+	suite.Require().NoError(suite.db.Migrator().DropTable(&user.User{}))
+
+	_, err := suite.repo.GetByEmail(context.Background(), "alice@example.com")
+	suite.Error(err)
+	suite.NotErrorIs(err, user.ErrUserNotFound)
+}
+
+func (suite *UserRepositoryTestSuite) TestListSearchesNameAndEmail() {
+	suite.createUser("Carol")
+	suite.createUser("dave")
+	other, err := user.NewUser("caroline@example.com", "erin", "hashed")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.repo.Create(context.Background(), other))
+
+	users, err := suite.repo.List(context.Background(), repository.UserFilter{Search: "CAROL", SortBy: "name", Limit: 10})
+	suite.NoError(err)
+	suite.Equal([]string{"Carol", "erin"}, names(users))
+}
+
+func (suite *UserRepositoryTestSuite) TestListSearchIsParameterized() {
+	suite.createUser("o'brien")
+	suite.createUser("plain")
+
+	for _, search := range []string{"' OR '1'='1", "%", "_"} {
+		users, err := suite.repo.List(context.Background(), repository.UserFilter{Search: search, Limit: 10})
+		suite.NoError(err)
+		suite.Empty(users, search)
+	}
+
+	users, err := suite.repo.List(context.Background(), repository.UserFilter{Search: "o'b", Limit: 10})
+	suite.NoError(err)
+	suite.Equal([]string{"o'brien"}, names(users))
+}
+
+func (suite *UserRepositoryTestSuite) TestListSortsByAllowedField() {
+	suite.createUser("bob")
+	suite.createUser("alice")
+	suite.createUser("carol")
+
+	users, err := suite.repo.List(context.Background(), repository.UserFilter{SortBy: "name", SortOrder: "desc", Limit: 10})
+	suite.NoError(err)
+	suite.Equal([]string{"carol", "bob", "alice"}, names(users))
+}
+
+func (suite *UserRepositoryTestSuite) TestListRejectsSortInjection() {
+	suite.createUser("survivor")
+
+	filters := map[string]repository.UserFilter{
+		"sort_by":    {SortBy: "name; DROP TABLE users; --"},
+		"sort_order": {SortBy: "name", SortOrder: "asc; DROP TABLE users; --"},
+		"password":   {SortBy: "password"},
+	}
+	for name, filter := range filters {
+		suite.Run(name, func() {
+			filter.Limit = 10
+			_, err := suite.repo.List(context.Background(), filter)
+			suite.ErrorIs(err, user.ErrInvalidSort)
+		})
+	}
+
+	users, err := suite.repo.List(context.Background(), repository.UserFilter{Limit: 10})
+	suite.NoError(err)
+	suite.Equal([]string{"survivor"}, names(users))
+}
+
+func (suite *UserRepositoryTestSuite) TestListAndCountFilterByRoleAndStatus() {
+	save := func(name string, role user.Role, status user.Status) {
+		u := suite.createUser(name)
+		u.Role, u.Status = role, status
+		suite.Require().NoError(suite.repo.Update(context.Background(), u))
+	}
+	save("boss", user.Employer, user.StatusActive)
+	save("carl", user.Employee, user.StatusInactive)
+	save("carol", user.Employee, user.StatusActive)
+	save("dave", user.Employee, user.StatusActive)
+
+	employee, inactive := user.Employee, user.StatusInactive
+	filter := repository.UserFilter{Role: &employee, SortBy: "name", Limit: 10}
+	users, err := suite.repo.List(context.Background(), filter)
+	suite.NoError(err)
+	suite.Equal([]string{"carl", "carol", "dave"}, names(users))
+
+	// The total ignores pagination, and search doesn't widen the other filters
+	filter = repository.UserFilter{Role: &employee, Search: "car", SortBy: "name", Limit: 1}
+	users, err = suite.repo.List(context.Background(), filter)
+	suite.NoError(err)
+	suite.Equal([]string{"carl"}, names(users))
+	total, err := suite.repo.Count(context.Background(), filter)
+	suite.NoError(err)
+	suite.EqualValues(2, total)
+
+	filter = repository.UserFilter{Status: &inactive, Search: "car", Limit: 10}
+	users, err = suite.repo.List(context.Background(), filter)
+	suite.NoError(err)
+	suite.Equal([]string{"carl"}, names(users))
+	total, err = suite.repo.Count(context.Background(), filter)
+	suite.NoError(err)
+	suite.EqualValues(1, total)
+}
+
+func (suite *UserRepositoryTestSuite) TestListPagesAreStableAcrossTies() {
+	var ids []string
+	for _, name := range []string{"erin", "carol", "dave", "bob", "alice"} {
+		ids = append(ids, suite.createUser(name).ID.String())
+	}
+	sort.Strings(ids)
+
+	// Every user has the same role, so only the ID orders them
+	for _, order := range []string{"asc", "desc"} {
+		var paged []string
+		for offset := 0; offset < len(ids); offset += 2 {
+			users, err := suite.repo.List(context.Background(), repository.UserFilter{SortBy: "role", SortOrder: order, Offset: offset, Limit: 2})
+			suite.NoError(err)
+			for _, u := range users {
+				paged = append(paged, u.ID.String())
+			}
+		}
+		suite.Equal(ids, paged, order)
+	}
+}
+
+func (suite *UserRepositoryTestSuite) TestListCombinesFiltersWithSort() {
+	for _, name := range []string{"Cara", "carl", "Marta", "Bart", "Dave"} {
+		u := suite.createUser(name)
+		u.Role = user.Employee
+		if name == "Marta" {
+			u.Role = user.Employer
+		}
+		suite.Require().NoError(suite.repo.Update(context.Background(), u))
+	}
+
+	employee := user.Employee
+	users, err := suite.repo.List(context.Background(), repository.UserFilter{Role: &employee, Search: "AR", SortBy: "name", SortOrder: "desc", Limit: 10})
+	suite.NoError(err)
+	// Marta matches the search but is an employer
+	suite.Equal([]string{"carl", "Cara", "Bart"}, names(users))
+}
+
+func (suite *UserRepositoryTestSuite) seenAt(name string, at time.Time) *user.User {
+	u := suite.createUser(name)
+	suite.Require().NoError(suite.repo.TouchLastSeen(context.Background(), u.ID, at))
+	return u
+}
+
+func (suite *UserRepositoryTestSuite) TestListRecentlyActiveFiltersByWindow() {
+	now := time.Now().UTC()
+	suite.seenAt("earlier", now.Add(-10*time.Minute))
+	suite.seenAt("latest", now.Add(-time.Minute))
+	suite.seenAt("stale", now.Add(-time.Hour))
+	suite.createUser("never")
+
+	users, err := suite.repo.ListRecentlyActive(context.Background(), now.Add(-15*time.Minute), 10)
+	suite.NoError(err)
+	suite.Equal([]string{"latest", "earlier"}, names(users))
+}
+
+func (suite *UserRepositoryTestSuite) TestListRecentlyActiveIncludesBoundary() {
+	now := time.Now().UTC()
+	suite.seenAt("edge", now.Add(-15*time.Minute))
+
+	users, err := suite.repo.ListRecentlyActive(context.Background(), now.Add(-15*time.Minute), 10)
+	suite.NoError(err)
+	suite.Equal([]string{"edge"}, names(users))
+}
+
+func (suite *UserRepositoryTestSuite) TestListRecentlyActiveAppliesLimit() {
+	now := time.Now().UTC()
+	suite.seenAt("first", now.Add(-3*time.Minute))
+	suite.seenAt("second", now.Add(-2*time.Minute))
+	suite.seenAt("third", now.Add(-time.Minute))
+
+	users, err := suite.repo.ListRecentlyActive(context.Background(), now.Add(-time.Hour), 2)
+	suite.NoError(err)
+	suite.Equal([]string{"third", "second"}, names(users))
+}
+
+func (suite *UserRepositoryTestSuite) TestTouchLastSeenLeavesUpdatedAt() {
+	u := suite.createUser("quiet")
+	suite.Require().NoError(suite.repo.TouchLastSeen(context.Background(), u.ID, time.Now().Add(time.Hour)))
+
+	stored, err := suite.repo.GetByID(context.Background(), u.ID)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(stored.LastSeenAt)
+	suite.True(stored.UpdatedAt.Equal(u.UpdatedAt))
+}
+
+func TestUserRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(UserRepositoryTestSuite))
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import "strings"
+
+// likeEscaper keeps LIKE wildcards typed by the caller from matching anything
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// EscapeLike escapes s for use in a LIKE pattern with ESCAPE '\'
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// validSortOrder reports whether order may be used as an ORDER BY direction.
+// Empty leaves the choice to the repository.
+func validSortOrder(order string) bool {
+	switch order {
+	case "", "asc", "desc":
+		return true
+	default:
+		return false
+	}
+}
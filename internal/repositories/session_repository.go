@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain/user"
+)
+
+// SessionRepository defines the interface for session persistence operations
+type SessionRepository interface {
+	// Create stores a new session
+	Create(ctx context.Context, session *user.Session) error
+
+	// GetByID retrieves a session by ID, returning nil when it does not exist
+	GetByID(ctx context.Context, id uuid.UUID) (*user.Session, error)
+
+	// ListActive retrieves the user's sessions that expire after now, most
+	// recently used first
+	ListActive(ctx context.Context, userID uuid.UUID, now time.Time) ([]*user.Session, error)
+
+	// TouchLastUsed records that the session's refresh token was used
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+
+	// Delete removes a session
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// DeleteByUser removes all of a user's sessions
+	DeleteByUser(ctx context.Context, userID uuid.UUID) error
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain/task"
+)
+
+// TaskCommentRepository defines the interface for task comment persistence operations
+type TaskCommentRepository interface {
+	// Create stores a new comment
+	Create(ctx context.Context, comment *task.TaskComment) error
+
+	// GetByID retrieves a comment by ID, returning nil when it does not exist
+	GetByID(ctx context.Context, id uuid.UUID) (*task.TaskComment, error)
+
+	// ListByTask retrieves a page of a task's comments, oldest first
+	ListByTask(ctx context.Context, taskID uuid.UUID, limit, offset int) ([]*task.TaskComment, error)
+
+	// Delete removes a comment
+	Delete(ctx context.Context, id uuid.UUID) error
+}
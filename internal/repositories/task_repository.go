@@ -13,12 +13,26 @@ type TaskRepository interface {
 	// Create stores a new task in the repository
 	Create(ctx context.Context, task *task.Task) error
 
+	// CreateBatch stores all the tasks in one transaction, or none of them
+	CreateBatch(ctx context.Context, tasks []*task.Task) error
+
 	// GetByID retrieves a task by ID
 	GetByID(ctx context.Context, id uuid.UUID) (*task.Task, error)
 
 	// Update updates an existing task in the repository
 	Update(ctx context.Context, task *task.Task) error
 
+	// UpdateWithEvents saves the task and records the events describing the
+	// change in one transaction, or neither
+	UpdateWithEvents(ctx context.Context, task *task.Task, events []*task.TaskEvent) error
+
+	// UpdateWithComment saves the task, stores the comment and records the
+	// events in one transaction, or none of them
+	UpdateWithComment(ctx context.Context, task *task.Task, comment *task.TaskComment, events []*task.TaskEvent) error
+
+	// ListEvents retrieves a task's history, oldest first
+	ListEvents(ctx context.Context, taskID uuid.UUID) ([]*task.TaskEvent, error)
+
 	// Delete removes a task from the repository
 	Delete(ctx context.Context, id uuid.UUID) error
 
@@ -34,16 +48,61 @@ type TaskRepository interface {
 	// FindByDueDateRange retrieves tasks with due dates in a given range
 	FindByDueDateRange(ctx context.Context, start, end time.Time) ([]*task.Task, error)
 
+	// FindOverdue retrieves tasks due before asOf that are not completed, most
+	// overdue first. A non-nil assigneeID limits the result to that assignee.
+	FindOverdue(ctx context.Context, asOf time.Time, assigneeID *uuid.UUID) ([]*task.Task, error)
+
+	// FindRecurrencesDue retrieves completed recurring tasks that have not
+	// spawned their next occurrence yet
+	FindRecurrencesDue(ctx context.Context) ([]*task.Task, error)
+
+	// CreateNextOccurrence stores next and links it from previous in one
+	// transaction. It returns false without storing anything when previous
+	// already has a next occurrence.
+	CreateNextOccurrence(ctx context.Context, previous, next *task.Task) (bool, error)
+
 	// List retrieves all tasks with optional filtering and sorting
 	List(ctx context.Context, filter TaskFilter) ([]*task.Task, error)
+
+	// AddTag attaches a tag to a task, creating the tag if it does not exist yet.
+	// It returns the stored tag.
+	AddTag(ctx context.Context, taskID uuid.UUID, tag *task.Tag) (*task.Tag, error)
+
+	// RemoveTag detaches the named tag from a task
+	RemoveTag(ctx context.Context, taskID uuid.UUID, name string) error
+
+	// ListTags retrieves a task's tags ordered by name
+	ListTags(ctx context.Context, taskID uuid.UUID) ([]*task.Tag, error)
 }
 
 // TaskFilter defines filtering and sorting options for tasks
 type TaskFilter struct {
-	AssigneeID *uuid.UUID   `json:"assignee_id,omitempty"`
-	Status     *task.Status `json:"status,omitempty"`
-	SortBy     string       `json:"sort_by,omitempty"`    // Options: "due_date", "status", "created_at"
-	SortOrder  string       `json:"sort_order,omitempty"` // Options: "asc", "desc"
-	Offset     int          `json:"offset,omitempty"`
-	Limit      int          `json:"limit,omitempty"`
+	AssigneeID *uuid.UUID    `json:"assignee_id,omitempty"`
+	Status     *task.Status  `json:"status,omitempty"`
+	Statuses   []task.Status `json:"statuses,omitempty"`   // Matches any of the listed statuses
+	Tags       []string      `json:"tags,omitempty"`       // Matches tasks with all of the listed tags
+	AnyTag     bool          `json:"any_tag,omitempty"`    // Match tasks with any rather than all of Tags
+	SortBy     string        `json:"sort_by,omitempty"`    // Options: "created_at", "updated_at", "due_date", "title", "status"
+	SortOrder  string        `json:"sort_order,omitempty"` // Options: "asc", "desc"
+	Offset     int           `json:"offset,omitempty"`
+	Limit      int           `json:"limit,omitempty"`
+}
+
+// sortableTaskFields are the columns tasks may be ordered by
+var sortableTaskFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"due_date":   true,
+	"title":      true,
+	"status":     true,
+}
+
+// ValidateSort rejects sort fields and orders outside the allowlist with
+// task.ErrInvalidSort. Both end up in ORDER BY, which can't be parameterized,
+// so repositories must call it before building the query.
+func (f TaskFilter) ValidateSort() error {
+	if (f.SortBy != "" && !sortableTaskFields[f.SortBy]) || !validSortOrder(f.SortOrder) {
+		return task.ErrInvalidSort
+	}
+	return nil
 }
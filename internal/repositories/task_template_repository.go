@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain/task"
+)
+
+// TaskTemplateRepository defines the interface for task template persistence operations
+type TaskTemplateRepository interface {
+	// Create stores a new template
+	Create(ctx context.Context, template *task.TaskTemplate) error
+
+	// GetByID retrieves a template by ID, returning nil when it does not exist
+	GetByID(ctx context.Context, id uuid.UUID) (*task.TaskTemplate, error)
+
+	// List retrieves a page of templates ordered by title
+	List(ctx context.Context, limit, offset int) ([]*task.TaskTemplate, error)
+
+	// Update saves changes to an existing template
+	Update(ctx context.Context, template *task.TaskTemplate) error
+
+	// Delete removes a template
+	Delete(ctx context.Context, id uuid.UUID) error
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/personal/task-management/internal/domain/user"
@@ -12,10 +13,12 @@ type UserRepository interface {
 	// Create stores a new user in the repository
 	Create(ctx context.Context, user *user.User) error
 
-	// GetByID retrieves a user by ID
+	// GetByID retrieves a user by ID, failing with user.ErrUserNotFound when
+	// there is none
 	GetByID(ctx context.Context, id uuid.UUID) (*user.User, error)
 
-	// GetByEmail retrieves a user by email
+	// GetByEmail retrieves a user by email, failing with user.ErrUserNotFound
+	// when there is none
 	GetByEmail(ctx context.Context, email string) (*user.User, error)
 
 	// Update updates an existing user in the repository
@@ -24,6 +27,50 @@ type UserRepository interface {
 	// Delete removes a user from the repository
 	Delete(ctx context.Context, id uuid.UUID) error
 
-	// List retrieves all users with optional pagination
-	List(ctx context.Context, offset, limit int) ([]*user.User, error)
+	// List retrieves users matching the filter with optional pagination
+	List(ctx context.Context, filter UserFilter) ([]*user.User, error)
+
+	// Count counts the users matching the filter, ignoring its sort and pagination
+	Count(ctx context.Context, filter UserFilter) (int64, error)
+
+	// SearchByNamePrefix retrieves up to limit users whose name starts with prefix,
+	// ignoring case. When sharedWith is set only users sharing a chat room with
+	// that user are returned
+	SearchByNamePrefix(ctx context.Context, prefix string, limit int, sharedWith *uuid.UUID) ([]*user.User, error)
+
+	// TouchLastSeen records that the user was active at the given time
+	TouchLastSeen(ctx context.Context, id uuid.UUID, at time.Time) error
+
+	// ListRecentlyActive retrieves up to limit users seen at or after since,
+	// most recently seen first
+	ListRecentlyActive(ctx context.Context, since time.Time, limit int) ([]*user.User, error)
+}
+
+// UserFilter defines filtering and sorting for listing users
+type UserFilter struct {
+	// Search matches a substring of the name or email, ignoring case
+	Search    string       `json:"search,omitempty"`
+	Role      *user.Role   `json:"role,omitempty"`
+	Status    *user.Status `json:"status,omitempty"`
+	SortBy    string       `json:"sort_by,omitempty"`    // Options: "name", "email", "role", "created_at"
+	SortOrder string       `json:"sort_order,omitempty"` // Options: "asc", "desc"
+	Offset    int          `json:"offset,omitempty"`
+	Limit     int          `json:"limit,omitempty"`
+}
+
+// sortableUserFields are the columns users may be ordered by
+var sortableUserFields = map[string]bool{
+	"name":       true,
+	"email":      true,
+	"role":       true,
+	"created_at": true,
+}
+
+// ValidateSort rejects sort fields and orders outside the allowlist with
+// user.ErrInvalidSort; see TaskFilter.ValidateSort
+func (f UserFilter) ValidateSort() error {
+	if (f.SortBy != "" && !sortableUserFields[f.SortBy]) || !validSortOrder(f.SortOrder) {
+		return user.ErrInvalidSort
+	}
+	return nil
 }
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -11,54 +12,93 @@ import (
 	"github.com/personal/task-management/internal/delivery/rest/handler"
 	"github.com/personal/task-management/internal/delivery/rest/middleware"
 	"github.com/personal/task-management/internal/delivery/websocket"
+	repository "github.com/personal/task-management/internal/repositories"
+	"github.com/personal/task-management/internal/usecase"
 	httpserver "github.com/personal/task-management/pkg/server/http-server"
 	"github.com/personal/task-management/pkg/utils/jwt"
 )
 
 // ServerDependencies holds all dependencies required for the server.
 type ServerDependencies struct {
-	UserHandler      *handler.UserHandler
-	TaskHandler      *handler.TaskHandler
-	AuthHandler      *handler.AuthHandler
-	ChatHandler      *handler.ChatHandler
-	JWTService       jwt.JWTTokenServicer
-	RBACService      middleware.CasbinRBACService
-	WebSocketHandler *websocket.Handler
+	UserHandler         *handler.UserHandler
+	TaskHandler         *handler.TaskHandler
+	TaskTemplateHandler *handler.TaskTemplateHandler
+	AuthHandler         *handler.AuthHandler
+	ChatHandler         *handler.ChatHandler
+	UploadHandler       *handler.UploadHandler
+	NotificationHandler *handler.NotificationHandler
+	AdminHandler        *handler.AdminHandler
+	PermissionHandler   *handler.PermissionHandler
+	JWTService          jwt.JWTTokenServicer
+	RBACService         middleware.CasbinRBACService
+	WebSocketHandler    *websocket.Handler
+	Logger              *slog.Logger
+	CORS                func(http.Handler) http.Handler
+	// Auth authenticates requests, built from JWTService by NewHTTPServer
+	Auth func(http.Handler) http.HandlerFunc
+	// TaskOwnership keeps employees to their own tasks on /tasks/{id}
+	TaskOwnership func(http.Handler) http.HandlerFunc
 }
 
-func NewHTTPServer(cfg *viper.Viper, userHandler *handler.UserHandler, taskHandler *handler.TaskHandler, authHandler *handler.AuthHandler, rbacService middleware.CasbinRBACService, wsHandler *websocket.Handler, chatHandler *handler.ChatHandler) *httpserver.Server {
+func NewHTTPServer(cfg *viper.Viper, log *slog.Logger, userService usecase.UserService, taskRepo repository.TaskRepository, userHandler *handler.UserHandler, taskHandler *handler.TaskHandler, taskTemplateHandler *handler.TaskTemplateHandler, authHandler *handler.AuthHandler, jwtService jwt.JWTTokenServicer, rbacService middleware.CasbinRBACService, wsHandler *websocket.Handler, chatHandler *handler.ChatHandler, uploadHandler *handler.UploadHandler, notificationHandler *handler.NotificationHandler, adminHandler *handler.AdminHandler, permissionHandler *handler.PermissionHandler) *httpserver.Server {
 	host := cfg.GetString("server.host")
 	port := cfg.GetInt("server.port")
 
-	jwtService := jwt.NewJWTTokenService(cfg)
-
 	dependencies := &ServerDependencies{
-		UserHandler:      userHandler,
-		TaskHandler:      taskHandler,
-		AuthHandler:      authHandler,
-		ChatHandler:      chatHandler,
-		JWTService:       jwtService,
-		RBACService:      rbacService,
-		WebSocketHandler: wsHandler,
+		UserHandler:         userHandler,
+		TaskHandler:         taskHandler,
+		TaskTemplateHandler: taskTemplateHandler,
+		AuthHandler:         authHandler,
+		ChatHandler:         chatHandler,
+		UploadHandler:       uploadHandler,
+		NotificationHandler: notificationHandler,
+		AdminHandler:        adminHandler,
+		PermissionHandler:   permissionHandler,
+		JWTService:          jwtService,
+		RBACService:         rbacService,
+		WebSocketHandler:    wsHandler,
+		Logger:              log,
+		CORS:                middleware.CORS(cfg),
+		Auth: middleware.AuthMiddleware(jwtService,
+			middleware.WithStatusCheck(userService, cfg.GetDuration("auth.status_check_after")),
+		),
+		TaskOwnership: middleware.TaskOwnership(taskRepo),
 	}
 
 	r := SetupRoutes(dependencies)
-	return httpserver.NewServer(r, httpserver.WithServerHost(host), httpserver.WithServerPort(port))
+	return httpserver.NewServer(r,
+		httpserver.WithServerHost(host),
+		httpserver.WithServerPort(port),
+		httpserver.WithShutdownTimeout(cfg.GetDuration("server.shutdown_timeout")),
+	)
 }
 
 // SetupRoutes initializes all application routes.
 func SetupRoutes(deps *ServerDependencies) *chi.Mux {
 	r := chi.NewRouter()
+	// Recover sits inside Logging so a panic is logged as a 500 with its
+	// request ID, and ahead of every route's own middleware. CORS answers
+	// preflights before authentication, which browsers never send them with.
+	r.Use(middleware.RequestID, middleware.Logging(deps.Logger), middleware.Recover, deps.CORS)
 	r.Get("/health", healthCheck)
 	r.Mount("/swagger", httpSwagger.WrapHandler)
 
 	r.HandleFunc("/ws", deps.WebSocketHandler.HandleWebSocket)
+	r.Get("/ws/heartbeat", deps.WebSocketHandler.Heartbeat)
+
+	// Attachments are public to anyone holding their unguessable URL, like a CDN
+	if files := deps.UploadHandler.Files(); files != nil {
+		r.Handle("/uploads/*", http.StripPrefix("/uploads", files))
+	}
 
 	r.Route("/api", func(r chi.Router) {
 		authRoutes(r, deps)
 		userRoutes(r, deps)
+		meRoutes(r, deps)
 		taskRoutes(r, deps)
 		chatRoutes(r, deps)
+		notificationRoutes(r, deps)
+		adminRoutes(r, deps)
 	})
 
 	return r
@@ -68,24 +108,67 @@ func authRoutes(router chi.Router, deps *ServerDependencies) {
 	router.Route("/auth", func(r chi.Router) {
 		r.Post("/register", deps.AuthHandler.RegisterUser)
 		r.Post("/login", deps.AuthHandler.Login)
+		r.Post("/refresh", deps.AuthHandler.RefreshToken)
+		// Logout only needs a valid token; every role may revoke its own
+		r.Post("/logout", middleware.Use(deps.AuthHandler.Logout, deps.Auth))
+		// Likewise every role manages its own sessions
+		r.Get("/sessions", middleware.Use(deps.AuthHandler.ListSessions, deps.Auth))
+		r.Delete("/sessions/{id}", middleware.Use(deps.AuthHandler.RevokeSession, deps.Auth))
 	})
 }
 
 func userRoutes(router chi.Router, deps *ServerDependencies) {
 	router.Route("/users", func(r chi.Router) {
 		r.Get("/", applyMiddlewares(deps.UserHandler.ListUsers, deps))
+		r.Get("/search", applyMiddlewares(deps.UserHandler.SearchUsers, deps))
+		r.Get("/active", applyMiddlewares(deps.UserHandler.ListActiveUsers, deps))
+		// Everyone may read their own profile and change their own password,
+		// so RBAC is not applied
+		r.Get("/me", middleware.Use(deps.UserHandler.GetCurrentUser, deps.Auth))
+		r.Post("/me/password", middleware.Use(deps.UserHandler.ChangePassword, deps.Auth))
 		r.Get("/{id}", applyMiddlewares(deps.UserHandler.GetUser, deps))
 		r.Put("/{id}", applyMiddlewares(deps.UserHandler.UpdateUser, deps))
+		r.Delete("/{id}", applyMiddlewares(deps.UserHandler.DeleteUser, deps))
+	})
+}
+
+func meRoutes(router chi.Router, deps *ServerDependencies) {
+	router.Route("/me", func(r chi.Router) {
+		// Every role may read its own permissions, so RBAC is not applied
+		r.Get("/permissions", middleware.Use(deps.PermissionHandler.GetMyPermissions, deps.Auth))
 	})
 }
 
 func taskRoutes(router chi.Router, deps *ServerDependencies) {
 	router.Route("/tasks", func(r chi.Router) {
 		r.Post("/", applyMiddlewares(deps.TaskHandler.Create, deps))
+		r.Post("/bulk", applyMiddlewares(deps.TaskHandler.CreateBulk, deps))
 		r.Get("/", applyMiddlewares(deps.TaskHandler.List, deps))
-		r.Get("/{id}", applyMiddlewares(deps.TaskHandler.Get, deps))
-		r.Put("/{id}", applyMiddlewares(deps.TaskHandler.Update, deps))
+		r.Get("/overdue", applyMiddlewares(deps.TaskHandler.ListOverdue, deps))
+		r.Get("/{id}", applyMiddlewares(deps.TaskHandler.Get, deps, deps.TaskOwnership))
+		r.Put("/{id}", applyMiddlewares(deps.TaskHandler.Update, deps, deps.TaskOwnership))
+		r.Patch("/{id}", applyMiddlewares(deps.TaskHandler.Edit, deps, deps.TaskOwnership))
+		r.Put("/{id}/assignee", applyMiddlewares(deps.TaskHandler.Reassign, deps))
+		r.Get("/{id}/history", applyMiddlewares(deps.TaskHandler.History, deps, deps.TaskOwnership))
 		r.Delete("/{id}", applyMiddlewares(deps.TaskHandler.Delete, deps))
+
+		// Comments
+		r.Post("/{id}/comments", applyMiddlewares(deps.TaskHandler.AddComment, deps))
+		r.Get("/{id}/comments", applyMiddlewares(deps.TaskHandler.ListComments, deps))
+		r.Delete("/{id}/comments/{commentId}", applyMiddlewares(deps.TaskHandler.DeleteComment, deps))
+
+		// Tags
+		r.Post("/{id}/tags", applyMiddlewares(deps.TaskHandler.AddTag, deps))
+		r.Get("/{id}/tags", applyMiddlewares(deps.TaskHandler.ListTags, deps))
+		r.Delete("/{id}/tags/{tag}", applyMiddlewares(deps.TaskHandler.RemoveTag, deps))
+
+		// Templates
+		r.Post("/templates", applyMiddlewares(deps.TaskTemplateHandler.Create, deps))
+		r.Get("/templates", applyMiddlewares(deps.TaskTemplateHandler.List, deps))
+		r.Get("/templates/{templateId}", applyMiddlewares(deps.TaskTemplateHandler.Get, deps))
+		r.Put("/templates/{templateId}", applyMiddlewares(deps.TaskTemplateHandler.Update, deps))
+		r.Delete("/templates/{templateId}", applyMiddlewares(deps.TaskTemplateHandler.Delete, deps))
+		r.Post("/from-template/{templateId}", applyMiddlewares(deps.TaskTemplateHandler.CreateTask, deps))
 	})
 }
 
@@ -95,32 +178,70 @@ func chatRoutes(router chi.Router, deps *ServerDependencies) {
 		r.Post("/rooms/direct", applyMiddlewares(deps.ChatHandler.CreateDirectRoom, deps))
 		r.Post("/rooms/group", applyMiddlewares(deps.ChatHandler.CreateGroupRoom, deps))
 		r.Get("/rooms", applyMiddlewares(deps.ChatHandler.ListRooms, deps))
-		r.Get("/rooms/{roomId}", applyMiddlewares(deps.ChatHandler.GetRoomHistory, deps))
+		r.Get("/rooms/{roomId}", applyMiddlewares(deps.ChatHandler.GetRoom, deps))
+		r.Get("/rooms/{roomId}/history", applyMiddlewares(deps.ChatHandler.GetRoomHistory, deps))
 		r.Post("/rooms/{roomId}/join", applyMiddlewares(deps.ChatHandler.JoinRoom, deps))
 		r.Post("/rooms/{roomId}/leave", applyMiddlewares(deps.ChatHandler.LeaveRoom, deps))
 		r.Put("/rooms/{roomId}", applyMiddlewares(deps.ChatHandler.UpdateRoom, deps))
+		r.Delete("/rooms/{roomId}", applyMiddlewares(deps.ChatHandler.DeleteRoom, deps))
+		r.Get("/unread-counts", applyMiddlewares(deps.ChatHandler.GetUnreadCounts, deps))
+		r.Get("/search", applyMiddlewares(deps.ChatHandler.SearchMessages, deps))
+		r.Post("/upload", applyMiddlewares(deps.UploadHandler.Upload, deps))
 
 		// Message management
 		r.Get("/rooms/{roomId}/messages", applyMiddlewares(deps.ChatHandler.GetMessages, deps))
 		r.Post("/rooms/{roomId}/messages", applyMiddlewares(deps.ChatHandler.SendMessage, deps))
+		r.Post("/direct/{userId}/messages", applyMiddlewares(deps.ChatHandler.SendDirectMessage, deps))
+		r.Get("/direct/{userId}/room-id", applyMiddlewares(deps.ChatHandler.GetDirectRoomID, deps))
 		r.Post("/rooms/{roomId}/messages/{messageId}/read", applyMiddlewares(deps.ChatHandler.MarkMessageAsRead, deps))
+		r.Get("/rooms/{roomId}/messages/{messageId}/read-receipts", applyMiddlewares(deps.ChatHandler.GetReadReceipts, deps))
 		r.Post("/rooms/{roomId}/messages/{messageId}/pin", applyMiddlewares(deps.ChatHandler.PinMessage, deps))
 		r.Delete("/rooms/{roomId}/messages/{messageId}/pin", applyMiddlewares(deps.ChatHandler.UnpinMessage, deps))
+		r.Get("/rooms/{roomId}/pinned", applyMiddlewares(deps.ChatHandler.GetPinnedMessages, deps))
 
 		// Room actions
 		r.Post("/rooms/{roomId}/archive", applyMiddlewares(deps.ChatHandler.ArchiveRoom, deps))
 		r.Post("/rooms/{roomId}/unarchive", applyMiddlewares(deps.ChatHandler.UnarchiveRoom, deps))
 		r.Post("/rooms/{roomId}/mute", applyMiddlewares(deps.ChatHandler.MuteRoom, deps))
 		r.Post("/rooms/{roomId}/unmute", applyMiddlewares(deps.ChatHandler.UnmuteRoom, deps))
+		r.Get("/rooms/{roomId}/members", applyMiddlewares(deps.ChatHandler.ListMembers, deps))
+		r.Post("/rooms/{roomId}/members", applyMiddlewares(deps.ChatHandler.AddMember, deps))
+		r.Delete("/rooms/{roomId}/members/{userId}", applyMiddlewares(deps.ChatHandler.RemoveMember, deps))
+		r.Post("/rooms/{roomId}/members/{userId}/promote", applyMiddlewares(deps.ChatHandler.PromoteMember, deps))
+		r.Post("/rooms/{roomId}/members/{userId}/demote", applyMiddlewares(deps.ChatHandler.DemoteMember, deps))
+		r.Post("/rooms/{roomId}/members/{userId}/mute", applyMiddlewares(deps.ChatHandler.MuteMember, deps))
+		r.Delete("/rooms/{roomId}/members/{userId}/mute", applyMiddlewares(deps.ChatHandler.UnmuteMember, deps))
 	})
 }
 
-// applyMiddlewares wraps a handler with authentication and authorization.
-func applyMiddlewares(handlerFunc http.HandlerFunc, deps *ServerDependencies) http.HandlerFunc {
-	return middleware.Use(handlerFunc,
-		middleware.AuthMiddleware(deps.JWTService),
+func notificationRoutes(router chi.Router, deps *ServerDependencies) {
+	router.Route("/notifications", func(r chi.Router) {
+		r.Get("/", applyMiddlewares(deps.NotificationHandler.ListNotifications, deps))
+		r.Get("/unread-count", applyMiddlewares(deps.NotificationHandler.GetUnreadCount, deps))
+		r.Post("/{id}/read", applyMiddlewares(deps.NotificationHandler.MarkAsRead, deps))
+		r.Get("/preferences", applyMiddlewares(deps.NotificationHandler.ListPreferences, deps))
+		r.Put("/preferences", applyMiddlewares(deps.NotificationHandler.SetPreference, deps))
+	})
+}
+
+func adminRoutes(router chi.Router, deps *ServerDependencies) {
+	router.Route("/admin", func(r chi.Router) {
+		r.Get("/chat-stats", applyMiddlewares(deps.ChatHandler.GetChatStats, deps))
+		r.Put("/chat/rooms/{roomId}/slow-mode", applyMiddlewares(deps.ChatHandler.SetRoomSlowMode, deps))
+		r.Get("/policies", applyMiddlewares(deps.AdminHandler.ListPolicies, deps))
+		r.Post("/policies", applyMiddlewares(deps.AdminHandler.AddPolicy, deps))
+		r.Delete("/policies", applyMiddlewares(deps.AdminHandler.RemovePolicy, deps))
+		r.Post("/policies/reload", applyMiddlewares(deps.AdminHandler.ReloadPolicies, deps))
+	})
+}
+
+// applyMiddlewares wraps a handler with authentication and authorization,
+// followed by any extra checks the route needs.
+func applyMiddlewares(handlerFunc http.HandlerFunc, deps *ServerDependencies, extra ...func(http.Handler) http.HandlerFunc) http.HandlerFunc {
+	return middleware.Use(handlerFunc, append([]func(http.Handler) http.HandlerFunc{
+		deps.Auth,
 		middleware.AuthorizationMiddleware(deps.JWTService, deps.RBACService),
-	)
+	}, extra...)...)
 }
 
 func healthCheck(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain"
+	repository "github.com/personal/task-management/internal/repositories"
+)
+
+// Notifier delivers a notification to a user outside of the WebSocket hub
+type Notifier interface {
+	Notify(ctx context.Context, userID string, notification *domain.Notification) error
+}
+
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+type noopNotifier struct{}
+
+// NewNoopNotifier returns a Notifier that drops every notification
+func NewNoopNotifier() Notifier {
+	return noopNotifier{}
+}
+
+func (noopNotifier) Notify(ctx context.Context, userID string, notification *domain.Notification) error {
+	return nil
+}
+
+type websocketNotifier struct {
+	hub *domain.Hub
+}
+
+// NewWebSocketNotifier returns a Notifier that pushes notifications to the
+// user's live connection through the hub
+func NewWebSocketNotifier(hub *domain.Hub) Notifier {
+	return &websocketNotifier{hub: hub}
+}
+
+func (n *websocketNotifier) Notify(ctx context.Context, userID string, notification *domain.Notification) error {
+	message := domain.WebSocketMessage{
+		Type:      notification.Type,
+		ID:        notification.ID,
+		UserID:    userID,
+		TargetID:  userID,
+		Content:   notification.Content,
+		Timestamp: time.Now(),
+	}
+
+	select {
+	case n.hub.DirectMessage <- message:
+		return nil
+	case <-n.hub.Done:
+		return domain.ErrHubStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// EventNotificationCreated is the webhook event fired for each notification
+// delivered over the webhook channel
+const EventNotificationCreated = "notification.created"
+
+// NotificationWebhookPayload is the data of an EventNotificationCreated event
+type NotificationWebhookPayload struct {
+	UserID       string               `json:"user_id"`
+	Notification *domain.Notification `json:"notification"`
+}
+
+type webhookNotifier struct {
+	dispatcher WebhookDispatcher
+}
+
+// NewWebhookNotifier returns a Notifier that forwards notifications to the
+// configured webhook endpoints
+func NewWebhookNotifier(dispatcher WebhookDispatcher) Notifier {
+	return &webhookNotifier{dispatcher: dispatcher}
+}
+
+// Notify hands the notification to the dispatcher in the background, since
+// deliveries are retried with backoff. Failures are logged.
+func (n *webhookNotifier) Notify(ctx context.Context, userID string, notification *domain.Notification) error {
+	payload := NotificationWebhookPayload{UserID: userID, Notification: notification}
+	go func() {
+		if err := n.dispatcher.Dispatch(context.Background(), EventNotificationCreated, payload); err != nil {
+			log.Printf("error dispatching %s webhook for notification %s: %v", EventNotificationCreated, notification.ID, err)
+		}
+	}()
+	return nil
+}
+
+type emailNotifier struct {
+	userRepo repository.UserRepository
+	mailer   Mailer
+}
+
+// NewEmailNotifier returns a Notifier that emails the notification to the user's address
+func NewEmailNotifier(userRepo repository.UserRepository, mailer Mailer) Notifier {
+	return &emailNotifier{
+		userRepo: userRepo,
+		mailer:   mailer,
+	}
+}
+
+func (n *emailNotifier) Notify(ctx context.Context, userID string, notification *domain.Notification) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return err
+	}
+
+	u, err := n.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return n.mailer.Send(u.Email, notification.Title, notification.Content)
+}
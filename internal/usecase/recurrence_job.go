@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/personal/task-management/pkg/features"
+	"github.com/spf13/viper"
+)
+
+const defaultRecurrenceInterval = time.Minute
+
+// RecurrenceJob periodically spawns the next occurrence of completed recurring
+// tasks. It satisfies server.Server so the app starts and stops it alongside
+// the HTTP server.
+type RecurrenceJob struct {
+	taskService TaskService
+	interval    time.Duration
+	enabled     bool
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// NewRecurrenceJob creates a job that runs every tasks.recurrence_interval
+// while the recurring_tasks feature is enabled
+func NewRecurrenceJob(cfg *viper.Viper, flags *features.Flags, taskService TaskService) *RecurrenceJob {
+	interval := cfg.GetDuration("tasks.recurrence_interval")
+	if interval <= 0 {
+		interval = defaultRecurrenceInterval
+	}
+
+	return &RecurrenceJob{
+		taskService: taskService,
+		interval:    interval,
+		enabled:     flags.Enabled(features.RecurringTasks),
+	}
+}
+
+// Start runs the job in the background and returns immediately
+func (j *RecurrenceJob) Start(ctx context.Context) error {
+	if !j.enabled {
+		log.Println("recurring tasks are disabled")
+		return nil
+	}
+	ctx, j.cancel = context.WithCancel(ctx)
+	j.done = make(chan struct{})
+	go j.run(ctx)
+	return nil
+}
+
+// Stop cancels the job and waits for the current run to finish
+func (j *RecurrenceJob) Stop(ctx context.Context) error {
+	if j.cancel == nil {
+		return nil
+	}
+	j.cancel()
+	<-j.done
+	return nil
+}
+
+func (j *RecurrenceJob) run(ctx context.Context) {
+	defer close(j.done)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		created, err := j.taskService.GenerateDueRecurrences(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("error generating recurring tasks: %v", err)
+		}
+		if created > 0 {
+			log.Printf("created %d recurring task occurrences", created)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
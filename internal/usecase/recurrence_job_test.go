@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/personal/task-management/pkg/features"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingTaskService records how often the job asks for recurrences
+type countingTaskService struct {
+	TaskService
+	calls atomic.Int32
+}
+
+func (s *countingTaskService) GenerateDueRecurrences(ctx context.Context) (int, error) {
+	s.calls.Add(1)
+	return 0, nil
+}
+
+func TestRecurrenceJobRunsUntilStopped(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("tasks.recurrence_interval", 10*time.Millisecond)
+	service := &countingTaskService{}
+	job := NewRecurrenceJob(cfg, features.NewFlags(cfg), service)
+
+	assert.NoError(t, job.Start(context.Background()))
+	assert.Eventually(t, func() bool { return service.calls.Load() >= 2 }, time.Second, 5*time.Millisecond)
+
+	assert.NoError(t, job.Stop(context.Background()))
+	calls := service.calls.Load()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, calls, service.calls.Load())
+
+	// Stopping twice is harmless since the app and its cleanup both stop servers
+	assert.NoError(t, job.Stop(context.Background()))
+}
+
+func TestRecurrenceJobSkippedWhenFeatureDisabled(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("tasks.recurrence_interval", time.Millisecond)
+	cfg.Set("features.recurring_tasks", false)
+	service := &countingTaskService{}
+	job := NewRecurrenceJob(cfg, features.NewFlags(cfg), service)
+
+	assert.NoError(t, job.Start(context.Background()))
+	time.Sleep(20 * time.Millisecond)
+	assert.Zero(t, service.calls.Load())
+	assert.NoError(t, job.Stop(context.Background()))
+}
@@ -2,38 +2,79 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/personal/task-management/internal/delivery/rest/dtos"
 	"github.com/personal/task-management/internal/domain/task"
+	"github.com/personal/task-management/internal/domain/user"
 	repository "github.com/personal/task-management/internal/repositories"
 	"github.com/personal/task-management/pkg/utils/validate"
 )
 
 type TaskService interface {
 	CreateTask(ctx context.Context, input dtos.CreateTaskInput) (*task.Task, error)
+	CreateTasksBulk(ctx context.Context, input dtos.CreateTasksBulkInput) (*dtos.CreateTasksBulkOutput, error)
 	UpdateTaskStatus(ctx context.Context, input dtos.UpdateTaskStatusInput) (*task.Task, error)
+	UpdateTaskDetails(ctx context.Context, input dtos.UpdateTaskDetailsInput) (*task.Task, error)
+	ReassignTask(ctx context.Context, input dtos.ReassignTaskInput) (*task.Task, error)
+	GetTaskHistory(ctx context.Context, taskID, requesterID uuid.UUID) ([]*task.TaskEvent, error)
 	GetTask(ctx context.Context, input dtos.GetTaskInput) (*task.Task, error)
 	GetEmployeeTasks(ctx context.Context, input dtos.GetEmployeeTasksInput) ([]*task.Task, error)
 	GetTasksWithFilter(ctx context.Context, input dtos.GetTasksWithFilterInput) ([]*task.Task, error)
+	GetOverdueTasks(ctx context.Context, userID uuid.UUID) ([]*task.Task, error)
+	GenerateDueRecurrences(ctx context.Context) (int, error)
 	GetTaskSummaryByEmployee(ctx context.Context, input dtos.GetTaskSummaryByEmployeeInput) ([]dtos.EmployeeTaskSummary, error)
 	DeleteTask(ctx context.Context, input dtos.DeleteTaskInput) error
+	AddComment(ctx context.Context, input dtos.AddCommentInput) (*task.TaskComment, error)
+	ListComments(ctx context.Context, input dtos.ListCommentsInput) ([]*task.TaskComment, error)
+	DeleteComment(ctx context.Context, input dtos.DeleteCommentInput) error
+	AddTag(ctx context.Context, input dtos.AddTagInput) (*task.Tag, error)
+	RemoveTag(ctx context.Context, input dtos.RemoveTagInput) error
+	ListTags(ctx context.Context, input dtos.ListTagsInput) ([]*task.Tag, error)
 }
 
+// WebhookDispatcher delivers task events to external integrations
+type WebhookDispatcher interface {
+	Dispatch(ctx context.Context, event string, data any) error
+}
+
+type noopWebhookDispatcher struct{}
+
+// NewNoopWebhookDispatcher returns a WebhookDispatcher that drops every event
+func NewNoopWebhookDispatcher() WebhookDispatcher {
+	return noopWebhookDispatcher{}
+}
+
+func (noopWebhookDispatcher) Dispatch(ctx context.Context, event string, data any) error {
+	return nil
+}
+
+// EventTaskStatusChanged is the webhook event fired after a status update
+const EventTaskStatusChanged = "task.status_changed"
+
 // TaskService handles task-related operations and business logic
 type taskService struct {
-	taskRepo  repository.TaskRepository
-	userRepo  repository.UserRepository
-	wsService WebSocketService
+	taskRepo    repository.TaskRepository
+	commentRepo repository.TaskCommentRepository
+	userRepo    repository.UserRepository
+	wsService   WebSocketService
+	webhooks    WebhookDispatcher
+	workflow    task.Workflow
 }
 
 // NewTaskService creates a new instance of TaskService
-func NewTaskService(taskRepo repository.TaskRepository, userRepo repository.UserRepository, wsService WebSocketService) TaskService {
+func NewTaskService(taskRepo repository.TaskRepository, commentRepo repository.TaskCommentRepository, userRepo repository.UserRepository, wsService WebSocketService, webhooks WebhookDispatcher, workflow task.Workflow) TaskService {
 	return &taskService{
-		taskRepo:  taskRepo,
-		userRepo:  userRepo,
-		wsService: wsService,
+		taskRepo:    taskRepo,
+		commentRepo: commentRepo,
+		userRepo:    userRepo,
+		wsService:   wsService,
+		webhooks:    webhooks,
+		workflow:    workflow,
 	}
 }
 
@@ -55,16 +96,10 @@ func (s *taskService) CreateTask(ctx context.Context, input dtos.CreateTaskInput
 		return nil, task.ErrUnauthorized
 	}
 
-	// Verify assignee exists
-	assignee, err := s.userRepo.GetByID(ctx, input.AssigneeID)
-	if err != nil {
+	if _, err := s.requireAssignee(ctx, input.AssigneeID); err != nil {
 		return nil, err
 	}
 
-	if !assignee.IsEmployee() {
-		return nil, task.ErrUnauthorized
-	}
-
 	// Create task
 	newTask, err := task.NewTask(
 		input.Title,
@@ -76,6 +111,11 @@ func (s *taskService) CreateTask(ctx context.Context, input dtos.CreateTaskInput
 	if err != nil {
 		return nil, err
 	}
+	if input.Recurrence != nil {
+		if err := newTask.SetRecurrence(*input.Recurrence); err != nil {
+			return nil, err
+		}
+	}
 
 	// Save task
 	if err := s.taskRepo.Create(ctx, newTask); err != nil {
@@ -83,7 +123,81 @@ func (s *taskService) CreateTask(ctx context.Context, input dtos.CreateTaskInput
 	}
 
 	// Broadcast task creation notification
-	s.wsService.SendTaskUpdateNotification(newTask.AssigneeID.String(), newTask.ID.String(), "Task created: "+newTask.Title, newTask.Status.String())
+	s.notifyTaskUpdate(newTask.ID, "Task created: "+newTask.Title, newTask.Status, newTask.AssigneeID)
+	s.notifySummaryChange()
+	return newTask, nil
+}
+
+// CreateTasksBulk validates each task on its own and stores the valid ones in
+// one transaction. Invalid tasks are reported by index rather than failing the
+// whole batch; an error is only returned when nothing could be attempted.
+func (s *taskService) CreateTasksBulk(ctx context.Context, input dtos.CreateTasksBulkInput) (*dtos.CreateTasksBulkOutput, error) {
+	if err := validate.Struct(input); err != nil {
+		return nil, err
+	}
+
+	creator, err := s.userRepo.GetByID(ctx, input.CreatorID)
+	if err != nil {
+		return nil, err
+	}
+	if !creator.CanCreateTasks() {
+		return nil, task.ErrUnauthorized
+	}
+
+	output := &dtos.CreateTasksBulkOutput{
+		Created: []*task.Task{},
+		Errors:  []dtos.BulkTaskError{},
+	}
+	// Batches usually assign to a handful of people, so look each up once
+	assignees := make(map[uuid.UUID]error)
+	for i, item := range input.Tasks {
+		item.CreatorID = input.CreatorID
+		newTask, err := s.buildBulkTask(ctx, item, assignees)
+		if err != nil {
+			output.Errors = append(output.Errors, dtos.BulkTaskError{Index: i, Error: err.Error()})
+			continue
+		}
+		output.Created = append(output.Created, newTask)
+	}
+
+	if err := s.taskRepo.CreateBatch(ctx, output.Created); err != nil {
+		return nil, err
+	}
+
+	for _, t := range output.Created {
+		s.notifyTaskUpdate(t.ID, "Task created: "+t.Title, t.Status, t.AssigneeID)
+	}
+	if len(output.Created) > 0 {
+		s.notifySummaryChange()
+	}
+	return output, nil
+}
+
+// buildBulkTask applies CreateTask's checks to one item of a bulk create.
+// assignees memoises the result of checking each assignee.
+func (s *taskService) buildBulkTask(ctx context.Context, input dtos.CreateTaskInput, assignees map[uuid.UUID]error) (*task.Task, error) {
+	if err := validate.Struct(input); err != nil {
+		return nil, err
+	}
+
+	assigneeErr, checked := assignees[input.AssigneeID]
+	if !checked {
+		_, assigneeErr = s.requireAssignee(ctx, input.AssigneeID)
+		assignees[input.AssigneeID] = assigneeErr
+	}
+	if assigneeErr != nil {
+		return nil, assigneeErr
+	}
+
+	newTask, err := task.NewTask(input.Title, input.Description, input.DueDate, input.CreatorID, input.AssigneeID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Recurrence != nil {
+		if err := newTask.SetRecurrence(*input.Recurrence); err != nil {
+			return nil, err
+		}
+	}
 	return newTask, nil
 }
 
@@ -101,31 +215,158 @@ func (s *taskService) UpdateTaskStatus(ctx context.Context, input dtos.UpdateTas
 		return nil, err
 	}
 
-	// Check authorization
-	if !u.CanUpdateTaskStatus() {
+	// Update status
+	before := *t
+	if err := s.changeStatus(t, u, input.NewStatus, input.Reason); err != nil {
+		return nil, err
+	}
+
+	// Save task along with its history
+	if err := s.taskRepo.UpdateWithEvents(ctx, t, task.Changes(&before, t, u.ID)); err != nil {
+		return nil, err
+	}
+
+	s.announceStatusChange(ctx, t, before.Status, u.ID)
+	return t, nil
+}
+
+// UpdateTaskDetails changes a task's title, description or due date. Only
+// employers, managers and the task's creator may edit it; its status is changed through
+// UpdateTaskStatus.
+func (s *taskService) UpdateTaskDetails(ctx context.Context, input dtos.UpdateTaskDetailsInput) (*task.Task, error) {
+	if err := validate.Struct(input); err != nil {
+		return nil, err
+	}
+
+	t, err := s.taskRepo.GetByID(ctx, input.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	requester, err := s.userRepo.GetByID(ctx, input.RequesterID)
+	if err != nil {
+		return nil, err
+	}
+	if !requester.CanManageAllTasks() && !t.IsCreatedBy(requester.ID) {
 		return nil, task.ErrUnauthorized
 	}
 
-	// Employees can only update tasks assigned to them
-	if u.IsEmployee() && !t.IsAssignedTo(input.UserID) {
+	if input.Title == nil && input.Description == nil && input.DueDate == nil {
+		return t, nil
+	}
+	before := *t
+	if err := t.Edit(input.Title, input.Description, input.DueDate); err != nil {
+		return nil, err
+	}
+	if err := s.taskRepo.UpdateWithEvents(ctx, t, task.Changes(&before, t, requester.ID)); err != nil {
+		return nil, err
+	}
+
+	s.notifyTaskUpdate(t.ID, "Task edited: "+t.Title, t.Status, t.AssigneeID, t.CreatorID)
+	return t, nil
+}
+
+// ReassignTask hands a task to another employee. Only employers, managers and
+// the task's creator may reassign it. The previous and new assignees are both notified.
+func (s *taskService) ReassignTask(ctx context.Context, input dtos.ReassignTaskInput) (*task.Task, error) {
+	if err := validate.Struct(input); err != nil {
+		return nil, err
+	}
+
+	t, err := s.taskRepo.GetByID(ctx, input.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	requester, err := s.userRepo.GetByID(ctx, input.RequesterID)
+	if err != nil {
+		return nil, err
+	}
+	if !requester.CanManageAllTasks() && !t.IsCreatedBy(requester.ID) {
 		return nil, task.ErrUnauthorized
 	}
 
-	// Update status
-	if err := t.UpdateStatus(input.NewStatus); err != nil {
+	assignee, err := s.requireAssignee(ctx, input.AssigneeID)
+	if err != nil {
 		return nil, err
 	}
 
-	// Save task
-	if err := s.taskRepo.Update(ctx, t); err != nil {
+	if t.IsAssignedTo(assignee.ID) {
+		return t, nil
+	}
+	before := *t
+	t.Reassign(assignee.ID)
+	if err := s.taskRepo.UpdateWithEvents(ctx, t, task.Changes(&before, t, requester.ID)); err != nil {
 		return nil, err
 	}
 
-	// Broadcast task update notification
-	s.wsService.SendTaskUpdateNotification(t.AssigneeID.String(), t.ID.String(), "Task updated: "+t.Title, t.Status.String())
+	s.notifyTaskUpdate(t.ID, "Task reassigned: "+t.Title, t.Status, before.AssigneeID, t.AssigneeID)
+	s.notifySummaryChange()
 	return t, nil
 }
 
+// requireAssignee loads the user a task is being assigned to, failing with
+// task.ErrInvalidAssignee unless they exist and can be assigned tasks
+func (s *taskService) requireAssignee(ctx context.Context, assigneeID uuid.UUID) (*user.User, error) {
+	assignee, err := s.userRepo.GetByID(ctx, assigneeID)
+	switch {
+	case errors.Is(err, user.ErrUserNotFound):
+		return nil, fmt.Errorf("assignee %s does not exist: %w", assigneeID, task.ErrInvalidAssignee)
+	case err != nil:
+		return nil, err
+	case !assignee.CanBeAssignedTasks():
+		return nil, fmt.Errorf("assignee %s: %w", assigneeID, task.ErrInvalidAssignee)
+	}
+	return assignee, nil
+}
+
+// changeStatus checks that the user may move the task to status and applies
+// the transition to t without saving it
+func (s *taskService) changeStatus(t *task.Task, u *user.User, status task.Status, reason string) error {
+	if !u.CanUpdateTaskStatus() {
+		return task.ErrUnauthorized
+	}
+
+	// Employees can only update tasks assigned to them
+	if u.IsEmployee() && !t.IsAssignedTo(u.ID) {
+		return task.ErrUnauthorized
+	}
+
+	if status == task.StatusBlocked {
+		return t.Block(s.workflow, reason, u.Role)
+	}
+	return t.UpdateStatus(s.workflow, status, u.Role)
+}
+
+// announceStatusChange tells webhooks, the assignee, the creator and online
+// employers that a saved task moved from previousStatus
+func (s *taskService) announceStatusChange(ctx context.Context, t *task.Task, previousStatus task.Status, changedBy uuid.UUID) {
+	// Notify external integrations without holding up the response
+	payload := dtos.TaskStatusChangedPayload{
+		TaskID:         t.ID,
+		Title:          t.Title,
+		PreviousStatus: previousStatus,
+		Status:         t.Status,
+		BlockReason:    t.BlockReason,
+		AssigneeID:     t.AssigneeID,
+		ChangedBy:      changedBy,
+		ChangedAt:      t.UpdatedAt,
+	}
+	go func() {
+		if err := s.webhooks.Dispatch(context.Background(), EventTaskStatusChanged, payload); err != nil {
+			log.Printf("error dispatching %s webhook for task %s: %v", EventTaskStatusChanged, payload.TaskID, err)
+		}
+	}()
+
+	// Let the assignee and creator know the status moved
+	title := "Task updated: " + t.Title
+	if t.Status == task.StatusBlocked {
+		title = fmt.Sprintf("Task blocked: %s (%s)", t.Title, t.BlockReason)
+	}
+	s.notifyTaskUpdate(t.ID, title, t.Status, t.AssigneeID, t.CreatorID)
+	s.notifySummaryChange()
+}
+
 // GetEmployeeTasks retrieves tasks assigned to an employee
 func (s *taskService) GetEmployeeTasks(ctx context.Context, input dtos.GetEmployeeTasksInput) ([]*task.Task, error) {
 	// Get requester
@@ -188,14 +429,71 @@ func (s *taskService) GetTasksWithFilter(ctx context.Context, input dtos.GetTask
 	if input.Filter.Status != "" {
 		filter.Status = &input.Filter.Status
 	}
+	if len(input.Filter.Statuses) > 0 {
+		filter.Statuses = input.Filter.Statuses
+	}
 	if input.Filter.AssigneeID != uuid.Nil {
 		filter.AssigneeID = &input.Filter.AssigneeID
 	}
+	for _, name := range input.Filter.Tags {
+		filter.Tags = append(filter.Tags, task.NormalizeTagName(name))
+	}
+	filter.AnyTag = input.Filter.AnyTag
 
 	// Get tasks with filter
 	return s.taskRepo.List(ctx, filter)
 }
 
+// GetOverdueTasks retrieves tasks past their due date that are not completed.
+// Employees only see overdue tasks assigned to them.
+func (s *taskService) GetOverdueTasks(ctx context.Context, userID uuid.UUID) ([]*task.Task, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var assigneeID *uuid.UUID
+	if !u.CanViewAllTasks() {
+		assigneeID = &u.ID
+	}
+	return s.taskRepo.FindOverdue(ctx, time.Now(), assigneeID)
+}
+
+// GenerateDueRecurrences creates the next occurrence of every completed
+// recurring task that has not spawned one yet. It returns how many tasks were
+// created and is safe to call from several schedulers at once.
+func (s *taskService) GenerateDueRecurrences(ctx context.Context) (int, error) {
+	due, err := s.taskRepo.FindRecurrencesDue(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	created := 0
+	for _, t := range due {
+		next, err := t.NextOccurrence(now)
+		if err != nil {
+			return created, err
+		}
+
+		ok, err := s.taskRepo.CreateNextOccurrence(ctx, t, next)
+		if err != nil {
+			return created, err
+		}
+		if !ok {
+			continue // Another run spawned it first
+		}
+		created++
+
+		s.notifyTaskUpdate(next.ID, "Task created: "+next.Title, next.Status, next.AssigneeID)
+	}
+
+	if created > 0 {
+		s.notifySummaryChange()
+	}
+	return created, nil
+}
+
 // GetTaskSummaryByEmployee retrieves a summary of tasks for all employees
 func (s *taskService) GetTaskSummaryByEmployee(ctx context.Context, input dtos.GetTaskSummaryByEmployeeInput) ([]dtos.EmployeeTaskSummary, error) {
 	// Get requester
@@ -210,7 +508,7 @@ func (s *taskService) GetTaskSummaryByEmployee(ctx context.Context, input dtos.G
 	}
 
 	// Get all employees
-	users, err := s.userRepo.List(ctx, 0, 1000) // Pagination would be better in a real system
+	users, err := s.userRepo.List(ctx, repository.UserFilter{Limit: 1000}) // Pagination would be better in a real system
 	if err != nil {
 		return nil, err
 	}
@@ -241,6 +539,8 @@ func (s *taskService) GetTaskSummaryByEmployee(ctx context.Context, input dtos.G
 				summary.PendingTasks++
 			case task.StatusInProgress:
 				summary.InProgressTasks++
+			case task.StatusBlocked:
+				summary.BlockedTasks++
 			case task.StatusCompleted:
 				summary.CompletedTasks++
 			}
@@ -270,6 +570,195 @@ func (s *taskService) DeleteTask(ctx context.Context, input dtos.DeleteTaskInput
 	}
 
 	// Broadcast task deletion notification
-	s.wsService.SendTaskUpdateNotification(u.ID.String(), input.TaskID.String(), fmt.Sprintf("Task deleted: %s", input.TaskID), task.StatusDeleted.String())
+	s.notifyTaskUpdate(input.TaskID, fmt.Sprintf("Task deleted: %s", input.TaskID), task.StatusDeleted, u.ID)
+	s.notifySummaryChange()
 	return nil
 }
+
+// AddComment adds a comment to a task. The assignee, the creator and any
+// employer may comment. A comment carrying a status also moves the task,
+// which takes the same permission as UpdateTaskStatus; the comment and the
+// transition are saved together or not at all.
+func (s *taskService) AddComment(ctx context.Context, input dtos.AddCommentInput) (*task.TaskComment, error) {
+	if err := validate.Struct(input); err != nil {
+		return nil, err
+	}
+
+	t, u, err := s.loadTaskParticipant(ctx, input.TaskID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !canDiscussTask(u, t) {
+		return nil, task.ErrUnauthorized
+	}
+
+	comment, err := task.NewTaskComment(t.ID, u.ID, input.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Status == nil {
+		if err := s.commentRepo.Create(ctx, comment); err != nil {
+			return nil, err
+		}
+		return comment, nil
+	}
+
+	before := *t
+	if err := s.changeStatus(t, u, *input.Status, comment.Content); err != nil {
+		return nil, err
+	}
+	if err := s.taskRepo.UpdateWithComment(ctx, t, comment, task.Changes(&before, t, u.ID)); err != nil {
+		return nil, err
+	}
+	s.announceStatusChange(ctx, t, before.Status, u.ID)
+	return comment, nil
+}
+
+// GetTaskHistory lists who changed what on a task, oldest first. Anyone who
+// may see every task can read it, as can the task's assignee and creator.
+func (s *taskService) GetTaskHistory(ctx context.Context, taskID, requesterID uuid.UUID) ([]*task.TaskEvent, error) {
+	t, u, err := s.loadTaskParticipant(ctx, taskID, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.CanViewAllTasks() && !t.IsAssignedTo(u.ID) && !t.IsCreatedBy(u.ID) {
+		return nil, task.ErrUnauthorized
+	}
+	return s.taskRepo.ListEvents(ctx, taskID)
+}
+
+// ListComments returns a page of a task's comments, oldest first
+func (s *taskService) ListComments(ctx context.Context, input dtos.ListCommentsInput) ([]*task.TaskComment, error) {
+	t, u, err := s.loadTaskParticipant(ctx, input.TaskID, input.RequesterID)
+	if err != nil {
+		return nil, err
+	}
+	if !canDiscussTask(u, t) {
+		return nil, task.ErrUnauthorized
+	}
+
+	return s.commentRepo.ListByTask(ctx, t.ID, input.Limit, input.Offset)
+}
+
+// DeleteComment removes a comment. Only its author or an employer may delete it.
+func (s *taskService) DeleteComment(ctx context.Context, input dtos.DeleteCommentInput) error {
+	u, err := s.userRepo.GetByID(ctx, input.RequesterID)
+	if err != nil {
+		return err
+	}
+
+	comment, err := s.commentRepo.GetByID(ctx, input.CommentID)
+	if err != nil {
+		return err
+	}
+	if comment == nil || comment.TaskID != input.TaskID {
+		return task.ErrCommentNotFound
+	}
+
+	if !u.IsEmployer() && !comment.IsWrittenBy(u.ID) {
+		return task.ErrUnauthorized
+	}
+
+	return s.commentRepo.Delete(ctx, comment.ID)
+}
+
+// AddTag labels a task. Any participant of the task may tag it.
+func (s *taskService) AddTag(ctx context.Context, input dtos.AddTagInput) (*task.Tag, error) {
+	if err := validate.Struct(input); err != nil {
+		return nil, err
+	}
+
+	t, u, err := s.loadTaskParticipant(ctx, input.TaskID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !canDiscussTask(u, t) {
+		return nil, task.ErrUnauthorized
+	}
+
+	tag, err := task.NewTag(input.Name)
+	if err != nil {
+		return nil, err
+	}
+	return s.taskRepo.AddTag(ctx, t.ID, tag)
+}
+
+// RemoveTag removes a label from a task. Removing a tag the task does not
+// have is not an error.
+func (s *taskService) RemoveTag(ctx context.Context, input dtos.RemoveTagInput) error {
+	t, u, err := s.loadTaskParticipant(ctx, input.TaskID, input.UserID)
+	if err != nil {
+		return err
+	}
+	if !canDiscussTask(u, t) {
+		return task.ErrUnauthorized
+	}
+
+	return s.taskRepo.RemoveTag(ctx, t.ID, task.NormalizeTagName(input.Name))
+}
+
+// ListTags returns a task's tags ordered by name
+func (s *taskService) ListTags(ctx context.Context, input dtos.ListTagsInput) ([]*task.Tag, error) {
+	t, u, err := s.loadTaskParticipant(ctx, input.TaskID, input.RequesterID)
+	if err != nil {
+		return nil, err
+	}
+	if !canDiscussTask(u, t) {
+		return nil, task.ErrUnauthorized
+	}
+
+	return s.taskRepo.ListTags(ctx, t.ID)
+}
+
+func (s *taskService) loadTaskParticipant(ctx context.Context, taskID, userID uuid.UUID) (*task.Task, *user.User, error) {
+	t, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, u, nil
+}
+
+// canDiscussTask checks if the user may read and write comments and tags on the task
+func canDiscussTask(u *user.User, t *task.Task) bool {
+	return u.CanManageAllTasks() || t.IsAssignedTo(u.ID) || t.IsCreatedBy(u.ID)
+}
+
+// notifyTaskUpdate sends a task update notification to each distinct user.
+// Failures are logged rather than returned since the task change has already
+// been saved.
+func (s *taskService) notifyTaskUpdate(taskID uuid.UUID, title string, status task.Status, userIDs ...uuid.UUID) {
+	if s.wsService == nil {
+		return
+	}
+
+	notified := make(map[uuid.UUID]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if userID == uuid.Nil || notified[userID] {
+			continue
+		}
+		notified[userID] = true
+
+		if err := s.wsService.SendTaskUpdateNotification(userID.String(), taskID.String(), title, status.String()); err != nil {
+			log.Printf("error sending task update notification for task %s to user %s: %v", taskID, userID, err)
+		}
+	}
+}
+
+// notifySummaryChange tells every connected employer that the per-employee
+// task summary changed so their dashboards can refresh it. Like
+// notifyTaskUpdate it only logs failures.
+func (s *taskService) notifySummaryChange() {
+	if s.wsService == nil {
+		return
+	}
+
+	if err := s.wsService.SendTaskSummaryUpdate(user.Employer.String()); err != nil {
+		log.Printf("error sending task summary update to employers: %v", err)
+	}
+}
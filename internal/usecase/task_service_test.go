@@ -0,0 +1,1022 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/domain/task"
+	"github.com/personal/task-management/internal/domain/user"
+	"github.com/personal/task-management/internal/mocks"
+	repository "github.com/personal/task-management/internal/repositories"
+	"github.com/personal/task-management/internal/usecase"
+	"github.com/stretchr/testify/suite"
+)
+
+type TaskServiceTestSuite struct {
+	suite.Suite
+	ctrl        *gomock.Controller
+	taskRepo    *mocks.MockTaskRepository
+	commentRepo *mocks.MockTaskCommentRepository
+	userRepo    *mocks.MockUserRepository
+	wsService   *mocks.MockWebSocketService
+	service     usecase.TaskService
+}
+
+func (suite *TaskServiceTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.taskRepo = mocks.NewMockTaskRepository(suite.ctrl)
+	suite.commentRepo = mocks.NewMockTaskCommentRepository(suite.ctrl)
+	suite.userRepo = mocks.NewMockUserRepository(suite.ctrl)
+	suite.wsService = mocks.NewMockWebSocketService(suite.ctrl)
+	suite.service = usecase.NewTaskService(suite.taskRepo, suite.commentRepo, suite.userRepo, suite.wsService, noopWebhooks{}, task.DefaultWorkflow())
+}
+
+func (suite *TaskServiceTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// expectSummaryUpdate expects the task summary to be announced to employers
+func (suite *TaskServiceTestSuite) expectSummaryUpdate() {
+	suite.wsService.EXPECT().SendTaskSummaryUpdate(user.Employer.String()).Return(nil)
+}
+
+type noopWebhooks struct{}
+
+func (noopWebhooks) Dispatch(ctx context.Context, event string, data any) error { return nil }
+
+func (suite *TaskServiceTestSuite) TestGetTasksWithFilterMultipleStatuses() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+
+	statuses := []task.Status{task.StatusPending, task.StatusInProgress}
+	suite.taskRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, filter repository.TaskFilter) ([]*task.Task, error) {
+			suite.Equal(statuses, filter.Statuses)
+			suite.Nil(filter.Status)
+			suite.Nil(filter.AssigneeID)
+			return []*task.Task{{Status: task.StatusPending}, {Status: task.StatusInProgress}}, nil
+		})
+
+	tasks, err := suite.service.GetTasksWithFilter(context.Background(), dtos.GetTasksWithFilterInput{
+		UserID: employer.ID,
+		Filter: dtos.TaskFilter{Statuses: statuses},
+	})
+	suite.NoError(err)
+	suite.Len(tasks, 2)
+}
+
+func (suite *TaskServiceTestSuite) TestGetTasksWithFilterByStatusOnly() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+
+	// Unset filters must not reach the repository as zero-value predicates
+	suite.taskRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, filter repository.TaskFilter) ([]*task.Task, error) {
+			suite.Require().NotNil(filter.Status)
+			suite.Equal(task.StatusBlocked, *filter.Status)
+			suite.Nil(filter.AssigneeID)
+			suite.Empty(filter.Statuses)
+			suite.Empty(filter.Tags)
+			return []*task.Task{{Status: task.StatusBlocked}}, nil
+		})
+
+	tasks, err := suite.service.GetTasksWithFilter(context.Background(), dtos.GetTasksWithFilterInput{
+		UserID: employer.ID,
+		Filter: dtos.TaskFilter{Status: task.StatusBlocked},
+	})
+	suite.NoError(err)
+	suite.Len(tasks, 1)
+}
+
+func (suite *TaskServiceTestSuite) TestGetTasksWithFilterMultipleStatusesScopedToEmployee() {
+	employee := &user.User{ID: uuid.New(), Role: user.Employee}
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employee.ID).Return(employee, nil)
+
+	statuses := []task.Status{task.StatusPending, task.StatusCompleted}
+	suite.taskRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, filter repository.TaskFilter) ([]*task.Task, error) {
+			suite.Equal(statuses, filter.Statuses)
+			suite.Require().NotNil(filter.AssigneeID)
+			suite.Equal(employee.ID, *filter.AssigneeID)
+			return nil, nil
+		})
+
+	_, err := suite.service.GetTasksWithFilter(context.Background(), dtos.GetTasksWithFilterInput{
+		UserID: employee.ID,
+		Filter: dtos.TaskFilter{Statuses: statuses},
+	})
+	suite.NoError(err)
+}
+
+func (suite *TaskServiceTestSuite) TestGetTasksWithFilterManagerSeesEveryTask() {
+	manager := &user.User{ID: uuid.New(), Role: user.Manager}
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), manager.ID).Return(manager, nil)
+	suite.taskRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, filter repository.TaskFilter) ([]*task.Task, error) {
+			suite.Nil(filter.AssigneeID)
+			return nil, nil
+		})
+
+	_, err := suite.service.GetTasksWithFilter(context.Background(), dtos.GetTasksWithFilterInput{UserID: manager.ID})
+	suite.NoError(err)
+}
+
+func (suite *TaskServiceTestSuite) TestUpdateTaskStatusNotifiesAssigneeAndCreator() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	t := &task.Task{
+		ID:         uuid.New(),
+		Title:      "Write report",
+		Status:     task.StatusPending,
+		AssigneeID: uuid.New(),
+		CreatorID:  employer.ID,
+	}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+	suite.taskRepo.EXPECT().UpdateWithEvents(gomock.Any(), t, gomock.Any()).Return(nil)
+
+	suite.wsService.EXPECT().SendTaskUpdateNotification(t.AssigneeID.String(), t.ID.String(), "Task updated: Write report", "in_progress").Return(nil)
+	suite.wsService.EXPECT().SendTaskUpdateNotification(t.CreatorID.String(), t.ID.String(), "Task updated: Write report", "in_progress").Return(nil)
+	suite.expectSummaryUpdate()
+
+	updated, err := suite.service.UpdateTaskStatus(context.Background(), dtos.UpdateTaskStatusInput{
+		TaskID:    t.ID,
+		UserID:    employer.ID,
+		NewStatus: task.StatusInProgress,
+	})
+	suite.NoError(err)
+	suite.Equal(task.StatusInProgress, updated.Status)
+}
+
+func (suite *TaskServiceTestSuite) TestUpdateTaskDetailsByCreatorEmployerOrManager() {
+	creator := &user.User{ID: uuid.New(), Role: user.Employee}
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	manager := &user.User{ID: uuid.New(), Role: user.Manager}
+	for _, requester := range []*user.User{creator, employer, manager} {
+		t := &task.Task{
+			ID:          uuid.New(),
+			Title:       "Wrte report",
+			Description: "Q3",
+			Status:      task.StatusPending,
+			AssigneeID:  uuid.New(),
+			CreatorID:   creator.ID,
+			DueDate:     time.Now().Add(time.Hour),
+		}
+		suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+		suite.userRepo.EXPECT().GetByID(gomock.Any(), requester.ID).Return(requester, nil)
+		suite.taskRepo.EXPECT().UpdateWithEvents(gomock.Any(), t, gomock.Any()).Return(nil)
+		suite.wsService.EXPECT().SendTaskUpdateNotification(t.AssigneeID.String(), t.ID.String(), "Task edited: Write report", "pending").Return(nil)
+		suite.wsService.EXPECT().SendTaskUpdateNotification(creator.ID.String(), t.ID.String(), "Task edited: Write report", "pending").Return(nil)
+
+		title, due := "Write report", time.Now().Add(72*time.Hour)
+		edited, err := suite.service.UpdateTaskDetails(context.Background(), dtos.UpdateTaskDetailsInput{
+			TaskID:      t.ID,
+			RequesterID: requester.ID,
+			Title:       &title,
+			DueDate:     &due,
+		})
+		suite.Require().NoError(err)
+		suite.Equal("Write report", edited.Title)
+		suite.Equal("Q3", edited.Description)
+		suite.Equal(due, edited.DueDate)
+		suite.Equal(task.StatusPending, edited.Status)
+	}
+}
+
+func (suite *TaskServiceTestSuite) TestUpdateTaskDetailsRejectsOtherUsers() {
+	// The assignee may move a task along but not rewrite it
+	requester := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{ID: uuid.New(), Title: "Write report", AssigneeID: requester.ID, CreatorID: uuid.New()}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), requester.ID).Return(requester, nil)
+
+	title := "Mine now"
+	_, err := suite.service.UpdateTaskDetails(context.Background(), dtos.UpdateTaskDetailsInput{
+		TaskID:      t.ID,
+		RequesterID: requester.ID,
+		Title:       &title,
+	})
+	suite.ErrorIs(err, task.ErrUnauthorized)
+	suite.Equal("Write report", t.Title)
+}
+
+func (suite *TaskServiceTestSuite) TestUpdateTaskDetailsRejectsPastDueDate() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	due := time.Now().Add(time.Hour)
+	t := &task.Task{ID: uuid.New(), Title: "Write report", CreatorID: employer.ID, DueDate: due}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+
+	// Nothing is saved
+	past := time.Now().Add(-time.Hour)
+	_, err := suite.service.UpdateTaskDetails(context.Background(), dtos.UpdateTaskDetailsInput{
+		TaskID:      t.ID,
+		RequesterID: employer.ID,
+		DueDate:     &past,
+	})
+	suite.ErrorIs(err, task.ErrInvalidDueDate)
+	suite.Equal(due, t.DueDate)
+}
+
+func (suite *TaskServiceTestSuite) TestReassignTaskNotifiesBothAssignees() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	newAssignee := &user.User{ID: uuid.New(), Role: user.Employee, Status: user.StatusActive}
+	previousAssignee := uuid.New()
+	updatedAt := time.Now().Add(-time.Hour)
+	t := &task.Task{
+		ID:         uuid.New(),
+		Title:      "Write report",
+		Status:     task.StatusInProgress,
+		AssigneeID: previousAssignee,
+		CreatorID:  uuid.New(),
+		UpdatedAt:  updatedAt,
+	}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), newAssignee.ID).Return(newAssignee, nil)
+	suite.taskRepo.EXPECT().UpdateWithEvents(gomock.Any(), t, gomock.Any()).Return(nil)
+
+	suite.wsService.EXPECT().SendTaskUpdateNotification(previousAssignee.String(), t.ID.String(), "Task reassigned: Write report", "in_progress").Return(nil)
+	suite.wsService.EXPECT().SendTaskUpdateNotification(newAssignee.ID.String(), t.ID.String(), "Task reassigned: Write report", "in_progress").Return(nil)
+	suite.expectSummaryUpdate()
+
+	reassigned, err := suite.service.ReassignTask(context.Background(), dtos.ReassignTaskInput{
+		TaskID:      t.ID,
+		RequesterID: employer.ID,
+		AssigneeID:  newAssignee.ID,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(newAssignee.ID, reassigned.AssigneeID)
+	suite.True(reassigned.UpdatedAt.After(updatedAt))
+}
+
+func (suite *TaskServiceTestSuite) TestManagerReassignsTask() {
+	manager := &user.User{ID: uuid.New(), Role: user.Manager}
+	newAssignee := &user.User{ID: uuid.New(), Role: user.Employee, Status: user.StatusActive}
+	previousAssignee := uuid.New()
+	t := &task.Task{ID: uuid.New(), Title: "Write report", Status: task.StatusPending, AssigneeID: previousAssignee, CreatorID: uuid.New()}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), manager.ID).Return(manager, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), newAssignee.ID).Return(newAssignee, nil)
+	suite.taskRepo.EXPECT().UpdateWithEvents(gomock.Any(), t, gomock.Any()).Return(nil)
+	suite.wsService.EXPECT().SendTaskUpdateNotification(previousAssignee.String(), t.ID.String(), "Task reassigned: Write report", "pending").Return(nil)
+	suite.wsService.EXPECT().SendTaskUpdateNotification(newAssignee.ID.String(), t.ID.String(), "Task reassigned: Write report", "pending").Return(nil)
+	suite.expectSummaryUpdate()
+
+	reassigned, err := suite.service.ReassignTask(context.Background(), dtos.ReassignTaskInput{
+		TaskID:      t.ID,
+		RequesterID: manager.ID,
+		AssigneeID:  newAssignee.ID,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(newAssignee.ID, reassigned.AssigneeID)
+}
+
+func (suite *TaskServiceTestSuite) TestReassignTaskRequiresAnActiveEmployee() {
+	creator := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{ID: uuid.New(), AssigneeID: uuid.New(), CreatorID: creator.ID}
+	targets := map[string]*user.User{
+		"employer": {ID: uuid.New(), Role: user.Employer, Status: user.StatusActive},
+		"manager":  {ID: uuid.New(), Role: user.Manager, Status: user.StatusActive},
+		"deleted":  {ID: uuid.New(), Role: user.Employee, Status: user.StatusInactive},
+	}
+	for name, target := range targets {
+		suite.Run(name, func() {
+			suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+			suite.userRepo.EXPECT().GetByID(gomock.Any(), creator.ID).Return(creator, nil)
+			suite.userRepo.EXPECT().GetByID(gomock.Any(), target.ID).Return(target, nil)
+
+			// Nothing is saved or announced
+			_, err := suite.service.ReassignTask(context.Background(), dtos.ReassignTaskInput{
+				TaskID:      t.ID,
+				RequesterID: creator.ID,
+				AssigneeID:  target.ID,
+			})
+			suite.ErrorIs(err, task.ErrInvalidAssignee)
+		})
+	}
+
+	missing := uuid.New()
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), creator.ID).Return(creator, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), missing).Return(nil, user.ErrUserNotFound)
+	_, err := suite.service.ReassignTask(context.Background(), dtos.ReassignTaskInput{TaskID: t.ID, RequesterID: creator.ID, AssigneeID: missing})
+	suite.ErrorIs(err, task.ErrInvalidAssignee)
+}
+
+func (suite *TaskServiceTestSuite) TestReassignTaskRequiresManagerOrCreator() {
+	// Being the assignee isn't enough
+	assignee := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{ID: uuid.New(), AssigneeID: assignee.ID, CreatorID: uuid.New()}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), assignee.ID).Return(assignee, nil)
+
+	_, err := suite.service.ReassignTask(context.Background(), dtos.ReassignTaskInput{
+		TaskID:      t.ID,
+		RequesterID: assignee.ID,
+		AssigneeID:  uuid.New(),
+	})
+	suite.ErrorIs(err, task.ErrUnauthorized)
+}
+
+func (suite *TaskServiceTestSuite) TestUpdateTaskStatusRecordsHistory() {
+	employee := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{ID: uuid.New(), Title: "Deploy", Status: task.StatusPending, AssigneeID: employee.ID, CreatorID: uuid.New()}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employee.ID).Return(employee, nil)
+	suite.taskRepo.EXPECT().UpdateWithEvents(gomock.Any(), t, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *task.Task, events []*task.TaskEvent) error {
+			suite.Require().Len(events, 1)
+			suite.Equal(t.ID, events[0].TaskID)
+			suite.Equal(employee.ID, events[0].ActorID)
+			suite.Equal(task.FieldStatus, events[0].Field)
+			suite.Equal("pending", events[0].OldValue)
+			suite.Equal("in_progress", events[0].NewValue)
+			return nil
+		})
+	suite.wsService.EXPECT().SendTaskUpdateNotification(gomock.Any(), t.ID.String(), gomock.Any(), "in_progress").Return(nil).Times(2)
+	suite.expectSummaryUpdate()
+
+	_, err := suite.service.UpdateTaskStatus(context.Background(), dtos.UpdateTaskStatusInput{
+		TaskID:    t.ID,
+		UserID:    employee.ID,
+		NewStatus: task.StatusInProgress,
+	})
+	suite.NoError(err)
+}
+
+func (suite *TaskServiceTestSuite) TestGetTaskHistory() {
+	assignee := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{ID: uuid.New(), AssigneeID: assignee.ID, CreatorID: uuid.New()}
+	history := []*task.TaskEvent{task.NewTaskEvent(t.ID, assignee.ID, task.FieldStatus, "pending", "in_progress")}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), assignee.ID).Return(assignee, nil)
+	suite.taskRepo.EXPECT().ListEvents(gomock.Any(), t.ID).Return(history, nil)
+
+	events, err := suite.service.GetTaskHistory(context.Background(), t.ID, assignee.ID)
+	suite.Require().NoError(err)
+	suite.Equal(history, events)
+
+	// Other employees can't see it
+	other := &user.User{ID: uuid.New(), Role: user.Employee}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), other.ID).Return(other, nil)
+	_, err = suite.service.GetTaskHistory(context.Background(), t.ID, other.ID)
+	suite.ErrorIs(err, task.ErrUnauthorized)
+}
+
+func (suite *TaskServiceTestSuite) TestUpdateTaskStatusNotifiesSelfAssignedOnce() {
+	employee := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{
+		ID:         uuid.New(),
+		Title:      "Write report",
+		Status:     task.StatusPending,
+		AssigneeID: employee.ID,
+		CreatorID:  employee.ID,
+	}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employee.ID).Return(employee, nil)
+	suite.taskRepo.EXPECT().UpdateWithEvents(gomock.Any(), t, gomock.Any()).Return(nil)
+
+	suite.wsService.EXPECT().SendTaskUpdateNotification(employee.ID.String(), t.ID.String(), "Task updated: Write report", "in_progress").Return(nil).Times(1)
+	suite.expectSummaryUpdate()
+
+	_, err := suite.service.UpdateTaskStatus(context.Background(), dtos.UpdateTaskStatusInput{
+		TaskID:    t.ID,
+		UserID:    employee.ID,
+		NewStatus: task.StatusInProgress,
+	})
+	suite.NoError(err)
+}
+
+func (suite *TaskServiceTestSuite) TestUpdateTaskStatusSendsSummaryUpdateToEmployersOnly() {
+	employee := &user.User{ID: uuid.New(), Role: user.Employee}
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	t := &task.Task{ID: uuid.New(), Title: "Write report", Status: task.StatusPending, AssigneeID: employee.ID, CreatorID: employer.ID}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employee.ID).Return(employee, nil)
+	suite.taskRepo.EXPECT().UpdateWithEvents(gomock.Any(), t, gomock.Any()).Return(nil)
+	suite.wsService.EXPECT().SendTaskUpdateNotification(gomock.Any(), t.ID.String(), gomock.Any(), "in_progress").Return(nil).Times(2)
+
+	// The summary update goes to employers, without loading any users
+	suite.expectSummaryUpdate()
+
+	_, err := suite.service.UpdateTaskStatus(context.Background(), dtos.UpdateTaskStatusInput{
+		TaskID:    t.ID,
+		UserID:    employee.ID,
+		NewStatus: task.StatusInProgress,
+	})
+	suite.NoError(err)
+}
+
+func (suite *TaskServiceTestSuite) TestGetTaskSummaryByEmployeeCountsEveryStatus() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	employee := &user.User{ID: uuid.New(), Name: "Alice", Role: user.Employee}
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+	suite.userRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]*user.User{employer, employee}, nil)
+	suite.taskRepo.EXPECT().FindByAssignee(gomock.Any(), employee.ID).Return([]*task.Task{
+		{Status: task.StatusPending},
+		{Status: task.StatusInProgress},
+		{Status: task.StatusBlocked},
+		{Status: task.StatusBlocked},
+		{Status: task.StatusCompleted},
+	}, nil)
+
+	summaries, err := suite.service.GetTaskSummaryByEmployee(context.Background(), dtos.GetTaskSummaryByEmployeeInput{RequesterID: employer.ID})
+	suite.Require().NoError(err)
+	suite.Equal([]dtos.EmployeeTaskSummary{{
+		EmployeeID:      employee.ID,
+		EmployeeName:    "Alice",
+		TotalTasks:      5,
+		CompletedTasks:  1,
+		PendingTasks:    1,
+		InProgressTasks: 1,
+		BlockedTasks:    2,
+	}}, summaries)
+}
+
+func (suite *TaskServiceTestSuite) TestDeleteTaskSendsSummaryUpdate() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	taskID := uuid.New()
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+	suite.taskRepo.EXPECT().Delete(gomock.Any(), taskID).Return(nil)
+	suite.wsService.EXPECT().SendTaskUpdateNotification(employer.ID.String(), taskID.String(), gomock.Any(), "deleted").Return(nil)
+	suite.expectSummaryUpdate()
+
+	suite.NoError(suite.service.DeleteTask(context.Background(), dtos.DeleteTaskInput{TaskID: taskID, RequesterID: employer.ID}))
+}
+
+func (suite *TaskServiceTestSuite) TestUpdateTaskStatusWithoutWebSocketService() {
+	service := usecase.NewTaskService(suite.taskRepo, suite.commentRepo, suite.userRepo, nil, noopWebhooks{}, task.DefaultWorkflow())
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	t := &task.Task{ID: uuid.New(), Status: task.StatusPending, AssigneeID: uuid.New(), CreatorID: employer.ID}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+	suite.taskRepo.EXPECT().UpdateWithEvents(gomock.Any(), t, gomock.Any()).Return(nil)
+
+	_, err := service.UpdateTaskStatus(context.Background(), dtos.UpdateTaskStatusInput{
+		TaskID:    t.ID,
+		UserID:    employer.ID,
+		NewStatus: task.StatusInProgress,
+	})
+	suite.NoError(err)
+}
+
+func (suite *TaskServiceTestSuite) TestUpdateTaskStatusReopenIsEmployerOnly() {
+	employee := &user.User{ID: uuid.New(), Role: user.Employee}
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	t := &task.Task{ID: uuid.New(), Status: task.StatusCompleted, AssigneeID: employee.ID, CreatorID: employer.ID}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil).Times(2)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employee.ID).Return(employee, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+
+	_, err := suite.service.UpdateTaskStatus(context.Background(), dtos.UpdateTaskStatusInput{
+		TaskID:    t.ID,
+		UserID:    employee.ID,
+		NewStatus: task.StatusPending,
+	})
+	suite.ErrorIs(err, task.ErrInvalidStatusTransition)
+
+	suite.taskRepo.EXPECT().UpdateWithEvents(gomock.Any(), t, gomock.Any()).Return(nil)
+	suite.wsService.EXPECT().SendTaskUpdateNotification(gomock.Any(), t.ID.String(), gomock.Any(), "pending").Return(nil).Times(2)
+	suite.expectSummaryUpdate()
+
+	reopened, err := suite.service.UpdateTaskStatus(context.Background(), dtos.UpdateTaskStatusInput{
+		TaskID:    t.ID,
+		UserID:    employer.ID,
+		NewStatus: task.StatusPending,
+	})
+	suite.NoError(err)
+	suite.Equal(task.StatusPending, reopened.Status)
+}
+
+func (suite *TaskServiceTestSuite) TestUpdateTaskStatusBlockedNotifiesCreator() {
+	employee := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{
+		ID:         uuid.New(),
+		Title:      "Write report",
+		Status:     task.StatusInProgress,
+		AssigneeID: employee.ID,
+		CreatorID:  uuid.New(),
+	}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employee.ID).Return(employee, nil)
+	suite.taskRepo.EXPECT().UpdateWithEvents(gomock.Any(), t, gomock.Any()).Return(nil)
+
+	title := "Task blocked: Write report (waiting on data)"
+	suite.wsService.EXPECT().SendTaskUpdateNotification(employee.ID.String(), t.ID.String(), title, "blocked").Return(nil)
+	suite.wsService.EXPECT().SendTaskUpdateNotification(t.CreatorID.String(), t.ID.String(), title, "blocked").Return(nil)
+	suite.expectSummaryUpdate()
+
+	blocked, err := suite.service.UpdateTaskStatus(context.Background(), dtos.UpdateTaskStatusInput{
+		TaskID:    t.ID,
+		UserID:    employee.ID,
+		NewStatus: task.StatusBlocked,
+		Reason:    "waiting on data",
+	})
+	suite.NoError(err)
+	suite.Equal(task.StatusBlocked, blocked.Status)
+	suite.Equal("waiting on data", blocked.BlockReason)
+}
+
+func (suite *TaskServiceTestSuite) TestUpdateTaskStatusBlockedWithoutReason() {
+	employee := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{ID: uuid.New(), Status: task.StatusInProgress, AssigneeID: employee.ID, CreatorID: uuid.New()}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employee.ID).Return(employee, nil)
+
+	_, err := suite.service.UpdateTaskStatus(context.Background(), dtos.UpdateTaskStatusInput{
+		TaskID:    t.ID,
+		UserID:    employee.ID,
+		NewStatus: task.StatusBlocked,
+	})
+	suite.ErrorIs(err, task.ErrBlockReasonRequired)
+	suite.Equal(task.StatusInProgress, t.Status)
+}
+
+func (suite *TaskServiceTestSuite) TestAddCommentAuthorization() {
+	assignee := &user.User{ID: uuid.New(), Role: user.Employee}
+	creator := &user.User{ID: uuid.New(), Role: user.Employee}
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	manager := &user.User{ID: uuid.New(), Role: user.Manager}
+	outsider := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{ID: uuid.New(), AssigneeID: assignee.ID, CreatorID: creator.ID}
+
+	tests := []struct {
+		name    string
+		user    *user.User
+		allowed bool
+	}{
+		{name: "assignee", user: assignee, allowed: true},
+		{name: "creator", user: creator, allowed: true},
+		{name: "employer", user: employer, allowed: true},
+		{name: "manager", user: manager, allowed: true},
+		{name: "unrelated employee", user: outsider, allowed: false},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+			suite.userRepo.EXPECT().GetByID(gomock.Any(), tt.user.ID).Return(tt.user, nil)
+			if tt.allowed {
+				suite.commentRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			comment, err := suite.service.AddComment(context.Background(), dtos.AddCommentInput{
+				TaskID:  t.ID,
+				UserID:  tt.user.ID,
+				Content: "  Looks good  ",
+			})
+			if !tt.allowed {
+				suite.ErrorIs(err, task.ErrUnauthorized)
+				return
+			}
+			suite.NoError(err)
+			suite.Equal(t.ID, comment.TaskID)
+			suite.Equal(tt.user.ID, comment.UserID)
+			suite.Equal("Looks good", comment.Content)
+		})
+	}
+}
+
+func (suite *TaskServiceTestSuite) TestAddCommentRejectsBlankContent() {
+	assignee := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{ID: uuid.New(), AssigneeID: assignee.ID}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), assignee.ID).Return(assignee, nil)
+
+	_, err := suite.service.AddComment(context.Background(), dtos.AddCommentInput{
+		TaskID:  t.ID,
+		UserID:  assignee.ID,
+		Content: "   ",
+	})
+	suite.ErrorIs(err, task.ErrEmptyComment)
+}
+
+func (suite *TaskServiceTestSuite) TestAddCommentWithStatusSavesBothTogether() {
+	employee := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{
+		ID:         uuid.New(),
+		Title:      "Deploy",
+		Status:     task.StatusInProgress,
+		AssigneeID: employee.ID,
+		CreatorID:  uuid.New(),
+	}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employee.ID).Return(employee, nil)
+	suite.taskRepo.EXPECT().UpdateWithComment(gomock.Any(), t, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, saved *task.Task, comment *task.TaskComment, _ []*task.TaskEvent) error {
+			suite.Equal(task.StatusCompleted, saved.Status)
+			suite.Equal("done — finished the deploy", comment.Content)
+			return nil
+		})
+	suite.wsService.EXPECT().SendTaskUpdateNotification(gomock.Any(), t.ID.String(), "Task updated: Deploy", "completed").Return(nil).Times(2)
+	suite.expectSummaryUpdate()
+
+	status := task.StatusCompleted
+	comment, err := suite.service.AddComment(context.Background(), dtos.AddCommentInput{
+		TaskID:  t.ID,
+		UserID:  employee.ID,
+		Content: "done — finished the deploy",
+		Status:  &status,
+	})
+	suite.Require().NoError(err)
+	suite.Equal(t.ID, comment.TaskID)
+	suite.Equal(task.StatusCompleted, t.Status)
+}
+
+func (suite *TaskServiceTestSuite) TestAddCommentWithBlockedStatusUsesCommentAsReason() {
+	employee := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{ID: uuid.New(), Title: "Deploy", Status: task.StatusInProgress, AssigneeID: employee.ID, CreatorID: uuid.New()}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employee.ID).Return(employee, nil)
+	suite.taskRepo.EXPECT().UpdateWithComment(gomock.Any(), t, gomock.Any(), gomock.Any()).Return(nil)
+	suite.wsService.EXPECT().SendTaskUpdateNotification(gomock.Any(), t.ID.String(), "Task blocked: Deploy (waiting on credentials)", "blocked").Return(nil).Times(2)
+	suite.expectSummaryUpdate()
+
+	status := task.StatusBlocked
+	_, err := suite.service.AddComment(context.Background(), dtos.AddCommentInput{
+		TaskID:  t.ID,
+		UserID:  employee.ID,
+		Content: " waiting on credentials ",
+		Status:  &status,
+	})
+	suite.Require().NoError(err)
+	suite.Equal("waiting on credentials", t.BlockReason)
+}
+
+func (suite *TaskServiceTestSuite) TestAddCommentWithStatusRejectsInvalidTransition() {
+	employee := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{ID: uuid.New(), Status: task.StatusCompleted, AssigneeID: employee.ID, CreatorID: uuid.New()}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employee.ID).Return(employee, nil)
+	// Neither the comment nor the task is written
+
+	status := task.StatusPending
+	_, err := suite.service.AddComment(context.Background(), dtos.AddCommentInput{
+		TaskID:  t.ID,
+		UserID:  employee.ID,
+		Content: "reopening this",
+		Status:  &status,
+	})
+	suite.ErrorIs(err, task.ErrInvalidStatusTransition)
+	suite.Equal(task.StatusCompleted, t.Status)
+}
+
+func (suite *TaskServiceTestSuite) TestAddCommentWithStatusRequiresTransitionPermission() {
+	// The creator may comment, but only the assignee or an employer may move the task
+	creator := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{ID: uuid.New(), Status: task.StatusInProgress, AssigneeID: uuid.New(), CreatorID: creator.ID}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), creator.ID).Return(creator, nil)
+
+	status := task.StatusCompleted
+	_, err := suite.service.AddComment(context.Background(), dtos.AddCommentInput{
+		TaskID:  t.ID,
+		UserID:  creator.ID,
+		Content: "looks finished to me",
+		Status:  &status,
+	})
+	suite.ErrorIs(err, task.ErrUnauthorized)
+	suite.Equal(task.StatusInProgress, t.Status)
+}
+
+func (suite *TaskServiceTestSuite) TestListCommentsAuthorization() {
+	assignee := &user.User{ID: uuid.New(), Role: user.Employee}
+	outsider := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{ID: uuid.New(), AssigneeID: assignee.ID, CreatorID: uuid.New()}
+
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil).Times(2)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), outsider.ID).Return(outsider, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), assignee.ID).Return(assignee, nil)
+
+	_, err := suite.service.ListComments(context.Background(), dtos.ListCommentsInput{TaskID: t.ID, RequesterID: outsider.ID})
+	suite.ErrorIs(err, task.ErrUnauthorized)
+
+	comments := []*task.TaskComment{{ID: uuid.New(), TaskID: t.ID}}
+	suite.commentRepo.EXPECT().ListByTask(gomock.Any(), t.ID, 20, 40).Return(comments, nil)
+	listed, err := suite.service.ListComments(context.Background(), dtos.ListCommentsInput{
+		TaskID:      t.ID,
+		RequesterID: assignee.ID,
+		Limit:       20,
+		Offset:      40,
+	})
+	suite.NoError(err)
+	suite.Equal(comments, listed)
+}
+
+func (suite *TaskServiceTestSuite) TestDeleteCommentAuthorization() {
+	author := &user.User{ID: uuid.New(), Role: user.Employee}
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	other := &user.User{ID: uuid.New(), Role: user.Employee}
+	taskID := uuid.New()
+	comment := &task.TaskComment{ID: uuid.New(), TaskID: taskID, UserID: author.ID}
+
+	tests := []struct {
+		name    string
+		user    *user.User
+		allowed bool
+	}{
+		{name: "author", user: author, allowed: true},
+		{name: "employer", user: employer, allowed: true},
+		{name: "another participant", user: other, allowed: false},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.userRepo.EXPECT().GetByID(gomock.Any(), tt.user.ID).Return(tt.user, nil)
+			suite.commentRepo.EXPECT().GetByID(gomock.Any(), comment.ID).Return(comment, nil)
+			if tt.allowed {
+				suite.commentRepo.EXPECT().Delete(gomock.Any(), comment.ID).Return(nil)
+			}
+
+			err := suite.service.DeleteComment(context.Background(), dtos.DeleteCommentInput{
+				TaskID:      taskID,
+				CommentID:   comment.ID,
+				RequesterID: tt.user.ID,
+			})
+			if tt.allowed {
+				suite.NoError(err)
+			} else {
+				suite.ErrorIs(err, task.ErrUnauthorized)
+			}
+		})
+	}
+}
+
+func (suite *TaskServiceTestSuite) TestDeleteCommentOnAnotherTask() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	comment := &task.TaskComment{ID: uuid.New(), TaskID: uuid.New(), UserID: employer.ID}
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+	suite.commentRepo.EXPECT().GetByID(gomock.Any(), comment.ID).Return(comment, nil)
+
+	err := suite.service.DeleteComment(context.Background(), dtos.DeleteCommentInput{
+		TaskID:      uuid.New(),
+		CommentID:   comment.ID,
+		RequesterID: employer.ID,
+	})
+	suite.ErrorIs(err, task.ErrCommentNotFound)
+}
+
+func (suite *TaskServiceTestSuite) TestAddTagNormalizesName() {
+	assignee := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{ID: uuid.New(), AssigneeID: assignee.ID}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), assignee.ID).Return(assignee, nil)
+	suite.taskRepo.EXPECT().AddTag(gomock.Any(), t.ID, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ uuid.UUID, tag *task.Tag) (*task.Tag, error) {
+			return tag, nil
+		})
+
+	tag, err := suite.service.AddTag(context.Background(), dtos.AddTagInput{
+		TaskID: t.ID,
+		UserID: assignee.ID,
+		Name:   " Backend ",
+	})
+	suite.NoError(err)
+	suite.Equal("backend", tag.Name)
+}
+
+func (suite *TaskServiceTestSuite) TestAddTagRejectsOutsider() {
+	outsider := &user.User{ID: uuid.New(), Role: user.Employee}
+	t := &task.Task{ID: uuid.New(), AssigneeID: uuid.New(), CreatorID: uuid.New()}
+	suite.taskRepo.EXPECT().GetByID(gomock.Any(), t.ID).Return(t, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), outsider.ID).Return(outsider, nil)
+
+	_, err := suite.service.AddTag(context.Background(), dtos.AddTagInput{
+		TaskID: t.ID,
+		UserID: outsider.ID,
+		Name:   "bug",
+	})
+	suite.ErrorIs(err, task.ErrUnauthorized)
+}
+
+func (suite *TaskServiceTestSuite) TestGetTasksWithFilterNormalizesTags() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+	suite.taskRepo.EXPECT().List(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, filter repository.TaskFilter) ([]*task.Task, error) {
+			suite.Equal([]string{"backend", "bug"}, filter.Tags)
+			suite.True(filter.AnyTag)
+			return []*task.Task{}, nil
+		})
+
+	_, err := suite.service.GetTasksWithFilter(context.Background(), dtos.GetTasksWithFilterInput{
+		UserID: employer.ID,
+		Filter: dtos.TaskFilter{Tags: []string{"Backend", " bug"}, AnyTag: true},
+	})
+	suite.NoError(err)
+}
+
+func (suite *TaskServiceTestSuite) TestGetOverdueTasksScopedByRole() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	employee := &user.User{ID: uuid.New(), Role: user.Employee}
+
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+	suite.taskRepo.EXPECT().FindOverdue(gomock.Any(), gomock.Any(), nil).Return([]*task.Task{{}, {}}, nil)
+	tasks, err := suite.service.GetOverdueTasks(context.Background(), employer.ID)
+	suite.NoError(err)
+	suite.Len(tasks, 2)
+
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employee.ID).Return(employee, nil)
+	suite.taskRepo.EXPECT().FindOverdue(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, asOf time.Time, assigneeID *uuid.UUID) ([]*task.Task, error) {
+			suite.WithinDuration(time.Now(), asOf, time.Second)
+			suite.Require().NotNil(assigneeID)
+			suite.Equal(employee.ID, *assigneeID)
+			return []*task.Task{{AssigneeID: employee.ID}}, nil
+		})
+	tasks, err = suite.service.GetOverdueTasks(context.Background(), employee.ID)
+	suite.NoError(err)
+	suite.Len(tasks, 1)
+}
+
+func (suite *TaskServiceTestSuite) TestCreateTaskRejectsInvalidRecurrence() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	employee := &user.User{ID: uuid.New(), Role: user.Employee}
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employee.ID).Return(employee, nil)
+
+	_, err := suite.service.CreateTask(context.Background(), dtos.CreateTaskInput{
+		Title:      "Standup notes",
+		DueDate:    time.Now().Add(time.Hour),
+		CreatorID:  employer.ID,
+		AssigneeID: employee.ID,
+		Recurrence: &task.RecurrenceRule{Frequency: "hourly", Interval: 1},
+	})
+	suite.ErrorIs(err, task.ErrInvalidRecurrence)
+}
+
+func (suite *TaskServiceTestSuite) TestCreateTaskRejectsDeletedAssignee() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	employee := &user.User{ID: uuid.New(), Role: user.Employee}
+	employee.Deactivate(time.Now())
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employee.ID).Return(employee, nil)
+
+	_, err := suite.service.CreateTask(context.Background(), dtos.CreateTaskInput{
+		Title:      "Hand over",
+		DueDate:    time.Now().Add(time.Hour),
+		CreatorID:  employer.ID,
+		AssigneeID: employee.ID,
+	})
+	suite.ErrorIs(err, task.ErrInvalidAssignee)
+}
+
+func (suite *TaskServiceTestSuite) TestCreateTaskAndBulkRejectTheSameAssignees() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	manager := &user.User{ID: uuid.New(), Role: user.Manager}
+	deleted := &user.User{ID: uuid.New(), Role: user.Employee}
+	deleted.Deactivate(time.Now())
+	missing := uuid.New()
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil).AnyTimes()
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), manager.ID).Return(manager, nil).AnyTimes()
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), deleted.ID).Return(deleted, nil).AnyTimes()
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), missing).Return(nil, user.ErrUserNotFound).AnyTimes()
+	// Nothing in the batch survives validation
+	suite.taskRepo.EXPECT().CreateBatch(gomock.Any(), gomock.Len(0)).Return(nil).AnyTimes()
+
+	due := time.Now().Add(time.Hour)
+	for _, assigneeID := range []uuid.UUID{employer.ID, manager.ID, deleted.ID, missing} {
+		input := dtos.CreateTaskInput{Title: "Hand over", DueDate: due, CreatorID: employer.ID, AssigneeID: assigneeID}
+		_, createErr := suite.service.CreateTask(context.Background(), input)
+		suite.ErrorIs(createErr, task.ErrInvalidAssignee)
+
+		output, err := suite.service.CreateTasksBulk(context.Background(), dtos.CreateTasksBulkInput{
+			CreatorID: employer.ID,
+			Tasks:     []dtos.CreateTaskInput{input},
+		})
+		suite.Require().NoError(err)
+		suite.Empty(output.Created)
+		suite.Require().Len(output.Errors, 1)
+		suite.Equal(createErr.Error(), output.Errors[0].Error)
+	}
+}
+
+func (suite *TaskServiceTestSuite) TestCreateTasksBulkReportsInvalidItems() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	employee := &user.User{ID: uuid.New(), Role: user.Employee}
+	missing := uuid.New()
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil).Times(2)
+	// Looked up once although two valid tasks are assigned to them
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employee.ID).Return(employee, nil).Times(1)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), missing).Return(nil, user.ErrUserNotFound)
+
+	due := time.Now().Add(time.Hour)
+	suite.taskRepo.EXPECT().CreateBatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, tasks []*task.Task) error {
+			suite.Require().Len(tasks, 2)
+			suite.Equal("Set up repo", tasks[0].Title)
+			suite.Equal("Write README", tasks[1].Title)
+			for _, t := range tasks {
+				suite.Equal(employer.ID, t.CreatorID)
+			}
+			return nil
+		})
+	suite.wsService.EXPECT().SendTaskUpdateNotification(employee.ID.String(), gomock.Any(), gomock.Any(), "pending").Return(nil).Times(2)
+	suite.expectSummaryUpdate()
+
+	output, err := suite.service.CreateTasksBulk(context.Background(), dtos.CreateTasksBulkInput{
+		CreatorID: employer.ID,
+		Tasks: []dtos.CreateTaskInput{
+			{Title: "Set up repo", DueDate: due, AssigneeID: employee.ID},
+			{Title: "", DueDate: due, AssigneeID: employee.ID},
+			{Title: "Review design", DueDate: due, AssigneeID: employer.ID},
+			{Title: "Write README", DueDate: due, AssigneeID: employee.ID},
+			{Title: "Plan sprint", DueDate: due, AssigneeID: missing},
+			{Title: "Standup", DueDate: due, AssigneeID: employee.ID, Recurrence: &task.RecurrenceRule{Frequency: "hourly", Interval: 1}},
+		},
+	})
+	suite.Require().NoError(err)
+	suite.Len(output.Created, 2)
+
+	failed := make([]int, 0, len(output.Errors))
+	for _, itemErr := range output.Errors {
+		failed = append(failed, itemErr.Index)
+		suite.NotEmpty(itemErr.Error)
+	}
+	suite.Equal([]int{1, 2, 4, 5}, failed)
+	suite.Contains(output.Errors[1].Error, task.ErrInvalidAssignee.Error())
+	suite.Contains(output.Errors[2].Error, missing.String())
+	suite.Contains(output.Errors[2].Error, task.ErrInvalidAssignee.Error())
+	suite.Equal(task.ErrInvalidRecurrence.Error(), output.Errors[3].Error)
+}
+
+func (suite *TaskServiceTestSuite) TestCreateTasksBulkRequiresEmployer() {
+	employee := &user.User{ID: uuid.New(), Role: user.Employee}
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employee.ID).Return(employee, nil)
+
+	_, err := suite.service.CreateTasksBulk(context.Background(), dtos.CreateTasksBulkInput{
+		CreatorID: employee.ID,
+		Tasks:     []dtos.CreateTaskInput{{Title: "Set up repo", DueDate: time.Now().Add(time.Hour), AssigneeID: employee.ID}},
+	})
+	suite.ErrorIs(err, task.ErrUnauthorized)
+}
+
+func (suite *TaskServiceTestSuite) TestCreateTasksBulkFailsWhenBatchFails() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	employee := &user.User{ID: uuid.New(), Role: user.Employee}
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employee.ID).Return(employee, nil)
+	suite.taskRepo.EXPECT().CreateBatch(gomock.Any(), gomock.Any()).Return(errors.New("connection reset"))
+
+	_, err := suite.service.CreateTasksBulk(context.Background(), dtos.CreateTasksBulkInput{
+		CreatorID: employer.ID,
+		Tasks:     []dtos.CreateTaskInput{{Title: "Set up repo", DueDate: time.Now().Add(time.Hour), AssigneeID: employee.ID}},
+	})
+	suite.EqualError(err, "connection reset")
+}
+
+func (suite *TaskServiceTestSuite) TestGenerateDueRecurrencesSpawnsWeeklySuccessor() {
+	dueDate := time.Now().Add(-time.Hour).Truncate(time.Second)
+	completed := &task.Task{
+		ID:         uuid.New(),
+		Title:      "Weekly report",
+		Status:     task.StatusCompleted,
+		AssigneeID: uuid.New(),
+		CreatorID:  uuid.New(),
+		DueDate:    dueDate,
+		Recurrence: task.RecurrenceRule{Frequency: task.FrequencyWeekly, Interval: 1},
+	}
+
+	var spawned *task.Task
+	suite.taskRepo.EXPECT().FindRecurrencesDue(gomock.Any()).Return([]*task.Task{completed}, nil)
+	suite.taskRepo.EXPECT().CreateNextOccurrence(gomock.Any(), completed, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, next *task.Task) (bool, error) {
+			spawned = next
+			return true, nil
+		})
+	suite.wsService.EXPECT().SendTaskUpdateNotification(completed.AssigneeID.String(), gomock.Any(), "Task created: Weekly report", "pending").Return(nil)
+	suite.expectSummaryUpdate()
+
+	created, err := suite.service.GenerateDueRecurrences(context.Background())
+	suite.NoError(err)
+	suite.Equal(1, created)
+	suite.Require().NotNil(spawned)
+	suite.Equal(dueDate.AddDate(0, 0, 7), spawned.DueDate)
+	suite.Equal(task.StatusPending, spawned.Status)
+	suite.Equal(completed.AssigneeID, spawned.AssigneeID)
+}
+
+func (suite *TaskServiceTestSuite) TestGenerateDueRecurrencesSkipsAlreadySpawned() {
+	completed := &task.Task{
+		ID:         uuid.New(),
+		Status:     task.StatusCompleted,
+		DueDate:    time.Now(),
+		Recurrence: task.RecurrenceRule{Frequency: task.FrequencyDaily, Interval: 1},
+	}
+	suite.taskRepo.EXPECT().FindRecurrencesDue(gomock.Any()).Return([]*task.Task{completed}, nil)
+	suite.taskRepo.EXPECT().CreateNextOccurrence(gomock.Any(), completed, gomock.Any()).Return(false, nil)
+
+	created, err := suite.service.GenerateDueRecurrences(context.Background())
+	suite.NoError(err)
+	suite.Zero(created)
+}
+
+func TestTaskServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TaskServiceTestSuite))
+}
@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/domain/task"
+	repository "github.com/personal/task-management/internal/repositories"
+	"github.com/personal/task-management/pkg/utils/validate"
+)
+
+type TaskTemplateService interface {
+	CreateTemplate(ctx context.Context, input dtos.CreateTaskTemplateInput) (*task.TaskTemplate, error)
+	GetTemplate(ctx context.Context, input dtos.GetTaskTemplateInput) (*task.TaskTemplate, error)
+	ListTemplates(ctx context.Context, input dtos.ListTaskTemplatesInput) ([]*task.TaskTemplate, error)
+	UpdateTemplate(ctx context.Context, input dtos.UpdateTaskTemplateInput) (*task.TaskTemplate, error)
+	DeleteTemplate(ctx context.Context, input dtos.DeleteTaskTemplateInput) error
+	CreateTaskFromTemplate(ctx context.Context, input dtos.CreateTaskFromTemplateInput) (*task.Task, error)
+}
+
+// taskTemplateService manages the templates employers share for recurring
+// kinds of work. Tasks are instantiated through the task service so they get
+// the same validation and notifications as any other task.
+type taskTemplateService struct {
+	templateRepo repository.TaskTemplateRepository
+	userRepo     repository.UserRepository
+	taskService  TaskService
+}
+
+// NewTaskTemplateService creates a new instance of TaskTemplateService
+func NewTaskTemplateService(templateRepo repository.TaskTemplateRepository, userRepo repository.UserRepository, taskService TaskService) TaskTemplateService {
+	return &taskTemplateService{
+		templateRepo: templateRepo,
+		userRepo:     userRepo,
+		taskService:  taskService,
+	}
+}
+
+// CreateTemplate stores a new template. Only employers may manage templates.
+func (s *taskTemplateService) CreateTemplate(ctx context.Context, input dtos.CreateTaskTemplateInput) (*task.TaskTemplate, error) {
+	if err := validate.Struct(input); err != nil {
+		return nil, err
+	}
+	if err := s.requireEmployer(ctx, input.CreatorID); err != nil {
+		return nil, err
+	}
+	if err := s.checkDefaultAssignee(ctx, input.DefaultAssigneeID); err != nil {
+		return nil, err
+	}
+
+	template, err := task.NewTaskTemplate(input.Title, input.Description, input.DueOffsetHours, input.DefaultAssigneeID, input.CreatorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.templateRepo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// GetTemplate retrieves a template by ID
+func (s *taskTemplateService) GetTemplate(ctx context.Context, input dtos.GetTaskTemplateInput) (*task.TaskTemplate, error) {
+	if err := s.requireEmployer(ctx, input.RequesterID); err != nil {
+		return nil, err
+	}
+	return s.loadTemplate(ctx, input.TemplateID)
+}
+
+// ListTemplates returns a page of templates ordered by title
+func (s *taskTemplateService) ListTemplates(ctx context.Context, input dtos.ListTaskTemplatesInput) ([]*task.TaskTemplate, error) {
+	if err := s.requireEmployer(ctx, input.RequesterID); err != nil {
+		return nil, err
+	}
+	return s.templateRepo.List(ctx, input.Limit, input.Offset)
+}
+
+// UpdateTemplate replaces a template's defaults
+func (s *taskTemplateService) UpdateTemplate(ctx context.Context, input dtos.UpdateTaskTemplateInput) (*task.TaskTemplate, error) {
+	if err := validate.Struct(input); err != nil {
+		return nil, err
+	}
+	if err := s.requireEmployer(ctx, input.RequesterID); err != nil {
+		return nil, err
+	}
+	if err := s.checkDefaultAssignee(ctx, input.DefaultAssigneeID); err != nil {
+		return nil, err
+	}
+
+	template, err := s.loadTemplate(ctx, input.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if err := template.Update(input.Title, input.Description, input.DueOffsetHours, input.DefaultAssigneeID); err != nil {
+		return nil, err
+	}
+	if err := s.templateRepo.Update(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// DeleteTemplate removes a template. Tasks already created from it are kept.
+func (s *taskTemplateService) DeleteTemplate(ctx context.Context, input dtos.DeleteTaskTemplateInput) error {
+	if err := s.requireEmployer(ctx, input.RequesterID); err != nil {
+		return err
+	}
+	template, err := s.loadTemplate(ctx, input.TemplateID)
+	if err != nil {
+		return err
+	}
+	return s.templateRepo.Delete(ctx, template.ID)
+}
+
+// CreateTaskFromTemplate creates a task from the template's defaults with
+// any overrides applied on top
+func (s *taskTemplateService) CreateTaskFromTemplate(ctx context.Context, input dtos.CreateTaskFromTemplateInput) (*task.Task, error) {
+	template, err := s.loadTemplate(ctx, input.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := input.Overrides
+	taskInput := dtos.CreateTaskInput{
+		Title:       template.Title,
+		Description: template.Description,
+		DueDate:     template.DueDateFrom(time.Now()),
+		CreatorID:   input.CreatorID,
+		Recurrence:  overrides.Recurrence,
+	}
+	if overrides.Title != nil {
+		taskInput.Title = *overrides.Title
+	}
+	if overrides.Description != nil {
+		taskInput.Description = *overrides.Description
+	}
+	if overrides.DueDate != nil {
+		taskInput.DueDate = *overrides.DueDate
+	}
+	switch {
+	case overrides.AssigneeID != nil:
+		taskInput.AssigneeID = *overrides.AssigneeID
+	case template.DefaultAssigneeID != nil:
+		taskInput.AssigneeID = *template.DefaultAssigneeID
+	default:
+		return nil, task.ErrAssigneeRequired
+	}
+
+	return s.taskService.CreateTask(ctx, taskInput)
+}
+
+func (s *taskTemplateService) requireEmployer(ctx context.Context, userID uuid.UUID) error {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !u.IsEmployer() {
+		return task.ErrUnauthorized
+	}
+	return nil
+}
+
+// checkDefaultAssignee rejects defaults that could never be instantiated,
+// mirroring the assignee check in CreateTask
+func (s *taskTemplateService) checkDefaultAssignee(ctx context.Context, assigneeID *uuid.UUID) error {
+	if assigneeID == nil {
+		return nil
+	}
+	assignee, err := s.userRepo.GetByID(ctx, *assigneeID)
+	if err != nil {
+		return err
+	}
+	if !assignee.CanBeAssignedTasks() {
+		return task.ErrUnauthorized
+	}
+	return nil
+}
+
+func (s *taskTemplateService) loadTemplate(ctx context.Context, id uuid.UUID) (*task.TaskTemplate, error) {
+	template, err := s.templateRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, task.ErrTemplateNotFound
+	}
+	return template, nil
+}
@@ -0,0 +1,171 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/domain/task"
+	"github.com/personal/task-management/internal/domain/user"
+	"github.com/personal/task-management/internal/mocks"
+	"github.com/personal/task-management/internal/usecase"
+	"github.com/stretchr/testify/suite"
+)
+
+type TaskTemplateServiceTestSuite struct {
+	suite.Suite
+	ctrl         *gomock.Controller
+	templateRepo *mocks.MockTaskTemplateRepository
+	userRepo     *mocks.MockUserRepository
+	taskService  *mocks.MockTaskService
+	service      usecase.TaskTemplateService
+
+	employer *user.User
+	employee *user.User
+}
+
+func (suite *TaskTemplateServiceTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.templateRepo = mocks.NewMockTaskTemplateRepository(suite.ctrl)
+	suite.userRepo = mocks.NewMockUserRepository(suite.ctrl)
+	suite.taskService = mocks.NewMockTaskService(suite.ctrl)
+	suite.service = usecase.NewTaskTemplateService(suite.templateRepo, suite.userRepo, suite.taskService)
+
+	suite.employer = &user.User{ID: uuid.New(), Role: user.Employer}
+	suite.employee = &user.User{ID: uuid.New(), Role: user.Employee}
+}
+
+func (suite *TaskTemplateServiceTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func (suite *TaskTemplateServiceTestSuite) TestCreateTemplate() {
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), suite.employer.ID).Return(suite.employer, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), suite.employee.ID).Return(suite.employee, nil)
+	suite.templateRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	template, err := suite.service.CreateTemplate(context.Background(), dtos.CreateTaskTemplateInput{
+		CreatorID:         suite.employer.ID,
+		Title:             "Weekly report",
+		Description:       "Summarise the week",
+		DueOffsetHours:    48,
+		DefaultAssigneeID: &suite.employee.ID,
+	})
+	suite.Require().NoError(err)
+	suite.Equal("Weekly report", template.Title)
+	suite.Equal(48, template.DueOffsetHours)
+	suite.Equal(suite.employer.ID, template.CreatorID)
+	suite.Equal(&suite.employee.ID, template.DefaultAssigneeID)
+}
+
+func (suite *TaskTemplateServiceTestSuite) TestCreateTemplateRequiresEmployer() {
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), suite.employee.ID).Return(suite.employee, nil)
+
+	_, err := suite.service.CreateTemplate(context.Background(), dtos.CreateTaskTemplateInput{
+		CreatorID:      suite.employee.ID,
+		Title:          "Weekly report",
+		DueOffsetHours: 48,
+	})
+	suite.ErrorIs(err, task.ErrUnauthorized)
+}
+
+func (suite *TaskTemplateServiceTestSuite) TestCreateTemplateRejectsEmployerAsDefaultAssignee() {
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), suite.employer.ID).Return(suite.employer, nil).Times(2)
+
+	_, err := suite.service.CreateTemplate(context.Background(), dtos.CreateTaskTemplateInput{
+		CreatorID:         suite.employer.ID,
+		Title:             "Weekly report",
+		DueOffsetHours:    48,
+		DefaultAssigneeID: &suite.employer.ID,
+	})
+	suite.ErrorIs(err, task.ErrUnauthorized)
+}
+
+func (suite *TaskTemplateServiceTestSuite) template(defaultAssignee *uuid.UUID) *task.TaskTemplate {
+	template, err := task.NewTaskTemplate("Weekly report", "Summarise the week", 48, defaultAssignee, suite.employer.ID)
+	suite.Require().NoError(err)
+	suite.templateRepo.EXPECT().GetByID(gomock.Any(), template.ID).Return(template, nil)
+	return template
+}
+
+func (suite *TaskTemplateServiceTestSuite) TestCreateTaskFromTemplateUsesDefaults() {
+	template := suite.template(&suite.employee.ID)
+	created := &task.Task{ID: uuid.New()}
+
+	before := time.Now()
+	suite.taskService.EXPECT().CreateTask(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, input dtos.CreateTaskInput) (*task.Task, error) {
+			suite.Equal("Weekly report", input.Title)
+			suite.Equal("Summarise the week", input.Description)
+			suite.Equal(suite.employee.ID, input.AssigneeID)
+			suite.Equal(suite.employer.ID, input.CreatorID)
+			suite.WithinDuration(before.Add(48*time.Hour), input.DueDate, time.Minute)
+			suite.Nil(input.Recurrence)
+			return created, nil
+		})
+
+	result, err := suite.service.CreateTaskFromTemplate(context.Background(), dtos.CreateTaskFromTemplateInput{
+		TemplateID: template.ID,
+		CreatorID:  suite.employer.ID,
+	})
+	suite.NoError(err)
+	suite.Equal(created, result)
+}
+
+func (suite *TaskTemplateServiceTestSuite) TestCreateTaskFromTemplateAppliesOverrides() {
+	template := suite.template(&suite.employee.ID)
+	title := "Weekly report: week 12"
+	description := ""
+	dueDate := time.Now().Add(72 * time.Hour)
+	assignee := uuid.New()
+	recurrence := &task.RecurrenceRule{Frequency: task.FrequencyWeekly, Interval: 1}
+
+	suite.taskService.EXPECT().CreateTask(gomock.Any(), dtos.CreateTaskInput{
+		Title:       title,
+		Description: description,
+		DueDate:     dueDate,
+		AssigneeID:  assignee,
+		CreatorID:   suite.employer.ID,
+		Recurrence:  recurrence,
+	}).Return(&task.Task{}, nil)
+
+	_, err := suite.service.CreateTaskFromTemplate(context.Background(), dtos.CreateTaskFromTemplateInput{
+		TemplateID: template.ID,
+		CreatorID:  suite.employer.ID,
+		Overrides: dtos.CreateTaskFromTemplateRequest{
+			Title:       &title,
+			Description: &description,
+			DueDate:     &dueDate,
+			AssigneeID:  &assignee,
+			Recurrence:  recurrence,
+		},
+	})
+	suite.NoError(err)
+}
+
+func (suite *TaskTemplateServiceTestSuite) TestCreateTaskFromTemplateRequiresAssignee() {
+	template := suite.template(nil)
+
+	_, err := suite.service.CreateTaskFromTemplate(context.Background(), dtos.CreateTaskFromTemplateInput{
+		TemplateID: template.ID,
+		CreatorID:  suite.employer.ID,
+	})
+	suite.ErrorIs(err, task.ErrAssigneeRequired)
+}
+
+func (suite *TaskTemplateServiceTestSuite) TestCreateTaskFromMissingTemplate() {
+	suite.templateRepo.EXPECT().GetByID(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	_, err := suite.service.CreateTaskFromTemplate(context.Background(), dtos.CreateTaskFromTemplateInput{
+		TemplateID: uuid.New(),
+		CreatorID:  suite.employer.ID,
+	})
+	suite.ErrorIs(err, task.ErrTemplateNotFound)
+}
+
+func TestTaskTemplateServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(TaskTemplateServiceTestSuite))
+}
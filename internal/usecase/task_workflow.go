@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"github.com/personal/task-management/internal/domain/task"
+	"github.com/spf13/viper"
+)
+
+// NewTaskWorkflow builds the task status graph from tasks.workflow. Each of
+// its transitions and reopen maps falls back to the default graph's when left
+// unset, so a deployment only configures the part it changes.
+func NewTaskWorkflow(cfg *viper.Viper) (task.Workflow, error) {
+	workflow := task.DefaultWorkflow()
+	if cfg.IsSet("tasks.workflow.transitions") {
+		workflow.Transitions = statusGraph(cfg.GetStringMapStringSlice("tasks.workflow.transitions"))
+	}
+	if cfg.IsSet("tasks.workflow.reopen") {
+		workflow.Reopen = statusGraph(cfg.GetStringMapStringSlice("tasks.workflow.reopen"))
+	}
+
+	if err := workflow.Validate(); err != nil {
+		return task.Workflow{}, err
+	}
+	return workflow, nil
+}
+
+func statusGraph(raw map[string][]string) map[task.Status][]task.Status {
+	graph := make(map[task.Status][]task.Status, len(raw))
+	for from, targets := range raw {
+		for _, to := range targets {
+			graph[task.Status(from)] = append(graph[task.Status(from)], task.Status(to))
+		}
+	}
+	return graph
+}
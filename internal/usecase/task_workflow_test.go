@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/personal/task-management/internal/domain/task"
+	"github.com/personal/task-management/internal/domain/user"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskWorkflowDefaultsWhenUnset(t *testing.T) {
+	workflow, err := NewTaskWorkflow(viper.New())
+	require.NoError(t, err)
+	assert.Equal(t, task.DefaultWorkflow(), workflow)
+}
+
+func TestTaskWorkflowFromConfig(t *testing.T) {
+	cfg := viper.New()
+	// A graph without completed; reopen is left unset and keeps its default
+	cfg.Set("tasks.workflow.transitions", map[string][]string{
+		"pending":     {"in_progress", "blocked"},
+		"in_progress": {"blocked", "pending"},
+		"blocked":     {"in_progress"},
+	})
+
+	workflow, err := NewTaskWorkflow(cfg)
+	require.NoError(t, err)
+	assert.False(t, workflow.Allows(task.StatusPending, task.StatusCompleted, user.Employee))
+	assert.True(t, workflow.Allows(task.StatusInProgress, task.StatusPending, user.Employee))
+	assert.True(t, workflow.Allows(task.StatusCompleted, task.StatusPending, user.Employer))
+}
+
+func TestTaskWorkflowRejectsUnknownStatuses(t *testing.T) {
+	for _, graph := range []map[string][]string{
+		{"pending": {"archived"}},
+		{"review": {"completed"}},
+		{"completed": {"deleted"}},
+	} {
+		cfg := viper.New()
+		cfg.Set("tasks.workflow.transitions", graph)
+		_, err := NewTaskWorkflow(cfg)
+		assert.ErrorIs(t, err, task.ErrInvalidWorkflow, graph)
+	}
+}
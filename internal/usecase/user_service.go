@@ -4,28 +4,45 @@ import (
 	"context"
 	"errors"
 	"log"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/personal/task-management/internal/delivery/rest/dtos"
 	"github.com/personal/task-management/internal/domain/user"
 	repository "github.com/personal/task-management/internal/repositories"
 	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/personal/task-management/pkg/utils/validate"
 )
 
 type UserService interface {
 	RegisterUser(ctx context.Context, input dtos.RegisterUserInput) (*dtos.GetUserOutput, error)
 	Login(ctx context.Context, input dtos.LoginInput) (*dtos.LoginOutput, error)
+	RefreshToken(ctx context.Context, input dtos.RefreshTokenInput) (*dtos.RefreshTokenOutput, error)
+	Logout(ctx context.Context, claims *jwt.UserClaims) error
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]*user.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error
 	GetUser(ctx context.Context, input dtos.GetUserInput) (*user.User, error)
 	UpdateUser(ctx context.Context, input dtos.UpdateUserInput) (*user.User, error)
-	ListUsers(ctx context.Context, input dtos.ListUsersInput) ([]*user.User, error)
+	ChangePassword(ctx context.Context, input dtos.ChangePasswordInput) error
+	DeleteUser(ctx context.Context, requesterID, targetID uuid.UUID) error
+	IsUserActive(ctx context.Context, userID uuid.UUID) (bool, error)
+	RecordActivity(ctx context.Context, userID uuid.UUID)
+	ListUsers(ctx context.Context, input dtos.ListUsersInput) ([]*user.User, int64, error)
+	SearchUsers(ctx context.Context, input dtos.SearchUsersInput) ([]*user.User, error)
+	ListActiveUsers(ctx context.Context, input dtos.ListActiveUsersInput) ([]*user.User, error)
 }
 
+// defaultActiveWindow is how far back ListActiveUsers looks by default
+const defaultActiveWindow = 15 * time.Minute
+
 // ErrInvalidCredentials is returned when authentication fails
 var ErrInvalidCredentials = errors.New("invalid email or password")
 
 // UserService handles user-related operations and business logic
 type userService struct {
 	userRepo     repository.UserRepository
+	sessionRepo  repository.SessionRepository
 	hasher       Hasher
 	tokenService jwt.JWTTokenServicer
 }
@@ -36,20 +53,29 @@ type Hasher interface {
 }
 
 // NewUserService creates a new instance of UserService
-func NewUserService(userRepo repository.UserRepository, hasher Hasher, tokenService jwt.JWTTokenServicer) UserService {
+func NewUserService(userRepo repository.UserRepository, sessionRepo repository.SessionRepository, hasher Hasher, tokenService jwt.JWTTokenServicer) UserService {
 	return &userService{
 		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
 		hasher:       hasher,
 		tokenService: tokenService,
 	}
 }
 
-// RegisterUser registers a new user
+// RegisterUser registers a new user. Invalid input, such as a malformed email
+// or a weak password, fails with validator.ValidationErrors.
 func (s *userService) RegisterUser(ctx context.Context, input dtos.RegisterUserInput) (*dtos.GetUserOutput, error) {
-	// Check if email already exists
-	existingUser, err := s.userRepo.GetByEmail(ctx, input.Email)
-	if err == nil && existingUser != nil {
+	if err := validate.Struct(input); err != nil {
+		return nil, err
+	}
+
+	// Only a missing user frees the email; a failed lookup proves nothing
+	_, err := s.userRepo.GetByEmail(ctx, input.Email)
+	switch {
+	case err == nil:
 		return nil, user.ErrEmailExists
+	case !errors.Is(err, user.ErrUserNotFound):
+		return nil, err
 	}
 
 	// Hash password
@@ -64,10 +90,10 @@ func (s *userService) RegisterUser(ctx context.Context, input dtos.RegisterUserI
 		input.Name,
 		hashedPassword,
 	)
-	newUser.SetRole(input.Role)
 	if err != nil {
 		return nil, err
 	}
+	newUser.SetRole(input.Role)
 
 	// Save user
 	if err := s.userRepo.Create(ctx, newUser); err != nil {
@@ -89,7 +115,15 @@ func (s *userService) RegisterUser(ctx context.Context, input dtos.RegisterUserI
 func (s *userService) Login(ctx context.Context, input dtos.LoginInput) (*dtos.LoginOutput, error) {
 	// Find user by email
 	u, err := s.userRepo.GetByEmail(ctx, input.Email)
+	if errors.Is(err, user.ErrUserNotFound) {
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
+		return nil, err
+	}
+
+	// Deleted users are turned away like unknown ones
+	if !u.IsActive() {
 		return nil, ErrInvalidCredentials
 	}
 
@@ -98,7 +132,22 @@ func (s *userService) Login(ctx context.Context, input dtos.LoginInput) (*dtos.L
 		return nil, ErrInvalidCredentials
 	}
 
-	token, err := s.tokenService.GenerateToken(u.ID, u.Email, u.Role.String())
+	s.RecordActivity(ctx, u.ID)
+
+	refreshToken, err := s.tokenService.IssueRefreshToken(u.ID, u.Email, u.Role.String())
+	if err != nil {
+		return nil, err
+	}
+	// The refresh token only works while its session exists
+	sessionID, err := uuid.Parse(refreshToken.ID)
+	if err != nil {
+		return nil, err
+	}
+	session := user.NewSession(sessionID, u.ID, input.Device, refreshToken.IssuedAt, refreshToken.ExpiresAt)
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	token, err := s.tokenService.IssueSessionToken(u.ID, u.Email, u.Role.String(), refreshToken.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -110,10 +159,95 @@ func (s *userService) Login(ctx context.Context, input dtos.LoginInput) (*dtos.L
 			Email: u.Email,
 			Role:  u.Role.String(),
 		},
-		AuthToken: token,
+		AuthToken:    token.Token,
+		RefreshToken: refreshToken.Token,
+		TokenType:    jwt.TokenTypeBearer,
+		IssuedAt:     token.IssuedAt,
+		ExpiresAt:    token.ExpiresAt,
 	}, nil
 }
 
+// RefreshToken exchanges a refresh token for a new access token. Tokens whose
+// session was revoked, that were issued before sessions were tracked, or whose
+// user is no longer active are rejected as revoked.
+func (s *userService) RefreshToken(ctx context.Context, input dtos.RefreshTokenInput) (*dtos.RefreshTokenOutput, error) {
+	claims, err := s.tokenService.ValidateRefreshToken(input.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	sessionID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, jwt.ErrRevokedToken
+	}
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.UserID != claims.UserID {
+		return nil, jwt.ErrRevokedToken
+	}
+	active, err := s.IsUserActive(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, jwt.ErrRevokedToken
+	}
+
+	token, err := s.tokenService.IssueSessionToken(claims.UserID, claims.Email, claims.Role, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Like presence, last use is best effort and must not block refreshing
+	if err := s.sessionRepo.TouchLastUsed(ctx, sessionID, time.Now()); err != nil {
+		log.Printf("failed to record last use of session %s: %v", sessionID, err)
+	}
+	s.RecordActivity(ctx, claims.UserID)
+	return &dtos.RefreshTokenOutput{AuthToken: token.Token}, nil
+}
+
+// Logout revokes the token the claims were read from and ends the session it
+// was issued for, so the session's refresh token stops working too. Tokens
+// issued before access tokens carried their session only revoke themselves.
+func (s *userService) Logout(ctx context.Context, claims *jwt.UserClaims) error {
+	if err := s.tokenService.RevokeToken(claims); err != nil {
+		return err
+	}
+	if claims.SessionID == "" {
+		return nil
+	}
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return nil
+	}
+	err = s.RevokeSession(ctx, claims.UserID, sessionID)
+	// The session may already have been revoked from another device
+	if errors.Is(err, user.ErrSessionNotFound) {
+		return nil
+	}
+	return err
+}
+
+// ListSessions returns the user's unexpired sessions, most recently used first
+func (s *userService) ListSessions(ctx context.Context, userID uuid.UUID) ([]*user.Session, error) {
+	return s.sessionRepo.ListActive(ctx, userID, time.Now())
+}
+
+// RevokeSession signs one of the user's devices out by deleting its session,
+// which stops its refresh token from working. Access tokens already issued to
+// the device stay valid until they expire.
+func (s *userService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	// Other users' sessions are reported as missing so IDs can't be probed
+	if session == nil || session.UserID != userID {
+		return user.ErrSessionNotFound
+	}
+	return s.sessionRepo.Delete(ctx, sessionID)
+}
+
 // GetUser retrieves a user by ID
 func (s *userService) GetUser(ctx context.Context, input dtos.GetUserInput) (*user.User, error) {
 	return s.userRepo.GetByID(ctx, *input.ID)
@@ -151,6 +285,128 @@ func (s *userService) UpdateUser(ctx context.Context, input dtos.UpdateUserInput
 	return u, nil
 }
 
-func (s *userService) ListUsers(ctx context.Context, input dtos.ListUsersInput) ([]*user.User, error) {
-	return s.userRepo.List(ctx, input.Offset, input.Limit)
+// ChangePassword sets a new password once the current one is verified,
+// failing with user.ErrWrongPassword otherwise
+func (s *userService) ChangePassword(ctx context.Context, input dtos.ChangePasswordInput) error {
+	u, err := s.userRepo.GetByID(ctx, input.UserID)
+	if err != nil {
+		return err
+	}
+	if !s.hasher.ComparePasswords(u.Password, input.CurrentPassword) {
+		return user.ErrWrongPassword
+	}
+
+	hashedPassword, err := s.hasher.HashPassword(input.NewPassword)
+	if err != nil {
+		return err
+	}
+	u.Password = hashedPassword
+	u.UpdatedAt = time.Now()
+	return s.userRepo.Update(ctx, u)
+}
+
+// DeleteUser soft-deletes the target user, which only employers may do. The
+// user is kept, inactive, so their tasks and messages still refer to someone;
+// they can no longer sign in or be assigned tasks, and their sessions are
+// removed so refresh tokens stop working. Access tokens already issued stay
+// valid until they expire. Deleting a deleted user does nothing.
+func (s *userService) DeleteUser(ctx context.Context, requesterID, targetID uuid.UUID) error {
+	requester, err := s.userRepo.GetByID(ctx, requesterID)
+	if err != nil {
+		return err
+	}
+	if !requester.IsEmployer() || !requester.IsActive() {
+		return user.ErrForbidden
+	}
+
+	target, err := s.userRepo.GetByID(ctx, targetID)
+	if err != nil {
+		return err
+	}
+	if target.DeletedAt != nil {
+		return nil
+	}
+	target.Deactivate(time.Now())
+	if err := s.userRepo.Update(ctx, target); err != nil {
+		return err
+	}
+	return s.sessionRepo.DeleteByUser(ctx, target.ID)
+}
+
+// RecordActivity marks the user as seen now, for ListActiveUsers. It is called
+// on login, token refresh and WebSocket connect. Presence is best effort, so
+// failures are logged rather than returned.
+func (s *userService) RecordActivity(ctx context.Context, userID uuid.UUID) {
+	if err := s.userRepo.TouchLastSeen(ctx, userID, time.Now()); err != nil {
+		log.Printf("failed to record last seen for user %s: %v", userID, err)
+	}
+}
+
+// IsUserActive reports whether the user exists and may still sign in
+func (s *userService) IsUserActive(ctx context.Context, userID uuid.UUID) (bool, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if errors.Is(err, user.ErrUserNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return u.IsActive(), nil
+}
+
+// ListUsers returns a page of the users matching the input and how many match
+// in total
+func (s *userService) ListUsers(ctx context.Context, input dtos.ListUsersInput) ([]*user.User, int64, error) {
+	filter := repository.UserFilter{
+		Search:    strings.TrimSpace(input.Search),
+		SortBy:    input.SortBy,
+		SortOrder: input.Sort,
+		Offset:    input.Offset,
+		Limit:     input.Limit,
+	}
+	if input.Role != "" {
+		role := user.ParseRole(input.Role)
+		if role == user.Unknown {
+			return nil, 0, user.ErrInvalidRole
+		}
+		filter.Role = &role
+	}
+	if input.Status != "" {
+		status, err := user.ParseStatus(input.Status)
+		if err != nil {
+			return nil, 0, err
+		}
+		filter.Status = &status
+	}
+
+	users, err := s.userRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.userRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// SearchUsers returns users whose name starts with the query, ignoring case
+func (s *userService) SearchUsers(ctx context.Context, input dtos.SearchUsersInput) ([]*user.User, error) {
+	query := strings.TrimSpace(input.Query)
+	if query == "" {
+		return nil, user.ErrEmptySearch
+	}
+	return s.userRepo.SearchByNamePrefix(ctx, query, input.Limit, input.SharedWith)
+}
+
+// ListActiveUsers returns users seen within the input window, most recent first
+func (s *userService) ListActiveUsers(ctx context.Context, input dtos.ListActiveUsersInput) ([]*user.User, error) {
+	within := input.Within
+	if within == 0 {
+		within = defaultActiveWindow
+	}
+	if within < 0 {
+		return nil, user.ErrInvalidWindow
+	}
+	return s.userRepo.ListRecentlyActive(ctx, time.Now().Add(-within), input.Limit)
 }
@@ -0,0 +1,397 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/delivery/rest/dtos"
+	"github.com/personal/task-management/internal/domain/user"
+	"github.com/personal/task-management/internal/mocks"
+	"github.com/personal/task-management/internal/usecase"
+	"github.com/personal/task-management/pkg/utils/jwt"
+	"github.com/stretchr/testify/suite"
+)
+
+type UserServiceTestSuite struct {
+	suite.Suite
+	ctrl        *gomock.Controller
+	userRepo    *mocks.MockUserRepository
+	sessionRepo *mocks.MockSessionRepository
+	hasher      *mocks.MockHasher
+	tokens      *mocks.MockJWTTokenServicer
+	service     usecase.UserService
+}
+
+func (suite *UserServiceTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.userRepo = mocks.NewMockUserRepository(suite.ctrl)
+	suite.sessionRepo = mocks.NewMockSessionRepository(suite.ctrl)
+	suite.hasher = mocks.NewMockHasher(suite.ctrl)
+	suite.tokens = mocks.NewMockJWTTokenServicer(suite.ctrl)
+	suite.service = usecase.NewUserService(suite.userRepo, suite.sessionRepo, suite.hasher, suite.tokens)
+}
+
+func (suite *UserServiceTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func (suite *UserServiceTestSuite) TestRegisterUserWithFreeEmail() {
+	input := dtos.RegisterUserInput{Email: "alice@example.com", Name: "Alice", Password: "s3cret-pass", Role: "employee"}
+	suite.userRepo.EXPECT().GetByEmail(gomock.Any(), input.Email).Return(nil, user.ErrUserNotFound)
+	suite.hasher.EXPECT().HashPassword("s3cret-pass").Return("hashed", nil)
+	suite.userRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, u *user.User) error {
+		suite.Equal(input.Email, u.Email)
+		suite.Equal("hashed", u.Password)
+		suite.Equal(user.Employee, u.Role)
+		return nil
+	})
+
+	output, err := suite.service.RegisterUser(context.Background(), input)
+	suite.Require().NoError(err)
+	suite.Equal("employee", output.Role)
+}
+
+func (suite *UserServiceTestSuite) TestRegisterManager() {
+	input := dtos.RegisterUserInput{Email: "maria@example.com", Name: "Maria", Password: "s3cret-pass", Role: "manager"}
+	suite.userRepo.EXPECT().GetByEmail(gomock.Any(), input.Email).Return(nil, user.ErrUserNotFound)
+	suite.hasher.EXPECT().HashPassword("s3cret-pass").Return("hashed", nil)
+	suite.userRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, u *user.User) error {
+		suite.Equal(user.Manager, u.Role)
+		return nil
+	})
+
+	output, err := suite.service.RegisterUser(context.Background(), input)
+	suite.Require().NoError(err)
+	suite.Equal("manager", output.Role)
+}
+
+func (suite *UserServiceTestSuite) TestRegisterUserWithTakenEmail() {
+	input := dtos.RegisterUserInput{Email: "alice@example.com", Name: "Alice", Password: "s3cret-pass", Role: "employee"}
+	suite.userRepo.EXPECT().GetByEmail(gomock.Any(), input.Email).Return(&user.User{ID: uuid.New(), Email: input.Email}, nil)
+
+	_, err := suite.service.RegisterUser(context.Background(), input)
+	suite.ErrorIs(err, user.ErrEmailExists)
+}
+
+func (suite *UserServiceTestSuite) TestRegisterUserFailsWhenLookupFails() {
+	input := dtos.RegisterUserInput{Email: "alice@example.com", Name: "Alice", Password: "s3cret-pass", Role: "employee"}
+	dbErr := errors.New("db down")
+	// Without an answer the email can't be assumed free, so nothing is created
+	suite.userRepo.EXPECT().GetByEmail(gomock.Any(), input.Email).Return(nil, dbErr)
+
+	_, err := suite.service.RegisterUser(context.Background(), input)
+	suite.ErrorIs(err, dbErr)
+	suite.NotErrorIs(err, user.ErrEmailExists)
+}
+
+func (suite *UserServiceTestSuite) TestRegisterUserValidatesInput() {
+	inputs := map[string]dtos.RegisterUserInput{
+		"malformed email": {Email: "alice.example.com", Name: "Alice", Password: "s3cret-pass", Role: "employee"},
+		"short password":  {Email: "alice@example.com", Name: "Alice", Password: "s3cret", Role: "employee"},
+		"no digit":        {Email: "alice@example.com", Name: "Alice", Password: "secret-pass", Role: "employee"},
+		"no letter":       {Email: "alice@example.com", Name: "Alice", Password: "12345678", Role: "employee"},
+	}
+	for name, input := range inputs {
+		suite.Run(name, func() {
+			// Rejected before anything is looked up or stored
+			_, err := suite.service.RegisterUser(context.Background(), input)
+			var validationErrors validator.ValidationErrors
+			suite.Require().ErrorAs(err, &validationErrors)
+			suite.Len(validationErrors, 1)
+		})
+	}
+}
+
+func (suite *UserServiceTestSuite) TestLoginReportsLookupFailures() {
+	dbErr := errors.New("db down")
+	suite.userRepo.EXPECT().GetByEmail(gomock.Any(), "alice@example.com").Return(nil, dbErr)
+	_, err := suite.service.Login(context.Background(), dtos.LoginInput{Email: "alice@example.com", Password: "secret"})
+	suite.ErrorIs(err, dbErr)
+
+	suite.userRepo.EXPECT().GetByEmail(gomock.Any(), "bob@example.com").Return(nil, user.ErrUserNotFound)
+	_, err = suite.service.Login(context.Background(), dtos.LoginInput{Email: "bob@example.com", Password: "secret"})
+	suite.ErrorIs(err, usecase.ErrInvalidCredentials)
+}
+
+func (suite *UserServiceTestSuite) TestLoginReturnsTokenLifetime() {
+	u := &user.User{ID: uuid.New(), Email: "alice@example.com", Name: "Alice", Password: "hashed", Role: user.Employee}
+	issuedAt := time.Now().Truncate(time.Second)
+	expiresAt := issuedAt.Add(time.Hour)
+
+	suite.userRepo.EXPECT().GetByEmail(gomock.Any(), u.Email).Return(u, nil)
+	suite.hasher.EXPECT().ComparePasswords("hashed", "secret").Return(true)
+	suite.userRepo.EXPECT().TouchLastSeen(gomock.Any(), u.ID, gomock.Any()).Return(nil)
+	sessionID := uuid.New()
+	suite.tokens.EXPECT().IssueRefreshToken(u.ID, u.Email, "employee").
+		Return(&jwt.IssuedToken{ID: sessionID.String(), Token: "refresh", IssuedAt: issuedAt, ExpiresAt: issuedAt.Add(24 * time.Hour)}, nil)
+	// The access token is bound to the session so logging out can end it
+	suite.tokens.EXPECT().IssueSessionToken(u.ID, u.Email, "employee", sessionID.String()).
+		Return(&jwt.IssuedToken{Token: "access", IssuedAt: issuedAt, ExpiresAt: expiresAt}, nil)
+	suite.sessionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, session *user.Session) error {
+		suite.Equal(sessionID, session.ID)
+		suite.Equal(u.ID, session.UserID)
+		suite.Equal("laptop", session.Device)
+		suite.Equal(issuedAt, session.LastUsedAt)
+		return nil
+	})
+
+	output, err := suite.service.Login(context.Background(), dtos.LoginInput{Email: u.Email, Password: "secret", Device: "laptop"})
+	suite.Require().NoError(err)
+	suite.Equal("access", output.AuthToken)
+	suite.Equal("refresh", output.RefreshToken)
+	suite.Equal("Bearer", output.TokenType)
+	suite.Equal(issuedAt, output.IssuedAt)
+	suite.Equal(expiresAt, output.ExpiresAt)
+}
+
+func (suite *UserServiceTestSuite) TestLoginSurvivesLastSeenFailure() {
+	u := &user.User{ID: uuid.New(), Email: "alice@example.com", Password: "hashed"}
+
+	suite.userRepo.EXPECT().GetByEmail(gomock.Any(), u.Email).Return(u, nil)
+	suite.hasher.EXPECT().ComparePasswords("hashed", "secret").Return(true)
+	suite.userRepo.EXPECT().TouchLastSeen(gomock.Any(), u.ID, gomock.Any()).Return(errors.New("db down"))
+	suite.tokens.EXPECT().IssueRefreshToken(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&jwt.IssuedToken{ID: uuid.NewString(), Token: "refresh"}, nil)
+	suite.sessionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+	suite.tokens.EXPECT().IssueSessionToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&jwt.IssuedToken{Token: "access"}, nil)
+
+	output, err := suite.service.Login(context.Background(), dtos.LoginInput{Email: u.Email, Password: "secret"})
+	suite.NoError(err)
+	suite.Equal("access", output.AuthToken)
+}
+
+func (suite *UserServiceTestSuite) TestLoginRejectsWrongPassword() {
+	u := &user.User{ID: uuid.New(), Email: "alice@example.com", Password: "hashed"}
+
+	suite.userRepo.EXPECT().GetByEmail(gomock.Any(), u.Email).Return(u, nil)
+	suite.hasher.EXPECT().ComparePasswords("hashed", "wrong").Return(false)
+
+	_, err := suite.service.Login(context.Background(), dtos.LoginInput{Email: u.Email, Password: "wrong"})
+	suite.ErrorIs(err, usecase.ErrInvalidCredentials)
+}
+
+func (suite *UserServiceTestSuite) TestRefreshTokenTouchesSession() {
+	claims := &jwt.UserClaims{UserID: uuid.New(), Email: "alice@example.com", Role: "employee"}
+	claims.ID = uuid.NewString()
+	session := &user.Session{ID: uuid.MustParse(claims.ID), UserID: claims.UserID}
+
+	suite.tokens.EXPECT().ValidateRefreshToken("refresh").Return(claims, nil)
+	suite.sessionRepo.EXPECT().GetByID(gomock.Any(), session.ID).Return(session, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), claims.UserID).Return(&user.User{ID: claims.UserID, Status: user.StatusActive}, nil)
+	suite.tokens.EXPECT().IssueSessionToken(claims.UserID, claims.Email, claims.Role, claims.ID).Return(&jwt.IssuedToken{Token: "access"}, nil)
+	suite.sessionRepo.EXPECT().TouchLastUsed(gomock.Any(), session.ID, gomock.Any()).Return(nil)
+	// Staying signed in through refreshes keeps the user present
+	suite.userRepo.EXPECT().TouchLastSeen(gomock.Any(), claims.UserID, gomock.Any()).Return(nil)
+
+	output, err := suite.service.RefreshToken(context.Background(), dtos.RefreshTokenInput{RefreshToken: "refresh"})
+	suite.Require().NoError(err)
+	suite.Equal("access", output.AuthToken)
+}
+
+func (suite *UserServiceTestSuite) TestRefreshTokenRejectsRevokedSession() {
+	claims := &jwt.UserClaims{UserID: uuid.New()}
+	claims.ID = uuid.NewString()
+
+	suite.tokens.EXPECT().ValidateRefreshToken("refresh").Return(claims, nil)
+	suite.sessionRepo.EXPECT().GetByID(gomock.Any(), uuid.MustParse(claims.ID)).Return(nil, nil)
+
+	_, err := suite.service.RefreshToken(context.Background(), dtos.RefreshTokenInput{RefreshToken: "refresh"})
+	suite.ErrorIs(err, jwt.ErrRevokedToken)
+}
+
+func (suite *UserServiceTestSuite) TestRefreshTokenRejectsInactiveUser() {
+	claims := &jwt.UserClaims{UserID: uuid.New()}
+	claims.ID = uuid.NewString()
+	session := &user.Session{ID: uuid.MustParse(claims.ID), UserID: claims.UserID}
+
+	suite.tokens.EXPECT().ValidateRefreshToken("refresh").Return(claims, nil)
+	suite.sessionRepo.EXPECT().GetByID(gomock.Any(), session.ID).Return(session, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), claims.UserID).Return(&user.User{ID: claims.UserID, Status: user.StatusInactive}, nil)
+
+	_, err := suite.service.RefreshToken(context.Background(), dtos.RefreshTokenInput{RefreshToken: "refresh"})
+	suite.ErrorIs(err, jwt.ErrRevokedToken)
+}
+
+func (suite *UserServiceTestSuite) TestRefreshTokenRejectsTokenWithoutSession() {
+	// Refresh tokens issued before sessions were tracked carry no ID
+	suite.tokens.EXPECT().ValidateRefreshToken("legacy").Return(&jwt.UserClaims{UserID: uuid.New()}, nil)
+
+	_, err := suite.service.RefreshToken(context.Background(), dtos.RefreshTokenInput{RefreshToken: "legacy"})
+	suite.ErrorIs(err, jwt.ErrRevokedToken)
+}
+
+func (suite *UserServiceTestSuite) TestLogoutEndsSession() {
+	claims := &jwt.UserClaims{UserID: uuid.New(), SessionID: uuid.NewString()}
+	sessionID := uuid.MustParse(claims.SessionID)
+	session := &user.Session{ID: sessionID, UserID: claims.UserID}
+
+	suite.tokens.EXPECT().RevokeToken(claims).Return(nil)
+	gomock.InOrder(
+		suite.sessionRepo.EXPECT().GetByID(gomock.Any(), sessionID).Return(session, nil),
+		suite.sessionRepo.EXPECT().Delete(gomock.Any(), sessionID).Return(nil),
+	)
+	suite.Require().NoError(suite.service.Logout(context.Background(), claims))
+
+	// The session's refresh token no longer works once it is gone
+	refreshClaims := &jwt.UserClaims{UserID: claims.UserID}
+	refreshClaims.ID = claims.SessionID
+	suite.tokens.EXPECT().ValidateRefreshToken("refresh").Return(refreshClaims, nil)
+	suite.sessionRepo.EXPECT().GetByID(gomock.Any(), sessionID).Return(nil, nil)
+
+	_, err := suite.service.RefreshToken(context.Background(), dtos.RefreshTokenInput{RefreshToken: "refresh"})
+	suite.ErrorIs(err, jwt.ErrRevokedToken)
+}
+
+func (suite *UserServiceTestSuite) TestLogoutWithRevokedSession() {
+	claims := &jwt.UserClaims{UserID: uuid.New(), SessionID: uuid.NewString()}
+	suite.tokens.EXPECT().RevokeToken(claims).Return(nil)
+	suite.sessionRepo.EXPECT().GetByID(gomock.Any(), uuid.MustParse(claims.SessionID)).Return(nil, nil)
+
+	suite.NoError(suite.service.Logout(context.Background(), claims))
+}
+
+func (suite *UserServiceTestSuite) TestLogoutWithoutSessionOnlyRevokesToken() {
+	// Access tokens issued before they carried their session
+	claims := &jwt.UserClaims{UserID: uuid.New()}
+	suite.tokens.EXPECT().RevokeToken(claims).Return(nil)
+
+	suite.NoError(suite.service.Logout(context.Background(), claims))
+}
+
+func (suite *UserServiceTestSuite) TestListSessions() {
+	userID := uuid.New()
+	sessions := []*user.Session{{ID: uuid.New(), UserID: userID}}
+	suite.sessionRepo.EXPECT().ListActive(gomock.Any(), userID, gomock.Any()).Return(sessions, nil)
+
+	got, err := suite.service.ListSessions(context.Background(), userID)
+	suite.Require().NoError(err)
+	suite.Equal(sessions, got)
+}
+
+func (suite *UserServiceTestSuite) TestRevokeSession() {
+	userID := uuid.New()
+	session := &user.Session{ID: uuid.New(), UserID: userID}
+	suite.sessionRepo.EXPECT().GetByID(gomock.Any(), session.ID).Return(session, nil)
+	suite.sessionRepo.EXPECT().Delete(gomock.Any(), session.ID).Return(nil)
+
+	suite.NoError(suite.service.RevokeSession(context.Background(), userID, session.ID))
+}
+
+func (suite *UserServiceTestSuite) TestRevokeSessionOfAnotherUserIsNotFound() {
+	session := &user.Session{ID: uuid.New(), UserID: uuid.New()}
+	suite.sessionRepo.EXPECT().GetByID(gomock.Any(), session.ID).Return(session, nil)
+
+	err := suite.service.RevokeSession(context.Background(), uuid.New(), session.ID)
+	suite.ErrorIs(err, user.ErrSessionNotFound)
+}
+
+func (suite *UserServiceTestSuite) TestRevokeMissingSessionIsNotFound() {
+	sessionID := uuid.New()
+	suite.sessionRepo.EXPECT().GetByID(gomock.Any(), sessionID).Return(nil, nil)
+
+	err := suite.service.RevokeSession(context.Background(), uuid.New(), sessionID)
+	suite.ErrorIs(err, user.ErrSessionNotFound)
+}
+
+func (suite *UserServiceTestSuite) TestLoginRejectsInactiveUser() {
+	u := &user.User{ID: uuid.New(), Email: "alice@example.com", Password: "hashed", Role: user.Employee, Status: user.StatusInactive}
+	suite.userRepo.EXPECT().GetByEmail(gomock.Any(), u.Email).Return(u, nil)
+
+	_, err := suite.service.Login(context.Background(), dtos.LoginInput{Email: u.Email, Password: "secret"})
+	suite.ErrorIs(err, usecase.ErrInvalidCredentials)
+}
+
+func (suite *UserServiceTestSuite) TestIsUserActive() {
+	active := &user.User{ID: uuid.New(), Status: user.StatusActive}
+	missing := uuid.New()
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), active.ID).Return(active, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), missing).Return(nil, user.ErrUserNotFound)
+
+	ok, err := suite.service.IsUserActive(context.Background(), active.ID)
+	suite.NoError(err)
+	suite.True(ok)
+	ok, err = suite.service.IsUserActive(context.Background(), missing)
+	suite.NoError(err)
+	suite.False(ok)
+}
+
+func (suite *UserServiceTestSuite) TestChangePassword() {
+	u := &user.User{ID: uuid.New(), Password: "old-hash"}
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), u.ID).Return(u, nil)
+	suite.hasher.EXPECT().ComparePasswords("old-hash", "old-password").Return(true)
+	suite.hasher.EXPECT().HashPassword("new-password").Return("new-hash", nil)
+	suite.userRepo.EXPECT().Update(gomock.Any(), u).Return(nil)
+
+	err := suite.service.ChangePassword(context.Background(), dtos.ChangePasswordInput{UserID: u.ID, CurrentPassword: "old-password", NewPassword: "new-password"})
+	suite.NoError(err)
+	suite.Equal("new-hash", u.Password)
+}
+
+func (suite *UserServiceTestSuite) TestChangePasswordRejectsWrongCurrentPassword() {
+	u := &user.User{ID: uuid.New(), Password: "old-hash"}
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), u.ID).Return(u, nil)
+	suite.hasher.EXPECT().ComparePasswords("old-hash", "guess").Return(false)
+
+	err := suite.service.ChangePassword(context.Background(), dtos.ChangePasswordInput{UserID: u.ID, CurrentPassword: "guess", NewPassword: "new-password"})
+	suite.ErrorIs(err, user.ErrWrongPassword)
+	suite.Equal("old-hash", u.Password)
+}
+
+func (suite *UserServiceTestSuite) TestDeleteUserRequiresEmployer() {
+	for _, role := range []user.Role{user.Employee, user.Manager} {
+		requester := &user.User{ID: uuid.New(), Role: role}
+		suite.userRepo.EXPECT().GetByID(gomock.Any(), requester.ID).Return(requester, nil)
+
+		err := suite.service.DeleteUser(context.Background(), requester.ID, uuid.New())
+		suite.ErrorIs(err, user.ErrForbidden, role.String())
+	}
+}
+
+func (suite *UserServiceTestSuite) TestDeleteUserSoftDeletes() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	target := &user.User{ID: uuid.New(), Role: user.Employee, Status: user.StatusActive}
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), target.ID).Return(target, nil)
+	suite.userRepo.EXPECT().Update(gomock.Any(), target).DoAndReturn(func(_ context.Context, u *user.User) error {
+		suite.Equal(user.StatusInactive, u.Status)
+		suite.NotNil(u.DeletedAt)
+		return nil
+	})
+	suite.sessionRepo.EXPECT().DeleteByUser(gomock.Any(), target.ID).Return(nil)
+
+	suite.NoError(suite.service.DeleteUser(context.Background(), employer.ID, target.ID))
+	suite.False(target.CanBeAssignedTasks())
+
+	// Deleting again changes nothing
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), target.ID).Return(target, nil)
+	suite.NoError(suite.service.DeleteUser(context.Background(), employer.ID, target.ID))
+}
+
+func (suite *UserServiceTestSuite) TestDeleteMissingUserIsNotFound() {
+	employer := &user.User{ID: uuid.New(), Role: user.Employer}
+	missing := uuid.New()
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), employer.ID).Return(employer, nil)
+	suite.userRepo.EXPECT().GetByID(gomock.Any(), missing).Return(nil, user.ErrUserNotFound)
+
+	suite.ErrorIs(suite.service.DeleteUser(context.Background(), employer.ID, missing), user.ErrUserNotFound)
+}
+
+func (suite *UserServiceTestSuite) TestLoginRejectsDeletedUser() {
+	u := &user.User{ID: uuid.New(), Email: "alice@example.com", Password: "hashed", Role: user.Employee}
+	u.Deactivate(time.Now())
+	suite.userRepo.EXPECT().GetByEmail(gomock.Any(), u.Email).Return(u, nil)
+
+	// Rejected even with the right password, and no session is created
+	_, err := suite.service.Login(context.Background(), dtos.LoginInput{Email: u.Email, Password: "secret"})
+	suite.ErrorIs(err, usecase.ErrInvalidCredentials)
+}
+
+func TestUserServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(UserServiceTestSuite))
+}
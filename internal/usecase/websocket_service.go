@@ -1,87 +1,374 @@
 package usecase
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"mime"
+	"slices"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/personal/task-management/internal/domain"
 	"github.com/personal/task-management/internal/repositories"
+	"github.com/personal/task-management/pkg/cache"
+	"github.com/personal/task-management/pkg/ratelimit"
+	"github.com/personal/task-management/pkg/utils/pagination"
+	"github.com/spf13/viper"
 )
 
 type WebSocketService interface {
 	// Connection management
-	HandleConnection(conn *websocket.Conn, userID string)
+	HandleConnection(conn *websocket.Conn, userID, role string)
+	HeartbeatStats() domain.HeartbeatStats
 
 	// Room operations
 	CreateDirectRoom(userID1, userID2 string) (*domain.Room, error)
-	CreateGroupRoom(name string, userIDs []string) (*domain.Room, error)
+	GetDirectRoomID(userID1, userID2 string) (string, bool, error)
+	CreateGroupRoom(name, creatorID string, userIDs []string, idempotencyKey string) (*domain.Room, error)
 	JoinRoom(roomID, userID string) error
 	LeaveRoom(roomID, userID string) error
+	DeleteRoom(roomID, userID string, isEmployer bool) error
+	GetRoomDetails(roomID, userID string) (*domain.Room, error)
+	ListRoomMembers(roomID, userID string) ([]string, error)
+	AddMember(roomID, adderID, userID string, isEmployer bool) error
+	RemoveMember(roomID, removerID, userID string, isEmployer bool) error
+	PromoteToAdmin(roomID, promoterID, userID string, isEmployer bool) error
+	DemoteMember(roomID, demoterID, userID string, isEmployer bool) error
 
 	// Message operations
-	SendDirectMessage(senderID, receiverID, content string) error
-	SendGroupMessage(roomID, userID, content string) error
-	SendFileMessage(roomID, userID, fileURL, fileName string, fileSize int64, fileType string) error
-	SendImageMessage(roomID, userID, imageURL, thumbnailURL string) error
-	SendVideoMessage(roomID, userID, videoURL, thumbnailURL string, duration int) error
-	SendAudioMessage(roomID, userID, audioURL string, duration int) error
+	SendDirectMessage(senderID, receiverID, content string) (*domain.Message, error)
+	SendGroupMessage(roomID, userID, content string) (*domain.Message, error)
+	SendFileMessage(roomID, userID, fileURL, fileName string, fileSize int64, fileType string) (*domain.Message, error)
+	SendImageMessage(roomID, userID, imageURL, thumbnailURL string, fileSize int64, fileType string) (*domain.Message, error)
+	SendVideoMessage(roomID, userID, videoURL, thumbnailURL string, fileSize int64, fileType string, duration int) (*domain.Message, error)
+	SendAudioMessage(roomID, userID, audioURL string, fileSize int64, fileType string, duration int) (*domain.Message, error)
 	SendTypingIndicator(roomID, userID string) error
 	MarkMessageAsRead(roomID, userID, messageID string) error
-	PinMessage(roomID, messageID string) error
-	UnpinMessage(roomID, messageID string) error
+	PinMessage(roomID, userID, messageID string) error
+	UnpinMessage(roomID, userID, messageID string) error
+	GetPinnedMessages(roomID, userID string) ([]domain.Message, error)
 
 	// Room management
-	ListRooms(userID string) ([]*domain.Room, error)
+	ListRooms(userID string, filter repositories.RoomFilter) ([]*domain.Room, error)
 	ArchiveRoom(roomID, userID string) error
 	UnarchiveRoom(roomID, userID string) error
 	MuteRoom(roomID, userID string) error
 	UnmuteRoom(roomID, userID string) error
-	UpdateRoomInfo(roomID, name, description, avatarURL string) error
+	MuteRoomMember(roomID, userID, memberID string) error
+	UnmuteRoomMember(roomID, userID, memberID string) error
+	UpdateRoomInfo(roomID, userID, name, description, avatarURL string, isEmployer bool) error
+	SetRoomSlowMode(roomID string, interval time.Duration) error
 
 	// History and status
-	GetRoomHistory(roomID string, limit, offset int) ([]domain.WebSocketMessage, error)
+	GetRoomHistory(roomID, userID string, limit, offset int, order domain.HistoryOrder) ([]domain.WebSocketMessage, error)
+	GetRoomHistoryBefore(roomID, userID, beforeMessageID string, limit int) ([]domain.WebSocketMessage, bool, error)
+	GetMissingMessages(roomID, userID string, lastSequence int64, limit int) ([]domain.WebSocketMessage, error)
+	GetReadReceipts(roomID, userID, messageID string) ([]domain.ReadReceipt, error)
+	SearchAllMessages(userID, query string, limit, offset int) ([]*domain.MessageSearchResult, error)
 	GetUnreadCount(roomID, userID string) (int, error)
+	GetUnreadCounts(userID string) (map[string]int, error)
+	GetChatStats(from, to time.Time, topRooms int) (*domain.ChatStats, error)
 
 	// Notification operations
 	SendTaskUpdateNotification(userID, taskID, taskTitle, taskStatus string) error
+	SendTaskSummaryUpdate(role string) error
 	SendMentionNotification(userID, senderID, content string) error
 	SendSystemNotification(userID, title, content string) error
-	MarkNotificationAsRead(notificationID string) error
+	RegisterNotifier(channel string, notifier Notifier)
+	ListNotifications(userID string, limit, offset int) ([]*domain.Notification, error)
+	MarkNotificationAsRead(notificationID, userID string) error
 	GetUnreadNotificationCount(userID string) (int, error)
+	GetNotificationPreferences(userID string) ([]*domain.NotificationPreference, error)
+	SetNotificationPreference(userID, channel, notificationType string, enabled bool) (*domain.NotificationPreference, error)
+
+	// Close stops the hub goroutine and cancels every connection without
+	// waiting for them to wind down; Shutdown also waits until ctx is done
+	Close() error
+	Shutdown(ctx context.Context) error
+}
+
+const (
+	// sendBufferSize is the number of outbound messages queued per connection
+	// before the hub treats it as dead
+	sendBufferSize = 256
+
+	// Clients must answer a ping within pongWait; pings go out every
+	// pingPeriod, which has to be shorter than pongWait
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+	writeWait  = 10 * time.Second
+
+	// defaultMaxHistoryMessages bounds how many messages a single history
+	// request may ask for
+	defaultMaxHistoryMessages = 500
+
+	defaultOfflineNotifyThreshold = 10 * time.Minute
+
+	// Chat statistics rank this many rooms by default, and never more than
+	// maxStatsTopRooms or over a range longer than maxStatsRange
+	defaultStatsTopRooms = 5
+	maxStatsTopRooms     = 50
+	maxStatsRange        = 366 * 24 * time.Hour
+
+	// maxSlowModeInterval caps how long slow mode can make users wait
+	maxSlowModeInterval = time.Hour
+
+	// defaultIdempotencyTTL is how long a group creation idempotency key
+	// keeps returning the room it created
+	defaultIdempotencyTTL = 10 * time.Minute
+
+	// A user's typing events reach the room at most once per typingDebounce,
+	// and a stop_typing event follows their last one after the typing timeout
+	typingDebounce       = time.Second
+	defaultTypingTimeout = 5 * time.Second
+
+	// Up to defaultRegisterQueueSize connections may wait for a busy hub to
+	// register them; past that a new one waits defaultRegisterTimeout for room
+	// before it is turned away
+	defaultRegisterQueueSize = 64
+	defaultRegisterTimeout   = 5 * time.Second
+
+	// defaultMaxFileSize caps attachments when chat.max_file_size is unset
+	defaultMaxFileSize = 10 << 20
+
+	// defaultMaxPinnedMessages caps a room's pins when
+	// chat.max_pinned_messages is unset
+	defaultMaxPinnedMessages = 50
+
+	// defaultMessagesPerMinute is how many messages a user may send in a
+	// minute when chat.messages_per_minute is unset
+	defaultMessagesPerMinute = 60
+
+	// defaultMaxMessageLength caps a text message's characters when
+	// chat.max_message_length is unset
+	defaultMaxMessageLength = 4000
+
+	// maxFrameOverhead is what a client frame may take beyond its content:
+	// the other fields and the JSON around them
+	maxFrameOverhead = 4 << 10
+)
+
+// defaultAllowedFileTypes are the types file messages accept when
+// chat.allowed_file_types is unset. A subtype of * matches any subtype.
+var defaultAllowedFileTypes = []string{
+	"image/*", "video/*", "audio/*",
+	"application/pdf", "application/zip", "text/plain", "text/csv",
+}
+
+// mediaFileTypes is what image, video and audio messages accept, whatever
+// file messages allow
+var mediaFileTypes = map[string]string{
+	domain.MessageTypeImage: "image/*",
+	domain.MessageTypeVideo: "video/*",
+	domain.MessageTypeAudio: "audio/*",
 }
 
 type websocketService struct {
 	hub      *domain.Hub
 	roomRepo repositories.ChatRepository
 	mu       sync.RWMutex
+
+	paginator          *pagination.Paginator
+	maxHistoryMessages int
+
+	// idempotencyKeys maps group creation keys to the rooms they created so
+	// retried requests do not create duplicates
+	idempotencyKeys cache.Cache
+	idempotencyTTL  time.Duration
+	idempotencyMu   sync.Mutex
+
+	// offlineNotifier reaches users who have been disconnected for longer
+	// than offlineThreshold; lastSeen records when each user disconnected,
+	// and users not seen since startedAt count as last seen then
+	offlineNotifier  Notifier
+	offlineThreshold time.Duration
+	lastSeen         map[string]time.Time
+	startedAt        time.Time
+
+	notifiers []registeredNotifier
+
+	// pinMu serializes changes to rooms' pinned messages so two pins can't
+	// both pass the maxPinnedMessages check
+	pinMu sync.Mutex
+
+	// Read notifications beyond the newest maxNotifications, or older than
+	// notificationMaxAge, are trimmed whenever a new one is stored. Zero
+	// disables either limit.
+	maxNotifications   int
+	notificationMaxAge time.Duration
+
+	// pumps tracks the read and write goroutines of every live connection
+	pumps sync.WaitGroup
+
+	// ctx parents every connection; stop cancels it to shut the service down
+	ctx  context.Context
+	stop context.CancelFunc
+
+	// typing holds the pending stop_typing timer of everyone typing
+	typing        map[typingKey]*typingState
+	typingMu      sync.Mutex
+	typingTimeout time.Duration
+
+	// now and afterFunc read and schedule on the clock; tests replace them
+	now       func() time.Time
+	afterFunc func(time.Duration, func()) timer
+
+	// registerTimeout bounds how long HandleConnection waits for the hub
+	registerTimeout time.Duration
+
+	// Attachments over maxFileSize bytes are rejected, as are file messages
+	// whose type is not in allowedFileTypes
+	maxFileSize      int64
+	allowedFileTypes []string
+
+	// maxPinnedMessages caps how many messages a room can have pinned
+	maxPinnedMessages int
+
+	// maxMessageLength caps how many characters a text message may have
+	maxMessageLength int
+
+	// sendLimiter holds each user to chat.messages_per_minute messages
+	sendLimiter *ratelimit.Limiter
+}
+
+type registeredNotifier struct {
+	channel  string
+	notifier Notifier
+}
+
+// timer is the part of *time.Timer the service uses
+type timer interface {
+	Stop() bool
+}
+
+type typingKey struct {
+	roomID string
+	userID string
+}
+
+// typingState is replaced on every typing event, so a timer that fires
+// after being superseded can tell by comparing pointers
+type typingState struct {
+	lastSent time.Time
+	timer    timer
+}
+
+func NewWebSocketService(cfg *viper.Viper, roomRepo repositories.ChatRepository, offlineNotifier Notifier, idempotencyKeys cache.Cache) WebSocketService {
+	service := newWebSocketService(cfg, roomRepo, offlineNotifier, idempotencyKeys)
+	go service.runHub()
+	return service
 }
 
-func NewWebSocketService(roomRepo repositories.ChatRepository) WebSocketService {
+// newWebSocketService builds the service without starting its hub goroutine
+func newWebSocketService(cfg *viper.Viper, roomRepo repositories.ChatRepository, offlineNotifier Notifier, idempotencyKeys cache.Cache) *websocketService {
+	registerQueueSize := cfg.GetInt("websocket.register_queue_size")
+	if registerQueueSize <= 0 {
+		registerQueueSize = defaultRegisterQueueSize
+	}
+
 	hub := &domain.Hub{
 		Rooms:         make(map[string]*domain.Room),
 		Connections:   make(map[string]*domain.Connection),
-		Register:      make(chan *domain.Connection),
-		Unregister:    make(chan *domain.Connection),
+		Register:      make(chan *domain.Connection, registerQueueSize),
+		Unregister:    make(chan *domain.Connection, registerQueueSize),
 		Broadcast:     make(chan domain.WebSocketMessage),
 		DirectMessage: make(chan domain.WebSocketMessage),
+		Done:          make(chan struct{}),
 	}
 
+	ctx, stop := context.WithCancel(context.Background())
 	service := &websocketService{
-		hub:      hub,
-		roomRepo: roomRepo,
-	}
-
-	go service.runHub()
+		hub:                hub,
+		roomRepo:           roomRepo,
+		paginator:          pagination.NewPaginator(cfg),
+		maxHistoryMessages: cfg.GetInt("chat.max_history_messages"),
+		idempotencyKeys:    idempotencyKeys,
+		idempotencyTTL:     cfg.GetDuration("chat.idempotency_ttl"),
+		offlineNotifier:    offlineNotifier,
+		offlineThreshold:   cfg.GetDuration("notifications.offline_email.threshold"),
+		lastSeen:           make(map[string]time.Time),
+		startedAt:          time.Now(),
+		maxNotifications:   cfg.GetInt("notifications.retention.max_per_user"),
+		notificationMaxAge: cfg.GetDuration("notifications.retention.max_age"),
+		ctx:                ctx,
+		stop:               stop,
+		typing:             make(map[typingKey]*typingState),
+		typingTimeout:      cfg.GetDuration("chat.typing_timeout"),
+		now:                time.Now,
+		afterFunc: func(d time.Duration, f func()) timer {
+			return time.AfterFunc(d, f)
+		},
+		registerTimeout:   cfg.GetDuration("websocket.register_timeout"),
+		maxFileSize:       int64(cfg.GetSizeInBytes("chat.max_file_size")),
+		allowedFileTypes:  cfg.GetStringSlice("chat.allowed_file_types"),
+		maxPinnedMessages: cfg.GetInt("chat.max_pinned_messages"),
+		maxMessageLength:  cfg.GetInt("chat.max_message_length"),
+	}
+	if service.maxHistoryMessages <= 0 {
+		service.maxHistoryMessages = defaultMaxHistoryMessages
+	}
+	if service.idempotencyTTL <= 0 {
+		service.idempotencyTTL = defaultIdempotencyTTL
+	}
+	if service.offlineThreshold <= 0 {
+		service.offlineThreshold = defaultOfflineNotifyThreshold
+	}
+	if service.typingTimeout <= 0 {
+		service.typingTimeout = defaultTypingTimeout
+	}
+	if service.registerTimeout <= 0 {
+		service.registerTimeout = defaultRegisterTimeout
+	}
+	if service.maxFileSize <= 0 {
+		service.maxFileSize = defaultMaxFileSize
+	}
+	if len(service.allowedFileTypes) == 0 {
+		service.allowedFileTypes = defaultAllowedFileTypes
+	}
+	if service.maxPinnedMessages <= 0 {
+		service.maxPinnedMessages = defaultMaxPinnedMessages
+	}
+	if service.maxMessageLength <= 0 {
+		service.maxMessageLength = defaultMaxMessageLength
+	}
+
+	messagesPerMinute := cfg.GetInt("chat.messages_per_minute")
+	if messagesPerMinute <= 0 {
+		messagesPerMinute = defaultMessagesPerMinute
+	}
+	// Read the clock through the service so tests replacing it affect the limiter
+	service.sendLimiter = ratelimit.NewLimiter(messagesPerMinute, time.Minute,
+		ratelimit.WithClock(func() time.Time { return service.now() }))
+
+	service.notifiers = append(service.notifiers, registeredNotifier{
+		channel:  domain.NotificationChannelWebSocket,
+		notifier: NewWebSocketNotifier(hub),
+	})
 	return service
 }
 
 func (s *websocketService) runHub() {
+	defer close(s.hub.Done)
+
 	for {
 		select {
+		case <-s.ctx.Done():
+			// Every connection was cancelled along with s.ctx; drop them so
+			// their write pumps see the closed send channel too
+			s.mu.Lock()
+			for _, conn := range s.hub.Connections {
+				s.dropConnection(conn)
+			}
+			s.mu.Unlock()
+			return
+
 		case conn := <-s.hub.Register:
 			s.mu.Lock()
 			s.hub.Connections[conn.UserID] = conn
@@ -89,28 +376,27 @@ func (s *websocketService) runHub() {
 
 		case conn := <-s.hub.Unregister:
 			s.mu.Lock()
-			delete(s.hub.Connections, conn.UserID)
-			if conn.RoomID != "" {
-				room, exists := s.hub.Rooms[conn.RoomID]
-				if exists {
-					for i, userID := range room.Users {
-						if userID == conn.UserID {
-							room.Users = append(room.Users[:i], room.Users[i+1:]...)
-							break
-						}
-					}
-				}
-			}
+			s.dropConnection(conn)
 			s.mu.Unlock()
 
 		case message := <-s.hub.DirectMessage:
+			var dead []*domain.Connection
+			var delivered []string
 			s.mu.RLock()
 			if targetConn, exists := s.hub.Connections[message.TargetID]; exists {
-				targetConn.Send <- message
+				if trySend(targetConn, message) {
+					delivered = append(delivered, message.TargetID)
+				} else {
+					dead = append(dead, targetConn)
+				}
 			}
 			s.mu.RUnlock()
+			s.reapConnections(dead)
+			s.acknowledgeDelivery(message, delivered)
 
 		case message := <-s.hub.Broadcast:
+			var dead []*domain.Connection
+			var delivered []string
 			s.mu.RLock()
 			if message.RoomID != "" {
 				// Group message
@@ -118,7 +404,11 @@ func (s *websocketService) runHub() {
 				if exists {
 					for _, userID := range room.Users {
 						if conn, exists := s.hub.Connections[userID]; exists {
-							conn.Send <- message
+							if trySend(conn, message) {
+								delivered = append(delivered, userID)
+							} else {
+								dead = append(dead, conn)
+							}
 						}
 					}
 					room.LastMessage = &domain.Message{
@@ -133,54 +423,344 @@ func (s *websocketService) runHub() {
 				}
 			} else if message.Type == domain.MessageTypeTaskUpdate {
 				for _, conn := range s.hub.Connections {
-					conn.Send <- message
+					if !trySend(conn, message) {
+						dead = append(dead, conn)
+					}
 				}
+			} else {
+				// broadcast rejects these, so reaching here means a sender bypassed it
+				log.Printf("dropping unroutable %q broadcast from user %s", message.Type, message.UserID)
 			}
 			s.mu.RUnlock()
+			s.reapConnections(dead)
+			s.acknowledgeDelivery(message, delivered)
+		}
+	}
+}
+
+// acknowledgeDelivery records that a chat message reached the given users'
+// connections and tells its sender. It runs off the hub goroutine, which must
+// not wait on the database or send to itself.
+func (s *websocketService) acknowledgeDelivery(message domain.WebSocketMessage, recipients []string) {
+	if message.ID == "" || !isChatContent(message.Type) {
+		return
+	}
+
+	var others []string
+	for _, userID := range recipients {
+		if userID != message.UserID {
+			others = append(others, userID)
+		}
+	}
+	if len(others) == 0 {
+		return
+	}
+
+	go func() {
+		for _, userID := range others {
+			if err := s.markDelivered(message, userID); err != nil && !errors.Is(err, domain.ErrHubStopped) {
+				log.Printf("error recording delivery of message %s to user %s: %v", message.ID, userID, err)
+			}
+		}
+	}()
+}
+
+// markDelivered stores a delivered status for the recipient, unless they
+// have already read the message, and sends the receipt to the sender
+func (s *websocketService) markDelivered(message domain.WebSocketMessage, recipientID string) error {
+	status, err := s.roomRepo.GetMessageStatus(message.ID, recipientID)
+	if err != nil {
+		return err
+	}
+	if status != nil && status.Status == domain.MessageStatusRead {
+		return nil
+	}
+	if err := s.saveMessageStatus(status, message.ID, recipientID, domain.MessageStatusDelivered); err != nil {
+		return err
+	}
+
+	return s.sendDirect(domain.WebSocketMessage{
+		Type:      domain.MessageTypeDelivered,
+		RoomID:    message.RoomID,
+		UserID:    recipientID,
+		TargetID:  message.UserID,
+		MessageID: message.ID,
+		Status:    domain.MessageStatusDelivered,
+		Timestamp: time.Now(),
+	})
+}
+
+// saveMessageStatus moves the user's existing status for the message, or a
+// new one when existing is nil, to the given status
+func (s *websocketService) saveMessageStatus(existing *domain.MessageStatus, messageID, userID, status string) error {
+	now := time.Now()
+	if existing == nil {
+		existing = &domain.MessageStatus{
+			ID:        generateMessageStatusID(),
+			MessageID: messageID,
+			UserID:    userID,
+			CreatedAt: now,
+		}
+	}
+	existing.Status = status
+	existing.UpdatedAt = now
+	return s.roomRepo.UpdateMessageStatus(existing)
+}
+
+// isChatContent reports whether messages of this type are ones users send
+// each other, as opposed to receipts, indicators and notifications
+func isChatContent(messageType string) bool {
+	switch messageType {
+	case domain.MessageTypeText, domain.MessageTypeFile, domain.MessageTypeImage,
+		domain.MessageTypeVideo, domain.MessageTypeAudio:
+		return true
+	default:
+		return false
+	}
+}
+
+// broadcast hands a room message, or a task update for everyone, to the hub.
+// Anything else has nowhere to go and is rejected rather than dropped.
+func (s *websocketService) broadcast(message domain.WebSocketMessage) error {
+	if message.RoomID == "" && message.Type != domain.MessageTypeTaskUpdate {
+		return fmt.Errorf("%w: %q message has no room", domain.ErrUnroutableMessage, message.Type)
+	}
+	select {
+	case s.hub.Broadcast <- message:
+		return nil
+	case <-s.hub.Done:
+		return domain.ErrHubStopped
+	}
+}
+
+// sendDirect hands a message for a single user to the hub
+func (s *websocketService) sendDirect(message domain.WebSocketMessage) error {
+	select {
+	case s.hub.DirectMessage <- message:
+		return nil
+	case <-s.hub.Done:
+		return domain.ErrHubStopped
+	}
+}
+
+// trySend hands a message to the connection without blocking the hub. It
+// reports false when the send buffer is full or the channel is already closed.
+func trySend(conn *domain.Connection, message domain.WebSocketMessage) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
 		}
+	}()
+
+	select {
+	case conn.Send <- message:
+		return true
+	default:
+		return false
 	}
 }
 
-func (s *websocketService) HandleConnection(conn *websocket.Conn, userID string) {
-	connection := &domain.Connection{
-		ID:     userID,
-		UserID: userID,
-		Send:   make(chan domain.WebSocketMessage),
-		Hub:    s.hub,
+// reapConnections removes connections whose writer stopped draining them
+func (s *websocketService) reapConnections(conns []*domain.Connection) {
+	if len(conns) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range conns {
+		log.Printf("dropping dead connection for user %s", conn.UserID)
+		s.dropConnection(conn)
 	}
+}
 
-	s.hub.Register <- connection
+// dropConnection unregisters the connection and its room subscription. The
+// caller must hold s.mu.
+func (s *websocketService) dropConnection(conn *domain.Connection) {
+	// The user may have reconnected since; leave the newer connection alone
+	if current, exists := s.hub.Connections[conn.UserID]; exists && current == conn {
+		delete(s.hub.Connections, conn.UserID)
+		s.lastSeen[conn.UserID] = time.Now()
+	}
+
+	if conn.RoomID != "" {
+		room, exists := s.hub.Rooms[conn.RoomID]
+		if exists {
+			for i, userID := range room.Users {
+				if userID == conn.UserID {
+					room.Users = append(room.Users[:i], room.Users[i+1:]...)
+					break
+				}
+			}
+		}
+		conn.RoomID = ""
+	}
+
+	conn.Close()
+}
+
+func (s *websocketService) HandleConnection(conn *websocket.Conn, userID, role string) {
+	connection := domain.NewConnection(s.ctx, userID, role, s.hub, sendBufferSize)
+	connection.MarkPong(time.Now())
+
+	// Count the pumps before registering so anyone who sees the connection
+	// in the hub can wait for them. Holding s.mu orders the count before
+	// Shutdown starts waiting, or turns the client away once it has.
+	s.mu.RLock()
+	if s.ctx.Err() != nil {
+		s.mu.RUnlock()
+		s.refuseConnection(conn, s.closeMessage())
+		return
+	}
+	s.pumps.Add(2)
+	s.mu.RUnlock()
+
+	// A hub too busy to drain its register queue in time turns the client
+	// away rather than tying up the accepting goroutine
+	timeout := time.NewTimer(s.registerTimeout)
+	defer timeout.Stop()
+
+	select {
+	case s.hub.Register <- connection:
+	case <-s.hub.Done:
+		s.pumps.Add(-2)
+		s.refuseConnection(conn, s.closeMessage())
+		return
+	case <-timeout.C:
+		log.Printf("refusing connection for user %s: hub did not register it within %s", userID, s.registerTimeout)
+		s.pumps.Add(-2)
+		connection.Cancel()
+		s.refuseConnection(conn, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "server busy"))
+		return
+	}
+
+	// Frames too big to carry a message of the longest allowed content are
+	// refused by the socket with a message too big close frame
+	conn.SetReadLimit(s.maxFrameSize())
 
 	go s.writePump(conn, connection)
 	go s.readPump(conn, connection)
 }
 
+// maxFrameSize is the largest client frame in bytes: the longest allowed
+// content with every character escaped in JSON as \uXXXX, plus room for the
+// rest of the frame
+func (s *websocketService) maxFrameSize() int64 {
+	return int64(s.maxMessageLength)*6 + maxFrameOverhead
+}
+
+// refuseConnection closes a connection that arrived during shutdown or that
+// the hub could not take on, sending the given close frame first
+func (s *websocketService) refuseConnection(conn *websocket.Conn, closeMessage []byte) {
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	conn.WriteMessage(websocket.CloseMessage, closeMessage)
+	conn.Close()
+}
+
+// CreateDirectRoom returns the direct room between two users, creating it if
+// this is their first contact. There is only ever one such room per pair.
 func (s *websocketService) CreateDirectRoom(userID1, userID2 string) (*domain.Room, error) {
-	room := &domain.Room{
-		ID:        generateRoomID(),
+	room, err := s.getOrCreateDirectRoom(userID1, userID2)
+	if err != nil {
+		return nil, err
+	}
+	return s.loadRoom(room.ID)
+}
+
+// getOrCreateDirectRoom looks up the direct room between two users by its
+// deterministic ID and creates it with both as members when it is missing.
+// creatorID is recorded as the creator of a new room.
+func (s *websocketService) getOrCreateDirectRoom(creatorID, otherID string) (*domain.Room, error) {
+	if creatorID == otherID {
+		return nil, domain.ErrSelfDirectRoom
+	}
+
+	roomID := generateDirectRoomID(creatorID, otherID)
+	room, err := s.roomRepo.GetRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room != nil {
+		return room, nil
+	}
+
+	room = &domain.Room{
+		ID:        roomID,
 		Type:      domain.RoomTypeDirect,
-		Users:     []string{userID1, userID2},
+		CreatedBy: creatorID,
+		Users:     []string{creatorID, otherID},
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
-
 	if err := s.roomRepo.CreateRoom(room); err != nil {
 		return nil, err
 	}
+	for _, userID := range room.Users {
+		if err := s.roomRepo.AddUserToRoom(room.ID, userID); err != nil {
+			return nil, err
+		}
+	}
+	return room, nil
+}
 
-	s.mu.Lock()
-	s.hub.Rooms[room.ID] = room
-	s.mu.Unlock()
+// GetDirectRoomID returns the ID the direct room between two users has, or
+// will have once either sends the other a message, and whether it exists.
+// The ID does not depend on the order the users are given in.
+func (s *websocketService) GetDirectRoomID(userID1, userID2 string) (string, bool, error) {
+	roomID := generateDirectRoomID(userID1, userID2)
+	room, err := s.roomRepo.GetRoom(roomID)
+	if err != nil {
+		return "", false, err
+	}
+	return roomID, room != nil, nil
+}
+
+// CreateGroupRoom creates a group room. A non-empty idempotency key makes the
+// call retry-safe: repeating it within chat.idempotency_ttl returns the room
+// created by the first call.
+func (s *websocketService) CreateGroupRoom(name, creatorID string, userIDs []string, idempotencyKey string) (*domain.Room, error) {
+	if idempotencyKey == "" {
+		return s.createGroupRoom(name, creatorID, userIDs)
+	}
+
+	s.idempotencyMu.Lock()
+	defer s.idempotencyMu.Unlock()
+
+	ctx := context.Background()
+	key := "chat:group_room:" + idempotencyKey
+	if roomID, err := s.idempotencyKeys.Get(ctx, key); err == nil {
+		return s.loadRoom(roomID.(string))
+	}
 
+	room, err := s.createGroupRoom(name, creatorID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.idempotencyKeys.SetWithExpire(ctx, key, room.ID, s.idempotencyTTL); err != nil {
+		log.Printf("error caching idempotency key for room %s: %v", room.ID, err)
+	}
 	return room, nil
 }
 
-func (s *websocketService) CreateGroupRoom(name string, userIDs []string) (*domain.Room, error) {
+// createGroupRoom stores the room and its members, making the creator, who
+// is added if missing, its admin
+func (s *websocketService) createGroupRoom(name, creatorID string, userIDs []string) (*domain.Room, error) {
+	var members []string
+	if creatorID != "" {
+		members = append(members, creatorID)
+	}
+	for _, userID := range userIDs {
+		if !slices.Contains(members, userID) {
+			members = append(members, userID)
+		}
+	}
+
 	room := &domain.Room{
 		ID:        generateRoomID(),
 		Name:      name,
 		Type:      domain.RoomTypeGroup,
-		Users:     userIDs,
+		CreatedBy: creatorID,
+		Users:     members,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -188,6 +768,17 @@ func (s *websocketService) CreateGroupRoom(name string, userIDs []string) (*doma
 	if err := s.roomRepo.CreateRoom(room); err != nil {
 		return nil, err
 	}
+	for _, userID := range members {
+		if err := s.roomRepo.AddUserToRoom(room.ID, userID); err != nil {
+			return nil, err
+		}
+	}
+	if creatorID != "" {
+		if err := s.roomRepo.SetRoomUserRole(room.ID, creatorID, domain.RoomRoleAdmin); err != nil {
+			return nil, err
+		}
+		room.Admins = []string{creatorID}
+	}
 
 	s.mu.Lock()
 	s.hub.Rooms[room.ID] = room
@@ -219,46 +810,337 @@ func (s *websocketService) JoinRoom(roomID, userID string) error {
 }
 
 func (s *websocketService) LeaveRoom(roomID, userID string) error {
+	room, err := s.loadRoom(roomID)
+	if err != nil {
+		return err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	room, exists := s.hub.Rooms[roomID]
-	if !exists {
-		return errors.New("room not found")
-	}
-
+	index := -1
 	for i, id := range room.Users {
 		if id == userID {
-			room.Users = append(room.Users[:i], room.Users[i+1:]...)
-			if err := s.roomRepo.RemoveUserFromRoom(roomID, userID); err != nil {
-				return err
-			}
-			return nil
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return domain.ErrUserNotInRoom
+	}
+
+	if err := s.roomRepo.RemoveUserFromRoom(roomID, userID); err != nil {
+		return err
+	}
+	room.Users = append(room.Users[:index], room.Users[index+1:]...)
+	room.Admins = slices.DeleteFunc(room.Admins, func(id string) bool { return id == userID })
+
+	// Drop the live subscription so the hub stops routing room traffic to it
+	if conn, exists := s.hub.Connections[userID]; exists && conn.RoomID == roomID {
+		conn.RoomID = ""
+	}
+
+	// A group without members can never be rejoined through the UI, so clean it up
+	if room.Type == domain.RoomTypeGroup && len(room.Users) == 0 {
+		if err := s.roomRepo.DeleteRoom(roomID); err != nil {
+			return err
 		}
+		delete(s.hub.Rooms, roomID)
 	}
 
-	return domain.ErrUserNotInRoom
+	return nil
 }
 
-func (s *websocketService) SendDirectMessage(senderID, receiverID, content string) error {
-	// Create or get direct room
-	room, err := s.roomRepo.GetRoom(generateDirectRoomID(senderID, receiverID))
+// DeleteRoom deletes the room with its members, messages and their statuses.
+// Only the room's creator or an employer may delete it. Members who are
+// online get a room_deleted event, and are unsubscribed from the room.
+func (s *websocketService) DeleteRoom(roomID, userID string, isEmployer bool) error {
+	room, err := s.loadRoom(roomID)
 	if err != nil {
 		return err
 	}
 
+	s.mu.Lock()
+	if !canManageRoom(room, userID, isEmployer) {
+		s.mu.Unlock()
+		return domain.ErrNotRoomCreator
+	}
+	if err := s.roomRepo.DeleteRoom(roomID); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	delete(s.hub.Rooms, roomID)
+	members := slices.Clone(room.Users)
+	for _, memberID := range members {
+		if conn, exists := s.hub.Connections[memberID]; exists && conn.RoomID == roomID {
+			conn.RoomID = ""
+		}
+	}
+	s.mu.Unlock()
+
+	s.clearRoomTyping(roomID)
+
+	// The room is gone from the hub, so members are told one by one
+	now := s.now()
+	for _, memberID := range members {
+		err := s.sendDirect(domain.WebSocketMessage{
+			Type:      domain.MessageTypeRoomDeleted,
+			RoomID:    roomID,
+			UserID:    userID,
+			TargetID:  memberID,
+			Timestamp: now,
+		})
+		if errors.Is(err, domain.ErrHubStopped) {
+			break
+		}
+	}
+	return nil
+}
+
+// canManageRoom reports whether the user created the room or is an employer.
+// Rooms created before creators were recorded are left to employers.
+func canManageRoom(room *domain.Room, userID string, isEmployer bool) bool {
+	return isEmployer || (room.CreatedBy != "" && room.CreatedBy == userID)
+}
+
+// GetRoomDetails returns the room with its members, admins, latest message,
+// pinned messages and the user's unread count in it. Only members may see it.
+func (s *websocketService) GetRoomDetails(roomID, userID string) (*domain.Room, error) {
+	cached, err := s.loadRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	isMember := slices.Contains(cached.Users, userID)
+	users, admins := slices.Clone(cached.Users), slices.Clone(cached.Admins)
+	s.mu.RUnlock()
+	if !isMember {
+		return nil, domain.ErrUserNotInRoom
+	}
+
+	// Pins are written straight to the repository, so the hub's copy of the
+	// room may not have them
+	room, err := s.roomRepo.GetRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
 	if room == nil {
-		room = &domain.Room{
-			ID:        generateDirectRoomID(senderID, receiverID),
-			Type:      domain.RoomTypeDirect,
-			Users:     []string{senderID, receiverID},
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		}
-		if err := s.roomRepo.CreateRoom(room); err != nil {
-			return err
+		return nil, domain.ErrRoomNotFound
+	}
+	room.Users = users
+	room.Admins = admins
+
+	latest, err := s.roomRepo.GetRoomMessages(roomID, 1, 0, domain.HistoryOrderNewestFirst)
+	if err != nil {
+		return nil, err
+	}
+	if len(latest) > 0 {
+		room.LastMessage = latest[0]
+	}
+
+	counts, err := s.roomRepo.GetUnreadCounts(userID)
+	if err != nil {
+		return nil, err
+	}
+	room.UnreadCount = map[string]int{userID: counts[roomID]}
+	return room, nil
+}
+
+// ListRoomMembers returns the IDs of the room's members. Only members of the
+// room may list them.
+func (s *websocketService) ListRoomMembers(roomID, userID string) ([]string, error) {
+	room, err := s.loadRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !slices.Contains(room.Users, userID) {
+		return nil, domain.ErrUserNotInRoom
+	}
+	return slices.Clone(room.Users), nil
+}
+
+// AddMember adds userID to a group room. Only the room's admins or an
+// employer may add members. Everyone in the room, the new member included, gets
+// a member_joined event.
+func (s *websocketService) AddMember(roomID, adderID, userID string, isEmployer bool) error {
+	room, err := s.loadRoom(roomID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if err := authorizeMemberChange(room, adderID, isEmployer); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	if slices.Contains(room.Users, userID) {
+		s.mu.Unlock()
+		return domain.ErrUserAlreadyInRoom
+	}
+	if err := s.roomRepo.AddUserToRoom(roomID, userID); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	room.Users = append(room.Users, userID)
+	s.mu.Unlock()
+
+	s.announceMembership(domain.MessageTypeMemberJoined, roomID, userID)
+	return nil
+}
+
+// RemoveMember removes userID from a group room. Only the room's admins or
+// an employer may remove members. The remaining members and the removed one
+// get a member_left event.
+func (s *websocketService) RemoveMember(roomID, removerID, userID string, isEmployer bool) error {
+	room, err := s.loadRoom(roomID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if err := authorizeMemberChange(room, removerID, isEmployer); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	index := slices.Index(room.Users, userID)
+	if index == -1 {
+		s.mu.Unlock()
+		return domain.ErrUserNotInRoom
+	}
+	if err := s.roomRepo.RemoveUserFromRoom(roomID, userID); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	room.Users = slices.Delete(room.Users, index, index+1)
+	room.Admins = slices.DeleteFunc(room.Admins, func(id string) bool { return id == userID })
+	if conn, exists := s.hub.Connections[userID]; exists && conn.RoomID == roomID {
+		conn.RoomID = ""
+	}
+	s.mu.Unlock()
+
+	s.announceMembership(domain.MessageTypeMemberLeft, roomID, userID)
+	// The removed member is no longer in the room, so they are told directly
+	err = s.sendDirect(domain.WebSocketMessage{
+		Type:      domain.MessageTypeMemberLeft,
+		RoomID:    roomID,
+		UserID:    userID,
+		TargetID:  userID,
+		Timestamp: s.now(),
+	})
+	if err != nil && !errors.Is(err, domain.ErrHubStopped) {
+		log.Printf("error sending member_left to user %s in room %s: %v", userID, roomID, err)
+	}
+	return nil
+}
+
+// PromoteToAdmin makes a member of a group room one of its admins. Only the
+// room's admins or an employer may promote members.
+func (s *websocketService) PromoteToAdmin(roomID, promoterID, userID string, isEmployer bool) error {
+	room, err := s.loadRoom(roomID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := authorizeMemberChange(room, promoterID, isEmployer); err != nil {
+		return err
+	}
+	if !slices.Contains(room.Users, userID) {
+		return domain.ErrUserNotInRoom
+	}
+	if room.IsAdmin(userID) {
+		return nil
+	}
+	if err := s.roomRepo.SetRoomUserRole(roomID, userID, domain.RoomRoleAdmin); err != nil {
+		return err
+	}
+	room.Admins = append(room.Admins, userID)
+	return nil
+}
+
+// DemoteMember makes an admin of a group room a plain member again. Only the
+// room's admins or an employer may demote admins, and the last admin stays.
+func (s *websocketService) DemoteMember(roomID, demoterID, userID string, isEmployer bool) error {
+	room, err := s.loadRoom(roomID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := authorizeMemberChange(room, demoterID, isEmployer); err != nil {
+		return err
+	}
+	if !slices.Contains(room.Users, userID) {
+		return domain.ErrUserNotInRoom
+	}
+	if !room.IsAdmin(userID) {
+		return nil
+	}
+	if len(room.Admins) == 1 {
+		return domain.ErrLastRoomAdmin
+	}
+	if err := s.roomRepo.SetRoomUserRole(roomID, userID, domain.RoomRoleMember); err != nil {
+		return err
+	}
+	room.Admins = slices.DeleteFunc(room.Admins, func(id string) bool { return id == userID })
+	return nil
+}
+
+// authorizeMemberChange checks that the room is a group whose members the user
+// may manage, as one of its admins or an employer. The caller holds s.mu.
+func authorizeMemberChange(room *domain.Room, userID string, isEmployer bool) error {
+	if room.Type != domain.RoomTypeGroup {
+		return domain.ErrInvalidRoomType
+	}
+	if !isEmployer && !room.IsAdmin(userID) {
+		return domain.ErrNotRoomAdmin
+	}
+	return nil
+}
+
+// announceMembership broadcasts a member_joined or member_left event for
+// memberID to the room. The change is already stored, so a failed broadcast
+// is only logged.
+func (s *websocketService) announceMembership(messageType, roomID, memberID string) {
+	err := s.broadcast(domain.WebSocketMessage{
+		Type:      messageType,
+		RoomID:    roomID,
+		UserID:    memberID,
+		Timestamp: s.now(),
+	})
+	if err != nil && !errors.Is(err, domain.ErrHubStopped) {
+		log.Printf("error broadcasting %s for user %s in room %s: %v", messageType, memberID, roomID, err)
+	}
+}
+
+// clearRoomTyping cancels the pending stop_typing timers of a room
+func (s *websocketService) clearRoomTyping(roomID string) {
+	s.typingMu.Lock()
+	defer s.typingMu.Unlock()
+	for key, state := range s.typing {
+		if key.roomID == roomID {
+			state.timer.Stop()
+			delete(s.typing, key)
 		}
 	}
+}
+
+func (s *websocketService) SendDirectMessage(senderID, receiverID, content string) (*domain.Message, error) {
+	if err := s.checkMessageLength(content); err != nil {
+		return nil, err
+	}
+	if err := s.checkRateLimit(senderID); err != nil {
+		return nil, err
+	}
+	room, err := s.getOrCreateDirectRoom(senderID, receiverID)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create message
 	message := &domain.Message{
@@ -273,19 +1155,20 @@ func (s *websocketService) SendDirectMessage(senderID, receiverID, content strin
 	}
 
 	if err := s.roomRepo.CreateMessage(message); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Update room's last message
 	room.LastMessage = message
 	if err := s.roomRepo.UpdateRoom(room); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Send message to receiver
 	wsMessage := domain.WebSocketMessage{
 		Type:      domain.MessageTypeText,
 		ID:        message.ID,
+		Sequence:  message.Sequence,
 		RoomID:    room.ID,
 		UserID:    senderID,
 		TargetID:  receiverID,
@@ -293,18 +1176,27 @@ func (s *websocketService) SendDirectMessage(senderID, receiverID, content strin
 		Timestamp: time.Now(),
 	}
 
-	s.hub.DirectMessage <- wsMessage
-	return nil
+	if err := s.sendDirect(wsMessage); err != nil {
+		return nil, err
+	}
+	s.pushUnreadCounts(room.ID, receiverID)
+	return message, nil
 }
 
-func (s *websocketService) SendGroupMessage(roomID, userID, content string) error {
-	room, err := s.roomRepo.GetRoom(roomID)
-	if err != nil {
-		return err
+// SendGroupMessage posts a text message to a room the sender belongs to
+func (s *websocketService) SendGroupMessage(roomID, userID, content string) (*domain.Message, error) {
+	if err := s.requireMembers(roomID, userID); err != nil {
+		return nil, err
 	}
-
-	if room == nil {
-		return domain.ErrRoomNotFound
+	if err := s.checkMessageLength(content); err != nil {
+		return nil, err
+	}
+	if err := s.checkRateLimit(userID); err != nil {
+		return nil, err
+	}
+	room, err := s.admitMessage(roomID, userID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create message
@@ -320,30 +1212,106 @@ func (s *websocketService) SendGroupMessage(roomID, userID, content string) erro
 	}
 
 	if err := s.roomRepo.CreateMessage(message); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Update room's last message
 	room.LastMessage = message
 	if err := s.roomRepo.UpdateRoom(room); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Send message to all room users
 	wsMessage := domain.WebSocketMessage{
 		Type:      domain.MessageTypeText,
 		ID:        message.ID,
+		Sequence:  message.Sequence,
 		RoomID:    roomID,
 		UserID:    userID,
 		Content:   content,
 		Timestamp: time.Now(),
 	}
-
-	s.hub.Broadcast <- wsMessage
+
+	if err := s.broadcast(wsMessage); err != nil {
+		return nil, err
+	}
+	s.notifyNewMessage(message)
+	return message, nil
+}
+
+// checkMessageLength rejects content over chat.max_message_length characters
+func (s *websocketService) checkMessageLength(content string) error {
+	if length := utf8.RuneCountInString(content); length > s.maxMessageLength {
+		return fmt.Errorf("%w: %d characters, at most %d allowed", domain.ErrMessageTooLong, length, s.maxMessageLength)
+	}
+	return nil
+}
+
+// checkRateLimit takes one of the user's chat.messages_per_minute sends,
+// rejecting the message with a *domain.RateLimitError when none are left
+func (s *websocketService) checkRateLimit(userID string) error {
+	if allowed, wait := s.sendLimiter.Allow(userID); !allowed {
+		return &domain.RateLimitError{Remaining: wait}
+	}
+	return nil
+}
+
+// admitMessage loads the room a message is about to be posted in, checking
+// its slow mode first. Every kind of room message goes through it, so
+// attachments can't be used to skip the cooldown.
+func (s *websocketService) admitMessage(roomID, userID string) (*domain.Room, error) {
+	room, err := s.roomRepo.GetRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, domain.ErrRoomNotFound
+	}
+	if err := s.checkSlowMode(room, userID); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// checkSlowMode rejects the message with a *domain.SlowModeError when the
+// user posted in the room less than its slow mode interval ago
+func (s *websocketService) checkSlowMode(room *domain.Room, userID string) error {
+	interval := room.SlowModeInterval()
+	if interval <= 0 {
+		return nil
+	}
+
+	last, err := s.roomRepo.GetLatestUserMessage(room.ID, userID)
+	if err != nil {
+		return err
+	}
+	if last == nil {
+		return nil
+	}
+	if remaining := interval - time.Since(last.CreatedAt); remaining > 0 {
+		return &domain.SlowModeError{Remaining: remaining}
+	}
 	return nil
 }
 
-func (s *websocketService) SendFileMessage(roomID, userID, fileURL, fileName string, fileSize int64, fileType string) error {
+func (s *websocketService) SendFileMessage(roomID, userID, fileURL, fileName string, fileSize int64, fileType string) (*domain.Message, error) {
+	if roomID == "" {
+		return nil, domain.ErrUnroutableMessage
+	}
+	if err := s.requireMembers(roomID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.checkRateLimit(userID); err != nil {
+		return nil, err
+	}
+	fileType, err := s.validateAttachment(domain.MessageTypeFile, fileURL, fileSize, fileType)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.admitMessage(roomID, userID); err != nil {
+		return nil, err
+	}
+
 	message := &domain.Message{
 		ID:        generateMessageID(),
 		RoomID:    roomID,
@@ -359,12 +1327,13 @@ func (s *websocketService) SendFileMessage(roomID, userID, fileURL, fileName str
 	}
 
 	if err := s.roomRepo.CreateMessage(message); err != nil {
-		return err
+		return nil, err
 	}
 
 	wsMessage := domain.WebSocketMessage{
 		Type:      domain.MessageTypeFile,
 		ID:        message.ID,
+		Sequence:  message.Sequence,
 		RoomID:    roomID,
 		UserID:    userID,
 		FileURL:   fileURL,
@@ -374,17 +1343,39 @@ func (s *websocketService) SendFileMessage(roomID, userID, fileURL, fileName str
 		Timestamp: time.Now(),
 	}
 
-	s.hub.Broadcast <- wsMessage
-	return nil
+	if err := s.broadcast(wsMessage); err != nil {
+		return nil, err
+	}
+	s.notifyNewMessage(message)
+	return message, nil
 }
 
-func (s *websocketService) SendImageMessage(roomID, userID, imageURL, thumbnailURL string) error {
+func (s *websocketService) SendImageMessage(roomID, userID, imageURL, thumbnailURL string, fileSize int64, fileType string) (*domain.Message, error) {
+	if roomID == "" {
+		return nil, domain.ErrUnroutableMessage
+	}
+	if err := s.requireMembers(roomID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.checkRateLimit(userID); err != nil {
+		return nil, err
+	}
+	fileType, err := s.validateAttachment(domain.MessageTypeImage, imageURL, fileSize, fileType)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.admitMessage(roomID, userID); err != nil {
+		return nil, err
+	}
+
 	message := &domain.Message{
 		ID:           generateMessageID(),
 		RoomID:       roomID,
 		UserID:       userID,
 		Type:         domain.MessageTypeImage,
 		FileURL:      imageURL,
+		FileSize:     fileSize,
+		FileType:     fileType,
 		ThumbnailURL: thumbnailURL,
 		Status:       domain.MessageStatusSent,
 		CreatedAt:    time.Now(),
@@ -392,30 +1383,55 @@ func (s *websocketService) SendImageMessage(roomID, userID, imageURL, thumbnailU
 	}
 
 	if err := s.roomRepo.CreateMessage(message); err != nil {
-		return err
+		return nil, err
 	}
 
 	wsMessage := domain.WebSocketMessage{
 		Type:         domain.MessageTypeImage,
 		ID:           message.ID,
+		Sequence:     message.Sequence,
 		RoomID:       roomID,
 		UserID:       userID,
 		FileURL:      imageURL,
+		FileSize:     fileSize,
+		FileType:     fileType,
 		ThumbnailURL: thumbnailURL,
 		Timestamp:    time.Now(),
 	}
 
-	s.hub.Broadcast <- wsMessage
-	return nil
+	if err := s.broadcast(wsMessage); err != nil {
+		return nil, err
+	}
+	s.notifyNewMessage(message)
+	return message, nil
 }
 
-func (s *websocketService) SendVideoMessage(roomID, userID, videoURL, thumbnailURL string, duration int) error {
+func (s *websocketService) SendVideoMessage(roomID, userID, videoURL, thumbnailURL string, fileSize int64, fileType string, duration int) (*domain.Message, error) {
+	if roomID == "" {
+		return nil, domain.ErrUnroutableMessage
+	}
+	if err := s.requireMembers(roomID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.checkRateLimit(userID); err != nil {
+		return nil, err
+	}
+	fileType, err := s.validateAttachment(domain.MessageTypeVideo, videoURL, fileSize, fileType)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.admitMessage(roomID, userID); err != nil {
+		return nil, err
+	}
+
 	message := &domain.Message{
 		ID:           generateMessageID(),
 		RoomID:       roomID,
 		UserID:       userID,
 		Type:         domain.MessageTypeVideo,
 		FileURL:      videoURL,
+		FileSize:     fileSize,
+		FileType:     fileType,
 		ThumbnailURL: thumbnailURL,
 		Duration:     duration,
 		Status:       domain.MessageStatusSent,
@@ -424,31 +1440,56 @@ func (s *websocketService) SendVideoMessage(roomID, userID, videoURL, thumbnailU
 	}
 
 	if err := s.roomRepo.CreateMessage(message); err != nil {
-		return err
+		return nil, err
 	}
 
 	wsMessage := domain.WebSocketMessage{
 		Type:         domain.MessageTypeVideo,
 		ID:           message.ID,
+		Sequence:     message.Sequence,
 		RoomID:       roomID,
 		UserID:       userID,
 		FileURL:      videoURL,
+		FileSize:     fileSize,
+		FileType:     fileType,
 		ThumbnailURL: thumbnailURL,
 		Duration:     duration,
 		Timestamp:    time.Now(),
 	}
 
-	s.hub.Broadcast <- wsMessage
-	return nil
+	if err := s.broadcast(wsMessage); err != nil {
+		return nil, err
+	}
+	s.notifyNewMessage(message)
+	return message, nil
 }
 
-func (s *websocketService) SendAudioMessage(roomID, userID, audioURL string, duration int) error {
+func (s *websocketService) SendAudioMessage(roomID, userID, audioURL string, fileSize int64, fileType string, duration int) (*domain.Message, error) {
+	if roomID == "" {
+		return nil, domain.ErrUnroutableMessage
+	}
+	if err := s.requireMembers(roomID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.checkRateLimit(userID); err != nil {
+		return nil, err
+	}
+	fileType, err := s.validateAttachment(domain.MessageTypeAudio, audioURL, fileSize, fileType)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.admitMessage(roomID, userID); err != nil {
+		return nil, err
+	}
+
 	message := &domain.Message{
 		ID:        generateMessageID(),
 		RoomID:    roomID,
 		UserID:    userID,
 		Type:      domain.MessageTypeAudio,
 		FileURL:   audioURL,
+		FileSize:  fileSize,
+		FileType:  fileType,
 		Duration:  duration,
 		Status:    domain.MessageStatusSent,
 		CreatedAt: time.Now(),
@@ -456,47 +1497,132 @@ func (s *websocketService) SendAudioMessage(roomID, userID, audioURL string, dur
 	}
 
 	if err := s.roomRepo.CreateMessage(message); err != nil {
-		return err
+		return nil, err
 	}
 
 	wsMessage := domain.WebSocketMessage{
 		Type:      domain.MessageTypeAudio,
 		ID:        message.ID,
+		Sequence:  message.Sequence,
 		RoomID:    roomID,
 		UserID:    userID,
 		FileURL:   audioURL,
+		FileSize:  fileSize,
+		FileType:  fileType,
 		Duration:  duration,
 		Timestamp: time.Now(),
 	}
 
-	s.hub.Broadcast <- wsMessage
-	return nil
+	if err := s.broadcast(wsMessage); err != nil {
+		return nil, err
+	}
+	s.notifyNewMessage(message)
+	return message, nil
+}
+
+// validateAttachment checks an attachment before a message of the given kind
+// is stored and returns its media type without parameters. Image, video and
+// audio messages only take their own top-level type.
+func (s *websocketService) validateAttachment(kind, fileURL string, fileSize int64, fileType string) (string, error) {
+	if strings.TrimSpace(fileURL) == "" {
+		return "", fmt.Errorf("%w: %s message has no file URL", domain.ErrInvalidMessage, kind)
+	}
+	if fileSize <= 0 {
+		return "", fmt.Errorf("%w: %s message has no file size", domain.ErrInvalidMessage, kind)
+	}
+	if fileSize > s.maxFileSize {
+		return "", fmt.Errorf("%w: file is %d bytes, over the %d byte limit", domain.ErrInvalidMessage, fileSize, s.maxFileSize)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(fileType)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid file type %q", domain.ErrInvalidMessage, fileType)
+	}
+	allowed := s.allowedFileTypes
+	if pattern, ok := mediaFileTypes[kind]; ok {
+		allowed = []string{pattern}
+	}
+	if !slices.ContainsFunc(allowed, func(pattern string) bool { return matchesMediaType(mediaType, pattern) }) {
+		return "", fmt.Errorf("%w: %s files are not allowed in %s messages", domain.ErrInvalidMessage, mediaType, kind)
+	}
+	return mediaType, nil
+}
+
+// matchesMediaType reports whether mediaType fits pattern, where a pattern
+// like image/* matches every image type
+func matchesMediaType(mediaType, pattern string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(mediaType, prefix+"/")
+	}
+	return mediaType == pattern
 }
 
+// SendTypingIndicator tells the room the user is typing. Events closer than
+// typingDebounce to the last one broadcast are dropped, and a stop_typing
+// event is broadcast once the user has sent none for the typing timeout.
 func (s *websocketService) SendTypingIndicator(roomID, userID string) error {
-	message := domain.WebSocketMessage{
+	if roomID == "" {
+		return fmt.Errorf("%w: %q message has no room", domain.ErrUnroutableMessage, domain.MessageTypeTyping)
+	}
+
+	key := typingKey{roomID: roomID, userID: userID}
+	now := s.now()
+
+	s.typingMu.Lock()
+	state := &typingState{lastSent: now}
+	debounced := false
+	if previous, exists := s.typing[key]; exists {
+		previous.timer.Stop()
+		if now.Sub(previous.lastSent) < typingDebounce {
+			state.lastSent = previous.lastSent
+			debounced = true
+		}
+	}
+	state.timer = s.afterFunc(s.typingTimeout, func() { s.stopTyping(key, state) })
+	s.typing[key] = state
+	s.typingMu.Unlock()
+
+	if debounced {
+		return nil
+	}
+	return s.broadcast(domain.WebSocketMessage{
 		Type:      domain.MessageTypeTyping,
 		RoomID:    roomID,
 		UserID:    userID,
-		Timestamp: time.Now(),
-	}
+		Timestamp: now,
+	})
+}
 
-	s.hub.Broadcast <- message
-	return nil
+// stopTyping broadcasts stop_typing unless the user typed again since state
+// was stored
+func (s *websocketService) stopTyping(key typingKey, state *typingState) {
+	s.typingMu.Lock()
+	if s.typing[key] != state {
+		s.typingMu.Unlock()
+		return
+	}
+	delete(s.typing, key)
+	s.typingMu.Unlock()
+
+	err := s.broadcast(domain.WebSocketMessage{
+		Type:      domain.MessageTypeStopTyping,
+		RoomID:    key.roomID,
+		UserID:    key.userID,
+		Timestamp: s.now(),
+	})
+	if err != nil && !errors.Is(err, domain.ErrHubStopped) {
+		log.Printf("error broadcasting stop_typing for user %s in room %s: %v", key.userID, key.roomID, err)
+	}
 }
 
 func (s *websocketService) MarkMessageAsRead(roomID, userID, messageID string) error {
-	// Update message status in database
-	status := &domain.MessageStatus{
-		ID:        generateMessageStatusID(),
-		MessageID: messageID,
-		UserID:    userID,
-		Status:    domain.MessageStatusRead,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	// Update message status in database, replacing any delivered status
+	status, err := s.roomRepo.GetMessageStatus(messageID, userID)
+	if err != nil {
+		return err
 	}
-
-	if err := s.roomRepo.UpdateMessageStatus(status); err != nil {
+	if err := s.saveMessageStatus(status, messageID, userID, domain.MessageStatusRead); err != nil {
 		return err
 	}
 
@@ -529,11 +1655,29 @@ func (s *websocketService) MarkMessageAsRead(roomID, userID, messageID string) e
 		Timestamp: time.Now(),
 	}
 
-	s.hub.Broadcast <- message
+	if err := s.broadcast(message); err != nil {
+		return err
+	}
+	s.pushUnreadCounts(roomID, userID)
 	return nil
 }
 
-func (s *websocketService) PinMessage(roomID, messageID string) error {
+// PinMessage pins one of the room's messages for everyone in it. Only members
+// may pin, and a room holds at most maxPinnedMessages pins.
+func (s *websocketService) PinMessage(roomID, userID, messageID string) error {
+	if err := s.requireMembers(roomID, userID); err != nil {
+		return err
+	}
+	message, err := s.roomRepo.GetMessage(messageID)
+	if err != nil {
+		return err
+	}
+	if message == nil || message.RoomID != roomID {
+		return domain.ErrMessageNotFound
+	}
+
+	s.pinMu.Lock()
+	defer s.pinMu.Unlock()
 	room, err := s.roomRepo.GetRoom(roomID)
 	if err != nil {
 		return err
@@ -549,12 +1693,56 @@ func (s *websocketService) PinMessage(roomID, messageID string) error {
 			return nil // Message is already pinned
 		}
 	}
+	if len(room.PinnedMessages) >= s.maxPinnedMessages {
+		return fmt.Errorf("%w: a room can have at most %d", domain.ErrPinLimitReached, s.maxPinnedMessages)
+	}
 
 	room.PinnedMessages = append(room.PinnedMessages, messageID)
 	return s.roomRepo.UpdateRoom(room)
 }
 
-func (s *websocketService) UnpinMessage(roomID, messageID string) error {
+// GetPinnedMessages returns the room's pinned messages in the order they were
+// pinned. Pins of messages since deleted, or from other rooms, are left out.
+// Only members may see them.
+func (s *websocketService) GetPinnedMessages(roomID, userID string) ([]domain.Message, error) {
+	if err := s.requireMembers(roomID, userID); err != nil {
+		return nil, err
+	}
+
+	room, err := s.roomRepo.GetRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, domain.ErrRoomNotFound
+	}
+
+	found, err := s.roomRepo.GetMessagesByIDs(room.PinnedMessages)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*domain.Message, len(found))
+	for _, message := range found {
+		byID[message.ID] = message
+	}
+
+	messages := make([]domain.Message, 0, len(found))
+	for _, id := range room.PinnedMessages {
+		if message, exists := byID[id]; exists && message.RoomID == roomID {
+			messages = append(messages, *message)
+		}
+	}
+	return messages, nil
+}
+
+// UnpinMessage unpins a message. Only members may unpin.
+func (s *websocketService) UnpinMessage(roomID, userID, messageID string) error {
+	if err := s.requireMembers(roomID, userID); err != nil {
+		return err
+	}
+
+	s.pinMu.Lock()
+	defer s.pinMu.Unlock()
 	room, err := s.roomRepo.GetRoom(roomID)
 	if err != nil {
 		return err
@@ -643,6 +1831,50 @@ func (s *websocketService) UnmuteRoom(roomID, userID string) error {
 	return nil
 }
 
+// MuteRoomMember stops memberID's messages in the room from pushing
+// notifications to userID. The messages are still stored and delivered live.
+func (s *websocketService) MuteRoomMember(roomID, userID, memberID string) error {
+	if userID == memberID {
+		return domain.ErrCannotMuteSelf
+	}
+	if err := s.requireMembers(roomID, userID, memberID); err != nil {
+		return err
+	}
+
+	return s.roomRepo.MuteRoomUser(&domain.RoomUserMute{
+		ID:          uuid.NewString(),
+		RoomID:      roomID,
+		UserID:      userID,
+		MutedUserID: memberID,
+		CreatedAt:   time.Now(),
+	})
+}
+
+// UnmuteRoomMember lets memberID's messages in the room notify userID again
+func (s *websocketService) UnmuteRoomMember(roomID, userID, memberID string) error {
+	if err := s.requireMembers(roomID, userID); err != nil {
+		return err
+	}
+	return s.roomRepo.UnmuteRoomUser(roomID, userID, memberID)
+}
+
+// requireMembers checks the room exists and every user belongs to it
+func (s *websocketService) requireMembers(roomID string, userIDs ...string) error {
+	room, err := s.loadRoom(roomID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, userID := range userIDs {
+		if !slices.Contains(room.Users, userID) {
+			return domain.ErrUserNotInRoom
+		}
+	}
+	return nil
+}
+
 func (s *websocketService) GetUnreadCount(roomID, userID string) (int, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -659,13 +1891,64 @@ func (s *websocketService) GetUnreadCount(roomID, userID string) (int, error) {
 	return room.UnreadCount[userID], nil
 }
 
-func (s *websocketService) UpdateRoomInfo(roomID, name, description, avatarURL string) error {
+// GetUnreadCounts returns the number of unread messages in each of the user's
+// rooms, keyed by room ID
+func (s *websocketService) GetUnreadCounts(userID string) (map[string]int, error) {
+	return s.roomRepo.GetUnreadCounts(userID)
+}
+
+// GetChatStats aggregates room and message activity within [from, to) along
+// with the number of live connections
+func (s *websocketService) GetChatStats(from, to time.Time, topRooms int) (*domain.ChatStats, error) {
+	if !from.Before(to) || to.Sub(from) > maxStatsRange {
+		return nil, domain.ErrInvalidStatsRange
+	}
+	if topRooms <= 0 {
+		topRooms = defaultStatsTopRooms
+	}
+	topRooms = min(topRooms, maxStatsTopRooms)
+
+	stats, err := s.roomRepo.GetChatStats(from, to, topRooms)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	stats.ActiveConnections = len(s.hub.Connections)
+	s.mu.RUnlock()
+	return stats, nil
+}
+
+// SetRoomSlowMode sets the minimum gap between a user's messages in the room,
+// in whole seconds. Zero turns slow mode off.
+func (s *websocketService) SetRoomSlowMode(roomID string, interval time.Duration) error {
+	if interval < 0 || interval > maxSlowModeInterval || interval%time.Second != 0 {
+		return domain.ErrInvalidSlowMode
+	}
+
+	room, err := s.loadRoom(roomID)
+	if err != nil {
+		return err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	room.SlowModeSeconds = int(interval / time.Second)
+	return s.roomRepo.UpdateRoom(room)
+}
 
-	room, exists := s.hub.Rooms[roomID]
-	if !exists {
-		return domain.ErrRoomNotFound
+// UpdateRoomInfo changes the room's name, description and avatar, leaving
+// empty ones as they are. Only admins or an employer may update a group room.
+func (s *websocketService) UpdateRoomInfo(roomID, userID, name, description, avatarURL string, isEmployer bool) error {
+	room, err := s.loadRoom(roomID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if room.Type == domain.RoomTypeGroup && !isEmployer && !room.IsAdmin(userID) {
+		return domain.ErrNotRoomAdmin
 	}
 
 	if name != "" {
@@ -674,45 +1957,225 @@ func (s *websocketService) UpdateRoomInfo(roomID, name, description, avatarURL s
 	if description != "" {
 		room.Description = description
 	}
-	if avatarURL != "" {
-		room.AvatarURL = avatarURL
+	if avatarURL != "" {
+		room.AvatarURL = avatarURL
+	}
+
+	if err := s.roomRepo.UpdateRoom(room); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListRooms returns a page of the user's rooms matching the filter, most
+// recently active first. The page size follows the shared pagination settings.
+func (s *websocketService) ListRooms(userID string, filter repositories.RoomFilter) ([]*domain.Room, error) {
+	switch filter.Type {
+	case "", domain.RoomTypeDirect, domain.RoomTypeGroup:
+	default:
+		return nil, fmt.Errorf("%w: %q", domain.ErrInvalidRoomType, filter.Type)
+	}
+	filter.Search = strings.TrimSpace(filter.Search)
+	filter.Limit, filter.Offset = s.paginator.Limit(filter.Limit), s.paginator.Offset(filter.Offset)
+
+	rooms, err := s.roomRepo.ListUserRooms(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return rooms, nil
+}
+
+// SearchAllMessages finds messages containing query, ignoring case, across
+// every room the user belongs to, newest first. The page size follows the
+// shared pagination settings.
+func (s *websocketService) SearchAllMessages(userID, query string, limit, offset int) ([]*domain.MessageSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, domain.ErrEmptySearchQuery
+	}
+	limit, offset = s.paginator.Limit(limit), s.paginator.Offset(offset)
+
+	return s.roomRepo.SearchMessages(userID, query, limit, offset)
+}
+
+// GetRoomHistory pages through a room's messages, newest first unless order
+// asks for chronological order
+func (s *websocketService) GetRoomHistory(roomID, userID string, limit, offset int, order domain.HistoryOrder) ([]domain.WebSocketMessage, error) {
+	if err := s.requireMembers(roomID, userID); err != nil {
+		return nil, err
+	}
+	room, err := s.loadRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err = s.historyLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := s.roomRepo.GetRoomMessages(roomID, limit, offset, order)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withReadReceipts(room.Type, toWebSocketMessages(messages))
+}
+
+// GetRoomHistoryBefore returns up to limit messages older than the anchor
+// message, newest first, and whether older messages remain. An empty anchor
+// starts from the latest message. Only members may page through a room.
+func (s *websocketService) GetRoomHistoryBefore(roomID, userID, beforeMessageID string, limit int) ([]domain.WebSocketMessage, bool, error) {
+	if err := s.requireMembers(roomID, userID); err != nil {
+		return nil, false, err
+	}
+	room, err := s.loadRoom(roomID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// The cursor is the anchor's (created_at, id), so messages sharing its
+	// timestamp are neither skipped nor repeated
+	before, beforeID := time.Now(), ""
+	if beforeMessageID != "" {
+		anchor, err := s.roomRepo.GetMessage(beforeMessageID)
+		if err != nil {
+			return nil, false, err
+		}
+		if anchor == nil || anchor.RoomID != roomID {
+			return nil, false, domain.ErrMessageNotFound
+		}
+		before, beforeID = anchor.CreatedAt, anchor.ID
+	}
+
+	limit, err = s.historyLimit(limit)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Fetch one extra row to learn whether another page exists
+	messages, err := s.roomRepo.GetRoomMessagesBefore(roomID, before, beforeID, limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(messages) > limit
+	if hasMore {
+		messages = messages[:limit]
+	}
+
+	history, err := s.withReadReceipts(room.Type, toWebSocketMessages(messages))
+	if err != nil {
+		return nil, false, err
+	}
+	return history, hasMore, nil
+}
+
+// GetReadReceipts returns who has read a message of the room, earliest
+// first. Only members may see them.
+func (s *websocketService) GetReadReceipts(roomID, userID, messageID string) ([]domain.ReadReceipt, error) {
+	if err := s.requireMembers(roomID, userID); err != nil {
+		return nil, err
+	}
+	message, err := s.roomRepo.GetMessage(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message == nil || message.RoomID != roomID {
+		return nil, domain.ErrMessageNotFound
+	}
+
+	receipts, err := s.readReceipts([]string{messageID})
+	if err != nil {
+		return nil, err
 	}
+	return receipts[messageID], nil
+}
 
-	if err := s.roomRepo.UpdateRoom(room); err != nil {
-		return err
+// readReceipts loads who has read each of the messages in one query, keyed by
+// message ID. Messages nobody has read get an empty list.
+func (s *websocketService) readReceipts(messageIDs []string) (map[string][]domain.ReadReceipt, error) {
+	statuses, err := s.roomRepo.GetMessageReadReceipts(messageIDs)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	receipts := make(map[string][]domain.ReadReceipt, len(messageIDs))
+	for _, messageID := range messageIDs {
+		receipts[messageID] = []domain.ReadReceipt{}
+	}
+	for _, status := range statuses {
+		receipts[status.MessageID] = append(receipts[status.MessageID], domain.ReadReceipt{UserID: status.UserID, ReadAt: status.UpdatedAt})
+	}
+	return receipts, nil
 }
 
-func (s *websocketService) ListRooms(userID string) ([]*domain.Room, error) {
-	rooms, err := s.roomRepo.ListUserRooms(userID)
+// withReadReceipts fills in who has read each message of a group room. In a
+// direct room the message status already tells the sender.
+func (s *websocketService) withReadReceipts(roomType string, messages []domain.WebSocketMessage) ([]domain.WebSocketMessage, error) {
+	if roomType != domain.RoomTypeGroup || len(messages) == 0 {
+		return messages, nil
+	}
+
+	messageIDs := make([]string, len(messages))
+	for i := range messages {
+		messageIDs[i] = messages[i].ID
+	}
+	receipts, err := s.readReceipts(messageIDs)
 	if err != nil {
 		return nil, err
 	}
-
-	return rooms, nil
+	for i := range messages {
+		messages[i].ReadBy = receipts[messages[i].ID]
+	}
+	return messages, nil
 }
 
-func (s *websocketService) GetRoomHistory(roomID string, limit, offset int) ([]domain.WebSocketMessage, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// GetMissingMessages returns up to limit room messages after lastSequence,
+// oldest first, so a reconnecting client can fill the gap it missed without
+// refetching the whole history. Clients ask again from the last sequence
+// received until a reply comes back short.
+func (s *websocketService) GetMissingMessages(roomID, userID string, lastSequence int64, limit int) ([]domain.WebSocketMessage, error) {
+	if lastSequence < 0 {
+		return nil, domain.ErrInvalidSequence
+	}
+	if err := s.requireMembers(roomID, userID); err != nil {
+		return nil, err
+	}
 
-	_, exists := s.hub.Rooms[roomID]
-	if !exists {
-		return nil, domain.ErrRoomNotFound
+	limit, err := s.historyLimit(limit)
+	if err != nil {
+		return nil, err
 	}
 
-	messages, err := s.roomRepo.GetRoomMessages(roomID, limit, offset)
+	messages, err := s.roomRepo.GetRoomMessagesAfterSequence(roomID, lastSequence, limit)
 	if err != nil {
 		return nil, err
 	}
+	return toWebSocketMessages(messages), nil
+}
+
+// historyLimit applies the default page size and rejects requests for more
+// than chat.max_history_messages messages
+func (s *websocketService) historyLimit(limit int) (int, error) {
+	if limit <= 0 {
+		return s.paginator.DefaultLimit(), nil
+	}
+	if limit > s.maxHistoryMessages {
+		return 0, fmt.Errorf("%w: requested %d messages, maximum is %d", domain.ErrHistoryLimitExceeded, limit, s.maxHistoryMessages)
+	}
+	return limit, nil
+}
 
+func toWebSocketMessages(messages []*domain.Message) []domain.WebSocketMessage {
 	wsMessages := make([]domain.WebSocketMessage, len(messages))
 	for i, msg := range messages {
 		wsMessages[i] = domain.WebSocketMessage{
 			Type:         msg.Type,
 			ID:           msg.ID,
+			Sequence:     msg.Sequence,
 			RoomID:       msg.RoomID,
 			UserID:       msg.UserID,
 			Content:      msg.Content,
@@ -727,19 +2190,24 @@ func (s *websocketService) GetRoomHistory(roomID string, limit, offset int) ([]d
 		}
 	}
 
-	return wsMessages, nil
+	return wsMessages
 }
 
 func (s *websocketService) writePump(conn *websocket.Conn, c *domain.Connection) {
+	ticker := time.NewTicker(pingPeriod)
 	defer func() {
+		ticker.Stop()
+		c.Cancel()
 		conn.Close()
+		s.pumps.Done()
 	}()
 
 	for {
 		select {
 		case message, ok := <-c.Send:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
-				conn.WriteMessage(websocket.CloseMessage, []byte{})
+				conn.WriteMessage(websocket.CloseMessage, s.closeMessage())
 				return
 			}
 
@@ -749,16 +2217,44 @@ func (s *websocketService) writePump(conn *websocket.Conn, c *domain.Connection)
 			}
 
 			json.NewEncoder(w).Encode(message)
+			if err := w.Close(); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-c.Done():
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			conn.WriteMessage(websocket.CloseMessage, s.closeMessage())
+			return
 		}
 	}
 }
 
 func (s *websocketService) readPump(conn *websocket.Conn, c *domain.Connection) {
 	defer func() {
-		s.hub.Unregister <- c
+		c.Cancel()
+		select {
+		case s.hub.Unregister <- c:
+		case <-s.hub.Done:
+			// The hub dropped every connection on its way out
+		}
 		conn.Close()
+		s.pumps.Done()
 	}()
 
+	// ReadMessage only returns on error. Once the connection is cancelled the
+	// write pump sends the close frame and closes the socket, which unblocks
+	// it; closing it here as well could cut that frame off.
+
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		c.MarkPong(time.Now())
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
 	for {
 		_, message, err := conn.ReadMessage()
 		if err != nil {
@@ -774,27 +2270,226 @@ func (s *websocketService) readPump(conn *websocket.Conn, c *domain.Connection)
 			continue
 		}
 
-		switch wsMessage.Type {
-		case domain.RoomTypeDirect:
-			s.hub.DirectMessage <- wsMessage
-		case domain.RoomTypeGroup:
-			s.hub.Broadcast <- wsMessage
-		default:
-			s.hub.Broadcast <- wsMessage
+		s.handleClientFrame(c, wsMessage)
+	}
+}
+
+// handleClientFrame acts on a frame read from a client through the same
+// service methods the REST API uses, so messages are persisted. The sender
+// is always the connection's user, whatever the frame claims, and frames
+// for a room require membership. Failures, including frame types clients
+// may not send, are answered with an error frame on the same connection.
+func (s *websocketService) handleClientFrame(c *domain.Connection, frame domain.WebSocketMessage) {
+	var err error
+	switch frame.Type {
+	case domain.MessageTypeRequestMissing:
+		s.replayMissingMessages(c, frame)
+		return
+	case domain.MessageTypeText:
+		// The REST API validates this in the handler
+		if frame.Content == "" {
+			err = fmt.Errorf("%w: content is required", domain.ErrInvalidMessage)
+			break
+		}
+		if frame.RoomID == "" && frame.TargetID != "" {
+			_, err = s.SendDirectMessage(c.UserID, frame.TargetID, frame.Content)
+			break
+		}
+		if err = s.requireRoomFrame(c, frame); err == nil {
+			_, err = s.SendGroupMessage(frame.RoomID, c.UserID, frame.Content)
+		}
+	case domain.MessageTypeTyping:
+		if err = s.requireRoomFrame(c, frame); err == nil {
+			err = s.SendTypingIndicator(frame.RoomID, c.UserID)
+		}
+	case domain.MessageTypeRead:
+		if err = s.requireRoomFrame(c, frame); err == nil {
+			err = s.MarkMessageAsRead(frame.RoomID, c.UserID, frame.MessageID)
+		}
+	default:
+		err = fmt.Errorf("%w: %q", domain.ErrUnsupportedFrame, frame.Type)
+	}
+
+	if errors.Is(err, domain.ErrRateLimited) {
+		trySend(c, domain.WebSocketMessage{
+			Type:      domain.MessageTypeRateLimited,
+			RoomID:    frame.RoomID,
+			Content:   err.Error(),
+			Timestamp: s.now(),
+		})
+		return
+	}
+	if err != nil {
+		sendError(c, frame.RoomID, err)
+	}
+}
+
+// requireRoomFrame checks the frame names a room the connection's user is a
+// member of
+func (s *websocketService) requireRoomFrame(c *domain.Connection, frame domain.WebSocketMessage) error {
+	if frame.RoomID == "" {
+		return fmt.Errorf("%w: %q message has no room", domain.ErrUnroutableMessage, frame.Type)
+	}
+	return s.requireMembers(frame.RoomID, c.UserID)
+}
+
+// closeMessage is the payload of the close frame sent when a connection
+// ends; during shutdown it tells clients the server is going away so they
+// can reconnect elsewhere
+func (s *websocketService) closeMessage() []byte {
+	if s.ctx.Err() != nil {
+		return websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	}
+	return []byte{}
+}
+
+// Close stops the hub and cancels every connection, whose write pumps then
+// send a going-away close frame. It returns once the hub goroutine has
+// exited; repeated calls do nothing.
+func (s *websocketService) Close() error {
+	s.mu.Lock()
+	s.stop()
+	s.mu.Unlock()
+
+	s.typingMu.Lock()
+	for key, state := range s.typing {
+		state.timer.Stop()
+		delete(s.typing, key)
+	}
+	s.typingMu.Unlock()
+
+	s.sendLimiter.Close()
+	<-s.hub.Done
+	return nil
+}
+
+// Shutdown closes the service and waits for every connection's pumps to
+// return, or returns ctx's error if they have not by the time ctx is done
+func (s *websocketService) Shutdown(ctx context.Context) error {
+	s.Close()
+
+	done := make(chan struct{})
+	go func() {
+		s.pumps.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// replayMissingMessages answers a request_missing frame on the requesting
+// connection only, one default-sized page at a time
+func (s *websocketService) replayMissingMessages(c *domain.Connection, request domain.WebSocketMessage) {
+	messages, err := s.GetMissingMessages(request.RoomID, c.UserID, request.Sequence, 0)
+	if err != nil {
+		sendError(c, request.RoomID, err)
+		return
+	}
+
+	for _, message := range messages {
+		if !trySend(c, message) {
+			s.reapConnections([]*domain.Connection{c})
+			return
+		}
+	}
+}
+
+// sendError reports a rejected client frame back on its connection
+func sendError(c *domain.Connection, roomID string, err error) {
+	trySend(c, domain.WebSocketMessage{
+		Type:      domain.MessageTypeError,
+		RoomID:    roomID,
+		Content:   err.Error(),
+		Timestamp: time.Now(),
+	})
+}
+
+// HeartbeatStats reports how long it has been since each connected client
+// last answered a ping
+func (s *websocketService) HeartbeatStats() domain.HeartbeatStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var stats domain.HeartbeatStats
+	var total time.Duration
+	now := time.Now()
+	for _, conn := range s.hub.Connections {
+		since := now.Sub(conn.LastPong())
+		if stats.Connections == 0 || since < stats.MinSinceLastPong {
+			stats.MinSinceLastPong = since
 		}
+		if since > stats.MaxSinceLastPong {
+			stats.MaxSinceLastPong = since
+		}
+		total += since
+		stats.Connections++
+	}
+	if stats.Connections > 0 {
+		stats.AvgSinceLastPong = total / time.Duration(stats.Connections)
+	}
+	return stats
+}
+
+// loadRoom returns the room cached in the hub, loading it and its members
+// from the repository when it is not cached yet
+func (s *websocketService) loadRoom(roomID string) (*domain.Room, error) {
+	s.mu.RLock()
+	room, exists := s.hub.Rooms[roomID]
+	s.mu.RUnlock()
+	if exists {
+		return room, nil
+	}
+
+	room, err := s.roomRepo.GetRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if room == nil {
+		return nil, domain.ErrRoomNotFound
+	}
+
+	users, err := s.roomRepo.GetRoomUsers(roomID)
+	if err != nil {
+		return nil, err
 	}
+	room.Users = users
+
+	admins, err := s.roomRepo.GetRoomAdmins(roomID)
+	if err != nil {
+		return nil, err
+	}
+	room.Admins = admins
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cached, exists := s.hub.Rooms[roomID]; exists {
+		return cached, nil
+	}
+	s.hub.Rooms[roomID] = room
+
+	return room, nil
 }
 
+// generateRoomID returns a unique room ID. The old timestamp-only format
+// collided for rooms created within the same second.
 func generateRoomID() string {
-	return time.Now().Format("20060102150405") + "_" + time.Now().Format("000000000")
+	return uuid.NewString()
 }
 
 func generateMessageID() string {
-	return time.Now().Format("20060102150405") + "_" + time.Now().Format("000000000")
+	return uuid.NewString()
 }
 
+// generateMessageStatusID returns a unique status ID. Statuses are saved by
+// ID, so the old timestamp-only format overwrote ones created in the same second.
 func generateMessageStatusID() string {
-	return time.Now().Format("20060102150405") + "_" + time.Now().Format("000000000")
+	return uuid.NewString()
 }
 
 func generateDirectRoomID(userID1, userID2 string) string {
@@ -821,16 +2516,37 @@ func (s *websocketService) SendTaskUpdateNotification(userID, taskID, taskTitle,
 	if err := s.roomRepo.CreateNotification(notification); err != nil {
 		return err
 	}
+	s.trimNotifications(userID)
 
-	message := domain.WebSocketMessage{
-		Type:      domain.MessageTypeTaskUpdate,
-		ID:        notification.ID,
-		UserID:    userID,
-		Content:   notification.Content,
-		Timestamp: time.Now(),
+	s.dispatchNotification(notification)
+	s.notifyIfOffline(userID, notification)
+	return nil
+}
+
+// SendTaskSummaryUpdate pushes a summary_update event to every connected user
+// with the role so their dashboards refetch the task summary. Nothing is
+// stored, so users who are offline simply load a fresh summary next time.
+func (s *websocketService) SendTaskSummaryUpdate(role string) error {
+	s.mu.RLock()
+	var userIDs []string
+	for userID, conn := range s.hub.Connections {
+		if conn.Role == role {
+			userIDs = append(userIDs, userID)
+		}
 	}
+	s.mu.RUnlock()
 
-	s.hub.DirectMessage <- message
+	for _, userID := range userIDs {
+		err := s.sendDirect(domain.WebSocketMessage{
+			Type:      domain.MessageTypeSummaryUpdate,
+			UserID:    userID,
+			TargetID:  userID,
+			Timestamp: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -847,19 +2563,20 @@ func (s *websocketService) SendMentionNotification(userID, senderID, content str
 		UpdatedAt: time.Now(),
 	}
 
+	return s.deliverNotification(notification)
+}
+
+// deliverNotification stores the notification, then fans it out to the
+// registered notifiers and, if the user has been away long enough, the
+// offline notifier
+func (s *websocketService) deliverNotification(notification *domain.Notification) error {
 	if err := s.roomRepo.CreateNotification(notification); err != nil {
 		return err
 	}
+	s.trimNotifications(notification.UserID)
 
-	message := domain.WebSocketMessage{
-		Type:      domain.MessageTypeMention,
-		ID:        notification.ID,
-		UserID:    userID,
-		Content:   notification.Content,
-		Timestamp: time.Now(),
-	}
-
-	s.hub.DirectMessage <- message
+	s.dispatchNotification(notification)
+	s.notifyIfOffline(notification.UserID, notification)
 	return nil
 }
 
@@ -878,20 +2595,75 @@ func (s *websocketService) SendSystemNotification(userID, title, content string)
 	if err := s.roomRepo.CreateNotification(notification); err != nil {
 		return err
 	}
+	s.trimNotifications(userID)
 
-	message := domain.WebSocketMessage{
-		Type:      domain.MessageTypeSystem,
-		ID:        notification.ID,
-		UserID:    userID,
-		Content:   notification.Content,
-		Timestamp: time.Now(),
+	s.dispatchNotification(notification)
+	return nil
+}
+
+// RegisterNotifier adds a delivery channel that every notification fans out
+// to. Users may only set preferences for channels something delivers over.
+func (s *websocketService) RegisterNotifier(channel string, notifier Notifier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifiers = append(s.notifiers, registeredNotifier{channel: channel, notifier: notifier})
+}
+
+// dispatchNotification delivers the notification through every registered
+// notifier the user has not opted out of
+func (s *websocketService) dispatchNotification(notification *domain.Notification) {
+	s.mu.RLock()
+	notifiers := append([]registeredNotifier(nil), s.notifiers...)
+	s.mu.RUnlock()
+
+	preferences, err := s.roomRepo.GetNotificationPreferences(notification.UserID)
+	if err != nil {
+		log.Printf("error loading notification preferences for user %s: %v", notification.UserID, err)
 	}
 
-	s.hub.DirectMessage <- message
-	return nil
+	for _, n := range notifiers {
+		if !domain.NotificationEnabled(preferences, n.channel, notification.Type) {
+			continue
+		}
+		if err := n.notifier.Notify(context.Background(), notification.UserID, notification); err != nil {
+			log.Printf("error delivering notification %s over %s: %v", notification.ID, n.channel, err)
+		}
+	}
 }
 
-func (s *websocketService) MarkNotificationAsRead(notificationID string) error {
+// trimNotifications applies the retention policy to the user's read
+// notifications. Unread notifications are always kept. Failures are logged
+// since the new notification has already been stored.
+func (s *websocketService) trimNotifications(userID string) {
+	if s.maxNotifications <= 0 && s.notificationMaxAge <= 0 {
+		return
+	}
+
+	var before time.Time
+	if s.notificationMaxAge > 0 {
+		before = time.Now().Add(-s.notificationMaxAge)
+	}
+	if err := s.roomRepo.TrimReadNotifications(userID, s.maxNotifications, before); err != nil {
+		log.Printf("error trimming notifications for user %s: %v", userID, err)
+	}
+}
+
+// ListNotifications returns a page of the user's notifications, newest first
+func (s *websocketService) ListNotifications(userID string, limit, offset int) ([]*domain.Notification, error) {
+	limit, offset = s.paginator.Limit(limit), s.paginator.Offset(offset)
+	return s.roomRepo.GetUserNotifications(userID, limit, offset)
+}
+
+// MarkNotificationAsRead marks one of the user's notifications as read.
+// Notifications owned by someone else are reported as not found.
+func (s *websocketService) MarkNotificationAsRead(notificationID, userID string) error {
+	notification, err := s.roomRepo.GetNotification(notificationID)
+	if err != nil {
+		return err
+	}
+	if notification == nil || notification.UserID != userID {
+		return domain.ErrNotificationNotFound
+	}
 	return s.roomRepo.MarkNotificationAsRead(notificationID)
 }
 
@@ -899,6 +2671,220 @@ func (s *websocketService) GetUnreadNotificationCount(userID string) (int, error
 	return s.roomRepo.GetUnreadNotificationCount(userID)
 }
 
+func (s *websocketService) GetNotificationPreferences(userID string) ([]*domain.NotificationPreference, error) {
+	return s.roomRepo.GetNotificationPreferences(userID)
+}
+
+// SetNotificationPreference opts the user in to or out of a channel, for one
+// notification type or, when notificationType is empty, for all of them. An
+// existing preference for the same channel and type is updated in place.
+// Channels nothing delivers over are rejected.
+func (s *websocketService) SetNotificationPreference(userID, channel, notificationType string, enabled bool) (*domain.NotificationPreference, error) {
+	switch channel {
+	case domain.NotificationChannelWebSocket, domain.NotificationChannelEmail, domain.NotificationChannelWebhook:
+	default:
+		return nil, domain.ErrInvalidPreference
+	}
+	if !s.deliversOver(channel) {
+		return nil, fmt.Errorf("%w: nothing delivers over %s", domain.ErrInvalidPreference, channel)
+	}
+	switch notificationType {
+	case "", domain.NotificationTypeTaskUpdate, domain.NotificationTypeMention,
+		domain.NotificationTypeSystem, domain.NotificationTypeMessage:
+	default:
+		return nil, domain.ErrInvalidPreference
+	}
+
+	preferences, err := s.roomRepo.GetNotificationPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	preference := &domain.NotificationPreference{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Channel:   channel,
+		Type:      notificationType,
+		CreatedAt: now,
+	}
+	for _, p := range preferences {
+		if p.Channel == channel && p.Type == notificationType {
+			preference = p
+			break
+		}
+	}
+	preference.Enabled = enabled
+	preference.UpdatedAt = now
+
+	if err := s.roomRepo.SaveNotificationPreference(preference); err != nil {
+		return nil, err
+	}
+	return preference, nil
+}
+
+// deliversOver reports whether notifications can reach users over the
+// channel: through a registered notifier or, for email, the offline fallback
+func (s *websocketService) deliversOver(channel string) bool {
+	if channel == domain.NotificationChannelEmail && s.offlineNotifier != nil {
+		if _, noop := s.offlineNotifier.(noopNotifier); !noop {
+			return true
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, n := range s.notifiers {
+		if n.channel == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyIfOffline falls back to the offline notifier when the user has had no
+// live connection for longer than the configured threshold
+func (s *websocketService) notifyIfOffline(userID string, notification *domain.Notification) {
+	if !s.isOfflineLongerThan(userID, s.offlineThreshold) {
+		return
+	}
+
+	preferences, err := s.roomRepo.GetNotificationPreferences(userID)
+	if err != nil {
+		log.Printf("error loading notification preferences for user %s: %v", userID, err)
+	}
+	if !domain.NotificationEnabled(preferences, domain.NotificationChannelEmail, notification.Type) {
+		return
+	}
+
+	if err := s.offlineNotifier.Notify(context.Background(), userID, notification); err != nil {
+		log.Printf("error notifying offline user %s: %v", userID, err)
+	}
+}
+
+// notifyNewMessage keeps members' unread counts current and sends a mention
+// notification to every member the message mentions, unless they muted the
+// sender in that room. Mentions may go out by email, so they are delivered
+// off the sender's request.
+func (s *websocketService) notifyNewMessage(message *domain.Message) {
+	members, err := s.roomRepo.GetRoomUsers(message.RoomID)
+	if err != nil {
+		log.Printf("error loading members of room %s: %v", message.RoomID, err)
+		return
+	}
+
+	var recipients, mentioned []string
+	for _, member := range members {
+		if member == message.UserID {
+			continue
+		}
+		recipients = append(recipients, member)
+		if mentions(message.Content, member) {
+			mentioned = append(mentioned, member)
+		}
+	}
+	// Muting silences notifications, but the message still counts as unread
+	s.pushUnreadCounts(message.RoomID, recipients...)
+	if len(mentioned) == 0 {
+		return
+	}
+
+	muters, err := s.roomRepo.GetRoomUserMuters(message.RoomID, message.UserID)
+	if err != nil {
+		log.Printf("error loading mutes for room %s: %v", message.RoomID, err)
+		return
+	}
+
+	go func() {
+		for _, member := range mentioned {
+			if slices.Contains(muters, member) {
+				continue
+			}
+			notification := &domain.Notification{
+				ID:        generateNotificationID(),
+				UserID:    member,
+				Type:      domain.NotificationTypeMention,
+				Title:     "You were mentioned",
+				Content:   message.Content,
+				Data:      `{"room_id": "` + message.RoomID + `", "message_id": "` + message.ID + `", "sender_id": "` + message.UserID + `"}`,
+				CreatedAt: message.CreatedAt,
+				UpdatedAt: message.CreatedAt,
+			}
+			if err := s.deliverNotification(notification); err != nil {
+				log.Printf("error notifying user %s of a mention: %v", member, err)
+			}
+		}
+	}()
+}
+
+// mentions reports whether content mentions the user. Clients insert
+// @<user ID> when a mention is picked from user search.
+func mentions(content, userID string) bool {
+	return strings.Contains(content, "@"+userID)
+}
+
+// pushUnreadCounts sends each online user their current unread count for
+// the room, keeping badges in sync across devices. The counts come from one
+// query, run in the background so senders don't wait on it.
+func (s *websocketService) pushUnreadCounts(roomID string, userIDs ...string) {
+	s.mu.RLock()
+	online := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if _, ok := s.hub.Connections[userID]; ok {
+			online = append(online, userID)
+		}
+	}
+	s.mu.RUnlock()
+	if len(online) == 0 {
+		return
+	}
+
+	go func() {
+		counts, err := s.roomRepo.GetRoomUnreadCounts(roomID, online)
+		if err != nil {
+			log.Printf("error counting unread messages in room %s: %v", roomID, err)
+			return
+		}
+
+		for _, userID := range online {
+			count := counts[userID]
+			err := s.sendDirect(domain.WebSocketMessage{
+				Type:        domain.MessageTypeUnreadUpdate,
+				RoomID:      roomID,
+				UserID:      userID,
+				TargetID:    userID,
+				UnreadCount: &count,
+				Timestamp:   time.Now(),
+			})
+			if errors.Is(err, domain.ErrHubStopped) {
+				return
+			}
+			if err != nil {
+				log.Printf("error sending unread count to user %s: %v", userID, err)
+			}
+		}
+	}()
+}
+
+// isOfflineLongerThan reports whether the user has no live connection and
+// has not had one for at least the given duration. Users never seen since
+// startup count as last seen at startup, since they may have disconnected
+// just before it.
+func (s *websocketService) isOfflineLongerThan(userID string, threshold time.Duration) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, online := s.hub.Connections[userID]; online {
+		return false
+	}
+
+	seen, exists := s.lastSeen[userID]
+	if !exists {
+		seen = s.startedAt
+	}
+	return time.Since(seen) > threshold
+}
+
 func generateNotificationID() string {
-	return time.Now().Format("20060102150405") + "_" + time.Now().Format("000000000")
+	return uuid.NewString()
 }
@@ -0,0 +1,2896 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/personal/task-management/internal/domain"
+	"github.com/personal/task-management/internal/repositories"
+	"github.com/personal/task-management/pkg/app"
+	localmemory "github.com/personal/task-management/pkg/cache/local-memory"
+	"github.com/personal/task-management/pkg/ratelimit"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeChatRepository is an in-memory ChatRepository used to exercise the
+// websocket service without a database
+type fakeChatRepository struct {
+	mu            sync.Mutex
+	rooms         map[string]*domain.Room
+	roomUsers     map[string][]string
+	roomAdmins    map[string][]string
+	messages      map[string]*domain.Message
+	statuses      map[string]*domain.MessageStatus
+	notifications map[string]*domain.Notification
+	preferences   map[string][]*domain.NotificationPreference
+	mutes         map[string]*domain.RoomUserMute
+	statsTopRooms []int
+	// receiptQueries counts GetMessageReadReceipts calls
+	receiptQueries int
+}
+
+// fakeClock stands in for the service's clock. Timers fire, on the calling
+// goroutine, when Advance moves past them.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock *fakeClock
+	at    time.Time
+	f     func()
+	done  bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var due []*fakeTimer
+	for _, t := range c.timers {
+		if !t.done && !t.at.After(c.now) {
+			t.done = true
+			due = append(due, t)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, t := range due {
+		t.f()
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	stopped := !t.done
+	t.done = true
+	return stopped
+}
+
+func newFakeChatRepository() *fakeChatRepository {
+	return &fakeChatRepository{
+		rooms:         make(map[string]*domain.Room),
+		roomUsers:     make(map[string][]string),
+		roomAdmins:    make(map[string][]string),
+		messages:      make(map[string]*domain.Message),
+		statuses:      make(map[string]*domain.MessageStatus),
+		notifications: make(map[string]*domain.Notification),
+		preferences:   make(map[string][]*domain.NotificationPreference),
+		mutes:         make(map[string]*domain.RoomUserMute),
+	}
+}
+
+func (r *fakeChatRepository) CreateRoom(room *domain.Room) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *room
+	stored.Users = nil
+	r.rooms[room.ID] = &stored
+	return nil
+}
+
+func (r *fakeChatRepository) GetRoom(roomID string) (*domain.Room, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	room, exists := r.rooms[roomID]
+	if !exists {
+		return nil, nil
+	}
+	loaded := *room
+	return &loaded, nil
+}
+
+func (r *fakeChatRepository) UpdateRoom(room *domain.Room) error {
+	return r.CreateRoom(room)
+}
+
+func (r *fakeChatRepository) DeleteRoom(roomID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, message := range r.messages {
+		if message.RoomID != roomID {
+			continue
+		}
+		for key, status := range r.statuses {
+			if status.MessageID == id {
+				delete(r.statuses, key)
+			}
+		}
+		delete(r.messages, id)
+	}
+	for key, mute := range r.mutes {
+		if mute.RoomID == roomID {
+			delete(r.mutes, key)
+		}
+	}
+	delete(r.rooms, roomID)
+	delete(r.roomUsers, roomID)
+	delete(r.roomAdmins, roomID)
+	return nil
+}
+
+func (r *fakeChatRepository) ListUserRooms(userID string, filter repositories.RoomFilter) ([]*domain.Room, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var rooms []*domain.Room
+	for roomID, users := range r.roomUsers {
+		room, exists := r.rooms[roomID]
+		if !exists || !slices.Contains(users, userID) {
+			continue
+		}
+		switch {
+		case filter.Type != "" && room.Type != filter.Type,
+			filter.IsArchived != nil && room.IsArchived != *filter.IsArchived,
+			filter.IsMuted != nil && room.IsMuted != *filter.IsMuted,
+			!strings.Contains(strings.ToLower(room.Name), strings.ToLower(filter.Search)):
+			continue
+		}
+		rooms = append(rooms, room)
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].UpdatedAt.After(rooms[j].UpdatedAt)
+	})
+	if filter.Limit <= 0 {
+		return rooms[min(filter.Offset, len(rooms)):], nil
+	}
+	return page(rooms, filter.Limit, filter.Offset), nil
+}
+
+// page applies limit/offset the way the SQL repositories do
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+func (r *fakeChatRepository) CreateMessage(message *domain.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	message.Sequence = 1
+	for _, existing := range r.messages {
+		if existing.RoomID == message.RoomID && existing.Sequence >= message.Sequence {
+			message.Sequence = existing.Sequence + 1
+		}
+	}
+	r.messages[message.ID] = message
+	return nil
+}
+
+func (r *fakeChatRepository) GetMessage(messageID string) (*domain.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.messages[messageID], nil
+}
+
+func (r *fakeChatRepository) GetMessagesByIDs(messageIDs []string) ([]*domain.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var messages []*domain.Message
+	for _, id := range messageIDs {
+		if message, exists := r.messages[id]; exists {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+func (r *fakeChatRepository) UpdateMessage(message *domain.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages[message.ID] = message
+	return nil
+}
+
+func (r *fakeChatRepository) GetLatestUserMessage(roomID, userID string) (*domain.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var latest *domain.Message
+	for _, message := range r.messages {
+		if message.RoomID == roomID && message.UserID == userID &&
+			(latest == nil || message.CreatedAt.After(latest.CreatedAt)) {
+			latest = message
+		}
+	}
+	return latest, nil
+}
+
+func (r *fakeChatRepository) DeleteMessage(messageID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.messages, messageID)
+	return nil
+}
+
+func (r *fakeChatRepository) GetRoomMessages(roomID string, limit, offset int, order domain.HistoryOrder) ([]*domain.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var messages []*domain.Message
+	for _, message := range r.messages {
+		if message.RoomID == roomID {
+			messages = append(messages, message)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if order == domain.HistoryOrderOldestFirst {
+			return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+		}
+		return messages[i].CreatedAt.After(messages[j].CreatedAt)
+	})
+	return page(messages, limit, offset), nil
+}
+
+func (r *fakeChatRepository) GetRoomMessagesBefore(roomID string, createdAt time.Time, messageID string, limit int) ([]*domain.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var messages []*domain.Message
+	for _, message := range r.messages {
+		if message.RoomID != roomID {
+			continue
+		}
+		if message.CreatedAt.Before(createdAt) || (message.CreatedAt.Equal(createdAt) && message.ID < messageID) {
+			messages = append(messages, message)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if !messages[i].CreatedAt.Equal(messages[j].CreatedAt) {
+			return messages[i].CreatedAt.After(messages[j].CreatedAt)
+		}
+		return messages[i].ID > messages[j].ID
+	})
+	return page(messages, limit, 0), nil
+}
+
+func (r *fakeChatRepository) GetRoomMessagesAfterSequence(roomID string, after int64, limit int) ([]*domain.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var messages []*domain.Message
+	for _, message := range r.messages {
+		if message.RoomID == roomID && message.Sequence > after {
+			messages = append(messages, message)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Sequence < messages[j].Sequence
+	})
+	return page(messages, limit, 0), nil
+}
+
+func (r *fakeChatRepository) GetChatStats(from, to time.Time, topRooms int) (*domain.ChatStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statsTopRooms = append(r.statsTopRooms, topRooms)
+	return &domain.ChatStats{TotalRooms: int64(len(r.rooms)), TotalMessages: int64(len(r.messages))}, nil
+}
+
+func (r *fakeChatRepository) AddUserToRoom(roomID, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.roomUsers[roomID] = append(r.roomUsers[roomID], userID)
+	return nil
+}
+
+func (r *fakeChatRepository) RemoveUserFromRoom(roomID, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.roomUsers[roomID] = slices.DeleteFunc(slices.Clone(r.roomUsers[roomID]), func(id string) bool { return id == userID })
+	r.roomAdmins[roomID] = slices.DeleteFunc(slices.Clone(r.roomAdmins[roomID]), func(id string) bool { return id == userID })
+	return nil
+}
+
+func (r *fakeChatRepository) SetRoomUserRole(roomID, userID, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !slices.Contains(r.roomUsers[roomID], userID) {
+		return nil
+	}
+	admins := slices.DeleteFunc(slices.Clone(r.roomAdmins[roomID]), func(id string) bool { return id == userID })
+	if role == domain.RoomRoleAdmin {
+		admins = append(admins, userID)
+	}
+	r.roomAdmins[roomID] = admins
+	return nil
+}
+
+func (r *fakeChatRepository) GetRoomAdmins(roomID string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.roomAdmins[roomID]), nil
+}
+
+func (r *fakeChatRepository) GetRoomUsers(roomID string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.roomUsers[roomID]...), nil
+}
+
+func (r *fakeChatRepository) MuteRoomUser(mute *domain.RoomUserMute) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := mute.RoomID + ":" + mute.UserID + ":" + mute.MutedUserID
+	if _, exists := r.mutes[key]; !exists {
+		r.mutes[key] = mute
+	}
+	return nil
+}
+
+func (r *fakeChatRepository) UnmuteRoomUser(roomID, userID, mutedUserID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.mutes, roomID+":"+userID+":"+mutedUserID)
+	return nil
+}
+
+func (r *fakeChatRepository) GetRoomUserMuters(roomID, mutedUserID string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var userIDs []string
+	for _, mute := range r.mutes {
+		if mute.RoomID == roomID && mute.MutedUserID == mutedUserID {
+			userIDs = append(userIDs, mute.UserID)
+		}
+	}
+	return userIDs, nil
+}
+
+func (r *fakeChatRepository) UpdateMessageStatus(status *domain.MessageStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses[status.MessageID+":"+status.UserID] = status
+	return nil
+}
+
+func (r *fakeChatRepository) SearchMessages(userID, query string, limit, offset int) ([]*domain.MessageSearchResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var results []*domain.MessageSearchResult
+	for _, message := range r.messages {
+		if !slices.Contains(r.roomUsers[message.RoomID], userID) ||
+			!strings.Contains(strings.ToLower(message.Content), strings.ToLower(query)) {
+			continue
+		}
+		result := &domain.MessageSearchResult{Message: *message}
+		if room := r.rooms[message.RoomID]; room != nil {
+			result.RoomName = room.Name
+		}
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CreatedAt.After(results[j].CreatedAt) })
+	if offset >= len(results) {
+		return nil, nil
+	}
+	return results[offset:min(offset+limit, len(results))], nil
+}
+
+func (r *fakeChatRepository) GetMessageReadReceipts(messageIDs []string) ([]*domain.MessageStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.receiptQueries++
+	var statuses []*domain.MessageStatus
+	for _, status := range r.statuses {
+		if slices.Contains(messageIDs, status.MessageID) && status.Status == domain.MessageStatusRead {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].UpdatedAt.Before(statuses[j].UpdatedAt) })
+	return statuses, nil
+}
+
+func (r *fakeChatRepository) GetMessageStatus(messageID, userID string) (*domain.MessageStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.statuses[messageID+":"+userID], nil
+}
+
+func (r *fakeChatRepository) GetUnreadCounts(userID string) (map[string]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := make(map[string]int)
+	for roomID, users := range r.roomUsers {
+		for _, id := range users {
+			if id == userID {
+				counts[roomID] = 0
+			}
+		}
+	}
+	for _, message := range r.messages {
+		if _, member := counts[message.RoomID]; !member || message.UserID == userID {
+			continue
+		}
+		if status := r.statuses[message.ID+":"+userID]; status != nil && status.Status == domain.MessageStatusRead {
+			continue
+		}
+		counts[message.RoomID]++
+	}
+	return counts, nil
+}
+
+func (r *fakeChatRepository) GetRoomUnreadCounts(roomID string, userIDs []string) (map[string]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := make(map[string]int)
+	for _, userID := range r.roomUsers[roomID] {
+		if slices.Contains(userIDs, userID) {
+			counts[userID] = 0
+		}
+	}
+	for _, message := range r.messages {
+		if message.RoomID != roomID {
+			continue
+		}
+		for userID := range counts {
+			if message.UserID == userID {
+				continue
+			}
+			if status := r.statuses[message.ID+":"+userID]; status != nil && status.Status == domain.MessageStatusRead {
+				continue
+			}
+			counts[userID]++
+		}
+	}
+	return counts, nil
+}
+
+func (r *fakeChatRepository) CreateNotification(notification *domain.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifications[notification.ID] = notification
+	return nil
+}
+
+func (r *fakeChatRepository) GetNotification(notificationID string) (*domain.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.notifications[notificationID], nil
+}
+
+func (r *fakeChatRepository) UpdateNotification(notification *domain.Notification) error {
+	return r.CreateNotification(notification)
+}
+
+func (r *fakeChatRepository) DeleteNotification(notificationID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.notifications, notificationID)
+	return nil
+}
+
+func (r *fakeChatRepository) GetUserNotifications(userID string, limit, offset int) ([]*domain.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var notifications []*domain.Notification
+	for _, notification := range r.notifications {
+		if notification.UserID == userID {
+			notifications = append(notifications, notification)
+		}
+	}
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+	})
+	return page(notifications, limit, offset), nil
+}
+
+func (r *fakeChatRepository) MarkNotificationAsRead(notificationID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if notification, exists := r.notifications[notificationID]; exists {
+		notification.IsRead = true
+	}
+	return nil
+}
+
+func (r *fakeChatRepository) GetUnreadNotificationCount(userID string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, notification := range r.notifications {
+		if notification.UserID == userID && !notification.IsRead {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeChatRepository) TrimReadNotifications(userID string, keep int, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var owned []*domain.Notification
+	for _, notification := range r.notifications {
+		if notification.UserID == userID {
+			owned = append(owned, notification)
+		}
+	}
+	sort.Slice(owned, func(i, j int) bool {
+		return owned[i].CreatedAt.After(owned[j].CreatedAt)
+	})
+	for i, notification := range owned {
+		beyondKeep := keep > 0 && i >= keep
+		expired := !before.IsZero() && notification.CreatedAt.Before(before)
+		if notification.IsRead && (beyondKeep || expired) {
+			delete(r.notifications, notification.ID)
+		}
+	}
+	return nil
+}
+
+// recordingNotifier captures the users it was asked to notify
+type recordingNotifier struct {
+	mu    sync.Mutex
+	users []string
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, userID string, notification *domain.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.users = append(n.users, userID)
+	return nil
+}
+
+func (n *recordingNotifier) notified() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.users...)
+}
+
+func (r *fakeChatRepository) GetNotificationPreferences(userID string) ([]*domain.NotificationPreference, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.preferences[userID], nil
+}
+
+func (r *fakeChatRepository) SaveNotificationPreference(preference *domain.NotificationPreference) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, p := range r.preferences[preference.UserID] {
+		if p.ID != "" && p.ID == preference.ID {
+			r.preferences[preference.UserID][i] = preference
+			return nil
+		}
+	}
+	r.preferences[preference.UserID] = append(r.preferences[preference.UserID], preference)
+	return nil
+}
+
+type WebSocketServiceTestSuite struct {
+	suite.Suite
+	repo     *fakeChatRepository
+	notifier *recordingNotifier
+	service  *websocketService
+}
+
+func (suite *WebSocketServiceTestSuite) SetupTest() {
+	cfg := viper.New()
+	cfg.Set("pagination.default_limit", 10)
+	cfg.Set("pagination.max_limit", 10)
+	cfg.Set("chat.max_history_messages", 10)
+	cfg.Set("notifications.offline_email.threshold", time.Minute)
+	cfg.Set("chat.max_file_size", "1MB")
+	cfg.Set("chat.allowed_file_types", []string{"application/pdf", "text/*"})
+	suite.repo = newFakeChatRepository()
+	suite.notifier = &recordingNotifier{}
+	idempotencyKeys, err := localmemory.NewCache(time.Minute)
+	suite.Require().NoError(err)
+	suite.T().Cleanup(func() { idempotencyKeys.Close() })
+	suite.service = NewWebSocketService(cfg, suite.repo, suite.notifier, idempotencyKeys).(*websocketService)
+	suite.T().Cleanup(func() { suite.service.Close() })
+}
+
+// seedRoom stores a room and its members in the repository only, leaving the
+// hub cache cold
+func (suite *WebSocketServiceTestSuite) seedRoom(roomID, roomType string, userIDs ...string) {
+	suite.NoError(suite.repo.CreateRoom(&domain.Room{
+		ID:        roomID,
+		Type:      roomType,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}))
+	for _, userID := range userIDs {
+		suite.NoError(suite.repo.AddUserToRoom(roomID, userID))
+	}
+}
+
+func (suite *WebSocketServiceTestSuite) cachedRoom(roomID string) (*domain.Room, bool) {
+	suite.service.mu.RLock()
+	defer suite.service.mu.RUnlock()
+	room, exists := suite.service.hub.Rooms[roomID]
+	return room, exists
+}
+
+func (suite *WebSocketServiceTestSuite) TestLeaveRoomCached() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+	_, err := suite.service.loadRoom("room-1")
+	suite.NoError(err)
+
+	suite.NoError(suite.service.LeaveRoom("room-1", "user-1"))
+
+	room, exists := suite.cachedRoom("room-1")
+	suite.True(exists)
+	suite.Equal([]string{"user-2"}, room.Users)
+
+	users, _ := suite.repo.GetRoomUsers("room-1")
+	suite.Equal([]string{"user-2"}, users)
+}
+
+func (suite *WebSocketServiceTestSuite) TestLeaveRoomUncached() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+
+	suite.NoError(suite.service.LeaveRoom("room-1", "user-2"))
+
+	room, exists := suite.cachedRoom("room-1")
+	suite.True(exists)
+	suite.Equal([]string{"user-1"}, room.Users)
+
+	users, _ := suite.repo.GetRoomUsers("room-1")
+	suite.Equal([]string{"user-1"}, users)
+}
+
+func (suite *WebSocketServiceTestSuite) TestLeaveRoomNotFound() {
+	suite.ErrorIs(suite.service.LeaveRoom("missing", "user-1"), domain.ErrRoomNotFound)
+}
+
+func (suite *WebSocketServiceTestSuite) TestLeaveRoomNotMember() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+
+	suite.ErrorIs(suite.service.LeaveRoom("room-1", "user-2"), domain.ErrUserNotInRoom)
+}
+
+func (suite *WebSocketServiceTestSuite) TestLeaveRoomClearsConnectionRoom() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+	conn := &domain.Connection{ID: "user-1", UserID: "user-1", RoomID: "room-1", Send: make(chan domain.WebSocketMessage, 1)}
+	suite.service.mu.Lock()
+	suite.service.hub.Connections["user-1"] = conn
+	suite.service.mu.Unlock()
+
+	suite.NoError(suite.service.LeaveRoom("room-1", "user-1"))
+
+	suite.service.mu.RLock()
+	defer suite.service.mu.RUnlock()
+	suite.Empty(conn.RoomID)
+}
+
+func (suite *WebSocketServiceTestSuite) TestLeaveRoomLastMemberDeletesGroup() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.NoError(suite.repo.CreateMessage(&domain.Message{ID: "message-1", RoomID: "room-1"}))
+
+	suite.NoError(suite.service.LeaveRoom("room-1", "user-1"))
+
+	_, exists := suite.cachedRoom("room-1")
+	suite.False(exists)
+
+	room, err := suite.repo.GetRoom("room-1")
+	suite.NoError(err)
+	suite.Nil(room)
+
+	message, _ := suite.repo.GetMessage("message-1")
+	suite.Nil(message)
+}
+
+func (suite *WebSocketServiceTestSuite) TestLeaveRoomLastMemberKeepsDirectRoom() {
+	suite.seedRoom("room-1", domain.RoomTypeDirect, "user-1")
+
+	suite.NoError(suite.service.LeaveRoom("room-1", "user-1"))
+
+	room, err := suite.repo.GetRoom("room-1")
+	suite.NoError(err)
+	suite.NotNil(room)
+}
+
+func (suite *WebSocketServiceTestSuite) TestDeleteRoomCascades() {
+	room, err := suite.service.CreateGroupRoom("Team", "user-1", []string{"user-1", "user-2"}, "")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.repo.CreateMessage(&domain.Message{ID: "message-1", RoomID: room.ID}))
+	suite.Require().NoError(suite.repo.CreateMessage(&domain.Message{ID: "message-2", RoomID: "room-2"}))
+	suite.Require().NoError(suite.repo.UpdateMessageStatus(&domain.MessageStatus{MessageID: "message-1", UserID: "user-2", Status: domain.MessageStatusRead}))
+	suite.Require().NoError(suite.repo.UpdateMessageStatus(&domain.MessageStatus{MessageID: "message-2", UserID: "user-2", Status: domain.MessageStatusRead}))
+	suite.Require().NoError(suite.repo.MuteRoomUser(&domain.RoomUserMute{RoomID: room.ID, UserID: "user-2", MutedUserID: "user-1"}))
+
+	member := suite.connect("user-2", 1)
+	suite.service.mu.Lock()
+	member.RoomID = room.ID
+	suite.service.mu.Unlock()
+
+	suite.NoError(suite.service.DeleteRoom(room.ID, "user-1", false))
+
+	frame := suite.expectFrame(member, domain.MessageTypeRoomDeleted)
+	suite.Equal(room.ID, frame.RoomID)
+	suite.Equal("user-1", frame.UserID)
+	suite.service.mu.RLock()
+	suite.Empty(member.RoomID)
+	suite.service.mu.RUnlock()
+
+	_, exists := suite.cachedRoom(room.ID)
+	suite.False(exists)
+	stored, _ := suite.repo.GetRoom(room.ID)
+	suite.Nil(stored)
+	users, _ := suite.repo.GetRoomUsers(room.ID)
+	suite.Empty(users)
+	message, _ := suite.repo.GetMessage("message-1")
+	suite.Nil(message)
+	status, _ := suite.repo.GetMessageStatus("message-1", "user-2")
+	suite.Nil(status)
+	muters, _ := suite.repo.GetRoomUserMuters(room.ID, "user-1")
+	suite.Empty(muters)
+
+	// Other rooms are untouched
+	message, _ = suite.repo.GetMessage("message-2")
+	suite.NotNil(message)
+	status, _ = suite.repo.GetMessageStatus("message-2", "user-2")
+	suite.NotNil(status)
+}
+
+func (suite *WebSocketServiceTestSuite) TestDeleteRoomAuthorization() {
+	room, err := suite.service.CreateGroupRoom("Team", "user-1", []string{"user-1", "user-2"}, "")
+	suite.Require().NoError(err)
+
+	// Members who did not create the room cannot delete it
+	suite.ErrorIs(suite.service.DeleteRoom(room.ID, "user-2", false), domain.ErrNotRoomCreator)
+	stored, _ := suite.repo.GetRoom(room.ID)
+	suite.NotNil(stored)
+
+	// Employers can delete any room
+	suite.NoError(suite.service.DeleteRoom(room.ID, "employer-1", true))
+	stored, _ = suite.repo.GetRoom(room.ID)
+	suite.Nil(stored)
+
+	suite.ErrorIs(suite.service.DeleteRoom(room.ID, "user-1", false), domain.ErrRoomNotFound)
+}
+
+func (suite *WebSocketServiceTestSuite) TestDeleteRoomWithoutCreator() {
+	// Rooms from before creators were recorded can only be deleted by employers
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+
+	suite.ErrorIs(suite.service.DeleteRoom("room-1", "", false), domain.ErrNotRoomCreator)
+	suite.NoError(suite.service.DeleteRoom("room-1", "employer-1", true))
+}
+
+func (suite *WebSocketServiceTestSuite) TestAddAndRemoveMembers() {
+	room, err := suite.service.CreateGroupRoom("Team", "user-1", []string{"user-1", "user-2"}, "")
+	suite.Require().NoError(err)
+	creator := suite.connect("user-1", 4)
+	added := suite.connect("user-3", 4)
+
+	suite.NoError(suite.service.AddMember(room.ID, "user-1", "user-3", false))
+	for _, conn := range []*domain.Connection{creator, added} {
+		frame := suite.expectFrame(conn, domain.MessageTypeMemberJoined)
+		suite.Equal(room.ID, frame.RoomID)
+		suite.Equal("user-3", frame.UserID)
+	}
+	members, err := suite.service.ListRoomMembers(room.ID, "user-1")
+	suite.NoError(err)
+	suite.Equal([]string{"user-1", "user-2", "user-3"}, members)
+	stored, _ := suite.repo.GetRoomUsers(room.ID)
+	suite.Equal([]string{"user-1", "user-2", "user-3"}, stored)
+
+	suite.ErrorIs(suite.service.AddMember(room.ID, "user-1", "user-3", false), domain.ErrUserAlreadyInRoom)
+
+	// Employers manage members of rooms they did not create
+	suite.NoError(suite.service.RemoveMember(room.ID, "employer-1", "user-3", true))
+	frame := suite.expectFrame(creator, domain.MessageTypeMemberLeft)
+	suite.Equal("user-3", frame.UserID)
+	suite.expectFrame(added, domain.MessageTypeMemberLeft)
+	suite.expectNoFrame(added)
+	members, err = suite.service.ListRoomMembers(room.ID, "user-1")
+	suite.NoError(err)
+	suite.Equal([]string{"user-1", "user-2"}, members)
+	stored, _ = suite.repo.GetRoomUsers(room.ID)
+	suite.Equal([]string{"user-1", "user-2"}, stored)
+
+	suite.ErrorIs(suite.service.RemoveMember(room.ID, "user-1", "user-3", false), domain.ErrUserNotInRoom)
+}
+
+func (suite *WebSocketServiceTestSuite) TestMemberChangesRequireAdminOfGroup() {
+	room, err := suite.service.CreateGroupRoom("Team", "user-1", []string{"user-1", "user-2"}, "")
+	suite.Require().NoError(err)
+
+	suite.ErrorIs(suite.service.AddMember(room.ID, "user-2", "user-3", false), domain.ErrNotRoomAdmin)
+	suite.ErrorIs(suite.service.RemoveMember(room.ID, "user-2", "user-1", false), domain.ErrNotRoomAdmin)
+	members, _ := suite.service.ListRoomMembers(room.ID, "user-1")
+	suite.Equal([]string{"user-1", "user-2"}, members)
+
+	direct, err := suite.service.CreateDirectRoom("user-1", "user-2")
+	suite.Require().NoError(err)
+	suite.ErrorIs(suite.service.AddMember(direct.ID, "user-1", "user-3", true), domain.ErrInvalidRoomType)
+	suite.ErrorIs(suite.service.RemoveMember(direct.ID, "user-1", "user-2", true), domain.ErrInvalidRoomType)
+
+	suite.ErrorIs(suite.service.AddMember("missing", "user-1", "user-3", true), domain.ErrRoomNotFound)
+	_, err = suite.service.ListRoomMembers("missing", "user-1")
+	suite.ErrorIs(err, domain.ErrRoomNotFound)
+}
+
+func (suite *WebSocketServiceTestSuite) TestListRoomMembersRequiresMembership() {
+	room, err := suite.service.CreateGroupRoom("Team", "user-1", []string{"user-1", "user-2"}, "")
+	suite.Require().NoError(err)
+
+	_, err = suite.service.ListRoomMembers(room.ID, "user-3")
+	suite.ErrorIs(err, domain.ErrUserNotInRoom)
+}
+
+func (suite *WebSocketServiceTestSuite) TestPlainMemberCannotManageGroup() {
+	room, err := suite.service.CreateGroupRoom("Team", "user-1", []string{"user-2", "user-3"}, "")
+	suite.Require().NoError(err)
+	suite.Equal([]string{"user-1", "user-2", "user-3"}, room.Users)
+	admins, _ := suite.repo.GetRoomAdmins(room.ID)
+	suite.Equal([]string{"user-1"}, admins)
+
+	suite.ErrorIs(suite.service.UpdateRoomInfo(room.ID, "user-2", "Renamed", "", "", false), domain.ErrNotRoomAdmin)
+	suite.ErrorIs(suite.service.RemoveMember(room.ID, "user-2", "user-3", false), domain.ErrNotRoomAdmin)
+	suite.ErrorIs(suite.service.PromoteToAdmin(room.ID, "user-2", "user-2", false), domain.ErrNotRoomAdmin)
+
+	stored, _ := suite.repo.GetRoom(room.ID)
+	suite.Equal("Team", stored.Name)
+	members, _ := suite.service.ListRoomMembers(room.ID, "user-1")
+	suite.Equal([]string{"user-1", "user-2", "user-3"}, members)
+
+	// Admins and employers can
+	suite.NoError(suite.service.UpdateRoomInfo(room.ID, "user-1", "Renamed", "", "", false))
+	suite.NoError(suite.service.UpdateRoomInfo(room.ID, "employer-1", "Renamed again", "", "", true))
+	stored, _ = suite.repo.GetRoom(room.ID)
+	suite.Equal("Renamed again", stored.Name)
+}
+
+func (suite *WebSocketServiceTestSuite) TestPromoteAndDemoteAdmins() {
+	room, err := suite.service.CreateGroupRoom("Team", "user-1", []string{"user-2", "user-3"}, "")
+	suite.Require().NoError(err)
+
+	suite.NoError(suite.service.PromoteToAdmin(room.ID, "user-1", "user-2", false))
+	// The new admin manages the room, and promoting twice changes nothing
+	suite.NoError(suite.service.PromoteToAdmin(room.ID, "user-2", "user-2", false))
+	suite.NoError(suite.service.UpdateRoomInfo(room.ID, "user-2", "Renamed", "", "", false))
+	suite.NoError(suite.service.RemoveMember(room.ID, "user-2", "user-3", false))
+	admins, _ := suite.repo.GetRoomAdmins(room.ID)
+	suite.ElementsMatch([]string{"user-1", "user-2"}, admins)
+
+	suite.NoError(suite.service.DemoteMember(room.ID, "user-2", "user-1", false))
+	suite.ErrorIs(suite.service.AddMember(room.ID, "user-1", "user-4", false), domain.ErrNotRoomAdmin)
+	suite.ErrorIs(suite.service.DemoteMember(room.ID, "user-2", "user-2", false), domain.ErrLastRoomAdmin)
+	suite.ErrorIs(suite.service.PromoteToAdmin(room.ID, "user-2", "user-3", false), domain.ErrUserNotInRoom)
+
+	// Roles survive the room being reloaded from the repository
+	suite.service.mu.Lock()
+	delete(suite.service.hub.Rooms, room.ID)
+	suite.service.mu.Unlock()
+	suite.NoError(suite.service.AddMember(room.ID, "user-2", "user-4", false))
+	suite.ErrorIs(suite.service.AddMember(room.ID, "user-1", "user-5", false), domain.ErrNotRoomAdmin)
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetRoomDetails() {
+	room, err := suite.service.CreateGroupRoom("Team", "user-1", []string{"user-2"}, "")
+	suite.Require().NoError(err)
+	start := time.Now().Add(-time.Hour)
+	for i, sender := range []string{"user-1", "user-1", "user-2"} {
+		suite.Require().NoError(suite.repo.CreateMessage(&domain.Message{
+			ID:        fmt.Sprintf("message-%d", i),
+			RoomID:    room.ID,
+			UserID:    sender,
+			Type:      domain.MessageTypeText,
+			CreatedAt: start.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+	suite.Require().NoError(suite.service.PinMessage(room.ID, "user-1", "message-0"))
+
+	details, err := suite.service.GetRoomDetails(room.ID, "user-2")
+	suite.Require().NoError(err)
+	suite.Equal("Team", details.Name)
+	suite.Equal([]string{"user-1", "user-2"}, details.Users)
+	suite.Equal([]string{"user-1"}, details.Admins)
+	suite.Require().NotNil(details.LastMessage)
+	suite.Equal("message-2", details.LastMessage.ID)
+	suite.Equal([]string{"message-0"}, details.PinnedMessages)
+	suite.Equal(map[string]int{"user-2": 2}, details.UnreadCount)
+
+	_, err = suite.service.GetRoomDetails(room.ID, "user-3")
+	suite.ErrorIs(err, domain.ErrUserNotInRoom)
+	_, err = suite.service.GetRoomDetails("missing", "user-1")
+	suite.ErrorIs(err, domain.ErrRoomNotFound)
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetPinnedMessages() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+	suite.seedRoom("room-2", domain.RoomTypeGroup, "user-1")
+	suite.seedMessages("room-1", 3)
+	suite.Require().NoError(suite.repo.CreateMessage(&domain.Message{ID: "other-room", RoomID: "room-2"}))
+	for _, id := range []string{"message-02", "message-00", "message-01"} {
+		suite.Require().NoError(suite.service.PinMessage("room-1", "user-1", id))
+	}
+	// A pin of another room's message stored before pins were checked
+	room, _ := suite.repo.GetRoom("room-1")
+	room.PinnedMessages = append(room.PinnedMessages, "other-room")
+	suite.Require().NoError(suite.repo.UpdateRoom(room))
+	suite.Require().NoError(suite.repo.DeleteMessage("message-01"))
+
+	// Pin order is kept, and deleted messages and other rooms' are left out
+	messages, err := suite.service.GetPinnedMessages("room-1", "user-2")
+	suite.Require().NoError(err)
+	suite.Require().Len(messages, 2)
+	suite.Equal("message-02", messages[0].ID)
+	suite.Equal("message-00", messages[1].ID)
+	suite.Equal(domain.MessageTypeText, messages[0].Type)
+
+	_, err = suite.service.GetPinnedMessages("room-2", "user-2")
+	suite.ErrorIs(err, domain.ErrUserNotInRoom)
+	_, err = suite.service.GetPinnedMessages("missing", "user-1")
+	suite.ErrorIs(err, domain.ErrRoomNotFound)
+}
+
+func (suite *WebSocketServiceTestSuite) TestPinMessageLimit() {
+	suite.service.maxPinnedMessages = 2
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.seedMessages("room-1", 3)
+
+	suite.NoError(suite.service.PinMessage("room-1", "user-1", "message-00"))
+	suite.NoError(suite.service.PinMessage("room-1", "user-1", "message-01"))
+	suite.ErrorIs(suite.service.PinMessage("room-1", "user-1", "message-02"), domain.ErrPinLimitReached)
+	// Pinning an already pinned message is still fine at the limit
+	suite.NoError(suite.service.PinMessage("room-1", "user-1", "message-01"))
+
+	// Unpinning makes room for another
+	suite.NoError(suite.service.UnpinMessage("room-1", "user-1", "message-00"))
+	suite.NoError(suite.service.PinMessage("room-1", "user-1", "message-02"))
+	room, _ := suite.repo.GetRoom("room-1")
+	suite.Equal([]string{"message-01", "message-02"}, room.PinnedMessages)
+}
+
+func (suite *WebSocketServiceTestSuite) TestPinMessageChecks() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.seedRoom("room-2", domain.RoomTypeGroup, "user-1")
+	suite.seedMessages("room-1", 1)
+	suite.Require().NoError(suite.repo.CreateMessage(&domain.Message{ID: "other-room", RoomID: "room-2"}))
+
+	suite.ErrorIs(suite.service.PinMessage("room-1", "user-2", "message-00"), domain.ErrUserNotInRoom)
+	suite.ErrorIs(suite.service.UnpinMessage("room-1", "user-2", "message-00"), domain.ErrUserNotInRoom)
+	suite.ErrorIs(suite.service.PinMessage("room-1", "user-1", "other-room"), domain.ErrMessageNotFound)
+	suite.ErrorIs(suite.service.PinMessage("room-1", "user-1", "missing"), domain.ErrMessageNotFound)
+	room, _ := suite.repo.GetRoom("room-1")
+	suite.Empty(room.PinnedMessages)
+}
+
+func (suite *WebSocketServiceTestSuite) TestConcurrentPinsStayWithinLimit() {
+	suite.service.maxPinnedMessages = 3
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.seedMessages("room-1", 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			suite.service.PinMessage("room-1", "user-1", id)
+		}(fmt.Sprintf("message-%02d", i))
+	}
+	wg.Wait()
+
+	room, _ := suite.repo.GetRoom("room-1")
+	suite.Len(room.PinnedMessages, 3)
+}
+
+// connect registers a connection with the given send buffer directly in the hub
+func (suite *WebSocketServiceTestSuite) connect(userID string, buffer int) *domain.Connection {
+	return suite.connectAs(userID, "employee", buffer)
+}
+
+// connectAs registers a fake connection for a user with the given role
+func (suite *WebSocketServiceTestSuite) connectAs(userID, role string, buffer int) *domain.Connection {
+	conn := &domain.Connection{
+		ID:     userID,
+		UserID: userID,
+		Role:   role,
+		Send:   make(chan domain.WebSocketMessage, buffer),
+		Hub:    suite.service.hub,
+	}
+	suite.service.hub.Register <- conn
+	suite.Eventually(func() bool {
+		return suite.isConnected(conn)
+	}, time.Second, 10*time.Millisecond)
+	return conn
+}
+
+func (suite *WebSocketServiceTestSuite) isConnected(conn *domain.Connection) bool {
+	suite.service.mu.RLock()
+	defer suite.service.mu.RUnlock()
+	return suite.service.hub.Connections[conn.UserID] == conn
+}
+
+// dial serves HandleConnection over a test server and returns the client
+// side along with the hub's connection for userID
+func (suite *WebSocketServiceTestSuite) dial(userID string) (*websocket.Conn, *domain.Connection) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		suite.service.HandleConnection(conn, userID, "employee")
+	}))
+	suite.T().Cleanup(server.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	suite.Require().NoError(err)
+	suite.T().Cleanup(func() { client.Close() })
+
+	var conn *domain.Connection
+	suite.Eventually(func() bool {
+		suite.service.mu.RLock()
+		defer suite.service.mu.RUnlock()
+		conn = suite.service.hub.Connections[userID]
+		return conn != nil
+	}, time.Second, 10*time.Millisecond)
+	return client, conn
+}
+
+func (suite *WebSocketServiceTestSuite) TestLastPongAdvancesOnPong() {
+	client, conn := suite.dial("user-1")
+	connectedAt := conn.LastPong()
+	suite.False(connectedAt.IsZero())
+
+	time.Sleep(10 * time.Millisecond)
+	suite.NoError(client.WriteControl(websocket.PongMessage, nil, time.Now().Add(time.Second)))
+
+	suite.Eventually(func() bool {
+		return conn.LastPong().After(connectedAt)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (suite *WebSocketServiceTestSuite) TestHeartbeatStats() {
+	suite.Equal(domain.HeartbeatStats{}, suite.service.HeartbeatStats())
+
+	now := time.Now()
+	suite.connect("user-1", 1).MarkPong(now.Add(-10 * time.Second))
+	suite.connect("user-2", 1).MarkPong(now.Add(-30 * time.Second))
+
+	stats := suite.service.HeartbeatStats()
+	suite.Equal(2, stats.Connections)
+	suite.InDelta(10*time.Second, stats.MinSinceLastPong, float64(time.Second))
+	suite.InDelta(30*time.Second, stats.MaxSinceLastPong, float64(time.Second))
+	suite.InDelta(20*time.Second, stats.AvgSinceLastPong, float64(time.Second))
+}
+
+func (suite *WebSocketServiceTestSuite) TestBroadcastReapsFullConnection() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+	_, err := suite.service.loadRoom("room-1")
+	suite.NoError(err)
+
+	stalled := suite.connect("user-1", 0)
+	healthy := suite.connect("user-2", 1)
+
+	suite.service.hub.Broadcast <- domain.WebSocketMessage{Type: domain.MessageTypeText, RoomID: "room-1"}
+
+	suite.Eventually(func() bool {
+		return !suite.isConnected(stalled)
+	}, time.Second, 10*time.Millisecond)
+	suite.True(suite.isConnected(healthy))
+	suite.Len(healthy.Send, 1)
+}
+
+func (suite *WebSocketServiceTestSuite) TestDirectMessageReapsClosedConnection() {
+	conn := suite.connect("user-1", 1)
+	conn.Close()
+
+	suite.service.hub.DirectMessage <- domain.WebSocketMessage{Type: domain.MessageTypeText, TargetID: "user-1"}
+
+	suite.Eventually(func() bool {
+		return !suite.isConnected(conn)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (suite *WebSocketServiceTestSuite) TestUnregisterKeepsNewerConnection() {
+	stale := suite.connect("user-1", 1)
+	current := suite.connect("user-1", 1)
+
+	suite.service.hub.Unregister <- stale
+	suite.service.hub.Register <- &domain.Connection{ID: "user-2", UserID: "user-2", Send: make(chan domain.WebSocketMessage)}
+
+	suite.Eventually(func() bool {
+		suite.service.mu.RLock()
+		defer suite.service.mu.RUnlock()
+		_, exists := suite.service.hub.Connections["user-2"]
+		return exists
+	}, time.Second, 10*time.Millisecond)
+	suite.True(suite.isConnected(current))
+}
+
+func (suite *WebSocketServiceTestSuite) TestListRoomsPaging() {
+	now := time.Now()
+	for i := 0; i < 25; i++ {
+		roomID := fmt.Sprintf("room-%02d", i)
+		suite.NoError(suite.repo.CreateRoom(&domain.Room{
+			ID:        roomID,
+			Type:      domain.RoomTypeGroup,
+			UpdatedAt: now.Add(time.Duration(i) * time.Minute),
+		}))
+		suite.NoError(suite.repo.AddUserToRoom(roomID, "user-1"))
+	}
+
+	first, err := suite.service.ListRooms("user-1", repositories.RoomFilter{Limit: 10})
+	suite.NoError(err)
+	suite.Len(first, 10)
+	suite.Equal("room-24", first[0].ID)
+
+	second, err := suite.service.ListRooms("user-1", repositories.RoomFilter{Limit: 10, Offset: 10})
+	suite.NoError(err)
+	suite.Len(second, 10)
+	suite.Equal("room-14", second[0].ID)
+
+	last, err := suite.service.ListRooms("user-1", repositories.RoomFilter{Limit: 10, Offset: 20})
+	suite.NoError(err)
+	suite.Len(last, 5)
+	suite.Equal("room-00", last[4].ID)
+
+	// Oversized and missing limits fall back to the shared pagination settings
+	capped, err := suite.service.ListRooms("user-1", repositories.RoomFilter{Limit: 1000})
+	suite.NoError(err)
+	suite.Len(capped, 10)
+
+	defaulted, err := suite.service.ListRooms("user-1", repositories.RoomFilter{})
+	suite.NoError(err)
+	suite.Len(defaulted, 10)
+}
+
+func (suite *WebSocketServiceTestSuite) TestListRoomsFilters() {
+	suite.NoError(suite.repo.CreateRoom(&domain.Room{ID: "room-1", Name: "Design Team", Type: domain.RoomTypeGroup}))
+	suite.NoError(suite.repo.CreateRoom(&domain.Room{ID: "room-2", Name: "Ops", Type: domain.RoomTypeGroup, IsArchived: true}))
+	suite.NoError(suite.repo.CreateRoom(&domain.Room{ID: "room-3", Type: domain.RoomTypeDirect}))
+	for _, roomID := range []string{"room-1", "room-2", "room-3"} {
+		suite.NoError(suite.repo.AddUserToRoom(roomID, "user-1"))
+	}
+
+	archived := false
+	rooms, err := suite.service.ListRooms("user-1", repositories.RoomFilter{
+		Type:       domain.RoomTypeGroup,
+		IsArchived: &archived,
+		Search:     "  design ",
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(rooms, 1)
+	suite.Equal("room-1", rooms[0].ID)
+
+	_, err = suite.service.ListRooms("user-1", repositories.RoomFilter{Type: "channel"})
+	suite.ErrorIs(err, domain.ErrInvalidRoomType)
+}
+
+func (suite *WebSocketServiceTestSuite) TestOfflineUserIsNotified() {
+	suite.service.mu.Lock()
+	suite.service.lastSeen["user-1"] = time.Now().Add(-2 * time.Minute)
+	suite.service.mu.Unlock()
+
+	suite.NoError(suite.service.SendMentionNotification("user-1", "user-2", "hello @user-1"))
+	suite.NoError(suite.service.SendTaskUpdateNotification("user-1", "task-1", "Write docs", "completed"))
+
+	suite.Equal([]string{"user-1", "user-1"}, suite.notifier.notified())
+}
+
+func (suite *WebSocketServiceTestSuite) TestUserNotSeenSinceStartupIsNotYetNotified() {
+	suite.NoError(suite.service.SendMentionNotification("user-1", "user-2", "hello @user-1"))
+	suite.Empty(suite.notifier.notified())
+
+	// Once the threshold has passed since startup the fallback kicks in
+	suite.service.mu.Lock()
+	suite.service.startedAt = time.Now().Add(-2 * time.Minute)
+	suite.service.mu.Unlock()
+
+	suite.NoError(suite.service.SendMentionNotification("user-1", "user-2", "hello @user-1"))
+	suite.Equal([]string{"user-1"}, suite.notifier.notified())
+}
+
+func (suite *WebSocketServiceTestSuite) TestOnlineUserIsNotNotified() {
+	suite.connect("user-1", 8)
+
+	suite.NoError(suite.service.SendMentionNotification("user-1", "user-2", "hello @user-1"))
+
+	suite.Empty(suite.notifier.notified())
+}
+
+func (suite *WebSocketServiceTestSuite) TestRecentlyDisconnectedUserIsNotNotified() {
+	conn := suite.connect("user-1", 8)
+	suite.service.hub.Unregister <- conn
+	suite.Eventually(func() bool {
+		return !suite.isConnected(conn)
+	}, time.Second, 10*time.Millisecond)
+
+	suite.NoError(suite.service.SendMentionNotification("user-1", "user-2", "hello @user-1"))
+	suite.Empty(suite.notifier.notified())
+
+	// Once the threshold has passed the fallback kicks in
+	suite.service.mu.Lock()
+	suite.service.lastSeen["user-1"] = time.Now().Add(-2 * time.Minute)
+	suite.service.mu.Unlock()
+
+	suite.NoError(suite.service.SendMentionNotification("user-1", "user-2", "hello @user-1"))
+	suite.Equal([]string{"user-1"}, suite.notifier.notified())
+}
+
+func (suite *WebSocketServiceTestSuite) TestNotificationFansOutToRegisteredNotifiers() {
+	email := &recordingNotifier{}
+	webhook := &recordingNotifier{}
+	suite.service.RegisterNotifier(domain.NotificationChannelEmail, email)
+	suite.service.RegisterNotifier(domain.NotificationChannelWebhook, webhook)
+	conn := suite.connect("user-1", 8)
+
+	suite.NoError(suite.service.SendSystemNotification("user-1", "Maintenance", "Back in 5 minutes"))
+
+	suite.Equal([]string{"user-1"}, email.notified())
+	suite.Equal([]string{"user-1"}, webhook.notified())
+	suite.Eventually(func() bool {
+		return len(conn.Send) == 1
+	}, time.Second, 10*time.Millisecond)
+	message := <-conn.Send
+	suite.Equal(domain.MessageTypeSystem, message.Type)
+	suite.Equal("Back in 5 minutes", message.Content)
+}
+
+func (suite *WebSocketServiceTestSuite) TestNotificationRespectsPreferences() {
+	email := &recordingNotifier{}
+	webhook := &recordingNotifier{}
+	suite.service.RegisterNotifier(domain.NotificationChannelEmail, email)
+	suite.service.RegisterNotifier(domain.NotificationChannelWebhook, webhook)
+	suite.NoError(suite.repo.SaveNotificationPreference(&domain.NotificationPreference{
+		UserID:  "user-1",
+		Channel: domain.NotificationChannelWebhook,
+		Enabled: false,
+	}))
+	suite.NoError(suite.repo.SaveNotificationPreference(&domain.NotificationPreference{
+		UserID:  "user-1",
+		Channel: domain.NotificationChannelEmail,
+		Type:    domain.NotificationTypeSystem,
+		Enabled: false,
+	}))
+
+	suite.NoError(suite.service.SendSystemNotification("user-1", "Maintenance", "Back in 5 minutes"))
+	suite.NoError(suite.service.SendTaskUpdateNotification("user-1", "task-1", "Write docs", "completed"))
+
+	suite.Equal([]string{"user-1"}, email.notified())
+	suite.Empty(webhook.notified())
+}
+
+func (suite *WebSocketServiceTestSuite) TestSetNotificationPreference() {
+	email := &recordingNotifier{}
+	suite.service.RegisterNotifier(domain.NotificationChannelEmail, email)
+
+	_, err := suite.service.SetNotificationPreference("user-1", domain.NotificationChannelEmail, domain.NotificationTypeSystem, false)
+	suite.NoError(err)
+	suite.NoError(suite.service.SendSystemNotification("user-1", "Maintenance", "Back in 5 minutes"))
+	suite.Empty(email.notified())
+
+	// Setting the same channel and type again updates the stored preference
+	preference, err := suite.service.SetNotificationPreference("user-1", domain.NotificationChannelEmail, domain.NotificationTypeSystem, true)
+	suite.NoError(err)
+	suite.True(preference.Enabled)
+	preferences, err := suite.service.GetNotificationPreferences("user-1")
+	suite.NoError(err)
+	suite.Len(preferences, 1)
+	suite.NoError(suite.service.SendSystemNotification("user-1", "Maintenance", "Back in 5 minutes"))
+	suite.Equal([]string{"user-1"}, email.notified())
+
+	_, err = suite.service.SetNotificationPreference("user-1", "carrier-pigeon", "", false)
+	suite.ErrorIs(err, domain.ErrInvalidPreference)
+	// Webhook preferences need a webhook notifier
+	_, err = suite.service.SetNotificationPreference("user-1", domain.NotificationChannelWebhook, "", false)
+	suite.ErrorIs(err, domain.ErrInvalidPreference)
+	suite.service.RegisterNotifier(domain.NotificationChannelWebhook, &recordingNotifier{})
+	_, err = suite.service.SetNotificationPreference("user-1", domain.NotificationChannelWebhook, "", false)
+	suite.NoError(err)
+	_, err = suite.service.SetNotificationPreference("user-1", domain.NotificationChannelEmail, "gossip", false)
+	suite.ErrorIs(err, domain.ErrInvalidPreference)
+}
+
+// seedMessages stores count messages one minute apart, message-00 being the oldest
+func (suite *WebSocketServiceTestSuite) seedMessages(roomID string, count int) {
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < count; i++ {
+		suite.NoError(suite.repo.CreateMessage(&domain.Message{
+			ID:        fmt.Sprintf("message-%02d", i),
+			RoomID:    roomID,
+			Type:      domain.MessageTypeText,
+			CreatedAt: start.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+}
+
+func messageIDs(messages []domain.WebSocketMessage) []string {
+	ids := make([]string, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+	return ids
+}
+
+func (suite *WebSocketServiceTestSuite) TestReadReceiptsWithTwoReaders() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2", "user-3")
+	suite.seedMessages("room-1", 2)
+	_, err := suite.service.loadRoom("room-1")
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(suite.service.MarkMessageAsRead("room-1", "user-2", "message-01"))
+	suite.Require().NoError(suite.service.MarkMessageAsRead("room-1", "user-3", "message-01"))
+
+	receipts, err := suite.service.GetReadReceipts("room-1", "user-1", "message-01")
+	suite.NoError(err)
+	suite.Require().Len(receipts, 2)
+	suite.Equal("user-2", receipts[0].UserID)
+	suite.Equal("user-3", receipts[1].UserID)
+	suite.False(receipts[0].ReadAt.IsZero())
+
+	queries := suite.repo.receiptQueries
+	history, err := suite.service.GetRoomHistory("room-1", "user-1", 10, 0, domain.HistoryOrderNewestFirst)
+	suite.NoError(err)
+	suite.Require().Equal([]string{"message-01", "message-00"}, messageIDs(history))
+	suite.Equal(receipts, history[0].ReadBy)
+	suite.Empty(history[1].ReadBy)
+	// The whole page's receipts come from one query
+	suite.Equal(queries+1, suite.repo.receiptQueries)
+
+	history, _, err = suite.service.GetRoomHistoryBefore("room-1", "user-1", "", 10)
+	suite.NoError(err)
+	suite.Equal(receipts, history[0].ReadBy)
+}
+
+func (suite *WebSocketServiceTestSuite) TestDirectRoomHistoryHasNoReadBy() {
+	suite.seedRoom("room-1", domain.RoomTypeDirect, "user-1", "user-2")
+	suite.seedMessages("room-1", 1)
+	_, err := suite.service.loadRoom("room-1")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.service.MarkMessageAsRead("room-1", "user-2", "message-00"))
+
+	history, err := suite.service.GetRoomHistory("room-1", "user-1", 10, 0, domain.HistoryOrderNewestFirst)
+	suite.NoError(err)
+	suite.Require().Len(history, 1)
+	suite.Nil(history[0].ReadBy)
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetReadReceiptsForMessageInAnotherRoom() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.seedMessages("room-2", 1)
+
+	_, err := suite.service.GetReadReceipts("room-1", "user-1", "message-00")
+	suite.ErrorIs(err, domain.ErrMessageNotFound)
+	_, err = suite.service.GetReadReceipts("room-1", "user-1", "missing")
+	suite.ErrorIs(err, domain.ErrMessageNotFound)
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetReadReceiptsRequiresMembership() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.seedMessages("room-1", 1)
+
+	_, err := suite.service.GetReadReceipts("room-1", "user-2", "message-00")
+	suite.ErrorIs(err, domain.ErrUserNotInRoom)
+}
+
+func (suite *WebSocketServiceTestSuite) TestSearchAllMessages() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.seedRoom("room-2", domain.RoomTypeGroup, "user-2")
+	suite.Require().NoError(suite.repo.CreateMessage(&domain.Message{ID: "message-1", RoomID: "room-1", Content: "release notes"}))
+	suite.Require().NoError(suite.repo.CreateMessage(&domain.Message{ID: "message-2", RoomID: "room-2", Content: "release day"}))
+
+	results, err := suite.service.SearchAllMessages("user-1", "  Release ", 0, 0)
+	suite.NoError(err)
+	suite.Require().Len(results, 1)
+	suite.Equal("message-1", results[0].ID)
+
+	_, err = suite.service.SearchAllMessages("user-1", "   ", 0, 0)
+	suite.ErrorIs(err, domain.ErrEmptySearchQuery)
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetRoomHistoryBeforeFirstPage() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.seedMessages("room-1", 5)
+
+	messages, hasMore, err := suite.service.GetRoomHistoryBefore("room-1", "user-1", "", 2)
+	suite.NoError(err)
+	suite.True(hasMore)
+	suite.Equal([]string{"message-04", "message-03"}, messageIDs(messages))
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetRoomHistoryBeforeMiddlePage() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.seedMessages("room-1", 5)
+
+	messages, hasMore, err := suite.service.GetRoomHistoryBefore("room-1", "user-1", "message-03", 2)
+	suite.NoError(err)
+	suite.True(hasMore)
+	suite.Equal([]string{"message-02", "message-01"}, messageIDs(messages))
+
+	messages, hasMore, err = suite.service.GetRoomHistoryBefore("room-1", "user-1", "message-01", 2)
+	suite.NoError(err)
+	suite.False(hasMore)
+	suite.Equal([]string{"message-00"}, messageIDs(messages))
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetRoomHistoryBeforeEmptyTail() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.seedMessages("room-1", 5)
+
+	messages, hasMore, err := suite.service.GetRoomHistoryBefore("room-1", "user-1", "message-00", 2)
+	suite.NoError(err)
+	suite.False(hasMore)
+	suite.Empty(messages)
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetRoomHistoryBeforeKeepsMessagesSharingATimestamp() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	sent := time.Now().Add(-time.Minute)
+	for _, id := range []string{"message-a", "message-b", "message-c", "message-d"} {
+		suite.Require().NoError(suite.repo.CreateMessage(&domain.Message{ID: id, RoomID: "room-1", CreatedAt: sent}))
+	}
+
+	messages, hasMore, err := suite.service.GetRoomHistoryBefore("room-1", "user-1", "", 2)
+	suite.NoError(err)
+	suite.True(hasMore)
+	suite.Equal([]string{"message-d", "message-c"}, messageIDs(messages))
+
+	messages, hasMore, err = suite.service.GetRoomHistoryBefore("room-1", "user-1", "message-c", 2)
+	suite.NoError(err)
+	suite.False(hasMore)
+	suite.Equal([]string{"message-b", "message-a"}, messageIDs(messages))
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetRoomHistoryBeforeRequiresMembership() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.seedMessages("room-1", 2)
+
+	_, _, err := suite.service.GetRoomHistoryBefore("room-1", "user-2", "", 2)
+	suite.ErrorIs(err, domain.ErrUserNotInRoom)
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetRoomHistoryRequiresMembership() {
+	// The room isn't in the hub yet and is loaded from the repository
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.seedMessages("room-1", 2)
+
+	messages, err := suite.service.GetRoomHistory("room-1", "user-1", 10, 0, domain.HistoryOrderNewestFirst)
+	suite.NoError(err)
+	suite.Len(messages, 2)
+
+	_, err = suite.service.GetRoomHistory("room-1", "user-2", 10, 0, domain.HistoryOrderNewestFirst)
+	suite.ErrorIs(err, domain.ErrUserNotInRoom)
+	_, err = suite.service.GetRoomHistory("missing", "user-1", 10, 0, domain.HistoryOrderNewestFirst)
+	suite.ErrorIs(err, domain.ErrRoomNotFound)
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetRoomHistoryBeforeUnknownAnchor() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+
+	_, _, err := suite.service.GetRoomHistoryBefore("room-1", "user-1", "missing", 2)
+	suite.ErrorIs(err, domain.ErrMessageNotFound)
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetDirectRoomIDIgnoresArgumentOrder() {
+	roomID, exists, err := suite.service.GetDirectRoomID("user-1", "user-2")
+	suite.NoError(err)
+	suite.False(exists)
+
+	swapped, exists, err := suite.service.GetDirectRoomID("user-2", "user-1")
+	suite.NoError(err)
+	suite.False(exists)
+	suite.Equal(roomID, swapped)
+
+	// Looking the ID up does not create the room
+	room, err := suite.repo.GetRoom(roomID)
+	suite.NoError(err)
+	suite.Nil(room)
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetDirectRoomIDMatchesRoomCreatedByFirstMessage() {
+	message, err := suite.service.SendDirectMessage("user-2", "user-1", "hello")
+	suite.Require().NoError(err)
+
+	roomID, exists, err := suite.service.GetDirectRoomID("user-1", "user-2")
+	suite.NoError(err)
+	suite.True(exists)
+	suite.Equal(message.RoomID, roomID)
+}
+
+func (suite *WebSocketServiceTestSuite) TestCreateDirectRoomIsIdempotent() {
+	room, err := suite.service.CreateDirectRoom("user-1", "user-2")
+	suite.Require().NoError(err)
+	suite.Equal(domain.RoomTypeDirect, room.Type)
+	suite.Equal("user-1", room.CreatedBy)
+	suite.ElementsMatch([]string{"user-1", "user-2"}, room.Users)
+	members, err := suite.repo.GetRoomUsers(room.ID)
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"user-1", "user-2"}, members)
+
+	// Either user gets the same room back, as does a later message
+	again, err := suite.service.CreateDirectRoom("user-2", "user-1")
+	suite.Require().NoError(err)
+	suite.Equal(room.ID, again.ID)
+	suite.Equal("user-1", again.CreatedBy)
+	message, err := suite.service.SendDirectMessage("user-2", "user-1", "hello")
+	suite.Require().NoError(err)
+	suite.Equal(room.ID, message.RoomID)
+
+	roomID, exists, err := suite.service.GetDirectRoomID("user-1", "user-2")
+	suite.NoError(err)
+	suite.True(exists)
+	suite.Equal(room.ID, roomID)
+	suite.Equal(1, suite.countRooms())
+}
+
+func (suite *WebSocketServiceTestSuite) TestDirectRoomWithSelfIsRejected() {
+	_, err := suite.service.CreateDirectRoom("user-1", "user-1")
+	suite.ErrorIs(err, domain.ErrSelfDirectRoom)
+	_, err = suite.service.SendDirectMessage("user-1", "user-1", "note to self")
+	suite.ErrorIs(err, domain.ErrSelfDirectRoom)
+	suite.Zero(suite.countRooms())
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetRoomHistoryOrder() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.seedMessages("room-1", 5)
+	_, err := suite.service.loadRoom("room-1")
+	suite.NoError(err)
+
+	messages, err := suite.service.GetRoomHistory("room-1", "user-1", 2, 1, domain.HistoryOrderNewestFirst)
+	suite.NoError(err)
+	suite.Equal([]string{"message-03", "message-02"}, messageIDs(messages))
+
+	messages, err = suite.service.GetRoomHistory("room-1", "user-1", 2, 1, domain.HistoryOrderOldestFirst)
+	suite.NoError(err)
+	suite.Equal([]string{"message-01", "message-02"}, messageIDs(messages))
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetRoomHistoryAtLimit() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.seedMessages("room-1", 12)
+	_, err := suite.service.loadRoom("room-1")
+	suite.NoError(err)
+
+	messages, err := suite.service.GetRoomHistory("room-1", "user-1", 10, 0, domain.HistoryOrderNewestFirst)
+	suite.NoError(err)
+	suite.Len(messages, 10)
+
+	messages, hasMore, err := suite.service.GetRoomHistoryBefore("room-1", "user-1", "", 10)
+	suite.NoError(err)
+	suite.True(hasMore)
+	suite.Len(messages, 10)
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetRoomHistoryOverLimit() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.seedMessages("room-1", 12)
+	_, err := suite.service.loadRoom("room-1")
+	suite.NoError(err)
+
+	_, err = suite.service.GetRoomHistory("room-1", "user-1", 11, 0, domain.HistoryOrderNewestFirst)
+	suite.ErrorIs(err, domain.ErrHistoryLimitExceeded)
+
+	_, _, err = suite.service.GetRoomHistoryBefore("room-1", "user-1", "", 11)
+	suite.ErrorIs(err, domain.ErrHistoryLimitExceeded)
+}
+
+func (suite *WebSocketServiceTestSuite) TestListEndpointsUseSharedDefaultPageSize() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.seedMessages("room-1", 12)
+	_, err := suite.service.loadRoom("room-1")
+	suite.NoError(err)
+	now := time.Now()
+	for i := 0; i < 12; i++ {
+		suite.seedNotification(fmt.Sprintf("notification-%02d", i), "user-1", now.Add(time.Duration(i)*time.Second))
+	}
+
+	messages, err := suite.service.GetRoomHistory("room-1", "user-1", 0, 0, domain.HistoryOrderNewestFirst)
+	suite.NoError(err)
+	suite.Len(messages, 10)
+
+	messages, hasMore, err := suite.service.GetRoomHistoryBefore("room-1", "user-1", "", 0)
+	suite.NoError(err)
+	suite.True(hasMore)
+	suite.Len(messages, 10)
+
+	notifications, err := suite.service.ListNotifications("user-1", 0, 0)
+	suite.NoError(err)
+	suite.Len(notifications, 10)
+}
+
+func (suite *WebSocketServiceTestSuite) countRooms() int {
+	suite.repo.mu.Lock()
+	defer suite.repo.mu.Unlock()
+	return len(suite.repo.rooms)
+}
+
+func (suite *WebSocketServiceTestSuite) TestCreateGroupRoomIdempotent() {
+	first, err := suite.service.CreateGroupRoom("Team", "user-1", []string{"user-1", "user-2"}, "user-1:key-1")
+	suite.NoError(err)
+
+	retried, err := suite.service.CreateGroupRoom("Team", "user-1", []string{"user-1", "user-2"}, "user-1:key-1")
+	suite.NoError(err)
+	suite.Equal(first.ID, retried.ID)
+	suite.Equal(1, suite.countRooms())
+
+	other, err := suite.service.CreateGroupRoom("Team", "user-1", []string{"user-1", "user-2"}, "user-1:key-2")
+	suite.NoError(err)
+	suite.NotEqual(first.ID, other.ID)
+	suite.Equal(2, suite.countRooms())
+}
+
+func (suite *WebSocketServiceTestSuite) TestCreateGroupRoomConcurrentRetries() {
+	var wg sync.WaitGroup
+	ids := make([]string, 5)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			room, err := suite.service.CreateGroupRoom("Team", "user-1", []string{"user-1"}, "user-1:key-1")
+			suite.NoError(err)
+			ids[i] = room.ID
+		}(i)
+	}
+	wg.Wait()
+
+	for _, id := range ids {
+		suite.Equal(ids[0], id)
+	}
+	suite.Equal(1, suite.countRooms())
+}
+
+func (suite *WebSocketServiceTestSuite) TestCreateGroupRoomWithoutKey() {
+	_, err := suite.service.CreateGroupRoom("Team", "user-1", []string{"user-1"}, "")
+	suite.NoError(err)
+	_, err = suite.service.CreateGroupRoom("Team", "user-1", []string{"user-1"}, "")
+	suite.NoError(err)
+	suite.Equal(2, suite.countRooms())
+}
+
+func (suite *WebSocketServiceTestSuite) seedNotification(id, userID string, createdAt time.Time) {
+	suite.NoError(suite.repo.CreateNotification(&domain.Notification{
+		ID:        id,
+		UserID:    userID,
+		Type:      domain.NotificationTypeSystem,
+		CreatedAt: createdAt,
+	}))
+}
+
+func (suite *WebSocketServiceTestSuite) TestListNotificationsNewestFirst() {
+	now := time.Now()
+	suite.seedNotification("notification-1", "user-1", now.Add(-2*time.Minute))
+	suite.seedNotification("notification-2", "user-1", now.Add(-time.Minute))
+	suite.seedNotification("notification-3", "user-2", now)
+
+	notifications, err := suite.service.ListNotifications("user-1", 0, 0)
+	suite.NoError(err)
+	suite.Len(notifications, 2)
+	suite.Equal("notification-2", notifications[0].ID)
+	suite.Equal("notification-1", notifications[1].ID)
+
+	notifications, err = suite.service.ListNotifications("user-1", 1, 1)
+	suite.NoError(err)
+	suite.Len(notifications, 1)
+	suite.Equal("notification-1", notifications[0].ID)
+}
+
+func (suite *WebSocketServiceTestSuite) TestMarkNotificationAsReadChecksOwner() {
+	suite.seedNotification("notification-1", "user-1", time.Now())
+
+	err := suite.service.MarkNotificationAsRead("notification-1", "user-2")
+	suite.ErrorIs(err, domain.ErrNotificationNotFound)
+	count, err := suite.service.GetUnreadNotificationCount("user-1")
+	suite.NoError(err)
+	suite.Equal(1, count)
+
+	suite.NoError(suite.service.MarkNotificationAsRead("notification-1", "user-1"))
+	count, err = suite.service.GetUnreadNotificationCount("user-1")
+	suite.NoError(err)
+	suite.Equal(0, count)
+
+	err = suite.service.MarkNotificationAsRead("missing", "user-1")
+	suite.ErrorIs(err, domain.ErrNotificationNotFound)
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetUnreadCountsPerRoom() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2", "user-3")
+	suite.seedRoom("room-2", domain.RoomTypeDirect, "user-1", "user-2")
+	suite.seedRoom("room-3", domain.RoomTypeGroup, "user-1", "user-3")
+	suite.seedRoom("room-4", domain.RoomTypeGroup, "user-2", "user-3")
+
+	now := time.Now()
+	for i, m := range []struct{ room, sender string }{
+		{"room-1", "user-2"},
+		{"room-1", "user-3"},
+		{"room-1", "user-1"},
+		{"room-2", "user-2"},
+		{"room-4", "user-2"},
+	} {
+		suite.NoError(suite.repo.CreateMessage(&domain.Message{
+			ID:        fmt.Sprintf("message-%d", i+1),
+			RoomID:    m.room,
+			UserID:    m.sender,
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+		}))
+	}
+	suite.NoError(suite.service.MarkMessageAsRead("room-1", "user-1", "message-1"))
+
+	counts, err := suite.service.GetUnreadCounts("user-1")
+	suite.NoError(err)
+	suite.Equal(map[string]int{"room-1": 1, "room-2": 1, "room-3": 0}, counts)
+
+	counts, err = suite.service.GetUnreadCounts("user-3")
+	suite.NoError(err)
+	suite.Equal(map[string]int{"room-1": 2, "room-3": 0, "room-4": 1}, counts)
+}
+
+// mentionCount returns how many mention notifications the user has received
+func (suite *WebSocketServiceTestSuite) mentionCount(userID string) int {
+	notifications, err := suite.service.ListNotifications(userID, 0, 0)
+	suite.Require().NoError(err)
+	count := 0
+	for _, notification := range notifications {
+		if notification.Type == domain.NotificationTypeMention {
+			count++
+		}
+	}
+	return count
+}
+
+func (suite *WebSocketServiceTestSuite) TestMentionsNotifyMentionedMembers() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2", "user-3")
+	suite.service.mu.Lock()
+	suite.service.startedAt = time.Now().Add(-2 * time.Minute)
+	suite.service.mu.Unlock()
+
+	_, err := suite.service.SendGroupMessage("room-1", "user-2", "@user-1 can you review?")
+	suite.NoError(err)
+
+	suite.Eventually(func() bool {
+		return suite.mentionCount("user-1") == 1
+	}, time.Second, 10*time.Millisecond)
+	suite.Equal([]string{"user-1"}, suite.notifier.notified())
+	suite.Zero(suite.mentionCount("user-3"))
+}
+
+func (suite *WebSocketServiceTestSuite) TestMessagesWithoutMentionsDoNotNotify() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+	suite.service.mu.Lock()
+	suite.service.startedAt = time.Now().Add(-2 * time.Minute)
+	suite.service.mu.Unlock()
+
+	_, err := suite.service.SendGroupMessage("room-1", "user-2", "standup in 5")
+	suite.NoError(err)
+	_, err = suite.service.SendImageMessage("room-1", "user-2", "https://example.com/cat.png", "", 2048, "image/png")
+	suite.NoError(err)
+
+	suite.Never(func() bool {
+		return suite.mentionCount("user-1") > 0 || len(suite.notifier.notified()) > 0
+	}, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func (suite *WebSocketServiceTestSuite) TestMutedMemberMessagesStoreButDoNotNotify() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2", "user-3")
+	suite.NoError(suite.service.MuteRoomMember("room-1", "user-1", "user-2"))
+
+	message, err := suite.service.SendGroupMessage("room-1", "user-2", "@user-1 @user-3 standup in 5")
+	suite.NoError(err)
+	stored, err := suite.repo.GetMessage(message.ID)
+	suite.NoError(err)
+	suite.Require().NotNil(stored)
+	suite.Equal("@user-1 @user-3 standup in 5", stored.Content)
+	suite.Eventually(func() bool {
+		return suite.mentionCount("user-3") == 1
+	}, time.Second, 10*time.Millisecond)
+	suite.Zero(suite.mentionCount("user-1"))
+
+	// The mute is per sender, so other members still notify the muter
+	_, err = suite.service.SendGroupMessage("room-1", "user-3", "@user-1 on my way")
+	suite.NoError(err)
+	suite.Eventually(func() bool {
+		return suite.mentionCount("user-1") == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (suite *WebSocketServiceTestSuite) TestUnmuteRoomMemberRestoresNotifications() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+	suite.NoError(suite.service.MuteRoomMember("room-1", "user-1", "user-2"))
+	suite.NoError(suite.service.MuteRoomMember("room-1", "user-1", "user-2"))
+	suite.NoError(suite.service.UnmuteRoomMember("room-1", "user-1", "user-2"))
+
+	_, err := suite.service.SendGroupMessage("room-1", "user-2", "@user-1 look at this")
+	suite.NoError(err)
+	suite.Eventually(func() bool {
+		return suite.mentionCount("user-1") == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (suite *WebSocketServiceTestSuite) TestMuteRoomMemberValidation() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+
+	suite.ErrorIs(suite.service.MuteRoomMember("room-1", "user-1", "user-1"), domain.ErrCannotMuteSelf)
+	suite.ErrorIs(suite.service.MuteRoomMember("room-1", "user-1", "user-9"), domain.ErrUserNotInRoom)
+	suite.ErrorIs(suite.service.MuteRoomMember("room-1", "user-9", "user-1"), domain.ErrUserNotInRoom)
+	suite.ErrorIs(suite.service.MuteRoomMember("missing", "user-1", "user-2"), domain.ErrRoomNotFound)
+}
+
+func (suite *WebSocketServiceTestSuite) TestNotificationRetentionTrimsOldReadNotifications() {
+	suite.service.maxNotifications = 3
+	suite.service.notificationMaxAge = 24 * time.Hour
+
+	now := time.Now()
+	suite.seedNotification("stale-read", "user-1", now.Add(-48*time.Hour))
+	suite.seedNotification("stale-unread", "user-1", now.Add(-47*time.Hour))
+	suite.seedNotification("old-read", "user-1", now.Add(-3*time.Hour))
+	suite.seedNotification("old-unread", "user-1", now.Add(-2*time.Hour))
+	suite.seedNotification("recent-read", "user-1", now.Add(-time.Hour))
+	for _, id := range []string{"stale-read", "old-read", "recent-read"} {
+		suite.NoError(suite.service.MarkNotificationAsRead(id, "user-1"))
+	}
+
+	suite.NoError(suite.service.SendSystemNotification("user-1", "Maintenance", "Back in 5 minutes"))
+
+	notifications, err := suite.service.ListNotifications("user-1", 10, 0)
+	suite.NoError(err)
+	var ids []string
+	for _, notification := range notifications {
+		ids = append(ids, notification.ID)
+	}
+	suite.Len(ids, 4)
+	suite.Equal([]string{"recent-read", "old-unread", "stale-unread"}, ids[1:])
+}
+
+func (suite *WebSocketServiceTestSuite) TestNotificationRetentionDisabledByDefault() {
+	now := time.Now()
+	suite.seedNotification("stale-read", "user-1", now.Add(-48*time.Hour))
+	suite.NoError(suite.service.MarkNotificationAsRead("stale-read", "user-1"))
+
+	suite.NoError(suite.service.SendSystemNotification("user-1", "Maintenance", "Back in 5 minutes"))
+
+	notifications, err := suite.service.ListNotifications("user-1", 10, 0)
+	suite.NoError(err)
+	suite.Len(notifications, 2)
+}
+
+func TestWebSocketServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(WebSocketServiceTestSuite))
+}
+
+// backdate moves a stored message's timestamp into the past
+func (suite *WebSocketServiceTestSuite) backdate(messageID string, by time.Duration) {
+	suite.repo.mu.Lock()
+	defer suite.repo.mu.Unlock()
+	suite.repo.messages[messageID].CreatedAt = suite.repo.messages[messageID].CreatedAt.Add(-by)
+}
+
+func (suite *WebSocketServiceTestSuite) TestSlowModeRejectsDuringCooldownAndAcceptsAfter() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+	suite.NoError(suite.service.SetRoomSlowMode("room-1", 30*time.Second))
+
+	first, err := suite.service.SendGroupMessage("room-1", "user-1", "first")
+	suite.Require().NoError(err)
+
+	_, err = suite.service.SendGroupMessage("room-1", "user-1", "too soon")
+	suite.ErrorIs(err, domain.ErrSlowMode)
+	var slowMode *domain.SlowModeError
+	suite.Require().ErrorAs(err, &slowMode)
+	suite.Equal(30*time.Second, slowMode.RetryAfter())
+	suite.Contains(err.Error(), "wait 30s")
+
+	// The cooldown is per user
+	_, err = suite.service.SendGroupMessage("room-1", "user-2", "hello")
+	suite.NoError(err)
+
+	suite.backdate(first.ID, 20*time.Second)
+	_, err = suite.service.SendGroupMessage("room-1", "user-1", "still too soon")
+	suite.Require().ErrorAs(err, &slowMode)
+	suite.Equal(10*time.Second, slowMode.RetryAfter())
+
+	suite.backdate(first.ID, 10*time.Second)
+	_, err = suite.service.SendGroupMessage("room-1", "user-1", "after cooldown")
+	suite.NoError(err)
+}
+
+func (suite *WebSocketServiceTestSuite) TestSlowModeAppliesToAttachments() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.NoError(suite.service.SetRoomSlowMode("room-1", 30*time.Second))
+
+	_, err := suite.service.SendGroupMessage("room-1", "user-1", "first")
+	suite.Require().NoError(err)
+
+	message, err := suite.service.SendFileMessage("room-1", "user-1", "https://example.com/report.pdf", "report.pdf", 2048, "application/pdf")
+	suite.ErrorIs(err, domain.ErrSlowMode)
+	suite.Nil(message)
+	_, err = suite.service.SendImageMessage("room-1", "user-1", "https://example.com/a.png", "", 2048, "image/png")
+	suite.ErrorIs(err, domain.ErrSlowMode)
+	suite.Len(suite.repo.messages, 1)
+}
+
+func (suite *WebSocketServiceTestSuite) TestSlowModeOffAllowsBackToBackMessages() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+	suite.NoError(suite.service.SetRoomSlowMode("room-1", time.Minute))
+	suite.NoError(suite.service.SetRoomSlowMode("room-1", 0))
+
+	suite.sendGroupMessages("room-1", "user-1", 3)
+	room, err := suite.repo.GetRoom("room-1")
+	suite.NoError(err)
+	suite.Zero(room.SlowModeSeconds)
+}
+
+func (suite *WebSocketServiceTestSuite) TestSetRoomSlowModeValidation() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+
+	for _, interval := range []time.Duration{-time.Second, 2 * time.Hour, 1500 * time.Millisecond} {
+		suite.ErrorIs(suite.service.SetRoomSlowMode("room-1", interval), domain.ErrInvalidSlowMode)
+	}
+	suite.ErrorIs(suite.service.SetRoomSlowMode("missing", time.Second), domain.ErrRoomNotFound)
+}
+
+// limitSends swaps in a send limiter allowing perMinute messages per user
+func (suite *WebSocketServiceTestSuite) limitSends(perMinute int) {
+	suite.service.sendLimiter.Close()
+	suite.service.sendLimiter = ratelimit.NewLimiter(perMinute, time.Minute,
+		ratelimit.WithClock(func() time.Time { return suite.service.now() }))
+}
+
+func (suite *WebSocketServiceTestSuite) TestSendsAreRateLimitedPerUser() {
+	clock := suite.useFakeClock()
+	suite.limitSends(3)
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+
+	suite.sendGroupMessages("room-1", "user-1", 3)
+	_, err := suite.service.SendGroupMessage("room-1", "user-1", "one too many")
+	suite.ErrorIs(err, domain.ErrRateLimited)
+	var rateLimited *domain.RateLimitError
+	suite.Require().ErrorAs(err, &rateLimited)
+	suite.Equal(20*time.Second, rateLimited.RetryAfter())
+
+	// Every kind of send shares the budget, which is per user
+	_, err = suite.service.SendDirectMessage("user-1", "user-2", "hi")
+	suite.ErrorIs(err, domain.ErrRateLimited)
+	_, err = suite.service.SendImageMessage("room-1", "user-1", "https://files.example.com/a.png", "", 10, "image/png")
+	suite.ErrorIs(err, domain.ErrRateLimited)
+	_, err = suite.service.SendGroupMessage("room-1", "user-2", "hello")
+	suite.NoError(err)
+
+	clock.Advance(20 * time.Second)
+	suite.sendGroupMessages("room-1", "user-1", 1)
+	_, err = suite.service.SendGroupMessage("room-1", "user-1", "too soon")
+	suite.ErrorIs(err, domain.ErrRateLimited)
+
+	clock.Advance(time.Minute)
+	suite.sendGroupMessages("room-1", "user-1", 3)
+}
+
+func (suite *WebSocketServiceTestSuite) TestRateLimitedTextFrameGetsRateLimitedReply() {
+	suite.limitSends(1)
+	clients := suite.dialRoom("room-1", "user-1", "user-2")
+
+	for _, content := range []string{"first", "second"} {
+		suite.Require().NoError(clients[0].WriteJSON(domain.WebSocketMessage{
+			Type:    domain.MessageTypeText,
+			RoomID:  "room-1",
+			Content: content,
+		}))
+	}
+
+	suite.Equal("first", suite.readFrame(clients[1]).Content)
+	// The rejection may overtake the sender's copy of its first message
+	reply := suite.readFrame(clients[0])
+	if reply.Type == domain.MessageTypeText {
+		reply = suite.readFrame(clients[0])
+	}
+	suite.Equal(domain.MessageTypeRateLimited, reply.Type)
+	suite.Equal("room-1", reply.RoomID)
+	suite.Contains(reply.Content, domain.ErrRateLimited.Error())
+}
+
+func (suite *WebSocketServiceTestSuite) TestMessageLengthIsCapped() {
+	suite.service.maxMessageLength = 5
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+
+	// Length counts characters, not bytes
+	message, err := suite.service.SendGroupMessage("room-1", "user-1", "héllo")
+	suite.Require().NoError(err)
+	suite.Equal("héllo", message.Content)
+	_, err = suite.service.SendDirectMessage("user-1", "user-2", "hello")
+	suite.NoError(err)
+
+	_, err = suite.service.SendGroupMessage("room-1", "user-1", "héllo!")
+	suite.ErrorIs(err, domain.ErrMessageTooLong)
+	_, err = suite.service.SendDirectMessage("user-1", "user-2", "hello!")
+	suite.ErrorIs(err, domain.ErrMessageTooLong)
+
+	messages, err := suite.repo.GetRoomMessages("room-1", 10, 0, domain.HistoryOrderNewestFirst)
+	suite.Require().NoError(err)
+	suite.Len(messages, 1)
+}
+
+func (suite *WebSocketServiceTestSuite) TestOversizedFrameClosesConnection() {
+	suite.service.maxMessageLength = 10
+	client, _ := suite.dial("user-1")
+
+	suite.Require().NoError(client.WriteJSON(domain.WebSocketMessage{
+		Type:    domain.MessageTypeText,
+		RoomID:  "room-1",
+		Content: strings.Repeat("a", int(suite.service.maxFrameSize())),
+	}))
+
+	suite.Require().NoError(client.SetReadDeadline(time.Now().Add(time.Second)))
+	_, _, err := client.ReadMessage()
+	suite.True(websocket.IsCloseError(err, websocket.CloseMessageTooBig), err)
+}
+
+func (suite *WebSocketServiceTestSuite) sendGroupMessages(roomID, userID string, count int) {
+	for i := 0; i < count; i++ {
+		_, err := suite.service.SendGroupMessage(roomID, userID, fmt.Sprintf("message %d", i+1))
+		suite.Require().NoError(err)
+	}
+}
+
+func sequences(messages []domain.WebSocketMessage) []int64 {
+	result := make([]int64, 0, len(messages))
+	for _, message := range messages {
+		result = append(result, message.Sequence)
+	}
+	return result
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetMissingMessagesReturnsOnlyTheGap() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+	suite.seedRoom("room-2", domain.RoomTypeGroup, "user-1", "user-2")
+	suite.sendGroupMessages("room-1", "user-2", 5)
+	suite.sendGroupMessages("room-2", "user-2", 3)
+
+	missing, err := suite.service.GetMissingMessages("room-1", "user-1", 2, 0)
+	suite.NoError(err)
+	suite.Equal([]int64{3, 4, 5}, sequences(missing))
+	suite.Equal("message 3", missing[0].Content)
+
+	missing, err = suite.service.GetMissingMessages("room-1", "user-1", 5, 0)
+	suite.NoError(err)
+	suite.Empty(missing)
+
+	missing, err = suite.service.GetMissingMessages("room-1", "user-1", 0, 2)
+	suite.NoError(err)
+	suite.Equal([]int64{1, 2}, sequences(missing))
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetMissingMessagesValidation() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+
+	_, err := suite.service.GetMissingMessages("room-1", "user-3", 0, 0)
+	suite.ErrorIs(err, domain.ErrUserNotInRoom)
+
+	_, err = suite.service.GetMissingMessages("room-1", "user-1", -1, 0)
+	suite.ErrorIs(err, domain.ErrInvalidSequence)
+
+	_, err = suite.service.GetMissingMessages("missing", "user-1", 0, 0)
+	suite.ErrorIs(err, domain.ErrRoomNotFound)
+}
+
+func (suite *WebSocketServiceTestSuite) TestRequestMissingFrameReplaysGap() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+	suite.sendGroupMessages("room-1", "user-2", 4)
+
+	client, _ := suite.dial("user-1")
+	suite.Require().NoError(client.WriteJSON(domain.WebSocketMessage{
+		Type:     domain.MessageTypeRequestMissing,
+		RoomID:   "room-1",
+		Sequence: 1,
+	}))
+
+	var replayed []domain.WebSocketMessage
+	for i := 0; i < 3; i++ {
+		var message domain.WebSocketMessage
+		suite.Require().NoError(client.SetReadDeadline(time.Now().Add(time.Second)))
+		suite.Require().NoError(client.ReadJSON(&message))
+		replayed = append(replayed, message)
+	}
+	suite.Equal([]int64{2, 3, 4}, sequences(replayed))
+
+	// Nothing beyond the gap is sent
+	suite.Require().NoError(client.SetReadDeadline(time.Now().Add(50 * time.Millisecond)))
+	var extra domain.WebSocketMessage
+	suite.Error(client.ReadJSON(&extra))
+}
+
+func (suite *WebSocketServiceTestSuite) TestRequestMissingFrameReportsErrors() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-2")
+
+	client, _ := suite.dial("user-1")
+	suite.Require().NoError(client.WriteJSON(domain.WebSocketMessage{
+		Type:   domain.MessageTypeRequestMissing,
+		RoomID: "room-1",
+	}))
+
+	var reply domain.WebSocketMessage
+	suite.Require().NoError(client.SetReadDeadline(time.Now().Add(time.Second)))
+	suite.Require().NoError(client.ReadJSON(&reply))
+	suite.Equal(domain.MessageTypeError, reply.Type)
+	suite.Equal(domain.ErrUserNotInRoom.Error(), reply.Content)
+}
+
+func (suite *WebSocketServiceTestSuite) TestUnroutableMessagesAreRejected() {
+	err := suite.service.SendTypingIndicator("", "user-1")
+	suite.ErrorIs(err, domain.ErrUnroutableMessage)
+
+	message, err := suite.service.SendImageMessage("", "user-1", "https://example.com/a.png", "", 2048, "image/png")
+	suite.ErrorIs(err, domain.ErrUnroutableMessage)
+	suite.Nil(message)
+	suite.Empty(suite.repo.messages)
+}
+
+func (suite *WebSocketServiceTestSuite) TestSendingRequiresMembership() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1")
+
+	sends := map[string]func() (*domain.Message, error){
+		"text": func() (*domain.Message, error) {
+			return suite.service.SendGroupMessage("room-1", "user-2", "hello")
+		},
+		"file": func() (*domain.Message, error) {
+			return suite.service.SendFileMessage("room-1", "user-2", "https://example.com/a.pdf", "a.pdf", 2048, "application/pdf")
+		},
+		"image": func() (*domain.Message, error) {
+			return suite.service.SendImageMessage("room-1", "user-2", "https://example.com/a.png", "", 2048, "image/png")
+		},
+		"video": func() (*domain.Message, error) {
+			return suite.service.SendVideoMessage("room-1", "user-2", "https://example.com/a.mp4", "", 2048, "video/mp4", 10)
+		},
+		"audio": func() (*domain.Message, error) {
+			return suite.service.SendAudioMessage("room-1", "user-2", "https://example.com/a.mp3", 2048, "audio/mpeg", 10)
+		},
+	}
+	for name, send := range sends {
+		suite.Run(name, func() {
+			message, err := send()
+			suite.ErrorIs(err, domain.ErrUserNotInRoom)
+			suite.Nil(message)
+		})
+	}
+	suite.Empty(suite.repo.messages)
+
+	_, err := suite.service.SendGroupMessage("missing", "user-1", "hello")
+	suite.ErrorIs(err, domain.ErrRoomNotFound)
+}
+
+func (suite *WebSocketServiceTestSuite) TestAttachmentValidation() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+	const url = "https://example.com/attachment"
+
+	tests := []struct {
+		name string
+		send func() (*domain.Message, error)
+	}{
+		{name: "file without url", send: func() (*domain.Message, error) {
+			return suite.service.SendFileMessage("room-1", "user-1", "  ", "report.pdf", 2048, "application/pdf")
+		}},
+		{name: "empty file", send: func() (*domain.Message, error) {
+			return suite.service.SendFileMessage("room-1", "user-1", url, "report.pdf", 0, "application/pdf")
+		}},
+		{name: "oversized file", send: func() (*domain.Message, error) {
+			return suite.service.SendFileMessage("room-1", "user-1", url, "report.pdf", 1<<20+1, "application/pdf")
+		}},
+		{name: "oversized image", send: func() (*domain.Message, error) {
+			return suite.service.SendImageMessage("room-1", "user-1", url, "", 2<<20, "image/png")
+		}},
+		{name: "file type not allowed", send: func() (*domain.Message, error) {
+			return suite.service.SendFileMessage("room-1", "user-1", url, "setup.exe", 2048, "application/x-msdownload")
+		}},
+		{name: "malformed file type", send: func() (*domain.Message, error) {
+			return suite.service.SendFileMessage("room-1", "user-1", url, "report.pdf", 2048, "pdf")
+		}},
+		{name: "image that is not an image", send: func() (*domain.Message, error) {
+			return suite.service.SendImageMessage("room-1", "user-1", url, "", 2048, "application/pdf")
+		}},
+		{name: "video that is audio", send: func() (*domain.Message, error) {
+			return suite.service.SendVideoMessage("room-1", "user-1", url, "", 2048, "audio/mpeg", 30)
+		}},
+		{name: "audio without a type", send: func() (*domain.Message, error) {
+			return suite.service.SendAudioMessage("room-1", "user-1", url, 2048, "", 30)
+		}},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			message, err := tt.send()
+			suite.ErrorIs(err, domain.ErrInvalidMessage)
+			suite.Nil(message)
+		})
+	}
+	suite.Empty(suite.repo.messages)
+}
+
+func (suite *WebSocketServiceTestSuite) TestAttachmentTypesAreNormalized() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+
+	// Wildcard allowlist entries and parameters on the type are both accepted
+	notes, err := suite.service.SendFileMessage("room-1", "user-1", "https://example.com/notes.txt", "notes.txt", 12, "Text/Plain; charset=utf-8")
+	suite.Require().NoError(err)
+	suite.Equal("text/plain", notes.FileType)
+
+	image, err := suite.service.SendImageMessage("room-1", "user-1", "https://example.com/cat.png", "", 1<<20, "image/png")
+	suite.Require().NoError(err)
+	suite.Equal(int64(1<<20), image.FileSize)
+	suite.Equal("image/png", image.FileType)
+}
+
+func (suite *WebSocketServiceTestSuite) TestUnroutableClientFrameGetsErrorReply() {
+	client, _ := suite.dial("user-1")
+	suite.Require().NoError(client.WriteJSON(domain.WebSocketMessage{
+		Type:    domain.MessageTypeText,
+		Content: "hello?",
+	}))
+
+	var reply domain.WebSocketMessage
+	suite.Require().NoError(client.SetReadDeadline(time.Now().Add(time.Second)))
+	suite.Require().NoError(client.ReadJSON(&reply))
+	suite.Equal(domain.MessageTypeError, reply.Type)
+	suite.Contains(reply.Content, domain.ErrUnroutableMessage.Error())
+}
+
+// useFakeClock drives the service's typing timers from a fake clock
+func (suite *WebSocketServiceTestSuite) useFakeClock() *fakeClock {
+	clock := newFakeClock()
+	suite.service.now = clock.Now
+	suite.service.afterFunc = clock.AfterFunc
+	return clock
+}
+
+// expectFrame waits for the next message queued for a fake connection
+func (suite *WebSocketServiceTestSuite) expectFrame(conn *domain.Connection, messageType string) domain.WebSocketMessage {
+	select {
+	case frame := <-conn.Send:
+		suite.Equal(messageType, frame.Type)
+		return frame
+	case <-time.After(time.Second):
+		suite.Failf("no frame", "expected a %s frame", messageType)
+		return domain.WebSocketMessage{}
+	}
+}
+
+// expectNoFrame asserts nothing was queued for a fake connection
+func (suite *WebSocketServiceTestSuite) expectNoFrame(conn *domain.Connection) {
+	select {
+	case frame := <-conn.Send:
+		suite.Failf("unexpected frame", "got a %s frame", frame.Type)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func (suite *WebSocketServiceTestSuite) TestTypingIndicatorStopsAfterTimeout() {
+	clock := suite.useFakeClock()
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+	_, err := suite.service.loadRoom("room-1")
+	suite.Require().NoError(err)
+	listener := suite.connect("user-2", 10)
+
+	suite.Require().NoError(suite.service.SendTypingIndicator("room-1", "user-1"))
+	suite.expectFrame(listener, domain.MessageTypeTyping)
+
+	clock.Advance(defaultTypingTimeout - time.Millisecond)
+	suite.expectNoFrame(listener)
+
+	clock.Advance(time.Millisecond)
+	frame := suite.expectFrame(listener, domain.MessageTypeStopTyping)
+	suite.Equal("room-1", frame.RoomID)
+	suite.Equal("user-1", frame.UserID)
+}
+
+func (suite *WebSocketServiceTestSuite) TestTypingIndicatorIsDebounced() {
+	clock := suite.useFakeClock()
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+	_, err := suite.service.loadRoom("room-1")
+	suite.Require().NoError(err)
+	listener := suite.connect("user-2", 10)
+
+	// Keystrokes within a second of the broadcast one are dropped
+	suite.Require().NoError(suite.service.SendTypingIndicator("room-1", "user-1"))
+	suite.expectFrame(listener, domain.MessageTypeTyping)
+	for i := 0; i < 3; i++ {
+		clock.Advance(300 * time.Millisecond)
+		suite.Require().NoError(suite.service.SendTypingIndicator("room-1", "user-1"))
+	}
+	suite.expectNoFrame(listener)
+
+	clock.Advance(100 * time.Millisecond)
+	suite.Require().NoError(suite.service.SendTypingIndicator("room-1", "user-1"))
+	suite.expectFrame(listener, domain.MessageTypeTyping)
+
+	// Each event, debounced or not, pushes the stop back
+	clock.Advance(300 * time.Millisecond)
+	suite.Require().NoError(suite.service.SendTypingIndicator("room-1", "user-1"))
+	clock.Advance(defaultTypingTimeout - time.Millisecond)
+	suite.expectNoFrame(listener)
+	clock.Advance(time.Millisecond)
+	suite.expectFrame(listener, domain.MessageTypeStopTyping)
+}
+
+func (suite *WebSocketServiceTestSuite) TestDirectMessageToOnlineUserIsAcknowledged() {
+	sender := suite.connect("user-1", 10)
+	recipient := suite.connect("user-2", 10)
+
+	message, err := suite.service.SendDirectMessage("user-1", "user-2", "hello")
+	suite.Require().NoError(err)
+	suite.expectFrame(recipient, domain.MessageTypeText)
+
+	receipt := suite.expectFrame(sender, domain.MessageTypeDelivered)
+	suite.Equal(message.ID, receipt.MessageID)
+	suite.Equal(message.RoomID, receipt.RoomID)
+	suite.Equal("user-2", receipt.UserID)
+	suite.Equal(domain.MessageStatusDelivered, receipt.Status)
+
+	status, err := suite.repo.GetMessageStatus(message.ID, "user-2")
+	suite.NoError(err)
+	suite.Require().NotNil(status)
+	suite.Equal(domain.MessageStatusDelivered, status.Status)
+}
+
+func (suite *WebSocketServiceTestSuite) TestGroupMessageIsAcknowledgedPerRecipient() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2", "user-3")
+	_, err := suite.service.loadRoom("room-1")
+	suite.Require().NoError(err)
+	sender := suite.connect("user-1", 10)
+	suite.connect("user-2", 10)
+	// user-3 is offline, so only user-2 acknowledges
+
+	message, err := suite.service.SendGroupMessage("room-1", "user-1", "hello")
+	suite.Require().NoError(err)
+	suite.expectFrame(sender, domain.MessageTypeText)
+
+	receipt := suite.expectFrame(sender, domain.MessageTypeDelivered)
+	suite.Equal(message.ID, receipt.MessageID)
+	suite.Equal("user-2", receipt.UserID)
+	suite.expectNoFrame(sender)
+
+	status, err := suite.repo.GetMessageStatus(message.ID, "user-3")
+	suite.NoError(err)
+	suite.Nil(status)
+}
+
+func (suite *WebSocketServiceTestSuite) TestUnreadUpdateOnNewGroupMessage() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+	_, err := suite.service.loadRoom("room-1")
+	suite.Require().NoError(err)
+	sender := suite.connect("user-1", 10)
+	member := suite.connect("user-2", 10)
+
+	_, err = suite.service.SendGroupMessage("room-1", "user-1", "hello")
+	suite.Require().NoError(err)
+
+	suite.expectFrame(member, domain.MessageTypeText)
+	update := suite.expectFrame(member, domain.MessageTypeUnreadUpdate)
+	suite.Equal("room-1", update.RoomID)
+	suite.Require().NotNil(update.UnreadCount)
+	suite.Equal(1, *update.UnreadCount)
+
+	// The sender's own count is unchanged
+	suite.expectFrame(sender, domain.MessageTypeText)
+	suite.expectFrame(sender, domain.MessageTypeDelivered)
+	suite.expectNoFrame(sender)
+}
+
+func (suite *WebSocketServiceTestSuite) TestUnreadUpdateOnNewDirectMessage() {
+	recipient := suite.connect("user-2", 10)
+
+	message, err := suite.service.SendDirectMessage("user-1", "user-2", "hello")
+	suite.Require().NoError(err)
+
+	suite.expectFrame(recipient, domain.MessageTypeText)
+	update := suite.expectFrame(recipient, domain.MessageTypeUnreadUpdate)
+	suite.Equal(message.RoomID, update.RoomID)
+	suite.Require().NotNil(update.UnreadCount)
+	suite.Equal(1, *update.UnreadCount)
+}
+
+func (suite *WebSocketServiceTestSuite) TestUnreadUpdateOnMarkAsRead() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+	suite.seedMessages("room-1", 1)
+	_, err := suite.service.loadRoom("room-1")
+	suite.Require().NoError(err)
+	reader := suite.connect("user-2", 10)
+
+	suite.Require().NoError(suite.service.MarkMessageAsRead("room-1", "user-2", "message-00"))
+
+	suite.expectFrame(reader, domain.MessageTypeRead)
+	update := suite.expectFrame(reader, domain.MessageTypeUnreadUpdate)
+	suite.Equal("room-1", update.RoomID)
+	suite.Require().NotNil(update.UnreadCount)
+	suite.Equal(0, *update.UnreadCount)
+}
+
+func (suite *WebSocketServiceTestSuite) TestTaskSummaryUpdateOnlyReachesRole() {
+	employer := suite.connectAs("employer-1", "employer", 10)
+	otherEmployer := suite.connectAs("employer-2", "employer", 10)
+	employee := suite.connectAs("employee-1", "employee", 10)
+
+	suite.Require().NoError(suite.service.SendTaskSummaryUpdate("employer"))
+
+	update := suite.expectFrame(employer, domain.MessageTypeSummaryUpdate)
+	suite.Equal("employer-1", update.TargetID)
+	update = suite.expectFrame(otherEmployer, domain.MessageTypeSummaryUpdate)
+	suite.Equal("employer-2", update.TargetID)
+	suite.expectNoFrame(employee)
+
+	// With nobody of the role online there is nothing to send
+	suite.NoError(suite.service.SendTaskSummaryUpdate("manager"))
+}
+
+func (suite *WebSocketServiceTestSuite) TestDeliveryDoesNotUndoRead() {
+	suite.connect("user-2", 10)
+	suite.Require().NoError(suite.repo.UpdateMessageStatus(&domain.MessageStatus{
+		ID:        "status-1",
+		MessageID: "message-1",
+		UserID:    "user-2",
+		Status:    domain.MessageStatusRead,
+	}))
+
+	suite.Require().NoError(suite.service.markDelivered(domain.WebSocketMessage{
+		Type:   domain.MessageTypeText,
+		ID:     "message-1",
+		UserID: "user-1",
+	}, "user-2"))
+
+	status, err := suite.repo.GetMessageStatus("message-1", "user-2")
+	suite.NoError(err)
+	suite.Equal(domain.MessageStatusRead, status.Status)
+}
+
+// readFrame reads the next frame the client receives
+func (suite *WebSocketServiceTestSuite) readFrame(client *websocket.Conn) domain.WebSocketMessage {
+	var frame domain.WebSocketMessage
+	suite.Require().NoError(client.SetReadDeadline(time.Now().Add(time.Second)))
+	suite.Require().NoError(client.ReadJSON(&frame))
+	return frame
+}
+
+// dialRoom seeds a group room and connects each of its members
+func (suite *WebSocketServiceTestSuite) dialRoom(roomID string, userIDs ...string) []*websocket.Conn {
+	suite.seedRoom(roomID, domain.RoomTypeGroup, userIDs...)
+	clients := make([]*websocket.Conn, len(userIDs))
+	for i, userID := range userIDs {
+		clients[i], _ = suite.dial(userID)
+	}
+	return clients
+}
+
+func (suite *WebSocketServiceTestSuite) TestTextFrameIsPersistedAndBroadcast() {
+	clients := suite.dialRoom("room-1", "user-1", "user-2")
+
+	// The claimed sender is ignored in favour of the connection's user
+	suite.Require().NoError(clients[0].WriteJSON(domain.WebSocketMessage{
+		Type:    domain.MessageTypeText,
+		RoomID:  "room-1",
+		UserID:  "user-2",
+		Content: "hello",
+	}))
+
+	frame := suite.readFrame(clients[1])
+	suite.Equal(domain.MessageTypeText, frame.Type)
+	suite.Equal("user-1", frame.UserID)
+	suite.Equal("hello", frame.Content)
+
+	message, err := suite.repo.GetMessage(frame.ID)
+	suite.Require().NoError(err)
+	suite.Require().NotNil(message)
+	suite.Equal("user-1", message.UserID)
+	suite.Equal("room-1", message.RoomID)
+}
+
+func (suite *WebSocketServiceTestSuite) TestDirectTextFrameReachesTarget() {
+	sender, _ := suite.dial("user-1")
+	receiver, _ := suite.dial("user-2")
+
+	suite.Require().NoError(sender.WriteJSON(domain.WebSocketMessage{
+		Type:     domain.MessageTypeText,
+		TargetID: "user-2",
+		Content:  "hi",
+	}))
+
+	frame := suite.readFrame(receiver)
+	suite.Equal("user-1", frame.UserID)
+	suite.Equal("hi", frame.Content)
+	message, err := suite.repo.GetMessage(frame.ID)
+	suite.NoError(err)
+	suite.NotNil(message)
+}
+
+func (suite *WebSocketServiceTestSuite) TestTypingFrameIsBroadcast() {
+	clients := suite.dialRoom("room-1", "user-1", "user-2")
+
+	suite.Require().NoError(clients[0].WriteJSON(domain.WebSocketMessage{
+		Type:   domain.MessageTypeTyping,
+		RoomID: "room-1",
+	}))
+
+	frame := suite.readFrame(clients[1])
+	suite.Equal(domain.MessageTypeTyping, frame.Type)
+	suite.Equal("user-1", frame.UserID)
+	suite.repo.mu.Lock()
+	defer suite.repo.mu.Unlock()
+	suite.Empty(suite.repo.messages)
+}
+
+func (suite *WebSocketServiceTestSuite) TestReadFrameRecordsReceipt() {
+	clients := suite.dialRoom("room-1", "user-1", "user-2")
+	suite.Require().NoError(suite.repo.CreateMessage(&domain.Message{ID: "message-1", RoomID: "room-1", UserID: "user-2"}))
+
+	suite.Require().NoError(clients[0].WriteJSON(domain.WebSocketMessage{
+		Type:      domain.MessageTypeRead,
+		RoomID:    "room-1",
+		MessageID: "message-1",
+	}))
+
+	frame := suite.readFrame(clients[1])
+	suite.Equal(domain.MessageTypeRead, frame.Type)
+	suite.Equal("user-1", frame.UserID)
+	suite.Equal("message-1", frame.MessageID)
+
+	suite.repo.mu.Lock()
+	defer suite.repo.mu.Unlock()
+	suite.Len(suite.repo.statuses, 1)
+}
+
+func (suite *WebSocketServiceTestSuite) TestRejectedFramesGetErrorReply() {
+	tests := []struct {
+		name  string
+		frame domain.WebSocketMessage
+		err   error
+	}{
+		{
+			name:  "text outside the sender's rooms",
+			frame: domain.WebSocketMessage{Type: domain.MessageTypeText, RoomID: "room-2", Content: "hello"},
+			err:   domain.ErrUserNotInRoom,
+		},
+		{
+			name:  "typing outside the sender's rooms",
+			frame: domain.WebSocketMessage{Type: domain.MessageTypeTyping, RoomID: "room-2"},
+			err:   domain.ErrUserNotInRoom,
+		},
+		{
+			name:  "empty text",
+			frame: domain.WebSocketMessage{Type: domain.MessageTypeText, RoomID: "room-1"},
+			err:   domain.ErrInvalidMessage,
+		},
+		{
+			name:  "server-only type",
+			frame: domain.WebSocketMessage{Type: domain.MessageTypeTaskUpdate, Content: "forged"},
+			err:   domain.ErrUnsupportedFrame,
+		},
+		{
+			name:  "room type instead of message type",
+			frame: domain.WebSocketMessage{Type: domain.RoomTypeDirect, TargetID: "user-2", Content: "hi"},
+			err:   domain.ErrUnsupportedFrame,
+		},
+	}
+
+	suite.seedRoom("room-2", domain.RoomTypeGroup, "user-2")
+	clients := suite.dialRoom("room-1", "user-1", "user-2")
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.Require().NoError(clients[0].WriteJSON(tt.frame))
+
+			reply := suite.readFrame(clients[0])
+			suite.Equal(domain.MessageTypeError, reply.Type)
+			suite.Contains(reply.Content, tt.err.Error())
+		})
+	}
+
+	// None of the frames reached the other member or was stored
+	suite.Require().NoError(clients[1].SetReadDeadline(time.Now().Add(50 * time.Millisecond)))
+	var frame domain.WebSocketMessage
+	suite.Error(clients[1].ReadJSON(&frame))
+	suite.repo.mu.Lock()
+	defer suite.repo.mu.Unlock()
+	suite.Empty(suite.repo.messages)
+}
+
+// waitForPumps asserts every read and write pump has returned
+func (suite *WebSocketServiceTestSuite) waitForPumps() {
+	done := make(chan struct{})
+	go func() {
+		suite.service.pumps.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		suite.Fail("connection pumps did not exit")
+	}
+}
+
+func (suite *WebSocketServiceTestSuite) TestRegistrationBackpressure() {
+	cfg := viper.New()
+	cfg.Set("websocket.register_queue_size", 1)
+	cfg.Set("websocket.register_timeout", 50*time.Millisecond)
+	// The hub goroutine isn't running yet, so it is as busy as it gets
+	service := newWebSocketService(cfg, suite.repo, suite.notifier, nil)
+
+	handled := make(chan struct{}, 2)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		service.HandleConnection(conn, r.URL.Query().Get("user"), "employee")
+		handled <- struct{}{}
+	}))
+	defer server.Close()
+
+	dial := func(userID string) *websocket.Conn {
+		client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"?user="+userID, nil)
+		suite.Require().NoError(err)
+		suite.T().Cleanup(func() { client.Close() })
+		return client
+	}
+	waitHandled := func() {
+		select {
+		case <-handled:
+		case <-time.After(time.Second):
+			suite.FailNow("HandleConnection did not return")
+		}
+	}
+
+	// The first connection fits in the queue
+	dial("user-1")
+	waitHandled()
+
+	// The second finds it full and is turned away once the timeout passes
+	refused := dial("user-2")
+	waitHandled()
+	suite.Require().NoError(refused.SetReadDeadline(time.Now().Add(time.Second)))
+	_, _, err := refused.ReadMessage()
+	suite.True(websocket.IsCloseError(err, websocket.CloseTryAgainLater), "unexpected error: %v", err)
+
+	// Once the hub catches up the queued connection is registered
+	go service.runHub()
+	defer service.Close()
+	suite.Eventually(func() bool {
+		service.mu.RLock()
+		defer service.mu.RUnlock()
+		return service.hub.Connections["user-1"] != nil && service.hub.Connections["user-2"] == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (suite *WebSocketServiceTestSuite) TestPumpsExitWhenClientCloses() {
+	client, conn := suite.dial("user-1")
+
+	suite.Require().NoError(client.Close())
+
+	suite.waitForPumps()
+	suite.Eventually(func() bool {
+		return !suite.isConnected(conn)
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case <-conn.Done():
+	default:
+		suite.Fail("connection was not cancelled")
+	}
+}
+
+func (suite *WebSocketServiceTestSuite) TestPumpsExitWhenServerCloses() {
+	client, conn := suite.dial("user-1")
+
+	suite.service.hub.Unregister <- conn
+
+	suite.waitForPumps()
+	suite.Require().NoError(client.SetReadDeadline(time.Now().Add(time.Second)))
+	_, _, err := client.ReadMessage()
+	suite.Error(err)
+}
+
+func (suite *WebSocketServiceTestSuite) TestCloseStopsHubGoroutine() {
+	idempotencyKeys, err := localmemory.NewCache(time.Minute)
+	suite.Require().NoError(err)
+	defer idempotencyKeys.Close()
+
+	before := runtime.NumGoroutine()
+	service := NewWebSocketService(viper.New(), newFakeChatRepository(), &recordingNotifier{}, idempotencyKeys).(*websocketService)
+	suite.Require().NoError(service.Close())
+
+	select {
+	case <-service.hub.Done:
+	default:
+		suite.Fail("Close returned before the hub stopped")
+	}
+	// Poll by hand: Eventually runs its condition on a goroutine of its own
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	suite.LessOrEqual(runtime.NumGoroutine(), before)
+
+	// Closing twice is harmless
+	suite.NoError(service.Close())
+}
+
+func (suite *WebSocketServiceTestSuite) TestShutdownClosesEveryConnection() {
+	clientA, connA := suite.dial("user-1")
+	clientB, connB := suite.dial("user-2")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	suite.Require().NoError(suite.service.Shutdown(ctx))
+
+	// Shutdown only returns once the hub and every pump have stopped
+	select {
+	case <-suite.service.hub.Done:
+	default:
+		suite.Fail("hub is still running")
+	}
+	suite.waitForPumps()
+	suite.False(suite.isConnected(connA))
+	suite.False(suite.isConnected(connB))
+
+	for _, client := range []*websocket.Conn{clientA, clientB} {
+		suite.Require().NoError(client.SetReadDeadline(time.Now().Add(time.Second)))
+		_, _, err := client.ReadMessage()
+		suite.True(websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error: %v", err)
+	}
+
+	// Shutting down again has nothing left to wait for
+	suite.NoError(suite.service.Shutdown(ctx))
+}
+
+// testHTTPServer lets an httptest server take part in app shutdown like the
+// real HTTP server does, running afterStop once its listener is closed
+type testHTTPServer struct {
+	*httptest.Server
+	afterStop func()
+}
+
+func (s *testHTTPServer) Start(ctx context.Context) error { return nil }
+
+func (s *testHTTPServer) Stop(ctx context.Context) error {
+	err := s.Config.Shutdown(ctx)
+	s.afterStop()
+	return err
+}
+
+func (suite *WebSocketServiceTestSuite) TestAppStopsUpgradesBeforeDrainingSockets() {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		suite.service.HandleConnection(conn, r.URL.Query().Get("user"), "employee")
+	}))
+	suite.T().Cleanup(server.Close)
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	online := func(userID string) bool {
+		suite.service.mu.RLock()
+		defer suite.service.mu.RUnlock()
+		return suite.service.hub.Connections[userID] != nil
+	}
+	existing, _, err := websocket.DefaultDialer.Dial(url+"?user=user-1", nil)
+	suite.Require().NoError(err)
+	defer existing.Close()
+	suite.Eventually(func() bool { return online("user-1") }, time.Second, 10*time.Millisecond)
+
+	httpServer := &testHTTPServer{Server: server, afterStop: func() {
+		// Upgrades are refused while the existing socket is still served
+		_, _, err := websocket.DefaultDialer.Dial(url+"?user=user-2", nil)
+		suite.Error(err)
+		suite.True(online("user-1"))
+	}}
+	wsShutdown := NewWebSocketShutdown(viper.New(), suite.service)
+
+	// Registered the other way round, so the stop order comes from WithStopOrder
+	a := app.NewApp(app.WithServer(wsShutdown), app.WithServer(httpServer), app.WithStopOrder(httpServer, wsShutdown))
+	suite.Require().NoError(a.Stop())
+
+	// The existing socket was then drained with a going-away close frame
+	suite.Require().NoError(existing.SetReadDeadline(time.Now().Add(time.Second)))
+	_, _, err = existing.ReadMessage()
+	suite.True(websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error: %v", err)
+	suite.waitForPumps()
+	suite.False(online("user-2"))
+}
+
+func (suite *WebSocketServiceTestSuite) TestShutdownRejectsLaterWork() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+	suite.Require().NoError(suite.service.Shutdown(context.Background()))
+
+	// Sends that would otherwise block on the stopped hub fail fast
+	_, err := suite.service.SendGroupMessage("room-1", "user-1", "hello")
+	suite.ErrorIs(err, domain.ErrHubStopped)
+	_, err = suite.service.SendDirectMessage("user-1", "user-2", "hello")
+	suite.ErrorIs(err, domain.ErrHubStopped)
+
+	// New connections are closed without starting pumps
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		suite.service.HandleConnection(conn, "user-1", "employee")
+	}))
+	defer server.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	suite.Require().NoError(err)
+	defer client.Close()
+	suite.Require().NoError(client.SetReadDeadline(time.Now().Add(time.Second)))
+	_, _, err = client.ReadMessage()
+	suite.True(websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error: %v", err)
+	suite.waitForPumps()
+}
+
+func (suite *WebSocketServiceTestSuite) TestShutdownGivesUpAtDeadline() {
+	// A registered connection without pumps stands in for one that never exits
+	suite.connect("user-1", 1)
+	suite.service.pumps.Add(1)
+	defer suite.service.pumps.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	suite.ErrorIs(suite.service.Shutdown(ctx), context.DeadlineExceeded)
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetChatStats() {
+	suite.seedRoom("room-1", domain.RoomTypeGroup, "user-1", "user-2")
+	suite.sendGroupMessages("room-1", "user-2", 2)
+	suite.connect("user-1", 1)
+
+	now := time.Now()
+	stats, err := suite.service.GetChatStats(now.Add(-time.Hour), now, 0)
+	suite.NoError(err)
+	suite.Equal(int64(1), stats.TotalRooms)
+	suite.Equal(int64(2), stats.TotalMessages)
+	suite.Equal(1, stats.ActiveConnections)
+
+	_, err = suite.service.GetChatStats(now.Add(-time.Hour), now, 1000)
+	suite.NoError(err)
+	suite.Equal([]int{defaultStatsTopRooms, maxStatsTopRooms}, suite.repo.statsTopRooms)
+}
+
+func (suite *WebSocketServiceTestSuite) TestGetChatStatsRejectsInvalidRange() {
+	now := time.Now()
+	_, err := suite.service.GetChatStats(now, now, 0)
+	suite.ErrorIs(err, domain.ErrInvalidStatsRange)
+
+	_, err = suite.service.GetChatStats(now.Add(-2*maxStatsRange), now, 0)
+	suite.ErrorIs(err, domain.ErrInvalidStatsRange)
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const defaultWebSocketShutdownTimeout = 10 * time.Second
+
+// WebSocketShutdown closes every WebSocket connection when the app stops.
+// The HTTP server does not track hijacked connections, so without it their
+// pumps and the hub outlive shutdown. It satisfies server.Server and has to
+// stop after the HTTP server (see app.WithStopOrder) so no new connections
+// arrive once it stops.
+type WebSocketShutdown struct {
+	wsService WebSocketService
+	timeout   time.Duration
+}
+
+// NewWebSocketShutdown waits up to websocket.shutdown_timeout for
+// connections to close
+func NewWebSocketShutdown(cfg *viper.Viper, wsService WebSocketService) *WebSocketShutdown {
+	timeout := cfg.GetDuration("websocket.shutdown_timeout")
+	if timeout <= 0 {
+		timeout = defaultWebSocketShutdownTimeout
+	}
+
+	return &WebSocketShutdown{
+		wsService: wsService,
+		timeout:   timeout,
+	}
+}
+
+// Start does nothing; the hub runs from the moment the service is created
+func (w *WebSocketShutdown) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop shuts the WebSocket service down, giving up after the timeout
+func (w *WebSocketShutdown) Stop(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+	return w.wsService.Shutdown(ctx)
+}
@@ -5,14 +5,18 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"slices"
+	"sync"
 	"syscall"
 
 	"github.com/personal/task-management/pkg/server"
 )
 
 type App struct {
-	servers []server.Server
-	name    string
+	servers   []server.Server
+	stopFirst []server.Server
+	name      string
+	stopOnce  sync.Once
 }
 
 type Option func(*App)
@@ -31,39 +35,64 @@ func WithServer(server server.Server) Option {
 	}
 }
 
+// WithStopOrder stops the given servers first, one after another in the given
+// order, before the remaining ones in the order they were registered. An HTTP
+// server listed before the WebSocket shutdown stops accepting upgrades before
+// existing sockets are closed.
+func WithStopOrder(servers ...server.Server) Option {
+	return func(a *App) {
+		a.stopFirst = servers
+	}
+}
+
 func WithName(name string) Option {
 	return func(a *App) {
 		a.name = name
 	}
 }
 
+// Run starts every server, each on its own goroutine since some block until
+// they stop, then waits for SIGINT or SIGTERM and stops them
 func (a *App) Run() error {
 	log.Printf("Starting %s", a.name)
 
-	for _, s := range a.servers {
-		if err := s.Start(context.Background()); err != nil {
-			log.Printf("Failed to start server: %v", err)
-		}
-	}
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
-	<-quit
+	defer signal.Stop(quit)
 
 	for _, s := range a.servers {
-		if err := s.Stop(context.Background()); err != nil {
-			log.Printf("Failed to stop server: %v", err)
-		}
+		go func() {
+			if err := s.Start(context.Background()); err != nil {
+				log.Printf("Failed to start server: %v", err)
+			}
+		}()
 	}
+	<-quit
 
 	log.Printf("Shutting down %s", a.name)
-	return nil
+	return a.Stop()
 }
 
+// Stop stops every server in stop order, waiting for each before the next.
+// Only the first call does anything.
 func (a *App) Stop() error {
+	a.stopOnce.Do(func() {
+		for _, s := range a.stopOrder() {
+			if err := s.Stop(context.Background()); err != nil {
+				log.Printf("Failed to stop server: %v", err)
+			}
+		}
+	})
+	return nil
+}
+
+// stopOrder lists the WithStopOrder servers followed by the rest
+func (a *App) stopOrder() []server.Server {
+	order := slices.Clone(a.stopFirst)
 	for _, s := range a.servers {
-		if err := s.Stop(context.Background()); err != nil {
-			log.Printf("Failed to stop server: %v", err)
+		if !slices.Contains(order, s) {
+			order = append(order, s)
 		}
 	}
-	return nil
+	return order
 }
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// recordingServer notes when it is stopped
+type recordingServer struct {
+	name    string
+	mu      *sync.Mutex
+	stopped *[]string
+}
+
+func (s *recordingServer) Start(ctx context.Context) error { return nil }
+
+func (s *recordingServer) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	*s.stopped = append(*s.stopped, s.name)
+	return nil
+}
+
+type AppTestSuite struct {
+	suite.Suite
+	mu      sync.Mutex
+	stopped []string
+}
+
+func (suite *AppTestSuite) SetupTest() {
+	suite.stopped = nil
+}
+
+func (suite *AppTestSuite) server(name string) *recordingServer {
+	return &recordingServer{name: name, mu: &suite.mu, stopped: &suite.stopped}
+}
+
+func (suite *AppTestSuite) TestStopFollowsRegistrationOrderByDefault() {
+	a := NewApp(WithServer(suite.server("job")), WithServer(suite.server("http")), WithServer(suite.server("websocket")))
+	suite.NoError(a.Stop())
+	suite.Equal([]string{"job", "http", "websocket"}, suite.stopped)
+}
+
+func (suite *AppTestSuite) TestStopOrderComesFirst() {
+	job, http, ws := suite.server("job"), suite.server("http"), suite.server("websocket")
+	a := NewApp(WithServer(job), WithServer(ws), WithServer(http), WithStopOrder(http, ws))
+	suite.NoError(a.Stop())
+	suite.Equal([]string{"http", "websocket", "job"}, suite.stopped)
+}
+
+func (suite *AppTestSuite) TestStopRunsOnce() {
+	a := NewApp(WithServer(suite.server("http")))
+	suite.NoError(a.Stop())
+	suite.NoError(a.Stop())
+	suite.Equal([]string{"http"}, suite.stopped)
+}
+
+func TestAppTestSuite(t *testing.T) {
+	suite.Run(t, new(AppTestSuite))
+}
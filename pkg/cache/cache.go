@@ -20,5 +20,11 @@ type Cache interface {
 	Update(ctx context.Context, key, value any) error
 	Delete(ctx context.Context, key any) error
 	DeleteByPrefix(ctx context.Context, prefix string) error
+	// Clear removes every entry
+	Clear(ctx context.Context) error
+	// Len reports the number of stored entries, including expired ones not yet cleaned up
+	Len() int
+	// Keys lists the string keys of entries that have not expired
+	Keys(ctx context.Context) ([]string, error)
 	Close() error
 }
@@ -171,6 +171,53 @@ func (c *localMemory) DeleteByPrefix(ctx context.Context, prefix string) error {
 	}
 }
 
+func (c *localMemory) Clear(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.store.Range(func(key, _ any) bool {
+			c.store.Delete(key)
+			return true
+		})
+		return nil
+	}
+}
+
+func (c *localMemory) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := 0
+	c.store.Range(func(_, _ any) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+func (c *localMemory) Keys(ctx context.Context) ([]string, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		keys := []string{}
+		c.store.Range(func(key, value any) bool {
+			item := value.(cacheItem)
+			if keyStr, ok := key.(string); ok && !item.isExpired() {
+				keys = append(keys, keyStr)
+			}
+			return true
+		})
+		return keys, nil
+	}
+}
+
 func (c *localMemory) Close() error {
 	close(c.stopChan)
 	c.ticker.Stop()
@@ -191,9 +238,14 @@ func (c *localMemory) startCleanupRoutine() {
 	}
 }
 
+// cleanupExpired holds the lock so an entry refreshed by Set while the sweep
+// runs cannot be deleted on the strength of its old expiry
 func (c *localMemory) cleanupExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	c.store.Range(func(key, value any) bool {
-		if item, ok := value.(*cacheItem); ok && item.isExpired() {
+		if item, ok := value.(cacheItem); ok && item.isExpired() {
 			c.store.Delete(key)
 		}
 		return true
@@ -0,0 +1,71 @@
+package localmemory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/personal/task-management/pkg/cache"
+	"github.com/stretchr/testify/suite"
+)
+
+type LocalMemoryTestSuite struct {
+	suite.Suite
+	cache cache.Cache
+}
+
+func (suite *LocalMemoryTestSuite) SetupTest() {
+	c, err := NewCache(10 * time.Millisecond)
+	suite.Require().NoError(err)
+	suite.cache = c
+}
+
+func (suite *LocalMemoryTestSuite) TearDownTest() {
+	suite.cache.Close()
+}
+
+func (suite *LocalMemoryTestSuite) TestClear() {
+	ctx := context.Background()
+	suite.Require().NoError(suite.cache.Set(ctx, "a", 1))
+	suite.Require().NoError(suite.cache.Set(ctx, "b", 2))
+
+	suite.NoError(suite.cache.Clear(ctx))
+
+	suite.Equal(0, suite.cache.Len())
+	_, err := suite.cache.Get(ctx, "a")
+	suite.ErrorIs(err, cache.ErrKeyNotFound)
+}
+
+// TestCleanupReapsExpiredWithoutGet relies on the background routine alone;
+// Get would also remove the entry lazily and hide a broken sweep
+func (suite *LocalMemoryTestSuite) TestCleanupReapsExpiredWithoutGet() {
+	ctx := context.Background()
+	suite.Require().NoError(suite.cache.Set(ctx, "kept", 1))
+	suite.Require().NoError(suite.cache.SetWithExpire(ctx, "expiring", 2, time.Millisecond))
+	suite.Equal(2, suite.cache.Len())
+
+	// Wait several cleanup intervals
+	time.Sleep(50 * time.Millisecond)
+	suite.Equal(1, suite.cache.Len())
+	keys, err := suite.cache.Keys(ctx)
+	suite.NoError(err)
+	suite.Equal([]string{"kept"}, keys)
+}
+
+func (suite *LocalMemoryTestSuite) TestKeysSkipsExpired() {
+	ctx := context.Background()
+	suite.Require().NoError(suite.cache.Set(ctx, "task:1", 1))
+	suite.Require().NoError(suite.cache.Set(ctx, "task:2", 2))
+	suite.Require().NoError(suite.cache.Set(ctx, 42, "not a string key"))
+	// Store an already-expired entry directly so the cleanup routine cannot race the assertion
+	expired := time.Now().Add(-time.Second)
+	suite.cache.(*localMemory).store.Store("stale", cacheItem{value: 3, expireTime: &expired})
+
+	keys, err := suite.cache.Keys(ctx)
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"task:1", "task:2"}, keys)
+}
+
+func TestLocalMemoryTestSuite(t *testing.T) {
+	suite.Run(t, new(LocalMemoryTestSuite))
+}
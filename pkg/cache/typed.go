@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTypeMismatch is returned by TypedCache when a key holds a value of another type
+var ErrTypeMismatch = errors.New("cached value has unexpected type")
+
+// TypedCache wraps a Cache so values go in and come out as T, without
+// callers asserting types themselves
+type TypedCache[T any] struct {
+	cache Cache
+}
+
+// NewTypedCache returns a typed view of c. Keys are shared with every other
+// user of c.
+func NewTypedCache[T any](c Cache) *TypedCache[T] {
+	return &TypedCache[T]{cache: c}
+}
+
+func (c *TypedCache[T]) Set(ctx context.Context, key any, value T) error {
+	return c.cache.Set(ctx, key, value)
+}
+
+func (c *TypedCache[T]) SetWithExpire(ctx context.Context, key any, value T, expireTime time.Duration) error {
+	return c.cache.SetWithExpire(ctx, key, value, expireTime)
+}
+
+// Get returns the value stored under key, or ErrTypeMismatch when it is not a T
+func (c *TypedCache[T]) Get(ctx context.Context, key any) (T, error) {
+	var zero T
+	value, err := c.cache.Get(ctx, key)
+	if err != nil {
+		return zero, err
+	}
+	typed, ok := value.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w: key %v holds %T, want %T", ErrTypeMismatch, key, value, zero)
+	}
+	return typed, nil
+}
+
+func (c *TypedCache[T]) Delete(ctx context.Context, key any) error {
+	return c.cache.Delete(ctx, key)
+}
+
+func (c *TypedCache[T]) DeleteByPrefix(ctx context.Context, prefix string) error {
+	return c.cache.DeleteByPrefix(ctx, prefix)
+}
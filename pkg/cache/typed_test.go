@@ -0,0 +1,62 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/personal/task-management/internal/domain/task"
+	"github.com/personal/task-management/pkg/cache"
+	localmemory "github.com/personal/task-management/pkg/cache/local-memory"
+	"github.com/stretchr/testify/suite"
+)
+
+type TypedCacheTestSuite struct {
+	suite.Suite
+	store cache.Cache
+	tasks *cache.TypedCache[*task.Task]
+}
+
+func (suite *TypedCacheTestSuite) SetupTest() {
+	store, err := localmemory.NewCache(time.Minute)
+	suite.Require().NoError(err)
+	suite.store = store
+	suite.tasks = cache.NewTypedCache[*task.Task](store)
+}
+
+func (suite *TypedCacheTestSuite) TearDownTest() {
+	suite.store.Close()
+}
+
+func (suite *TypedCacheTestSuite) TestRoundTripsTask() {
+	t, err := task.NewTask("write docs", "", time.Now().Add(time.Hour), uuid.New(), uuid.New())
+	suite.Require().NoError(err)
+
+	suite.NoError(suite.tasks.Set(context.Background(), "task:1", t))
+	got, err := suite.tasks.Get(context.Background(), "task:1")
+	suite.NoError(err)
+	suite.Same(t, got)
+
+	suite.NoError(suite.tasks.SetWithExpire(context.Background(), "task:2", t, time.Minute))
+	got, err = suite.tasks.Get(context.Background(), "task:2")
+	suite.NoError(err)
+	suite.Equal(t.Title, got.Title)
+}
+
+func (suite *TypedCacheTestSuite) TestGetReportsTypeMismatch() {
+	suite.Require().NoError(suite.store.Set(context.Background(), "task:1", "not a task"))
+
+	got, err := suite.tasks.Get(context.Background(), "task:1")
+	suite.ErrorIs(err, cache.ErrTypeMismatch)
+	suite.Nil(got)
+}
+
+func (suite *TypedCacheTestSuite) TestGetPassesThroughMisses() {
+	_, err := suite.tasks.Get(context.Background(), "task:missing")
+	suite.ErrorIs(err, cache.ErrKeyNotFound)
+}
+
+func TestTypedCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(TypedCacheTestSuite))
+}
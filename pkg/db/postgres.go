@@ -43,5 +43,7 @@ func (db *PostgresDB) GetDB() *gorm.DB {
 }
 
 func (db *PostgresDB) MigrateDB() {
-	db.db.AutoMigrate(&user.User{}, &task.Task{}) // basic migration
+	db.db.AutoMigrate(&user.User{}, &task.Task{}, &task.TaskComment{}, &task.TaskEvent{}, &task.Tag{}, &task.TaskTag{}, &task.TaskTemplate{}, &user.Session{}) // basic migration
+	// Serves case-insensitive name prefix searches used by mention autocomplete
+	db.db.Exec("CREATE INDEX IF NOT EXISTS idx_users_name_prefix ON users (LOWER(name) text_pattern_ops)")
 }
@@ -0,0 +1,42 @@
+package features
+
+import "github.com/spf13/viper"
+
+// Feature names a toggle under the features config section
+type Feature string
+
+const (
+	// OfflineEmail emails notifications to users who have been offline for a while
+	OfflineEmail Feature = "offline_email"
+	// Webhooks posts task events to the configured webhook endpoints
+	Webhooks Feature = "webhooks"
+	// RecurringTasks runs the job that spawns the next occurrence of recurring tasks
+	RecurringTasks Feature = "recurring_tasks"
+)
+
+// defaults apply when a deployment leaves a flag unset
+var defaults = map[Feature]bool{
+	OfflineEmail:   false,
+	Webhooks:       true,
+	RecurringTasks: true,
+}
+
+// Flags reports which features are enabled for this deployment
+type Flags struct {
+	cfg *viper.Viper
+}
+
+// NewFlags reads flags from features.<name>
+func NewFlags(cfg *viper.Viper) *Flags {
+	return &Flags{cfg: cfg}
+}
+
+// Enabled reports whether the feature is switched on, falling back to its
+// default when the deployment does not configure it
+func (f *Flags) Enabled(feature Feature) bool {
+	key := "features." + string(feature)
+	if !f.cfg.IsSet(key) {
+		return defaults[feature]
+	}
+	return f.cfg.GetBool(key)
+}
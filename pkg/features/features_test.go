@@ -0,0 +1,37 @@
+package features
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+type FeaturesTestSuite struct {
+	suite.Suite
+}
+
+func (suite *FeaturesTestSuite) TestDefaults() {
+	flags := NewFlags(viper.New())
+	suite.False(flags.Enabled(OfflineEmail))
+	suite.True(flags.Enabled(Webhooks))
+	suite.True(flags.Enabled(RecurringTasks))
+	suite.False(flags.Enabled(Feature("unknown")))
+}
+
+func (suite *FeaturesTestSuite) TestConfiguredFlagsOverrideDefaults() {
+	cfg := viper.New()
+	cfg.Set("features.offline_email", true)
+	cfg.Set("features.webhooks", false)
+	// Values substituted from environment variables arrive as strings
+	cfg.Set("features.recurring_tasks", "false")
+
+	flags := NewFlags(cfg)
+	suite.True(flags.Enabled(OfflineEmail))
+	suite.False(flags.Enabled(Webhooks))
+	suite.False(flags.Enabled(RecurringTasks))
+}
+
+func TestFeaturesTestSuite(t *testing.T) {
+	suite.Run(t, new(FeaturesTestSuite))
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+type contextKey struct{}
+
+// New builds a logger writing to stdout as configured by logging.level
+// (debug, info, warn or error; info if unset) and logging.format (json or
+// text; json if unset)
+func New(cfg *viper.Viper) *slog.Logger {
+	return NewWithWriter(os.Stdout, cfg.GetString("logging.level"), cfg.GetString("logging.format"))
+}
+
+// NewWithWriter builds a logger writing to w at the given level and format
+func NewWithWriter(w io.Writer, level, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: ParseLevel(level)}
+	if strings.EqualFold(format, "text") {
+		return slog.New(slog.NewTextHandler(w, opts))
+	}
+	return slog.New(slog.NewJSONHandler(w, opts))
+}
+
+// ParseLevel reads a level name, ignoring case, falling back to info for
+// anything it does not recognise
+func ParseLevel(level string) slog.Level {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return parsed
+}
+
+// WithContext stores the logger on the context for FromContext
+func WithContext(ctx context.Context, log *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, log)
+}
+
+// FromContext returns the logger stored by WithContext, which for requests
+// carries their request ID, or the default logger when there is none
+func FromContext(ctx context.Context) *slog.Logger {
+	if log, ok := ctx.Value(contextKey{}).(*slog.Logger); ok && log != nil {
+		return log
+	}
+	return slog.Default()
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LoggerTestSuite struct {
+	suite.Suite
+}
+
+func (suite *LoggerTestSuite) TestParseLevel() {
+	suite.Equal(slog.LevelDebug, ParseLevel("debug"))
+	suite.Equal(slog.LevelWarn, ParseLevel("WARN"))
+	suite.Equal(slog.LevelError, ParseLevel("error"))
+	suite.Equal(slog.LevelInfo, ParseLevel(""))
+	suite.Equal(slog.LevelInfo, ParseLevel("verbose"))
+}
+
+func (suite *LoggerTestSuite) TestFormatAndLevel() {
+	var out bytes.Buffer
+	log := NewWithWriter(&out, "warn", "json")
+	log.Info("dropped")
+	log.Warn("kept", "room_id", "room-1")
+
+	var record map[string]any
+	suite.Require().NoError(json.Unmarshal(out.Bytes(), &record))
+	suite.Equal("kept", record["msg"])
+	suite.Equal("room-1", record["room_id"])
+
+	out.Reset()
+	NewWithWriter(&out, "info", "text").Info("hello", "user_id", "user-1")
+	suite.True(strings.Contains(out.String(), "msg=hello user_id=user-1"), out.String())
+}
+
+func (suite *LoggerTestSuite) TestContext() {
+	suite.Same(slog.Default(), FromContext(context.Background()))
+
+	log := NewWithWriter(&bytes.Buffer{}, "info", "json")
+	suite.Same(log, FromContext(WithContext(context.Background(), log)))
+}
+
+func TestLoggerTestSuite(t *testing.T) {
+	suite.Run(t, new(LoggerTestSuite))
+}
@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter keeps a token bucket per key. Each bucket holds up to the per-minute
+// limit and refills continuously, so a key can burst to the limit and then
+// sends at the refill rate.
+type Limiter struct {
+	mu       sync.Mutex
+	buckets  map[string]*bucket
+	capacity float64
+	// interval is how long one token takes to refill
+	interval time.Duration
+	now      func() time.Time
+
+	ticker   *time.Ticker
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+type bucket struct {
+	tokens float64
+	// updated is when tokens was last brought up to date
+	updated time.Time
+}
+
+type Option func(*Limiter)
+
+// WithClock makes the limiter read the time from now instead of time.Now
+func WithClock(now func() time.Time) Option {
+	return func(l *Limiter) {
+		l.now = now
+	}
+}
+
+// NewLimiter allows perMinute events per key per minute, and every
+// cleanupInterval, a minute if unset, forgets keys whose buckets have
+// refilled completely
+func NewLimiter(perMinute int, cleanupInterval time.Duration, opts ...Option) *Limiter {
+	perMinute = max(perMinute, 1)
+	if cleanupInterval <= 0 {
+		cleanupInterval = time.Minute
+	}
+	l := &Limiter{
+		buckets:  make(map[string]*bucket),
+		capacity: float64(perMinute),
+		interval: time.Minute / time.Duration(perMinute),
+		now:      time.Now,
+		ticker:   time.NewTicker(cleanupInterval),
+		stopChan: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	l.wg.Add(1)
+	go l.startCleanupRoutine()
+	return l
+}
+
+// Allow takes a token from the key's bucket. When the bucket is empty it
+// returns false along with how long until a token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &bucket{tokens: l.capacity, updated: now}
+		l.buckets[key] = b
+	}
+	l.refill(b, now)
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) * float64(l.interval))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// Close stops the cleanup routine; repeated calls do nothing
+func (l *Limiter) Close() error {
+	l.stopOnce.Do(func() {
+		close(l.stopChan)
+		l.ticker.Stop()
+	})
+	l.wg.Wait()
+	return nil
+}
+
+func (l *Limiter) refill(b *bucket, now time.Time) {
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = min(l.capacity, b.tokens+float64(elapsed)/float64(l.interval))
+		b.updated = now
+	}
+}
+
+func (l *Limiter) startCleanupRoutine() {
+	defer l.wg.Done()
+
+	for {
+		select {
+		case <-l.ticker.C:
+			l.cleanup()
+		case <-l.stopChan:
+			return
+		}
+	}
+}
+
+// cleanup drops full buckets, which behave exactly like the new bucket an
+// unknown key gets
+func (l *Limiter) cleanup() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for key, b := range l.buckets {
+		l.refill(b, now)
+		if b.tokens >= l.capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
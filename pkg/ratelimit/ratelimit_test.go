@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LimiterTestSuite struct {
+	suite.Suite
+	mu      sync.Mutex
+	now     time.Time
+	limiter *Limiter
+}
+
+func (suite *LimiterTestSuite) SetupTest() {
+	suite.now = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	suite.limiter = NewLimiter(3, time.Hour, WithClock(suite.clock))
+	suite.T().Cleanup(func() { suite.limiter.Close() })
+}
+
+func (suite *LimiterTestSuite) clock() time.Time {
+	suite.mu.Lock()
+	defer suite.mu.Unlock()
+	return suite.now
+}
+
+func (suite *LimiterTestSuite) advance(d time.Duration) {
+	suite.mu.Lock()
+	defer suite.mu.Unlock()
+	suite.now = suite.now.Add(d)
+}
+
+func (suite *LimiterTestSuite) TestBurstThenRefill() {
+	for i := 0; i < 3; i++ {
+		allowed, _ := suite.limiter.Allow("user-1")
+		suite.True(allowed, i)
+	}
+	allowed, wait := suite.limiter.Allow("user-1")
+	suite.False(allowed)
+	suite.Equal(20*time.Second, wait)
+
+	// Other keys have their own bucket
+	allowed, _ = suite.limiter.Allow("user-2")
+	suite.True(allowed)
+
+	// A token refills every 20 seconds
+	suite.advance(15 * time.Second)
+	allowed, wait = suite.limiter.Allow("user-1")
+	suite.False(allowed)
+	suite.Equal(5*time.Second, wait)
+	suite.advance(5 * time.Second)
+	allowed, _ = suite.limiter.Allow("user-1")
+	suite.True(allowed)
+	allowed, _ = suite.limiter.Allow("user-1")
+	suite.False(allowed)
+
+	// A full minute refills the whole bucket, but no more
+	suite.advance(10 * time.Minute)
+	for i := 0; i < 3; i++ {
+		allowed, _ := suite.limiter.Allow("user-1")
+		suite.True(allowed, i)
+	}
+	allowed, _ = suite.limiter.Allow("user-1")
+	suite.False(allowed)
+}
+
+func (suite *LimiterTestSuite) TestCleanupDropsFullBuckets() {
+	suite.limiter.Allow("user-1")
+	suite.limiter.Allow("user-2")
+	suite.advance(40 * time.Second)
+	suite.limiter.Allow("user-2")
+
+	suite.limiter.cleanup()
+	suite.limiter.mu.Lock()
+	defer suite.limiter.mu.Unlock()
+	suite.NotContains(suite.limiter.buckets, "user-1")
+	suite.Contains(suite.limiter.buckets, "user-2")
+}
+
+func TestLimiterTestSuite(t *testing.T) {
+	suite.Run(t, new(LimiterTestSuite))
+}
@@ -11,21 +11,30 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
+const defaultShutdownTimeout = 5 * time.Second
+
 type Server struct {
 	*chi.Mux
-	httpSrv *http.Server
-	host    string
-	port    int
+	httpSrv         *http.Server
+	host            string
+	port            int
+	shutdownTimeout time.Duration
 }
 type Option func(s *Server)
 
 func NewServer(engine *chi.Mux, opts ...Option) *Server {
 	s := &Server{
-		Mux: engine,
+		Mux:             engine,
+		shutdownTimeout: defaultShutdownTimeout,
 	}
 	for _, opt := range opts {
 		opt(s)
 	}
+	// Created up front so Stop never races Start, even if it runs first
+	s.httpSrv = &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", s.host, s.port),
+		Handler: s,
+	}
 	return s
 }
 
@@ -40,27 +49,34 @@ func WithServerPort(port int) Option {
 	}
 }
 
-func (s *Server) Start(ctx context.Context) error {
-	s.httpSrv = &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", s.host, s.port),
-		Handler: s,
+// WithShutdownTimeout bounds how long Stop waits for in-flight requests
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		if timeout > 0 {
+			s.shutdownTimeout = timeout
+		}
 	}
+}
 
+// Start blocks serving requests until Stop is called
+func (s *Server) Start(ctx context.Context) error {
 	if err := s.httpSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("listen: %s\n", err)
 	}
 
 	return nil
 }
+
+// Stop closes the listeners, so no new connections or WebSocket upgrades are
+// accepted, then waits for in-flight requests up to the shutdown timeout.
+// Hijacked connections such as WebSockets are not waited for.
 func (s *Server) Stop(ctx context.Context) error {
 	log.Println("Shutting down server...")
 
-	// The context is used to inform the server it has 5 seconds to finish
-	// the request it is currently handling
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, s.shutdownTimeout)
 	defer cancel()
 	if err := s.httpSrv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %s", err)
+		return fmt.Errorf("server forced to shutdown: %w", err)
 	}
 
 	log.Println("Server exiting")
@@ -0,0 +1,75 @@
+package localdisk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/personal/task-management/pkg/storage"
+)
+
+// Storage writes files into a directory and serves them back over HTTP
+type Storage struct {
+	dir     string
+	baseURL string
+	files   http.Handler
+}
+
+// NewStorage creates dir if needed. Saved files are reported under baseURL,
+// which should point at wherever the Storage's handler is mounted.
+func NewStorage(dir, baseURL string) (*Storage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Storage{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		files:   http.FileServer(http.Dir(dir)),
+	}, nil
+}
+
+// Save refuses names that would leave the directory and never overwrites an
+// existing file
+func (s *Storage) Save(ctx context.Context, filename string, r io.Reader) (string, error) {
+	if filename == "" || filename != filepath.Base(filename) || filename == "." || filename == ".." {
+		return "", storage.ErrInvalidFilename
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(s.dir, filename)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return "", storage.ErrFileExists
+		}
+		return "", err
+	}
+
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return s.baseURL + "/" + url.PathEscape(filename), nil
+}
+
+// ServeHTTP serves saved files by name. Directory listings are not exposed.
+func (s *Storage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	s.files.ServeHTTP(w, r)
+}
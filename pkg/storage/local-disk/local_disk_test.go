@@ -0,0 +1,68 @@
+package localdisk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/personal/task-management/pkg/storage"
+	"github.com/stretchr/testify/suite"
+)
+
+type LocalDiskTestSuite struct {
+	suite.Suite
+	dir     string
+	storage *Storage
+}
+
+func (suite *LocalDiskTestSuite) SetupTest() {
+	suite.dir = filepath.Join(suite.T().TempDir(), "uploads")
+	s, err := NewStorage(suite.dir, "http://files.example.com/uploads/")
+	suite.Require().NoError(err)
+	suite.storage = s
+}
+
+func (suite *LocalDiskTestSuite) TestSaveAndServe() {
+	fileURL, err := suite.storage.Save(context.Background(), "report 1.txt", strings.NewReader("quarterly numbers"))
+	suite.Require().NoError(err)
+	suite.Equal("http://files.example.com/uploads/report%201.txt", fileURL)
+
+	rec := httptest.NewRecorder()
+	suite.storage.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/report%201.txt", nil))
+	suite.Equal(http.StatusOK, rec.Code)
+	suite.Equal("quarterly numbers", rec.Body.String())
+
+	// The directory itself is not listed
+	rec = httptest.NewRecorder()
+	suite.storage.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	suite.Equal(http.StatusNotFound, rec.Code)
+}
+
+func (suite *LocalDiskTestSuite) TestSaveRejectsPathsOutsideDir() {
+	for _, name := range []string{"", ".", "..", "../escape.txt", "nested/file.txt"} {
+		_, err := suite.storage.Save(context.Background(), name, strings.NewReader("x"))
+		suite.ErrorIs(err, storage.ErrInvalidFilename, name)
+	}
+	_, err := os.Stat(filepath.Join(filepath.Dir(suite.dir), "escape.txt"))
+	suite.True(os.IsNotExist(err))
+}
+
+func (suite *LocalDiskTestSuite) TestSaveNeverOverwrites() {
+	_, err := suite.storage.Save(context.Background(), "a.txt", strings.NewReader("first"))
+	suite.Require().NoError(err)
+
+	_, err = suite.storage.Save(context.Background(), "a.txt", strings.NewReader("second"))
+	suite.ErrorIs(err, storage.ErrFileExists)
+
+	content, err := os.ReadFile(filepath.Join(suite.dir, "a.txt"))
+	suite.Require().NoError(err)
+	suite.Equal("first", string(content))
+}
+
+func TestLocalDiskTestSuite(t *testing.T) {
+	suite.Run(t, new(LocalDiskTestSuite))
+}
@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	ErrInvalidFilename = errors.New("invalid filename")
+	ErrFileExists      = errors.New("file already exists")
+)
+
+// Storage keeps uploaded files somewhere clients can fetch them from
+type Storage interface {
+	// Save stores the contents of r under filename and returns the URL the
+	// file is served from
+	Save(ctx context.Context, filename string, r io.Reader) (string, error)
+}
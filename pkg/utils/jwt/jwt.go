@@ -1,74 +1,235 @@
 package jwt
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/personal/task-management/pkg/cache"
 	"github.com/spf13/viper"
 )
 
 // Common JWT errors
 var (
-	ErrInvalidToken = errors.New("invalid token")
-	ErrExpiredToken = errors.New("token has expired")
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrExpiredToken   = errors.New("token has expired")
+	ErrWrongTokenType = errors.New("wrong token type")
+	ErrRevokedToken   = errors.New("token has been revoked")
 )
 
+// Token types carried in the token_type claim
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// TokenTypeBearer is the OAuth token_type clients send access tokens as
+const TokenTypeBearer = "Bearer"
+
+// defaultRefreshDuration applies when auth.refresh_expiration is unset
+const defaultRefreshDuration = 7 * 24 * time.Hour
+
+// revokedTokenPrefix namespaces denylisted token IDs in the shared cache
+const revokedTokenPrefix = "revoked_token:"
+
 // JWTTokenServicer defines the interface for JWT token operations
 type JWTTokenServicer interface {
 	GenerateToken(userID uuid.UUID, email string, role string) (string, error)
+	IssueToken(userID uuid.UUID, email string, role string) (*IssuedToken, error)
+	IssueSessionToken(userID uuid.UUID, email string, role string, sessionID string) (*IssuedToken, error)
 	ValidateToken(tokenString string) (*UserClaims, error)
+	GenerateRefreshToken(userID uuid.UUID, email string, role string) (string, error)
+	IssueRefreshToken(userID uuid.UUID, email string, role string) (*IssuedToken, error)
+	ValidateRefreshToken(refreshToken string) (*UserClaims, error)
+	RefreshAccessToken(refreshToken string) (string, error)
+	RevokeToken(claims *UserClaims) error
 }
 
 // JWTTokenService handles JWT token generation and validation
 type JWTTokenService struct {
-	secretKey     []byte
-	tokenDuration time.Duration
+	secretKey       []byte
+	tokenDuration   time.Duration
+	refreshDuration time.Duration
+
+	// denylist holds the IDs of revoked tokens until they would have expired
+	denylist cache.Cache
 }
 
 // NewJWTTokenService creates a new instance of JWTTokenService
-func NewJWTTokenService(cfg *viper.Viper) JWTTokenServicer {
+func NewJWTTokenService(cfg *viper.Viper, denylist cache.Cache) JWTTokenServicer {
+	refreshDuration := cfg.GetDuration("auth.refresh_expiration")
+	if refreshDuration <= 0 {
+		refreshDuration = defaultRefreshDuration
+	}
+
 	return &JWTTokenService{
-		secretKey:     []byte(cfg.GetString("auth.jwt_secret")),
-		tokenDuration: cfg.GetDuration("auth.jwt_expiration"),
+		secretKey:       []byte(cfg.GetString("auth.jwt_secret")),
+		tokenDuration:   cfg.GetDuration("auth.jwt_expiration"),
+		refreshDuration: refreshDuration,
+		denylist:        denylist,
 	}
 }
 
 // UserClaims represents the JWT claims for a user
 type UserClaims struct {
 	jwt.RegisteredClaims
-	UserID uuid.UUID `json:"user_id"`
-	Email  string    `json:"email"`
-	Role   string    `json:"role"`
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	TokenType string    `json:"token_type"`
+	// SessionID links an access token to the login session it was issued
+	// for, so logging out can end that session
+	SessionID string `json:"sid,omitempty"`
 }
 
-// GenerateToken generates a new JWT token for a user
+// IssuedToken is a signed token along with its ID and when it was issued
+// and expires
+type IssuedToken struct {
+	ID        string
+	Token     string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// GenerateToken generates a new access token for a user
 func (s *JWTTokenService) GenerateToken(userID uuid.UUID, email string, role string) (string, error) {
-	// Create the claims
-	now := time.Now()
+	issued, err := s.IssueToken(userID, email, role)
+	if err != nil {
+		return "", err
+	}
+	return issued.Token, nil
+}
+
+// IssueToken generates a new access token and reports its lifetime so
+// clients can schedule a refresh
+func (s *JWTTokenService) IssueToken(userID uuid.UUID, email string, role string) (*IssuedToken, error) {
+	return s.issue(userID, email, role, TokenTypeAccess, "", s.tokenDuration)
+}
+
+// IssueSessionToken generates an access token bound to a login session
+func (s *JWTTokenService) IssueSessionToken(userID uuid.UUID, email string, role string, sessionID string) (*IssuedToken, error) {
+	return s.issue(userID, email, role, TokenTypeAccess, sessionID, s.tokenDuration)
+}
+
+// GenerateRefreshToken generates a longer-lived token that can only be
+// exchanged for new access tokens
+func (s *JWTTokenService) GenerateRefreshToken(userID uuid.UUID, email string, role string) (string, error) {
+	return s.sign(userID, email, role, TokenTypeRefresh, s.refreshDuration)
+}
+
+// IssueRefreshToken generates a refresh token and reports its ID and
+// lifetime so the caller can track it
+func (s *JWTTokenService) IssueRefreshToken(userID uuid.UUID, email string, role string) (*IssuedToken, error) {
+	return s.issue(userID, email, role, TokenTypeRefresh, "", s.refreshDuration)
+}
+
+// ValidateRefreshToken validates a refresh token and returns the claims
+func (s *JWTTokenService) ValidateRefreshToken(refreshToken string) (*UserClaims, error) {
+	claims, err := s.parse(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != TokenTypeRefresh {
+		return nil, ErrWrongTokenType
+	}
+	return claims, nil
+}
+
+// RefreshAccessToken issues a new access token for the refresh token's user
+func (s *JWTTokenService) RefreshAccessToken(refreshToken string) (string, error) {
+	claims, err := s.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return "", err
+	}
+	return s.GenerateToken(claims.UserID, claims.Email, claims.Role)
+}
+
+// ValidateToken validates an access token and returns the claims
+func (s *JWTTokenService) ValidateToken(tokenString string) (*UserClaims, error) {
+	claims, err := s.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	// Tokens issued before token types existed carry none and are access tokens
+	if claims.TokenType != TokenTypeAccess && claims.TokenType != "" {
+		return nil, ErrWrongTokenType
+	}
+	return claims, nil
+}
+
+// RevokeToken denylists the token until its natural expiry
+func (s *JWTTokenService) RevokeToken(claims *UserClaims) error {
+	// Tokens issued before token IDs existed cannot be told apart
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return ErrInvalidToken
+	}
+
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.denylist.SetWithExpire(context.Background(), revokedTokenKey(claims.ID), true, ttl)
+}
+
+func (s *JWTTokenService) isRevoked(tokenID string) bool {
+	if tokenID == "" {
+		return false
+	}
+	_, err := s.denylist.Get(context.Background(), revokedTokenKey(tokenID))
+	return err == nil
+}
+
+func revokedTokenKey(tokenID string) string {
+	return revokedTokenPrefix + tokenID
+}
+
+func (s *JWTTokenService) sign(userID uuid.UUID, email, role, tokenType string, duration time.Duration) (string, error) {
+	issued, err := s.issue(userID, email, role, tokenType, "", duration)
+	if err != nil {
+		return "", err
+	}
+	return issued.Token, nil
+}
+
+func (s *JWTTokenService) issue(userID uuid.UUID, email, role, tokenType, sessionID string, duration time.Duration) (*IssuedToken, error) {
+	// Create the claims; the JWT encodes times in whole seconds, so truncate
+	// here to report exactly what the token carries
+	now := time.Now().Truncate(time.Second)
 	claims := UserClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(s.tokenDuration)),
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Subject:   userID.String(),
 		},
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		TokenType: tokenType,
+		SessionID: sessionID,
 	}
 
 	// Create the token
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// Sign the token
-	return token.SignedString(s.secretKey)
+	signed, err := token.SignedString(s.secretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &IssuedToken{
+		ID:        claims.ID,
+		Token:     signed,
+		IssuedAt:  claims.IssuedAt.Time,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
 }
 
-// ValidateToken validates a JWT token and returns the claims
-func (s *JWTTokenService) ValidateToken(tokenString string) (*UserClaims, error) {
+func (s *JWTTokenService) parse(tokenString string) (*UserClaims, error) {
 	// Parse the token
 	token, err := jwt.ParseWithClaims(tokenString, &UserClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// Validate signing method
@@ -77,7 +238,6 @@ func (s *JWTTokenService) ValidateToken(tokenString string) (*UserClaims, error)
 		}
 		return s.secretKey, nil
 	})
-
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, ErrExpiredToken
@@ -96,5 +256,9 @@ func (s *JWTTokenService) ValidateToken(tokenString string) (*UserClaims, error)
 		return nil, ErrInvalidToken
 	}
 
+	if s.isRevoked(claims.ID) {
+		return nil, ErrRevokedToken
+	}
+
 	return claims, nil
 }
@@ -4,21 +4,33 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/personal/task-management/pkg/cache"
+	localmemory "github.com/personal/task-management/pkg/cache/local-memory"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/suite"
 )
 
 type JWTTestSuite struct {
 	suite.Suite
-	service JWTTokenServicer
+	denylist cache.Cache
+	service  JWTTokenServicer
 }
 
 func (suite *JWTTestSuite) SetupTest() {
 	cfg := viper.New()
 	cfg.Set("auth.jwt_secret", "test_secret_key")
 	cfg.Set("auth.jwt_expiration", time.Hour)
-	suite.service = NewJWTTokenService(cfg)
+
+	denylist, err := localmemory.NewCache(time.Minute)
+	suite.Require().NoError(err)
+	suite.denylist = denylist
+	suite.service = NewJWTTokenService(cfg, denylist)
+}
+
+func (suite *JWTTestSuite) TearDownTest() {
+	suite.denylist.Close()
 }
 
 func (suite *JWTTestSuite) TestGenerateToken() {
@@ -38,6 +50,17 @@ func (suite *JWTTestSuite) TestGenerateToken() {
 	suite.Equal(role, claims.Role)
 }
 
+func (suite *JWTTestSuite) TestIssueSessionToken() {
+	sessionID := uuid.NewString()
+	issued, err := suite.service.IssueSessionToken(uuid.New(), "test@example.com", "employee", sessionID)
+	suite.Require().NoError(err)
+
+	claims, err := suite.service.ValidateToken(issued.Token)
+	suite.Require().NoError(err)
+	suite.Equal(sessionID, claims.SessionID)
+	suite.Equal(TokenTypeAccess, claims.TokenType)
+}
+
 func (suite *JWTTestSuite) TestValidateToken() {
 	// Generate a valid token
 	userID := uuid.New()
@@ -59,6 +82,119 @@ func (suite *JWTTestSuite) TestValidateToken() {
 	suite.Equal(ErrInvalidToken, err)
 }
 
+func (suite *JWTTestSuite) TestIssueTokenReportsLifetime() {
+	issued, err := suite.service.IssueToken(uuid.New(), "test@example.com", "employee")
+	suite.Require().NoError(err)
+	suite.Equal(time.Hour, issued.ExpiresAt.Sub(issued.IssuedAt))
+
+	claims, err := suite.service.ValidateToken(issued.Token)
+	suite.Require().NoError(err)
+	suite.True(claims.IssuedAt.Time.Equal(issued.IssuedAt))
+	suite.True(claims.ExpiresAt.Time.Equal(issued.ExpiresAt))
+}
+
+func (suite *JWTTestSuite) TestRefreshAccessToken() {
+	userID := uuid.New()
+	refreshToken, err := suite.service.GenerateRefreshToken(userID, "test@example.com", "employer")
+	suite.Require().NoError(err)
+
+	accessToken, err := suite.service.RefreshAccessToken(refreshToken)
+	suite.NoError(err)
+
+	claims, err := suite.service.ValidateToken(accessToken)
+	suite.NoError(err)
+	suite.Equal(userID, claims.UserID)
+	suite.Equal("test@example.com", claims.Email)
+	suite.Equal("employer", claims.Role)
+	suite.Equal(TokenTypeAccess, claims.TokenType)
+}
+
+func (suite *JWTTestSuite) TestRefreshAccessTokenExpired() {
+	service := suite.service.(*JWTTokenService)
+	expired, err := service.sign(uuid.New(), "test@example.com", "employee", TokenTypeRefresh, -time.Minute)
+	suite.Require().NoError(err)
+
+	_, err = suite.service.RefreshAccessToken(expired)
+	suite.ErrorIs(err, ErrExpiredToken)
+}
+
+func (suite *JWTTestSuite) TestTokenTypesAreNotInterchangeable() {
+	userID := uuid.New()
+	accessToken, err := suite.service.GenerateToken(userID, "test@example.com", "employee")
+	suite.Require().NoError(err)
+	refreshToken, err := suite.service.GenerateRefreshToken(userID, "test@example.com", "employee")
+	suite.Require().NoError(err)
+
+	_, err = suite.service.RefreshAccessToken(accessToken)
+	suite.ErrorIs(err, ErrWrongTokenType)
+
+	_, err = suite.service.ValidateToken(refreshToken)
+	suite.ErrorIs(err, ErrWrongTokenType)
+}
+
+func (suite *JWTTestSuite) TestRefreshTokenOutlivesAccessToken() {
+	refreshToken, err := suite.service.GenerateRefreshToken(uuid.New(), "test@example.com", "employee")
+	suite.Require().NoError(err)
+	accessToken, err := suite.service.GenerateToken(uuid.New(), "test@example.com", "employee")
+	suite.Require().NoError(err)
+
+	service := suite.service.(*JWTTokenService)
+	refreshClaims, err := service.parse(refreshToken)
+	suite.Require().NoError(err)
+	accessClaims, err := service.parse(accessToken)
+	suite.Require().NoError(err)
+	suite.Equal(defaultRefreshDuration, refreshClaims.ExpiresAt.Sub(refreshClaims.IssuedAt.Time))
+	suite.True(refreshClaims.ExpiresAt.After(accessClaims.ExpiresAt.Time))
+}
+
+func (suite *JWTTestSuite) TestRevokedTokenIsRejected() {
+	userID := uuid.New()
+	token, err := suite.service.GenerateToken(userID, "test@example.com", "employee")
+	suite.Require().NoError(err)
+	claims, err := suite.service.ValidateToken(token)
+	suite.Require().NoError(err)
+	suite.NotEmpty(claims.ID)
+
+	suite.Require().NoError(suite.service.RevokeToken(claims))
+
+	_, err = suite.service.ValidateToken(token)
+	suite.ErrorIs(err, ErrRevokedToken)
+
+	// A token issued afterwards carries its own ID and is unaffected
+	fresh, err := suite.service.GenerateToken(userID, "test@example.com", "employee")
+	suite.Require().NoError(err)
+	freshClaims, err := suite.service.ValidateToken(fresh)
+	suite.NoError(err)
+	suite.NotEqual(claims.ID, freshClaims.ID)
+}
+
+func (suite *JWTTestSuite) TestRevokedRefreshTokenCannotRefresh() {
+	refreshToken, err := suite.service.GenerateRefreshToken(uuid.New(), "test@example.com", "employee")
+	suite.Require().NoError(err)
+	claims, err := suite.service.(*JWTTokenService).parse(refreshToken)
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(suite.service.RevokeToken(claims))
+
+	_, err = suite.service.RefreshAccessToken(refreshToken)
+	suite.ErrorIs(err, ErrRevokedToken)
+}
+
+func (suite *JWTTestSuite) TestRevokeExpiredTokenIsNoop() {
+	claims := &UserClaims{RegisteredClaims: jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+	}}
+
+	suite.NoError(suite.service.RevokeToken(claims))
+	suite.Equal(0, suite.denylist.Len())
+}
+
+func (suite *JWTTestSuite) TestRevokeTokenWithoutID() {
+	err := suite.service.RevokeToken(&UserClaims{})
+	suite.ErrorIs(err, ErrInvalidToken)
+}
+
 func TestJWTTestSuite(t *testing.T) {
 	suite.Run(t, new(JWTTestSuite))
 }
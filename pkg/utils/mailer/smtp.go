@@ -0,0 +1,31 @@
+package mailer
+
+import (
+	"fmt"
+	"net/smtp"
+
+	"github.com/spf13/viper"
+)
+
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(cfg *viper.Viper) *SMTPMailer {
+	host := cfg.GetString("notifications.smtp.host")
+	m := &SMTPMailer{
+		addr: fmt.Sprintf("%s:%d", host, cfg.GetInt("notifications.smtp.port")),
+		from: cfg.GetString("notifications.smtp.from"),
+	}
+	if username := cfg.GetString("notifications.smtp.username"); username != "" {
+		m.auth = smtp.PlainAuth("", username, cfg.GetString("notifications.smtp.password"), host)
+	}
+	return m
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n", m.from, to, subject, body)
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(msg))
+}
@@ -0,0 +1,83 @@
+package pagination
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultLimit    = 20
+	defaultMaxLimit = 100
+)
+
+var (
+	ErrInvalidLimit  = errors.New("invalid limit")
+	ErrInvalidOffset = errors.New("invalid offset")
+)
+
+// Paginator applies the page size defaults shared by every list endpoint
+type Paginator struct {
+	defaultLimit int
+	maxLimit     int
+}
+
+// NewPaginator reads pagination.default_limit and pagination.max_limit
+func NewPaginator(cfg *viper.Viper) *Paginator {
+	p := &Paginator{
+		defaultLimit: cfg.GetInt("pagination.default_limit"),
+		maxLimit:     cfg.GetInt("pagination.max_limit"),
+	}
+	if p.maxLimit <= 0 {
+		p.maxLimit = defaultMaxLimit
+	}
+	if p.defaultLimit <= 0 {
+		p.defaultLimit = defaultLimit
+	}
+	if p.defaultLimit > p.maxLimit {
+		p.defaultLimit = p.maxLimit
+	}
+	return p
+}
+
+// DefaultLimit returns the page size used when none is requested
+func (p *Paginator) DefaultLimit() int {
+	return p.defaultLimit
+}
+
+// Limit returns the default page size for a missing limit and caps the rest
+func (p *Paginator) Limit(limit int) int {
+	if limit <= 0 {
+		return p.defaultLimit
+	}
+	if limit > p.maxLimit {
+		return p.maxLimit
+	}
+	return limit
+}
+
+// Offset clamps negative offsets to the first page
+func (p *Paginator) Offset(offset int) int {
+	if offset < 0 {
+		return 0
+	}
+	return offset
+}
+
+// Parse reads the limit and offset query parameters of a list request
+func (p *Paginator) Parse(r *http.Request) (limit, offset int, err error) {
+	query := r.URL.Query()
+	if value := query.Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil {
+			return 0, 0, ErrInvalidLimit
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil {
+			return 0, 0, ErrInvalidOffset
+		}
+	}
+	return p.Limit(limit), p.Offset(offset), nil
+}
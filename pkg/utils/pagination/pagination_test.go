@@ -0,0 +1,56 @@
+package pagination
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+type PaginationTestSuite struct {
+	suite.Suite
+	paginator *Paginator
+}
+
+func (suite *PaginationTestSuite) SetupTest() {
+	cfg := viper.New()
+	cfg.Set("pagination.default_limit", 20)
+	cfg.Set("pagination.max_limit", 50)
+	suite.paginator = NewPaginator(cfg)
+}
+
+func (suite *PaginationTestSuite) TestDefaults() {
+	paginator := NewPaginator(viper.New())
+	suite.Equal(defaultLimit, paginator.DefaultLimit())
+	suite.Equal(defaultMaxLimit, paginator.Limit(1000))
+}
+
+func (suite *PaginationTestSuite) TestLimit() {
+	suite.Equal(20, suite.paginator.Limit(0))
+	suite.Equal(20, suite.paginator.Limit(-5))
+	suite.Equal(30, suite.paginator.Limit(30))
+	suite.Equal(50, suite.paginator.Limit(51))
+}
+
+func (suite *PaginationTestSuite) TestParse() {
+	limit, offset, err := suite.paginator.Parse(httptest.NewRequest("GET", "/items", nil))
+	suite.NoError(err)
+	suite.Equal(20, limit)
+	suite.Equal(0, offset)
+
+	limit, offset, err = suite.paginator.Parse(httptest.NewRequest("GET", "/items?limit=500&offset=-3", nil))
+	suite.NoError(err)
+	suite.Equal(50, limit)
+	suite.Equal(0, offset)
+
+	_, _, err = suite.paginator.Parse(httptest.NewRequest("GET", "/items?limit=ten", nil))
+	suite.ErrorIs(err, ErrInvalidLimit)
+
+	_, _, err = suite.paginator.Parse(httptest.NewRequest("GET", "/items?offset=x", nil))
+	suite.ErrorIs(err, ErrInvalidOffset)
+}
+
+func TestPaginationTestSuite(t *testing.T) {
+	suite.Run(t, new(PaginationTestSuite))
+}
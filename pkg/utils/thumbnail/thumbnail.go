@@ -0,0 +1,77 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif" // registers the GIF decoder
+	"image/jpeg"
+	_ "image/png" // registers the PNG decoder
+	"io"
+)
+
+const (
+	jpegQuality = 80
+	// maxPixels keeps a tiny file that declares huge dimensions from being
+	// decoded into gigabytes of memory
+	maxPixels = 50_000_000
+)
+
+var ErrImageTooLarge = errors.New("image dimensions too large")
+
+// JPEG decodes a GIF, JPEG or PNG image and re-encodes it as a JPEG whose
+// longest side is at most maxSize pixels. Smaller images keep their size.
+func JPEG(r io.ReadSeeker, maxSize int) ([]byte, error) {
+	config, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, ErrImageTooLarge
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	src, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := src.Bounds()
+	width, height := scaledSize(bounds.Dx(), bounds.Dy(), maxSize)
+
+	// Nearest-neighbour sampling is plenty for a preview
+	scaled := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		sy := bounds.Min.Y + y*bounds.Dy()/height
+		for x := 0; x < width; x++ {
+			sx := bounds.Min.X + x*bounds.Dx()/width
+			scaled.Set(x, y, src.At(sx, sy))
+		}
+	}
+
+	// JPEG has no alpha channel, so transparent pixels are laid over white
+	dst := image.NewRGBA(scaled.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), scaled, image.Point{}, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// scaledSize fits width x height inside maxSize x maxSize, keeping the aspect
+// ratio and at least one pixel per side
+func scaledSize(width, height, maxSize int) (int, int) {
+	if maxSize <= 0 || (width <= maxSize && height <= maxSize) {
+		return max(width, 1), max(height, 1)
+	}
+	if width >= height {
+		return maxSize, max(height*maxSize/width, 1)
+	}
+	return max(width*maxSize/height, 1), maxSize
+}
@@ -1,11 +1,35 @@
 package validate
 
 import (
+	"unicode"
+
 	"github.com/go-playground/validator/v10"
 )
 
-var validate = validator.New()
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	// Registering a tag only fails for an empty name or nil function
+	_ = v.RegisterValidation("password", strongPassword)
+	return v
+}
 
 func Struct(s any) error {
 	return validate.Struct(s)
 }
+
+// strongPassword backs the "password" tag: a password needs at least one
+// letter and one digit. Pair it with min= for the length.
+func strongPassword(fl validator.FieldLevel) bool {
+	var letter, digit bool
+	for _, r := range fl.Field().String() {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return letter && digit
+}
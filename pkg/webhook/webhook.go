@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// SignatureHeader carries the hex HMAC-SHA256 of the request body
+	SignatureHeader = "X-Webhook-Signature"
+	// EventHeader carries the event name
+	EventHeader = "X-Webhook-Event"
+)
+
+// Event is the JSON envelope posted to every endpoint
+type Event struct {
+	Event     string    `json:"event"`
+	Timestamp time.Time `json:"timestamp"`
+	Data      any       `json:"data"`
+}
+
+// Dispatcher posts signed events to the configured endpoints
+type Dispatcher struct {
+	urls         []string
+	secret       []byte
+	client       *http.Client
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+// NewDispatcher creates a Dispatcher from the webhooks config section
+func NewDispatcher(cfg *viper.Viper) *Dispatcher {
+	timeout := cfg.GetDuration("webhooks.timeout")
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	backoff := cfg.GetDuration("webhooks.retry_backoff")
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	return &Dispatcher{
+		urls:         cfg.GetStringSlice("webhooks.urls"),
+		secret:       []byte(cfg.GetString("webhooks.secret")),
+		client:       &http.Client{Timeout: timeout},
+		maxRetries:   cfg.GetInt("webhooks.max_retries"),
+		retryBackoff: backoff,
+	}
+}
+
+// Dispatch delivers the event to every endpoint, retrying failed deliveries.
+// It returns the joined errors of the endpoints that never accepted it.
+func (d *Dispatcher) Dispatch(ctx context.Context, event string, data any) error {
+	if len(d.urls) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(Event{Event: event, Timestamp: time.Now(), Data: data})
+	if err != nil {
+		return err
+	}
+	signature := Sign(d.secret, body)
+
+	var errs []error
+	for _, url := range d.urls {
+		if err := d.deliver(ctx, url, event, body, signature); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", url, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, url, event string, body []byte, signature string) error {
+	var lastErr error
+	for attempt := 0; attempt <= d.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(d.retryBackoff * time.Duration(attempt)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(EventHeader, event)
+		req.Header.Set(SignatureHeader, signature)
+
+		resp, err := d.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return lastErr
+}
+
+// Sign returns the signature receivers use to verify a payload
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+)
+
+type WebhookTestSuite struct {
+	suite.Suite
+	cfg *viper.Viper
+}
+
+func (suite *WebhookTestSuite) SetupTest() {
+	suite.cfg = viper.New()
+	suite.cfg.Set("webhooks.secret", "test_secret")
+	suite.cfg.Set("webhooks.timeout", time.Second)
+	suite.cfg.Set("webhooks.retry_backoff", time.Millisecond)
+	suite.cfg.Set("webhooks.max_retries", 2)
+}
+
+func (suite *WebhookTestSuite) TestDispatchSignsPayload() {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		suite.NoError(err)
+		suite.Equal(Sign([]byte("test_secret"), body), r.Header.Get(SignatureHeader))
+		suite.Equal("task.status_changed", r.Header.Get(EventHeader))
+		suite.NoError(json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	suite.cfg.Set("webhooks.urls", []string{server.URL})
+
+	err := NewDispatcher(suite.cfg).Dispatch(context.Background(), "task.status_changed", map[string]string{
+		"task_id": "task-1",
+		"status":  "completed",
+	})
+	suite.NoError(err)
+	suite.Equal("task.status_changed", received.Event)
+	suite.Equal(map[string]any{"task_id": "task-1", "status": "completed"}, received.Data)
+}
+
+func (suite *WebhookTestSuite) TestDispatchRetriesFailures() {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	suite.cfg.Set("webhooks.urls", []string{server.URL})
+
+	suite.NoError(NewDispatcher(suite.cfg).Dispatch(context.Background(), "task.status_changed", nil))
+	suite.Equal(int32(3), atomic.LoadInt32(&attempts))
+}
+
+func (suite *WebhookTestSuite) TestDispatchGivesUpAfterMaxRetries() {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	suite.cfg.Set("webhooks.urls", []string{server.URL})
+
+	suite.Error(NewDispatcher(suite.cfg).Dispatch(context.Background(), "task.status_changed", nil))
+	suite.Equal(int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestWebhookTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookTestSuite))
+}